@@ -0,0 +1,191 @@
+package astits
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// AC-3 audio coding modes
+// Page: 37 | Chapter: 5.4.2.3 | Link: https://www.atsc.org/wp-content/uploads/2015/03/A52-201212-17.pdf
+const (
+	AC3ACMod2F1R     = 4 // 2/1
+	AC3ACMod2F2R     = 6 // 2/2
+	AC3ACMod3F       = 3 // 3/0
+	AC3ACMod3F1R     = 5 // 3/1
+	AC3ACMod3F2R     = 7 // 3/2
+	AC3ACModDualMono = 0 // 1+1
+	AC3ACModMono     = 1 // 1/0
+	AC3ACModStereo   = 2 // 2/0
+)
+
+// AC-3 bitrates in kbps indexed by frame size code >> 1
+// Page: 50 | Chapter: 5.4.1.4 | Link: https://www.atsc.org/wp-content/uploads/2015/03/A52-201212-17.pdf
+var ac3Bitrates = []int{32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384, 448, 512, 576, 640}
+
+// AC-3 sample rates indexed by sample rate code
+var ac3SampleRates = []int{48000, 44100, 32000}
+
+// E-AC-3 sample rates indexed by sample rate code 2, when sample rate code is 3
+var eac3ReducedSampleRates = []int{24000, 22050, 16000}
+
+// E-AC-3 number of audio blocks indexed by number of audio blocks code
+var eac3NumberOfBlocks = []int{1, 2, 3, 6}
+
+// AC3Frame represents an AC-3 or E-AC-3 syncframe
+// Only the fields of the syncinfo and of the beginning of the bit stream information are parsed
+// Page: 33 | Chapter: 5.3 | Link: https://www.atsc.org/wp-content/uploads/2015/03/A52-201212-17.pdf
+// Page: 139 | Chapter: E.1.2 | Link: https://www.atsc.org/wp-content/uploads/2015/03/A52-201212-17.pdf
+type AC3Frame struct {
+	ACMod       uint8 // Audio coding mode, i.e. the arrangement of the full bandwidth channels
+	Bitrate     int   // In bits per second
+	BSID        uint8 // Bit stream identification. Above 10 for E-AC-3
+	BSMod       uint8 // Bit stream mode. AC-3 only
+	Data        []byte
+	FrameSize   int // In bytes
+	IsEAC3      bool
+	LFEOn       bool
+	SampleRate  int   // In Hz
+	StreamType  uint8 // E-AC-3 only
+	SubstreamID uint8 // E-AC-3 only
+}
+
+// ParseAC3Frames parses the AC-3 or E-AC-3 syncframes of a byte stream, such as the payload of a PES of the
+// StreamTypeAC3Audio or StreamTypeEAC3Audio stream types
+// Parsing resumes at the next 0x0b77 syncword whenever a syncframe is invalid. Since syncframes of 1536 samples are
+// often larger than a PES payload, rest holds the syncframe cut off by the end of the input, to be prepended to the
+// next payload
+func ParseAC3Frames(i []byte) (fs []*AC3Frame, rest []byte) {
+	rest = walkAudioFrames(i, func(offset *int) (err error) {
+		var f *AC3Frame
+		if f, err = parseAC3Frame(i, offset); err != nil {
+			return
+		}
+		fs = append(fs, f)
+		return
+	})
+	return
+}
+
+// parseAC3Frame parses an AC-3 or E-AC-3 syncframe
+func parseAC3Frame(i []byte, offset *int) (f *AC3Frame, err error) {
+	// Syncword
+	// The second byte is only checked when available so that a syncword cut off by the end of the input is kept
+	var offsetStart = *offset
+	if i[offsetStart] != 0x0b || (offsetStart+1 < len(i) && i[offsetStart+1] != 0x77) {
+		err = fmt.Errorf("astits: invalid ac3 syncword at offset %d", offsetStart)
+		return
+	}
+
+	// Check for incomplete header
+	if offsetStart+8 > len(i) {
+		err = errors.Wrapf(errTruncatedAudioFrame, "astits: ac3 header end (%d) > len(i) (%d)", offsetStart+8, len(i))
+		return
+	}
+
+	// Init
+	f = &AC3Frame{BSID: uint8(i[offsetStart+5] >> 3)}
+
+	// Parse header
+	if f.IsEAC3 = f.BSID > 10; f.IsEAC3 {
+		err = parseEAC3FrameHeader(i[offsetStart:], f)
+	} else {
+		err = parseAC3FrameHeader(i[offsetStart:], f)
+	}
+	if err != nil {
+		return
+	}
+
+	// Check frame size
+	var offsetEnd = offsetStart + f.FrameSize
+	if offsetEnd > len(i) {
+		err = errors.Wrapf(errTruncatedAudioFrame, "astits: ac3 frame end (%d) > len(i) (%d)", offsetEnd, len(i))
+		return
+	}
+
+	// Data
+	f.Data = i[offsetStart:offsetEnd]
+	*offset = offsetEnd
+	return
+}
+
+// parseAC3FrameHeader parses the syncinfo and the beginning of the bit stream information of an AC-3 syncframe
+func parseAC3FrameHeader(i []byte, f *AC3Frame) (err error) {
+	// Sample rate and frame size codes
+	var fscod = int(i[4] >> 6)
+	var frmsizecod = int(i[4] & 0x3f)
+	if fscod >= len(ac3SampleRates) || frmsizecod>>1 >= len(ac3Bitrates) {
+		err = fmt.Errorf("astits: invalid ac3 sample rate code %d or frame size code %d", fscod, frmsizecod)
+		return
+	}
+	f.SampleRate = ac3SampleRates[fscod]
+	f.Bitrate = ac3Bitrates[frmsizecod>>1] * 1000
+
+	// Frame size is coded in 16 bits words
+	// Page: 50 | Chapter: 5.4.1.4 | Link: https://www.atsc.org/wp-content/uploads/2015/03/A52-201212-17.pdf
+	switch f.SampleRate {
+	case 48000:
+		f.FrameSize = ac3Bitrates[frmsizecod>>1] * 2 * 2
+	case 44100:
+		f.FrameSize = (ac3Bitrates[frmsizecod>>1]*96000/44100 + frmsizecod&0x1) * 2
+	case 32000:
+		f.FrameSize = ac3Bitrates[frmsizecod>>1] * 3 * 2
+	}
+
+	// Bit stream mode
+	f.BSMod = uint8(i[5] & 0x7)
+
+	// Audio coding mode
+	f.ACMod = uint8(i[6] >> 5)
+
+	// The LFE flag follows optional mix levels and Dolby surround mode
+	var bit = 3
+	if f.ACMod&0x1 > 0 && f.ACMod != AC3ACModMono {
+		bit += 2
+	}
+	if f.ACMod&0x4 > 0 {
+		bit += 2
+	}
+	if f.ACMod == AC3ACModStereo {
+		bit += 2
+	}
+	f.LFEOn = (uint16(i[6])<<8|uint16(i[7]))>>uint(15-bit)&0x1 > 0
+	return
+}
+
+// parseEAC3FrameHeader parses the syncinfo and the beginning of the bit stream information of an E-AC-3 syncframe
+func parseEAC3FrameHeader(i []byte, f *AC3Frame) (err error) {
+	// Stream type
+	f.StreamType = uint8(i[2] >> 6)
+
+	// Substream ID
+	f.SubstreamID = uint8(i[2] >> 3 & 0x7)
+
+	// Frame size is coded in 16 bits words minus one
+	f.FrameSize = ((int(i[2]&0x7)<<8 | int(i[3])) + 1) * 2
+
+	// Sample rate and number of blocks
+	var fscod = int(i[4] >> 6)
+	var numberOfBlocks = 6
+	if fscod == 3 {
+		var fscod2 = int(i[4] >> 4 & 0x3)
+		if fscod2 >= len(eac3ReducedSampleRates) {
+			err = fmt.Errorf("astits: invalid eac3 sample rate code 2 %d", fscod2)
+			return
+		}
+		f.SampleRate = eac3ReducedSampleRates[fscod2]
+	} else {
+		f.SampleRate = ac3SampleRates[fscod]
+		numberOfBlocks = eac3NumberOfBlocks[i[4]>>4&0x3]
+	}
+
+	// Bitrate
+	f.Bitrate = f.FrameSize * 8 * f.SampleRate / (numberOfBlocks * 256)
+
+	// Audio coding mode
+	f.ACMod = uint8(i[4] >> 1 & 0x7)
+
+	// LFE
+	f.LFEOn = i[4]&0x1 > 0
+	return
+}
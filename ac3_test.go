@@ -0,0 +1,65 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ac3FrameBytes(header []byte, size int) []byte {
+	b := make([]byte, size)
+	copy(b, header)
+	return b
+}
+
+func TestParseAC3Frames(t *testing.T) {
+	// Valid frames
+	b := append(ac3FrameBytes([]byte{0xb, 0x77, 0x0, 0x0, 0x14, 0x40, 0xe1, 0x0}, 768), ac3FrameBytes([]byte{0xb, 0x77, 0x2, 0xff, 0x3f, 0x80, 0x0, 0x0}, 1536)...)
+	fs, rest := ParseAC3Frames(b)
+	assert.Empty(t, rest)
+	assert.Equal(t, []*AC3Frame{
+		{
+			ACMod:      AC3ACMod3F2R,
+			Bitrate:    192000,
+			BSID:       8,
+			Data:       b[:768],
+			FrameSize:  768,
+			LFEOn:      true,
+			SampleRate: 48000,
+		},
+		{
+			ACMod:      AC3ACMod3F2R,
+			Bitrate:    384000,
+			BSID:       16,
+			Data:       b[768:],
+			FrameSize:  1536,
+			IsEAC3:     true,
+			LFEOn:      true,
+			SampleRate: 48000,
+		},
+	}, fs)
+
+	// 44.1kHz frame size
+	fs, _ = ParseAC3Frames(ac3FrameBytes([]byte{0xb, 0x77, 0x0, 0x0, 0x41, 0x40, 0x40, 0x0}, 70*2))
+	assert.Equal(t, 140, fs[0].FrameSize)
+	assert.Equal(t, 44100, fs[0].SampleRate)
+	assert.Equal(t, uint8(AC3ACModStereo), fs[0].ACMod)
+	assert.False(t, fs[0].LFEOn)
+
+	// Syncframe spanning 2 payloads
+	fs, rest = ParseAC3Frames(b[:1000])
+	assert.Len(t, fs, 1)
+	assert.Equal(t, b[768:1000], rest)
+	fs, rest = ParseAC3Frames(append(rest, b[1000:]...))
+	assert.Empty(t, rest)
+	assert.Len(t, fs, 1)
+	assert.True(t, fs[0].IsEAC3)
+
+	// Invalid bytes are skipped
+	fs, rest = ParseAC3Frames(append(ac3FrameBytes([]byte{0xb, 0x78}, 8), b...))
+	assert.Empty(t, rest)
+	assert.Len(t, fs, 2)
+	fs, rest = ParseAC3Frames([]byte{0x1, 0xb})
+	assert.Empty(t, fs)
+	assert.Equal(t, []byte{0xb}, rest)
+}
@@ -0,0 +1,191 @@
+package astits
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// Adaptation field limits
+const (
+	adaptationFieldMaximumLength = MpegTsPacketSize - 5 // Sync byte, header and length byte excluded
+	legalTimeWindowOffsetMaximum = 0x7fff
+	piecewiseRateMaximum         = 0x3fffff
+	spliceTypeMaximum            = 0xf
+)
+
+// AdaptationFieldBuilder represents an object capable of building packet adaptation fields without hand-encoding
+// their flags, lengths and stuffing
+// Setters can be chained and errors are only returned once the adaptation field is built, for instance:
+//
+//	a, err := astits.NewAdaptationFieldBuilder().PCR(pcr).RandomAccess().Length(20).Build()
+type AdaptationFieldBuilder struct {
+	a         PacketAdaptationField
+	e         PacketAdaptationExtensionField
+	hasLength bool
+	length    int
+}
+
+// NewAdaptationFieldBuilder creates a new adaptation field builder
+func NewAdaptationFieldBuilder() *AdaptationFieldBuilder {
+	return &AdaptationFieldBuilder{}
+}
+
+// Discontinuity sets the discontinuity indicator
+func (b *AdaptationFieldBuilder) Discontinuity() *AdaptationFieldBuilder {
+	b.a.DiscontinuityIndicator = true
+	return b
+}
+
+// ElementaryStreamPriority sets the elementary stream priority indicator
+func (b *AdaptationFieldBuilder) ElementaryStreamPriority() *AdaptationFieldBuilder {
+	b.a.ElementaryStreamPriorityIndicator = true
+	return b
+}
+
+// LegalTimeWindow adds a legal time window to the adaptation extension field
+func (b *AdaptationFieldBuilder) LegalTimeWindow(isValid bool, offset uint16) *AdaptationFieldBuilder {
+	b.a.HasAdaptationExtensionField = true
+	b.e.HasLegalTimeWindow = true
+	b.e.LegalTimeWindowIsValid = isValid
+	b.e.LegalTimeWindowOffset = offset
+	return b
+}
+
+// Length sets the exact length of the adaptation field, its length byte excluded, which is reached by stuffing it
+// with 0xff bytes
+// When it's not set, the adaptation field has its minimum length
+func (b *AdaptationFieldBuilder) Length(l int) *AdaptationFieldBuilder {
+	b.hasLength = true
+	b.length = l
+	return b
+}
+
+// OPCR adds an original program clock reference
+func (b *AdaptationFieldBuilder) OPCR(c *ClockReference) *AdaptationFieldBuilder {
+	b.a.HasOPCR = true
+	b.a.OPCR = c
+	return b
+}
+
+// PCR adds a program clock reference
+func (b *AdaptationFieldBuilder) PCR(c *ClockReference) *AdaptationFieldBuilder {
+	b.a.HasPCR = true
+	b.a.PCR = c
+	return b
+}
+
+// PiecewiseRate adds a piecewise rate to the adaptation extension field
+func (b *AdaptationFieldBuilder) PiecewiseRate(rate uint32) *AdaptationFieldBuilder {
+	b.a.HasAdaptationExtensionField = true
+	b.e.HasPiecewiseRate = true
+	b.e.PiecewiseRate = rate
+	return b
+}
+
+// RandomAccess sets the random access indicator
+func (b *AdaptationFieldBuilder) RandomAccess() *AdaptationFieldBuilder {
+	b.a.RandomAccessIndicator = true
+	return b
+}
+
+// SeamlessSplice adds a seamless splice to the adaptation extension field
+func (b *AdaptationFieldBuilder) SeamlessSplice(spliceType uint8, dtsNextAccessUnit *ClockReference) *AdaptationFieldBuilder {
+	b.a.HasAdaptationExtensionField = true
+	b.e.DTSNextAccessUnit = dtsNextAccessUnit
+	b.e.HasSeamlessSplice = true
+	b.e.SpliceType = spliceType
+	return b
+}
+
+// SpliceCountdown adds a splicing point occurring countdown packets from this one
+// Negative values indicate how many packets ago the splicing point occurred
+func (b *AdaptationFieldBuilder) SpliceCountdown(countdown int) *AdaptationFieldBuilder {
+	b.a.HasSplicingCountdown = true
+	b.a.SpliceCountdown = countdown
+	return b
+}
+
+// TransportPrivateData adds transport private data
+func (b *AdaptationFieldBuilder) TransportPrivateData(d []byte) *AdaptationFieldBuilder {
+	b.a.HasTransportPrivateData = true
+	b.a.TransportPrivateData = d
+	b.a.TransportPrivateDataLength = len(d)
+	return b
+}
+
+// Build validates the fields and returns the adaptation field
+func (b *AdaptationFieldBuilder) Build() (a *PacketAdaptationField, err error) {
+	// Init
+	var c = b.a
+	a = &c
+
+	// Adaptation extension
+	if a.HasAdaptationExtensionField {
+		var e = b.e
+		e.Length = packetAdaptationExtensionFieldLength(&e)
+		a.AdaptationExtensionField = &e
+	}
+
+	// Validate fields
+	if err = validateAdaptationField(a); err != nil {
+		a = nil
+		return
+	}
+
+	// Length
+	var l = packetAdaptationFieldMinimumLength(a)
+	if b.hasLength {
+		if b.length < l {
+			err = fmt.Errorf("astits: adaptation field length %d is smaller than the length of its fields %d", b.length, l)
+			a = nil
+			return
+		} else if b.length > adaptationFieldMaximumLength {
+			err = fmt.Errorf("astits: adaptation field length %d is bigger than %d", b.length, adaptationFieldMaximumLength)
+			a = nil
+			return
+		}
+		l = b.length
+	} else if l > adaptationFieldMaximumLength {
+		err = fmt.Errorf("astits: adaptation field length %d is bigger than %d", l, adaptationFieldMaximumLength)
+		a = nil
+		return
+	}
+	a.Length = l
+	return
+}
+
+// Bytes builds the adaptation field and returns its serialized bytes, length byte and stuffing bytes included
+func (b *AdaptationFieldBuilder) Bytes() (o []byte, err error) {
+	var a *PacketAdaptationField
+	if a, err = b.Build(); err != nil {
+		return
+	}
+	o = writePacketAdaptationField(a)
+	return
+}
+
+// validateAdaptationField checks whether the fields of an adaptation field can be serialized
+func validateAdaptationField(a *PacketAdaptationField) error {
+	if a.HasPCR && a.PCR == nil {
+		return errors.New("astits: PCR is nil")
+	} else if a.HasOPCR && a.OPCR == nil {
+		return errors.New("astits: OPCR is nil")
+	} else if a.HasSplicingCountdown && (a.SpliceCountdown < -128 || a.SpliceCountdown > 127) {
+		return fmt.Errorf("astits: splice countdown %d is out of [-128, 127]", a.SpliceCountdown)
+	} else if a.HasTransportPrivateData && len(a.TransportPrivateData) > 0xff {
+		return fmt.Errorf("astits: transport private data length %d is bigger than 255", len(a.TransportPrivateData))
+	}
+	if e := a.AdaptationExtensionField; a.HasAdaptationExtensionField && e != nil {
+		if e.HasLegalTimeWindow && e.LegalTimeWindowOffset > legalTimeWindowOffsetMaximum {
+			return fmt.Errorf("astits: legal time window offset %d is bigger than %d", e.LegalTimeWindowOffset, legalTimeWindowOffsetMaximum)
+		} else if e.HasPiecewiseRate && e.PiecewiseRate > piecewiseRateMaximum {
+			return fmt.Errorf("astits: piecewise rate %d is bigger than %d", e.PiecewiseRate, piecewiseRateMaximum)
+		} else if e.HasSeamlessSplice && e.DTSNextAccessUnit == nil {
+			return errors.New("astits: DTS of the next access unit is nil")
+		} else if e.HasSeamlessSplice && e.SpliceType > spliceTypeMaximum {
+			return fmt.Errorf("astits: splice type %d is bigger than %d", e.SpliceType, spliceTypeMaximum)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdaptationFieldBuilder(t *testing.T) {
+	// All fields
+	a, err := NewAdaptationFieldBuilder().
+		Discontinuity().
+		ElementaryStreamPriority().
+		LegalTimeWindow(true, 10922).
+		Length(36).
+		OPCR(pcr).
+		PCR(pcr).
+		PiecewiseRate(2796202).
+		RandomAccess().
+		SeamlessSplice(2, dtsClockReference).
+		SpliceCountdown(2).
+		TransportPrivateData([]byte("test")).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, packetAdaptationField, a)
+	b, err := NewAdaptationFieldBuilder().
+		Discontinuity().
+		ElementaryStreamPriority().
+		LegalTimeWindow(true, 10922).
+		Length(36).
+		OPCR(pcr).
+		PCR(pcr).
+		PiecewiseRate(2796202).
+		RandomAccess().
+		SeamlessSplice(2, dtsClockReference).
+		SpliceCountdown(2).
+		TransportPrivateData([]byte("test")).
+		Bytes()
+	assert.NoError(t, err)
+	assert.Len(t, b, 37)
+	assert.Equal(t, packetAdaptationFieldBytes(*packetAdaptationField)[:27], b[:27])
+	assert.Equal(t, []byte{0xff, 0xff, 0xff, 0xff, 0xff}, b[32:])
+	assert.Equal(t, packetAdaptationField, parsePacketAdaptationField(b))
+
+	// Minimum length
+	a, err = NewAdaptationFieldBuilder().PCR(pcr).SpliceCountdown(-3).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, 8, a.Length)
+	b, err = NewAdaptationFieldBuilder().PCR(pcr).SpliceCountdown(-3).Bytes()
+	assert.NoError(t, err)
+	assert.Len(t, b, 9)
+	assert.Equal(t, a, parsePacketAdaptationField(b))
+
+	// Only stuffing
+	b, err = NewAdaptationFieldBuilder().Length(3).Bytes()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x3, 0x0, 0xff, 0xff}, b)
+
+	// Packet
+	a, err = NewAdaptationFieldBuilder().PCR(pcr).Build()
+	assert.NoError(t, err)
+	p, err := parsePacket(writePacket(&Packet{AdaptationField: a, Header: &PacketHeader{PID: 256}, Payload: []byte("payload")}))
+	assert.NoError(t, err)
+	assert.Equal(t, pcr, p.AdaptationField.PCR)
+	assert.Equal(t, []byte("payload"), p.Payload)
+
+	// Errors
+	for _, b := range []*AdaptationFieldBuilder{
+		NewAdaptationFieldBuilder().PCR(nil),
+		NewAdaptationFieldBuilder().OPCR(nil),
+		NewAdaptationFieldBuilder().SpliceCountdown(128),
+		NewAdaptationFieldBuilder().TransportPrivateData(make([]byte, 256)),
+		NewAdaptationFieldBuilder().LegalTimeWindow(true, 0x8000),
+		NewAdaptationFieldBuilder().PiecewiseRate(0x400000),
+		NewAdaptationFieldBuilder().SeamlessSplice(2, nil),
+		NewAdaptationFieldBuilder().SeamlessSplice(16, dtsClockReference),
+		NewAdaptationFieldBuilder().PCR(pcr).Length(6),
+		NewAdaptationFieldBuilder().Length(184),
+		NewAdaptationFieldBuilder().TransportPrivateData(make([]byte, 182)),
+	} {
+		_, err = b.Build()
+		assert.Error(t, err)
+	}
+}
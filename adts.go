@@ -0,0 +1,144 @@
+package astits
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// errTruncatedAudioFrame is the cause of the errors of audio frames cut off by the end of the input
+var errTruncatedAudioFrame = errors.New("astits: truncated audio frame")
+
+// ADTS sampling frequencies indexed by sampling frequency index
+// Page: 47 | Chapter: 1.6.3.4 | Link: ISO/IEC 14496-3
+var adtsSamplingFrequencies = []int{96000, 88200, 64000, 48000, 44100, 32000, 24000, 22050, 16000, 12000, 11025, 8000, 7350}
+
+// ADTSFrame represents an ADTS frame
+// Page: 29 | Chapter: 6.2 | Link: ISO/IEC 13818-7
+type ADTSFrame struct {
+	BufferFullness         uint16
+	ChannelConfiguration   uint8 // 0 when the channel configuration is defined in the raw data blocks, otherwise the number of channels except for 7 which means 8 channels
+	CRC                    uint16
+	Data                   []byte // Raw data blocks, header and CRC excluded
+	FrameLength            uint16 // Header included
+	HasCRC                 bool
+	IsMPEG2                bool  // False means MPEG-4
+	NumberOfRawDataBlocks  uint8 // Number of raw data blocks in the frame minus one
+	Profile                uint8 // MPEG-4 audio object type minus one, e.g. 1 for AAC LC
+	SamplingFrequency      int   // In Hz. 0 when the index is reserved
+	SamplingFrequencyIndex uint8
+}
+
+// ParseADTSFrames parses the ADTS frames of a byte stream, such as the payload of a PES of the StreamTypeADTSAudio
+// stream type
+// Bytes that don't start a valid frame are skipped until the next 0xfff syncword, and rest holds the beginning of the
+// last frame when its frame length goes beyond the input, e.g. fs, rest = ParseADTSFrames(append(rest, payload...))
+func ParseADTSFrames(i []byte) (fs []*ADTSFrame, rest []byte) {
+	rest = walkAudioFrames(i, func(offset *int) (err error) {
+		var f *ADTSFrame
+		if f, err = parseADTSFrame(i, offset); err != nil {
+			return
+		}
+		fs = append(fs, f)
+		return
+	})
+	return
+}
+
+// walkAudioFrames walks a byte stream of audio frames starting with a syncword and returns the bytes left after the
+// last complete frame
+// Whenever a frame is invalid, parsing resumes on the next byte so that the stream is resynchronized on the next
+// syncword. Frames cut off by the end of the input are not dropped though since, when the data alignment indicator is
+// not set, frames span PES packets: their bytes are returned so that callers can prepend them to the next payload.
+// The bytes returned don't share the capacity of the input so that appending to them doesn't overwrite it.
+func walkAudioFrames(i []byte, parse func(offset *int) error) (rest []byte) {
+	for offset := 0; offset < len(i); {
+		var o = offset
+		if err := parse(&o); err != nil {
+			if errors.Cause(err) == errTruncatedAudioFrame {
+				return i[offset:len(i):len(i)]
+			}
+			offset++
+			continue
+		}
+		offset = o
+	}
+	return
+}
+
+// parseADTSFrame parses an ADTS frame
+func parseADTSFrame(i []byte, offset *int) (f *ADTSFrame, err error) {
+	// Syncword and layer
+	// Only the bytes available are checked so that a header cut off by the end of the input is reported as truncated
+	var offsetStart = *offset
+	if i[offsetStart] != 0xff || (offsetStart+1 < len(i) && i[offsetStart+1]&0xf6 != 0xf0) {
+		err = fmt.Errorf("astits: invalid adts syncword or layer at offset %d", offsetStart)
+		return
+	}
+
+	// Check for incomplete header
+	if offsetStart+7 > len(i) {
+		err = errors.Wrapf(errTruncatedAudioFrame, "astits: adts header end (%d) > len(i) (%d)", offsetStart+7, len(i))
+		return
+	}
+
+	// Init
+	f = &ADTSFrame{}
+
+	// MPEG version
+	f.IsMPEG2 = i[*offset+1]&0x8 > 0
+
+	// Protection absent
+	f.HasCRC = i[*offset+1]&0x1 == 0
+	*offset += 2
+
+	// Profile
+	f.Profile = uint8(i[*offset] >> 6)
+
+	// Sampling frequency
+	f.SamplingFrequencyIndex = uint8(i[*offset] >> 2 & 0xf)
+	if int(f.SamplingFrequencyIndex) < len(adtsSamplingFrequencies) {
+		f.SamplingFrequency = adtsSamplingFrequencies[f.SamplingFrequencyIndex]
+	}
+
+	// Channel configuration
+	f.ChannelConfiguration = uint8(i[*offset]&0x1<<2 | i[*offset+1]>>6)
+	*offset += 1
+
+	// Frame length
+	f.FrameLength = uint16(i[*offset]&0x3)<<11 | uint16(i[*offset+1])<<3 | uint16(i[*offset+2]>>5)
+	*offset += 2
+
+	// Buffer fullness
+	f.BufferFullness = uint16(i[*offset]&0x1f)<<6 | uint16(i[*offset+1]>>2)
+	*offset += 1
+
+	// Number of raw data blocks
+	f.NumberOfRawDataBlocks = uint8(i[*offset] & 0x3)
+	*offset += 1
+
+	// Check frame length
+	var headerLength = 7
+	if f.HasCRC {
+		headerLength += 2
+	}
+	var offsetEnd = offsetStart + int(f.FrameLength)
+	if int(f.FrameLength) < headerLength {
+		err = fmt.Errorf("astits: adts frame length (%d) < header length (%d)", f.FrameLength, headerLength)
+		return
+	} else if offsetEnd > len(i) {
+		err = errors.Wrapf(errTruncatedAudioFrame, "astits: adts frame end (%d) > len(i) (%d)", offsetEnd, len(i))
+		return
+	}
+
+	// CRC
+	if f.HasCRC {
+		f.CRC = uint16(i[*offset])<<8 | uint16(i[*offset+1])
+		*offset += 2
+	}
+
+	// Data
+	f.Data = i[*offset:offsetEnd]
+	*offset = offsetEnd
+	return
+}
@@ -0,0 +1,80 @@
+package astits
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/asticode/go-astitools/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+func adtsFrameBytes(hasCRC bool, data []byte) []byte {
+	w := astibinary.New()
+	w.Write("111111111111") // Syncword
+	w.Write("0")            // MPEG version
+	w.Write("00")           // Layer
+	w.Write(!hasCRC)        // Protection absent
+	w.Write("01")           // Profile
+	w.Write("0011")         // Sampling frequency index
+	w.Write("0")            // Private bit
+	w.Write("010")          // Channel configuration
+	w.Write("0000")         // Original, home, copyright ID bit and start
+	l := 7 + len(data)
+	if hasCRC {
+		l += 2
+	}
+	w.Write(fmt.Sprintf("%013b", l)) // Frame length
+	w.Write("11111111111")           // Buffer fullness
+	w.Write("00")                    // Number of raw data blocks
+	if hasCRC {
+		w.Write(uint16(0x1234)) // CRC
+	}
+	w.Write(data)
+	return w.Bytes()
+}
+
+func TestParseADTSFrames(t *testing.T) {
+	// Valid frames
+	b := append(adtsFrameBytes(false, []byte("frame1")), adtsFrameBytes(true, []byte("frame2"))...)
+	fs, rest := ParseADTSFrames(b)
+	assert.Empty(t, rest)
+	assert.Equal(t, []*ADTSFrame{
+		{
+			BufferFullness:         0x7ff,
+			ChannelConfiguration:   2,
+			Data:                   []byte("frame1"),
+			FrameLength:            13,
+			Profile:                1,
+			SamplingFrequency:      48000,
+			SamplingFrequencyIndex: 3,
+		},
+		{
+			BufferFullness:         0x7ff,
+			ChannelConfiguration:   2,
+			CRC:                    0x1234,
+			Data:                   []byte("frame2"),
+			FrameLength:            15,
+			HasCRC:                 true,
+			Profile:                1,
+			SamplingFrequency:      48000,
+			SamplingFrequencyIndex: 3,
+		},
+	}, fs)
+
+	// Frame spanning 2 payloads
+	fs, rest = ParseADTSFrames(b[:len(b)-1])
+	assert.Len(t, fs, 1)
+	assert.Equal(t, b[13:len(b)-1], rest)
+	fs, rest = ParseADTSFrames(append(rest, b[len(b)-1:]...))
+	assert.Empty(t, rest)
+	assert.Len(t, fs, 1)
+	assert.Equal(t, []byte("frame2"), fs[0].Data)
+
+	// Invalid bytes are skipped
+	fs, rest = ParseADTSFrames(append([]byte{0xff, 0xe1, 0x0, 0x1, 0x0, 0x0, 0x0}, b...))
+	assert.Empty(t, rest)
+	assert.Len(t, fs, 2)
+	fs, rest = ParseADTSFrames([]byte{0x1, 0xff})
+	assert.Empty(t, fs)
+	assert.Equal(t, []byte{0xff}, rest)
+}
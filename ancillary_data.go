@@ -0,0 +1,133 @@
+package astits
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Ancillary data identifiers, made of the DID and the SDID
+// Link: https://smpte-ra.org/smpte-ancillary-data-smpte-st-291
+const (
+	ANCDataIdentifierAFD       = 0x4105
+	ANCDataIdentifierCEA608    = 0x6102
+	ANCDataIdentifierCEA708    = 0x6101
+	ANCDataIdentifierOP47Multi = 0x4303
+	ANCDataIdentifierOP47SDP   = 0x4302
+	ANCDataIdentifierSCTE104   = 0x4107
+	ANCDataIdentifierTimecode  = 0x6060
+)
+
+// ANCData represents the SMPTE ST 2038 ancillary data packets carried by a PES
+type ANCData struct {
+	Packets []*ANCPacket
+	PTS     *ClockReference // PTS of the PES the packets were carried by, i.e. of the video frame they belong to
+}
+
+// ANCPacket represents an ancillary data packet
+// Page: 4 | Chapter: 4.2 | Link: SMPTE ST 2038
+type ANCPacket struct {
+	Checksum         uint16
+	CNotYChannel     bool // Set when the packet is carried in the color difference channel, unset for the luma channel
+	DID              uint8
+	HorizontalOffset uint16
+	LineNumber       uint16
+	SDID             uint8
+	UserData         []byte // 8 least significant bits of the user data words, parity bits excluded
+}
+
+// DataIdentifier returns the DID and the SDID of the packet as a single value that can be compared to the
+// ANCDataIdentifier* constants
+func (p *ANCPacket) DataIdentifier() uint16 {
+	return uint16(p.DID)<<8 | uint16(p.SDID)
+}
+
+// ParseANCPackets parses the SMPTE ST 2038 ancillary data packets of a PES payload. Parsing stops at the first stuffing
+// byte
+// Page: 4 | Chapter: 4.2 | Link: SMPTE ST 2038
+func ParseANCPackets(i []byte) (ps []*ANCPacket, err error) {
+	var r = newBitReader(i)
+	for r.byteOffset() < len(i) {
+		// Zero bits, stuffing bytes are made of ones
+		var v uint32
+		if v, err = r.read(6); err != nil {
+			err = errors.Wrap(err, "astits: reading ANC zero bits failed")
+			return
+		} else if v != 0 {
+			return
+		}
+
+		// Parse packet
+		var p *ANCPacket
+		if p, err = parseANCPacket(r); err != nil {
+			err = errors.Wrapf(err, "astits: parsing ANC packet #%d failed", len(ps)+1)
+			return
+		}
+		ps = append(ps, p)
+
+		// Word align
+		r.align()
+	}
+	return
+}
+
+// parseANCPacket parses an ancillary data packet once its zero bits have been read
+func parseANCPacket(r *bitReader) (p *ANCPacket, err error) {
+	// Read header
+	var vs = make([]uint32, 6)
+	for idx, n := range []int{1, 11, 12, 10, 10, 10} {
+		if vs[idx], err = r.read(n); err != nil {
+			err = errors.Wrap(err, "astits: reading ANC packet header failed")
+			return
+		}
+	}
+	var tmp = &ANCPacket{
+		CNotYChannel:     vs[0] > 0,
+		DID:              uint8(vs[3]),
+		HorizontalOffset: uint16(vs[2]),
+		LineNumber:       uint16(vs[1]),
+		SDID:             uint8(vs[4]),
+	}
+
+	// User data words
+	var count = int(vs[5] & 0xff)
+	tmp.UserData = make([]byte, count)
+	for idx := 0; idx < count; idx++ {
+		var v uint32
+		if v, err = r.read(10); err != nil {
+			err = errors.Wrapf(err, "astits: reading ANC user data word #%d failed", idx+1)
+			return
+		}
+		tmp.UserData[idx] = uint8(v)
+	}
+
+	// Checksum
+	var v uint32
+	if v, err = r.read(10); err != nil {
+		err = errors.Wrap(err, "astits: reading ANC checksum failed")
+		return
+	}
+	tmp.Checksum = uint16(v)
+	p = tmp
+	return
+}
+
+// updateANCData parses the ancillary data packets of PES data received on SMPTE ST 2038 streams
+func (dmx *Demuxer) updateANCData(ds []*Data) {
+	for _, d := range ds {
+		// Only PES data of ancillary data streams is updated
+		if d.PES == nil || !dmx.ancPIDs[d.PID] {
+			continue
+		}
+
+		// Parse packets
+		ps, err := ParseANCPackets(d.PES.Data)
+		if err != nil {
+			// Packets may be incomplete, therefore we only log the error and move on
+			dmx.optLogger.Debugf("astits: parsing ANC packets of PID %d failed: %s", d.PID, err)
+			continue
+		}
+		d.ANC = &ANCData{Packets: ps}
+		if h := d.PES.Header.OptionalHeader; h != nil {
+			d.ANC.PTS = h.PTS
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/asticode/go-astitools/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+func ancBytes() []byte {
+	w := astibinary.New()
+	w.Write("000000")           // Zero bits
+	w.Write("1")                // C not Y channel flag
+	w.Write("00000001001")      // Line number
+	w.Write("000000000010")     // Horizontal offset
+	w.Write("1001000001")       // DID
+	w.Write("0100000111")       // SDID
+	w.Write("0100000010")       // Data count
+	w.Write("1000000001")       // User data word #1
+	w.Write("0100000010")       // User data word #2
+	w.Write("0110001110")       // Checksum
+	w.Write("111111")           // Word align
+	w.Write([]byte{0xff, 0xff}) // Stuffing
+	return w.Bytes()
+}
+
+var ancPackets = []*ANCPacket{{
+	Checksum:         0x18e,
+	CNotYChannel:     true,
+	DID:              0x41,
+	HorizontalOffset: 2,
+	LineNumber:       9,
+	SDID:             0x07,
+	UserData:         []byte{0x1, 0x2},
+}}
+
+func TestParseANCPackets(t *testing.T) {
+	ps, err := ParseANCPackets(ancBytes())
+	assert.NoError(t, err)
+	assert.Equal(t, ancPackets, ps)
+	assert.Equal(t, uint16(ANCDataIdentifierSCTE104), ps[0].DataIdentifier())
+
+	// Truncated packet
+	_, err = ParseANCPackets(ancBytes()[:8])
+	assert.Error(t, err)
+}
+
+func TestDemuxerANC(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	pm, _ := (&PMTData{ElementaryStreams: []*PMTElementaryStream{{
+		ElementaryPID:               0x101,
+		ElementaryStreamDescriptors: []*Descriptor{{Registration: &DescriptorRegistration{FormatIdentifier: RegistrationFormatIdentifierVANC}, Tag: DescriptorTagRegistration}},
+		StreamType:                  StreamTypeMPEG2PacketizedData,
+	}}, PCRPID: 0x1fff, ProgramNumber: 1}).Serialize(0)
+	pes := (&PESData{
+		Data: ancBytes(),
+		Header: &PESHeader{
+			OptionalHeader: &PESOptionalHeader{
+				MarkerBits:      2,
+				PTS:             newClockReference(90000, 0),
+				PTSDTSIndicator: PTSDTSIndicatorOnlyPTS,
+			},
+			StreamID: StreamIDPrivateStream1,
+		},
+	}).Serialize()
+	for _, v := range []struct {
+		b   []byte
+		pid uint16
+	}{
+		{b: append([]byte{0x0}, pm...), pid: 0x100},
+		{b: pes, pid: 0x101},
+	} {
+		var p = append(v.b, bytes.Repeat([]byte{0xff}, 147-len(v.b))...)
+		b, _ := packet(PacketHeader{PayloadUnitStartIndicator: true, PID: v.pid}, PacketAdaptationField{}, p)
+		w.Write(b)
+		b, _ = packet(PacketHeader{ContinuityCounter: uint8(1), PayloadUnitStartIndicator: true, PID: v.pid}, PacketAdaptationField{}, []byte{})
+		w.Write(b)
+	}
+	dmx := New(context.Background(), bytes.NewReader(w.Bytes()))
+	dmx.programMap.set(0x100, 1)
+
+	// PMT
+	d, err := dmx.NextData()
+	assert.NoError(t, err)
+	assert.NotNil(t, d.PMT)
+
+	// ANC
+	d, err = dmx.NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, &ANCData{Packets: ancPackets, PTS: newClockReference(90000, 0)}, d.ANC)
+}
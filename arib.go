@@ -0,0 +1,270 @@
+package astits
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// ARIB STD-B24 graphic sets final bytes
+// Chapter: 7.2.1 | ARIB STD-B24 Volume 1 Part 2
+const (
+	aribSetAlphanumeric             = 0x4a
+	aribSetHiragana                 = 0x30
+	aribSetJISX0201Katakana         = 0x49
+	aribSetJISCompatibleKanjiPlane1 = 0x39
+	aribSetJISCompatibleKanjiPlane2 = 0x3a
+	aribSetKanji                    = 0x42
+	aribSetKatakana                 = 0x31
+	aribSetProportionalAlphanumeric = 0x36
+	aribSetProportionalHiragana     = 0x37
+	aribSetProportionalKatakana     = 0x38
+)
+
+// aribSet represents a graphic set designated to G0, G1, G2 or G3
+type aribSet struct {
+	drcs     bool // Dynamically redefinable character sets can't be decoded
+	final    uint8
+	multiple bool // Characters are coded on 2 bytes
+}
+
+// ARIB hiragana and katakana sets share their last characters
+var aribKanaSymbols = []rune("ー。「」、・")
+
+// aribJISX0208 contains the characters of the JIS X 0208 set indexed by row and cell
+var aribJISX0208 = func() (o [94][]rune) {
+	for idx, r := range aribJISX0208Rows {
+		o[idx] = []rune(r)
+	}
+	return
+}()
+
+// ARIBTextDecoder represents a text decoder for strings coded with the 8-bit character codes of ARIB STD-B24 as it's
+// done in ISDB SI tables
+// Control codes, except for the new line and the space, are dropped as well as characters of the DRCS, mosaic and
+// additional symbols sets
+// Chapter: 7 | ARIB STD-B24 Volume 1 Part 2
+type ARIBTextDecoder struct{}
+
+// NewARIBTextDecoder creates a new ARIB text decoder
+func NewARIBTextDecoder() *ARIBTextDecoder {
+	return &ARIBTextDecoder{}
+}
+
+// aribTextDecoderState represents the state of the code extension while decoding an ARIB string
+type aribTextDecoderState struct {
+	g           [4]aribSet
+	gl          int
+	gr          int
+	singleShift int // -1 when not set
+}
+
+// DecodeText implements the TextDecoder interface
+func (d *ARIBTextDecoder) DecodeText(i []byte) (o string, err error) {
+	// Init
+	var s = aribTextDecoderState{
+		g: [4]aribSet{
+			{final: aribSetKanji, multiple: true},
+			{final: aribSetAlphanumeric},
+			{final: aribSetHiragana},
+			{final: aribSetKatakana},
+		},
+		gl:          0,
+		gr:          2,
+		singleShift: -1,
+	}
+	var rs []rune
+
+	// Loop through bytes
+	for offset := 0; offset < len(i); {
+		var b = i[offset]
+		switch {
+		case b == 0x1b:
+			// Escape sequence
+			if offset, err = s.escape(i, offset+1); err != nil {
+				return
+			}
+		case b <= 0x20:
+			// C0 control codes
+			offset = s.c0(i, offset, &rs)
+		case b == 0x7f, b == 0xa0, b == 0xff:
+			offset++
+		case b >= 0x80 && b < 0xa0:
+			// C1 control codes
+			offset = aribC1(i, offset)
+		default:
+			// Get set
+			var g = s.gl
+			if b >= 0x80 {
+				g = s.gr
+			}
+			if s.singleShift >= 0 {
+				g = s.singleShift
+				s.singleShift = -1
+			}
+			var set = s.g[g]
+
+			// Get character
+			var c1 = b & 0x7f
+			var c2 uint8
+			offset++
+			if set.multiple {
+				if offset >= len(i) {
+					err = fmt.Errorf("astits: ARIB 2-byte character is truncated")
+					return
+				}
+				c2 = i[offset] & 0x7f
+				offset++
+			}
+			if r, ok := set.rune(c1, c2); ok {
+				rs = append(rs, r)
+			}
+		}
+	}
+	o = string(rs)
+	return
+}
+
+// escape processes an escape sequence and returns the offset of the byte following it
+func (s *aribTextDecoderState) escape(i []byte, offset int) (int, error) {
+	if offset >= len(i) {
+		return offset, fmt.Errorf("astits: ARIB escape sequence is truncated")
+	}
+	switch b := i[offset]; b {
+	case 0x6e: // LS2
+		s.gl = 2
+	case 0x6f: // LS3
+		s.gl = 3
+	case 0x7e: // LS1R
+		s.gr = 1
+	case 0x7d: // LS2R
+		s.gr = 2
+	case 0x7c: // LS3R
+		s.gr = 3
+	case 0x24:
+		// 2-byte sets designation
+		offset++
+		var g int
+		if offset < len(i) && i[offset] >= 0x28 && i[offset] <= 0x2b {
+			g = int(i[offset] - 0x28)
+			offset++
+		}
+		return s.designate(i, offset, g, true)
+	case 0x28, 0x29, 0x2a, 0x2b:
+		// 1-byte sets designation
+		return s.designate(i, offset+1, int(b-0x28), false)
+	default:
+		return offset, fmt.Errorf("astits: unknown ARIB escape sequence 0x%x", b)
+	}
+	return offset + 1, nil
+}
+
+// designate designates a set to one of G0, G1, G2 and G3 based on its final byte
+func (s *aribTextDecoderState) designate(i []byte, offset, g int, multiple bool) (int, error) {
+	var set = aribSet{multiple: multiple}
+	if offset < len(i) && i[offset] == 0x20 {
+		set.drcs = true
+		offset++
+	}
+	if offset >= len(i) {
+		return offset, fmt.Errorf("astits: ARIB designation is truncated")
+	}
+	set.final = i[offset]
+	s.g[g] = set
+	return offset + 1, nil
+}
+
+// c0 processes a C0 control code and returns the offset of the byte following it
+func (s *aribTextDecoderState) c0(i []byte, offset int, rs *[]rune) int {
+	switch i[offset] {
+	case 0x0d: // APR
+		*rs = append(*rs, '\n')
+	case 0x0e: // LS1
+		s.gl = 1
+	case 0x0f: // LS0
+		s.gl = 0
+	case 0x16: // PAPF
+		return offset + 2
+	case 0x19: // SS2
+		s.singleShift = 2
+	case 0x1c: // APS
+		return offset + 3
+	case 0x1d: // SS3
+		s.singleShift = 3
+	case 0x20: // SP
+		*rs = append(*rs, ' ')
+	}
+	return offset + 1
+}
+
+// aribC1 skips a C1 control code and its parameters and returns the offset of the byte following it
+func aribC1(i []byte, offset int) int {
+	switch i[offset] {
+	case 0x8b, 0x91, 0x93, 0x94, 0x97, 0x98: // SZX, FLC, POL, WMM, HLC, RPC
+		return offset + 2
+	case 0x90, 0x92: // COL, CDC
+		if offset+1 < len(i) && i[offset+1] == 0x20 {
+			return offset + 3
+		}
+		return offset + 2
+	case 0x9d: // TIME
+		return offset + 3
+	case 0x9b: // CSI is terminated by a final byte
+		for offset++; offset < len(i); offset++ {
+			if i[offset] >= 0x40 && i[offset] <= 0x6f {
+				break
+			}
+		}
+		return offset + 1
+	}
+	return offset + 1
+}
+
+// rune returns the character of the set coded with c1 and, for 2-byte sets, c2
+func (s aribSet) rune(c1, c2 uint8) (rune, bool) {
+	// DRCS
+	if s.drcs {
+		return 0, false
+	}
+
+	// Switch on set
+	switch s.final {
+	case aribSetAlphanumeric, aribSetProportionalAlphanumeric:
+		switch c1 {
+		case 0x5c:
+			return '¥', true
+		case 0x7e:
+			return '‾', true
+		}
+		return rune(c1), true
+	case aribSetHiragana, aribSetProportionalHiragana:
+		return aribKana(c1, 'ぁ', 'ゝ', 0x73)
+	case aribSetKatakana, aribSetProportionalKatakana:
+		return aribKana(c1, 'ァ', 'ヽ', 0x76)
+	case aribSetJISX0201Katakana:
+		if c1 <= 0x5f {
+			return rune(c1) - 0x21 + '｡', true
+		}
+	case aribSetKanji, aribSetJISCompatibleKanjiPlane1:
+		if c2 >= 0x21 && c2 <= 0x7e {
+			if r := aribJISX0208[c1-0x21][c2-0x21]; r != utf8.RuneError {
+				return r, true
+			}
+		}
+		return utf8.RuneError, true
+	}
+	return 0, false
+}
+
+// aribKana returns a character of the hiragana and katakana sets whose first characters are consecutive in Unicode
+// followed by 2 iteration marks and the shared symbols
+func aribKana(c uint8, first, iterationMark rune, last uint8) (rune, bool) {
+	switch {
+	case c <= last:
+		return first + rune(c-0x21), true
+	case c == 0x77, c == 0x78:
+		return iterationMark + rune(c-0x77), true
+	case c >= 0x79:
+		return aribKanaSymbols[c-0x79], true
+	}
+	return 0, false
+}
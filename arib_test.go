@@ -0,0 +1,43 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestARIBTextDecoder(t *testing.T) {
+	d := NewARIBTextDecoder()
+
+	// Default sets
+	s, err := d.DecodeText([]byte{
+		0x0e, 'N', 'H', 'K', // LS1 and alphanumeric in G1
+		0x0f, 0x41, 0x6d, 0x39, 0x67, // LS0 and Kanji in G0
+		0x90, 0x48, // Color control code
+		0x20,             // Space
+		0xc6, 0xb9, 0xc8, // Hiragana in G2 invoked in GR
+		0x0d,             // New line
+		0x1b, 0x7c, 0xc6, // LS3R and katakana in G3
+		0x19, 0x7e, // SS2 and hiragana symbol
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "NHK総合 てすと\nテ・", s)
+
+	// Designations
+	s, err = d.DecodeText([]byte{
+		0x1b, 0x28, 0x4a, 'a', 0x5c, // Alphanumeric in G0
+		0x1b, 0x29, 0x49, 0x0e, 0x31, // JIS X 0201 katakana in G1
+		0x1b, 0x2a, 0x20, 0x41, 0x19, 0x21, // DRCS in G2
+		0x1b, 0x24, 0x2b, 0x42, 0x1d, 0x41, 0x6d, // Kanji in G3
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "a¥ｱ総", s)
+
+	// Errors
+	_, err = d.DecodeText([]byte{0x41})
+	assert.Error(t, err)
+	_, err = d.DecodeText([]byte{0x1b})
+	assert.Error(t, err)
+	_, err = d.DecodeText([]byte{0x1b, 0x40})
+	assert.Error(t, err)
+}
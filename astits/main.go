@@ -6,7 +6,6 @@ import (
 	"flag"
 	"fmt"
 	"io"
-	"net"
 	"net/url"
 	"os"
 	"os/signal"
@@ -29,6 +28,7 @@ var (
 	format          = flag.String("f", "", "the format")
 	inputPath       = flag.String("i", "", "the input path")
 	memoryProfiling = flag.Bool("mp", false, "if yes, memory profiling is enabled")
+	textEncoding    = flag.String("t", "", "the text encoding of descriptors: arib or dvb (default)")
 )
 
 func main() {
@@ -62,7 +62,11 @@ func main() {
 	}
 
 	// Create the demuxer
-	var dmx = astits.New(ctx, r)
+	var opts []func(*astits.Demuxer)
+	if *textEncoding == "arib" {
+		opts = append(opts, astits.OptTextDecoder(astits.NewARIBTextDecoder()))
+	}
+	var dmx = astits.New(ctx, r, opts...)
 
 	// Switch on subcommand
 	switch s {
@@ -130,21 +134,13 @@ func buildReader(ctx context.Context) (r io.Reader, err error) {
 	// Switch on scheme
 	switch u.Scheme {
 	case "udp":
-		// Resolve addr
-		var addr *net.UDPAddr
-		if addr, err = net.ResolveUDPAddr("udp", u.Host); err != nil {
-			err = errors.Wrapf(err, "astits: resolving udp addr %s failed", u.Host)
+		// Listen to UDP. RTP headers are stripped by the reader
+		var c *astits.UDPReader
+		if c, err = astits.ListenUDP(u.Host, nil); err != nil {
+			err = errors.Wrapf(err, "astits: listening on udp addr %s failed", u.Host)
 			return
 		}
 
-		// Listen to multicast UDP
-		var c *net.UDPConn
-		if c, err = net.ListenMulticastUDP("udp", nil, addr); err != nil {
-			err = errors.Wrapf(err, "astits: listening on multicast udp addr %s failed", u.Host)
-			return
-		}
-		c.SetReadBuffer(4096)
-
 		// Initialize linearizer
 		// It will read 4096 bytes at each iteration, and will store up to 2MB in its buffer
 		var l = astiio.NewLinearizer(ctx, c, 4096, 2048*1024)
@@ -166,13 +162,67 @@ func buildReader(ctx context.Context) (r io.Reader, err error) {
 
 func data(dmx *astits.Demuxer) (err error) {
 	// Determine which data to log
-	var logAll, logEIT, logNIT, logPAT, logPES, logPMT, logSDT, logTOT bool
+	var logAll, logAIT, logANC, logATSCEIT, logBAT, logBIT, logCAT, logCVCT, logDIT, logDSMCC, logECM, logEIT, logEMM, logETT, logID3, logKLV, logLDT, logMGT, logMPE, logNBIT, logNIT, logPAT, logPES, logPMT, logRRT, logRST, logSCTE35, logSDT, logSIT, logST, logSTT, logT2MI, logTDT, logTOT, logTSDT, logTVCT, logULE bool
 	if _, ok := dataTypes["all"]; ok {
 		logAll = true
 	}
+	if _, ok := dataTypes["ait"]; ok {
+		logAIT = true
+	}
+	if _, ok := dataTypes["anc"]; ok {
+		logANC = true
+	}
+	if _, ok := dataTypes["atsceit"]; ok {
+		logATSCEIT = true
+	}
+	if _, ok := dataTypes["bat"]; ok {
+		logBAT = true
+	}
+	if _, ok := dataTypes["bit"]; ok {
+		logBIT = true
+	}
+	if _, ok := dataTypes["cat"]; ok {
+		logCAT = true
+	}
+	if _, ok := dataTypes["cvct"]; ok {
+		logCVCT = true
+	}
+	if _, ok := dataTypes["dit"]; ok {
+		logDIT = true
+	}
+	if _, ok := dataTypes["dsmcc"]; ok {
+		logDSMCC = true
+	}
+	if _, ok := dataTypes["ecm"]; ok {
+		logECM = true
+	}
 	if _, ok := dataTypes["eit"]; ok {
 		logEIT = true
 	}
+	if _, ok := dataTypes["emm"]; ok {
+		logEMM = true
+	}
+	if _, ok := dataTypes["ett"]; ok {
+		logETT = true
+	}
+	if _, ok := dataTypes["id3"]; ok {
+		logID3 = true
+	}
+	if _, ok := dataTypes["klv"]; ok {
+		logKLV = true
+	}
+	if _, ok := dataTypes["ldt"]; ok {
+		logLDT = true
+	}
+	if _, ok := dataTypes["mgt"]; ok {
+		logMGT = true
+	}
+	if _, ok := dataTypes["mpe"]; ok {
+		logMPE = true
+	}
+	if _, ok := dataTypes["nbit"]; ok {
+		logNBIT = true
+	}
 	if _, ok := dataTypes["nit"]; ok {
 		logNIT = true
 	}
@@ -185,12 +235,45 @@ func data(dmx *astits.Demuxer) (err error) {
 	if _, ok := dataTypes["pmt"]; ok {
 		logPMT = true
 	}
+	if _, ok := dataTypes["rrt"]; ok {
+		logRRT = true
+	}
+	if _, ok := dataTypes["rst"]; ok {
+		logRST = true
+	}
+	if _, ok := dataTypes["scte35"]; ok {
+		logSCTE35 = true
+	}
 	if _, ok := dataTypes["sdt"]; ok {
 		logSDT = true
 	}
+	if _, ok := dataTypes["sit"]; ok {
+		logSIT = true
+	}
+	if _, ok := dataTypes["st"]; ok {
+		logST = true
+	}
+	if _, ok := dataTypes["stt"]; ok {
+		logSTT = true
+	}
+	if _, ok := dataTypes["t2mi"]; ok {
+		logT2MI = true
+	}
+	if _, ok := dataTypes["tdt"]; ok {
+		logTDT = true
+	}
 	if _, ok := dataTypes["tot"]; ok {
 		logTOT = true
 	}
+	if _, ok := dataTypes["tsdt"]; ok {
+		logTSDT = true
+	}
+	if _, ok := dataTypes["tvct"]; ok {
+		logTVCT = true
+	}
+	if _, ok := dataTypes["ule"]; ok {
+		logULE = true
+	}
 
 	// Loop through data
 	var d *astits.Data
@@ -206,9 +289,84 @@ func data(dmx *astits.Demuxer) (err error) {
 		}
 
 		// Log data
-		if d.EIT != nil && (logAll || logEIT) {
+		if d.AIT != nil && (logAll || logAIT) {
+			astilog.Infof("AIT: %d | application type: 0x%x", d.PID, d.AIT.ApplicationType)
+			for _, a := range d.AIT.Applications {
+				astilog.Infof("application | organisation: 0x%x | id: 0x%x | control code: 0x%x | urls: %v", a.OrganisationID, a.ApplicationID, a.ControlCode, d.AIT.URLs(a))
+			}
+		} else if d.ANC != nil && (logAll || logANC) {
+			astilog.Infof("ANC: %d | packets: %d", d.PID, len(d.ANC.Packets))
+			for _, p := range d.ANC.Packets {
+				astilog.Infof("- DID 0x%x | SDID 0x%x | line %d | %d bytes", p.DID, p.SDID, p.LineNumber, len(p.UserData))
+			}
+		} else if d.ATSCEIT != nil && (logAll || logATSCEIT) {
+			astilog.Infof("ATSC EIT: %d | source: %d", d.PID, d.ATSCEIT.SourceID)
+			for _, e := range d.ATSCEIT.Events {
+				astilog.Infof("- id: %d | start: %s | duration: %s | title: %s", e.EventID, e.StartTime.Format("15:04:05"), e.Duration, e.Title)
+			}
+		} else if d.BAT != nil && (logAll || logBAT) {
+			astilog.Infof("BAT: %d", d.PID)
+		} else if d.BIT != nil && (logAll || logBIT) {
+			astilog.Infof("BIT: %d | original network: %d", d.PID, d.BIT.OriginalNetworkID)
+			for _, b := range d.BIT.Broadcasters {
+				astilog.Infof("- broadcaster %d", b.BroadcasterID)
+			}
+		} else if d.CAT != nil && (logAll || logCAT) {
+			astilog.Infof("CAT: %d", d.PID)
+			for _, dsc := range d.CAT.Descriptors {
+				astilog.Info(descriptorToString(dsc))
+			}
+		} else if d.CVCT != nil && (logAll || logCVCT) {
+			astilog.Infof("CVCT: %d", d.PID)
+			astilog.Info(channelsToString(d.CVCT.Channels))
+		} else if d.DIT != nil && (logAll || logDIT) {
+			astilog.Infof("DIT: %d | transition flag: %v", d.PID, d.DIT.TransitionFlag)
+		} else if d.DSMCC != nil && (logAll || logDSMCC) {
+			astilog.Infof("DSMCC: %d | message id: 0x%x | transaction id: 0x%x", d.PID, d.DSMCC.MessageID, d.DSMCC.TransactionID)
+		} else if d.ECM != nil && (logAll || logECM) {
+			astilog.Infof("ECM: %d | CA system id: 0x%x (%s) | table id: 0x%x | length: %d", d.PID, d.ECM.CASystemID, astits.CASystemName(d.ECM.CASystemID), d.ECM.TableID, len(d.ECM.Data))
+		} else if d.EIT != nil && (logAll || logEIT) {
 			astilog.Infof("EIT: %d", d.PID)
 			astilog.Info(eventsToString(d.EIT.Events))
+		} else if d.EMM != nil && (logAll || logEMM) {
+			astilog.Infof("EMM: %d | CA system id: 0x%x (%s) | table id: 0x%x | length: %d", d.PID, d.EMM.CASystemID, astits.CASystemName(d.EMM.CASystemID), d.EMM.TableID, len(d.EMM.Data))
+		} else if d.ETT != nil && (logAll || logETT) {
+			astilog.Infof("ETT: %d | source: %d | event: %d | text: %s", d.PID, d.ETT.SourceID, d.ETT.EventID, d.ETT.ExtendedText)
+		} else if d.ID3 != nil && (logAll || logID3) {
+			if d.ID3.PTS != nil {
+				astilog.Infof("ID3: %d | PTS: %s | tags: %d", d.PID, d.ID3.PTS.Duration(), len(d.ID3.Tags))
+			} else {
+				astilog.Infof("ID3: %d | tags: %d", d.PID, len(d.ID3.Tags))
+			}
+			for _, t := range d.ID3.Tags {
+				for _, f := range t.Frames {
+					astilog.Infof("- frame %s | %d bytes", f.ID, len(f.Data))
+				}
+			}
+		} else if d.KLV != nil && (logAll || logKLV) {
+			astilog.Infof("KLV: %d | packets: %d", d.PID, len(d.KLV.Packets))
+			for _, p := range d.KLV.Packets {
+				astilog.Infof("- key %x | %d bytes", p.Key, p.Length)
+			}
+		} else if d.LDT != nil && (logAll || logLDT) {
+			astilog.Infof("LDT: %d | original service: %d", d.PID, d.LDT.OriginalServiceID)
+			for _, ds := range d.LDT.Descriptions {
+				astilog.Infof("- description %d", ds.DescriptionID)
+			}
+		} else if d.MPE != nil && (logAll || logMPE) {
+			astilog.Infof("MPE: %d | MAC address: %x | section: %d/%d | datagram length: %d", d.PID, d.MPE.MACAddress, d.MPE.SectionNumber, d.MPE.LastSectionNumber, len(d.MPE.Datagram))
+		} else if d.MPEFEC != nil && (logAll || logMPE) {
+			astilog.Infof("MPE-FEC: %d | column: %d/%d | padding columns: %d", d.PID, d.MPEFEC.SectionNumber, d.MPEFEC.LastSectionNumber, d.MPEFEC.PaddingColumns)
+		} else if d.MGT != nil && (logAll || logMGT) {
+			astilog.Infof("MGT: %d", d.PID)
+			for _, t := range d.MGT.Tables {
+				astilog.Infof("table type 0x%x | PID %d | version %d", t.Type, t.PID, t.VersionNumber)
+			}
+		} else if d.NBIT != nil && (logAll || logNBIT) {
+			astilog.Infof("NBIT: %d | original network: %d", d.PID, d.NBIT.OriginalNetworkID)
+			for _, n := range d.NBIT.Informations {
+				astilog.Infof("- information %d | type %d", n.InformationID, n.InformationType)
+			}
 		} else if d.NIT != nil && (logAll || logNIT) {
 			astilog.Infof("NIT: %d", d.PID)
 		} else if d.PAT != nil && (logAll || logPAT) {
@@ -217,10 +375,60 @@ func data(dmx *astits.Demuxer) (err error) {
 			astilog.Infof("PES: %d", d.PID)
 		} else if d.PMT != nil && (logAll || logPMT) {
 			astilog.Infof("PMT: %d", d.PID)
+		} else if d.RRT != nil && (logAll || logRRT) {
+			astilog.Infof("RRT: %d | rating region %d: %s", d.PID, d.RRT.RatingRegion, d.RRT.RatingRegionName)
+		} else if d.RST != nil && (logAll || logRST) {
+			astilog.Infof("RST: %d", d.PID)
+			for _, e := range d.RST.Events {
+				astilog.Infof("service %d | event %d | running status %d", e.ServiceID, e.EventID, e.RunningStatus)
+			}
+		} else if d.SCTE35 != nil && (logAll || logSCTE35) {
+			astilog.Infof("SCTE35: %d | splice command type %d | encrypted: %v", d.PID, d.SCTE35.SpliceCommandType, d.SCTE35.EncryptedPacket)
+			if d.SCTE35.SpliceInsert != nil {
+				astilog.Infof("splice insert | event %d | cancel: %v | out of network: %v", d.SCTE35.SpliceInsert.SpliceEventID, d.SCTE35.SpliceInsert.SpliceEventCancel, d.SCTE35.SpliceInsert.OutOfNetwork)
+			}
+			for _, dsc := range d.SCTE35.Descriptors {
+				if dsc.Segmentation != nil {
+					astilog.Infof("segmentation | event %d | type 0x%x | segment %d/%d", dsc.Segmentation.SegmentationEventID, dsc.Segmentation.SegmentationTypeID, dsc.Segmentation.SegmentNum, dsc.Segmentation.SegmentsExpected)
+				}
+			}
 		} else if d.SDT != nil && (logAll || logSDT) {
 			astilog.Infof("SDT: %d", d.PID)
+		} else if d.SIT != nil && (logAll || logSIT) {
+			astilog.Infof("SIT: %d", d.PID)
+		} else if d.ST != nil && (logAll || logST) {
+			astilog.Infof("ST: %d", d.PID)
+		} else if d.STT != nil && (logAll || logSTT) {
+			astilog.Infof("STT: %d | UTC time: %s", d.PID, d.STT.UTCTime)
+		} else if d.T2MI != nil && (logAll || logT2MI) {
+			astilog.Infof("T2-MI: %d", d.PID)
+			for _, p := range d.T2MI.Packets {
+				switch {
+				case p.BBFrame != nil:
+					astilog.Infof("  BBFrame | stream id: %d | plp id: %d | frame idx: %d | ts packets: %d", p.StreamID, p.BBFrame.PLPID, p.BBFrame.FrameIndex, len(p.BBFrame.TSPackets))
+				case p.L1Current != nil:
+					astilog.Infof("  L1-current | stream id: %d | frame idx: %d | network id: %d | t2 system id: %d | cell id: %d", p.StreamID, p.L1Current.FrameIndex, p.L1Current.L1Pre.NetworkID, p.L1Current.L1Pre.T2SystemID, p.L1Current.L1Pre.CellID)
+				default:
+					astilog.Infof("  Packet type: 0x%x | stream id: %d | length: %d", p.Type, p.StreamID, len(p.Payload))
+				}
+			}
+		} else if d.TDT != nil && (logAll || logTDT) {
+			astilog.Infof("TDT: %d | utc time: %s", d.PID, d.TDT.UTCTime)
 		} else if d.TOT != nil && (logAll || logTOT) {
-			astilog.Infof("TOT: %d", d.PID)
+			astilog.Infof("TOT: %d | utc time: %s", d.PID, d.TOT.UTCTime)
+		} else if d.TSDT != nil && (logAll || logTSDT) {
+			astilog.Infof("TSDT: %d", d.PID)
+			for _, dsc := range d.TSDT.Descriptors {
+				astilog.Info(descriptorToString(dsc))
+			}
+		} else if d.TVCT != nil && (logAll || logTVCT) {
+			astilog.Infof("TVCT: %d", d.PID)
+			astilog.Info(channelsToString(d.TVCT.Channels))
+		} else if d.ULE != nil && (logAll || logULE) {
+			astilog.Infof("ULE: %d", d.PID)
+			for _, s := range d.ULE.SNDUs {
+				astilog.Infof("  type: 0x%x | destination address: %x | length: %d", s.Type, s.DestinationAddress, len(s.PDU))
+			}
 		}
 	}
 	return
@@ -339,6 +547,14 @@ func (s Stream) String() (o string) {
 	// Get type
 	var t = fmt.Sprintf("unlisted stream type %d", s.Type)
 	switch s.Type {
+	case astits.StreamTypeAC3Audio:
+		t = "AC-3 audio"
+	case astits.StreamTypeADTSAudio:
+		t = "AAC audio"
+	case astits.StreamTypeEAC3Audio:
+		t = "E-AC-3 audio"
+	case astits.StreamTypeHEVCVideo:
+		t = "HEVC video"
 	case astits.StreamTypeLowerBitrateVideo:
 		t = "Lower bitrate video"
 	case astits.StreamTypeMPEG1Audio:
@@ -357,6 +573,14 @@ func (s Stream) String() (o string) {
 	return
 }
 
+func multilingualNameToString(d *astits.DescriptorMultilingualName) string {
+	var os []string
+	for _, i := range d.Items {
+		os = append(os, fmt.Sprintf("language: %s | name: %s", i.ISO639LanguageCode, i.DecodedName))
+	}
+	return strings.Join(os, " - ")
+}
+
 func eventsToString(es []*astits.EITDataEvent) string {
 	var os []string
 	for idx, e := range es {
@@ -371,9 +595,20 @@ func eventToString(idx int, e *astits.EITDataEvent) (s string) {
 	for _, d := range e.Descriptors {
 		os = append(os, "  - "+descriptorToString(d))
 	}
+	for _, x := range e.ExtendedEvents() {
+		os = append(os, fmt.Sprintf("  - [Reassembled extended event] language: %s | complete: %v | text: %s", x.Language, x.Complete, x.DecodedText))
+	}
 	return s + strings.Join(os, "\n")
 }
 
+func channelsToString(cs []*astits.VCTDataChannel) string {
+	var os []string
+	for _, c := range cs {
+		os = append(os, fmt.Sprintf("- %d.%d %s | program: %d | source: %d", c.MajorChannelNumber, c.MinorChannelNumber, c.ShortName, c.ProgramNumber, c.SourceID))
+	}
+	return strings.Join(os, "\n")
+}
+
 func runningStatusToString(s uint8) string {
 	switch s {
 	case astits.RunningStatusNotRunning:
@@ -390,38 +625,88 @@ func descriptorToString(d *astits.Descriptor) string {
 	switch d.Tag {
 	case astits.DescriptorTagAC3:
 		return fmt.Sprintf("[AC3] ac3 asvc: %d | bsid: %d | component type: %d | mainid: %d | info: %s", d.AC3.ASVC, d.AC3.BSID, d.AC3.ComponentType, d.AC3.MainID, d.AC3.AdditionalInfo)
+	case astits.DescriptorTagAudioStream:
+		return fmt.Sprintf("[Audio stream] id: %d | layer: %d | free format: %v | variable rate: %v", d.AudioStream.ID, d.AudioStream.Layer, d.AudioStream.FreeFormatFlag, d.AudioStream.VariableRateAudioIndicator)
+	case astits.DescriptorTagAVCVideo:
+		return fmt.Sprintf("[AVC video] profile: %s | level: %.1f | still present: %v | 24 hour picture: %v | frame packing sei not present: %v", d.AVCVideo.ProfileName(), d.AVCVideo.Level(), d.AVCVideo.AVCStillPresent, d.AVCVideo.AVC24HourPictureFlag, d.AVCVideo.FramePackingSEINotPresentFlag)
+	case astits.DescriptorTagCA:
+		return fmt.Sprintf("[CA] ca system id: 0x%x (%s) | ca pid: %d | private data length: %d", d.CA.CASystemID, d.CA.CASystemName(), d.CA.CAPID, len(d.CA.PrivateData))
+	case astits.DescriptorTagCableDeliverySystem:
+		return fmt.Sprintf("[Cable delivery system] frequency: %d Hz | symbol rate: %d | modulation: %d | fec inner: %d | fec outer: %d", d.CableDeliverySystem.Frequency, d.CableDeliverySystem.SymbolRate, d.CableDeliverySystem.Modulation, d.CableDeliverySystem.FECInner, d.CableDeliverySystem.FECOuter)
 	case astits.DescriptorTagComponent:
-		return fmt.Sprintf("[Component] language: %s | text: %s | component tag: %d | component type: %d | stream content: %d | stream content ext: %d", d.Component.ISO639LanguageCode, d.Component.Text, d.Component.ComponentTag, d.Component.ComponentType, d.Component.StreamContent, d.Component.StreamContentExt)
+		return fmt.Sprintf("[Component] language: %s | text: %s | component tag: %d | component type: %d | stream content: %d | stream content ext: %d | %s", d.Component.ISO639LanguageCode, d.Component.DecodedText, d.Component.ComponentTag, d.Component.ComponentType, d.Component.StreamContent, d.Component.StreamContentExt, d.Component.Info())
 	case astits.DescriptorTagContent:
 		var os []string
 		for _, i := range d.Content.Items {
-			os = append(os, fmt.Sprintf("content nibble 1: %d | content nibble 2: %d | user byte: %d", i.ContentNibbleLevel1, i.ContentNibbleLevel2, i.UserByte))
+			os = append(os, fmt.Sprintf("genre: %s | subgenre: %s | user byte: %d", i.Genre(), i.Subgenre(), i.UserByte))
 		}
 		return "[Content] " + strings.Join(os, " - ")
 	case astits.DescriptorTagExtendedEvent:
-		s := fmt.Sprintf("[Extended event] language: %s | text: %s", d.ExtendedEvent.ISO639LanguageCode, d.ExtendedEvent.Text)
+		s := fmt.Sprintf("[Extended event] language: %s | text: %s", d.ExtendedEvent.ISO639LanguageCode, d.ExtendedEvent.DecodedText)
 		for _, i := range d.ExtendedEvent.Items {
-			s += fmt.Sprintf(" | %s: %s", i.Description, i.Content)
+			s += fmt.Sprintf(" | %s: %s", i.DecodedDescription, i.DecodedContent)
 		}
 		return s
+	case astits.DescriptorTagHDSimulcastLogicalChannel:
+		if d.HDSimulcastLogicalChannel != nil {
+			var os []string
+			for _, i := range d.HDSimulcastLogicalChannel.Items {
+				os = append(os, fmt.Sprintf("service id: %d | lcn: %d | visible: %v", i.ServiceID, i.LogicalChannelNumber, i.VisibleServiceFlag))
+			}
+			return "[HD simulcast logical channel] " + strings.Join(os, " - ")
+		}
+	case astits.DescriptorTagHEVCVideo:
+		return fmt.Sprintf("[HEVC video] profile: %s | level: %.1f | high tier: %v | still present: %v | 24 hour picture: %v | progressive: %v | interlaced: %v", d.HEVCVideo.ProfileName(), d.HEVCVideo.Level(), d.HEVCVideo.TierFlag, d.HEVCVideo.HEVCStillPresentFlag, d.HEVCVideo.HEVC24HourPicturePresentFlag, d.HEVCVideo.ProgressiveSourceFlag, d.HEVCVideo.InterlacedSourceFlag)
 	case astits.DescriptorTagISO639LanguageAndAudioType:
 		return fmt.Sprintf("[ISO639 language and audio type] language: %s | audio type: %d", d.ISO639LanguageAndAudioType.Language, d.ISO639LanguageAndAudioType.Type)
+	case astits.DescriptorTagLogicalChannel:
+		if d.LogicalChannel != nil {
+			var os []string
+			for _, i := range d.LogicalChannel.Items {
+				os = append(os, fmt.Sprintf("service id: %d | lcn: %d | visible: %v", i.ServiceID, i.LogicalChannelNumber, i.VisibleServiceFlag))
+			}
+			return "[Logical channel] " + strings.Join(os, " - ")
+		}
 	case astits.DescriptorTagMaximumBitrate:
 		return fmt.Sprintf("[Maximum bitrate] maximum bitrate: %d", d.MaximumBitrate.Bitrate)
+	case astits.DescriptorTagMultilingualBouquetName:
+		return "[Multilingual bouquet name] " + multilingualNameToString(d.MultilingualBouquetName)
+	case astits.DescriptorTagMultilingualComponent:
+		var os []string
+		for _, i := range d.MultilingualComponent.Items {
+			os = append(os, fmt.Sprintf("language: %s | text: %s", i.ISO639LanguageCode, i.DecodedText))
+		}
+		return fmt.Sprintf("[Multilingual component] component tag: %d | ", d.MultilingualComponent.ComponentTag) + strings.Join(os, " - ")
+	case astits.DescriptorTagMultilingualNetworkName:
+		return "[Multilingual network name] " + multilingualNameToString(d.MultilingualNetworkName)
+	case astits.DescriptorTagMultilingualServiceName:
+		var os []string
+		for _, i := range d.MultilingualServiceName.Items {
+			os = append(os, fmt.Sprintf("language: %s | provider: %s | name: %s", i.ISO639LanguageCode, i.DecodedProvider, i.DecodedName))
+		}
+		return "[Multilingual service name] " + strings.Join(os, " - ")
 	case astits.DescriptorTagNetworkName:
-		return fmt.Sprintf("[Network name] network name: %s", d.NetworkName.Name)
+		return fmt.Sprintf("[Network name] network name: %s", d.NetworkName.DecodedName)
 	case astits.DescriptorTagParentalRating:
 		var os []string
 		for _, i := range d.ParentalRating.Items {
-			os = append(os, fmt.Sprintf("country: %s | rating: %d | minimum age: %d", i.CountryCode, i.Rating, i.MinimumAge()))
+			if i.IsUserDefined() {
+				os = append(os, fmt.Sprintf("country: %s | rating: %d | user defined", i.CountryCode, i.Rating))
+			} else {
+				os = append(os, fmt.Sprintf("country: %s | rating: %d | minimum age: %d", i.CountryCode, i.Rating, i.MinimumAge()))
+			}
 		}
 		return "[Parental rating] " + strings.Join(os, " - ")
 	case astits.DescriptorTagPrivateDataSpecifier:
 		return fmt.Sprintf("[Private data specifier] specifier: %d", d.PrivateDataSpecifier.Specifier)
+	case astits.DescriptorTagRegistration:
+		return fmt.Sprintf("[Registration] format identifier: %s | additional identification info length: %d", d.Registration.FourCC(), len(d.Registration.AdditionalIdentificationInfo))
+	case astits.DescriptorTagSatelliteDeliverySystem:
+		return fmt.Sprintf("[Satellite delivery system] frequency: %d Hz | symbol rate: %d | polarization: %d | orbital position: %d | east: %v | modulation system: %d | modulation type: %d | fec inner: %d", d.SatelliteDeliverySystem.Frequency, d.SatelliteDeliverySystem.SymbolRate, d.SatelliteDeliverySystem.Polarization, d.SatelliteDeliverySystem.OrbitalPosition, d.SatelliteDeliverySystem.WestEastFlag, d.SatelliteDeliverySystem.ModulationSystem, d.SatelliteDeliverySystem.ModulationType, d.SatelliteDeliverySystem.FECInner)
 	case astits.DescriptorTagService:
-		return fmt.Sprintf("[Service] service %s | provider: %s", d.Service.Name, d.Service.Provider)
+		return fmt.Sprintf("[Service] service %s | provider: %s", d.Service.DecodedName, d.Service.DecodedProvider)
 	case astits.DescriptorTagShortEvent:
-		return fmt.Sprintf("[Short event] language: %s | name: %s | text: %s", d.ShortEvent.Language, d.ShortEvent.EventName, d.ShortEvent.Text)
+		return fmt.Sprintf("[Short event] language: %s | name: %s | text: %s", d.ShortEvent.Language, d.ShortEvent.DecodedEventName, d.ShortEvent.DecodedText)
 	case astits.DescriptorTagStreamIdentifier:
 		return fmt.Sprintf("[Stream identifier] stream identifier component tag: %d", d.StreamIdentifier.ComponentTag)
 	case astits.DescriptorTagSubtitling:
@@ -436,6 +721,10 @@ func descriptorToString(d *astits.Descriptor) string {
 			os = append(os, fmt.Sprintf("Teletext page %01d%02d: %s", t.Magazine, t.Page, t.Language))
 		}
 		return "[Teletext] " + strings.Join(os, " - ")
+	case astits.DescriptorTagTerrestrialDeliverySystem:
+		return fmt.Sprintf("[Terrestrial delivery system] frequency: %d Hz | bandwidth: %d | constellation: %d | code rate hp: %d | guard interval: %d | transmission mode: %d", d.TerrestrialDeliverySystem.Frequency, d.TerrestrialDeliverySystem.Bandwidth, d.TerrestrialDeliverySystem.Constellation, d.TerrestrialDeliverySystem.CodeRateHPStream, d.TerrestrialDeliverySystem.GuardInterval, d.TerrestrialDeliverySystem.TransmissionMode)
+	case astits.DescriptorTagVideoStream:
+		return fmt.Sprintf("[Video stream] frame rate: %.3f | multiple frame rate: %v | mpeg-1 only: %v | still picture: %v | profile and level: 0x%x | chroma format: %d", d.VideoStream.FrameRate(), d.VideoStream.MultipleFrameRateFlag, d.VideoStream.MPEG1OnlyFlag, d.VideoStream.StillPictureFlag, d.VideoStream.ProfileAndLevelIndication, d.VideoStream.ChromaFormat)
 	}
 	return fmt.Sprintf("unlisted descriptor tag 0x%x", d.Tag)
 }
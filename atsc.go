@@ -0,0 +1,182 @@
+package astits
+
+import (
+	"time"
+	"unicode/utf16"
+)
+
+// ATSC multiple string structure modes
+// Page: 79 | Chapter: 6.10 | Link: https://www.atsc.org/wp-content/uploads/2015/03/Program-System-Information-Protocol-for-Terrestrial-Broadcast-and-Cable.pdf
+const (
+	ATSCStringModeSCSU    = 0x3e
+	ATSCStringModeUTF16   = 0x3f
+	atscStringModeMaximum = 0x33 // Greatest mode selecting a Unicode page
+)
+
+// atscGPSEpoch is the time 0 of the ATSC system time
+var atscGPSEpoch = time.Date(1980, 1, 6, 0, 0, 0, 0, time.UTC)
+
+// ATSCMultipleString represents an ATSC multiple string structure
+// Page: 78 | Chapter: 6.10 | Link: https://www.atsc.org/wp-content/uploads/2015/03/Program-System-Information-Protocol-for-Terrestrial-Broadcast-and-Cable.pdf
+type ATSCMultipleString struct {
+	Strings []*ATSCString
+}
+
+// ATSCString represents one language version of an ATSC multiple string structure
+type ATSCString struct {
+	Language []byte
+	Segments []*ATSCStringSegment
+}
+
+// ATSCStringSegment represents an ATSC string segment
+type ATSCStringSegment struct {
+	Bytes           []byte
+	CompressionType uint8
+	Mode            uint8
+}
+
+// parseATSCMultipleString parses an ATSC multiple string structure
+func parseATSCMultipleString(i []byte, offset *int) (m *ATSCMultipleString) {
+	// Init
+	m = &ATSCMultipleString{}
+
+	// Number of strings
+	var numberStrings = int(i[*offset])
+	*offset += 1
+
+	// Loop through strings
+	for idxString := 0; idxString < numberStrings; idxString++ {
+		// Language
+		var s = &ATSCString{Language: i[*offset : *offset+3]}
+		*offset += 3
+
+		// Number of segments
+		var numberSegments = int(i[*offset])
+		*offset += 1
+
+		// Loop through segments
+		for idxSegment := 0; idxSegment < numberSegments; idxSegment++ {
+			// Compression type
+			var sg = &ATSCStringSegment{CompressionType: uint8(i[*offset])}
+			*offset += 1
+
+			// Mode
+			sg.Mode = uint8(i[*offset])
+			*offset += 1
+
+			// Number of bytes
+			var numberBytes = int(i[*offset])
+			*offset += 1
+
+			// Bytes
+			sg.Bytes = i[*offset : *offset+numberBytes]
+			*offset += numberBytes
+
+			// Append segment
+			s.Segments = append(s.Segments, sg)
+		}
+
+		// Append string
+		m.Strings = append(m.Strings, s)
+	}
+	return
+}
+
+// parseATSCMultipleStringWithLength parses an ATSC multiple string structure preceded by its length in bytes
+// The offset is always moved to the end of the structure as stated by its length
+func parseATSCMultipleStringWithLength(i []byte, offset *int) (m *ATSCMultipleString) {
+	// Length
+	var length = int(i[*offset])
+	*offset += 1
+
+	// Multiple string structure
+	var offsetEnd = *offset + length
+	if length > 0 {
+		m = parseATSCMultipleString(i[:offsetEnd], offset)
+	}
+	*offset = offsetEnd
+	return
+}
+
+// parseATSCDescriptors parses descriptors whose length is coded on 10 bits as is the case in ATSC tables
+func parseATSCDescriptors(i []byte, offset *int) (o []*Descriptor) {
+	// Get length
+	var length = int(uint16(i[*offset]&0x3)<<8 | uint16(i[*offset+1]))
+	*offset += 2
+
+	// Loop
+	if length > 0 {
+		o = parseDescriptorsLoop(i, offset, *offset+length)
+	}
+	return
+}
+
+// String returns the text of the first language version of the multiple string structure
+func (m *ATSCMultipleString) String() string {
+	if m == nil || len(m.Strings) == 0 {
+		return ""
+	}
+	return m.Strings[0].String()
+}
+
+// Text returns the text of the multiple string structure in the provided language and whether it has been found
+func (m *ATSCMultipleString) Text(language string) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	for _, s := range m.Strings {
+		if string(s.Language) == language {
+			return s.String(), true
+		}
+	}
+	return "", false
+}
+
+// String returns the text of the string by concatenating its segments
+func (s *ATSCString) String() (o string) {
+	for _, sg := range s.Segments {
+		o += sg.String()
+	}
+	return
+}
+
+// String returns the text of the segment
+// Compressed segments as well as segments using SCSU or a reserved mode are not decoded and an empty string is
+// returned
+func (sg *ATSCStringSegment) String() string {
+	// Compressed
+	if sg.CompressionType != 0 {
+		return ""
+	}
+
+	// Switch on mode
+	switch {
+	case sg.Mode <= atscStringModeMaximum:
+		// Each byte is the least significant byte of a code point in the Unicode page selected by the mode
+		var rs = make([]rune, len(sg.Bytes))
+		for idx, b := range sg.Bytes {
+			rs[idx] = rune(sg.Mode)<<8 | rune(b)
+		}
+		return string(rs)
+	case sg.Mode == ATSCStringModeUTF16:
+		return parseUTF16String(sg.Bytes)
+	}
+	return ""
+}
+
+// parseATSCTime parses an ATSC time expressed as a number of GPS seconds since the GPS epoch
+// The returned time is a GPS time: it's ahead of UTC by the GPS UTC offset of the STT
+func parseATSCTime(i []byte, offset *int) time.Time {
+	var t = uint32(i[*offset])<<24 | uint32(i[*offset+1])<<16 | uint32(i[*offset+2])<<8 | uint32(i[*offset+3])
+	*offset += 4
+	return atscGPSEpoch.Add(time.Duration(t) * time.Second)
+}
+
+// parseUTF16String parses a big-endian UTF-16 string
+func parseUTF16String(i []byte) string {
+	var us = make([]uint16, len(i)/2)
+	for idx := range us {
+		us[idx] = uint16(i[2*idx])<<8 | uint16(i[2*idx+1])
+	}
+	return string(utf16.Decode(us))
+}
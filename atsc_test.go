@@ -0,0 +1,79 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/asticode/go-astitools/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+var atscMultipleString = &ATSCMultipleString{Strings: []*ATSCString{
+	{
+		Language: []byte("eng"),
+		Segments: []*ATSCStringSegment{
+			{Bytes: []byte("caf"), Mode: 0x0},
+			{Bytes: []byte{0xe9}, Mode: 0x0},
+		},
+	},
+	{
+		Language: []byte("fra"),
+		Segments: []*ATSCStringSegment{{Bytes: []byte{0x0, 0x63, 0x0, 0xe9}, Mode: ATSCStringModeUTF16}},
+	},
+}}
+
+func atscMultipleStringBytes(w *astibinary.Writer) {
+	w.Write(uint8(2))         // Number of strings
+	w.Write([]byte("eng"))    // String #1 language
+	w.Write(uint8(2))         // String #1 number of segments
+	w.Write(uint8(0))         // String #1 segment #1 compression type
+	w.Write(uint8(0))         // String #1 segment #1 mode
+	w.Write(uint8(3))         // String #1 segment #1 number of bytes
+	w.Write([]byte("caf"))    // String #1 segment #1 bytes
+	w.Write(uint8(0))         // String #1 segment #2 compression type
+	w.Write(uint8(0))         // String #1 segment #2 mode
+	w.Write(uint8(1))         // String #1 segment #2 number of bytes
+	w.Write(uint8(0xe9))      // String #1 segment #2 bytes
+	w.Write([]byte("fra"))    // String #2 language
+	w.Write(uint8(1))         // String #2 number of segments
+	w.Write(uint8(0))         // String #2 segment #1 compression type
+	w.Write(uint8(0x3f))      // String #2 segment #1 mode
+	w.Write(uint8(4))         // String #2 segment #1 number of bytes
+	w.Write(uint32(0x6300e9)) // String #2 segment #1 bytes
+}
+
+func TestParseATSCMultipleString(t *testing.T) {
+	w := astibinary.New()
+	atscMultipleStringBytes(w)
+	var offset int
+	var b = w.Bytes()
+	m := parseATSCMultipleString(b, &offset)
+	assert.Equal(t, atscMultipleString, m)
+	assert.Equal(t, len(b), offset)
+}
+
+func TestATSCMultipleStringText(t *testing.T) {
+	assert.Equal(t, "café", atscMultipleString.String())
+	s, ok := atscMultipleString.Text("fra")
+	assert.True(t, ok)
+	assert.Equal(t, "cé", s)
+	_, ok = atscMultipleString.Text("spa")
+	assert.False(t, ok)
+	assert.Equal(t, "", (&ATSCStringSegment{Bytes: []byte("test"), CompressionType: 1}).String())
+	assert.Equal(t, "А", (&ATSCStringSegment{Bytes: []byte{0x10}, Mode: 0x4}).String())
+	var m *ATSCMultipleString
+	assert.Equal(t, "", m.String())
+}
+
+func TestParseATSCDescriptors(t *testing.T) {
+	w := astibinary.New()
+	w.Write("111111")                             // Reserved
+	w.Write("0000000011")                         // Descriptors length
+	w.Write(uint8(DescriptorTagStreamIdentifier)) // Tag
+	w.Write(uint8(1))                             // Length
+	w.Write(uint8(7))                             // Component tag
+	var offset int
+	var b = w.Bytes()
+	ds := parseATSCDescriptors(b, &offset)
+	assert.Equal(t, descriptors, ds)
+	assert.Equal(t, len(b), offset)
+}
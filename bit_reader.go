@@ -0,0 +1,58 @@
+package astits
+
+import "fmt"
+
+// bitReader represents an object capable of reading bit fields that are not aligned on bytes, most significant bit
+// first
+type bitReader struct {
+	i      []byte
+	offset int // In bits
+}
+
+// newBitReader creates a new bit reader
+func newBitReader(i []byte) *bitReader {
+	return &bitReader{i: i}
+}
+
+// read reads n bits, n being 32 at most
+func (r *bitReader) read(n int) (v uint32, err error) {
+	// Check length
+	if r.offset+n > len(r.i)*8 {
+		err = fmt.Errorf("astits: bits end (%d) > len(i) (%d)", r.offset+n, len(r.i)*8)
+		return
+	}
+
+	// Loop through bits
+	for idx := 0; idx < n; idx++ {
+		v = v<<1 | uint32(r.i[r.offset/8]>>(7-uint(r.offset%8))&0x1)
+		r.offset++
+	}
+	return
+}
+
+// readBytes reads n bytes, skipping the bits left until the next byte first. Bytes point to the input
+func (r *bitReader) readBytes(n int) (b []byte, err error) {
+	// Check length
+	r.align()
+	if r.offset/8+n > len(r.i) {
+		err = fmt.Errorf("astits: bytes end (%d) > len(i) (%d)", r.offset/8+n, len(r.i))
+		return
+	}
+
+	// Read
+	b = r.i[r.offset/8 : r.offset/8+n]
+	r.offset += n * 8
+	return
+}
+
+// align skips the bits left until the next byte
+func (r *bitReader) align() {
+	if m := r.offset % 8; m > 0 {
+		r.offset += 8 - m
+	}
+}
+
+// byteOffset returns the offset of the next byte, bits already read in the current byte excluded
+func (r *bitReader) byteOffset() int {
+	return (r.offset + 7) / 8
+}
@@ -0,0 +1,39 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitReader(t *testing.T) {
+	r := newBitReader([]byte{0xa5, 0x3c})
+	v, err := r.read(3)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(0x5), v)
+	v, err = r.read(7)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(0x14), v)
+	assert.Equal(t, 2, r.byteOffset())
+	r.align()
+	assert.Equal(t, 2, r.byteOffset())
+	_, err = r.read(1)
+	assert.Error(t, err)
+
+	r = newBitReader([]byte{0xa5, 0x3c})
+	_, err = r.read(1)
+	assert.NoError(t, err)
+	r.align()
+	v, err = r.read(8)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(0x3c), v)
+
+	r = newBitReader([]byte{0xa5, 0x3c, 0x1})
+	_, err = r.read(1)
+	assert.NoError(t, err)
+	b, err := r.readBytes(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x3c, 0x1}, b)
+	_, err = r.readBytes(1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,67 @@
+package astits
+
+import "sort"
+
+// caSystemRange represents a range of CA system IDs allocated to a vendor
+type caSystemRange struct {
+	first, last uint16
+	name        string
+}
+
+// caSystemRanges are the ranges of CA system IDs allocated by DVB, sorted by first ID
+// Link: https://www.dvbservices.com/identifiers/ca_system_id
+var caSystemRanges = []caSystemRange{
+	{0x0001, 0x00ff, "Standardized systems"},
+	{0x0100, 0x01ff, "Canal+ (MediaGuard)"},
+	{0x0200, 0x02ff, "CCETT"},
+	{0x0300, 0x03ff, "MSG MediaServices"},
+	{0x0400, 0x04ff, "Eurodec"},
+	{0x0500, 0x05ff, "Viaccess"},
+	{0x0600, 0x06ff, "Irdeto"},
+	{0x0700, 0x07ff, "Motorola (DigiCipher 2)"},
+	{0x0800, 0x08ff, "Matra Communication"},
+	{0x0900, 0x09ff, "NDS (VideoGuard)"},
+	{0x0a00, 0x0aff, "Nokia"},
+	{0x0b00, 0x0bff, "Conax"},
+	{0x0c00, 0x0cff, "NTL"},
+	{0x0d00, 0x0dff, "Philips (CryptoWorks)"},
+	{0x0e00, 0x0eff, "Scientific Atlanta (PowerVu)"},
+	{0x0f00, 0x0fff, "Sony"},
+	{0x1000, 0x10ff, "Tandberg Television"},
+	{0x1100, 0x11ff, "Thomson"},
+	{0x1200, 0x12ff, "TV/Com"},
+	{0x1300, 0x13ff, "HPT - Croatian Post and Telecommunications"},
+	{0x1400, 0x14ff, "HRT - Croatian Radio and Television"},
+	{0x1500, 0x15ff, "IBM"},
+	{0x1600, 0x16ff, "Nera"},
+	{0x1700, 0x17ff, "BetaTechnik (BetaCrypt)"},
+	{0x1800, 0x18ff, "Nagravision"},
+	{0x1900, 0x19ff, "Titan Information Systems"},
+	{0x2000, 0x20ff, "Telefonica Servicios Audiovisuales"},
+	{0x2100, 0x21ff, "Stentor"},
+	{0x2200, 0x22ff, "Scopus Network Technologies"},
+	{0x2300, 0x23ff, "Barco"},
+	{0x2400, 0x24ff, "StarGuide Digital Networks"},
+	{0x2500, 0x25ff, "Mentor Data System"},
+	{0x2600, 0x26ff, "EBU (BISS)"},
+	{0x4700, 0x47ff, "General Instrument (DigiCipher)"},
+	{0x4800, 0x48ff, "Telemann (AccessGate)"},
+	{0x4900, 0x49ff, "CryptoWorks China"},
+	{0x4ae0, 0x4ae1, "DRE-Crypt"},
+	{0x5601, 0x5604, "Verimatrix"},
+}
+
+// CASystemName returns the name of the vendor a CA system ID is allocated to, or an empty string if it's not known
+func CASystemName(caSystemID uint16) string {
+	var idx = sort.Search(len(caSystemRanges), func(i int) bool { return caSystemRanges[i].last >= caSystemID })
+	if idx < len(caSystemRanges) && caSystemRanges[idx].first <= caSystemID {
+		return caSystemRanges[idx].name
+	}
+	return ""
+}
+
+// CASystemName returns the name of the vendor the CA system ID of the descriptor is allocated to, or an empty string
+// if it's not known
+func (d *DescriptorCA) CASystemName() string {
+	return CASystemName(d.CASystemID)
+}
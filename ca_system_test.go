@@ -0,0 +1,18 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCASystemName(t *testing.T) {
+	assert.Equal(t, "", CASystemName(0))
+	assert.Equal(t, "Standardized systems", CASystemName(0x1))
+	assert.Equal(t, "Viaccess", CASystemName(0x500))
+	assert.Equal(t, "Irdeto", CASystemName(0x6ff))
+	assert.Equal(t, "Nagravision", (&DescriptorCA{CASystemID: 0x1801}).CASystemName())
+	assert.Equal(t, "", CASystemName(0x4ae2))
+	assert.Equal(t, "Verimatrix", CASystemName(0x5604))
+	assert.Equal(t, "", CASystemName(0xffff))
+}
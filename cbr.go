@@ -0,0 +1,150 @@
+package astits
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// nullPacket is the null packet inserted to pad streams
+var nullPacket = writePacket(&Packet{
+	Header:  &PacketHeader{HasPayload: true, PID: PIDNull},
+	Payload: nullPacketPayload(),
+})
+
+// nullPacketPayload returns the payload of a null packet
+func nullPacketPayload() (b []byte) {
+	b = make([]byte, MpegTsPacketSize-4)
+	for idx := range b {
+		b[idx] = 0xff
+	}
+	return
+}
+
+// CBRWriter represents a writer shaping 188 bytes packets into a constant bitrate stream
+// Packets carrying a PCR of the reference PID are scheduled at the position matching their PCR at the output bitrate,
+// and null packets are inserted before them to pad the stream. The reference PID is the first PID carrying PCRs
+// unless set with CBRWriterOptPCRPID. When packets are written faster than their PCRs allow, they can't be delayed
+// and are written as is, which is counted as a late PCR.
+type CBRWriter struct {
+	bitrate       int // In bits per second
+	buf           []byte
+	first         int // First PCR of the reference PID, in 27 MHz ticks
+	firstPosition int64
+	hasPCR        bool
+	hasPCRPID     bool
+	last          int // Last PCR of the reference PID, in 27 MHz ticks
+	latePCRs      int64
+	nullPackets   int64
+	pcrPID        uint16 // Reference PID
+	position      int64  // Number of bytes written
+	unwrapper     *ClockUnwrapper
+	w             io.Writer
+}
+
+// NewCBRWriter creates a new CBR writer for an output bitrate in bits per second
+func NewCBRWriter(w io.Writer, bitrate int, opts ...func(*CBRWriter)) (cw *CBRWriter) {
+	cw = &CBRWriter{
+		bitrate:   bitrate,
+		unwrapper: NewClockUnwrapper(),
+		w:         w,
+	}
+	for _, opt := range opts {
+		opt(cw)
+	}
+	return
+}
+
+// CBRWriterOptPCRPID returns the option to set the PID whose PCRs packets are scheduled against
+func CBRWriterOptPCRPID(pid uint16) func(*CBRWriter) {
+	return func(w *CBRWriter) {
+		w.hasPCRPID = true
+		w.pcrPID = pid
+	}
+}
+
+// LatePCRs returns the number of PCRs that were written after the position matching them
+func (w *CBRWriter) LatePCRs() int64 {
+	return w.latePCRs
+}
+
+// NullPackets returns the number of null packets inserted
+func (w *CBRWriter) NullPackets() int64 {
+	return w.nullPackets
+}
+
+// Write writes 188 bytes packets, padded with null packets
+// Bytes that don't make a complete packet are buffered until the next write
+func (w *CBRWriter) Write(b []byte) (n int, err error) {
+	// Buffer bytes
+	n = len(b)
+	w.buf = append(w.buf, b...)
+
+	// Loop through packets
+	for len(w.buf) >= MpegTsPacketSize {
+		if err = w.writePacket(w.buf[:MpegTsPacketSize]); err != nil {
+			err = errors.Wrap(err, "astits: writing packet failed")
+			return
+		}
+		w.buf = w.buf[MpegTsPacketSize:]
+	}
+
+	// Release buffer
+	if len(w.buf) == 0 {
+		w.buf = nil
+	}
+	return
+}
+
+// writePacket pads the stream until the position matching the PCR of a packet, if any, and writes it
+func (w *CBRWriter) writePacket(b []byte) (err error) {
+	// Parse packet
+	var p *Packet
+	if p, err = parsePacket(b); err != nil {
+		err = errors.Wrap(err, "astits: parsing packet failed")
+		return
+	}
+
+	// Schedule packet
+	if p.Header.HasAdaptationField && p.AdaptationField != nil && p.AdaptationField.HasPCR && p.AdaptationField.PCR != nil &&
+		(!w.hasPCRPID || p.Header.PID == w.pcrPID) {
+		// First PCR or discontinuity
+		var c = w.unwrapper.Unwrap(p.AdaptationField.PCR).Ticks27MHz()
+		if !w.hasPCR || p.AdaptationField.DiscontinuityIndicator || c < w.last {
+			w.first = c
+			w.firstPosition = w.position
+			w.hasPCR = true
+			w.hasPCRPID = true
+			w.pcrPID = p.Header.PID
+		}
+		w.last = c
+
+		// Pad
+		var target = w.firstPosition + w.bytes(c-w.first)
+		if w.position > target {
+			w.latePCRs++
+		}
+		for w.position+MpegTsPacketSize <= target {
+			if _, err = w.w.Write(nullPacket); err != nil {
+				err = errors.Wrap(err, "astits: writing null packet failed")
+				return
+			}
+			w.nullPackets++
+			w.position += MpegTsPacketSize
+		}
+	}
+
+	// Write packet
+	if _, err = w.w.Write(b); err != nil {
+		err = errors.Wrap(err, "astits: writing packet failed")
+		return
+	}
+	w.position += MpegTsPacketSize
+	return
+}
+
+// bytes converts a number of 27 MHz ticks into the number of bytes transmitted during them at the output bitrate
+func (w *CBRWriter) bytes(ticks int) int64 {
+	var t, bitrate = int64(ticks), int64(w.bitrate)
+	return (t/27000000*bitrate + t%27000000*bitrate/27000000) / 8
+}
@@ -0,0 +1,72 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCBRWriter(t *testing.T) {
+	// Init
+	b := &bytes.Buffer{}
+	w := NewCBRWriter(b, MpegTsPacketSize*8*100)
+	write := func(pid uint16, pcr time.Duration) {
+		p := &Packet{Header: &PacketHeader{HasPayload: true, PID: pid}, Payload: []byte("payload")}
+		if pcr >= 0 {
+			p.AdaptationField = &PacketAdaptationField{HasPCR: true, PCR: NewClockReference27MHz(durationTo27MHzTicks(pcr))}
+			p.Header.HasAdaptationField = true
+		}
+		w.Write(writePacket(p))
+	}
+
+	// Packets are scheduled against PCRs
+	write(0x100, 0)
+	write(0x101, -1)
+	write(0x100, 100*time.Millisecond)
+	write(0x101, 50*time.Millisecond)
+	write(0x100, 200*time.Millisecond)
+	var pids []uint16
+	for i := b.Bytes(); len(i) >= MpegTsPacketSize; i = i[MpegTsPacketSize:] {
+		pid, _ := rawPacketPID(i)
+		pids = append(pids, pid)
+	}
+	assert.Len(t, pids, 21)
+	assert.Equal(t, uint16(0x101), pids[1])
+	assert.Equal(t, uint16(0x100), pids[10])
+	assert.Equal(t, uint16(0x101), pids[11])
+	assert.Equal(t, uint16(0x100), pids[20])
+	assert.Equal(t, int64(16), w.NullPackets())
+	assert.Equal(t, int64(0), w.LatePCRs())
+
+	// Late PCR
+	for i := 0; i < 20; i++ {
+		write(0x101, -1)
+	}
+	write(0x100, 300*time.Millisecond)
+	assert.Equal(t, int64(1), w.LatePCRs())
+
+	// Partial packets are buffered
+	b.Reset()
+	bs := writePacket(&Packet{Header: &PacketHeader{HasPayload: true, PID: 0x101}, Payload: []byte("payload")})
+	w.Write(bs[:100])
+	assert.Equal(t, 0, b.Len())
+	w.Write(bs[100:])
+	assert.Equal(t, bs, b.Bytes())
+}
+
+func TestMuxerOptConstantBitrate(t *testing.T) {
+	b := &bytes.Buffer{}
+	mx := NewMuxer(context.Background(), b, MuxerOptConstantBitrate(MpegTsPacketSize*8*100))
+	mx.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x100, StreamType: StreamTypeMPEG1Audio})
+	for idx := 0; idx < 2; idx++ {
+		mx.WriteData(&MuxerData{
+			AdaptationField: &PacketAdaptationField{HasPCR: true, PCR: NewClockReference27MHz(durationTo27MHzTicks(time.Duration(idx) * 100 * time.Millisecond))},
+			PES:             &PESData{Data: []byte("data"), Header: &PESHeader{OptionalHeader: &PESOptionalHeader{}, StreamID: 0xc0}},
+			PID:             0x100,
+		})
+	}
+	assert.Equal(t, 13*MpegTsPacketSize, b.Len())
+}
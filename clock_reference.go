@@ -10,6 +10,55 @@ type ClockReference struct {
 	Base, Extension int
 }
 
+// clockReferenceBaseWrap is the value at which 33 bits clock reference bases wrap, i.e. every 26.5 hours
+const clockReferenceBaseWrap = 1 << 33
+
+// wrapClockReferenceBase wraps a clock reference base at 33 bits
+func wrapClockReferenceBase(b int) int {
+	return (b%clockReferenceBaseWrap + clockReferenceBaseWrap) % clockReferenceBaseWrap
+}
+
+// ClockUnwrapper represents an object capable of unwrapping 33 bits clock reference bases into a continuous timeline
+// PCR, PTS and DTS of a same program can be unwrapped by the same unwrapper since they are close to each other
+type ClockUnwrapper struct {
+	hasLast bool
+	last    int // Last unwrapped base
+}
+
+// NewClockUnwrapper creates a new clock unwrapper
+func NewClockUnwrapper() *ClockUnwrapper {
+	return &ClockUnwrapper{}
+}
+
+// Unwrap returns a copy of the clock reference whose base is shifted by as many wraps as needed for it to be the
+// closest to the base unwrapped before. The first base is kept as is, and a nil clock reference returns nil
+func (u *ClockUnwrapper) Unwrap(c *ClockReference) *ClockReference {
+	// Nil
+	if c == nil {
+		return nil
+	}
+
+	// Unwrap
+	var b = c.Base
+	if u.hasLast {
+		// Start from the wrap of the last base
+		b += u.last - u.last%clockReferenceBaseWrap
+		if u.last < 0 && u.last%clockReferenceBaseWrap != 0 {
+			b -= clockReferenceBaseWrap
+		}
+
+		// Get the closest base
+		if b-u.last > clockReferenceBaseWrap/2 {
+			b -= clockReferenceBaseWrap
+		} else if u.last-b > clockReferenceBaseWrap/2 {
+			b += clockReferenceBaseWrap
+		}
+	}
+	u.hasLast = true
+	u.last = b
+	return newClockReference(b, c.Extension)
+}
+
 // newClockReference builds a new clock reference
 func newClockReference(base, extension int) *ClockReference {
 	return &ClockReference{
@@ -18,6 +67,91 @@ func newClockReference(base, extension int) *ClockReference {
 	}
 }
 
+// NewClockReference27MHz creates a new clock reference based on a number of 27 MHz ticks
+// The extension is always positive, even for a negative number of ticks
+func NewClockReference27MHz(ticks int) *ClockReference {
+	var base = ticks / 300
+	if ticks%300 < 0 {
+		base--
+	}
+	return newClockReference(base, ticks-base*300)
+}
+
+// NewClockReference90kHz creates a new clock reference based on a number of 90 kHz ticks
+func NewClockReference90kHz(ticks int) *ClockReference {
+	return newClockReference(ticks, 0)
+}
+
+// NewClockReferenceFromDuration creates a new clock reference based on a duration, rounded to the nearest 27 MHz tick
+func NewClockReferenceFromDuration(d time.Duration) *ClockReference {
+	return NewClockReference27MHz(durationTo27MHzTicks(d))
+}
+
+// durationTo27MHzTicks converts a duration into a number of 27 MHz ticks rounded to the nearest tick
+func durationTo27MHzTicks(d time.Duration) int {
+	var n = d.Nanoseconds() * 27
+	if n < 0 {
+		return int((n - 500) / 1000)
+	}
+	return int((n + 500) / 1000)
+}
+
+// ticks27MHzToDuration converts a number of 27 MHz ticks into a duration rounded to the nearest nanosecond
+func ticks27MHzToDuration(ticks int) time.Duration {
+	var n = int64(ticks) * 1000
+	if n < 0 {
+		return time.Duration((n - 13) / 27)
+	}
+	return time.Duration((n + 13) / 27)
+}
+
+// Ticks27MHz returns the number of 27 MHz ticks of the clock reference
+func (p ClockReference) Ticks27MHz() int {
+	return p.Base*300 + p.Extension
+}
+
+// Ticks90kHz returns the number of 90 kHz ticks of the clock reference, i.e. its base once its extension is folded
+func (p ClockReference) Ticks90kHz() int {
+	return p.Ticks27MHz() / 300
+}
+
+// Add returns a new clock reference shifted by a duration rounded to the nearest 27 MHz tick
+// The result isn't wrapped at 33 bits, which is done upon serialization
+func (p ClockReference) Add(d time.Duration) *ClockReference {
+	return NewClockReference27MHz(p.Ticks27MHz() + durationTo27MHzTicks(d))
+}
+
+// Sub returns the duration between the clock reference and another one
+func (p ClockReference) Sub(o ClockReference) time.Duration {
+	return ticks27MHzToDuration(p.Ticks27MHz() - o.Ticks27MHz())
+}
+
+// Before checks whether the clock reference is before another one
+func (p ClockReference) Before(o ClockReference) bool {
+	return p.Ticks27MHz() < o.Ticks27MHz()
+}
+
+// After checks whether the clock reference is after another one
+func (p ClockReference) After(o ClockReference) bool {
+	return p.Ticks27MHz() > o.Ticks27MHz()
+}
+
+// Equal checks whether the clock reference represents the same instant as another one
+func (p ClockReference) Equal(o ClockReference) bool {
+	return p.Ticks27MHz() == o.Ticks27MHz()
+}
+
+// SerializePCR serializes the clock reference as the 6 bytes PCR of an adaptation field
+func (p ClockReference) SerializePCR() []byte {
+	return writePCR(&p)
+}
+
+// SerializePTSOrDTS serializes the base of the clock reference as the 5 bytes PTS or DTS of a PES optional header
+// The 4 bits flag is 0x2 for a PTS alone, 0x3 for a PTS followed by a DTS and 0x1 for a DTS
+func (p ClockReference) SerializePTSOrDTS(flag uint8) []byte {
+	return writePTSOrDTS(flag, &p)
+}
+
 // Duration converts the clock reference into duration
 func (p ClockReference) Duration() time.Duration {
 	return time.Duration(p.Base*1e9/90000) + time.Duration(p.Extension*1e9/27000000)
@@ -13,3 +13,50 @@ func TestClockReference(t *testing.T) {
 	assert.Equal(t, 36344825768814*time.Nanosecond, clockReference.Duration())
 	assert.Equal(t, int64(36344), clockReference.Time().Unix())
 }
+
+func TestClockUnwrapper(t *testing.T) {
+	u := NewClockUnwrapper()
+	assert.Nil(t, u.Unwrap(nil))
+	var bs []int
+	for _, b := range []int{clockReferenceBaseWrap - 10, clockReferenceBaseWrap - 5, 3, clockReferenceBaseWrap - 2, 10, 1 << 32, 1<<32 + 1<<31, 5, 2} {
+		bs = append(bs, u.Unwrap(&ClockReference{Base: b}).Base)
+	}
+	assert.Equal(t, []int{clockReferenceBaseWrap - 10, clockReferenceBaseWrap - 5, clockReferenceBaseWrap + 3, clockReferenceBaseWrap - 2, clockReferenceBaseWrap + 10, clockReferenceBaseWrap + 1<<32, clockReferenceBaseWrap + 1<<32 + 1<<31, 2*clockReferenceBaseWrap + 5, 2*clockReferenceBaseWrap + 2}, bs)
+	assert.Equal(t, &ClockReference{Base: 2*clockReferenceBaseWrap + 1, Extension: 3}, u.Unwrap(&ClockReference{Base: 1, Extension: 3}))
+
+	// Bases before the first one
+	u = NewClockUnwrapper()
+	bs = []int{}
+	for _, b := range []int{10, clockReferenceBaseWrap - 5, clockReferenceBaseWrap - 3, 20} {
+		bs = append(bs, u.Unwrap(&ClockReference{Base: b}).Base)
+	}
+	assert.Equal(t, []int{10, -5, -3, 20}, bs)
+}
+
+func TestClockReferenceArithmetic(t *testing.T) {
+	// Ticks
+	assert.Equal(t, &ClockReference{Base: 2, Extension: 5}, NewClockReference27MHz(605))
+	assert.Equal(t, &ClockReference{Base: -3, Extension: 295}, NewClockReference27MHz(-605))
+	assert.Equal(t, &ClockReference{Base: 90000}, NewClockReference90kHz(90000))
+	assert.Equal(t, 605, ClockReference{Base: 2, Extension: 5}.Ticks27MHz())
+	assert.Equal(t, 2, ClockReference{Base: 2, Extension: 5}.Ticks90kHz())
+	assert.Equal(t, &ClockReference{Base: 90000, Extension: 150}, NewClockReferenceFromDuration(time.Second+time.Second/180000))
+
+	// Add and sub
+	c := ClockReference{Base: 90000, Extension: 299}
+	assert.Equal(t, &ClockReference{Base: 180001}, c.Add(time.Second+time.Second/27000000))
+	assert.Equal(t, &ClockReference{Base: 0, Extension: 299}, c.Add(-time.Second))
+	assert.Equal(t, time.Second, c.Add(time.Second).Sub(c))
+	assert.Equal(t, -time.Second, c.Sub(*c.Add(time.Second)))
+
+	// Comparison
+	assert.True(t, c.Before(ClockReference{Base: 90001}))
+	assert.False(t, c.Before(c))
+	assert.True(t, c.After(ClockReference{Base: 90000, Extension: 298}))
+	assert.True(t, c.Equal(ClockReference{Base: 89999, Extension: 599}))
+
+	// Serialization
+	assert.Equal(t, pcr, parsePCR(pcr.SerializePCR()))
+	assert.Equal(t, ptsClockReference, parsePTSOrDTS(ptsClockReference.SerializePTSOrDTS(0x2)))
+	assert.Equal(t, uint8(0x3), ptsClockReference.SerializePTSOrDTS(0x3)[0]>>4)
+}
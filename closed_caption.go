@@ -0,0 +1,272 @@
+package astits
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// Closed caption types
+// Page: 15 | Chapter: 4.4 | Link: CTA-708-E
+const (
+	ClosedCaptionTypeDTVCCPacketData  = 2
+	ClosedCaptionTypeDTVCCPacketStart = 3
+	ClosedCaptionTypeNTSCField1       = 0 // CEA-608 data of field 1, i.e. channels CC1 and CC2
+	ClosedCaptionTypeNTSCField2       = 1 // CEA-608 data of field 2, i.e. channels CC3 and CC4
+)
+
+// Closed caption constants
+// Page: 65 | Chapter: 6.7.3 | Link: ATSC A/53 Part 4
+const (
+	closedCaptionCountryCodeUnitedStates    = 0xb5
+	closedCaptionProviderCodeATSC           = 0x0031
+	closedCaptionServiceNumberExtended      = 7
+	closedCaptionServiceNumberNull          = 0
+	closedCaptionUserDataTypeCodeCCData     = 0x03
+	closedCaptionUserIdentifierATSC         = 0x47413934 // "GA94"
+	seiPayloadTypeUserDataRegisteredITUTT35 = 4
+)
+
+// ClosedCaption represents a cc_data construct, i.e. a pair of CEA-608 bytes or 2 bytes of a DTVCC packet
+// Page: 14 | Chapter: 4.4 | Link: CTA-708-E
+type ClosedCaption struct {
+	Data1 uint8
+	Data2 uint8
+	Type  uint8
+	Valid bool
+}
+
+// CaptionChannelPacket represents a CEA-708 caption channel packet, i.e. a DTVCC packet
+// Page: 22 | Chapter: 5 | Link: CTA-708-E
+type CaptionChannelPacket struct {
+	Data           []byte // Packet header excluded
+	SequenceNumber uint8
+	ServiceBlocks  []*CaptionServiceBlock
+}
+
+// CaptionServiceBlock represents a CEA-708 service block
+// Page: 24 | Chapter: 6.2 | Link: CTA-708-E
+type CaptionServiceBlock struct {
+	Data          []byte
+	ServiceNumber uint8
+}
+
+// ClosedCaptions returns the closed captions carried in the ATSC user data SEI messages of an H.264 access unit,
+// invalid ones included, in the order of transmission
+func (au *H264AccessUnit) ClosedCaptions() (cs []*ClosedCaption, err error) {
+	// Loop through NAL units
+	for _, n := range au.NALUnits {
+		// Only SEI NAL units are parsed
+		if n.Type != H264NALUnitTypeSEI {
+			continue
+		}
+
+		// Parse SEI messages
+		var ms []*seiMessage
+		if ms, err = parseSEIMessages(removeEmulationPreventionBytes(n.Data[1:])); err != nil {
+			err = errors.Wrap(err, "astits: parsing SEI messages failed")
+			return
+		}
+
+		// Loop through messages
+		for _, m := range ms {
+			// Only ATSC closed captions are parsed
+			if m.payloadType != seiPayloadTypeUserDataRegisteredITUTT35 {
+				continue
+			}
+			var mcs []*ClosedCaption
+			if mcs, err = parseATSCClosedCaptions(m.payload); err != nil {
+				err = errors.Wrap(err, "astits: parsing ATSC closed captions failed")
+				return
+			}
+			cs = append(cs, mcs...)
+		}
+	}
+	return
+}
+
+// seiMessage represents an SEI message
+// Page: 48 | Chapter: 7.3.2.3.1 | Link: https://www.itu.int/rec/T-REC-H.264
+type seiMessage struct {
+	payload     []byte
+	payloadType int
+}
+
+// parseSEIMessages parses the SEI messages of an SEI RBSP
+func parseSEIMessages(i []byte) (ms []*seiMessage, err error) {
+	var offset int
+	for offset < len(i) && i[offset] != 0x80 {
+		// Payload type and size
+		var t, s int
+		if t, offset, err = parseSEIMessageValue(i, offset); err != nil {
+			err = errors.Wrap(err, "astits: parsing SEI message payload type failed")
+			return
+		}
+		if s, offset, err = parseSEIMessageValue(i, offset); err != nil {
+			err = errors.Wrap(err, "astits: parsing SEI message payload size failed")
+			return
+		}
+
+		// Payload
+		if offset+s > len(i) {
+			err = fmt.Errorf("astits: SEI message payload end (%d) > len(i) (%d)", offset+s, len(i))
+			return
+		}
+		ms = append(ms, &seiMessage{payload: i[offset : offset+s], payloadType: t})
+		offset += s
+	}
+	return
+}
+
+// parseSEIMessageValue parses an SEI message payload type or size, coded as a sum of bytes
+func parseSEIMessageValue(i []byte, offset int) (v, o int, err error) {
+	for o = offset; o < len(i); o++ {
+		v += int(i[o])
+		if i[o] != 0xff {
+			o++
+			return
+		}
+	}
+	err = fmt.Errorf("astits: SEI message value end (%d) > len(i) (%d)", o+1, len(i))
+	return
+}
+
+// parseATSCClosedCaptions parses the closed captions of an ITU-T T.35 registered user data payload
+// Payloads that are not ATSC cc_data or whose process_cc_data_flag is not set are ignored
+// Page: 19 | Chapter: 6.2.3 | Link: ATSC A/53 Part 4
+func parseATSCClosedCaptions(i []byte) (cs []*ClosedCaption, err error) {
+	// Check header
+	if len(i) < 10 || i[0] != closedCaptionCountryCodeUnitedStates || uint16(i[1])<<8|uint16(i[2]) != closedCaptionProviderCodeATSC ||
+		uint32(i[3])<<24|uint32(i[4])<<16|uint32(i[5])<<8|uint32(i[6]) != closedCaptionUserIdentifierATSC ||
+		i[7] != closedCaptionUserDataTypeCodeCCData || i[8]&0x40 == 0 {
+		return
+	}
+
+	// Check length
+	var count = int(i[8] & 0x1f)
+	var offset = 10
+	if offset+count*3 > len(i) {
+		err = fmt.Errorf("astits: cc_data end (%d) > len(i) (%d)", offset+count*3, len(i))
+		return
+	}
+
+	// Loop through closed captions
+	for idx := 0; idx < count; idx++ {
+		cs = append(cs, &ClosedCaption{
+			Data1: i[offset+1],
+			Data2: i[offset+2],
+			Type:  i[offset] & 0x3,
+			Valid: i[offset]&0x4 > 0,
+		})
+		offset += 3
+	}
+	return
+}
+
+// removeEmulationPreventionBytes removes the 0x03 bytes following 0x0000 sequences in a NAL unit
+// Page: 63 | Chapter: 7.4.1 | Link: https://www.itu.int/rec/T-REC-H.264
+func removeEmulationPreventionBytes(i []byte) (o []byte) {
+	o = make([]byte, 0, len(i))
+	var zeros int
+	for _, b := range i {
+		if zeros >= 2 && b == 0x03 {
+			zeros = 0
+			continue
+		}
+		if b == 0 {
+			zeros++
+		} else {
+			zeros = 0
+		}
+		o = append(o, b)
+	}
+	return
+}
+
+// CaptionChannelPacketDecoder represents an object capable of assembling CEA-708 caption channel packets out of
+// closed captions, since packets usually span several pictures
+// Packets that are interrupted by the start of another packet are dropped
+type CaptionChannelPacketDecoder struct {
+	buf  []byte
+	size int
+}
+
+// NewCaptionChannelPacketDecoder creates a new caption channel packet decoder
+func NewCaptionChannelPacketDecoder() *CaptionChannelPacketDecoder {
+	return &CaptionChannelPacketDecoder{}
+}
+
+// Add adds closed captions and returns the caption channel packets they complete
+// CEA-608 and invalid closed captions are ignored
+func (d *CaptionChannelPacketDecoder) Add(cs ...*ClosedCaption) (ps []*CaptionChannelPacket) {
+	for _, c := range cs {
+		// Only valid DTVCC data are processed
+		if !c.Valid || (c.Type != ClosedCaptionTypeDTVCCPacketStart && c.Type != ClosedCaptionTypeDTVCCPacketData) {
+			continue
+		}
+
+		// Start packet
+		if c.Type == ClosedCaptionTypeDTVCCPacketStart {
+			// Packet size is coded in units of 2 bytes, 0 meaning 128 bytes
+			d.buf = d.buf[:0]
+			if d.size = int(c.Data1&0x3f) * 2; d.size == 0 {
+				d.size = 128
+			}
+		} else if d.size == 0 {
+			continue
+		}
+
+		// Append data
+		d.buf = append(d.buf, c.Data1, c.Data2)
+
+		// Packet is complete
+		if len(d.buf) >= d.size {
+			ps = append(ps, newCaptionChannelPacket(d.buf[:d.size]))
+			d.buf = d.buf[:0]
+			d.size = 0
+		}
+	}
+	return
+}
+
+// newCaptionChannelPacket parses a complete caption channel packet
+// Parsing of service blocks stops at the null service block or at a block that exceeds the packet
+func newCaptionChannelPacket(i []byte) (p *CaptionChannelPacket) {
+	// Init
+	p = &CaptionChannelPacket{
+		Data:           make([]byte, len(i)-1),
+		SequenceNumber: i[0] >> 6,
+	}
+	copy(p.Data, i[1:])
+
+	// Loop through service blocks
+	var offset int
+	for offset < len(p.Data) {
+		// Header
+		var n, size = p.Data[offset] >> 5, int(p.Data[offset] & 0x1f)
+		if n == closedCaptionServiceNumberNull {
+			break
+		}
+		offset++
+
+		// Extended service number
+		if n == closedCaptionServiceNumberExtended && size > 0 {
+			if offset >= len(p.Data) {
+				break
+			}
+			n = p.Data[offset] & 0x3f
+			offset++
+		}
+
+		// Data
+		if offset+size > len(p.Data) {
+			break
+		}
+		p.ServiceBlocks = append(p.ServiceBlocks, &CaptionServiceBlock{
+			Data:          p.Data[offset : offset+size],
+			ServiceNumber: n,
+		})
+		offset += size
+	}
+	return
+}
@@ -0,0 +1,79 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func closedCaptionSEIBytes(ccs ...byte) []byte {
+	var p = append([]byte{0xb5, 0x0, 0x31, 0x47, 0x41, 0x39, 0x34, 0x3, 0xc0 | uint8(len(ccs)/3), 0xff}, ccs...)
+	p = append(p, 0xff)
+	return append(append([]byte{0x0, 0x0, 0x1, 0x6, 0x5, 0x1, 0xaa, seiPayloadTypeUserDataRegisteredITUTT35, uint8(len(p))}, p...), 0x80)
+}
+
+func TestRemoveEmulationPreventionBytes(t *testing.T) {
+	assert.Equal(t, []byte{0x0, 0x0, 0x1, 0x0, 0x0, 0x3, 0x0, 0x3}, removeEmulationPreventionBytes([]byte{0x0, 0x0, 0x3, 0x1, 0x0, 0x0, 0x3, 0x3, 0x0, 0x3}))
+}
+
+func TestParseSEIMessages(t *testing.T) {
+	ms, err := parseSEIMessages([]byte{0xff, 0x1, 0x2, 0x1, 0x2, 0x5, 0x0, 0x80})
+	assert.NoError(t, err)
+	assert.Equal(t, []*seiMessage{{payload: []byte{0x1, 0x2}, payloadType: 256}, {payload: []byte{}, payloadType: 5}}, ms)
+	_, err = parseSEIMessages([]byte{0xff})
+	assert.Error(t, err)
+	_, err = parseSEIMessages([]byte{0x4, 0x2, 0x1})
+	assert.Error(t, err)
+}
+
+func TestH264AccessUnitClosedCaptions(t *testing.T) {
+	// Parse
+	au := ParseH264AccessUnit(append(closedCaptionSEIBytes(0xfc, 0x94, 0x2c, 0xff, 0x42, 0x22, 0xfa, 0x0, 0x0, 0xfe, 0x48, 0x69), 0x0, 0x0, 0x1, 0x65, 0x1))
+	cs, err := au.ClosedCaptions()
+	assert.NoError(t, err)
+	assert.Equal(t, []*ClosedCaption{
+		{Data1: 0x94, Data2: 0x2c, Type: ClosedCaptionTypeNTSCField1, Valid: true},
+		{Data1: 0x42, Data2: 0x22, Type: ClosedCaptionTypeDTVCCPacketStart, Valid: true},
+		{Type: ClosedCaptionTypeDTVCCPacketData},
+		{Data1: 0x48, Data2: 0x69, Type: ClosedCaptionTypeDTVCCPacketData, Valid: true},
+	}, cs)
+
+	// Not ATSC
+	cs, err = ParseH264AccessUnit([]byte{0x0, 0x0, 0x1, 0x6, 0x4, 0x3, 0xb5, 0x0, 0x2f, 0x80}).ClosedCaptions()
+	assert.NoError(t, err)
+	assert.Empty(t, cs)
+
+	// Errors
+	var b = closedCaptionSEIBytes(0xfc, 0x94, 0x2c)
+	b[17]++
+	_, err = ParseH264AccessUnit(b[:len(b)-3]).ClosedCaptions()
+	assert.Error(t, err)
+}
+
+func TestCaptionChannelPacketDecoder(t *testing.T) {
+	d := NewCaptionChannelPacketDecoder()
+
+	// Data without a packet start
+	assert.Empty(t, d.Add(&ClosedCaption{Data1: 0x1, Type: ClosedCaptionTypeDTVCCPacketData, Valid: true}))
+
+	// Packet spanning several additions, with an interrupted packet first
+	assert.Empty(t, d.Add(
+		&ClosedCaption{Data1: 0x3, Type: ClosedCaptionTypeDTVCCPacketStart, Valid: true},
+		&ClosedCaption{Data1: 0x94, Type: ClosedCaptionTypeNTSCField1, Valid: true},
+		&ClosedCaption{Data1: 0x84, Data2: 0xe1, Type: ClosedCaptionTypeDTVCCPacketStart, Valid: true},
+	))
+	ps := d.Add(
+		&ClosedCaption{Data1: 0xff, Data2: 0xff, Type: ClosedCaptionTypeDTVCCPacketData},
+		&ClosedCaption{Data1: 0x8, Data2: 0x45, Type: ClosedCaptionTypeDTVCCPacketData, Valid: true},
+		&ClosedCaption{Data1: 0x22, Data2: 0x1, Type: ClosedCaptionTypeDTVCCPacketData, Valid: true},
+		&ClosedCaption{Data1: 0x2, Data2: 0x0, Type: ClosedCaptionTypeDTVCCPacketData, Valid: true},
+	)
+	assert.Equal(t, []*CaptionChannelPacket{{
+		Data:           []byte{0xe1, 0x8, 0x45, 0x22, 0x1, 0x2, 0x0},
+		SequenceNumber: 2,
+		ServiceBlocks: []*CaptionServiceBlock{
+			{Data: []byte{0x45}, ServiceNumber: 8},
+			{Data: []byte{0x1, 0x2}, ServiceNumber: 1},
+		},
+	}}, ps)
+}
@@ -0,0 +1,208 @@
+package astits
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Component kinds
+const (
+	ComponentKindAudio     = "audio"
+	ComponentKindData      = "data"
+	ComponentKindSubtitles = "subtitles"
+	ComponentKindTeletext  = "teletext"
+	ComponentKindVideo     = "video"
+)
+
+// DescriptorComponentInfo represents the interpretation of the stream content and of the component type of a component
+// descriptor
+// Components whose component type is reserved, or not defined by the stream content, only have their kind and their
+// codec set
+// Page: 52 | Chapter: 6.2.8 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+type DescriptorComponentInfo struct {
+	AspectRatio      string // Aspect ratio of the video, or of the video the subtitles are intended for, if signaled
+	AudioDescription bool   // Whether the audio describes the video for the visually impaired
+	AudioMode        string // Such as "mono", "dual mono", "stereo" or "surround"
+	Codec            string // Such as "MPEG-2 video" or "HE-AAC audio"
+	FrameRate        int    // Frame rate of the video in Hz, if signaled
+	HardOfHearing    bool   // Whether the audio or the subtitles are intended for the hard of hearing
+	HD               bool
+	Kind             string // One of the component kinds, or empty when the stream content is reserved
+	SubtitleType     string // "EBU teletext", "DVB" or "TTML"
+	UHD              bool
+}
+
+// componentVideoAspectRatios are the aspect ratios of MPEG-2 and H.264/AVC video
+// Component types 0x1 to 0x10 are made of 4 aspect ratios at 25 Hz and then at 30 Hz, in SD and then in HD
+var componentVideoAspectRatios = []string{"4:3", "16:9", "16:9", "> 16:9"}
+
+// componentH264VideoTypes are the component types used by H.264/AVC video
+var componentH264VideoTypes = map[uint8]bool{0x1: true, 0x3: true, 0x4: true, 0x5: true, 0x7: true, 0x8: true, 0xb: true, 0xc: true, 0xf: true, 0x10: true}
+
+// componentAudioModes are the audio modes of MPEG-1 Layer 2 and HE-AAC audio
+var componentAudioModes = map[uint8]string{0x1: "mono", 0x2: "dual mono", 0x3: "stereo", 0x4: "multi-lingual multi-channel", 0x5: "surround"}
+
+// componentAC3AudioModes are the audio modes of AC-3 audio indexed by the number of channels bits
+var componentAC3AudioModes = []string{"mono", "dual mono", "stereo", "stereo surround", "multichannel", "multichannel > 5.1", "multiple substreams", "reserved"}
+
+// Info returns the interpretation of the stream content and of the component type
+func (d *DescriptorComponent) Info() (i *DescriptorComponentInfo) {
+	// Init
+	i = &DescriptorComponentInfo{}
+	var t = d.ComponentType
+
+	// Switch on stream content
+	switch d.StreamContent {
+	case 0x1:
+		i.Codec, i.Kind = "MPEG-2 video", ComponentKindVideo
+		if t >= 0x1 && t <= 0x10 {
+			i.setVideo(t)
+		}
+	case 0x2, 0x6:
+		i.Codec, i.Kind = "MPEG-1 Layer 2 audio", ComponentKindAudio
+		if d.StreamContent == 0x6 {
+			i.Codec = "HE-AAC audio"
+		}
+		i.setAudio(d.StreamContent, t)
+	case 0x3:
+		i.setSubtitles(t)
+	case 0x4:
+		i.Codec, i.Kind = "AC-3 audio", ComponentKindAudio
+		if t&0x80 > 0 {
+			i.Codec = "E-AC-3 audio"
+		}
+		i.AudioMode = componentAC3AudioModes[t&0x7]
+		switch (t >> 3) & 0x7 {
+		case 0x2:
+			i.AudioDescription = true
+		case 0x3:
+			i.HardOfHearing = true
+		}
+	case 0x5:
+		i.Codec, i.Kind = "H.264/AVC video", ComponentKindVideo
+		if componentH264VideoTypes[t] {
+			i.setVideo(t)
+		} else if t >= 0x80 && t <= 0x84 {
+			// Plano-stereoscopic video is always HD 16:9
+			i.AspectRatio, i.HD = "16:9", true
+		}
+	case 0x7:
+		i.Codec, i.Kind = "DTS audio", ComponentKindAudio
+	case 0x8:
+		i.Codec, i.Kind = "DVB SRM and CPCM data", ComponentKindData
+	case 0x9:
+		switch d.StreamContentExt {
+		case 0x0:
+			i.Codec, i.Kind = "HEVC video", ComponentKindVideo
+			if t < 0x4 {
+				i.FrameRate, i.HD = 50+10*int(t>>1), true
+			} else if t == 0x4 {
+				i.UHD = true
+			}
+		case 0x1:
+			i.Codec, i.Kind = "AC-4 audio", ComponentKindAudio
+		case 0x2:
+			i.Codec, i.Kind, i.SubtitleType = "TTML subtitles", ComponentKindSubtitles, "TTML"
+		}
+	}
+	return
+}
+
+// setVideo interprets the component types of MPEG-2 and H.264/AVC video
+func (i *DescriptorComponentInfo) setVideo(t uint8) {
+	var idx = int(t) - 1
+	i.AspectRatio = componentVideoAspectRatios[idx%4]
+	i.FrameRate = 25
+	if idx%8 >= 4 {
+		i.FrameRate = 30
+	}
+	i.HD = idx >= 8
+}
+
+// setAudio interprets the component types of MPEG-1 Layer 2 and HE-AAC audio
+func (i *DescriptorComponentInfo) setAudio(streamContent, t uint8) {
+	// HE-AAC v2
+	if streamContent == 0x6 && (t == 0x43 || t == 0x44 || t == 0x45 || t == 0x46 || t == 0x49 || t == 0x4a) {
+		i.Codec = "HE-AAC v2 audio"
+	}
+
+	// Switch on component type
+	switch t {
+	case 0x40, 0x44, 0x47, 0x48, 0x49, 0x4a:
+		i.AudioDescription = true
+	case 0x41, 0x45:
+		i.HardOfHearing = true
+	case 0x43:
+		i.AudioMode = "stereo"
+	default:
+		i.AudioMode = componentAudioModes[t]
+	}
+}
+
+// setSubtitles interprets the component types of subtitles and teletext
+func (i *DescriptorComponentInfo) setSubtitles(t uint8) {
+	switch {
+	case t == 0x1:
+		i.Codec, i.Kind, i.SubtitleType = "EBU teletext subtitles", ComponentKindSubtitles, "EBU teletext"
+	case t == 0x2:
+		i.Codec, i.Kind = "EBU teletext", ComponentKindTeletext
+	case t == 0x3:
+		i.Codec, i.Kind = "VBI data", ComponentKindData
+	case t >= 0x10 && t <= 0x15, t >= 0x20 && t <= 0x25:
+		i.Codec, i.Kind, i.SubtitleType = "DVB subtitles", ComponentKindSubtitles, "DVB"
+		i.HardOfHearing = t >= 0x20
+		switch t & 0xf {
+		case 0x1:
+			i.AspectRatio = "4:3"
+		case 0x2:
+			i.AspectRatio = "16:9"
+		case 0x3:
+			i.AspectRatio = "2.21:1"
+		case 0x4, 0x5:
+			i.HD = true
+		}
+	case t == 0x30, t == 0x31:
+		i.Codec, i.Kind = "sign language interpretation video", ComponentKindVideo
+	case t == 0x40:
+		i.Codec, i.Kind = "video up-sampled from SD", ComponentKindVideo
+	}
+}
+
+// String returns a description of the component in human terms
+func (i *DescriptorComponentInfo) String() string {
+	// Codec
+	var ps []string
+	if i.Codec == "" {
+		return "reserved"
+	}
+	ps = append(ps, i.Codec)
+
+	// Video
+	if i.Kind == ComponentKindVideo || i.Kind == ComponentKindSubtitles {
+		if i.UHD {
+			ps = append(ps, "UHD")
+		} else if i.HD {
+			ps = append(ps, "HD")
+		} else if i.Kind == ComponentKindVideo && i.AspectRatio != "" {
+			ps = append(ps, "SD")
+		}
+	}
+	if i.AspectRatio != "" {
+		ps = append(ps, i.AspectRatio)
+	}
+	if i.FrameRate > 0 {
+		ps = append(ps, fmt.Sprintf("%d Hz", i.FrameRate))
+	}
+
+	// Audio
+	if i.AudioMode != "" {
+		ps = append(ps, i.AudioMode)
+	}
+	if i.AudioDescription {
+		ps = append(ps, "audio description")
+	}
+	if i.HardOfHearing {
+		ps = append(ps, "hard of hearing")
+	}
+	return strings.Join(ps, ", ")
+}
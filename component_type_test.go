@@ -0,0 +1,28 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescriptorComponentInfo(t *testing.T) {
+	info := func(streamContent, streamContentExt, componentType uint8) *DescriptorComponentInfo {
+		return (&DescriptorComponent{ComponentType: componentType, StreamContent: streamContent, StreamContentExt: streamContentExt}).Info()
+	}
+	i := info(0x1, 0xf, 0x3)
+	assert.Equal(t, &DescriptorComponentInfo{AspectRatio: "16:9", Codec: "MPEG-2 video", FrameRate: 25, Kind: ComponentKindVideo}, i)
+	assert.Equal(t, "MPEG-2 video, SD, 16:9, 25 Hz", i.String())
+	assert.Equal(t, "H.264/AVC video, HD, > 16:9, 30 Hz", info(0x5, 0xf, 0x10).String())
+	assert.Equal(t, "H.264/AVC video", info(0x5, 0xf, 0x2).String())
+	assert.Equal(t, "HEVC video, HD, 60 Hz", info(0x9, 0x0, 0x3).String())
+	assert.Equal(t, "HEVC video, UHD", info(0x9, 0x0, 0x4).String())
+	assert.Equal(t, "MPEG-1 Layer 2 audio, dual mono", info(0x2, 0xf, 0x2).String())
+	assert.Equal(t, "HE-AAC v2 audio, audio description", info(0x6, 0xf, 0x44).String())
+	assert.Equal(t, "E-AC-3 audio, multichannel, hard of hearing", info(0x4, 0xf, 0xdc).String())
+	i = info(0x3, 0xf, 0x24)
+	assert.Equal(t, &DescriptorComponentInfo{Codec: "DVB subtitles", HardOfHearing: true, HD: true, Kind: ComponentKindSubtitles, SubtitleType: "DVB"}, i)
+	assert.Equal(t, "DVB subtitles, HD, hard of hearing", i.String())
+	assert.Equal(t, ComponentKindTeletext, info(0x3, 0xf, 0x2).Kind)
+	assert.Equal(t, "reserved", info(0xc, 0xf, 0x1).String())
+}
@@ -0,0 +1,255 @@
+package astits
+
+// ContentGenre represents the genre of an event, as signaled by the first content nibble of a content descriptor
+// Page: 59 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+type ContentGenre uint8
+
+// Content genres
+const (
+	ContentGenreAdult                    ContentGenre = 0xc
+	ContentGenreArtsCulture              ContentGenre = 0x7
+	ContentGenreChildrenYouth            ContentGenre = 0x5
+	ContentGenreEducationScienceFactual  ContentGenre = 0x9
+	ContentGenreLeisureHobbies           ContentGenre = 0xa
+	ContentGenreMovieDrama               ContentGenre = 0x1
+	ContentGenreMusicBalletDance         ContentGenre = 0x6
+	ContentGenreNewsCurrentAffairs       ContentGenre = 0x2
+	ContentGenreShowGameShow             ContentGenre = 0x3
+	ContentGenreSocialPoliticalEconomics ContentGenre = 0x8
+	ContentGenreSpecialCharacteristics   ContentGenre = 0xb
+	ContentGenreSports                   ContentGenre = 0x4
+	ContentGenreUndefined                ContentGenre = 0x0
+	ContentGenreUserDefined              ContentGenre = 0xf
+)
+
+// ContentSubgenre represents the subgenre of an event, as signaled by both content nibbles of a content descriptor,
+// the first one being its genre
+// Page: 59 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+type ContentSubgenre uint8
+
+// Content subgenres
+const (
+	ContentSubgenreAdult                                    ContentSubgenre = 0xc0
+	ContentSubgenreArtsBroadcastingPress                    ContentSubgenre = 0x78
+	ContentSubgenreArtsCulture                              ContentSubgenre = 0x70
+	ContentSubgenreArtsCultureMagazines                     ContentSubgenre = 0x7a
+	ContentSubgenreArtsExperimentalFilmVideo                ContentSubgenre = 0x77
+	ContentSubgenreArtsFashion                              ContentSubgenre = 0x7b
+	ContentSubgenreArtsFilmCinema                           ContentSubgenre = 0x76
+	ContentSubgenreArtsFineArts                             ContentSubgenre = 0x72
+	ContentSubgenreArtsLiterature                           ContentSubgenre = 0x75
+	ContentSubgenreArtsNewMedia                             ContentSubgenre = 0x79
+	ContentSubgenreArtsPerformingArts                       ContentSubgenre = 0x71
+	ContentSubgenreArtsPopularCultureTraditionalArts        ContentSubgenre = 0x74
+	ContentSubgenreArtsReligion                             ContentSubgenre = 0x73
+	ContentSubgenreChildrenCartoonsPuppets                  ContentSubgenre = 0x55
+	ContentSubgenreChildrenEntertainment10To16              ContentSubgenre = 0x53
+	ContentSubgenreChildrenEntertainment6To14               ContentSubgenre = 0x52
+	ContentSubgenreChildrenInformationalEducationalSchool   ContentSubgenre = 0x54
+	ContentSubgenreChildrenPreSchool                        ContentSubgenre = 0x51
+	ContentSubgenreChildrenYouth                            ContentSubgenre = 0x50
+	ContentSubgenreEducationForeignCountriesExpeditions     ContentSubgenre = 0x94
+	ContentSubgenreEducationFurtherEducation                ContentSubgenre = 0x96
+	ContentSubgenreEducationLanguages                       ContentSubgenre = 0x97
+	ContentSubgenreEducationMedicinePhysiologyPsychology    ContentSubgenre = 0x93
+	ContentSubgenreEducationNatureAnimalsEnvironment        ContentSubgenre = 0x91
+	ContentSubgenreEducationScienceFactual                  ContentSubgenre = 0x90
+	ContentSubgenreEducationSocialSpiritualSciences         ContentSubgenre = 0x95
+	ContentSubgenreEducationTechnologyNaturalSciences       ContentSubgenre = 0x92
+	ContentSubgenreLeisureAdvertisementShopping             ContentSubgenre = 0xa6
+	ContentSubgenreLeisureCooking                           ContentSubgenre = 0xa5
+	ContentSubgenreLeisureFitnessHealth                     ContentSubgenre = 0xa4
+	ContentSubgenreLeisureGardening                         ContentSubgenre = 0xa7
+	ContentSubgenreLeisureHandicraft                        ContentSubgenre = 0xa2
+	ContentSubgenreLeisureHobbies                           ContentSubgenre = 0xa0
+	ContentSubgenreLeisureMotoring                          ContentSubgenre = 0xa3
+	ContentSubgenreLeisureTourismTravel                     ContentSubgenre = 0xa1
+	ContentSubgenreMovieAdult                               ContentSubgenre = 0x18
+	ContentSubgenreMovieAdventureWesternWar                 ContentSubgenre = 0x12
+	ContentSubgenreMovieComedy                              ContentSubgenre = 0x14
+	ContentSubgenreMovieDetectiveThriller                   ContentSubgenre = 0x11
+	ContentSubgenreMovieDrama                               ContentSubgenre = 0x10
+	ContentSubgenreMovieRomance                             ContentSubgenre = 0x16
+	ContentSubgenreMovieScienceFictionFantasyHorror         ContentSubgenre = 0x13
+	ContentSubgenreMovieSeriousClassicalReligiousHistorical ContentSubgenre = 0x17
+	ContentSubgenreMovieSoapMelodramaFolklore               ContentSubgenre = 0x15
+	ContentSubgenreMusicBallet                              ContentSubgenre = 0x66
+	ContentSubgenreMusicBalletDance                         ContentSubgenre = 0x60
+	ContentSubgenreMusicFolkTraditional                     ContentSubgenre = 0x63
+	ContentSubgenreMusicJazz                                ContentSubgenre = 0x64
+	ContentSubgenreMusicMusicalOpera                        ContentSubgenre = 0x65
+	ContentSubgenreMusicRockPop                             ContentSubgenre = 0x61
+	ContentSubgenreMusicSeriousClassical                    ContentSubgenre = 0x62
+	ContentSubgenreNewsCurrentAffairs                       ContentSubgenre = 0x20
+	ContentSubgenreNewsDiscussionInterviewDebate            ContentSubgenre = 0x24
+	ContentSubgenreNewsDocumentary                          ContentSubgenre = 0x23
+	ContentSubgenreNewsMagazine                             ContentSubgenre = 0x22
+	ContentSubgenreNewsWeatherReport                        ContentSubgenre = 0x21
+	ContentSubgenreShowGameShow                             ContentSubgenre = 0x30
+	ContentSubgenreShowGameShowQuizContest                  ContentSubgenre = 0x31
+	ContentSubgenreShowTalkShow                             ContentSubgenre = 0x33
+	ContentSubgenreShowVarietyShow                          ContentSubgenre = 0x32
+	ContentSubgenreSocialEconomicsSocialAdvisory            ContentSubgenre = 0x82
+	ContentSubgenreSocialMagazinesReportsDocumentary        ContentSubgenre = 0x81
+	ContentSubgenreSocialPoliticalEconomics                 ContentSubgenre = 0x80
+	ContentSubgenreSocialRemarkablePeople                   ContentSubgenre = 0x83
+	ContentSubgenreSpecialBlackAndWhite                     ContentSubgenre = 0xb1
+	ContentSubgenreSpecialLiveBroadcast                     ContentSubgenre = 0xb3
+	ContentSubgenreSpecialLocalOrRegional                   ContentSubgenre = 0xb5
+	ContentSubgenreSpecialOriginalLanguage                  ContentSubgenre = 0xb0
+	ContentSubgenreSpecialPlanoStereoscopic                 ContentSubgenre = 0xb4
+	ContentSubgenreSpecialUnpublished                       ContentSubgenre = 0xb2
+	ContentSubgenreSports                                   ContentSubgenre = 0x40
+	ContentSubgenreSportsAthletics                          ContentSubgenre = 0x46
+	ContentSubgenreSportsEquestrian                         ContentSubgenre = 0x4a
+	ContentSubgenreSportsFootballSoccer                     ContentSubgenre = 0x43
+	ContentSubgenreSportsMagazines                          ContentSubgenre = 0x42
+	ContentSubgenreSportsMartialSports                      ContentSubgenre = 0x4b
+	ContentSubgenreSportsMotorSport                         ContentSubgenre = 0x47
+	ContentSubgenreSportsSpecialEvents                      ContentSubgenre = 0x41
+	ContentSubgenreSportsTeamSports                         ContentSubgenre = 0x45
+	ContentSubgenreSportsTennisSquash                       ContentSubgenre = 0x44
+	ContentSubgenreSportsWaterSport                         ContentSubgenre = 0x48
+	ContentSubgenreSportsWinterSports                       ContentSubgenre = 0x49
+)
+
+// Content genre names
+var contentGenreNames = map[ContentGenre]string{
+	ContentGenreAdult:                    "adult",
+	ContentGenreArtsCulture:              "arts/culture (without music)",
+	ContentGenreChildrenYouth:            "children's/youth programmes",
+	ContentGenreEducationScienceFactual:  "education/science/factual topics",
+	ContentGenreLeisureHobbies:           "leisure hobbies",
+	ContentGenreMovieDrama:               "movie/drama",
+	ContentGenreMusicBalletDance:         "music/ballet/dance",
+	ContentGenreNewsCurrentAffairs:       "news/current affairs",
+	ContentGenreShowGameShow:             "show/game show",
+	ContentGenreSocialPoliticalEconomics: "social/political issues/economics",
+	ContentGenreSpecialCharacteristics:   "special characteristics",
+	ContentGenreSports:                   "sports",
+	ContentGenreUndefined:                "undefined content",
+	ContentGenreUserDefined:              "user defined",
+}
+
+// Content subgenre names
+var contentSubgenreNames = map[ContentSubgenre]string{
+	ContentSubgenreAdult:                                    "adult (general)",
+	ContentSubgenreArtsBroadcastingPress:                    "broadcasting/press",
+	ContentSubgenreArtsCulture:                              "arts/culture (without music, general)",
+	ContentSubgenreArtsCultureMagazines:                     "arts/culture magazines",
+	ContentSubgenreArtsExperimentalFilmVideo:                "experimental film/video",
+	ContentSubgenreArtsFashion:                              "fashion",
+	ContentSubgenreArtsFilmCinema:                           "film/cinema",
+	ContentSubgenreArtsFineArts:                             "fine arts",
+	ContentSubgenreArtsLiterature:                           "literature",
+	ContentSubgenreArtsNewMedia:                             "new media",
+	ContentSubgenreArtsPerformingArts:                       "performing arts",
+	ContentSubgenreArtsPopularCultureTraditionalArts:        "popular culture/traditional arts",
+	ContentSubgenreArtsReligion:                             "religion",
+	ContentSubgenreChildrenCartoonsPuppets:                  "cartoons/puppets",
+	ContentSubgenreChildrenEntertainment10To16:              "entertainment programmes for 10 to 16",
+	ContentSubgenreChildrenEntertainment6To14:               "entertainment programmes for 6 to 14",
+	ContentSubgenreChildrenInformationalEducationalSchool:   "informational/educational/school programmes",
+	ContentSubgenreChildrenPreSchool:                        "pre-school children's programmes",
+	ContentSubgenreChildrenYouth:                            "children's/youth programmes (general)",
+	ContentSubgenreEducationForeignCountriesExpeditions:     "foreign countries/expeditions",
+	ContentSubgenreEducationFurtherEducation:                "further education",
+	ContentSubgenreEducationLanguages:                       "languages",
+	ContentSubgenreEducationMedicinePhysiologyPsychology:    "medicine/physiology/psychology",
+	ContentSubgenreEducationNatureAnimalsEnvironment:        "nature/animals/environment",
+	ContentSubgenreEducationScienceFactual:                  "education/science/factual topics (general)",
+	ContentSubgenreEducationSocialSpiritualSciences:         "social/spiritual sciences",
+	ContentSubgenreEducationTechnologyNaturalSciences:       "technology/natural sciences",
+	ContentSubgenreLeisureAdvertisementShopping:             "advertisement/shopping",
+	ContentSubgenreLeisureCooking:                           "cooking",
+	ContentSubgenreLeisureFitnessHealth:                     "fitness and health",
+	ContentSubgenreLeisureGardening:                         "gardening",
+	ContentSubgenreLeisureHandicraft:                        "handicraft",
+	ContentSubgenreLeisureHobbies:                           "leisure hobbies (general)",
+	ContentSubgenreLeisureMotoring:                          "motoring",
+	ContentSubgenreLeisureTourismTravel:                     "tourism/travel",
+	ContentSubgenreMovieAdult:                               "adult movie/drama",
+	ContentSubgenreMovieAdventureWesternWar:                 "adventure/western/war",
+	ContentSubgenreMovieComedy:                              "comedy",
+	ContentSubgenreMovieDetectiveThriller:                   "detective/thriller",
+	ContentSubgenreMovieDrama:                               "movie/drama (general)",
+	ContentSubgenreMovieRomance:                             "romance",
+	ContentSubgenreMovieScienceFictionFantasyHorror:         "science fiction/fantasy/horror",
+	ContentSubgenreMovieSeriousClassicalReligiousHistorical: "serious/classical/religious/historical movie/drama",
+	ContentSubgenreMovieSoapMelodramaFolklore:               "soap/melodrama/folklore",
+	ContentSubgenreMusicBallet:                              "ballet",
+	ContentSubgenreMusicBalletDance:                         "music/ballet/dance (general)",
+	ContentSubgenreMusicFolkTraditional:                     "folk/traditional music",
+	ContentSubgenreMusicJazz:                                "jazz",
+	ContentSubgenreMusicMusicalOpera:                        "musical/opera",
+	ContentSubgenreMusicRockPop:                             "rock/pop",
+	ContentSubgenreMusicSeriousClassical:                    "serious music/classical music",
+	ContentSubgenreNewsCurrentAffairs:                       "news/current affairs (general)",
+	ContentSubgenreNewsDiscussionInterviewDebate:            "discussion/interview/debate",
+	ContentSubgenreNewsDocumentary:                          "documentary",
+	ContentSubgenreNewsMagazine:                             "news magazine",
+	ContentSubgenreNewsWeatherReport:                        "news/weather report",
+	ContentSubgenreShowGameShow:                             "show/game show (general)",
+	ContentSubgenreShowGameShowQuizContest:                  "game show/quiz/contest",
+	ContentSubgenreShowTalkShow:                             "talk show",
+	ContentSubgenreShowVarietyShow:                          "variety show",
+	ContentSubgenreSocialEconomicsSocialAdvisory:            "economics/social advisory",
+	ContentSubgenreSocialMagazinesReportsDocumentary:        "magazines/reports/documentary",
+	ContentSubgenreSocialPoliticalEconomics:                 "social/political issues/economics (general)",
+	ContentSubgenreSocialRemarkablePeople:                   "remarkable people",
+	ContentSubgenreSpecialBlackAndWhite:                     "black and white",
+	ContentSubgenreSpecialLiveBroadcast:                     "live broadcast",
+	ContentSubgenreSpecialLocalOrRegional:                   "local or regional",
+	ContentSubgenreSpecialOriginalLanguage:                  "original language",
+	ContentSubgenreSpecialPlanoStereoscopic:                 "plano-stereoscopic",
+	ContentSubgenreSpecialUnpublished:                       "unpublished",
+	ContentSubgenreSports:                                   "sports (general)",
+	ContentSubgenreSportsAthletics:                          "athletics",
+	ContentSubgenreSportsEquestrian:                         "equestrian",
+	ContentSubgenreSportsFootballSoccer:                     "football/soccer",
+	ContentSubgenreSportsMagazines:                          "sports magazines",
+	ContentSubgenreSportsMartialSports:                      "martial sports",
+	ContentSubgenreSportsMotorSport:                         "motor sport",
+	ContentSubgenreSportsSpecialEvents:                      "special events (Olympic Games, World Cup, etc.)",
+	ContentSubgenreSportsTeamSports:                         "team sports (excluding football)",
+	ContentSubgenreSportsTennisSquash:                       "tennis/squash",
+	ContentSubgenreSportsWaterSport:                         "water sport",
+	ContentSubgenreSportsWinterSports:                       "winter sports",
+}
+
+// String returns the name of the genre
+func (g ContentGenre) String() string {
+	if n, ok := contentGenreNames[g]; ok {
+		return n
+	}
+	return "reserved"
+}
+
+// Genre returns the genre the subgenre belongs to
+func (s ContentSubgenre) Genre() ContentGenre {
+	return ContentGenre(s >> 4)
+}
+
+// String returns the name of the subgenre
+func (s ContentSubgenre) String() string {
+	if n, ok := contentSubgenreNames[s]; ok {
+		return n
+	}
+	switch {
+	case s.Genre() == ContentGenreUndefined:
+		return ContentGenreUndefined.String()
+	case s.Genre() == ContentGenreUserDefined || s&0xf == 0xf:
+		return ContentGenreUserDefined.String()
+	}
+	return "reserved"
+}
+
+// Genre returns the genre of the content item
+func (itm *DescriptorContentItem) Genre() ContentGenre {
+	return ContentGenre(itm.ContentNibbleLevel1)
+}
+
+// Subgenre returns the subgenre of the content item
+func (itm *DescriptorContentItem) Subgenre() ContentSubgenre {
+	return ContentSubgenre(itm.ContentNibbleLevel1<<4 | itm.ContentNibbleLevel2&0xf)
+}
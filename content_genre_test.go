@@ -0,0 +1,21 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentGenre(t *testing.T) {
+	itm := &DescriptorContentItem{ContentNibbleLevel1: 0x1, ContentNibbleLevel2: 0x4}
+	assert.Equal(t, ContentGenreMovieDrama, itm.Genre())
+	assert.Equal(t, ContentSubgenreMovieComedy, itm.Subgenre())
+	assert.Equal(t, "movie/drama", itm.Genre().String())
+	assert.Equal(t, "comedy", itm.Subgenre().String())
+	assert.Equal(t, ContentGenreSports, ContentSubgenreSportsAthletics.Genre())
+	assert.Equal(t, "reserved", ContentGenre(0xd).String())
+	assert.Equal(t, "undefined content", ContentSubgenre(0x03).String())
+	assert.Equal(t, "user defined", ContentSubgenre(0x1f).String())
+	assert.Equal(t, "user defined", ContentSubgenre(0xf2).String())
+	assert.Equal(t, "reserved", ContentSubgenre(0x19).String())
+}
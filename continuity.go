@@ -0,0 +1,77 @@
+package astits
+
+// Packet event types
+const (
+	PacketEventTypeContinuityError = "continuity_error" // Packets have been lost, a packet occurs more than twice or the continuity counter of a packet without payload has changed
+	PacketEventTypeDuplicatePacket = "duplicate_packet" // A packet occurs twice in a row which is allowed
+)
+
+// PacketEvent represents an event detected on a packet
+type PacketEvent struct {
+	ExpectedContinuityCounter uint8
+	LostPackets               int     // Number of packets lost, modulo 16, for continuity errors caused by lost packets
+	Packet                    *Packet // Copy of the packet which, unlike the packet returned by NextPacket, can be kept
+	Type                      string
+}
+
+// PacketEventHandler represents an object capable of handling packet events
+type PacketEventHandler func(e *PacketEvent)
+
+// continuityChecker represents an object capable of checking the continuity counters of packets
+// Page: 38 | Chapter: 2.4.3.3 | Link: https://www.itu.int/rec/dologin_pub.asp?lang=e&id=T-REC-H.222.0-201206-S!!PDF-E&type=items
+type continuityChecker struct {
+	pids map[uint16]*continuityCheckerPID
+}
+
+// continuityCheckerPID represents the continuity state of a PID
+type continuityCheckerPID struct {
+	continuityCounter uint8
+	duplicates        int
+}
+
+// newContinuityChecker creates a new continuity checker
+func newContinuityChecker() *continuityChecker {
+	return &continuityChecker{pids: make(map[uint16]*continuityCheckerPID)}
+}
+
+// check checks the continuity counter of a packet and returns an event if it's not the expected one
+// Null packets and packets with their transport error indicator set are ignored
+func (c *continuityChecker) check(p *Packet) (e *PacketEvent) {
+	// Ignore packets
+	if p.Header.PID == PIDNull || p.Header.TransportErrorIndicator {
+		return
+	}
+
+	// First packet or discontinuity
+	var s, ok = c.pids[p.Header.PID]
+	if !ok || (p.Header.HasAdaptationField && p.AdaptationField != nil && p.AdaptationField.DiscontinuityIndicator) {
+		c.pids[p.Header.PID] = &continuityCheckerPID{continuityCounter: p.Header.ContinuityCounter}
+		return
+	}
+
+	// Packets without payload don't increment the continuity counter
+	var expected = s.continuityCounter
+	if p.Header.HasPayload {
+		expected = (expected + 1) % 16
+	}
+
+	// Check continuity counter
+	switch {
+	case p.Header.ContinuityCounter == expected:
+		s.duplicates = 0
+	case p.Header.HasPayload && p.Header.ContinuityCounter == s.continuityCounter:
+		s.duplicates++
+		e = &PacketEvent{ExpectedContinuityCounter: expected, Packet: p, Type: PacketEventTypeDuplicatePacket}
+		if s.duplicates > 1 {
+			e.Type = PacketEventTypeContinuityError
+		}
+	default:
+		s.duplicates = 0
+		e = &PacketEvent{ExpectedContinuityCounter: expected, Packet: p, Type: PacketEventTypeContinuityError}
+		if p.Header.HasPayload {
+			e.LostPackets = int((p.Header.ContinuityCounter - expected + 16) % 16)
+		}
+	}
+	s.continuityCounter = p.Header.ContinuityCounter
+	return
+}
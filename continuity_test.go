@@ -0,0 +1,105 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func continuityPacket(pid uint16, cc uint8, hasPayload, discontinuity bool) *Packet {
+	var p = &Packet{Header: &PacketHeader{ContinuityCounter: cc, HasPayload: hasPayload, PID: pid}}
+	if discontinuity {
+		p.Header.HasAdaptationField = true
+		p.AdaptationField = &PacketAdaptationField{DiscontinuityIndicator: true}
+	}
+	return p
+}
+
+func TestContinuityChecker(t *testing.T) {
+	c := newContinuityChecker()
+
+	// First packet
+	assert.Nil(t, c.check(continuityPacket(1, 14, true, false)))
+
+	// Wrap
+	assert.Nil(t, c.check(continuityPacket(1, 15, true, false)))
+	assert.Nil(t, c.check(continuityPacket(1, 0, true, false)))
+
+	// Other PID
+	assert.Nil(t, c.check(continuityPacket(2, 5, true, false)))
+
+	// Without payload
+	assert.Nil(t, c.check(continuityPacket(1, 0, false, false)))
+	p := continuityPacket(1, 1, false, false)
+	assert.Equal(t, &PacketEvent{ExpectedContinuityCounter: 0, Packet: p, Type: PacketEventTypeContinuityError}, c.check(p))
+
+	// Duplicates
+	p = continuityPacket(1, 1, true, false)
+	assert.Equal(t, &PacketEvent{ExpectedContinuityCounter: 2, Packet: p, Type: PacketEventTypeDuplicatePacket}, c.check(p))
+	assert.Equal(t, &PacketEvent{ExpectedContinuityCounter: 2, Packet: p, Type: PacketEventTypeContinuityError}, c.check(p))
+	assert.Nil(t, c.check(continuityPacket(1, 2, true, false)))
+
+	// Lost packets
+	p = continuityPacket(1, 1, true, false)
+	assert.Equal(t, &PacketEvent{ExpectedContinuityCounter: 3, LostPackets: 14, Packet: p, Type: PacketEventTypeContinuityError}, c.check(p))
+
+	// Discontinuity
+	assert.Nil(t, c.check(continuityPacket(1, 7, true, true)))
+	assert.Nil(t, c.check(continuityPacket(1, 8, true, false)))
+
+	// Ignored packets
+	assert.Nil(t, c.check(continuityPacket(PIDNull, 0, true, false)))
+	assert.Nil(t, c.check(continuityPacket(PIDNull, 5, true, false)))
+	p = continuityPacket(1, 3, true, false)
+	p.Header.TransportErrorIndicator = true
+	assert.Nil(t, c.check(p))
+	assert.Nil(t, c.check(continuityPacket(1, 9, true, false)))
+}
+
+func TestDemuxerContinuity(t *testing.T) {
+	// Init
+	buf := &bytes.Buffer{}
+	for _, cc := range []uint8{0, 1, 3, 3, 4} {
+		b := make([]byte, MpegTsPacketSize)
+		b[0] = syncByte
+		b[1] = 0x01
+		b[3] = 0x10 | cc
+		buf.Write(b)
+	}
+
+	// Demux
+	var es []*PacketEvent
+	dmx := New(context.Background(), bytes.NewReader(buf.Bytes()), OptPacketEventHandler(func(e *PacketEvent) { es = append(es, e) }))
+	for {
+		if _, err := dmx.NextPacket(); err != nil {
+			assert.Equal(t, ErrNoMorePackets, err)
+			break
+		}
+	}
+	assert.Len(t, es, 2)
+	assert.Equal(t, PacketEventTypeContinuityError, es[0].Type)
+	assert.Equal(t, uint8(2), es[0].ExpectedContinuityCounter)
+	assert.Equal(t, 1, es[0].LostPackets)
+	assert.Equal(t, uint16(0x100), es[0].Packet.Header.PID)
+	assert.Equal(t, PacketEventTypeDuplicatePacket, es[1].Type)
+	assert.Equal(t, int64(1), dmx.ContinuityErrors())
+
+	// Rewind
+	_, err := dmx.Rewind()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), dmx.ContinuityErrors())
+
+	// Packets of events can be kept while packets are reused
+	es = []*PacketEvent{}
+	dmx = New(context.Background(), bytes.NewReader(buf.Bytes()), OptPacketEventHandler(func(e *PacketEvent) { es = append(es, e) }), OptZeroCopy())
+	for {
+		if _, err := dmx.NextPacket(); err != nil {
+			break
+		}
+	}
+	assert.Len(t, es, 2)
+	assert.Equal(t, uint8(3), es[0].Packet.Header.ContinuityCounter)
+	assert.Equal(t, uint8(0x13), es[0].Packet.Bytes[3])
+}
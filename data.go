@@ -1,32 +1,72 @@
 package astits
 
 import (
+	"github.com/asticode/go-astilog"
 	"github.com/pkg/errors"
 )
 
 // PIDs
 const (
-	PIDPAT  = 0x0    // Program Association Table (PAT) contains a directory listing of all Program Map Tables.
-	PIDCAT  = 0x1    // Conditional Access Table (CAT) contains a directory listing of all ITU-T Rec. H.222 entitlement management message streams used by Program Map Tables.
-	PIDTSDT = 0x2    // Transport Stream Description Table (TSDT) contains descriptors related to the overall transport stream
-	PIDNull = 0x1fff // Null Packet (used for fixed bandwidth padding)
+	PIDPAT      = 0x0    // Program Association Table (PAT) contains a directory listing of all Program Map Tables.
+	PIDCAT      = 0x1    // Conditional Access Table (CAT) contains a directory listing of all ITU-T Rec. H.222 entitlement management message streams used by Program Map Tables.
+	PIDTSDT     = 0x2    // Transport Stream Description Table (TSDT) contains descriptors related to the overall transport stream
+	PIDNIT      = 0x10   // Network Information Table (NIT) describes the transport streams of the network
+	PIDSDT      = 0x11   // Service Description Table (SDT) describes the services of the transport streams, along with the BAT
+	PIDATSCBase = 0x1ffb // ATSC PSIP base PID carrying the MGT, the TVCT, the CVCT, the STT and the RRT
+	PIDNull     = 0x1fff // Null Packet (used for fixed bandwidth padding)
 )
 
 // Data represents a data
 type Data struct {
-	EIT         *EITData
-	FirstPacket *Packet
-	NIT         *NITData
-	PAT         *PATData
-	PES         *PESData
-	PID         uint16
-	PMT         *PMTData
-	SDT         *SDTData
-	TOT         *TOTData
+	AIT            *AITData
+	ANC            *ANCData // Set when the PES data is received on an SMPTE ST 2038 ancillary data stream
+	ATSCEIT        *ATSCEITData
+	BAT            *BATData
+	BIT            *BITData
+	CAT            *CATData
+	CVCT           *VCTData
+	DIT            *DITData
+	DSMCC          *DSMCCData
+	ECM            *CAMessageData
+	EIT            *EITData
+	EMM            *CAMessageData
+	ETT            *ETTData
+	FirstPacket    *Packet
+	ID3            *ID3Data // Set when the PES data is received on an ID3 timed metadata stream
+	InvalidCRC32   bool     // Set when the CRCModeFlag mode is used and the CRC32 of the section the data was parsed from is invalid
+	IsNext         bool     // Set when the current_next_indicator of the section the data was parsed from is unset, meaning the data describes an upcoming configuration that is not applicable yet
+	KLV            *KLVData // Set when the PES data is received on a KLV metadata stream
+	LDT            *LDTData
+	MGT            *MGTData
+	MPE            *MPEData
+	MPEFEC         *MPEFECData
+	NBIT           *NBITData
+	NIT            *NITData
+	PAT            *PATData
+	PCR            *ClockReference // Last PCR of the program received before the first packet of PES data. Comparing it to the PES PTS maps the PTS to the program clock
+	PES            *PESData
+	PID            uint16
+	PMT            *PMTData
+	RawSection     []byte // Complete PSI section the data was parsed from, CRC32 included. Tables that are unknown are only available this way
+	RRT            *RRTData
+	RST            *RSTData
+	SCTE35         *SCTE35Data
+	SDT            *SDTData
+	SIT            *SITData
+	ST             *STData
+	STT            *STTData
+	T2MI           *T2MIData // Set when the data is received on a T2-MI stream
+	TDT            *TDTData
+	TOT            *TOTData
+	TransportError bool // Set when the TransportErrorPolicyFlag policy is used and one of the packets the data was parsed from has its transport error indicator set
+	TSDT           *TSDTData
+	TVCT           *VCTData
+	ULE            *ULEData // Set when the data is received on a ULE stream
 }
 
 // parseData parses a payload spanning over multiple packets and returns a set of data
-func parseData(ps []*Packet, prs PacketsParser, pm programMap) (ds []*Data, err error) {
+// Errors that don't prevent the next data from being parsed are logged
+func parseData(ps []*Packet, prs PacketsParser, pm, sm programMap, shs sectionHandlers, crcMode string, lg astilog.Logger) (ds []*Data, err error) {
 	// Use custom parser first
 	if prs != nil {
 		var skip bool
@@ -52,35 +92,51 @@ func parseData(ps []*Packet, prs PacketsParser, pm programMap) (ds []*Data, err
 	// Parse PID
 	var pid = ps[0].Header.PID
 
+	// Hand sections to custom handlers
+	var handled bool
+	if handled, err = shs.handle(payload, pid); err != nil {
+		err = errors.Wrap(err, "astits: custom sections handling failed")
+		return
+	}
+
 	// Parse payload
-	if pid == PIDCAT {
-		// Information in a CAT payload is private and dependent on the CA system. Use the PacketsParser
-		// to parse this type of payload
-	} else if isPSIPayload(pid, pm) {
+	if isPSIPayload(pid, pm, sm) {
 		var psiData *PSIData
-		if psiData, err = parsePSIData(payload); err != nil {
+		if psiData, err = parsePSIData(payload, pid, crcMode); err != nil {
 			err = errors.Wrap(err, "astits: parsing PSI data failed")
 			return
 		}
 		ds = psiData.toData(ps[0], pid)
+	} else if handled {
+		return
 	} else if isPESPayload(payload) {
-		d, err := parsePESData(payload)
-		if err == nil {
-			ds = append(ds, &Data{
-				FirstPacket: ps[0],
-				PES:         d,
-				PID:         pid,
-			})
+		var pesData *PESData
+		if pesData, err = parsePESData(payload); err != nil {
+			// PES data may be incomplete, therefore we only log the error and move on
+			lg.Debugf("astits: parsing PES data of PID %d failed: %s", pid, err)
+			err = nil
+			return
 		}
+		ds = append(ds, &Data{
+			FirstPacket: ps[0],
+			PES:         pesData,
+			PID:         pid,
+		})
 	}
 	return
 }
 
 // isPSIPayload checks whether the payload is a PSI one
-func isPSIPayload(pid uint16, pm programMap) bool {
+// sm contains the PIDs announced as carrying sections by the ATSC MGT or by the PMT
+func isPSIPayload(pid uint16, pm, sm programMap) bool {
 	return pid == PIDPAT || // PAT
+		pid == PIDCAT || // CAT
+		pid == PIDTSDT || // TSDT
+		pid == PIDATSCBase || // ATSC PSIP
 		pm.exists(pid) || // PMT
-		((pid >= 0x10 && pid <= 0x14) || (pid >= 0x1e && pid <= 0x1f)) //DVB
+		sm.exists(pid) || // ATSC EIT and ETT, SCTE-35, AIT, DSM-CC, MPE, ECM and EMM
+		((pid >= 0x10 && pid <= 0x14) || (pid >= 0x1e && pid <= 0x1f)) || //DVB
+		(pid >= 0x24 && pid <= 0x25) // ISDB
 }
 
 // isPESPayload checks whether the payload is a PES one
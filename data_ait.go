@@ -0,0 +1,407 @@
+package astits
+
+// AIT application control codes
+// Page: 23 | Chapter: 5.3.4.3 | Link: https://www.etsi.org/deliver/etsi_ts/102800_102899/102809/01.03.01_60/ts_102809v010301p.pdf
+const (
+	AITApplicationControlCodeAutostart         = 0x1
+	AITApplicationControlCodeDestroy           = 0x3
+	AITApplicationControlCodeDisabled          = 0x7
+	AITApplicationControlCodeKill              = 0x4
+	AITApplicationControlCodePlaybackAutostart = 0x8
+	AITApplicationControlCodePrefetch          = 0x5
+	AITApplicationControlCodePresent           = 0x2
+	AITApplicationControlCodeRemote            = 0x6
+)
+
+// AIT application types
+// Page: 22 | Chapter: 5.3.4.2 | Link: https://www.etsi.org/deliver/etsi_ts/102800_102899/102809/01.03.01_60/ts_102809v010301p.pdf
+const (
+	AITApplicationTypeDVBHTML = 0x2
+	AITApplicationTypeDVBJ    = 0x1
+	AITApplicationTypeHbbTV   = 0x10
+)
+
+// AIT descriptor tags
+// Tags are specific to the AIT and overlap with the tags of the descriptors of other tables
+// Page: 28 | Chapter: 5.3.5 | Link: https://www.etsi.org/deliver/etsi_ts/102800_102899/102809/01.03.01_60/ts_102809v010301p.pdf
+const (
+	AITDescriptorTagApplication               = 0x0
+	AITDescriptorTagApplicationName           = 0x1
+	AITDescriptorTagApplicationUsage          = 0x16
+	AITDescriptorTagSimpleApplicationBoundary = 0x17
+	AITDescriptorTagSimpleApplicationLocation = 0x15
+	AITDescriptorTagTransportProtocol         = 0x2
+)
+
+// AIT transport protocol IDs
+// Page: 35 | Chapter: 5.3.6 | Link: https://www.etsi.org/deliver/etsi_ts/102800_102899/102809/01.03.01_60/ts_102809v010301p.pdf
+const (
+	AITTransportProtocolIDHTTP           = 0x3
+	AITTransportProtocolIDObjectCarousel = 0x1
+)
+
+// AITData represents an AIT data
+// Page: 20 | Chapter: 5.3.4 | Link: https://www.etsi.org/deliver/etsi_ts/102800_102899/102809/01.03.01_60/ts_102809v010301p.pdf
+type AITData struct {
+	Applications      []*AITDataApplication
+	ApplicationType   uint16
+	CommonDescriptors []*AITDescriptor
+	TestApplication   bool
+}
+
+// AITDataApplication represents an AIT data application
+type AITDataApplication struct {
+	ApplicationID  uint16
+	ControlCode    uint8
+	Descriptors    []*AITDescriptor
+	OrganisationID uint32
+}
+
+// AITDescriptor represents an AIT descriptor
+type AITDescriptor struct {
+	Application               *AITDescriptorApplication
+	ApplicationName           *AITDescriptorApplicationName
+	ApplicationUsage          *AITDescriptorApplicationUsage
+	Length                    uint8
+	PrivateBytes              []byte // Set when the tag is unknown
+	SimpleApplicationBoundary *AITDescriptorSimpleApplicationBoundary
+	SimpleApplicationLocation *AITDescriptorSimpleApplicationLocation
+	Tag                       uint8
+	TransportProtocol         *AITDescriptorTransportProtocol
+}
+
+// AITDescriptorApplication represents an AIT application descriptor
+// Page: 28 | Chapter: 5.3.5.3 | Link: https://www.etsi.org/deliver/etsi_ts/102800_102899/102809/01.03.01_60/ts_102809v010301p.pdf
+type AITDescriptorApplication struct {
+	Priority                uint8
+	Profiles                []*AITDescriptorApplicationProfile
+	ServiceBound            bool
+	TransportProtocolLabels []uint8
+	Visibility              uint8
+}
+
+// AITDescriptorApplicationProfile represents an AIT application descriptor profile
+type AITDescriptorApplicationProfile struct {
+	Profile      uint16
+	VersionMajor uint8
+	VersionMicro uint8
+	VersionMinor uint8
+}
+
+// AITDescriptorApplicationName represents an AIT application name descriptor
+// Page: 30 | Chapter: 5.3.5.6 | Link: https://www.etsi.org/deliver/etsi_ts/102800_102899/102809/01.03.01_60/ts_102809v010301p.pdf
+type AITDescriptorApplicationName struct {
+	Items []*AITDescriptorApplicationNameItem
+}
+
+// AITDescriptorApplicationNameItem represents an AIT application name descriptor item
+type AITDescriptorApplicationNameItem struct {
+	Language []byte
+	Name     []byte
+}
+
+// AITDescriptorApplicationUsage represents an AIT application usage descriptor
+// Page: 32 | Chapter: 5.3.5.5 | Link: https://www.etsi.org/deliver/etsi_ts/102800_102899/102809/01.03.01_60/ts_102809v010301p.pdf
+type AITDescriptorApplicationUsage struct {
+	UsageType uint8
+}
+
+// AITDescriptorSimpleApplicationBoundary represents an AIT simple application boundary descriptor
+// Page: 33 | Chapter: 5.3.8 | Link: https://www.etsi.org/deliver/etsi_ts/102800_102899/102809/01.03.01_60/ts_102809v010301p.pdf
+type AITDescriptorSimpleApplicationBoundary struct {
+	BoundaryExtensions [][]byte
+}
+
+// AITDescriptorSimpleApplicationLocation represents an AIT simple application location descriptor
+// Page: 33 | Chapter: 5.3.7 | Link: https://www.etsi.org/deliver/etsi_ts/102800_102899/102809/01.03.01_60/ts_102809v010301p.pdf
+type AITDescriptorSimpleApplicationLocation struct {
+	InitialPath []byte
+}
+
+// AITDescriptorTransportProtocol represents an AIT transport protocol descriptor
+// Page: 35 | Chapter: 5.3.6 | Link: https://www.etsi.org/deliver/etsi_ts/102800_102899/102809/01.03.01_60/ts_102809v010301p.pdf
+type AITDescriptorTransportProtocol struct {
+	HTTP           []*AITDescriptorTransportProtocolHTTPURL // Set when the protocol is HTTP
+	Label          uint8
+	ObjectCarousel *AITDescriptorTransportProtocolObjectCarousel // Set when the protocol is the object carousel
+	ProtocolID     uint16
+	SelectorBytes  []byte // Set when the protocol is unknown
+}
+
+// AITDescriptorTransportProtocolHTTPURL represents an AIT transport protocol descriptor HTTP URL
+type AITDescriptorTransportProtocolHTTPURL struct {
+	Base       []byte
+	Extensions [][]byte
+}
+
+// AITDescriptorTransportProtocolObjectCarousel represents an AIT transport protocol descriptor object carousel
+type AITDescriptorTransportProtocolObjectCarousel struct {
+	ComponentTag      uint8
+	OriginalNetworkID uint16 // Only set when the carousel is remote
+	Remote            bool
+	ServiceID         uint16 // Only set when the carousel is remote
+	TransportStreamID uint16 // Only set when the carousel is remote
+}
+
+// URLs returns the URLs of an application of the AIT, built by appending its initial path to the HTTP URL bases of
+// the transport protocols it's signalled on
+func (d *AITData) URLs(a *AITDataApplication) (us []string) {
+	// Get initial path and transport protocol labels
+	var path string
+	var labels []uint8
+	for _, ds := range [][]*AITDescriptor{a.Descriptors, d.CommonDescriptors} {
+		for _, dsc := range ds {
+			if dsc.SimpleApplicationLocation != nil && len(path) == 0 {
+				path = string(dsc.SimpleApplicationLocation.InitialPath)
+			} else if dsc.Application != nil && labels == nil {
+				labels = dsc.Application.TransportProtocolLabels
+			}
+		}
+	}
+
+	// Loop through transport protocols, application ones taking precedence over common ones
+	for _, ds := range [][]*AITDescriptor{a.Descriptors, d.CommonDescriptors} {
+		for _, dsc := range ds {
+			if dsc.TransportProtocol == nil || !hasAITTransportProtocolLabel(labels, dsc.TransportProtocol.Label) {
+				continue
+			}
+			for _, u := range dsc.TransportProtocol.HTTP {
+				us = append(us, string(u.Base)+path)
+			}
+		}
+		if len(us) > 0 {
+			return
+		}
+	}
+	return
+}
+
+// hasAITTransportProtocolLabel checks whether the label is in the list, an empty list accepting all labels
+func hasAITTransportProtocolLabel(labels []uint8, label uint8) bool {
+	if len(labels) == 0 {
+		return true
+	}
+	for _, l := range labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAITSection parses an AIT section
+func parseAITSection(i []byte, offset *int, offsetSectionsEnd int, tableIDExtension uint16) (d *AITData) {
+	// Init
+	d = &AITData{
+		ApplicationType: tableIDExtension & 0x7fff,
+		TestApplication: tableIDExtension&0x8000 > 0,
+	}
+
+	// Common descriptors
+	d.CommonDescriptors = parseAITDescriptors(i, offset)
+
+	// Application loop length
+	var offsetEnd = *offset + 2 + int(uint16(i[*offset]&0xf)<<8|uint16(i[*offset+1]))
+	*offset += 2
+	if offsetEnd > offsetSectionsEnd {
+		offsetEnd = offsetSectionsEnd
+	}
+
+	// Loop through applications
+	for *offset+9 <= offsetEnd {
+		// Application identifier
+		var a = &AITDataApplication{}
+		a.OrganisationID = uint32(i[*offset])<<24 | uint32(i[*offset+1])<<16 | uint32(i[*offset+2])<<8 | uint32(i[*offset+3])
+		a.ApplicationID = uint16(i[*offset+4])<<8 | uint16(i[*offset+5])
+		*offset += 6
+
+		// Control code
+		a.ControlCode = uint8(i[*offset])
+		*offset += 1
+
+		// Descriptors
+		a.Descriptors = parseAITDescriptors(i, offset)
+
+		// Append application
+		d.Applications = append(d.Applications, a)
+	}
+	return
+}
+
+// parseAITDescriptors parses an AIT descriptors loop
+func parseAITDescriptors(i []byte, offset *int) (o []*AITDescriptor) {
+	// Descriptors loop length
+	var offsetEnd = *offset + 2 + int(uint16(i[*offset]&0xf)<<8|uint16(i[*offset+1]))
+	*offset += 2
+
+	// Loop through descriptors
+	for *offset+2 <= offsetEnd {
+		// Init
+		var d = &AITDescriptor{
+			Length: uint8(i[*offset+1]),
+			Tag:    uint8(i[*offset]),
+		}
+		*offset += 2
+
+		// Get descriptor content
+		var b = i[*offset : *offset+int(d.Length)]
+		*offset += int(d.Length)
+
+		// Switch on tag
+		switch d.Tag {
+		case AITDescriptorTagApplication:
+			d.Application = newAITDescriptorApplication(b)
+		case AITDescriptorTagApplicationName:
+			d.ApplicationName = newAITDescriptorApplicationName(b)
+		case AITDescriptorTagApplicationUsage:
+			d.ApplicationUsage = newAITDescriptorApplicationUsage(b)
+		case AITDescriptorTagSimpleApplicationBoundary:
+			d.SimpleApplicationBoundary = newAITDescriptorSimpleApplicationBoundary(b)
+		case AITDescriptorTagSimpleApplicationLocation:
+			d.SimpleApplicationLocation = &AITDescriptorSimpleApplicationLocation{InitialPath: b}
+		case AITDescriptorTagTransportProtocol:
+			d.TransportProtocol = newAITDescriptorTransportProtocol(b)
+		default:
+			d.PrivateBytes = make([]byte, len(b))
+			copy(d.PrivateBytes, b)
+		}
+		o = append(o, d)
+	}
+	return
+}
+
+func newAITDescriptorApplication(i []byte) (d *AITDescriptorApplication) {
+	// Init
+	d = &AITDescriptorApplication{}
+	if len(i) < 1 {
+		return
+	}
+
+	// Profiles
+	var offset = 1
+	var offsetEnd = offset + int(i[0])
+	for ; offset+5 <= offsetEnd && offset+5 <= len(i); offset += 5 {
+		d.Profiles = append(d.Profiles, &AITDescriptorApplicationProfile{
+			Profile:      uint16(i[offset])<<8 | uint16(i[offset+1]),
+			VersionMajor: i[offset+2],
+			VersionMicro: i[offset+4],
+			VersionMinor: i[offset+3],
+		})
+	}
+	if offset+2 > len(i) {
+		return
+	}
+
+	// Flags
+	d.ServiceBound = i[offset]&0x80 > 0
+	d.Visibility = i[offset] >> 5 & 0x3
+	d.Priority = i[offset+1]
+	offset += 2
+
+	// Transport protocol labels
+	d.TransportProtocolLabels = i[offset:]
+	return
+}
+
+func newAITDescriptorApplicationName(i []byte) (d *AITDescriptorApplicationName) {
+	d = &AITDescriptorApplicationName{}
+	var offset int
+	for offset+4 <= len(i) {
+		itm := &AITDescriptorApplicationNameItem{}
+		itm.Language = i[offset : offset+3]
+		offset += 3
+		var length = int(i[offset])
+		offset += 1
+		if offset+length > len(i) {
+			break
+		}
+		itm.Name = i[offset : offset+length]
+		offset += length
+		d.Items = append(d.Items, itm)
+	}
+	return
+}
+
+func newAITDescriptorApplicationUsage(i []byte) (d *AITDescriptorApplicationUsage) {
+	d = &AITDescriptorApplicationUsage{}
+	if len(i) > 0 {
+		d.UsageType = i[0]
+	}
+	return
+}
+
+func newAITDescriptorSimpleApplicationBoundary(i []byte) (d *AITDescriptorSimpleApplicationBoundary) {
+	d = &AITDescriptorSimpleApplicationBoundary{}
+	if len(i) < 1 {
+		return
+	}
+	var offset = 1
+	for idx := 0; idx < int(i[0]) && offset < len(i); idx++ {
+		var length = int(i[offset])
+		offset += 1
+		if offset+length > len(i) {
+			break
+		}
+		d.BoundaryExtensions = append(d.BoundaryExtensions, i[offset:offset+length])
+		offset += length
+	}
+	return
+}
+
+func newAITDescriptorTransportProtocol(i []byte) (d *AITDescriptorTransportProtocol) {
+	// Init
+	d = &AITDescriptorTransportProtocol{}
+	if len(i) < 3 {
+		return
+	}
+	d.ProtocolID = uint16(i[0])<<8 | uint16(i[1])
+	d.Label = i[2]
+	var b = i[3:]
+
+	// Switch on protocol
+	switch d.ProtocolID {
+	case AITTransportProtocolIDHTTP:
+		var offset int
+		for offset < len(b) {
+			// Base
+			var length = int(b[offset])
+			offset += 1
+			if offset+length+1 > len(b) {
+				break
+			}
+			var u = &AITDescriptorTransportProtocolHTTPURL{Base: b[offset : offset+length]}
+			offset += length
+
+			// Extensions
+			var count = int(b[offset])
+			offset += 1
+			for idx := 0; idx < count && offset < len(b); idx++ {
+				length = int(b[offset])
+				offset += 1
+				if offset+length > len(b) {
+					break
+				}
+				u.Extensions = append(u.Extensions, b[offset:offset+length])
+				offset += length
+			}
+			d.HTTP = append(d.HTTP, u)
+		}
+	case AITTransportProtocolIDObjectCarousel:
+		if len(b) < 2 {
+			break
+		}
+		d.ObjectCarousel = &AITDescriptorTransportProtocolObjectCarousel{Remote: b[0]&0x80 > 0}
+		var offset = 1
+		if d.ObjectCarousel.Remote && len(b) >= 8 {
+			d.ObjectCarousel.OriginalNetworkID = uint16(b[1])<<8 | uint16(b[2])
+			d.ObjectCarousel.TransportStreamID = uint16(b[3])<<8 | uint16(b[4])
+			d.ObjectCarousel.ServiceID = uint16(b[5])<<8 | uint16(b[6])
+			offset = 7
+		}
+		d.ObjectCarousel.ComponentTag = b[offset]
+	default:
+		d.SelectorBytes = make([]byte, len(b))
+		copy(d.SelectorBytes, b)
+	}
+	return
+}
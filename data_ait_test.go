@@ -0,0 +1,116 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/asticode/go-astitools/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+var ait = &AITData{
+	Applications: []*AITDataApplication{{
+		ApplicationID: 2,
+		ControlCode:   AITApplicationControlCodeAutostart,
+		Descriptors: []*AITDescriptor{
+			{Application: &AITDescriptorApplication{
+				Priority:                3,
+				Profiles:                []*AITDescriptorApplicationProfile{{Profile: 0x1, VersionMajor: 1, VersionMicro: 3, VersionMinor: 2}},
+				ServiceBound:            true,
+				TransportProtocolLabels: []uint8{1},
+				Visibility:              3,
+			}, Length: 9, Tag: AITDescriptorTagApplication},
+			{ApplicationName: &AITDescriptorApplicationName{Items: []*AITDescriptorApplicationNameItem{{Language: []byte("eng"), Name: []byte("app")}}}, Length: 7, Tag: AITDescriptorTagApplicationName},
+			{Length: 10, SimpleApplicationLocation: &AITDescriptorSimpleApplicationLocation{InitialPath: []byte("index.html")}, Tag: AITDescriptorTagSimpleApplicationLocation},
+			{ApplicationUsage: &AITDescriptorApplicationUsage{UsageType: 1}, Length: 1, Tag: AITDescriptorTagApplicationUsage},
+			{Length: 7, SimpleApplicationBoundary: &AITDescriptorSimpleApplicationBoundary{BoundaryExtensions: [][]byte{[]byte("http:")}}, Tag: AITDescriptorTagSimpleApplicationBoundary},
+			{Length: 1, PrivateBytes: []byte{0x1}, Tag: 0x5},
+		},
+		OrganisationID: 1,
+	}},
+	ApplicationType: AITApplicationTypeHbbTV,
+	CommonDescriptors: []*AITDescriptor{
+		{Length: 20, Tag: AITDescriptorTagTransportProtocol, TransportProtocol: &AITDescriptorTransportProtocol{
+			HTTP:       []*AITDescriptorTransportProtocolHTTPURL{{Base: []byte("http://a.b/"), Extensions: [][]byte{[]byte("ext")}}},
+			Label:      1,
+			ProtocolID: AITTransportProtocolIDHTTP,
+		}},
+		{Length: 5, Tag: AITDescriptorTagTransportProtocol, TransportProtocol: &AITDescriptorTransportProtocol{
+			Label:          2,
+			ObjectCarousel: &AITDescriptorTransportProtocolObjectCarousel{ComponentTag: 4},
+			ProtocolID:     AITTransportProtocolIDObjectCarousel,
+		}},
+	},
+	TestApplication: true,
+}
+
+func aitBytes() []byte {
+	w := astibinary.New()
+	w.Write("1111")                                           // Reserved
+	w.Write("000000011101")                                   // Common descriptors length
+	w.Write(uint8(AITDescriptorTagTransportProtocol))         // Transport protocol #1 tag
+	w.Write(uint8(20))                                        // Transport protocol #1 length
+	w.Write(uint16(AITTransportProtocolIDHTTP))               // Transport protocol #1 protocol ID
+	w.Write(uint8(1))                                         // Transport protocol #1 label
+	w.Write(uint8(11))                                        // Transport protocol #1 URL base length
+	w.Write([]byte("http://a.b/"))                            // Transport protocol #1 URL base
+	w.Write(uint8(1))                                         // Transport protocol #1 URL extension count
+	w.Write(uint8(3))                                         // Transport protocol #1 URL extension length
+	w.Write([]byte("ext"))                                    // Transport protocol #1 URL extension
+	w.Write(uint8(AITDescriptorTagTransportProtocol))         // Transport protocol #2 tag
+	w.Write(uint8(5))                                         // Transport protocol #2 length
+	w.Write(uint16(AITTransportProtocolIDObjectCarousel))     // Transport protocol #2 protocol ID
+	w.Write(uint8(2))                                         // Transport protocol #2 label
+	w.Write("0")                                              // Transport protocol #2 remote connection
+	w.Write("1111111")                                        // Transport protocol #2 reserved
+	w.Write(uint8(4))                                         // Transport protocol #2 component tag
+	w.Write("1111")                                           // Reserved
+	w.Write("000000111000")                                   // Application loop length
+	w.Write(uint32(1))                                        // Organisation ID
+	w.Write(uint16(2))                                        // Application ID
+	w.Write(uint8(AITApplicationControlCodeAutostart))        // Application control code
+	w.Write("1111")                                           // Reserved
+	w.Write("000000101111")                                   // Application descriptors loop length
+	w.Write(uint8(AITDescriptorTagApplication))               // Application tag
+	w.Write(uint8(9))                                         // Application length
+	w.Write(uint8(5))                                         // Application profiles length
+	w.Write(uint16(1))                                        // Application profile
+	w.Write([]byte{1, 2, 3})                                  // Application version
+	w.Write("1")                                              // Application service bound flag
+	w.Write("11")                                             // Application visibility
+	w.Write("11111")                                          // Application reserved
+	w.Write(uint8(3))                                         // Application priority
+	w.Write(uint8(1))                                         // Application transport protocol label
+	w.Write(uint8(AITDescriptorTagApplicationName))           // Application name tag
+	w.Write(uint8(7))                                         // Application name length
+	w.Write([]byte("eng"))                                    // Application name language
+	w.Write(uint8(3))                                         // Application name name length
+	w.Write([]byte("app"))                                    // Application name name
+	w.Write(uint8(AITDescriptorTagSimpleApplicationLocation)) // Simple application location tag
+	w.Write(uint8(10))                                        // Simple application location length
+	w.Write([]byte("index.html"))                             // Simple application location initial path
+	w.Write(uint8(AITDescriptorTagApplicationUsage))          // Application usage tag
+	w.Write(uint8(1))                                         // Application usage length
+	w.Write(uint8(1))                                         // Application usage type
+	w.Write(uint8(AITDescriptorTagSimpleApplicationBoundary)) // Simple application boundary tag
+	w.Write(uint8(7))                                         // Simple application boundary length
+	w.Write(uint8(1))                                         // Simple application boundary extension count
+	w.Write(uint8(5))                                         // Simple application boundary extension length
+	w.Write([]byte("http:"))                                  // Simple application boundary extension
+	w.Write(uint8(0x5))                                       // Unknown tag
+	w.Write(uint8(1))                                         // Unknown length
+	w.Write(uint8(1))                                         // Unknown data
+	return w.Bytes()
+}
+
+func TestParseAITSection(t *testing.T) {
+	var offset int
+	var b = aitBytes()
+	d := parseAITSection(b, &offset, len(b), 0x8000|AITApplicationTypeHbbTV)
+	assert.Equal(t, ait, d)
+	assert.Equal(t, len(b), offset)
+}
+
+func TestAITDataURLs(t *testing.T) {
+	assert.Equal(t, []string{"http://a.b/index.html"}, ait.URLs(ait.Applications[0]))
+	assert.Empty(t, ait.URLs(&AITDataApplication{Descriptors: []*AITDescriptor{{Application: &AITDescriptorApplication{TransportProtocolLabels: []uint8{2}}}}}))
+}
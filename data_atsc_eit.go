@@ -0,0 +1,63 @@
+package astits
+
+import "time"
+
+// ATSCEITData represents an ATSC EIT data
+// Page: 45 | Chapter: 6.5 | Link: https://www.atsc.org/wp-content/uploads/2015/03/Program-System-Information-Protocol-for-Terrestrial-Broadcast-and-Cable.pdf
+type ATSCEITData struct {
+	Events          []*ATSCEITDataEvent
+	ProtocolVersion uint8
+	SourceID        uint16
+}
+
+// ATSCEITDataEvent represents an ATSC EIT data event
+type ATSCEITDataEvent struct {
+	Descriptors []*Descriptor
+	Duration    time.Duration
+	ETMLocation uint8
+	EventID     uint16
+	StartTime   time.Time // GPS time, ahead of UTC by the GPS UTC offset of the STT
+	Title       *ATSCMultipleString
+}
+
+// parseATSCEITSection parses an ATSC EIT section
+func parseATSCEITSection(i []byte, offset *int, tableIDExtension uint16) (d *ATSCEITData) {
+	// Init
+	d = &ATSCEITData{SourceID: tableIDExtension}
+
+	// Protocol version
+	d.ProtocolVersion = uint8(i[*offset])
+	*offset += 1
+
+	// Number of events in section
+	var numEvents = int(i[*offset])
+	*offset += 1
+
+	// Loop through events
+	for idx := 0; idx < numEvents; idx++ {
+		// Event ID
+		var e = &ATSCEITDataEvent{}
+		e.EventID = uint16(i[*offset]&0x3f)<<8 | uint16(i[*offset+1])
+		*offset += 2
+
+		// Start time
+		e.StartTime = parseATSCTime(i, offset)
+
+		// ETM location
+		e.ETMLocation = uint8(i[*offset]>>4) & 0x3
+
+		// Duration
+		e.Duration = time.Duration(uint32(i[*offset]&0xf)<<16|uint32(i[*offset+1])<<8|uint32(i[*offset+2])) * time.Second
+		*offset += 3
+
+		// Title
+		e.Title = parseATSCMultipleStringWithLength(i, offset)
+
+		// Descriptors
+		e.Descriptors = parseDescriptors(i, offset)
+
+		// Append event
+		d.Events = append(d.Events, e)
+	}
+	return
+}
@@ -0,0 +1,48 @@
+package astits
+
+import (
+	"testing"
+	"time"
+
+	"github.com/asticode/go-astitools/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+var atscEIT = &ATSCEITData{
+	Events: []*ATSCEITDataEvent{{
+		Descriptors: descriptors,
+		Duration:    time.Hour,
+		ETMLocation: VCTETMLocationThisPTC,
+		EventID:     2,
+		StartTime:   time.Date(2011, 9, 14, 1, 47, 58, 0, time.UTC),
+		Title:       atscMultipleString,
+	}},
+	SourceID: 1,
+}
+
+func atscEITBytes() []byte {
+	w := astibinary.New()
+	m := astibinary.New()
+	atscMultipleStringBytes(m)
+	w.Write(uint8(0))               // Protocol version
+	w.Write(uint8(1))               // Number of events in section
+	w.Write("11")                   // Event #1 reserved
+	w.Write("00000000000010")       // Event #1 ID
+	w.Write(uint32(1000000078))     // Event #1 start time
+	w.Write("11")                   // Event #1 reserved
+	w.Write("01")                   // Event #1 ETM location
+	w.Write("00000000111000010000") // Event #1 length in seconds
+	w.Write(uint8(len(m.Bytes())))  // Event #1 title length
+	w.Write(m.Bytes())              // Event #1 title
+	w.Write("1111")                 // Event #1 reserved
+	descriptorsBytes(w)             // Event #1 descriptors
+	return w.Bytes()
+}
+
+func TestParseATSCEITSection(t *testing.T) {
+	var offset int
+	var b = atscEITBytes()
+	d := parseATSCEITSection(b, &offset, uint16(1))
+	assert.Equal(t, atscEIT, d)
+	assert.Equal(t, len(b), offset)
+}
@@ -0,0 +1,49 @@
+package astits
+
+// BATData represents a BAT data
+// Page: 32 | Chapter: 5.2.2 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+type BATData struct {
+	BouquetDescriptors []*Descriptor
+	BouquetID          uint16
+	TransportStreams   []*BATDataTransportStream
+}
+
+// BATDataTransportStream represents a BAT data transport stream
+type BATDataTransportStream struct {
+	OriginalNetworkID    uint16
+	TransportDescriptors []*Descriptor
+	TransportStreamID    uint16
+}
+
+// parseBATSection parses a BAT section
+func parseBATSection(i []byte, offset *int, tableIDExtension uint16) (d *BATData) {
+	// Init
+	d = &BATData{BouquetID: tableIDExtension}
+
+	// Bouquet descriptors
+	d.BouquetDescriptors = parseDescriptors(i, offset)
+
+	// Transport stream loop length
+	var transportStreamLoopLength = int(uint16(i[*offset]&0xf)<<8 | uint16(i[*offset+1]))
+	*offset += 2
+
+	// Transport stream loop
+	transportStreamLoopLength += *offset
+	for *offset < transportStreamLoopLength {
+		// Transport stream ID
+		var ts = &BATDataTransportStream{}
+		ts.TransportStreamID = uint16(i[*offset])<<8 | uint16(i[*offset+1])
+		*offset += 2
+
+		// Original network ID
+		ts.OriginalNetworkID = uint16(i[*offset])<<8 | uint16(i[*offset+1])
+		*offset += 2
+
+		// Transport descriptors
+		ts.TransportDescriptors = parseDescriptors(i, offset)
+
+		// Append transport stream
+		d.TransportStreams = append(d.TransportStreams, ts)
+	}
+	return
+}
@@ -0,0 +1,43 @@
+package astits
+
+// BITData represents an ISDB BIT data
+// Chapter: 5.2.13 | ARIB STD-B10
+type BITData struct {
+	BroadcastViewPropriety bool // When true, the broadcasters of the network should be viewed as a single unit
+	Broadcasters           []*BITDataBroadcaster
+	Descriptors            []*Descriptor
+	OriginalNetworkID      uint16
+}
+
+// BITDataBroadcaster represents a BIT data broadcaster
+type BITDataBroadcaster struct {
+	BroadcasterID uint8
+	Descriptors   []*Descriptor
+}
+
+// parseBITSection parses a BIT section
+func parseBITSection(i []byte, offset *int, offsetSectionsEnd int, tableIDExtension uint16) (d *BITData) {
+	// Init
+	d = &BITData{OriginalNetworkID: tableIDExtension}
+
+	// Broadcast view propriety
+	d.BroadcastViewPropriety = i[*offset]&0x10 > 0
+
+	// Descriptors
+	d.Descriptors = parseDescriptors(i, offset)
+
+	// Loop until end of section data is reached
+	for *offset < offsetSectionsEnd {
+		// Broadcaster ID
+		var b = &BITDataBroadcaster{}
+		b.BroadcasterID = uint8(i[*offset])
+		*offset += 1
+
+		// Descriptors
+		b.Descriptors = parseDescriptors(i, offset)
+
+		// Append broadcaster
+		d.Broadcasters = append(d.Broadcasters, b)
+	}
+	return
+}
@@ -0,0 +1,37 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/asticode/go-astitools/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+var bit = &BITData{
+	BroadcastViewPropriety: true,
+	Broadcasters: []*BITDataBroadcaster{{
+		BroadcasterID: 2,
+		Descriptors:   descriptors,
+	}},
+	Descriptors:       descriptors,
+	OriginalNetworkID: 1,
+}
+
+func bitBytes() []byte {
+	w := astibinary.New()
+	w.Write("111")      // Reserved for future use
+	w.Write("1")        // Broadcast view propriety
+	descriptorsBytes(w) // First descriptors
+	w.Write(uint8(2))   // Broadcaster #1 ID
+	w.Write("1111")     // Broadcaster #1 reserved for future use
+	descriptorsBytes(w) // Broadcaster #1 descriptors
+	return w.Bytes()
+}
+
+func TestParseBITSection(t *testing.T) {
+	var offset int
+	var b = bitBytes()
+	d := parseBITSection(b, &offset, len(b), uint16(1))
+	assert.Equal(t, bit, d)
+	assert.Equal(t, len(b), offset)
+}
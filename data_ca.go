@@ -0,0 +1,56 @@
+package astits
+
+// CA message table IDs
+// ECMs use the 2 first table IDs to signal the key parity, EMMs use the other ones in a CA system specific way
+// Page: 11 | Chapter: 5.5 | Link: https://www.etsi.org/deliver/etsi_tr/101200_101299/101289/01.01.01_60/tr_101289v010101p.pdf
+const (
+	CAMessageTableIDECMEven = 0x80
+	CAMessageTableIDECMOdd  = 0x81
+	CAMessageTableIDEMMMax  = 0x8f
+	CAMessageTableIDEMMMin  = 0x82
+)
+
+// CAMessageData represents a CA message, i.e. an ECM or an EMM, carried in a private section whose content is specific
+// to the CA system
+// Page: 11 | Chapter: 5.5 | Link: https://www.etsi.org/deliver/etsi_tr/101200_101299/101289/01.01.01_60/tr_101289v010101p.pdf
+type CAMessageData struct {
+	CASystemID uint16 // CA system ID of the CA descriptor announcing the PID in the CAT or in a PMT
+	Data       []byte // Section content following the section length
+	TableID    uint8
+}
+
+// parseCAMessageSection parses a CA message section and returns it either as an ECM or as an EMM depending on its
+// table ID
+// CA message table IDs are user defined, therefore sections are only parsed as CA messages once their PID has been
+// announced by a CA descriptor
+func parseCAMessageSection(i []byte, caSystemID uint16) (ecm, emm *CAMessageData) {
+	// Check length
+	if len(i) < 3 {
+		return
+	}
+
+	// Create message
+	var d = &CAMessageData{
+		CASystemID: caSystemID,
+		Data:       i[3:],
+		TableID:    i[0],
+	}
+
+	// Switch on table ID
+	switch {
+	case d.TableID == CAMessageTableIDECMEven, d.TableID == CAMessageTableIDECMOdd:
+		ecm = d
+	case d.TableID >= CAMessageTableIDEMMMin && d.TableID <= CAMessageTableIDEMMMax:
+		emm = d
+	}
+	return
+}
+
+// updateCAMessageData parses the sections received on the ECM and EMM PIDs as CA messages
+func (dmx *Demuxer) updateCAMessageData(ds []*Data) {
+	for _, d := range ds {
+		if caSystemID, ok := dmx.caSystemIDs[d.PID]; ok && d.RawSection != nil {
+			d.ECM, d.EMM = parseCAMessageSection(d.RawSection, caSystemID)
+		}
+	}
+}
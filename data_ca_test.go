@@ -0,0 +1,81 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/asticode/go-astitools/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCAMessageSection(t *testing.T) {
+	// ECM
+	ecm, emm := parseCAMessageSection([]byte{CAMessageTableIDECMOdd, 0x30, 0x2, 0x12, 0x34}, 0x2600)
+	assert.Equal(t, &CAMessageData{CASystemID: 0x2600, Data: []byte{0x12, 0x34}, TableID: CAMessageTableIDECMOdd}, ecm)
+	assert.Nil(t, emm)
+
+	// EMM
+	ecm, emm = parseCAMessageSection([]byte{0x85, 0x30, 0x1, 0x12}, 0x2600)
+	assert.Nil(t, ecm)
+	assert.Equal(t, &CAMessageData{CASystemID: 0x2600, Data: []byte{0x12}, TableID: 0x85}, emm)
+
+	// Other table
+	ecm, emm = parseCAMessageSection([]byte{0x90, 0x30, 0x0}, 0x2600)
+	assert.Nil(t, ecm)
+	assert.Nil(t, emm)
+}
+
+func TestDemuxerCAMessages(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	pm, _ := (&PMTData{
+		ElementaryStreams: []*PMTElementaryStream{{ElementaryPID: 0x102, StreamType: StreamTypeLowerBitrateVideo}},
+		PCRPID:            0x1fff,
+		ProgramDescriptors: []*Descriptor{{
+			CA:  &DescriptorCA{CAPID: 0x101, CASystemID: 0x2610},
+			Tag: DescriptorTagCA,
+		}},
+		ProgramNumber: 1,
+	}).Serialize(0)
+	for _, v := range []struct {
+		b   []byte
+		pid uint16
+	}{
+		{b: writePSISection(&PSISectionHeader{SectionSyntaxIndicator: true, TableID: 0x1}, &PSISectionSyntaxHeader{CurrentNextIndicator: true}, catBytes()), pid: PIDCAT},
+		{b: pm, pid: 0x200},
+		{b: []byte{CAMessageTableIDECMEven, 0x30, 0x2, 0x12, 0x34}, pid: 0x101},
+		{b: []byte{0x82, 0x30, 0x1, 0x56}, pid: 0x100},
+	} {
+		var p = append([]byte{0x0}, v.b...)
+		p = append(p, bytes.Repeat([]byte{0xff}, 147-len(p))...)
+		b, _ := packet(PacketHeader{PayloadUnitStartIndicator: true, PID: v.pid}, PacketAdaptationField{}, p)
+		w.Write(b)
+		b, _ = packet(PacketHeader{ContinuityCounter: uint8(1), PayloadUnitStartIndicator: true, PID: v.pid}, PacketAdaptationField{}, []byte{})
+		w.Write(b)
+	}
+	dmx := New(context.Background(), bytes.NewReader(w.Bytes()))
+	dmx.programMap.set(0x200, 1)
+
+	// CAT
+	d, err := dmx.NextData()
+	assert.NoError(t, err)
+	assert.NotNil(t, d.CAT)
+	assert.Equal(t, map[uint16]uint16{0x100: 0x500}, dmx.caSystemIDs)
+
+	// PMT
+	d, err = dmx.NextData()
+	assert.NoError(t, err)
+	assert.NotNil(t, d.PMT)
+	assert.Equal(t, map[uint16]uint16{0x100: 0x500, 0x101: 0x2610}, dmx.caSystemIDs)
+
+	// ECM
+	d, err = dmx.NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, &CAMessageData{CASystemID: 0x2610, Data: []byte{0x12, 0x34}, TableID: CAMessageTableIDECMEven}, d.ECM)
+
+	// EMM
+	d, err = dmx.NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, &CAMessageData{CASystemID: 0x500, Data: []byte{0x56}, TableID: 0x82}, d.EMM)
+}
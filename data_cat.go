@@ -0,0 +1,28 @@
+package astits
+
+// CATData represents a CAT data
+// Page: 42 | Chapter: 2.4.4.6 | Link: http://ecee.colorado.edu/~ecen5653/ecen5653/papers/iso13818-1.pdf
+type CATData struct {
+	Descriptors []*Descriptor // CA descriptors pointing at EMM PIDs
+}
+
+// parseCATSection parses a CAT section
+func parseCATSection(i []byte, offset *int, offsetSectionsEnd int) (d *CATData) {
+	// Init
+	d = &CATData{}
+
+	// Descriptors
+	d.Descriptors = parseDescriptorsLoop(i, offset, offsetSectionsEnd)
+	return
+}
+
+// EMMPIDs returns the PIDs carrying EMMs indexed by CA system ID
+func (d *CATData) EMMPIDs() (o map[uint16][]uint16) {
+	o = make(map[uint16][]uint16)
+	for _, dsc := range d.Descriptors {
+		if dsc.CA != nil {
+			o[dsc.CA.CASystemID] = append(o[dsc.CA.CASystemID], dsc.CA.CAPID)
+		}
+	}
+	return
+}
@@ -0,0 +1,37 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/asticode/go-astitools/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+var cat = &CATData{Descriptors: []*Descriptor{{
+	CA:     &DescriptorCA{CAPID: 0x100, CASystemID: 0x500, PrivateData: []byte("private")},
+	Length: 11,
+	Tag:    DescriptorTagCA,
+}}}
+
+func catBytes() []byte {
+	w := astibinary.New()
+	w.Write(uint8(DescriptorTagCA)) // Tag
+	w.Write(uint8(11))              // Length
+	w.Write(uint16(0x500))          // CA system ID
+	w.Write("111")                  // Reserved
+	w.Write("0000100000000")        // CA PID
+	w.Write([]byte("private"))      // Private data
+	return w.Bytes()
+}
+
+func TestParseCATSection(t *testing.T) {
+	var offset int
+	var b = catBytes()
+	d := parseCATSection(b, &offset, len(b))
+	assert.Equal(t, cat, d)
+	assert.Equal(t, len(b), offset)
+}
+
+func TestCATDataEMMPIDs(t *testing.T) {
+	assert.Equal(t, map[uint16][]uint16{0x500: {0x100}}, cat.EMMPIDs())
+}
@@ -0,0 +1,14 @@
+package astits
+
+// DITData represents a DIT data
+// Page: 84 | Chapter: 7.1.1 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+type DITData struct {
+	TransitionFlag bool // When true indicates that the transition is due to a change of the originating source
+}
+
+// parseDITSection parses a DIT section
+func parseDITSection(i []byte, offset *int) (d *DITData) {
+	d = &DITData{TransitionFlag: i[*offset]&0x80 > 0}
+	*offset += 1
+	return
+}
@@ -0,0 +1,14 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDITSection(t *testing.T) {
+	var offset int
+	d := parseDITSection([]byte{0xff}, &offset)
+	assert.Equal(t, &DITData{TransitionFlag: true}, d)
+	assert.Equal(t, 1, offset)
+}
@@ -0,0 +1,310 @@
+package astits
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// DSM-CC message IDs
+// Page: 101 | Chapter: 7.3 | Link: ISO/IEC 13818-6
+const (
+	DSMCCMessageIDDownloadDataBlock      = 0x1003
+	DSMCCMessageIDDownloadInfoIndication = 0x1002
+	DSMCCMessageIDDownloadServerInitiate = 0x1006
+)
+
+// DSM-CC constants
+const (
+	dsmccDescriptorTagCompressedModule = 0x09
+	dsmccProtocolDiscriminator         = 0x11
+	dsmccServerIDLength                = 20
+	dsmccTypeDownload                  = 0x03
+)
+
+// DSMCCData represents a DSM-CC data, i.e. a download message carried in a DSM-CC section
+// Messages that are truncated or that are not download messages are left unparsed
+// Page: 292 | Chapter: 9.2.2 | Link: ISO/IEC 13818-6
+type DSMCCData struct {
+	DDB           *DSMCCDownloadDataBlock
+	DII           *DSMCCDownloadInfoIndication
+	DSI           *DSMCCDownloadServerInitiate
+	MessageID     uint16
+	TransactionID uint32 // Download ID for download data blocks
+}
+
+// DSMCCDownloadDataBlock represents a DSM-CC DownloadDataBlock message, which carries a block of a module
+// Page: 113 | Chapter: 7.3.7 | Link: ISO/IEC 13818-6
+type DSMCCDownloadDataBlock struct {
+	BlockNumber   uint16
+	Data          []byte
+	ModuleID      uint16
+	ModuleVersion uint8
+}
+
+// DSMCCDownloadInfoIndication represents a DSM-CC DownloadInfoIndication message, which describes the modules of a
+// carousel
+// Page: 110 | Chapter: 7.3.6 | Link: ISO/IEC 13818-6
+type DSMCCDownloadInfoIndication struct {
+	BlockSize   uint16
+	DownloadID  uint32
+	Modules     []*DSMCCDownloadInfoIndicationModule
+	PrivateData []byte
+}
+
+// DSMCCDownloadInfoIndicationModule represents a module described by a DSM-CC DownloadInfoIndication message
+type DSMCCDownloadInfoIndicationModule struct {
+	Compressed   bool // Set when the module is compressed with zlib, as signalled by a compressed module descriptor
+	ID           uint16
+	Info         []byte
+	OriginalSize uint32 // Size of the module once decompressed
+	Size         uint32
+	Version      uint8
+}
+
+// DSMCCDownloadServerInitiate represents a DSM-CC DownloadServerInitiate message, which points to the service gateway
+// of an object carousel
+// Page: 115 | Chapter: 7.3.8 | Link: ISO/IEC 13818-6
+type DSMCCDownloadServerInitiate struct {
+	PrivateData    []byte
+	ServerID       []byte
+	ServiceGateway *DSMCCIOR // Set when the private data starts with the IOR of the service gateway
+}
+
+// parseDSMCCSection parses a DSM-CC section
+func parseDSMCCSection(i []byte, offset *int, offsetSectionsEnd int) (d *DSMCCData) {
+	// Init
+	d = &DSMCCData{}
+	var r = newBitReader(i[*offset:offsetSectionsEnd])
+	*offset = offsetSectionsEnd
+
+	// Parse header
+	var m []byte
+	var err error
+	if m, err = parseDSMCCMessageHeader(r, d); err != nil {
+		return
+	}
+
+	// Switch on message ID
+	switch d.MessageID {
+	case DSMCCMessageIDDownloadDataBlock:
+		d.DDB, _ = newDSMCCDownloadDataBlock(m)
+	case DSMCCMessageIDDownloadInfoIndication:
+		d.DII, _ = newDSMCCDownloadInfoIndication(m)
+	case DSMCCMessageIDDownloadServerInitiate:
+		d.DSI, _ = newDSMCCDownloadServerInitiate(m)
+	}
+	return
+}
+
+// parseDSMCCMessageHeader parses a DSM-CC message header, or a download data header which shares the same syntax,
+// and returns the message
+// Page: 18 | Chapter: 2 | Link: ISO/IEC 13818-6
+func parseDSMCCMessageHeader(r *bitReader, d *DSMCCData) (m []byte, err error) {
+	// Protocol discriminator and type
+	var b []byte
+	if b, err = r.readBytes(4); err != nil {
+		err = errors.Wrap(err, "astits: reading DSM-CC message header failed")
+		return
+	} else if b[0] != dsmccProtocolDiscriminator || b[1] != dsmccTypeDownload {
+		err = fmt.Errorf("astits: DSM-CC message is not a download message (protocol discriminator 0x%x, type 0x%x)", b[0], b[1])
+		return
+	}
+	d.MessageID = uint16(b[2])<<8 | uint16(b[3])
+
+	// Transaction ID
+	var v uint32
+	if v, err = r.read(32); err != nil {
+		err = errors.Wrap(err, "astits: reading DSM-CC transaction ID failed")
+		return
+	}
+	d.TransactionID = v
+
+	// Reserved, adaptation length and message length
+	if b, err = r.readBytes(4); err != nil {
+		err = errors.Wrap(err, "astits: reading DSM-CC message length failed")
+		return
+	}
+	var adaptationLength, messageLength = int(b[1]), int(uint16(b[2])<<8 | uint16(b[3]))
+	if adaptationLength > messageLength {
+		err = fmt.Errorf("astits: DSM-CC adaptation length %d > message length %d", adaptationLength, messageLength)
+		return
+	}
+
+	// Adaptation header and message
+	if _, err = r.readBytes(adaptationLength); err != nil {
+		err = errors.Wrap(err, "astits: reading DSM-CC adaptation header failed")
+		return
+	}
+	if m, err = r.readBytes(messageLength - adaptationLength); err != nil {
+		err = errors.Wrap(err, "astits: reading DSM-CC message failed")
+		return
+	}
+	return
+}
+
+func newDSMCCDownloadDataBlock(i []byte) (d *DSMCCDownloadDataBlock, err error) {
+	// Check length
+	if len(i) < 6 {
+		err = fmt.Errorf("astits: DSM-CC download data block header end (6) > len(i) (%d)", len(i))
+		return
+	}
+
+	// Create block
+	d = &DSMCCDownloadDataBlock{
+		BlockNumber:   uint16(i[4])<<8 | uint16(i[5]),
+		Data:          i[6:],
+		ModuleID:      uint16(i[0])<<8 | uint16(i[1]),
+		ModuleVersion: i[2],
+	}
+	return
+}
+
+func newDSMCCDownloadInfoIndication(i []byte) (d *DSMCCDownloadInfoIndication, err error) {
+	// Init
+	var r = newBitReader(i)
+	var tmp = &DSMCCDownloadInfoIndication{}
+
+	// Download ID and block size
+	var v uint32
+	if v, err = r.read(32); err != nil {
+		err = errors.Wrap(err, "astits: reading download ID failed")
+		return
+	}
+	tmp.DownloadID = v
+	if v, err = r.read(16); err != nil {
+		err = errors.Wrap(err, "astits: reading block size failed")
+		return
+	}
+	tmp.BlockSize = uint16(v)
+
+	// Window size, ack period, tCDownloadWindow and tCDownloadScenario
+	if _, err = r.readBytes(10); err != nil {
+		err = errors.Wrap(err, "astits: reading download window failed")
+		return
+	}
+
+	// Compatibility descriptor
+	if _, err = readDSMCCLengthPrefixedBytes(r, 16); err != nil {
+		err = errors.Wrap(err, "astits: reading compatibility descriptor failed")
+		return
+	}
+
+	// Number of modules
+	if v, err = r.read(16); err != nil {
+		err = errors.Wrap(err, "astits: reading number of modules failed")
+		return
+	}
+
+	// Loop through modules
+	for idx := 0; idx < int(v); idx++ {
+		// Module ID, size and version
+		var b []byte
+		if b, err = r.readBytes(7); err != nil {
+			err = errors.Wrapf(err, "astits: reading module #%d failed", idx+1)
+			return
+		}
+		var m = &DSMCCDownloadInfoIndicationModule{
+			ID:      uint16(b[0])<<8 | uint16(b[1]),
+			Size:    uint32(b[2])<<24 | uint32(b[3])<<16 | uint32(b[4])<<8 | uint32(b[5]),
+			Version: b[6],
+		}
+
+		// Module info
+		if m.Info, err = readDSMCCLengthPrefixedBytes(r, 8); err != nil {
+			err = errors.Wrapf(err, "astits: reading module #%d info failed", idx+1)
+			return
+		}
+		m.Compressed, m.OriginalSize = parseDSMCCModuleInfoCompression(m.Info)
+		tmp.Modules = append(tmp.Modules, m)
+	}
+
+	// Private data
+	if tmp.PrivateData, err = readDSMCCLengthPrefixedBytes(r, 16); err != nil {
+		err = errors.Wrap(err, "astits: reading private data failed")
+		return
+	}
+	d = tmp
+	return
+}
+
+// parseDSMCCModuleInfoCompression looks for a compressed module descriptor in the user info of a BIOP module info
+// Page: 35 | Chapter: 4.7.3.2 | Link: https://www.etsi.org/deliver/etsi_tr/101200_101299/101202/01.02.01_60/tr_101202v010201p.pdf
+func parseDSMCCModuleInfoCompression(i []byte) (compressed bool, originalSize uint32) {
+	// Module timeout, block timeout and min block time
+	var r = newBitReader(i)
+	if _, err := r.readBytes(12); err != nil {
+		return
+	}
+
+	// Taps
+	v, err := r.read(8)
+	if err != nil {
+		return
+	}
+	for idx := 0; idx < int(v); idx++ {
+		if _, err = r.readBytes(6); err != nil {
+			return
+		}
+		if _, err = readDSMCCLengthPrefixedBytes(r, 8); err != nil {
+			return
+		}
+	}
+
+	// User info
+	b, err := readDSMCCLengthPrefixedBytes(r, 8)
+	if err != nil {
+		return
+	}
+	for offset := 0; offset+2 <= len(b); offset += 2 + int(b[offset+1]) {
+		if b[offset] == dsmccDescriptorTagCompressedModule && b[offset+1] >= 5 && offset+7 <= len(b) {
+			return true, uint32(b[offset+3])<<24 | uint32(b[offset+4])<<16 | uint32(b[offset+5])<<8 | uint32(b[offset+6])
+		}
+	}
+	return
+}
+
+func newDSMCCDownloadServerInitiate(i []byte) (d *DSMCCDownloadServerInitiate, err error) {
+	// Init
+	var r = newBitReader(i)
+	var tmp = &DSMCCDownloadServerInitiate{}
+
+	// Server ID
+	if tmp.ServerID, err = r.readBytes(dsmccServerIDLength); err != nil {
+		err = errors.Wrap(err, "astits: reading server ID failed")
+		return
+	}
+
+	// Compatibility descriptor
+	if _, err = readDSMCCLengthPrefixedBytes(r, 16); err != nil {
+		err = errors.Wrap(err, "astits: reading compatibility descriptor failed")
+		return
+	}
+
+	// Private data
+	if tmp.PrivateData, err = readDSMCCLengthPrefixedBytes(r, 16); err != nil {
+		err = errors.Wrap(err, "astits: reading private data failed")
+		return
+	}
+
+	// Service gateway, private data of data carousels are not IORs
+	if ior, err := parseDSMCCIOR(newBitReader(tmp.PrivateData)); err == nil {
+		tmp.ServiceGateway = ior
+	}
+	d = tmp
+	return
+}
+
+// readDSMCCLengthPrefixedBytes reads bytes prefixed with their length coded on n bits
+func readDSMCCLengthPrefixedBytes(r *bitReader, n int) (b []byte, err error) {
+	var l uint32
+	if l, err = r.read(n); err != nil {
+		err = errors.Wrap(err, "astits: reading length failed")
+		return
+	}
+	if b, err = r.readBytes(int(l)); err != nil {
+		err = errors.Wrap(err, "astits: reading bytes failed")
+		return
+	}
+	return
+}
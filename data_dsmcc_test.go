@@ -0,0 +1,92 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func dsmccMessageBytes(messageID uint16, transactionID uint32, m []byte) []byte {
+	return append([]byte{dsmccProtocolDiscriminator, dsmccTypeDownload, uint8(messageID >> 8), uint8(messageID), uint8(transactionID >> 24), uint8(transactionID >> 16), uint8(transactionID >> 8), uint8(transactionID), 0xff, 0x1, uint8((len(m) + 1) >> 8), uint8(len(m) + 1), 0xaa}, m...)
+}
+
+func dsmccDIIBytes(downloadID uint32, blockSize uint16, modules ...[]byte) []byte {
+	var b = []byte{uint8(downloadID >> 24), uint8(downloadID >> 16), uint8(downloadID >> 8), uint8(downloadID), uint8(blockSize >> 8), uint8(blockSize), 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, uint8(len(modules))}
+	for _, m := range modules {
+		b = append(b, m...)
+	}
+	return append(b, 0x0, 0x1, 0x2)
+}
+
+func dsmccDIIModuleBytes(id uint16, size uint32, version uint8, compressedSize uint32) []byte {
+	var info = make([]byte, 13)
+	if compressedSize > 0 {
+		info = append(info, 7, dsmccDescriptorTagCompressedModule, 5, 0x8, uint8(compressedSize>>24), uint8(compressedSize>>16), uint8(compressedSize>>8), uint8(compressedSize))
+	} else {
+		info = append(info, 0)
+	}
+	return append([]byte{uint8(id >> 8), uint8(id), uint8(size >> 24), uint8(size >> 16), uint8(size >> 8), uint8(size), version, uint8(len(info))}, info...)
+}
+
+func dsmccDDBBytes(moduleID uint16, version uint8, blockNumber uint16, data []byte) []byte {
+	return append([]byte{uint8(moduleID >> 8), uint8(moduleID), version, 0xff, uint8(blockNumber >> 8), uint8(blockNumber)}, data...)
+}
+
+func dsmccDSIBytes(private []byte) []byte {
+	var b = make([]byte, dsmccServerIDLength)
+	for idx := range b {
+		b[idx] = 0xff
+	}
+	return append(append(b, 0x0, 0x0, uint8(len(private)>>8), uint8(len(private))), private...)
+}
+
+func TestParseDSMCCSection(t *testing.T) {
+	// DII
+	var b = dsmccMessageBytes(DSMCCMessageIDDownloadInfoIndication, 0x80000002, dsmccDIIBytes(1, 4066, dsmccDIIModuleBytes(1, 10, 2, 0), dsmccDIIModuleBytes(2, 20, 3, 40)))
+	var offset int
+	d := parseDSMCCSection(b, &offset, len(b))
+	assert.Equal(t, len(b), offset)
+	assert.Equal(t, uint16(DSMCCMessageIDDownloadInfoIndication), d.MessageID)
+	assert.Equal(t, uint32(0x80000002), d.TransactionID)
+	assert.Equal(t, &DSMCCDownloadInfoIndication{
+		BlockSize:  4066,
+		DownloadID: 1,
+		Modules: []*DSMCCDownloadInfoIndicationModule{
+			{ID: 1, Info: make([]byte, 14), Size: 10, Version: 2},
+			{Compressed: true, ID: 2, Info: append(make([]byte, 13), 7, dsmccDescriptorTagCompressedModule, 5, 0x8, 0x0, 0x0, 0x0, 40), OriginalSize: 40, Size: 20, Version: 3},
+		},
+		PrivateData: []byte{0x2},
+	}, d.DII)
+
+	// DDB
+	b = dsmccMessageBytes(DSMCCMessageIDDownloadDataBlock, 1, dsmccDDBBytes(2, 3, 4, []byte("data")))
+	offset = 0
+	d = parseDSMCCSection(b, &offset, len(b))
+	assert.Equal(t, &DSMCCDownloadDataBlock{BlockNumber: 4, Data: []byte("data"), ModuleID: 2, ModuleVersion: 3}, d.DDB)
+
+	// DSI of a data carousel
+	b = dsmccMessageBytes(DSMCCMessageIDDownloadServerInitiate, 0x80000000, dsmccDSIBytes([]byte{0x1, 0x2}))
+	offset = 0
+	d = parseDSMCCSection(b, &offset, len(b))
+	assert.Equal(t, []byte{0x1, 0x2}, d.DSI.PrivateData)
+	assert.Len(t, d.DSI.ServerID, dsmccServerIDLength)
+	assert.Nil(t, d.DSI.ServiceGateway)
+
+	// Not a download message
+	b = []byte{0x11, 0x2, 0x10, 0x2}
+	offset = 0
+	d = parseDSMCCSection(b, &offset, len(b))
+	assert.Equal(t, len(b), offset)
+	assert.Equal(t, &DSMCCData{}, d)
+
+	// Truncated message
+	b = dsmccMessageBytes(DSMCCMessageIDDownloadInfoIndication, 2, dsmccDIIBytes(1, 4066, dsmccDIIModuleBytes(1, 10, 2, 0)))
+	b[11] += 2
+	offset = 0
+	d = parseDSMCCSection(b, &offset, len(b))
+	assert.Equal(t, &DSMCCData{MessageID: DSMCCMessageIDDownloadInfoIndication, TransactionID: 2}, d)
+	b = dsmccMessageBytes(DSMCCMessageIDDownloadInfoIndication, 2, dsmccDIIBytes(1, 4066, dsmccDIIModuleBytes(1, 10, 2, 0))[:30])
+	offset = 0
+	d = parseDSMCCSection(b, &offset, len(b))
+	assert.Nil(t, d.DII)
+}
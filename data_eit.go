@@ -1,16 +1,44 @@
 package astits
 
-import "time"
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// EIT table IDs
+const (
+	eitTableIDPresentFollowingActual = 0x4e
+	eitTableIDPresentFollowingOther  = 0x4f
+	eitTableIDScheduleActual         = 0x50
+	eitTableIDScheduleOther          = 0x60
+)
+
+// EIT schedule layout
+// Page: 24 | Chapter: 4.1.4 | Link: https://www.etsi.org/deliver/etsi_tr/101200_101299/101211/01.11.01_60/tr_101211v011101p.pdf
+const (
+	eitScheduleSegmentDuration = 3 * time.Hour
+	eitScheduleSegmentSections = 8
+	eitScheduleTableSegments   = 32
+	eitScheduleTables          = 16
+)
 
 // EITData represents an EIT data
+// Its table ID, section number and version number are those of the section it was parsed from, which tells the
+// schedule segment it belongs to, and are ignored upon serialization
 // Page: 36 | Chapter: 5.2.4 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
 type EITData struct {
 	Events                   []*EITDataEvent
 	LastTableID              uint8
 	OriginalNetworkID        uint16
+	SectionNumber            uint8
 	SegmentLastSectionNumber uint8
 	ServiceID                uint16
+	TableID                  uint8
 	TransportStreamID        uint16
+	VersionNumber            uint8
 }
 
 // EITDataEvent represents an EIT data event
@@ -23,10 +51,91 @@ type EITDataEvent struct {
 	StartTime      time.Time
 }
 
+// EITDataExtendedEvent represents the extended event descriptors of an event in a language, reassembled in the order
+// of their descriptor number
+// Texts split across descriptors are concatenated, the character table selectors repeated at the beginning of the
+// following descriptors being dropped from raw texts, whereas items without description continue the previous item
+// Link: https://www.etsi.org/deliver/etsi_tr/101200_101299/101211/01.11.01_60/tr_101211v011101p.pdf
+type EITDataExtendedEvent struct {
+	Complete    bool   // Whether descriptors up to the last descriptor number have all been received
+	DecodedText string // Set when a text decoder is provided
+	Items       []*DescriptorExtendedEventItem
+	Language    string
+	Text        []byte
+}
+
+// ExtendedEvents returns the extended event descriptors of the event reassembled per language, in the order of the
+// first descriptor of each language
+func (e *EITDataEvent) ExtendedEvents() (xs []*EITDataExtendedEvent) {
+	// Group descriptors by language
+	var ds = make(map[string][]*DescriptorExtendedEvent)
+	var languages []string
+	for _, d := range e.Descriptors {
+		if d.ExtendedEvent == nil {
+			continue
+		}
+		var l = string(d.ExtendedEvent.ISO639LanguageCode)
+		if _, ok := ds[l]; !ok {
+			languages = append(languages, l)
+		}
+		ds[l] = append(ds[l], d.ExtendedEvent)
+	}
+
+	// Loop through languages
+	for _, l := range languages {
+		xs = append(xs, newEITDataExtendedEvent(l, ds[l]))
+	}
+	return
+}
+
+// newEITDataExtendedEvent reassembles the extended event descriptors of a language
+func newEITDataExtendedEvent(language string, ds []*DescriptorExtendedEvent) (x *EITDataExtendedEvent) {
+	// Init
+	x = &EITDataExtendedEvent{Language: language}
+	sort.SliceStable(ds, func(i, j int) bool { return ds[i].Number < ds[j].Number })
+
+	// Loop through descriptors
+	var numbers = make(map[uint8]bool)
+	for _, d := range ds {
+		// Text
+		numbers[d.Number] = true
+		x.DecodedText += d.DecodedText
+		x.Text = appendDVBText(x.Text, d.Text)
+
+		// Items
+		for _, itm := range d.Items {
+			if len(itm.Description) == 0 && len(x.Items) > 0 {
+				var p = x.Items[len(x.Items)-1]
+				p.Content = appendDVBText(p.Content, itm.Content)
+				p.DecodedContent += itm.DecodedContent
+				continue
+			}
+			var c = *itm
+			c.Content = append([]byte{}, itm.Content...)
+			x.Items = append(x.Items, &c)
+		}
+	}
+
+	// Check whether all descriptors have been received
+	x.Complete = true
+	for n := 0; n <= int(ds[len(ds)-1].LastDescriptorNumber); n++ {
+		if !numbers[uint8(n)] {
+			x.Complete = false
+			break
+		}
+	}
+	return
+}
+
 // parseEITSection parses an EIT section
-func parseEITSection(i []byte, offset *int, offsetSectionsEnd int, tableIDExtension uint16) (d *EITData) {
+func parseEITSection(i []byte, offset *int, offsetSectionsEnd int, tableID uint8, sh *PSISectionSyntaxHeader) (d *EITData) {
 	// Init
-	d = &EITData{ServiceID: tableIDExtension}
+	d = &EITData{
+		SectionNumber: sh.SectionNumber,
+		ServiceID:     sh.TableIDExtension,
+		TableID:       tableID,
+		VersionNumber: sh.VersionNumber,
+	}
 
 	// Transport stream ID
 	d.TransportStreamID = uint16(i[*offset])<<8 | uint16(i[*offset+1])
@@ -71,3 +180,153 @@ func parseEITSection(i []byte, offset *int, offsetSectionsEnd int, tableIDExtens
 	}
 	return
 }
+
+// SerializePresentFollowing serializes the EIT data into the 2 sections of a present/following table, CRC32 included
+// The first 2 events are the present and following events. When actual is false, the table describes another transport
+// stream than the actual one
+func (d *EITData) SerializePresentFollowing(versionNumber uint8, actual bool) (ss [][]byte, err error) {
+	// Get table ID
+	var tableID uint8 = eitTableIDPresentFollowingOther
+	if actual {
+		tableID = eitTableIDPresentFollowingActual
+	}
+
+	// Loop through sections
+	for idx := 0; idx < 2; idx++ {
+		// Event
+		var b []byte
+		if idx < len(d.Events) {
+			if b, err = writeEITDataEvent(d.Events[idx]); err != nil {
+				err = errors.Wrapf(err, "astits: writing event %d failed", d.Events[idx].EventID)
+				return
+			}
+		}
+
+		// Section
+		ss = append(ss, d.writeSection(tableID, versionNumber, uint8(idx), 1, 1, tableID, b))
+	}
+	return
+}
+
+// SerializeSchedule serializes the EIT data into the sections of as many schedule tables as needed, CRC32 included
+// Events are dispatched in 3 hours segments starting at midnight UTC of the day of now, segments with no event being
+// signaled by an empty section, and events outside the 64 days window return an error. When actual is false, tables
+// describe another transport stream than the actual one
+func (d *EITData) SerializeSchedule(versionNumber uint8, actual bool, now time.Time) (ss [][]byte, err error) {
+	// Get first table ID
+	var firstTableID uint8 = eitTableIDScheduleOther
+	if actual {
+		firstTableID = eitTableIDScheduleActual
+	}
+
+	// Sort events
+	var es = make([]*EITDataEvent, len(d.Events))
+	copy(es, d.Events)
+	sort.SliceStable(es, func(i, j int) bool { return es[i].StartTime.Before(es[j].StartTime) })
+
+	// Dispatch events in segments
+	now = now.UTC()
+	var start = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	var segments = make([][][]byte, 1)
+	for _, e := range es {
+		// Get segment
+		var idx = int(e.StartTime.Sub(start) / eitScheduleSegmentDuration)
+		if e.StartTime.Before(start) || idx >= eitScheduleTables*eitScheduleTableSegments {
+			err = fmt.Errorf("astits: event %d starts outside the schedule window", e.EventID)
+			return
+		}
+
+		// Write event
+		var b []byte
+		if b, err = writeEITDataEvent(e); err != nil {
+			err = errors.Wrapf(err, "astits: writing event %d failed", e.EventID)
+			return
+		}
+
+		// Add event to segment
+		for len(segments) <= idx {
+			segments = append(segments, nil)
+		}
+		segments[idx] = append(segments[idx], b)
+	}
+
+	// Split segments in sections
+	var groups = make([][][]byte, len(segments))
+	for idx, items := range segments {
+		if groups[idx], err = splitPSIItems(6, items); err != nil {
+			err = errors.Wrapf(err, "astits: splitting segment %d failed", idx)
+			return
+		} else if len(groups[idx]) > eitScheduleSegmentSections {
+			err = fmt.Errorf("astits: segment %d needs %d sections which is more than %d", idx, len(groups[idx]), eitScheduleSegmentSections)
+			return
+		}
+	}
+
+	// Loop through tables
+	var lastTableID = firstTableID + uint8((len(groups)-1)/eitScheduleTableSegments)
+	for tableID := firstTableID; tableID <= lastTableID; tableID++ {
+		// Get table segments
+		var offset = int(tableID-firstTableID) * eitScheduleTableSegments
+		var tgs = groups[offset:]
+		if len(tgs) > eitScheduleTableSegments {
+			tgs = tgs[:eitScheduleTableSegments]
+		}
+
+		// Write sections
+		var lastSectionNumber = uint8((len(tgs)-1)*eitScheduleSegmentSections + len(tgs[len(tgs)-1]) - 1)
+		for idx, gs := range tgs {
+			var segmentLastSectionNumber = uint8(idx*eitScheduleSegmentSections + len(gs) - 1)
+			for gidx, g := range gs {
+				ss = append(ss, d.writeSection(tableID, versionNumber, uint8(idx*eitScheduleSegmentSections+gidx), lastSectionNumber, segmentLastSectionNumber, lastTableID, g))
+			}
+		}
+	}
+	return
+}
+
+// writeSection serializes a complete EIT section
+func (d *EITData) writeSection(tableID, versionNumber, sectionNumber, lastSectionNumber, segmentLastSectionNumber, lastTableID uint8, events []byte) []byte {
+	return writePSISection(
+		&PSISectionHeader{PrivateBit: true, SectionSyntaxIndicator: true, TableID: int(tableID)},
+		&PSISectionSyntaxHeader{
+			CurrentNextIndicator: true,
+			LastSectionNumber:    lastSectionNumber,
+			SectionNumber:        sectionNumber,
+			TableIDExtension:     d.ServiceID,
+			VersionNumber:        versionNumber,
+		},
+		append([]byte{
+			uint8(d.TransportStreamID >> 8), uint8(d.TransportStreamID),
+			uint8(d.OriginalNetworkID >> 8), uint8(d.OriginalNetworkID),
+			segmentLastSectionNumber,
+			lastTableID,
+		}, events...),
+	)
+}
+
+// writeEITDataEvent serializes an EIT data event
+func writeEITDataEvent(e *EITDataEvent) (b []byte, err error) {
+	// Event ID
+	b = append(b, uint8(e.EventID>>8), uint8(e.EventID))
+
+	// Start time
+	b = append(b, writeDVBTime(e.StartTime)...)
+
+	// Duration
+	b = append(b, writeDVBDurationSeconds(e.Duration)...)
+
+	// Descriptors
+	var bd []byte
+	if bd, err = writeDescriptors(e.Descriptors); err != nil {
+		err = errors.Wrap(err, "astits: writing descriptors failed")
+		return
+	}
+
+	// Running status and free CA mode share their byte with the descriptors loop length
+	bd[0] = e.RunningStatus<<5 | bd[0]&0xf
+	if e.HasFreeCSAMode {
+		bd[0] |= 0x10
+	}
+	b = append(b, bd...)
+	return
+}
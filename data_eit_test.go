@@ -2,6 +2,7 @@ package astits
 
 import (
 	"testing"
+	"time"
 
 	"github.com/asticode/go-astitools/binary"
 	"github.com/stretchr/testify/assert"
@@ -18,9 +19,12 @@ var eit = &EITData{
 	}},
 	LastTableID:              5,
 	OriginalNetworkID:        3,
+	SectionNumber:            2,
 	SegmentLastSectionNumber: 4,
 	ServiceID:                1,
+	TableID:                  eitTableIDPresentFollowingActual,
 	TransportStreamID:        2,
+	VersionNumber:            21,
 }
 
 func eitBytes() []byte {
@@ -41,6 +45,137 @@ func eitBytes() []byte {
 func TestParseEITSection(t *testing.T) {
 	var offset int
 	var b = eitBytes()
-	d := parseEITSection(b, &offset, len(b), uint16(1))
+	d := parseEITSection(b, &offset, len(b), eitTableIDPresentFollowingActual, &PSISectionSyntaxHeader{SectionNumber: 2, TableIDExtension: 1, VersionNumber: 21})
 	assert.Equal(t, d, eit)
 }
+
+func TestEITDataSerializePresentFollowing(t *testing.T) {
+	// Init
+	var d = &EITData{
+		Events: []*EITDataEvent{{
+			Descriptors:   []*Descriptor{NewDescriptorShortEvent("eng", "present", "text")},
+			Duration:      dvbDurationSeconds,
+			EventID:       1,
+			RunningStatus: RunningStatusRunning,
+			StartTime:     dvbTime,
+		}},
+		OriginalNetworkID: 3,
+		ServiceID:         1,
+		TransportStreamID: 2,
+	}
+
+	// Only present
+	ss, err := d.SerializePresentFollowing(1, true)
+	assert.NoError(t, err)
+	assert.Len(t, ss, 2)
+	p, err := parsePSIData(writePSIPayload(ss), uint16(0x12), CRCModeError)
+	assert.NoError(t, err)
+	assert.Len(t, p.Sections, 2)
+	assert.Equal(t, eitTableIDPresentFollowingActual, p.Sections[0].Header.TableID)
+	assert.Equal(t, uint8(1), p.Sections[1].Syntax.Header.SectionNumber)
+	assert.Equal(t, uint8(1), p.Sections[1].Syntax.Header.LastSectionNumber)
+	assert.Equal(t, &EITData{
+		Events:                   d.Events,
+		LastTableID:              eitTableIDPresentFollowingActual,
+		OriginalNetworkID:        3,
+		SegmentLastSectionNumber: 1,
+		ServiceID:                1,
+		TableID:                  eitTableIDPresentFollowingActual,
+		TransportStreamID:        2,
+		VersionNumber:            1,
+	}, p.Sections[0].Syntax.Data.EIT)
+	assert.Empty(t, p.Sections[1].Syntax.Data.EIT.Events)
+
+	// Other transport stream
+	ss, err = d.SerializePresentFollowing(1, false)
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(eitTableIDPresentFollowingOther), ss[0][0])
+}
+
+func TestEITDataSerializeSchedule(t *testing.T) {
+	// Init
+	now := time.Date(2018, 1, 1, 10, 0, 0, 0, time.UTC)
+	var d = &EITData{OriginalNetworkID: 3, ServiceID: 1, TransportStreamID: 2}
+	for idx, s := range []time.Time{
+		now.Add(5 * 24 * time.Hour),
+		now.Add(-9 * time.Hour),
+		now.Add(-8 * time.Hour),
+	} {
+		d.Events = append(d.Events, &EITDataEvent{
+			Descriptors:   []*Descriptor{NewDescriptorShortEvent("eng", "name", "text")},
+			Duration:      time.Hour,
+			EventID:       uint16(idx),
+			RunningStatus: RunningStatusNotRunning,
+			StartTime:     s,
+		})
+	}
+
+	// Serialize
+	ss, err := d.SerializeSchedule(2, true, now)
+	assert.NoError(t, err)
+	p, err := parsePSIData(writePSIPayload(ss), uint16(0x12), CRCModeError)
+	assert.NoError(t, err)
+	var tableIDs []int
+	var sectionNumbers []uint8
+	for _, s := range p.Sections {
+		tableIDs = append(tableIDs, s.Header.TableID)
+		sectionNumbers = append(sectionNumbers, s.Syntax.Header.SectionNumber)
+		assert.Equal(t, uint8(0x51), s.Syntax.Data.EIT.LastTableID)
+	}
+	assert.Equal(t, append(repeatInts(0x50, 32), repeatInts(0x51, 12)...), tableIDs)
+	assert.Equal(t, uint8(0), sectionNumbers[0])
+	assert.Equal(t, uint8(8), sectionNumbers[1])
+	assert.Equal(t, uint8(88), sectionNumbers[43])
+	assert.Len(t, p.Sections[0].Syntax.Data.EIT.Events, 2)
+	assert.Equal(t, uint16(1), p.Sections[0].Syntax.Data.EIT.Events[0].EventID)
+	assert.Equal(t, uint8(248), p.Sections[0].Syntax.Header.LastSectionNumber)
+	assert.Equal(t, uint8(88), p.Sections[43].Syntax.Header.LastSectionNumber)
+	assert.Equal(t, uint16(0), p.Sections[43].Syntax.Data.EIT.Events[0].EventID)
+
+	// Event out of the schedule window
+	d.Events[0].StartTime = now.Add(-24 * time.Hour)
+	_, err = d.SerializeSchedule(2, true, now)
+	assert.Error(t, err)
+}
+
+func repeatInts(v, n int) (o []int) {
+	for idx := 0; idx < n; idx++ {
+		o = append(o, v)
+	}
+	return
+}
+
+func TestEITDataEventExtendedEvents(t *testing.T) {
+	e := &EITDataEvent{Descriptors: []*Descriptor{
+		{ExtendedEvent: &DescriptorExtendedEvent{
+			DecodedText:          " world",
+			ISO639LanguageCode:   []byte("eng"),
+			Items:                []*DescriptorExtendedEventItem{{Content: []byte{0x15, 'b'}, DecodedContent: "b", Description: []byte("cast")}, {Content: []byte{0x15, 'c'}, DecodedContent: "c"}},
+			LastDescriptorNumber: 1,
+			Number:               1,
+			Text:                 []byte{0x15, ' ', 'w', 'o', 'r', 'l', 'd'},
+		}},
+		{ExtendedEvent: &DescriptorExtendedEvent{ISO639LanguageCode: []byte("fre"), LastDescriptorNumber: 1, Number: 1, Text: []byte("monde")}},
+		{ExtendedEvent: &DescriptorExtendedEvent{
+			DecodedText:          "hello",
+			ISO639LanguageCode:   []byte("eng"),
+			Items:                []*DescriptorExtendedEventItem{{Content: []byte{0x15, 'a'}, DecodedContent: "a", Description: []byte("director")}},
+			LastDescriptorNumber: 1,
+			Text:                 []byte{0x15, 'h', 'e', 'l', 'l', 'o'},
+		}},
+	}}
+	assert.Equal(t, []*EITDataExtendedEvent{
+		{
+			Complete:    true,
+			DecodedText: "hello world",
+			Items: []*DescriptorExtendedEventItem{
+				{Content: []byte{0x15, 'a'}, DecodedContent: "a", Description: []byte("director")},
+				{Content: []byte{0x15, 'b', 'c'}, DecodedContent: "bc", Description: []byte("cast")},
+			},
+			Language: "eng",
+			Text:     []byte{0x15, 'h', 'e', 'l', 'l', 'o', ' ', 'w', 'o', 'r', 'l', 'd'},
+		},
+		{Language: "fre", Text: []byte("monde")},
+	}, e.ExtendedEvents())
+	assert.Equal(t, []byte{0x15, 'b'}, e.Descriptors[0].ExtendedEvent.Items[0].Content)
+}
@@ -0,0 +1,43 @@
+package astits
+
+// ETM types
+const (
+	ETMTypeChannel = 0x0
+	ETMTypeEvent   = 0x2
+)
+
+// ETTData represents an ETT data
+// Page: 49 | Chapter: 6.6 | Link: https://www.atsc.org/wp-content/uploads/2015/03/Program-System-Information-Protocol-for-Terrestrial-Broadcast-and-Cable.pdf
+type ETTData struct {
+	EventID          uint16 // Only relevant when the ETM type is ETMTypeEvent
+	ExtendedText     *ATSCMultipleString
+	ETMType          uint8
+	ProtocolVersion  uint8
+	SourceID         uint16
+	TableIDExtension uint16
+}
+
+// parseETTSection parses an ETT section
+func parseETTSection(i []byte, offset *int, offsetSectionsEnd int, tableIDExtension uint16) (d *ETTData) {
+	// Init
+	d = &ETTData{TableIDExtension: tableIDExtension}
+
+	// Protocol version
+	d.ProtocolVersion = uint8(i[*offset])
+	*offset += 1
+
+	// Source ID
+	d.SourceID = uint16(i[*offset])<<8 | uint16(i[*offset+1])
+	*offset += 2
+
+	// Event ID
+	d.EventID = uint16(i[*offset])<<6 | uint16(i[*offset+1]>>2)
+
+	// ETM type
+	d.ETMType = uint8(i[*offset+1] & 0x3)
+	*offset += 2
+
+	// Extended text
+	d.ExtendedText = parseATSCMultipleString(i[:offsetSectionsEnd], offset)
+	return
+}
@@ -0,0 +1,34 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/asticode/go-astitools/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+var ett = &ETTData{
+	EventID:          2,
+	ExtendedText:     atscMultipleString,
+	ETMType:          ETMTypeEvent,
+	SourceID:         1,
+	TableIDExtension: 3,
+}
+
+func ettBytes() []byte {
+	w := astibinary.New()
+	w.Write(uint8(0))          // Protocol version
+	w.Write(uint16(1))         // Source ID
+	w.Write("00000000000010")  // Event ID
+	w.Write("10")              // ETM type
+	atscMultipleStringBytes(w) // Extended text
+	return w.Bytes()
+}
+
+func TestParseETTSection(t *testing.T) {
+	var offset int
+	var b = ettBytes()
+	d := parseETTSection(b, &offset, len(b), uint16(3))
+	assert.Equal(t, ett, d)
+	assert.Equal(t, len(b), offset)
+}
@@ -0,0 +1,48 @@
+package astits
+
+// LDTData represents an ISDB LDT data
+// Chapter: 5.2.15 | ARIB STD-B10
+type LDTData struct {
+	Descriptions      []*LDTDataDescription
+	OriginalNetworkID uint16
+	OriginalServiceID uint16
+	TransportStreamID uint16
+}
+
+// LDTDataDescription represents a LDT data description
+type LDTDataDescription struct {
+	DescriptionID uint16
+	Descriptors   []*Descriptor
+}
+
+// parseLDTSection parses a LDT section
+func parseLDTSection(i []byte, offset *int, offsetSectionsEnd int, tableIDExtension uint16) (d *LDTData) {
+	// Init
+	d = &LDTData{OriginalServiceID: tableIDExtension}
+
+	// Transport stream ID
+	d.TransportStreamID = uint16(i[*offset])<<8 | uint16(i[*offset+1])
+	*offset += 2
+
+	// Original network ID
+	d.OriginalNetworkID = uint16(i[*offset])<<8 | uint16(i[*offset+1])
+	*offset += 2
+
+	// Loop until end of section data is reached
+	for *offset < offsetSectionsEnd {
+		// Description ID
+		var ds = &LDTDataDescription{}
+		ds.DescriptionID = uint16(i[*offset])<<8 | uint16(i[*offset+1])
+		*offset += 2
+
+		// Reserved for future use
+		*offset += 1
+
+		// Descriptors
+		ds.Descriptors = parseDescriptors(i, offset)
+
+		// Append description
+		d.Descriptions = append(d.Descriptions, ds)
+	}
+	return
+}
@@ -0,0 +1,36 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/asticode/go-astitools/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+var ldt = &LDTData{
+	Descriptions: []*LDTDataDescription{{
+		DescriptionID: 4,
+		Descriptors:   descriptors,
+	}},
+	OriginalNetworkID: 3,
+	OriginalServiceID: 1,
+	TransportStreamID: 2,
+}
+
+func ldtBytes() []byte {
+	w := astibinary.New()
+	w.Write(uint16(2))      // Transport stream ID
+	w.Write(uint16(3))      // Original network ID
+	w.Write(uint16(4))      // Description #1 ID
+	w.Write("111111111111") // Description #1 reserved for future use
+	descriptorsBytes(w)     // Description #1 descriptors
+	return w.Bytes()
+}
+
+func TestParseLDTSection(t *testing.T) {
+	var offset int
+	var b = ldtBytes()
+	d := parseLDTSection(b, &offset, len(b), uint16(1))
+	assert.Equal(t, ldt, d)
+	assert.Equal(t, len(b), offset)
+}
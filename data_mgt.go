@@ -0,0 +1,89 @@
+package astits
+
+// MGT table types
+// Page: 27 | Chapter: 6.2 | Link: https://www.atsc.org/wp-content/uploads/2015/03/Program-System-Information-Protocol-for-Terrestrial-Broadcast-and-Cable.pdf
+const (
+	MGTTableTypeTVCTCurrent   = 0x0
+	MGTTableTypeTVCTNext      = 0x1
+	MGTTableTypeCVCTCurrent   = 0x2
+	MGTTableTypeCVCTNext      = 0x3
+	MGTTableTypeChannelETT    = 0x4
+	MGTTableTypeDCCSCT        = 0x5
+	MGTTableTypeEITFirst      = 0x100 // EIT-0
+	MGTTableTypeEITLast       = 0x17f // EIT-127
+	MGTTableTypeEventETTFirst = 0x200 // Event ETT-0
+	MGTTableTypeEventETTLast  = 0x27f // Event ETT-127
+	MGTTableTypeRRTFirst      = 0x301 // RRT with rating region 1
+	MGTTableTypeRRTLast       = 0x3ff // RRT with rating region 255
+	MGTTableTypeDCCTFirst     = 0x1400
+	MGTTableTypeDCCTLast      = 0x14ff
+)
+
+// MGTData represents a MGT data
+// Page: 26 | Chapter: 6.2 | Link: https://www.atsc.org/wp-content/uploads/2015/03/Program-System-Information-Protocol-for-Terrestrial-Broadcast-and-Cable.pdf
+type MGTData struct {
+	Descriptors     []*Descriptor
+	ProtocolVersion uint8
+	Tables          []*MGTDataTable
+}
+
+// MGTDataTable represents a MGT data table
+type MGTDataTable struct {
+	Descriptors   []*Descriptor
+	NumberBytes   uint32
+	PID           uint16
+	Type          uint16
+	VersionNumber uint8
+}
+
+// parseMGTSection parses a MGT section
+func parseMGTSection(i []byte, offset *int) (d *MGTData) {
+	// Init
+	d = &MGTData{}
+
+	// Protocol version
+	d.ProtocolVersion = uint8(i[*offset])
+	*offset += 1
+
+	// Tables defined
+	var tablesDefined = int(uint16(i[*offset])<<8 | uint16(i[*offset+1]))
+	*offset += 2
+
+	// Loop through tables
+	for idx := 0; idx < tablesDefined; idx++ {
+		// Table type
+		var t = &MGTDataTable{}
+		t.Type = uint16(i[*offset])<<8 | uint16(i[*offset+1])
+		*offset += 2
+
+		// PID
+		t.PID = uint16(i[*offset]&0x1f)<<8 | uint16(i[*offset+1])
+		*offset += 2
+
+		// Version number
+		t.VersionNumber = uint8(i[*offset] & 0x1f)
+		*offset += 1
+
+		// Number bytes
+		t.NumberBytes = uint32(i[*offset])<<24 | uint32(i[*offset+1])<<16 | uint32(i[*offset+2])<<8 | uint32(i[*offset+3])
+		*offset += 4
+
+		// Descriptors
+		t.Descriptors = parseDescriptors(i, offset)
+
+		// Append table
+		d.Tables = append(d.Tables, t)
+	}
+
+	// Descriptors
+	d.Descriptors = parseDescriptors(i, offset)
+	return
+}
+
+// isMGTTableTypeOnOwnPID checks whether tables of this type are carried on the PID announced in the MGT rather than
+// on the base PID
+func isMGTTableTypeOnOwnPID(t uint16) bool {
+	return t == MGTTableTypeChannelETT ||
+		(t >= MGTTableTypeEITFirst && t <= MGTTableTypeEITLast) ||
+		(t >= MGTTableTypeEventETTFirst && t <= MGTTableTypeEventETTLast)
+}
@@ -0,0 +1,53 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/asticode/go-astitools/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+var mgt = &MGTData{
+	Descriptors:     descriptors,
+	ProtocolVersion: 0,
+	Tables: []*MGTDataTable{{
+		Descriptors:   descriptors,
+		NumberBytes:   1234,
+		PID:           0x1ffb,
+		Type:          MGTTableTypeTVCTCurrent,
+		VersionNumber: 3,
+	}},
+}
+
+func mgtBytes() []byte {
+	w := astibinary.New()
+	w.Write(uint8(0))        // Protocol version
+	w.Write(uint16(1))       // Tables defined
+	w.Write(uint16(0))       // Table #1 type
+	w.Write("111")           // Table #1 reserved
+	w.Write("1111111111011") // Table #1 PID
+	w.Write("111")           // Table #1 reserved
+	w.Write("00011")         // Table #1 version number
+	w.Write(uint32(1234))    // Table #1 number bytes
+	w.Write("1111")          // Table #1 reserved
+	descriptorsBytes(w)      // Table #1 descriptors
+	w.Write("1111")          // Reserved
+	descriptorsBytes(w)      // Descriptors
+	return w.Bytes()
+}
+
+func TestParseMGTSection(t *testing.T) {
+	var offset int
+	var b = mgtBytes()
+	d := parseMGTSection(b, &offset)
+	assert.Equal(t, mgt, d)
+	assert.Equal(t, len(b), offset)
+}
+
+func TestIsMGTTableTypeOnOwnPID(t *testing.T) {
+	assert.False(t, isMGTTableTypeOnOwnPID(MGTTableTypeTVCTCurrent))
+	assert.True(t, isMGTTableTypeOnOwnPID(MGTTableTypeChannelETT))
+	assert.True(t, isMGTTableTypeOnOwnPID(0x103))
+	assert.True(t, isMGTTableTypeOnOwnPID(0x203))
+	assert.False(t, isMGTTableTypeOnOwnPID(0x301))
+}
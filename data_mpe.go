@@ -0,0 +1,191 @@
+package astits
+
+// MPE constants
+const (
+	mpeLLCSNAPLength            = 8
+	mpeRealTimeParametersLength = 4
+)
+
+// MPEData represents a datagram section, which carries an IP datagram or a fragment of it using the DVB
+// multi-protocol encapsulation
+// Page: 17 | Chapter: 7.1 | Link: https://www.etsi.org/deliver/etsi_en/301100_301199/301192/01.06.01_60/en_301192v010601p.pdf
+type MPEData struct {
+	AddressScramblingControl uint8
+	Datagram                 []byte // Complete IP datagram, stuffing bytes excluded. Set when the section carries the last fragment of the datagram and its previous fragments have been received
+	EtherType                uint16 // Only set when the payload is preceded by a LLC/SNAP header
+	HasLLCSNAP               bool
+	LastSectionNumber        uint8
+	MACAddress               []byte // Most significant byte first. When time slicing or MPE-FEC is used, its 4 most significant bytes carry the real time parameters
+	Payload                  []byte // Fragment of the datagram carried by the section, LLC/SNAP header excluded
+	PayloadScramblingControl uint8
+	SectionNumber            uint8
+}
+
+// MPEFECData represents a MPE-FEC section, which carries a column of the RS data table protecting the datagrams of a
+// time slicing burst
+// Page: 21 | Chapter: 9.9 | Link: https://www.etsi.org/deliver/etsi_en/301100_301199/301192/01.06.01_60/en_301192v010601p.pdf
+type MPEFECData struct {
+	LastSectionNumber  uint8
+	PaddingColumns     uint8 // Number of columns of the application data table that only contain padding bytes
+	RealTimeParameters *MPERealTimeParameters
+	RSData             []byte
+	SectionNumber      uint8 // Index of the column of the RS data table
+}
+
+// MPERealTimeParameters represents the real time parameters of a time slicing burst or of an MPE-FEC frame
+// Page: 22 | Chapter: 9.10 | Link: https://www.etsi.org/deliver/etsi_en/301100_301199/301192/01.06.01_60/en_301192v010601p.pdf
+type MPERealTimeParameters struct {
+	Address       uint32 // Position of the section payload in the application data table or in the RS data table
+	DeltaT        uint16 // Time to the next burst of the elementary stream, in units of 10ms
+	FrameBoundary bool   // Set when the section is the last one of the MPE-FEC frame
+	TableBoundary bool   // Set when the section is the last one of the application data table or of the RS data table
+}
+
+// parseMPESection parses a datagram section
+// Datagrams split over several sections are only available once the demuxer has received all their fragments
+func parseMPESection(i []byte, offset *int, offsetSectionsEnd int, sh *PSISectionSyntaxHeader) (d *MPEData) {
+	// Init
+	d = &MPEData{
+		AddressScramblingControl: sh.VersionNumber >> 1 & 0x3,
+		HasLLCSNAP:               sh.VersionNumber&0x1 > 0,
+		LastSectionNumber:        sh.LastSectionNumber,
+		PayloadScramblingControl: sh.VersionNumber >> 3 & 0x3,
+		SectionNumber:            sh.SectionNumber,
+	}
+
+	// Check length
+	if *offset+4 > offsetSectionsEnd {
+		*offset = offsetSectionsEnd
+		return
+	}
+
+	// MAC address, whose 2 least significant bytes are carried by the table ID extension and whose 4 other bytes
+	// follow the syntax header in reverse order
+	d.MACAddress = []byte{i[*offset+3], i[*offset+2], i[*offset+1], i[*offset], uint8(sh.TableIDExtension), uint8(sh.TableIDExtension >> 8)}
+	*offset += 4
+
+	// LLC/SNAP header
+	var b = i[*offset:offsetSectionsEnd]
+	*offset = offsetSectionsEnd
+	if d.HasLLCSNAP && len(b) >= mpeLLCSNAPLength {
+		d.EtherType = uint16(b[6])<<8 | uint16(b[7])
+		b = b[mpeLLCSNAPLength:]
+	}
+	d.Payload = b
+
+	// Datagrams carried by a single section are complete
+	if d.SectionNumber == 0 && d.LastSectionNumber == 0 {
+		d.Datagram = trimIPDatagram(d.Payload)
+	}
+	return
+}
+
+// RealTimeParameters parses the real time parameters carried by the MAC address of a datagram section when time
+// slicing or MPE-FEC is used, as signalled by a time slice and FEC identifier descriptor
+func (d *MPEData) RealTimeParameters() *MPERealTimeParameters {
+	if len(d.MACAddress) < 6 {
+		return nil
+	}
+	return newMPERealTimeParameters([]byte{d.MACAddress[3], d.MACAddress[2], d.MACAddress[1], d.MACAddress[0]})
+}
+
+// newMPERealTimeParameters parses real time parameters
+func newMPERealTimeParameters(i []byte) *MPERealTimeParameters {
+	return &MPERealTimeParameters{
+		Address:       uint32(i[1]&0x3)<<16 | uint32(i[2])<<8 | uint32(i[3]),
+		DeltaT:        uint16(i[0])<<4 | uint16(i[1]>>4),
+		FrameBoundary: i[1]&0x4 > 0,
+		TableBoundary: i[1]&0x8 > 0,
+	}
+}
+
+// parseMPEFECSection parses a MPE-FEC section
+func parseMPEFECSection(i []byte, offset *int, offsetSectionsEnd int, sh *PSISectionSyntaxHeader) (d *MPEFECData) {
+	// Init
+	d = &MPEFECData{
+		LastSectionNumber: sh.LastSectionNumber,
+		PaddingColumns:    uint8(sh.TableIDExtension >> 8),
+		SectionNumber:     sh.SectionNumber,
+	}
+
+	// Real time parameters
+	if *offset+mpeRealTimeParametersLength <= offsetSectionsEnd {
+		d.RealTimeParameters = newMPERealTimeParameters(i[*offset : *offset+mpeRealTimeParametersLength])
+		*offset += mpeRealTimeParametersLength
+	}
+
+	// RS data
+	d.RSData = i[*offset:offsetSectionsEnd]
+	*offset = offsetSectionsEnd
+	return
+}
+
+// trimIPDatagram removes the stuffing bytes following an IPv4 or an IPv6 datagram, based on the length found in its
+// header. Datagrams of other protocols are left untouched
+func trimIPDatagram(i []byte) []byte {
+	if len(i) == 0 {
+		return i
+	}
+	var l int
+	switch i[0] >> 4 {
+	case 4:
+		if len(i) >= 4 {
+			l = int(uint16(i[2])<<8 | uint16(i[3]))
+		}
+	case 6:
+		if len(i) >= 6 {
+			l = 40 + int(uint16(i[4])<<8|uint16(i[5]))
+		}
+	}
+	if l > 0 && l <= len(i) {
+		return i[:l]
+	}
+	return i
+}
+
+// mpeKey represents the key of a datagram being reassembled
+// Since the 4 most significant bytes of the MAC address may carry real time parameters that change with every section,
+// only its 2 least significant bytes are used
+type mpeKey struct {
+	macAddress uint16
+	pid        uint16
+}
+
+// mpeDatagram represents a datagram being reassembled
+type mpeDatagram struct {
+	buf               []byte
+	nextSectionNumber uint8
+}
+
+// updateMPEData reassembles the datagrams split over several datagram sections
+// Fragments are expected in order, therefore a missing fragment drops the whole datagram
+func (dmx *Demuxer) updateMPEData(ds []*Data) {
+	for _, d := range ds {
+		// Only fragmented datagrams need to be reassembled
+		if d.MPE == nil || d.MPE.Datagram != nil || len(d.MPE.MACAddress) < 6 {
+			continue
+		}
+
+		// First fragment
+		var k = mpeKey{macAddress: uint16(d.MPE.MACAddress[4])<<8 | uint16(d.MPE.MACAddress[5]), pid: d.PID}
+		if d.MPE.SectionNumber == 0 {
+			dmx.mpeDatagrams[k] = &mpeDatagram{buf: append([]byte{}, d.MPE.Payload...), nextSectionNumber: 1}
+			continue
+		}
+
+		// Next fragment
+		v, ok := dmx.mpeDatagrams[k]
+		if !ok || v.nextSectionNumber != d.MPE.SectionNumber {
+			delete(dmx.mpeDatagrams, k)
+			continue
+		}
+		v.buf = append(v.buf, d.MPE.Payload...)
+		v.nextSectionNumber++
+
+		// Last fragment
+		if d.MPE.SectionNumber == d.MPE.LastSectionNumber {
+			d.MPE.Datagram = trimIPDatagram(v.buf)
+			delete(dmx.mpeDatagrams, k)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/asticode/go-astitools/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+// ipDatagram is an IPv4 datagram whose total length is 24 bytes
+var ipDatagram = append([]byte{0x45, 0x0, 0x0, 0x18}, append(make([]byte, 16), []byte("data")...)...)
+
+func mpeSectionBytes(sectionNumber, lastSectionNumber uint8, llcSNAP bool, payload []byte) []byte {
+	w := astibinary.New()
+	w.Write([]byte{0x4, 0x3, 0x2, 0x1}) // MAC addresses 4 to 1
+	if llcSNAP {
+		w.Write([]byte{0xaa, 0xaa, 0x3, 0x0, 0x0, 0x0, 0x8, 0x0}) // LLC/SNAP
+	}
+	w.Write(payload) // Datagram
+	var sh = &PSISectionSyntaxHeader{
+		CurrentNextIndicator: true,
+		LastSectionNumber:    lastSectionNumber,
+		SectionNumber:        sectionNumber,
+		TableIDExtension:     0x0605, // MAC addresses 6 and 5
+		VersionNumber:        0x10,   // Payload scrambling control
+	}
+	if llcSNAP {
+		sh.VersionNumber |= 0x1
+	}
+	return writePSISection(&PSISectionHeader{SectionSyntaxIndicator: true, TableID: 0x3e}, sh, w.Bytes())
+}
+
+func TestParseMPESection(t *testing.T) {
+	// Datagram followed by stuffing bytes
+	d, err := parsePSIData(append([]byte{0x0}, mpeSectionBytes(0, 0, true, append(ipDatagram, 0xff, 0xff))...), 0x100, CRCModeError)
+	assert.NoError(t, err)
+	assert.Equal(t, &MPEData{
+		Datagram:                 ipDatagram,
+		EtherType:                0x800,
+		HasLLCSNAP:               true,
+		MACAddress:               []byte{0x1, 0x2, 0x3, 0x4, 0x5, 0x6},
+		Payload:                  append(ipDatagram, 0xff, 0xff),
+		PayloadScramblingControl: 2,
+	}, d.Sections[0].Syntax.Data.MPE)
+
+	// Fragment
+	d, err = parsePSIData(append([]byte{0x0}, mpeSectionBytes(0, 1, false, ipDatagram[:10])...), 0x100, CRCModeError)
+	assert.NoError(t, err)
+	assert.Nil(t, d.Sections[0].Syntax.Data.MPE.Datagram)
+	assert.Equal(t, ipDatagram[:10], d.Sections[0].Syntax.Data.MPE.Payload)
+}
+
+func TestMPEDataRealTimeParameters(t *testing.T) {
+	d := &MPEData{MACAddress: []byte{0xcd, 0xab, 0x3a, 0x12, 0x5, 0x6}}
+	assert.Equal(t, &MPERealTimeParameters{Address: 0x2abcd, DeltaT: 0x123, TableBoundary: true}, d.RealTimeParameters())
+}
+
+func TestParseMPEFECSection(t *testing.T) {
+	s := writePSISection(&PSISectionHeader{SectionSyntaxIndicator: true, TableID: 0x78}, &PSISectionSyntaxHeader{
+		CurrentNextIndicator: true,
+		LastSectionNumber:    190,
+		SectionNumber:        3,
+		TableIDExtension:     0x0200,
+	}, []byte{0x12, 0x3e, 0xab, 0xcd, 0x1, 0x2})
+	d, err := parsePSIData(append([]byte{0x0}, s...), 0x100, CRCModeError)
+	assert.NoError(t, err)
+	assert.Equal(t, &MPEFECData{
+		LastSectionNumber:  190,
+		PaddingColumns:     2,
+		RealTimeParameters: &MPERealTimeParameters{Address: 0x2abcd, DeltaT: 0x123, FrameBoundary: true, TableBoundary: true},
+		RSData:             []byte{0x1, 0x2},
+		SectionNumber:      3,
+	}, d.Sections[0].Syntax.Data.MPEFEC)
+}
+
+func TestDemuxerMPE(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	pm, _ := (&PMTData{ElementaryStreams: []*PMTElementaryStream{{ElementaryPID: 0x101, StreamType: StreamTypeDSMCCSections}}, PCRPID: 0x1fff, ProgramNumber: 1}).Serialize(0)
+	for _, v := range []struct {
+		b   []byte
+		pid uint16
+	}{
+		{b: pm, pid: 0x100},
+		{b: append(mpeSectionBytes(0, 1, false, ipDatagram[:10]), mpeSectionBytes(1, 1, false, append(ipDatagram[10:], 0xff))...), pid: 0x101},
+	} {
+		var p = append([]byte{0x0}, v.b...)
+		p = append(p, bytes.Repeat([]byte{0xff}, 147-len(p))...)
+		b, _ := packet(PacketHeader{PayloadUnitStartIndicator: true, PID: v.pid}, PacketAdaptationField{}, p)
+		w.Write(b)
+		b, _ = packet(PacketHeader{ContinuityCounter: uint8(1), PayloadUnitStartIndicator: true, PID: v.pid}, PacketAdaptationField{}, []byte{})
+		w.Write(b)
+	}
+	dmx := New(context.Background(), bytes.NewReader(w.Bytes()))
+	dmx.programMap.set(0x100, 1)
+
+	// PMT
+	d, err := dmx.NextData()
+	assert.NoError(t, err)
+	assert.NotNil(t, d.PMT)
+
+	// First fragment
+	d, err = dmx.NextData()
+	assert.NoError(t, err)
+	assert.Nil(t, d.MPE.Datagram)
+
+	// Last fragment
+	d, err = dmx.NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, ipDatagram, d.MPE.Datagram)
+	assert.Len(t, dmx.mpeDatagrams, 0)
+}
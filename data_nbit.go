@@ -0,0 +1,61 @@
+package astits
+
+// NBITData represents an ISDB NBIT data
+// The same structure is used by the NBIT carrying the information body and the NBIT carrying reference information
+// Chapter: 5.2.14 | ARIB STD-B10
+type NBITData struct {
+	Informations      []*NBITDataInformation
+	OriginalNetworkID uint16
+}
+
+// NBITDataInformation represents a NBIT data information
+type NBITDataInformation struct {
+	DescriptionBodyLocation uint8
+	Descriptors             []*Descriptor
+	InformationID           uint16
+	InformationType         uint8
+	KeyIDs                  []uint16
+	UserDefined             uint8
+}
+
+// parseNBITSection parses a NBIT section
+func parseNBITSection(i []byte, offset *int, offsetSectionsEnd int, tableIDExtension uint16) (d *NBITData) {
+	// Init
+	d = &NBITData{OriginalNetworkID: tableIDExtension}
+
+	// Loop until end of section data is reached
+	for *offset < offsetSectionsEnd {
+		// Information ID
+		var n = &NBITDataInformation{}
+		n.InformationID = uint16(i[*offset])<<8 | uint16(i[*offset+1])
+		*offset += 2
+
+		// Information type
+		n.InformationType = uint8(i[*offset] >> 4)
+
+		// Description body location
+		n.DescriptionBodyLocation = uint8(i[*offset]>>2) & 0x3
+		*offset += 1
+
+		// User defined
+		n.UserDefined = uint8(i[*offset])
+		*offset += 1
+
+		// Number of keys
+		var numberOfKeys = int(i[*offset])
+		*offset += 1
+
+		// Key IDs
+		for idx := 0; idx < numberOfKeys; idx++ {
+			n.KeyIDs = append(n.KeyIDs, uint16(i[*offset])<<8|uint16(i[*offset+1]))
+			*offset += 2
+		}
+
+		// Descriptors
+		n.Descriptors = parseDescriptors(i, offset)
+
+		// Append information
+		d.Informations = append(d.Informations, n)
+	}
+	return
+}
@@ -0,0 +1,43 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/asticode/go-astitools/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+var nbit = &NBITData{
+	Informations: []*NBITDataInformation{{
+		DescriptionBodyLocation: 2,
+		Descriptors:             descriptors,
+		InformationID:           2,
+		InformationType:         3,
+		KeyIDs:                  []uint16{4, 5},
+		UserDefined:             6,
+	}},
+	OriginalNetworkID: 1,
+}
+
+func nbitBytes() []byte {
+	w := astibinary.New()
+	w.Write(uint16(2))  // Information #1 ID
+	w.Write("0011")     // Information #1 type
+	w.Write("10")       // Information #1 description body location
+	w.Write("11")       // Information #1 reserved for future use
+	w.Write(uint8(6))   // Information #1 user defined
+	w.Write(uint8(2))   // Information #1 number of keys
+	w.Write(uint16(4))  // Information #1 key #1 ID
+	w.Write(uint16(5))  // Information #1 key #2 ID
+	w.Write("1111")     // Information #1 reserved for future use
+	descriptorsBytes(w) // Information #1 descriptors
+	return w.Bytes()
+}
+
+func TestParseNBITSection(t *testing.T) {
+	var offset int
+	var b = nbitBytes()
+	d := parseNBITSection(b, &offset, len(b), uint16(1))
+	assert.Equal(t, nbit, d)
+	assert.Equal(t, len(b), offset)
+}
@@ -1,5 +1,11 @@
 package astits
 
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
 // NITData represents a NIT data
 // Page: 29 | Chapter: 5.2.1 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
 type NITData struct {
@@ -47,3 +53,74 @@ func parseNITSection(i []byte, offset *int, tableIDExtension uint16) (d *NITData
 	}
 	return
 }
+
+// NewNITData creates a new NIT data describing a network with a network name descriptor
+func NewNITData(networkID uint16, name string) *NITData {
+	return &NITData{
+		NetworkDescriptors: []*Descriptor{NewDescriptorNetworkName(name)},
+		NetworkID:          networkID,
+	}
+}
+
+// Serialize serializes the NIT data of the actual network into as many sections as needed, CRC32 included
+func (d *NITData) Serialize(versionNumber uint8) (ss [][]byte, err error) {
+	// Network descriptors
+	var nd []byte
+	if nd, err = writeDescriptors(d.NetworkDescriptors); err != nil {
+		err = errors.Wrap(err, "astits: writing network descriptors failed")
+		return
+	}
+
+	// Transport streams
+	var items [][]byte
+	for _, ts := range d.TransportStreams {
+		var b []byte
+		if b, err = writeNITDataTransportStream(ts); err != nil {
+			err = errors.Wrapf(err, "astits: writing transport stream %d failed", ts.TransportStreamID)
+			return
+		}
+		items = append(items, b)
+	}
+
+	// Split transport streams in sections
+	var gs [][]byte
+	if gs, err = splitPSIItems(len(nd)+2, items); err != nil {
+		err = errors.Wrap(err, "astits: splitting transport streams failed")
+		return
+	} else if len(gs) > psiSectionMaximumNumber {
+		err = fmt.Errorf("astits: %d sections are needed which is more than %d", len(gs), psiSectionMaximumNumber)
+		return
+	}
+
+	// Write sections
+	for idx, g := range gs {
+		var b = append(append([]byte{}, nd...), 0xf0|uint8(len(g)>>8)&0xf, uint8(len(g)))
+		ss = append(ss, writePSISection(
+			&PSISectionHeader{PrivateBit: true, SectionSyntaxIndicator: true, TableID: 0x40},
+			&PSISectionSyntaxHeader{
+				CurrentNextIndicator: true,
+				LastSectionNumber:    uint8(len(gs) - 1),
+				SectionNumber:        uint8(idx),
+				TableIDExtension:     d.NetworkID,
+				VersionNumber:        versionNumber,
+			},
+			append(b, g...),
+		))
+	}
+	return
+}
+
+// writeNITDataTransportStream serializes a NIT data transport stream
+func writeNITDataTransportStream(ts *NITDataTransportStream) (b []byte, err error) {
+	// Transport stream ID and original network ID
+	b = append(b, uint8(ts.TransportStreamID>>8), uint8(ts.TransportStreamID), uint8(ts.OriginalNetworkID>>8), uint8(ts.OriginalNetworkID))
+
+	// Descriptors
+	var bd []byte
+	if bd, err = writeDescriptors(ts.TransportDescriptors); err != nil {
+		err = errors.Wrap(err, "astits: writing descriptors failed")
+		return
+	}
+	b = append(b, bd...)
+	return
+}
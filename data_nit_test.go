@@ -36,3 +36,37 @@ func TestParseNITSection(t *testing.T) {
 	d := parseNITSection(b, &offset, uint16(1))
 	assert.Equal(t, d, nit)
 }
+
+func TestNITDataSerialize(t *testing.T) {
+	// Single section
+	ss, err := nit.Serialize(2)
+	assert.NoError(t, err)
+	assert.Len(t, ss, 1)
+	d, err := parsePSIData(writePSIPayload(ss), PIDNIT, CRCModeError)
+	assert.NoError(t, err)
+	assert.Equal(t, PSITableTypeNIT, d.Sections[0].Header.TableType)
+	assert.Equal(t, uint8(2), d.Sections[0].Syntax.Header.VersionNumber)
+	assert.Equal(t, nit, d.Sections[0].Syntax.Data.NIT)
+
+	// Transport streams
+	var e = NewNITData(1, "network")
+	for idx := 0; idx < 80; idx++ {
+		e.TransportStreams = append(e.TransportStreams, &NITDataTransportStream{
+			OriginalNetworkID: 1,
+			TransportDescriptors: []*Descriptor{{
+				Length:                    11,
+				Tag:                       DescriptorTagTerrestrialDeliverySystem,
+				TerrestrialDeliverySystem: &DescriptorTerrestrialDeliverySystem{Frequency: 474000000},
+			}},
+			TransportStreamID: uint16(idx),
+		})
+	}
+	ss, err = e.Serialize(0)
+	assert.NoError(t, err)
+	assert.Len(t, ss, 2)
+	d, err = parsePSIData(writePSIPayload(ss), PIDNIT, CRCModeError)
+	assert.NoError(t, err)
+	assert.Len(t, d.Sections, 2)
+	assert.Equal(t, e.NetworkDescriptors, d.Sections[1].Syntax.Data.NIT.NetworkDescriptors)
+	assert.Equal(t, e.TransportStreams, append(d.Sections[0].Syntax.Data.NIT.TransportStreams, d.Sections[1].Syntax.Data.NIT.TransportStreams...))
+}
@@ -1,5 +1,7 @@
 package astits
 
+import "github.com/pkg/errors"
+
 // PATData represents a PAT data
 // https://en.wikipedia.org/wiki/Program-specific_information
 type PATData struct {
@@ -28,3 +30,41 @@ func parsePATSection(i []byte, offset *int, offsetSectionsEnd int, tableIDExtens
 	}
 	return
 }
+
+// Serialize serializes the PAT data into as many sections as needed, CRC32 included
+func (d *PATData) Serialize(versionNumber uint8) (ss [][]byte, err error) {
+	// Programs
+	var items [][]byte
+	for _, p := range d.Programs {
+		items = append(items, writePATProgram(p))
+	}
+
+	// Sections
+	if ss, err = writePSISections(
+		&PSISectionHeader{SectionSyntaxIndicator: true, TableID: 0},
+		&PSISectionSyntaxHeader{
+			CurrentNextIndicator: true,
+			TableIDExtension:     d.TransportStreamID,
+			VersionNumber:        versionNumber,
+		},
+		nil,
+		items,
+	); err != nil {
+		err = errors.Wrap(err, "astits: writing PSI sections failed")
+		return
+	}
+	return
+}
+
+// writePATSection serializes a PAT section data
+func writePATSection(d *PATData) (b []byte) {
+	for _, p := range d.Programs {
+		b = append(b, writePATProgram(p)...)
+	}
+	return
+}
+
+// writePATProgram serializes a PAT program
+func writePATProgram(p *PATProgram) []byte {
+	return []byte{uint8(p.ProgramNumber >> 8), uint8(p.ProgramNumber), 0xe0 | uint8(p.ProgramMapID>>8)&0x1f, uint8(p.ProgramMapID)}
+}
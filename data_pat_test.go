@@ -32,3 +32,37 @@ func TestParsePATSection(t *testing.T) {
 	d := parsePATSection(b, &offset, len(b), uint16(1))
 	assert.Equal(t, d, pat)
 }
+
+func TestWritePATSection(t *testing.T) {
+	assert.Equal(t, patBytes(), writePATSection(pat))
+}
+
+func TestPATDataSerialize(t *testing.T) {
+	ss, err := pat.Serialize(4)
+	assert.NoError(t, err)
+	assert.Len(t, ss, 1)
+	d, err := parsePSIData(append([]byte{0x0}, ss[0]...), PIDPAT, CRCModeError)
+	assert.NoError(t, err)
+	assert.Len(t, d.Sections, 1)
+	assert.Equal(t, uint8(4), d.Sections[0].Syntax.Header.VersionNumber)
+	assert.True(t, d.Sections[0].Syntax.Header.CurrentNextIndicator)
+	assert.Equal(t, pat, d.Sections[0].Syntax.Data.PAT)
+
+	// Several sections
+	var ps []*PATProgram
+	for idx := 1; idx <= 300; idx++ {
+		ps = append(ps, &PATProgram{ProgramMapID: uint16(0x1000 + idx), ProgramNumber: uint16(idx)})
+	}
+	ss, err = (&PATData{Programs: ps, TransportStreamID: 1}).Serialize(0)
+	assert.NoError(t, err)
+	assert.Len(t, ss, 2)
+	var programs []*PATProgram
+	for idx, s := range ss {
+		d, err = parsePSIData(append([]byte{0x0}, s...), PIDPAT, CRCModeError)
+		assert.NoError(t, err)
+		assert.Equal(t, uint8(idx), d.Sections[0].Syntax.Header.SectionNumber)
+		assert.Equal(t, uint8(1), d.Sections[0].Syntax.Header.LastSectionNumber)
+		programs = append(programs, d.Sections[0].Syntax.Data.PAT.Programs...)
+	}
+	assert.Equal(t, ps, programs)
+}
@@ -25,6 +25,7 @@ const (
 	StreamIDPrivateStream1 = 189
 	StreamIDPaddingStream  = 190
 	StreamIDPrivateStream2 = 191
+	StreamIDMetadataStream = 252 // PES payload is made of metadata access unit cells
 )
 
 // Trick mode controls
@@ -41,8 +42,10 @@ const (
 // http://dvd.sourceforge.net/dvdinfo/pes-hdr.html
 // http://happy.emu.id.au/lab/tut/dttb/dtbtut4b.htm
 type PESData struct {
-	Data   []byte
-	Header *PESHeader
+	Data            []byte
+	Header          *PESHeader
+	IsLengthBounded bool // True when the PES packet length is set. Otherwise the PES ends at the next payload unit start of its PID or at the end of the stream. Ignored when serializing since the length is computed
+	Truncated       bool // True when the stream was cut off before the end of the PES packet. Data then only contains the bytes that were received
 }
 
 // PESHeader represents a packet PES header
@@ -107,6 +110,12 @@ func parsePESData(i []byte) (d *PESData, err error) {
 	// Init
 	d = &PESData{}
 
+	// Check for incomplete header
+	if len(i) < 6 {
+		err = fmt.Errorf("astits: pes len(i) (%d) < 6", len(i))
+		return
+	}
+
 	// Parse header
 	var offset, dataStart, dataEnd = 3, 0, 0
 	if d.Header, dataStart, dataEnd, err = parsePESHeader(i, &offset); err != nil {
@@ -114,6 +123,19 @@ func parsePESData(i []byte) (d *PESData, err error) {
 		return
 	}
 
+	// Check for truncated data
+	d.IsLengthBounded = d.Header.PacketLength > 0
+	if dataEnd > len(i) {
+		d.Truncated = true
+		dataEnd = len(i)
+	}
+
+	// Check for invalid header length
+	if dataStart > dataEnd {
+		err = fmt.Errorf("astits: pes dataStart (%d) > dataEnd (%d)", dataStart, dataEnd)
+		return
+	}
+
 	// Parse data
 	d.Data = i[dataStart:dataEnd]
 	return
@@ -144,15 +166,17 @@ func parsePESHeader(i []byte, offset *int) (h *PESHeader, dataStart, dataEnd int
 		dataEnd = len(i)
 	}
 
-	// Check for incomplete data
-	if dataEnd > len(i) {
-		err = fmt.Errorf("astits: pes dataEnd (%d) > len(i) (%d)", dataEnd, len(i))
-		return
-	}
-
 	// Optional header
 	if hasPESOptionalHeader(h.StreamID) {
-		h.OptionalHeader, dataStart = parsePESOptionalHeader(i, offset)
+		// Check for incomplete optional header
+		if *offset+3 > len(i) || *offset+3+int(i[*offset+2]) > len(i) {
+			err = fmt.Errorf("astits: pes optional header end > len(i) (%d)", len(i))
+			return
+		}
+		if h.OptionalHeader, dataStart, err = parsePESOptionalHeader(i, offset); err != nil {
+			err = errors.Wrap(err, "astits: parsing PES optional header failed")
+			return
+		}
 	} else {
 		dataStart = *offset
 	}
@@ -160,7 +184,8 @@ func parsePESHeader(i []byte, offset *int) (h *PESHeader, dataStart, dataEnd int
 }
 
 // parsePESOptionalHeader parses a PES optional header
-func parsePESOptionalHeader(i []byte, offset *int) (h *PESOptionalHeader, dataStart int) {
+// Fields going beyond the header length or the payload return an error
+func parsePESOptionalHeader(i []byte, offset *int) (h *PESOptionalHeader, dataStart int, err error) {
 	// Init
 	h = &PESOptionalHeader{}
 
@@ -204,9 +229,15 @@ func parsePESOptionalHeader(i []byte, offset *int) (h *PESOptionalHeader, dataSt
 
 	// PTS/DTS
 	if h.PTSDTSIndicator == PTSDTSIndicatorOnlyPTS {
+		if err = checkPESOptionalHeaderField(i, *offset, 5, dataStart, "PTS"); err != nil {
+			return
+		}
 		h.PTS = parsePTSOrDTS(i[*offset:])
 		*offset += 5
 	} else if h.PTSDTSIndicator == PTSDTSIndicatorBothPresent {
+		if err = checkPESOptionalHeaderField(i, *offset, 10, dataStart, "PTS and DTS"); err != nil {
+			return
+		}
 		h.PTS = parsePTSOrDTS(i[*offset:])
 		*offset += 5
 		h.DTS = parsePTSOrDTS(i[*offset:])
@@ -215,37 +246,55 @@ func parsePESOptionalHeader(i []byte, offset *int) (h *PESOptionalHeader, dataSt
 
 	// ESCR
 	if h.HasESCR {
+		if err = checkPESOptionalHeaderField(i, *offset, 6, dataStart, "ESCR"); err != nil {
+			return
+		}
 		h.ESCR = parseESCR(i[*offset:])
 		*offset += 6
 	}
 
 	// ES rate
 	if h.HasESRate {
+		if err = checkPESOptionalHeaderField(i, *offset, 3, dataStart, "ES rate"); err != nil {
+			return
+		}
 		h.ESRate = uint32(i[*offset])&0x7f<<15 | uint32(i[*offset+1])<<7 | uint32(i[*offset+2])>>1
 		*offset += 3
 	}
 
 	// Trick mode
 	if h.HasDSMTrickMode {
+		if err = checkPESOptionalHeaderField(i, *offset, 1, dataStart, "DSM trick mode"); err != nil {
+			return
+		}
 		h.DSMTrickMode = parseDSMTrickMode(i[*offset])
 		*offset += 1
 	}
 
 	// Additional copy info
 	if h.HasAdditionalCopyInfo {
+		if err = checkPESOptionalHeaderField(i, *offset, 1, dataStart, "additional copy info"); err != nil {
+			return
+		}
 		h.AdditionalCopyInfo = i[*offset] & 0x7f
 		*offset += 1
 	}
 
 	// CRC
 	if h.HasCRC {
-		h.CRC = uint16(i[*offset])>>8 | uint16(i[*offset+1])
+		if err = checkPESOptionalHeaderField(i, *offset, 2, dataStart, "CRC"); err != nil {
+			return
+		}
+		h.CRC = uint16(i[*offset])<<8 | uint16(i[*offset+1])
 		*offset += 2
 	}
 
 	// Extension
 	if h.HasExtension {
 		// Flags
+		if err = checkPESOptionalHeaderField(i, *offset, 1, dataStart, "extension flags"); err != nil {
+			return
+		}
 		h.HasPrivateData = i[*offset]&0x80 > 0
 		h.HasPackHeaderField = i[*offset]&0x40 > 0
 		h.HasProgramPacketSequenceCounter = i[*offset]&0x20 > 0
@@ -255,18 +304,27 @@ func parsePESOptionalHeader(i []byte, offset *int) (h *PESOptionalHeader, dataSt
 
 		// Private data
 		if h.HasPrivateData {
+			if err = checkPESOptionalHeaderField(i, *offset, 16, dataStart, "private data"); err != nil {
+				return
+			}
 			h.PrivateData = i[*offset : *offset+16]
 			*offset += 16
 		}
 
 		// Pack field length
 		if h.HasPackHeaderField {
+			if err = checkPESOptionalHeaderField(i, *offset, 1, dataStart, "pack header field"); err != nil {
+				return
+			}
 			h.PackField = uint8(i[*offset])
 			*offset += 1
 		}
 
 		// Program packet sequence counter
 		if h.HasProgramPacketSequenceCounter {
+			if err = checkPESOptionalHeaderField(i, *offset, 2, dataStart, "program packet sequence counter"); err != nil {
+				return
+			}
 			h.PacketSequenceCounter = uint8(i[*offset]) & 0x7f
 			h.MPEG1OrMPEG2ID = uint8(i[*offset+1]) >> 6 & 0x1
 			h.OriginalStuffingLength = uint8(i[*offset+1]) & 0x3f
@@ -275,6 +333,9 @@ func parsePESOptionalHeader(i []byte, offset *int) (h *PESOptionalHeader, dataSt
 
 		// P-STD buffer
 		if h.HasPSTDBuffer {
+			if err = checkPESOptionalHeaderField(i, *offset, 2, dataStart, "P-STD buffer"); err != nil {
+				return
+			}
 			h.PSTDBufferScale = i[*offset] >> 5 & 0x1
 			h.PSTDBufferSize = uint16(i[*offset])&0x1f<<8 | uint16(i[*offset+1])
 			*offset += 2
@@ -283,10 +344,16 @@ func parsePESOptionalHeader(i []byte, offset *int) (h *PESOptionalHeader, dataSt
 		// Extension 2
 		if h.HasExtension2 {
 			// Length
+			if err = checkPESOptionalHeaderField(i, *offset, 2, dataStart, "extension 2 length"); err != nil {
+				return
+			}
 			h.Extension2Length = uint8(i[*offset]) & 0x7f
 			*offset += 2
 
 			// Data
+			if err = checkPESOptionalHeaderField(i, *offset, int(h.Extension2Length), dataStart, "extension 2 data"); err != nil {
+				return
+			}
 			h.Extension2Data = i[*offset : *offset+int(h.Extension2Length)]
 			*offset += int(h.Extension2Length)
 		}
@@ -294,6 +361,15 @@ func parsePESOptionalHeader(i []byte, offset *int) (h *PESOptionalHeader, dataSt
 	return
 }
 
+// checkPESOptionalHeaderField checks that a field of a PES optional header ends before both the header end and the
+// end of the payload
+func checkPESOptionalHeaderField(i []byte, offset, n, headerEnd int, name string) error {
+	if offset+n > headerEnd || offset+n > len(i) {
+		return fmt.Errorf("astits: pes optional header %s end (%d) > header end (%d) or len(i) (%d)", name, offset+n, headerEnd, len(i))
+	}
+	return nil
+}
+
 // parseDSMTrickMode parses a DSM trick mode
 func parseDSMTrickMode(i byte) (m *DSMTrickMode) {
 	m = &DSMTrickMode{}
@@ -320,3 +396,220 @@ func parseESCR(i []byte) *ClockReference {
 	var escr = uint64(i[0])>>3&0x7<<39 | uint64(i[0])&0x3<<37 | uint64(i[1])<<29 | uint64(i[2])>>3<<24 | uint64(i[2])&0x3<<22 | uint64(i[3])<<14 | uint64(i[4])>>3<<9 | uint64(i[4])&0x3<<7 | uint64(i[5])>>1
 	return newClockReference(int(escr>>9), int(escr&0x1ff))
 }
+
+// Serialize serializes the PES data into a PES packet, ready to be split into TS packets
+// The packet length is computed from the optional header and the data. It is set to 0 when it exceeds 65535 bytes,
+// which is only allowed for video elementary streams. The PTS DTS indicator and the header length of the optional
+// header are computed as well
+func (d *PESData) Serialize() []byte {
+	return writePESData(d)
+}
+
+// writePESData serializes a PES data
+func writePESData(d *PESData) (b []byte) {
+	// Prefix and stream ID
+	b = append(b, 0x0, 0x0, 0x1, d.Header.StreamID)
+
+	// Optional header
+	var oh []byte
+	if hasPESOptionalHeader(d.Header.StreamID) && d.Header.OptionalHeader != nil {
+		oh = writePESOptionalHeader(d.Header.OptionalHeader)
+	}
+
+	// Packet length
+	var l = len(oh) + len(d.Data)
+	if l > 0xffff {
+		l = 0
+	}
+	b = append(b, uint8(l>>8), uint8(l))
+
+	// Optional header and data
+	b = append(b, oh...)
+	b = append(b, d.Data...)
+	return
+}
+
+// writePESOptionalHeader serializes a PES optional header
+// Optional fields are written when their pointer is set or, for fields that are values, when their flag is set
+func writePESOptionalHeader(h *PESOptionalHeader) (b []byte) {
+	// Flags
+	var flags = uint8(0x80) | h.ScramblingControl&0x3<<4
+	if h.Priority {
+		flags |= 0x8
+	}
+	if h.DataAlignmentIndicator {
+		flags |= 0x4
+	}
+	if h.IsCopyrighted {
+		flags |= 0x2
+	}
+	if h.IsOriginal {
+		flags |= 0x1
+	}
+	b = append(b, flags)
+
+	// PTS DTS indicator
+	var ptsDTSIndicator = uint8(PTSDTSIndicatorNoPTSOrDTS)
+	if h.PTS != nil && h.DTS != nil {
+		ptsDTSIndicator = PTSDTSIndicatorBothPresent
+	} else if h.PTS != nil {
+		ptsDTSIndicator = PTSDTSIndicatorOnlyPTS
+	}
+
+	// Optional fields flags
+	flags = ptsDTSIndicator << 6
+	if h.ESCR != nil {
+		flags |= 0x20
+	}
+	if h.HasESRate {
+		flags |= 0x10
+	}
+	if h.DSMTrickMode != nil {
+		flags |= 0x8
+	}
+	if h.HasAdditionalCopyInfo {
+		flags |= 0x4
+	}
+	if h.HasCRC {
+		flags |= 0x2
+	}
+	if h.HasExtension {
+		flags |= 0x1
+	}
+	b = append(b, flags)
+
+	// PTS/DTS
+	var f []byte
+	if ptsDTSIndicator == PTSDTSIndicatorOnlyPTS {
+		f = append(f, writePTSOrDTS(0x2, h.PTS)...)
+	} else if ptsDTSIndicator == PTSDTSIndicatorBothPresent {
+		f = append(f, writePTSOrDTS(0x3, h.PTS)...)
+		f = append(f, writePTSOrDTS(0x1, h.DTS)...)
+	}
+
+	// ESCR
+	if h.ESCR != nil {
+		f = append(f, writeESCR(h.ESCR)...)
+	}
+
+	// ES rate
+	if h.HasESRate {
+		f = append(f, 0x80|uint8(h.ESRate>>15)&0x7f, uint8(h.ESRate>>7), uint8(h.ESRate<<1)|0x1)
+	}
+
+	// Trick mode
+	if h.DSMTrickMode != nil {
+		f = append(f, writeDSMTrickMode(h.DSMTrickMode))
+	}
+
+	// Additional copy info
+	if h.HasAdditionalCopyInfo {
+		f = append(f, 0x80|h.AdditionalCopyInfo&0x7f)
+	}
+
+	// CRC
+	if h.HasCRC {
+		f = append(f, uint8(h.CRC>>8), uint8(h.CRC))
+	}
+
+	// Extension
+	if h.HasExtension {
+		f = append(f, writePESOptionalHeaderExtension(h)...)
+	}
+
+	// Header length
+	b = append(b, uint8(len(f)))
+	b = append(b, f...)
+	return
+}
+
+// writePESOptionalHeaderExtension serializes the extension of a PES optional header
+func writePESOptionalHeaderExtension(h *PESOptionalHeader) (b []byte) {
+	// Flags
+	var flags = uint8(0xe)
+	if h.HasPrivateData {
+		flags |= 0x80
+	}
+	if h.HasPackHeaderField {
+		flags |= 0x40
+	}
+	if h.HasProgramPacketSequenceCounter {
+		flags |= 0x20
+	}
+	if h.HasPSTDBuffer {
+		flags |= 0x10
+	}
+	if h.HasExtension2 {
+		flags |= 0x1
+	}
+	b = append(b, flags)
+
+	// Private data is always 16 bytes long
+	if h.HasPrivateData {
+		var d = make([]byte, 16)
+		copy(d, h.PrivateData)
+		b = append(b, d...)
+	}
+
+	// Pack field length
+	if h.HasPackHeaderField {
+		b = append(b, h.PackField)
+	}
+
+	// Program packet sequence counter
+	if h.HasProgramPacketSequenceCounter {
+		b = append(b, 0x80|h.PacketSequenceCounter&0x7f, 0x80|h.MPEG1OrMPEG2ID&0x1<<6|h.OriginalStuffingLength&0x3f)
+	}
+
+	// P-STD buffer
+	if h.HasPSTDBuffer {
+		b = append(b, 0x40|h.PSTDBufferScale&0x1<<5|uint8(h.PSTDBufferSize>>8)&0x1f, uint8(h.PSTDBufferSize))
+	}
+
+	// Extension 2
+	if h.HasExtension2 {
+		b = append(b, 0x80|uint8(len(h.Extension2Data))&0x7f, 0x0)
+		b = append(b, h.Extension2Data...)
+	}
+	return
+}
+
+// writeESCR serializes an ESCR
+func writeESCR(cr *ClockReference) []byte {
+	var base, ext = uint64(cr.Base), uint64(cr.Extension)
+	return []byte{
+		0xc0 | uint8(base>>27)&0x38 | 0x4 | uint8(base>>28)&0x3,
+		uint8(base >> 20),
+		uint8(base>>12)&0xf8 | 0x4 | uint8(base>>13)&0x3,
+		uint8(base >> 5),
+		uint8(base<<3) | 0x4 | uint8(ext>>7)&0x3,
+		uint8(ext<<1) | 0x1,
+	}
+}
+
+// writeDSMTrickMode serializes a DSM trick mode
+func writeDSMTrickMode(m *DSMTrickMode) (b uint8) {
+	b = m.TrickModeControl << 5
+	if m.TrickModeControl == TrickModeControlFastForward || m.TrickModeControl == TrickModeControlFastReverse {
+		b |= m.FieldID&0x3<<3 | m.IntraSliceRefresh&0x1<<2 | m.FrequencyTruncation&0x3
+	} else if m.TrickModeControl == TrickModeControlFreezeFrame {
+		b |= m.FieldID&0x3<<3 | 0x7
+	} else if m.TrickModeControl == TrickModeControlSlowMotion || m.TrickModeControl == TrickModeControlSlowReverse {
+		b |= m.RepeatControl & 0x1f
+	} else {
+		b |= 0x1f
+	}
+	return
+}
+
+// writePTSOrDTS serializes a PTS or a DTS preceded by its 4 bits flag
+func writePTSOrDTS(flag uint8, cr *ClockReference) []byte {
+	var v = uint64(cr.Base)
+	return []byte{
+		flag<<4 | uint8(v>>29)&0xe | 0x1,
+		uint8(v >> 22),
+		uint8(v>>14)&0xfe | 0x1,
+		uint8(v >> 7),
+		uint8(v<<1) | 0x1,
+	}
+}
@@ -113,6 +113,7 @@ var pesWithoutHeader = &PESData{
 		PacketLength: 9,
 		StreamID:     StreamIDPaddingStream,
 	},
+	IsLengthBounded: true,
 }
 
 func pesWithoutHeaderBytes() []byte {
@@ -224,3 +225,85 @@ func TestParsePESSection(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, d, pesWithHeader)
 }
+
+func TestWritePESData(t *testing.T) {
+	// Only PTS
+	d := &PESData{
+		Data: []byte("data"),
+		Header: &PESHeader{
+			OptionalHeader: &PESOptionalHeader{
+				DataAlignmentIndicator: true,
+				PTS:                    ptsClockReference,
+			},
+			StreamID: 0xe0,
+		},
+	}
+	o, err := parsePESData(writePESData(d))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("data"), o.Data)
+	assert.Equal(t, uint16(12), o.Header.PacketLength)
+	assert.True(t, o.Header.OptionalHeader.DataAlignmentIndicator)
+	assert.Equal(t, uint8(PTSDTSIndicatorOnlyPTS), o.Header.OptionalHeader.PTSDTSIndicator)
+	assert.Equal(t, ptsClockReference, o.Header.OptionalHeader.PTS)
+
+	// PTS and DTS
+	d.Header.OptionalHeader.DTS = dtsClockReference
+	o, err = parsePESData(writePESData(d))
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(PTSDTSIndicatorBothPresent), o.Header.OptionalHeader.PTSDTSIndicator)
+	assert.Equal(t, ptsClockReference, o.Header.OptionalHeader.PTS)
+	assert.Equal(t, dtsClockReference, o.Header.OptionalHeader.DTS)
+
+	// Unbounded
+	d.Data = make([]byte, 0x10000)
+	assert.Equal(t, []byte{0x0, 0x0}, writePESData(d)[4:6])
+}
+
+func TestPESDataSerialize(t *testing.T) {
+	// All optional fields
+	o, err := parsePESData(pesWithHeader.Serialize())
+	assert.NoError(t, err)
+	h := *pesWithHeader.Header.OptionalHeader
+	h.HeaderLength = 57
+	assert.Equal(t, &PESData{
+		Data: pesWithHeader.Data,
+		Header: &PESHeader{
+			OptionalHeader: &h,
+			PacketLength:   69,
+			StreamID:       pesWithHeader.Header.StreamID,
+		},
+		IsLengthBounded: true,
+	}, o)
+
+	// Trick modes
+	for _, m := range []*DSMTrickMode{
+		{FieldID: 2, FrequencyTruncation: 1, IntraSliceRefresh: 1, TrickModeControl: TrickModeControlFastForward},
+		{FieldID: 1, TrickModeControl: TrickModeControlFreezeFrame},
+	} {
+		assert.Equal(t, m, parseDSMTrickMode(writeDSMTrickMode(m)))
+	}
+}
+
+func TestParsePESDataTruncated(t *testing.T) {
+	// Truncated data
+	b := pesWithoutHeaderBytes()
+	d, err := parsePESData(b[:13])
+	assert.NoError(t, err)
+	assert.True(t, d.Truncated)
+	assert.Equal(t, []byte("stuffda"), d.Data)
+
+	// Incomplete header
+	_, err = parsePESData(b[:5])
+	assert.Error(t, err)
+	_, err = parsePESData(pesWithHeaderBytes()[:10])
+	assert.Error(t, err)
+
+	// Optional header fields beyond the header length
+	b = make([]byte, 54)
+	for idx := range b {
+		b[idx] = 0xff
+	}
+	copy(b, []byte{0x0, 0x0, 0x1, 0x80, 0xa7, 0x7f, 0x3d, 0xfd, 0x25, 0x67, 0xc1, 0x89, 0x79, 0xe4, 0xd6, 0x0f})
+	_, err = parsePESData(b)
+	assert.Error(t, err)
+}
@@ -1,13 +1,37 @@
 package astits
 
+import "github.com/pkg/errors"
+
 // Stream types
 const (
-	StreamTypeLowerBitrateVideo          = 27 // ITU-T Rec. H.264 and ISO/IEC 14496-10
-	StreamTypeMPEG1Audio                 = 3  // ISO/IEC 11172-3
-	StreamTypeMPEG2HalvedSampleRateAudio = 4  // ISO/IEC 13818-3
-	StreamTypeMPEG2PacketizedData        = 6  // ITU-T Rec. H.222 and ISO/IEC 13818-1 i.e., DVB subtitles/VBI and AC-3
+	StreamTypeAC3Audio                   = 0x81 // ATSC A/52 AC-3
+	StreamTypeADTSAudio                  = 0x0f // ISO/IEC 13818-7 Audio with ADTS transport syntax
+	StreamTypeDSMCCMPE                   = 0x0a // ISO/IEC 13818-6 type A i.e., DVB multi-protocol encapsulation
+	StreamTypeDSMCCSections              = 0x0d // ISO/IEC 13818-6 type D i.e., DSM-CC sections of any type, including DVB multi-protocol encapsulation
+	StreamTypeDSMCCUNMessages            = 0x0b // ISO/IEC 13818-6 type B i.e., DSM-CC object and data carousels
+	StreamTypeEAC3Audio                  = 0x87 // ATSC A/52 Annex E E-AC-3
+	StreamTypeHEVCVideo                  = 0x24 // ITU-T Rec. H.265 and ISO/IEC 23008-2
+	StreamTypeLowerBitrateVideo          = 27   // ITU-T Rec. H.264 and ISO/IEC 14496-10
+	StreamTypeMetadataPES                = 0x15 // ITU-T Rec. H.222 and ISO/IEC 13818-1 metadata carried in PES packets i.e., ID3 timed metadata
+	StreamTypeMPEG1Audio                 = 3    // ISO/IEC 11172-3
+	StreamTypeMPEG1Video                 = 1    // ISO/IEC 11172-2
+	StreamTypeMPEG2HalvedSampleRateAudio = 4    // ISO/IEC 13818-3
+	StreamTypeMPEG2PacketizedData        = 6    // ITU-T Rec. H.222 and ISO/IEC 13818-1 i.e., DVB subtitles/VBI and AC-3
+	StreamTypeMPEG2Video                 = 2    // ITU-T Rec. H.262 and ISO/IEC 13818-2
+	StreamTypePrivateSections            = 5    // ITU-T Rec. H.222 and ISO/IEC 13818-1 private sections i.e., AIT
+	StreamTypeSCTE35                     = 0x86 // ANSI/SCTE 35 splice information
+	StreamTypeULE                        = 0x91 // IETF RFC 4326 unidirectional lightweight encapsulation
 )
 
+// isVideoStreamType checks whether a stream type is a video stream type
+func isVideoStreamType(t uint8) bool {
+	switch t {
+	case StreamTypeHEVCVideo, StreamTypeLowerBitrateVideo, StreamTypeMPEG1Video, StreamTypeMPEG2Video:
+		return true
+	}
+	return false
+}
+
 // PMTData represents a PMT data
 // https://en.wikipedia.org/wiki/Program-specific_information
 type PMTData struct {
@@ -24,6 +48,125 @@ type PMTElementaryStream struct {
 	StreamType                  uint8         // This defines the structure of the data contained within the elementary packet identifier.
 }
 
+// SubtitleTrack represents a DVB subtitle track announced in a PMT
+type SubtitleTrack struct {
+	AncillaryPageID   uint16
+	CompositionPageID uint16
+	ElementaryPID     uint16
+	Language          string
+	Type              uint8
+}
+
+// ECMPIDs returns the PIDs carrying ECMs indexed by CA system ID, as announced by the CA descriptors of the program
+// and of its elementary streams
+func (d *PMTData) ECMPIDs() (o map[uint16][]uint16) {
+	o = make(map[uint16][]uint16)
+	var dss = [][]*Descriptor{d.ProgramDescriptors}
+	for _, es := range d.ElementaryStreams {
+		dss = append(dss, es.ElementaryStreamDescriptors)
+	}
+	for _, ds := range dss {
+		for _, dsc := range ds {
+			if dsc.CA != nil {
+				o[dsc.CA.CASystemID] = append(o[dsc.CA.CASystemID], dsc.CA.CAPID)
+			}
+		}
+	}
+	return
+}
+
+// SubtitleTracks returns the DVB subtitle tracks announced in the PMT, which are carried on elementary streams of the
+// StreamTypeMPEG2PacketizedData stream type described by a subtitling descriptor
+// Each item of the subtitling descriptor is a track, so that an elementary stream may carry several tracks
+func (d *PMTData) SubtitleTracks() []*SubtitleTrack {
+	return subtitleTracks(d.ElementaryStreams)
+}
+
+// subtitleTracks returns the DVB subtitle tracks of elementary streams
+func subtitleTracks(ess []*PMTElementaryStream) (ts []*SubtitleTrack) {
+	for _, es := range ess {
+		// Only packetized data can carry DVB subtitles
+		if es.StreamType != StreamTypeMPEG2PacketizedData {
+			continue
+		}
+
+		// Loop through descriptors
+		for _, d := range es.ElementaryStreamDescriptors {
+			if d.Subtitling == nil {
+				continue
+			}
+			for _, itm := range d.Subtitling.Items {
+				ts = append(ts, &SubtitleTrack{
+					AncillaryPageID:   itm.AncillaryPageID,
+					CompositionPageID: itm.CompositionPageID,
+					ElementaryPID:     es.ElementaryPID,
+					Language:          string(itm.Language),
+					Type:              itm.Type,
+				})
+			}
+		}
+	}
+	return
+}
+
+// hasApplicationSignallingDescriptor checks whether an elementary stream carries an AIT, which is flagged by an
+// application signalling descriptor
+func hasApplicationSignallingDescriptor(es *PMTElementaryStream) bool {
+	for _, d := range es.ElementaryStreamDescriptors {
+		if d.ApplicationSignalling != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatIdentifier returns the format identifier of the first registration descriptor of the program, or 0 if there is
+// none
+func (d *PMTData) FormatIdentifier() uint32 {
+	return registrationFormatIdentifier(d.ProgramDescriptors)
+}
+
+// FormatIdentifier returns the format identifier of the first registration descriptor of the elementary stream, or 0
+// if there is none
+// Formats of elementary streams of private stream types, such as StreamTypeMPEG2PacketizedData, are often only
+// signaled this way
+func (es *PMTElementaryStream) FormatIdentifier() uint32 {
+	return registrationFormatIdentifier(es.ElementaryStreamDescriptors)
+}
+
+// registrationFormatIdentifier returns the format identifier of the first registration descriptor of a descriptors
+// loop, or 0 if there is none
+func registrationFormatIdentifier(ds []*Descriptor) uint32 {
+	for _, d := range ds {
+		if d.Registration != nil {
+			return d.Registration.FormatIdentifier
+		}
+	}
+	return 0
+}
+
+// hasRegistrationDescriptor checks whether an elementary stream has a registration descriptor with the provided format
+// identifier
+func hasRegistrationDescriptor(es *PMTElementaryStream, formatIdentifier uint32) bool {
+	for _, d := range es.ElementaryStreamDescriptors {
+		if d.Registration != nil && d.Registration.FormatIdentifier == formatIdentifier {
+			return true
+		}
+	}
+	return false
+}
+
+// metadataDescriptor returns the metadata descriptor of an elementary stream if it signals the provided format
+// identifier
+func metadataDescriptor(es *PMTElementaryStream, formatIdentifier uint32) *DescriptorMetadata {
+	for _, d := range es.ElementaryStreamDescriptors {
+		if d.Metadata != nil && d.Metadata.Format == MetadataFormatIdentifierField && d.Metadata.FormatIdentifier == formatIdentifier {
+			return d.Metadata
+		}
+	}
+	return nil
+}
+
 // parsePMTSection parses a PMT section
 func parsePMTSection(i []byte, offset *int, offsetSectionsEnd int, tableIDExtension uint16) (d *PMTData) {
 	// Init
@@ -55,3 +198,53 @@ func parsePMTSection(i []byte, offset *int, offsetSectionsEnd int, tableIDExtens
 	}
 	return
 }
+
+// Serialize serializes the PMT data into a complete PMT section, CRC32 included
+func (d *PMTData) Serialize(versionNumber uint8) (b []byte, err error) {
+	// Data
+	var bd []byte
+	if bd, err = writePMTSection(d); err != nil {
+		err = errors.Wrap(err, "astits: writing PMT section failed")
+		return
+	}
+
+	// Section
+	b = writePSISection(
+		&PSISectionHeader{SectionSyntaxIndicator: true, TableID: 2},
+		&PSISectionSyntaxHeader{
+			CurrentNextIndicator: true,
+			TableIDExtension:     d.ProgramNumber,
+			VersionNumber:        versionNumber,
+		},
+		bd,
+	)
+	return
+}
+
+// writePMTSection serializes a PMT section data
+func writePMTSection(d *PMTData) (b []byte, err error) {
+	// PCR PID
+	b = append(b, 0xe0|uint8(d.PCRPID>>8)&0x1f, uint8(d.PCRPID))
+
+	// Program descriptors
+	var bd []byte
+	if bd, err = writeDescriptors(d.ProgramDescriptors); err != nil {
+		err = errors.Wrap(err, "astits: writing program descriptors failed")
+		return
+	}
+	b = append(b, bd...)
+
+	// Elementary streams
+	for _, e := range d.ElementaryStreams {
+		// Stream type and elementary PID
+		b = append(b, e.StreamType, 0xe0|uint8(e.ElementaryPID>>8)&0x1f, uint8(e.ElementaryPID))
+
+		// Elementary stream descriptors
+		if bd, err = writeDescriptors(e.ElementaryStreamDescriptors); err != nil {
+			err = errors.Wrapf(err, "astits: writing descriptors of elementary stream with PID %d failed", e.ElementaryPID)
+			return
+		}
+		b = append(b, bd...)
+	}
+	return
+}
@@ -38,3 +38,67 @@ func TestParsePMTSection(t *testing.T) {
 	d := parsePMTSection(b, &offset, len(b), uint16(1))
 	assert.Equal(t, d, pmt)
 }
+
+func TestWritePMTSection(t *testing.T) {
+	b, err := writePMTSection(pmt)
+	assert.NoError(t, err)
+	assert.Equal(t, pmtBytes(), b)
+}
+
+func TestPMTDataSerialize(t *testing.T) {
+	b, err := pmt.Serialize(3)
+	assert.NoError(t, err)
+	d, err := parsePSIData(append([]byte{0x0}, b...), uint16(0x1000), CRCModeError)
+	assert.NoError(t, err)
+	assert.Len(t, d.Sections, 1)
+	assert.Equal(t, uint8(3), d.Sections[0].Syntax.Header.VersionNumber)
+	assert.True(t, d.Sections[0].Syntax.Header.CurrentNextIndicator)
+	assert.Equal(t, pmt, d.Sections[0].Syntax.Data.PMT)
+
+	// Descriptor that can't be serialized
+	_, err = (&PMTData{ProgramDescriptors: []*Descriptor{{Length: 1, Tag: DescriptorTagTeletext}}}).Serialize(0)
+	assert.Error(t, err)
+}
+
+func TestPMTDataSubtitleTracks(t *testing.T) {
+	var subtitling = &Descriptor{
+		Subtitling: &DescriptorSubtitling{Items: []*DescriptorSubtitlingItem{
+			{AncillaryPageID: 2, CompositionPageID: 1, Language: []byte("eng"), Type: SubtitlingTypeDVBSubtitles},
+			{AncillaryPageID: 2, CompositionPageID: 3, Language: []byte("fra"), Type: SubtitlingTypeDVBSubtitlesHardOfHearing},
+		}},
+		Tag: DescriptorTagSubtitling,
+	}
+	d := &PMTData{ElementaryStreams: []*PMTElementaryStream{
+		{ElementaryPID: 0x100, StreamType: StreamTypeLowerBitrateVideo},
+		{ElementaryPID: 0x101, ElementaryStreamDescriptors: []*Descriptor{subtitling}, StreamType: StreamTypeMPEG2PacketizedData},
+		{ElementaryPID: 0x102, ElementaryStreamDescriptors: []*Descriptor{subtitling}, StreamType: StreamTypeMPEG1Audio},
+	}}
+	assert.Equal(t, []*SubtitleTrack{
+		{AncillaryPageID: 2, CompositionPageID: 1, ElementaryPID: 0x101, Language: "eng", Type: SubtitlingTypeDVBSubtitles},
+		{AncillaryPageID: 2, CompositionPageID: 3, ElementaryPID: 0x101, Language: "fra", Type: SubtitlingTypeDVBSubtitlesHardOfHearing},
+	}, d.SubtitleTracks())
+	assert.False(t, subtitling.Subtitling.Items[0].IsHardOfHearing())
+	assert.True(t, subtitling.Subtitling.Items[1].IsHardOfHearing())
+}
+
+func TestPMTDataECMPIDs(t *testing.T) {
+	d := &PMTData{
+		ElementaryStreams:  []*PMTElementaryStream{{ElementaryStreamDescriptors: []*Descriptor{{CA: &DescriptorCA{CAPID: 0x102, CASystemID: 0x500}}}}},
+		ProgramDescriptors: []*Descriptor{{CA: &DescriptorCA{CAPID: 0x101, CASystemID: 0x500}}, {CA: &DescriptorCA{CAPID: 0x103, CASystemID: 0x2610}}},
+	}
+	assert.Equal(t, map[uint16][]uint16{0x500: {0x101, 0x102}, 0x2610: {0x103}}, d.ECMPIDs())
+}
+
+func TestPMTDataFormatIdentifier(t *testing.T) {
+	var es = &PMTElementaryStream{ElementaryStreamDescriptors: []*Descriptor{
+		{StreamIdentifier: &DescriptorStreamIdentifier{ComponentTag: 1}},
+		{Registration: &DescriptorRegistration{FormatIdentifier: RegistrationFormatIdentifierAC3}},
+		{Registration: &DescriptorRegistration{FormatIdentifier: RegistrationFormatIdentifierKLVA}},
+	}}
+	var d = &PMTData{ElementaryStreams: []*PMTElementaryStream{es}, ProgramDescriptors: []*Descriptor{{Registration: &DescriptorRegistration{FormatIdentifier: RegistrationFormatIdentifierCUEI}}}}
+	assert.Equal(t, uint32(RegistrationFormatIdentifierCUEI), d.FormatIdentifier())
+	assert.Equal(t, uint32(RegistrationFormatIdentifierAC3), es.FormatIdentifier())
+	assert.Equal(t, uint32(0), (&PMTElementaryStream{}).FormatIdentifier())
+	assert.Equal(t, "AC-3", es.ElementaryStreamDescriptors[1].Registration.FourCC())
+	assert.Equal(t, "...A", (&DescriptorRegistration{FormatIdentifier: 0x41}).FourCC())
+}
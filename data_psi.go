@@ -3,28 +3,58 @@ package astits
 import (
 	"fmt"
 
-	"github.com/asticode/go-astilog"
 	"github.com/pkg/errors"
 )
 
 // PSI table IDs
 const (
+	PSITableTypeAIT     = "AIT"
+	PSITableTypeATSCEIT = "ATSC EIT"
 	PSITableTypeBAT     = "BAT"
+	PSITableTypeBIT     = "BIT"
+	PSITableTypeCAT     = "CAT"
+	PSITableTypeCVCT    = "CVCT"
 	PSITableTypeDIT     = "DIT"
+	PSITableTypeDSMCC   = "DSMCC"
 	PSITableTypeEIT     = "EIT"
+	PSITableTypeETT     = "ETT"
+	PSITableTypeLDT     = "LDT"
+	PSITableTypeMGT     = "MGT"
+	PSITableTypeMPE     = "MPE"
+	PSITableTypeMPEFEC  = "MPE-FEC"
+	PSITableTypeNBIT    = "NBIT"
 	PSITableTypeNIT     = "NIT"
 	PSITableTypeNull    = "Null"
 	PSITableTypePAT     = "PAT"
 	PSITableTypePMT     = "PMT"
+	PSITableTypeRRT     = "RRT"
 	PSITableTypeRST     = "RST"
+	PSITableTypeSCTE35  = "SCTE35"
 	PSITableTypeSDT     = "SDT"
 	PSITableTypeSIT     = "SIT"
 	PSITableTypeST      = "ST"
+	PSITableTypeSTT     = "STT"
 	PSITableTypeTDT     = "TDT"
 	PSITableTypeTOT     = "TOT"
+	PSITableTypeTSDT    = "TSDT"
+	PSITableTypeTVCT    = "TVCT"
 	PSITableTypeUnknown = "Unknown"
 )
 
+// CRC modes
+const (
+	CRCModeDrop  = "drop"  // Sections with an invalid CRC32 are dropped
+	CRCModeError = "error" // Sections with an invalid CRC32 make the parsing of their payload fail
+	CRCModeFlag  = "flag"  // Sections with an invalid CRC32 are kept and the data parsed from them is flagged with InvalidCRC32
+)
+
+// PSI section sizes
+const (
+	psiSectionMaximumLength      = 1021 // Maximum section length for tables defined in ISO/IEC 13818-1 and ETSI EN 300 468
+	psiSectionMaximumNumber      = 256
+	psiSectionSyntaxHeaderLength = 5
+)
+
 // PSIData represents a PSI data
 // https://en.wikipedia.org/wiki/Program-specific_information
 type PSIData struct {
@@ -34,9 +64,11 @@ type PSIData struct {
 
 // PSISection represents a PSI section
 type PSISection struct {
-	CRC32  uint32 // A checksum of the entire table excluding the pointer field, pointer filler bytes and the trailing CRC32.
-	Header *PSISectionHeader
-	Syntax *PSISectionSyntax
+	CRC32        uint32 // A checksum of the entire table excluding the pointer field, pointer filler bytes and the trailing CRC32.
+	Header       *PSISectionHeader
+	InvalidCRC32 bool   // Set when the CRC32 doesn't match the computed one and the CRC mode keeps the section
+	RawSection   []byte // The complete section, from the table ID to the CRC32 included, as it was reassembled.
+	Syntax       *PSISectionSyntax
 }
 
 // PSISectionHeader represents a PSI section header
@@ -65,16 +97,40 @@ type PSISectionSyntaxHeader struct {
 
 // PSISectionSyntaxData represents a PSI section syntax data
 type PSISectionSyntaxData struct {
-	EIT *EITData
-	NIT *NITData
-	PAT *PATData
-	PMT *PMTData
-	SDT *SDTData
-	TOT *TOTData
+	AIT     *AITData
+	ATSCEIT *ATSCEITData
+	BAT     *BATData
+	BIT     *BITData
+	CAT     *CATData
+	CVCT    *VCTData
+	DIT     *DITData
+	DSMCC   *DSMCCData
+	EIT     *EITData
+	ETT     *ETTData
+	LDT     *LDTData
+	MGT     *MGTData
+	MPE     *MPEData
+	MPEFEC  *MPEFECData
+	NBIT    *NBITData
+	NIT     *NITData
+	PAT     *PATData
+	PMT     *PMTData
+	RRT     *RRTData
+	RST     *RSTData
+	SCTE35  *SCTE35Data
+	SDT     *SDTData
+	SIT     *SITData
+	ST      *STData
+	STT     *STTData
+	TDT     *TDTData
+	TOT     *TOTData
+	TSDT    *TSDTData
+	TVCT    *VCTData
 }
 
 // parsePSIData parses a PSI data
-func parsePSIData(i []byte) (d *PSIData, err error) {
+// The CRC mode defines how sections with an invalid CRC32 are processed
+func parsePSIData(i []byte, pid uint16, crcMode string) (d *PSIData, err error) {
 	// Init data
 	d = &PSIData{}
 	var offset int
@@ -90,9 +146,11 @@ func parsePSIData(i []byte) (d *PSIData, err error) {
 	var s *PSISection
 	var stop bool
 	for offset < len(i) && !stop {
-		if s, stop, err = parsePSISection(i, &offset); err != nil {
+		if s, stop, err = parsePSISection(i, &offset, pid, crcMode); err != nil {
 			err = errors.Wrap(err, "astits: parsing PSI table failed")
 			return
+		} else if s.InvalidCRC32 && crcMode == CRCModeDrop {
+			continue
 		}
 		d.Sections = append(d.Sections, s)
 	}
@@ -100,20 +158,31 @@ func parsePSIData(i []byte) (d *PSIData, err error) {
 }
 
 // parsePSISection parses a PSI section
-func parsePSISection(i []byte, offset *int) (s *PSISection, stop bool, err error) {
+func parsePSISection(i []byte, offset *int, pid uint16, crcMode string) (s *PSISection, stop bool, err error) {
 	// Init section
 	s = &PSISection{}
 
 	// Parse header
 	var offsetStart, offsetSectionsEnd, offsetEnd int
-	s.Header, offsetStart, _, offsetSectionsEnd, offsetEnd = parsePSISectionHeader(i, offset)
+	s.Header, offsetStart, _, offsetSectionsEnd, offsetEnd = parsePSISectionHeader(i, offset, pid)
 
 	// Check whether we need to stop the parsing
 	if shouldStopPSIParsing(s.Header.TableType) {
+		// Sections of unknown tables are kept raw as long as they're complete, otherwise they're most likely garbage
+		if s.Header.TableType == PSITableTypeUnknown && s.Header.SectionLength > 0 && offsetEnd <= len(i) {
+			s.RawSection = i[offsetStart:offsetEnd]
+			*offset = offsetEnd
+			return
+		}
 		stop = true
 		return
 	}
 
+	// Raw section
+	if offsetEnd <= len(i) {
+		s.RawSection = i[offsetStart:offsetEnd]
+	}
+
 	// Check whether there's a syntax section
 	if s.Header.SectionLength > 0 {
 		// Parse syntax
@@ -128,6 +197,10 @@ func parsePSISection(i []byte, offset *int) (s *PSISection, stop bool, err error
 			// Check CRC32
 			var c = computeCRC32(i[offsetStart:offsetSectionsEnd])
 			if c != s.CRC32 {
+				if crcMode == CRCModeDrop || crcMode == CRCModeFlag {
+					s.InvalidCRC32 = true
+					return
+				}
 				err = fmt.Errorf("astits: Table CRC32 %x != computed CRC32 %x", s.CRC32, c)
 				return
 			}
@@ -164,7 +237,7 @@ func shouldStopPSIParsing(tableType string) bool {
 }
 
 // parsePSISectionHeader parses a PSI section header
-func parsePSISectionHeader(i []byte, offset *int) (h *PSISectionHeader, offsetStart, offsetSectionsStart, offsetSectionsEnd, offsetEnd int) {
+func parsePSISectionHeader(i []byte, offset *int, pid uint16) (h *PSISectionHeader, offsetStart, offsetSectionsStart, offsetSectionsEnd, offsetEnd int) {
 	// Init
 	h = &PSISectionHeader{}
 	offsetStart = *offset
@@ -174,10 +247,10 @@ func parsePSISectionHeader(i []byte, offset *int) (h *PSISectionHeader, offsetSt
 	*offset += 1
 
 	// Table type
-	h.TableType = psiTableType(h.TableID)
+	h.TableType = psiTableTypeOnPID(h.TableID, pid)
 
 	// Check whether we need to stop the parsing
-	if shouldStopPSIParsing(h.TableType) {
+	if h.TableType == PSITableTypeNull || *offset+2 > len(i) {
 		return
 	}
 
@@ -203,24 +276,77 @@ func parsePSISectionHeader(i []byte, offset *int) (h *PSISectionHeader, offsetSt
 
 // hasCRC32 checks whether the table has a CRC32
 func hasCRC32(tableType string) bool {
-	return tableType == PSITableTypePAT ||
+	return tableType == PSITableTypeAIT ||
+		tableType == PSITableTypeATSCEIT ||
+		tableType == PSITableTypeBAT ||
+		tableType == PSITableTypeBIT ||
+		tableType == PSITableTypeCAT ||
+		tableType == PSITableTypeCVCT ||
+		tableType == PSITableTypeDSMCC ||
+		tableType == PSITableTypePAT ||
 		tableType == PSITableTypePMT ||
 		tableType == PSITableTypeEIT ||
+		tableType == PSITableTypeETT ||
+		tableType == PSITableTypeLDT ||
+		tableType == PSITableTypeMGT ||
+		tableType == PSITableTypeMPE ||
+		tableType == PSITableTypeMPEFEC ||
+		tableType == PSITableTypeNBIT ||
 		tableType == PSITableTypeNIT ||
 		tableType == PSITableTypeTOT ||
-		tableType == PSITableTypeSDT
+		tableType == PSITableTypeRRT ||
+		tableType == PSITableTypeSCTE35 ||
+		tableType == PSITableTypeSDT ||
+		tableType == PSITableTypeSIT ||
+		tableType == PSITableTypeSTT ||
+		tableType == PSITableTypeTSDT ||
+		tableType == PSITableTypeTVCT
+}
+
+// psiTableTypeOnPID returns the psi table type based on the table id and the PID carrying it, since some table ids
+// have different meanings depending on the standard
+func psiTableTypeOnPID(tableID int, pid uint16) string {
+	// ISDB LDT shares its table id with the ATSC MGT
+	if tableID == 0xc7 && pid == 0x25 {
+		return PSITableTypeLDT
+	}
+	return psiTableType(tableID)
 }
 
 // psiTableType returns the psi table type based on the table id
 // Page: 28 | https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+// SCTE-35 table ID: Chapter: 9.6 | ANSI/SCTE 35
+// ATSC PSIP table IDs: Page: 23 | Chapter: 6 | https://www.atsc.org/wp-content/uploads/2015/03/Program-System-Information-Protocol-for-Terrestrial-Broadcast-and-Cable.pdf
 func psiTableType(tableID int) string {
 	switch {
+	case tableID == 0x74:
+		return PSITableTypeAIT
+	case tableID == 0xcb:
+		return PSITableTypeATSCEIT
 	case tableID == 0x4a:
 		return PSITableTypeBAT
+	case tableID == 0xc4:
+		return PSITableTypeBIT
+	case tableID == 1:
+		return PSITableTypeCAT
+	case tableID == 0xc9:
+		return PSITableTypeCVCT
+	case tableID == 0x3b, tableID == 0x3c:
+		return PSITableTypeDSMCC
 	case tableID >= 0x4e && tableID <= 0x6f:
 		return PSITableTypeEIT
+	case tableID == 0xcc:
+		return PSITableTypeETT
 	case tableID == 0x7e:
 		return PSITableTypeDIT
+	case tableID == 0xc7:
+		return PSITableTypeMGT
+	case tableID == 0x3e:
+		return PSITableTypeMPE
+	case tableID == 0x78:
+		return PSITableTypeMPEFEC
+	case tableID == 0xc5, tableID == 0xc6:
+		return PSITableTypeNBIT
 	case tableID == 0x40, tableID == 0x41:
 		return PSITableTypeNIT
 	case tableID == 0xff:
@@ -229,21 +355,29 @@ func psiTableType(tableID int) string {
 		return PSITableTypePAT
 	case tableID == 2:
 		return PSITableTypePMT
+	case tableID == 0xca:
+		return PSITableTypeRRT
 	case tableID == 0x71:
 		return PSITableTypeRST
+	case tableID == 0xfc:
+		return PSITableTypeSCTE35
 	case tableID == 0x42, tableID == 0x46:
 		return PSITableTypeSDT
 	case tableID == 0x7f:
 		return PSITableTypeSIT
 	case tableID == 0x72:
 		return PSITableTypeST
+	case tableID == 0xcd:
+		return PSITableTypeSTT
 	case tableID == 0x70:
 		return PSITableTypeTDT
 	case tableID == 0x73:
 		return PSITableTypeTOT
+	case tableID == 3:
+		return PSITableTypeTSDT
+	case tableID == 0xc8:
+		return PSITableTypeTVCT
 	}
-	// TODO Remove this log
-	astilog.Debugf("astits: unlisted PSI table ID %d", tableID)
 	return PSITableTypeUnknown
 }
 
@@ -264,11 +398,29 @@ func parsePSISectionSyntax(i []byte, offset *int, h *PSISectionHeader, offsetSec
 
 // hasPSISyntaxHeader checks whether the section has a syntax header
 func hasPSISyntaxHeader(tableType string) bool {
-	return tableType == PSITableTypeEIT ||
+	return tableType == PSITableTypeAIT ||
+		tableType == PSITableTypeATSCEIT ||
+		tableType == PSITableTypeBAT ||
+		tableType == PSITableTypeBIT ||
+		tableType == PSITableTypeCAT ||
+		tableType == PSITableTypeCVCT ||
+		tableType == PSITableTypeDSMCC ||
+		tableType == PSITableTypeEIT ||
+		tableType == PSITableTypeETT ||
+		tableType == PSITableTypeLDT ||
+		tableType == PSITableTypeMGT ||
+		tableType == PSITableTypeMPE ||
+		tableType == PSITableTypeMPEFEC ||
+		tableType == PSITableTypeNBIT ||
 		tableType == PSITableTypeNIT ||
 		tableType == PSITableTypePAT ||
 		tableType == PSITableTypePMT ||
-		tableType == PSITableTypeSDT
+		tableType == PSITableTypeRRT ||
+		tableType == PSITableTypeSDT ||
+		tableType == PSITableTypeSIT ||
+		tableType == PSITableTypeSTT ||
+		tableType == PSITableTypeTSDT ||
+		tableType == PSITableTypeTVCT
 }
 
 // parsePSISectionSyntaxHeader parses a PSI section syntax header
@@ -304,30 +456,64 @@ func parsePSISectionSyntaxData(i []byte, offset *int, h *PSISectionHeader, sh *P
 
 	// Switch on table type
 	switch h.TableType {
+	case PSITableTypeAIT:
+		d.AIT = parseAITSection(i, offset, offsetSectionsEnd, sh.TableIDExtension)
+	case PSITableTypeATSCEIT:
+		d.ATSCEIT = parseATSCEITSection(i, offset, sh.TableIDExtension)
 	case PSITableTypeBAT:
-		// TODO Parse BAT
+		d.BAT = parseBATSection(i, offset, sh.TableIDExtension)
+	case PSITableTypeBIT:
+		d.BIT = parseBITSection(i, offset, offsetSectionsEnd, sh.TableIDExtension)
+	case PSITableTypeCAT:
+		d.CAT = parseCATSection(i, offset, offsetSectionsEnd)
+	case PSITableTypeCVCT:
+		d.CVCT = parseVCTSection(i, offset, sh.TableIDExtension, true)
 	case PSITableTypeDIT:
-		// TODO Parse DIT
+		d.DIT = parseDITSection(i, offset)
+	case PSITableTypeDSMCC:
+		d.DSMCC = parseDSMCCSection(i, offset, offsetSectionsEnd)
 	case PSITableTypeEIT:
-		d.EIT = parseEITSection(i, offset, offsetSectionsEnd, sh.TableIDExtension)
+		d.EIT = parseEITSection(i, offset, offsetSectionsEnd, uint8(h.TableID), sh)
+	case PSITableTypeETT:
+		d.ETT = parseETTSection(i, offset, offsetSectionsEnd, sh.TableIDExtension)
+	case PSITableTypeLDT:
+		d.LDT = parseLDTSection(i, offset, offsetSectionsEnd, sh.TableIDExtension)
+	case PSITableTypeMGT:
+		d.MGT = parseMGTSection(i, offset)
+	case PSITableTypeMPE:
+		d.MPE = parseMPESection(i, offset, offsetSectionsEnd, sh)
+	case PSITableTypeMPEFEC:
+		d.MPEFEC = parseMPEFECSection(i, offset, offsetSectionsEnd, sh)
+	case PSITableTypeNBIT:
+		d.NBIT = parseNBITSection(i, offset, offsetSectionsEnd, sh.TableIDExtension)
 	case PSITableTypeNIT:
 		d.NIT = parseNITSection(i, offset, sh.TableIDExtension)
 	case PSITableTypePAT:
 		d.PAT = parsePATSection(i, offset, offsetSectionsEnd, sh.TableIDExtension)
 	case PSITableTypePMT:
 		d.PMT = parsePMTSection(i, offset, offsetSectionsEnd, sh.TableIDExtension)
+	case PSITableTypeRRT:
+		d.RRT = parseRRTSection(i, offset, sh.TableIDExtension)
 	case PSITableTypeRST:
-		// TODO Parse RST
+		d.RST = parseRSTSection(i, offset, offsetSectionsEnd)
+	case PSITableTypeSCTE35:
+		d.SCTE35 = parseSCTE35Section(i, offset, offsetSectionsEnd)
 	case PSITableTypeSDT:
 		d.SDT = parseSDTSection(i, offset, offsetSectionsEnd, sh.TableIDExtension)
 	case PSITableTypeSIT:
-		// TODO Parse SIT
+		d.SIT = parseSITSection(i, offset, offsetSectionsEnd)
 	case PSITableTypeST:
-		// TODO Parse ST
+		d.ST = parseSTSection(i, offset, offsetSectionsEnd)
+	case PSITableTypeSTT:
+		d.STT = parseSTTSection(i, offset, offsetSectionsEnd)
 	case PSITableTypeTOT:
 		d.TOT = parseTOTSection(i, offset)
 	case PSITableTypeTDT:
-		// TODO Parse TDT
+		d.TDT = parseTDTSection(i, offset)
+	case PSITableTypeTSDT:
+		d.TSDT = parseTSDTSection(i, offset, offsetSectionsEnd)
+	case PSITableTypeTVCT:
+		d.TVCT = parseVCTSection(i, offset, sh.TableIDExtension, false)
 	}
 	return
 }
@@ -336,21 +522,180 @@ func parsePSISectionSyntaxData(i []byte, offset *int, h *PSISectionHeader, sh *P
 func (d *PSIData) toData(firstPacket *Packet, pid uint16) (ds []*Data) {
 	// Loop through sections
 	for _, s := range d.Sections {
+		// Empty sections of known tables have no data
+		if s.Syntax == nil && s.Header.TableType != PSITableTypeUnknown {
+			continue
+		}
+
 		// Switch on table type
+		var l = len(ds)
 		switch s.Header.TableType {
+		case PSITableTypeAIT:
+			ds = append(ds, &Data{AIT: s.Syntax.Data.AIT, FirstPacket: firstPacket, PID: pid})
+		case PSITableTypeATSCEIT:
+			ds = append(ds, &Data{ATSCEIT: s.Syntax.Data.ATSCEIT, FirstPacket: firstPacket, PID: pid})
+		case PSITableTypeBAT:
+			ds = append(ds, &Data{BAT: s.Syntax.Data.BAT, FirstPacket: firstPacket, PID: pid})
+		case PSITableTypeBIT:
+			ds = append(ds, &Data{BIT: s.Syntax.Data.BIT, FirstPacket: firstPacket, PID: pid})
+		case PSITableTypeCAT:
+			ds = append(ds, &Data{CAT: s.Syntax.Data.CAT, FirstPacket: firstPacket, PID: pid})
+		case PSITableTypeCVCT:
+			ds = append(ds, &Data{CVCT: s.Syntax.Data.CVCT, FirstPacket: firstPacket, PID: pid})
+		case PSITableTypeDIT:
+			ds = append(ds, &Data{DIT: s.Syntax.Data.DIT, FirstPacket: firstPacket, PID: pid})
+		case PSITableTypeDSMCC:
+			ds = append(ds, &Data{DSMCC: s.Syntax.Data.DSMCC, FirstPacket: firstPacket, PID: pid})
 		case PSITableTypeEIT:
 			ds = append(ds, &Data{EIT: s.Syntax.Data.EIT, FirstPacket: firstPacket, PID: pid})
+		case PSITableTypeETT:
+			ds = append(ds, &Data{ETT: s.Syntax.Data.ETT, FirstPacket: firstPacket, PID: pid})
+		case PSITableTypeLDT:
+			ds = append(ds, &Data{FirstPacket: firstPacket, LDT: s.Syntax.Data.LDT, PID: pid})
+		case PSITableTypeMGT:
+			ds = append(ds, &Data{FirstPacket: firstPacket, MGT: s.Syntax.Data.MGT, PID: pid})
+		case PSITableTypeMPE:
+			ds = append(ds, &Data{FirstPacket: firstPacket, MPE: s.Syntax.Data.MPE, PID: pid})
+		case PSITableTypeMPEFEC:
+			ds = append(ds, &Data{FirstPacket: firstPacket, MPEFEC: s.Syntax.Data.MPEFEC, PID: pid})
+		case PSITableTypeNBIT:
+			ds = append(ds, &Data{FirstPacket: firstPacket, NBIT: s.Syntax.Data.NBIT, PID: pid})
 		case PSITableTypeNIT:
 			ds = append(ds, &Data{FirstPacket: firstPacket, NIT: s.Syntax.Data.NIT, PID: pid})
 		case PSITableTypePAT:
 			ds = append(ds, &Data{FirstPacket: firstPacket, PAT: s.Syntax.Data.PAT, PID: pid})
 		case PSITableTypePMT:
 			ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid, PMT: s.Syntax.Data.PMT})
+		case PSITableTypeRRT:
+			ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid, RRT: s.Syntax.Data.RRT})
+		case PSITableTypeRST:
+			ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid, RST: s.Syntax.Data.RST})
+		case PSITableTypeSCTE35:
+			ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid, SCTE35: s.Syntax.Data.SCTE35})
 		case PSITableTypeSDT:
 			ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid, SDT: s.Syntax.Data.SDT})
+		case PSITableTypeSIT:
+			ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid, SIT: s.Syntax.Data.SIT})
+		case PSITableTypeST:
+			ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid, ST: s.Syntax.Data.ST})
+		case PSITableTypeSTT:
+			ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid, STT: s.Syntax.Data.STT})
+		case PSITableTypeTDT:
+			ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid, TDT: s.Syntax.Data.TDT})
 		case PSITableTypeTOT:
 			ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid, TOT: s.Syntax.Data.TOT})
+		case PSITableTypeTSDT:
+			ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid, TSDT: s.Syntax.Data.TSDT})
+		case PSITableTypeTVCT:
+			ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid, TVCT: s.Syntax.Data.TVCT})
+		case PSITableTypeUnknown:
+			if s.RawSection != nil {
+				ds = append(ds, &Data{FirstPacket: firstPacket, PID: pid})
+			}
+		}
+
+		// Raw section
+		if len(ds) > l {
+			ds[len(ds)-1].InvalidCRC32 = s.InvalidCRC32
+			ds[len(ds)-1].IsNext = s.Syntax != nil && s.Syntax.Header != nil && !s.Syntax.Header.CurrentNextIndicator
+			ds[len(ds)-1].RawSection = s.RawSection
 		}
 	}
 	return
 }
+
+// writePSISection serializes a PSI section with a syntax header and appends its CRC32
+// Sections don't start with a pointer field, and the Serialize methods built on top of them flag them as currently
+// applicable
+func writePSISection(h *PSISectionHeader, sh *PSISectionSyntaxHeader, data []byte) (b []byte) {
+	// Table ID
+	b = append(b, uint8(h.TableID))
+
+	// Section syntax indicator, private bit and section length
+	var l = 5 + len(data) + 4
+	var flags = uint8(0x30)
+	if h.SectionSyntaxIndicator {
+		flags |= 0x80
+	}
+	if h.PrivateBit {
+		flags |= 0x40
+	}
+	b = append(b, flags|uint8(l>>8)&0xf, uint8(l))
+
+	// Syntax header
+	b = append(b, writePSISectionSyntaxHeader(sh)...)
+
+	// Data
+	b = append(b, data...)
+
+	// CRC32
+	var c = computeCRC32(b)
+	b = append(b, uint8(c>>24), uint8(c>>16), uint8(c>>8), uint8(c))
+	return
+}
+
+// writePSISections serializes a table into as many sections as needed so that none exceeds the maximum section length
+// The fixed part is repeated at the beginning of each section whereas items are never split across sections.
+// Section numbers of the syntax header are computed
+func writePSISections(h *PSISectionHeader, sh *PSISectionSyntaxHeader, fixed []byte, items [][]byte) (ss [][]byte, err error) {
+	// Split items
+	var ds [][]byte
+	if ds, err = splitPSIItems(len(fixed), items); err != nil {
+		err = errors.Wrap(err, "astits: splitting items failed")
+		return
+	}
+
+	// Check number of sections
+	if len(ds) > psiSectionMaximumNumber {
+		err = fmt.Errorf("astits: %d sections are needed which is more than %d", len(ds), psiSectionMaximumNumber)
+		return
+	}
+
+	// Write sections
+	var c = *sh
+	c.LastSectionNumber = uint8(len(ds) - 1)
+	for idx, d := range ds {
+		c.SectionNumber = uint8(idx)
+		ss = append(ss, writePSISection(h, &c, append(append([]byte{}, fixed...), d...)))
+	}
+	return
+}
+
+// splitPSIItems groups items so that each group fits in a section along with a fixed part of the provided length
+// There's always at least one group, even if it's empty
+func splitPSIItems(fixedLength int, items [][]byte) (gs [][]byte, err error) {
+	var max = psiSectionMaximumLength - psiSectionSyntaxHeaderLength - 4 - fixedLength
+	gs = [][]byte{{}}
+	for _, item := range items {
+		// Item doesn't fit in a section
+		if len(item) > max {
+			err = fmt.Errorf("astits: item of %d bytes doesn't fit in a section", len(item))
+			return
+		}
+
+		// Item doesn't fit in the current group
+		if len(gs[len(gs)-1])+len(item) > max {
+			gs = append(gs, []byte{})
+		}
+		gs[len(gs)-1] = append(gs[len(gs)-1], item...)
+	}
+	return
+}
+
+// writePSIPayload serializes sections into a payload starting with a pointer field, ready to be split into packets
+func writePSIPayload(ss [][]byte) (b []byte) {
+	b = []byte{0x0}
+	for _, s := range ss {
+		b = append(b, s...)
+	}
+	return
+}
+
+// writePSISectionSyntaxHeader serializes a PSI section syntax header
+func writePSISectionSyntaxHeader(h *PSISectionSyntaxHeader) []byte {
+	var b = uint8(0xc0) | h.VersionNumber&0x1f<<1
+	if h.CurrentNextIndicator {
+		b |= 0x1
+	}
+	return []byte{uint8(h.TableIDExtension >> 8), uint8(h.TableIDExtension), b, h.SectionNumber, h.LastSectionNumber}
+}
@@ -97,6 +97,13 @@ var psi = &PSIData{
 	},
 }
 
+func init() {
+	// Raw sections
+	for idx, s := range splitPSISections(psiBytes()) {
+		psi.Sections[idx].RawSection = s
+	}
+}
+
 func psiBytes() []byte {
 	w := astibinary.New()
 	w.Write(uint8(4))                      // Pointer field
@@ -164,11 +171,22 @@ func TestParsePSIData(t *testing.T) {
 	w.Write("000000001110") // TOT section length
 	w.Write(totBytes())     // TOT data
 	w.Write(uint32(32))     // TOT CRC32
-	_, err := parsePSIData(w.Bytes())
+	_, err := parsePSIData(w.Bytes(), PIDPAT, CRCModeError)
 	assert.EqualError(t, err, "astits: parsing PSI table failed: astits: Table CRC32 20 != computed CRC32 6969b13")
+	d, err := parsePSIData(w.Bytes(), PIDPAT, CRCModeDrop)
+	assert.NoError(t, err)
+	assert.Empty(t, d.Sections)
+	d, err = parsePSIData(w.Bytes(), PIDPAT, CRCModeFlag)
+	assert.NoError(t, err)
+	assert.Len(t, d.Sections, 1)
+	assert.True(t, d.Sections[0].InvalidCRC32)
+	assert.Equal(t, uint32(32), d.Sections[0].CRC32)
+	ds := d.toData(&Packet{}, PIDPAT)
+	assert.Len(t, ds, 1)
+	assert.True(t, ds[0].InvalidCRC32)
 
 	// Valid
-	d, err := parsePSIData(psiBytes())
+	d, err = parsePSIData(psiBytes(), PIDPAT, CRCModeError)
 	assert.NoError(t, err)
 	assert.Equal(t, d, psi)
 }
@@ -198,7 +216,7 @@ func TestParsePSISectionHeader(t *testing.T) {
 	w.Write("1")        // Syntax section indicator
 	w.Write("0000000")  // Finish the byte
 	var offset int
-	d, _, _, _, _ := parsePSISectionHeader(w.Bytes(), &offset)
+	d, _, _, _, _ := parsePSISectionHeader(w.Bytes(), &offset, PIDPAT)
 	assert.Equal(t, d, &PSISectionHeader{
 		TableID:   254,
 		TableType: PSITableTypeUnknown,
@@ -206,7 +224,7 @@ func TestParsePSISectionHeader(t *testing.T) {
 
 	// Valid table type
 	offset = 0
-	d, offsetStart, offsetSectionsStart, offsetSectionsEnd, offsetEnd := parsePSISectionHeader(psiSectionHeaderBytes(), &offset)
+	d, offsetStart, offsetSectionsStart, offsetSectionsEnd, offsetEnd := parsePSISectionHeader(psiSectionHeaderBytes(), &offset, PIDPAT)
 	assert.Equal(t, d, psiSectionHeader)
 	assert.Equal(t, 0, offsetStart)
 	assert.Equal(t, 3, offsetSectionsStart)
@@ -215,24 +233,47 @@ func TestParsePSISectionHeader(t *testing.T) {
 }
 
 func TestPSITableType(t *testing.T) {
+	assert.Equal(t, PSITableTypeAIT, psiTableType(0x74))
+	assert.Equal(t, PSITableTypeATSCEIT, psiTableType(0xcb))
 	assert.Equal(t, PSITableTypeBAT, psiTableType(74))
+	assert.Equal(t, PSITableTypeBIT, psiTableType(0xc4))
 	for i := 78; i <= 111; i++ {
 		assert.Equal(t, PSITableTypeEIT, psiTableType(i))
 	}
+	assert.Equal(t, PSITableTypeCVCT, psiTableType(0xc9))
 	assert.Equal(t, PSITableTypeDIT, psiTableType(126))
+	assert.Equal(t, PSITableTypeDSMCC, psiTableType(0x3b))
+	assert.Equal(t, PSITableTypeDSMCC, psiTableType(0x3c))
+	assert.Equal(t, PSITableTypeETT, psiTableType(0xcc))
+	assert.Equal(t, PSITableTypeMPE, psiTableType(0x3e))
+	assert.Equal(t, PSITableTypeMPEFEC, psiTableType(0x78))
+	assert.Equal(t, PSITableTypeMGT, psiTableType(0xc7))
+	for i := 0xc5; i <= 0xc6; i++ {
+		assert.Equal(t, PSITableTypeNBIT, psiTableType(i))
+	}
 	for i := 64; i <= 65; i++ {
 		assert.Equal(t, PSITableTypeNIT, psiTableType(i))
 	}
 	assert.Equal(t, PSITableTypeNull, psiTableType(255))
 	assert.Equal(t, PSITableTypePAT, psiTableType(0))
 	assert.Equal(t, PSITableTypePMT, psiTableType(2))
+	assert.Equal(t, PSITableTypeRRT, psiTableType(0xca))
 	assert.Equal(t, PSITableTypeRST, psiTableType(113))
+	assert.Equal(t, PSITableTypeSCTE35, psiTableType(0xfc))
 	assert.Equal(t, PSITableTypeSDT, psiTableType(66))
 	assert.Equal(t, PSITableTypeSDT, psiTableType(70))
 	assert.Equal(t, PSITableTypeSIT, psiTableType(127))
 	assert.Equal(t, PSITableTypeST, psiTableType(114))
+	assert.Equal(t, PSITableTypeSTT, psiTableType(0xcd))
 	assert.Equal(t, PSITableTypeTDT, psiTableType(112))
 	assert.Equal(t, PSITableTypeTOT, psiTableType(115))
+	assert.Equal(t, PSITableTypeTVCT, psiTableType(0xc8))
+}
+
+func TestPSITableTypeOnPID(t *testing.T) {
+	assert.Equal(t, PSITableTypeMGT, psiTableTypeOnPID(0xc7, PIDATSCBase))
+	assert.Equal(t, PSITableTypeLDT, psiTableTypeOnPID(0xc7, 0x25))
+	assert.Equal(t, PSITableTypePAT, psiTableTypeOnPID(0, 0x25))
 }
 
 var psiSectionSyntaxHeader = &PSISectionSyntaxHeader{
@@ -262,11 +303,61 @@ func TestParsePSISectionSyntaxHeader(t *testing.T) {
 func TestPSIToData(t *testing.T) {
 	p := &Packet{}
 	assert.Equal(t, []*Data{
-		{EIT: eit, FirstPacket: p, PID: 2},
-		{FirstPacket: p, NIT: nit, PID: 2},
-		{FirstPacket: p, PAT: pat, PID: 2},
-		{FirstPacket: p, PMT: pmt, PID: 2},
-		{FirstPacket: p, SDT: sdt, PID: 2},
-		{FirstPacket: p, TOT: tot, PID: 2},
+		{EIT: eit, FirstPacket: p, PID: 2, RawSection: psi.Sections[0].RawSection},
+		{FirstPacket: p, NIT: nit, PID: 2, RawSection: psi.Sections[1].RawSection},
+		{FirstPacket: p, PAT: pat, PID: 2, RawSection: psi.Sections[2].RawSection},
+		{FirstPacket: p, PMT: pmt, PID: 2, RawSection: psi.Sections[3].RawSection},
+		{FirstPacket: p, PID: 2, RawSection: psi.Sections[4].RawSection, SDT: sdt},
+		{FirstPacket: p, PID: 2, RawSection: psi.Sections[5].RawSection, TOT: tot},
 	}, psi.toData(p, uint16(2)))
+
+	// Unknown table
+	w := astibinary.New()
+	w.Write(uint8(0))       // Pointer field
+	w.Write(uint8(0x80))    // Unknown table ID
+	w.Write("0")            // Syntax section indicator
+	w.Write("0")            // Private bit
+	w.Write("11")           // Reserved
+	w.Write("000000000010") // Section length
+	w.Write(uint16(0x1234)) // Data
+	w.Write(uint8(0xff))    // Stuffing
+	d, err := parsePSIData(w.Bytes(), 0x100, CRCModeError)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Data{{FirstPacket: p, PID: 0x100, RawSection: []byte{0x80, 0x30, 0x2, 0x12, 0x34}}}, d.toData(p, 0x100))
+}
+
+func TestWritePSISection(t *testing.T) {
+	d, err := parsePSIData(append([]byte{0x0}, writePSISection(&PSISectionHeader{PrivateBit: true, SectionSyntaxIndicator: true, TableID: 0}, psiSectionSyntaxHeader, patBytes())...), PIDPAT, CRCModeError)
+	assert.NoError(t, err)
+	assert.Equal(t, psi.Sections[2], d.Sections[0])
+}
+
+func TestWritePSISectionSyntaxHeader(t *testing.T) {
+	assert.Equal(t, psiSectionSyntaxHeaderBytes(), writePSISectionSyntaxHeader(psiSectionSyntaxHeader))
+}
+
+func TestWritePSISections(t *testing.T) {
+	// Items are split
+	var items [][]byte
+	var ps []*PATProgram
+	for idx := 0; idx < 300; idx++ {
+		p := &PATProgram{ProgramMapID: uint16(idx + 0x100), ProgramNumber: uint16(idx + 1)}
+		items = append(items, writePATSection(&PATData{Programs: []*PATProgram{p}}))
+		ps = append(ps, p)
+	}
+	ss, err := writePSISections(&PSISectionHeader{SectionSyntaxIndicator: true, TableID: 0}, &PSISectionSyntaxHeader{CurrentNextIndicator: true, TableIDExtension: 1}, nil, items)
+	assert.NoError(t, err)
+	assert.Len(t, ss, 2)
+	d, err := parsePSIData(writePSIPayload(ss), PIDPAT, CRCModeError)
+	assert.NoError(t, err)
+	assert.Len(t, d.Sections, 2)
+	assert.Equal(t, uint8(0), d.Sections[0].Syntax.Header.SectionNumber)
+	assert.Equal(t, uint8(1), d.Sections[0].Syntax.Header.LastSectionNumber)
+	assert.Equal(t, uint8(1), d.Sections[1].Syntax.Header.SectionNumber)
+	assert.Equal(t, uint8(1), d.Sections[1].Syntax.Header.LastSectionNumber)
+	assert.Equal(t, ps, append(d.Sections[0].Syntax.Data.PAT.Programs, d.Sections[1].Syntax.Data.PAT.Programs...))
+
+	// Item is too big
+	_, err = writePSISections(&PSISectionHeader{}, &PSISectionSyntaxHeader{}, []byte("fixed"), [][]byte{make([]byte, 1010)})
+	assert.Error(t, err)
 }
@@ -0,0 +1,75 @@
+package astits
+
+// RRTData represents a RRT data
+// Page: 41 | Chapter: 6.4 | Link: https://www.atsc.org/wp-content/uploads/2015/03/Program-System-Information-Protocol-for-Terrestrial-Broadcast-and-Cable.pdf
+type RRTData struct {
+	Descriptors      []*Descriptor
+	Dimensions       []*RRTDataDimension
+	ProtocolVersion  uint8
+	RatingRegion     uint8
+	RatingRegionName *ATSCMultipleString
+}
+
+// RRTDataDimension represents a RRT data dimension
+type RRTDataDimension struct {
+	GraduatedScale bool
+	Name           *ATSCMultipleString
+	Values         []*RRTDataValue
+}
+
+// RRTDataValue represents a RRT data value
+type RRTDataValue struct {
+	Abbreviation *ATSCMultipleString
+	Text         *ATSCMultipleString
+}
+
+// parseRRTSection parses a RRT section
+func parseRRTSection(i []byte, offset *int, tableIDExtension uint16) (d *RRTData) {
+	// Init
+	d = &RRTData{RatingRegion: uint8(tableIDExtension)}
+
+	// Protocol version
+	d.ProtocolVersion = uint8(i[*offset])
+	*offset += 1
+
+	// Rating region name
+	d.RatingRegionName = parseATSCMultipleStringWithLength(i, offset)
+
+	// Dimensions defined
+	var dimensionsDefined = int(i[*offset])
+	*offset += 1
+
+	// Loop through dimensions
+	for idxDimension := 0; idxDimension < dimensionsDefined; idxDimension++ {
+		// Name
+		var dm = &RRTDataDimension{}
+		dm.Name = parseATSCMultipleStringWithLength(i, offset)
+
+		// Graduated scale
+		dm.GraduatedScale = i[*offset]&0x10 > 0
+
+		// Values defined
+		var valuesDefined = int(i[*offset] & 0xf)
+		*offset += 1
+
+		// Loop through values
+		for idxValue := 0; idxValue < valuesDefined; idxValue++ {
+			// Abbreviation
+			var v = &RRTDataValue{}
+			v.Abbreviation = parseATSCMultipleStringWithLength(i, offset)
+
+			// Text
+			v.Text = parseATSCMultipleStringWithLength(i, offset)
+
+			// Append value
+			dm.Values = append(dm.Values, v)
+		}
+
+		// Append dimension
+		d.Dimensions = append(d.Dimensions, dm)
+	}
+
+	// Descriptors
+	d.Descriptors = parseATSCDescriptors(i, offset)
+	return
+}
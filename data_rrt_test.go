@@ -0,0 +1,51 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/asticode/go-astitools/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+var rrt = &RRTData{
+	Dimensions: []*RRTDataDimension{{
+		GraduatedScale: true,
+		Name:           atscMultipleString,
+		Values: []*RRTDataValue{{
+			Abbreviation: atscMultipleString,
+			Text:         atscMultipleString,
+		}},
+	}},
+	RatingRegion:     1,
+	RatingRegionName: atscMultipleString,
+}
+
+func rrtBytes() []byte {
+	w := astibinary.New()
+	m := astibinary.New()
+	atscMultipleStringBytes(m)
+	w.Write(uint8(0))              // Protocol version
+	w.Write(uint8(len(m.Bytes()))) // Rating region name length
+	w.Write(m.Bytes())             // Rating region name
+	w.Write(uint8(1))              // Dimensions defined
+	w.Write(uint8(len(m.Bytes()))) // Dimension #1 name length
+	w.Write(m.Bytes())             // Dimension #1 name
+	w.Write("111")                 // Dimension #1 reserved
+	w.Write("1")                   // Dimension #1 graduated scale
+	w.Write("0001")                // Dimension #1 values defined
+	w.Write(uint8(len(m.Bytes()))) // Dimension #1 value #1 abbreviation length
+	w.Write(m.Bytes())             // Dimension #1 value #1 abbreviation
+	w.Write(uint8(len(m.Bytes()))) // Dimension #1 value #1 text length
+	w.Write(m.Bytes())             // Dimension #1 value #1 text
+	w.Write("111111")              // Reserved
+	w.Write("0000000000")          // Descriptors length
+	return w.Bytes()
+}
+
+func TestParseRRTSection(t *testing.T) {
+	var offset int
+	var b = rrtBytes()
+	d := parseRRTSection(b, &offset, uint16(0xff01))
+	assert.Equal(t, rrt, d)
+	assert.Equal(t, len(b), offset)
+}
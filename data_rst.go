@@ -0,0 +1,50 @@
+package astits
+
+// RSTData represents a RST data
+// Page: 40 | Chapter: 5.2.7 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+type RSTData struct {
+	Events []*RSTDataEvent
+}
+
+// RSTDataEvent represents a RST data event
+type RSTDataEvent struct {
+	EventID           uint16
+	OriginalNetworkID uint16
+	RunningStatus     uint8
+	ServiceID         uint16
+	TransportStreamID uint16
+}
+
+// parseRSTSection parses a RST section
+func parseRSTSection(i []byte, offset *int, offsetSectionsEnd int) (d *RSTData) {
+	// Init
+	d = &RSTData{}
+
+	// Loop until end of section data is reached
+	for *offset+9 <= offsetSectionsEnd {
+		// Transport stream ID
+		var e = &RSTDataEvent{}
+		e.TransportStreamID = uint16(i[*offset])<<8 | uint16(i[*offset+1])
+		*offset += 2
+
+		// Original network ID
+		e.OriginalNetworkID = uint16(i[*offset])<<8 | uint16(i[*offset+1])
+		*offset += 2
+
+		// Service ID
+		e.ServiceID = uint16(i[*offset])<<8 | uint16(i[*offset+1])
+		*offset += 2
+
+		// Event ID
+		e.EventID = uint16(i[*offset])<<8 | uint16(i[*offset+1])
+		*offset += 2
+
+		// Running status
+		e.RunningStatus = uint8(i[*offset] & 0x7)
+		*offset += 1
+
+		// Add event
+		d.Events = append(d.Events, e)
+	}
+	return
+}
@@ -0,0 +1,51 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/asticode/go-astitools/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+var rst = &RSTData{Events: []*RSTDataEvent{{
+	EventID:           4,
+	OriginalNetworkID: 2,
+	RunningStatus:     RunningStatusRunning,
+	ServiceID:         3,
+	TransportStreamID: 1,
+}}}
+
+func rstBytes() []byte {
+	w := astibinary.New()
+	w.Write(uint16(1)) // Event #1 transport stream id
+	w.Write(uint16(2)) // Event #1 original network id
+	w.Write(uint16(3)) // Event #1 service id
+	w.Write(uint16(4)) // Event #1 event id
+	w.Write("11111")   // Event #1 reserved for future use
+	w.Write("100")     // Event #1 running status
+	return w.Bytes()
+}
+
+func TestParseRSTSection(t *testing.T) {
+	var offset int
+	var b = rstBytes()
+	d := parseRSTSection(b, &offset, len(b))
+	assert.Equal(t, rst, d)
+	assert.Equal(t, len(b), offset)
+}
+
+func TestParsePSIDataRST(t *testing.T) {
+	w := astibinary.New()
+	w.Write(uint8(0))       // Pointer field
+	w.Write(uint8(0x71))    // RST table ID
+	w.Write("0")            // RST syntax section indicator
+	w.Write("1")            // RST reserved for future use
+	w.Write("11")           // RST reserved
+	w.Write("000000001001") // RST section length
+	w.Write(rstBytes())     // RST data
+	w.Write(uint8(0xff))    // Stuffing
+	d, err := parsePSIData(w.Bytes(), uint16(0x13), CRCModeError)
+	assert.NoError(t, err)
+	assert.Len(t, d.Sections, 2)
+	assert.Equal(t, rst, d.Sections[0].Syntax.Data.RST)
+}
@@ -0,0 +1,1056 @@
+package astits
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// SCTE-35 encryption algorithms
+// Chapter: 9.8.1 | ANSI/SCTE 35
+const (
+	SCTE35EncryptionAlgorithmDESCBC           = 2
+	SCTE35EncryptionAlgorithmDESECB           = 1
+	SCTE35EncryptionAlgorithmNone             = 0
+	SCTE35EncryptionAlgorithmTripleDESEDE3ECB = 3
+)
+
+// SCTE-35 splice command types
+// Chapter: 9.6 | ANSI/SCTE 35
+const (
+	SCTE35SpliceCommandTypeBandwidthReservation = 0x7
+	SCTE35SpliceCommandTypePrivateCommand       = 0xff
+	SCTE35SpliceCommandTypeSpliceInsert         = 0x5
+	SCTE35SpliceCommandTypeSpliceNull           = 0x0
+	SCTE35SpliceCommandTypeSpliceSchedule       = 0x4
+	SCTE35SpliceCommandTypeTimeSignal           = 0x6
+)
+
+// SCTE-35 splice descriptor tags
+// Chapter: 10.2 | ANSI/SCTE 35
+const (
+	SCTE35SpliceDescriptorTagAudio        = 0x4
+	SCTE35SpliceDescriptorTagAvail        = 0x0
+	SCTE35SpliceDescriptorTagDTMF         = 0x1
+	SCTE35SpliceDescriptorTagSegmentation = 0x2
+	SCTE35SpliceDescriptorTagTime         = 0x3
+)
+
+// SCTE35IdentifierCUEI is the identifier of the splice descriptors defined by SCTE-35
+const SCTE35IdentifierCUEI = 0x43554549
+
+// SCTE-35 segmentation type ids
+// Chapter: 10.3.3.1 | ANSI/SCTE 35
+const (
+	SCTE35SegmentationTypeIDBreakEnd                                 = 0x23
+	SCTE35SegmentationTypeIDBreakStart                               = 0x22
+	SCTE35SegmentationTypeIDChapterEnd                               = 0x21
+	SCTE35SegmentationTypeIDChapterStart                             = 0x20
+	SCTE35SegmentationTypeIDContentIdentification                    = 0x1
+	SCTE35SegmentationTypeIDDistributorAdvertisementEnd              = 0x33
+	SCTE35SegmentationTypeIDDistributorAdvertisementStart            = 0x32
+	SCTE35SegmentationTypeIDDistributorPlacementOpportunityEnd       = 0x37
+	SCTE35SegmentationTypeIDDistributorPlacementOpportunityStart     = 0x36
+	SCTE35SegmentationTypeIDNotIndicated                             = 0x0
+	SCTE35SegmentationTypeIDProgramEnd                               = 0x11
+	SCTE35SegmentationTypeIDProgramStart                             = 0x10
+	SCTE35SegmentationTypeIDProviderAdvertisementEnd                 = 0x31
+	SCTE35SegmentationTypeIDProviderAdvertisementStart               = 0x30
+	SCTE35SegmentationTypeIDProviderPlacementOpportunityEnd          = 0x35
+	SCTE35SegmentationTypeIDProviderPlacementOpportunityStart        = 0x34
+	SCTE35SegmentationTypeIDProviderOverlayPlacementOpportunityEnd   = 0x39
+	SCTE35SegmentationTypeIDProviderOverlayPlacementOpportunityStart = 0x38
+	SCTE35SegmentationTypeIDUnscheduledEventEnd                      = 0x41
+	SCTE35SegmentationTypeIDUnscheduledEventStart                    = 0x40
+)
+
+// scte35SectionMaximumLength is the maximum section length of a SCTE-35 splice info section
+const scte35SectionMaximumLength = 4093
+
+// SCTE35Data represents a SCTE-35 splice info section
+// When the packet is encrypted, the splice command and the descriptors are not parsed and are stored in EncryptedBytes
+// Chapter: 9.6 | ANSI/SCTE 35
+type SCTE35Data struct {
+	CWIndex             uint8
+	Descriptors         []*SCTE35SpliceDescriptor
+	EncryptedBytes      []byte // Splice command, descriptors, alignment stuffing and E_CRC_32 when the packet is encrypted
+	EncryptedPacket     bool
+	EncryptionAlgorithm uint8
+	PrivateCommand      *SCTE35PrivateCommand
+	ProtocolVersion     uint8
+	PTSAdjustment       *ClockReference
+	SpliceCommandType   uint8
+	SpliceInsert        *SCTE35SpliceInsert
+	SpliceSchedule      *SCTE35SpliceSchedule
+	Tier                uint16
+	TimeSignal          *SCTE35TimeSignal
+}
+
+// SCTE35SpliceTime represents a SCTE-35 splice time
+type SCTE35SpliceTime struct {
+	PTSTime *ClockReference // Nil when the time is not specified
+}
+
+// SCTE35BreakDuration represents a SCTE-35 break duration
+type SCTE35BreakDuration struct {
+	AutoReturn bool
+	Duration   *ClockReference
+}
+
+// SCTE35SpliceInsert represents a SCTE-35 splice insert command
+// Chapter: 9.7.3 | ANSI/SCTE 35
+type SCTE35SpliceInsert struct {
+	AvailNum          uint8
+	AvailsExpected    uint8
+	BreakDuration     *SCTE35BreakDuration
+	Components        []*SCTE35SpliceInsertComponent // Only set when the splice is not a program splice
+	OutOfNetwork      bool
+	ProgramSplice     bool
+	SpliceEventCancel bool
+	SpliceEventID     uint32
+	SpliceImmediate   bool
+	SpliceTime        *SCTE35SpliceTime // Only set for program splices that are not immediate
+	UniqueProgramID   uint16
+}
+
+// SCTE35SpliceInsertComponent represents a SCTE-35 splice insert component
+type SCTE35SpliceInsertComponent struct {
+	ComponentTag uint8
+	SpliceTime   *SCTE35SpliceTime // Only set when the splice is not immediate
+}
+
+// SCTE35SpliceSchedule represents a SCTE-35 splice schedule command
+// Chapter: 9.7.2 | ANSI/SCTE 35
+type SCTE35SpliceSchedule struct {
+	Events []*SCTE35SpliceScheduleEvent
+}
+
+// SCTE35SpliceScheduleEvent represents a SCTE-35 splice schedule event
+type SCTE35SpliceScheduleEvent struct {
+	AvailNum          uint8
+	AvailsExpected    uint8
+	BreakDuration     *SCTE35BreakDuration
+	Components        []*SCTE35SpliceScheduleComponent // Only set when the splice is not a program splice
+	OutOfNetwork      bool
+	ProgramSplice     bool
+	SpliceEventCancel bool
+	SpliceEventID     uint32
+	UniqueProgramID   uint16
+	UTCSpliceTime     uint32 // Number of GPS seconds since 00:00:00 UTC, January 6th, 1980
+}
+
+// SCTE35SpliceScheduleComponent represents a SCTE-35 splice schedule component
+type SCTE35SpliceScheduleComponent struct {
+	ComponentTag  uint8
+	UTCSpliceTime uint32 // Number of GPS seconds since 00:00:00 UTC, January 6th, 1980
+}
+
+// SCTE35TimeSignal represents a SCTE-35 time signal command
+// Chapter: 9.7.4 | ANSI/SCTE 35
+type SCTE35TimeSignal struct {
+	SpliceTime *SCTE35SpliceTime
+}
+
+// SCTE35PrivateCommand represents a SCTE-35 private command
+// Chapter: 9.7.6 | ANSI/SCTE 35
+type SCTE35PrivateCommand struct {
+	Identifier   uint32
+	PrivateBytes []byte
+}
+
+// SCTE35SpliceDescriptor represents a SCTE-35 splice descriptor
+// Chapter: 10 | ANSI/SCTE 35
+type SCTE35SpliceDescriptor struct {
+	Audio        *SCTE35SpliceDescriptorAudio
+	Avail        *SCTE35SpliceDescriptorAvail
+	DTMF         *SCTE35SpliceDescriptorDTMF
+	Identifier   uint32
+	Length       uint8
+	PrivateBytes []byte // Set when the identifier is not CUEI or the tag is unknown
+	Segmentation *SCTE35SpliceDescriptorSegmentation
+	Tag          uint8
+	Time         *SCTE35SpliceDescriptorTime
+}
+
+// SCTE35SpliceDescriptorAvail represents a SCTE-35 avail descriptor
+// Chapter: 10.3.1 | ANSI/SCTE 35
+type SCTE35SpliceDescriptorAvail struct {
+	ProviderAvailID uint32
+}
+
+// SCTE35SpliceDescriptorDTMF represents a SCTE-35 DTMF descriptor
+// Chapter: 10.3.2 | ANSI/SCTE 35
+type SCTE35SpliceDescriptorDTMF struct {
+	Chars   []byte
+	Preroll uint8 // In tenths of seconds
+}
+
+// SCTE35SpliceDescriptorSegmentation represents a SCTE-35 segmentation descriptor
+// Chapter: 10.3.3 | ANSI/SCTE 35
+type SCTE35SpliceDescriptorSegmentation struct {
+	ArchiveAllowed          bool
+	Components              []*SCTE35SpliceDescriptorSegmentationComponent // Only set when the segmentation is not a program segmentation
+	DeliveryNotRestricted   bool
+	DeviceRestrictions      uint8
+	NoRegionalBlackout      bool
+	ProgramSegmentation     bool
+	SegmentationDuration    *ClockReference
+	SegmentationEventCancel bool
+	SegmentationEventID     uint32
+	SegmentationTypeID      uint8
+	SegmentationUPID        []byte
+	SegmentationUPIDType    uint8
+	SegmentNum              uint8
+	SegmentsExpected        uint8
+	SubSegmentNum           uint8
+	SubSegmentsExpected     uint8
+	WebDeliveryAllowed      bool
+}
+
+// SCTE35SpliceDescriptorSegmentationComponent represents a SCTE-35 segmentation descriptor component
+type SCTE35SpliceDescriptorSegmentationComponent struct {
+	ComponentTag uint8
+	PTSOffset    *ClockReference
+}
+
+// SCTE35SpliceDescriptorTime represents a SCTE-35 time descriptor
+// Chapter: 10.3.4 | ANSI/SCTE 35
+type SCTE35SpliceDescriptorTime struct {
+	TAINanoseconds uint32
+	TAISeconds     uint64
+	UTCOffset      uint16
+}
+
+// SCTE35SpliceDescriptorAudio represents a SCTE-35 audio descriptor
+// Chapter: 10.3.5 | ANSI/SCTE 35
+type SCTE35SpliceDescriptorAudio struct {
+	Components []*SCTE35SpliceDescriptorAudioComponent
+}
+
+// SCTE35SpliceDescriptorAudioComponent represents a SCTE-35 audio descriptor component
+type SCTE35SpliceDescriptorAudioComponent struct {
+	BitStreamMode      uint8
+	ComponentTag       uint8
+	FullServiceAudio   bool
+	ISO639LanguageCode []byte
+	NumChannels        uint8
+}
+
+// parseSCTE35Section parses a SCTE-35 splice info section
+func parseSCTE35Section(i []byte, offset *int, offsetSectionsEnd int) (d *SCTE35Data) {
+	// Init
+	d = &SCTE35Data{}
+
+	// Protocol version
+	d.ProtocolVersion = uint8(i[*offset])
+	*offset += 1
+
+	// Encrypted packet
+	d.EncryptedPacket = i[*offset]&0x80 > 0
+
+	// Encryption algorithm
+	d.EncryptionAlgorithm = uint8(i[*offset]&0x7e) >> 1
+
+	// PTS adjustment
+	d.PTSAdjustment = parseSCTE35PTS(i[*offset:])
+	*offset += 5
+
+	// CW index
+	d.CWIndex = uint8(i[*offset])
+	*offset += 1
+
+	// Tier
+	d.Tier = uint16(i[*offset])<<4 | uint16(i[*offset+1])>>4
+
+	// Splice command length
+	var spliceCommandLength = int(i[*offset+1]&0xf)<<8 | int(i[*offset+2])
+	*offset += 3
+
+	// Encrypted content can't be parsed
+	if d.EncryptedPacket {
+		d.EncryptedBytes = make([]byte, offsetSectionsEnd-*offset)
+		copy(d.EncryptedBytes, i[*offset:offsetSectionsEnd])
+		*offset = offsetSectionsEnd
+		return
+	}
+
+	// Splice command type
+	d.SpliceCommandType = uint8(i[*offset])
+	*offset += 1
+
+	// Splice command
+	var offsetCommandStart = *offset
+	switch d.SpliceCommandType {
+	case SCTE35SpliceCommandTypePrivateCommand:
+		d.PrivateCommand = &SCTE35PrivateCommand{Identifier: uint32(i[*offset])<<24 | uint32(i[*offset+1])<<16 | uint32(i[*offset+2])<<8 | uint32(i[*offset+3])}
+		*offset += 4
+		if spliceCommandLength != 0xfff {
+			d.PrivateCommand.PrivateBytes = make([]byte, offsetCommandStart+spliceCommandLength-*offset)
+			copy(d.PrivateCommand.PrivateBytes, i[*offset:offsetCommandStart+spliceCommandLength])
+		}
+	case SCTE35SpliceCommandTypeSpliceInsert:
+		d.SpliceInsert = parseSCTE35SpliceInsert(i, offset)
+	case SCTE35SpliceCommandTypeSpliceSchedule:
+		d.SpliceSchedule = parseSCTE35SpliceSchedule(i, offset)
+	case SCTE35SpliceCommandTypeTimeSignal:
+		d.TimeSignal = &SCTE35TimeSignal{SpliceTime: parseSCTE35SpliceTime(i, offset)}
+	}
+
+	// Legacy encoders may not set the splice command length, in which case it's 0xfff
+	if spliceCommandLength != 0xfff {
+		*offset = offsetCommandStart + spliceCommandLength
+	}
+
+	// Descriptors
+	d.Descriptors = parseSCTE35SpliceDescriptors(i, offset)
+
+	// Alignment stuffing
+	*offset = offsetSectionsEnd
+	return
+}
+
+// parseSCTE35PTS parses a 33 bits PTS whose most significant bit is the least significant bit of the first byte
+func parseSCTE35PTS(i []byte) *ClockReference {
+	return newClockReference(int(uint64(i[0]&0x1)<<32|uint64(i[1])<<24|uint64(i[2])<<16|uint64(i[3])<<8|uint64(i[4])), 0)
+}
+
+// parseSCTE35SpliceTime parses a SCTE-35 splice time
+func parseSCTE35SpliceTime(i []byte, offset *int) (t *SCTE35SpliceTime) {
+	// Init
+	t = &SCTE35SpliceTime{}
+
+	// Time specified flag
+	if i[*offset]&0x80 == 0 {
+		*offset += 1
+		return
+	}
+
+	// PTS time
+	t.PTSTime = parseSCTE35PTS(i[*offset:])
+	*offset += 5
+	return
+}
+
+// parseSCTE35BreakDuration parses a SCTE-35 break duration
+func parseSCTE35BreakDuration(i []byte, offset *int) (d *SCTE35BreakDuration) {
+	d = &SCTE35BreakDuration{
+		AutoReturn: i[*offset]&0x80 > 0,
+		Duration:   parseSCTE35PTS(i[*offset:]),
+	}
+	*offset += 5
+	return
+}
+
+// parseSCTE35SpliceInsert parses a SCTE-35 splice insert command
+func parseSCTE35SpliceInsert(i []byte, offset *int) (s *SCTE35SpliceInsert) {
+	// Init
+	s = &SCTE35SpliceInsert{}
+
+	// Splice event ID
+	s.SpliceEventID = uint32(i[*offset])<<24 | uint32(i[*offset+1])<<16 | uint32(i[*offset+2])<<8 | uint32(i[*offset+3])
+	*offset += 4
+
+	// Splice event cancel indicator
+	s.SpliceEventCancel = i[*offset]&0x80 > 0
+	*offset += 1
+	if s.SpliceEventCancel {
+		return
+	}
+
+	// Flags
+	s.OutOfNetwork = i[*offset]&0x80 > 0
+	s.ProgramSplice = i[*offset]&0x40 > 0
+	var hasDuration = i[*offset]&0x20 > 0
+	s.SpliceImmediate = i[*offset]&0x10 > 0
+	*offset += 1
+
+	// Splice time
+	if s.ProgramSplice && !s.SpliceImmediate {
+		s.SpliceTime = parseSCTE35SpliceTime(i, offset)
+	}
+
+	// Components
+	if !s.ProgramSplice {
+		// Component count
+		var componentCount = int(i[*offset])
+		*offset += 1
+
+		// Loop through components
+		for idx := 0; idx < componentCount; idx++ {
+			// Component tag
+			var c = &SCTE35SpliceInsertComponent{ComponentTag: uint8(i[*offset])}
+			*offset += 1
+
+			// Splice time
+			if !s.SpliceImmediate {
+				c.SpliceTime = parseSCTE35SpliceTime(i, offset)
+			}
+
+			// Append component
+			s.Components = append(s.Components, c)
+		}
+	}
+
+	// Break duration
+	if hasDuration {
+		s.BreakDuration = parseSCTE35BreakDuration(i, offset)
+	}
+
+	// Unique program ID
+	s.UniqueProgramID = uint16(i[*offset])<<8 | uint16(i[*offset+1])
+	*offset += 2
+
+	// Avail num
+	s.AvailNum = uint8(i[*offset])
+	*offset += 1
+
+	// Avails expected
+	s.AvailsExpected = uint8(i[*offset])
+	*offset += 1
+	return
+}
+
+// parseSCTE35SpliceSchedule parses a SCTE-35 splice schedule command
+func parseSCTE35SpliceSchedule(i []byte, offset *int) (s *SCTE35SpliceSchedule) {
+	// Init
+	s = &SCTE35SpliceSchedule{}
+
+	// Splice count
+	var spliceCount = int(i[*offset])
+	*offset += 1
+
+	// Loop through events
+	for idxEvent := 0; idxEvent < spliceCount; idxEvent++ {
+		// Splice event ID
+		var e = &SCTE35SpliceScheduleEvent{}
+		e.SpliceEventID = uint32(i[*offset])<<24 | uint32(i[*offset+1])<<16 | uint32(i[*offset+2])<<8 | uint32(i[*offset+3])
+		*offset += 4
+
+		// Splice event cancel indicator
+		e.SpliceEventCancel = i[*offset]&0x80 > 0
+		*offset += 1
+		if e.SpliceEventCancel {
+			s.Events = append(s.Events, e)
+			continue
+		}
+
+		// Flags
+		e.OutOfNetwork = i[*offset]&0x80 > 0
+		e.ProgramSplice = i[*offset]&0x40 > 0
+		var hasDuration = i[*offset]&0x20 > 0
+		*offset += 1
+
+		// UTC splice time
+		if e.ProgramSplice {
+			e.UTCSpliceTime = uint32(i[*offset])<<24 | uint32(i[*offset+1])<<16 | uint32(i[*offset+2])<<8 | uint32(i[*offset+3])
+			*offset += 4
+		} else {
+			// Component count
+			var componentCount = int(i[*offset])
+			*offset += 1
+
+			// Loop through components
+			for idxComponent := 0; idxComponent < componentCount; idxComponent++ {
+				e.Components = append(e.Components, &SCTE35SpliceScheduleComponent{
+					ComponentTag:  uint8(i[*offset]),
+					UTCSpliceTime: uint32(i[*offset+1])<<24 | uint32(i[*offset+2])<<16 | uint32(i[*offset+3])<<8 | uint32(i[*offset+4]),
+				})
+				*offset += 5
+			}
+		}
+
+		// Break duration
+		if hasDuration {
+			e.BreakDuration = parseSCTE35BreakDuration(i, offset)
+		}
+
+		// Unique program ID
+		e.UniqueProgramID = uint16(i[*offset])<<8 | uint16(i[*offset+1])
+		*offset += 2
+
+		// Avail num
+		e.AvailNum = uint8(i[*offset])
+		*offset += 1
+
+		// Avails expected
+		e.AvailsExpected = uint8(i[*offset])
+		*offset += 1
+
+		// Append event
+		s.Events = append(s.Events, e)
+	}
+	return
+}
+
+// parseSCTE35SpliceDescriptors parses a SCTE-35 splice descriptors loop
+func parseSCTE35SpliceDescriptors(i []byte, offset *int) (o []*SCTE35SpliceDescriptor) {
+	// Descriptor loop length
+	var offsetEnd = *offset + 2 + int(uint16(i[*offset])<<8|uint16(i[*offset+1]))
+	*offset += 2
+
+	// Loop through descriptors
+	for *offset < offsetEnd {
+		// Init
+		var d = &SCTE35SpliceDescriptor{
+			Length: uint8(i[*offset+1]),
+			Tag:    uint8(i[*offset]),
+		}
+		*offset += 2
+		var offsetDescriptorEnd = *offset + int(d.Length)
+
+		// Identifier
+		d.Identifier = uint32(i[*offset])<<24 | uint32(i[*offset+1])<<16 | uint32(i[*offset+2])<<8 | uint32(i[*offset+3])
+		*offset += 4
+
+		// Get descriptor content
+		var b = i[*offset:offsetDescriptorEnd]
+
+		// Switch on tag
+		var parsed = true
+		if d.Identifier == SCTE35IdentifierCUEI {
+			switch d.Tag {
+			case SCTE35SpliceDescriptorTagAudio:
+				d.Audio = newSCTE35SpliceDescriptorAudio(b)
+			case SCTE35SpliceDescriptorTagAvail:
+				d.Avail = newSCTE35SpliceDescriptorAvail(b)
+			case SCTE35SpliceDescriptorTagDTMF:
+				d.DTMF = newSCTE35SpliceDescriptorDTMF(b)
+			case SCTE35SpliceDescriptorTagSegmentation:
+				d.Segmentation = newSCTE35SpliceDescriptorSegmentation(b)
+			case SCTE35SpliceDescriptorTagTime:
+				d.Time = newSCTE35SpliceDescriptorTime(b)
+			default:
+				parsed = false
+			}
+		} else {
+			parsed = false
+		}
+
+		// Private bytes
+		if !parsed {
+			d.PrivateBytes = make([]byte, len(b))
+			copy(d.PrivateBytes, b)
+		}
+
+		// Append descriptor
+		*offset = offsetDescriptorEnd
+		o = append(o, d)
+	}
+	return
+}
+
+// newSCTE35SpliceDescriptorAvail parses a SCTE-35 avail descriptor
+func newSCTE35SpliceDescriptorAvail(i []byte) *SCTE35SpliceDescriptorAvail {
+	return &SCTE35SpliceDescriptorAvail{ProviderAvailID: uint32(i[0])<<24 | uint32(i[1])<<16 | uint32(i[2])<<8 | uint32(i[3])}
+}
+
+// newSCTE35SpliceDescriptorDTMF parses a SCTE-35 DTMF descriptor
+func newSCTE35SpliceDescriptorDTMF(i []byte) (d *SCTE35SpliceDescriptorDTMF) {
+	// Preroll
+	d = &SCTE35SpliceDescriptorDTMF{Preroll: uint8(i[0])}
+
+	// Chars
+	var count = int(i[1] >> 5)
+	d.Chars = make([]byte, count)
+	copy(d.Chars, i[2:2+count])
+	return
+}
+
+// newSCTE35SpliceDescriptorSegmentation parses a SCTE-35 segmentation descriptor
+func newSCTE35SpliceDescriptorSegmentation(i []byte) (d *SCTE35SpliceDescriptorSegmentation) {
+	// Init
+	d = &SCTE35SpliceDescriptorSegmentation{}
+	var offset int
+
+	// Segmentation event ID
+	d.SegmentationEventID = uint32(i[offset])<<24 | uint32(i[offset+1])<<16 | uint32(i[offset+2])<<8 | uint32(i[offset+3])
+	offset += 4
+
+	// Segmentation event cancel indicator
+	d.SegmentationEventCancel = i[offset]&0x80 > 0
+	offset += 1
+	if d.SegmentationEventCancel {
+		return
+	}
+
+	// Flags
+	d.ProgramSegmentation = i[offset]&0x80 > 0
+	var hasDuration = i[offset]&0x40 > 0
+	d.DeliveryNotRestricted = i[offset]&0x20 > 0
+	if !d.DeliveryNotRestricted {
+		d.WebDeliveryAllowed = i[offset]&0x10 > 0
+		d.NoRegionalBlackout = i[offset]&0x8 > 0
+		d.ArchiveAllowed = i[offset]&0x4 > 0
+		d.DeviceRestrictions = uint8(i[offset] & 0x3)
+	}
+	offset += 1
+
+	// Components
+	if !d.ProgramSegmentation {
+		// Component count
+		var componentCount = int(i[offset])
+		offset += 1
+
+		// Loop through components
+		for idx := 0; idx < componentCount; idx++ {
+			d.Components = append(d.Components, &SCTE35SpliceDescriptorSegmentationComponent{
+				ComponentTag: uint8(i[offset]),
+				PTSOffset:    parseSCTE35PTS(i[offset+1:]),
+			})
+			offset += 6
+		}
+	}
+
+	// Segmentation duration
+	if hasDuration {
+		d.SegmentationDuration = newClockReference(int(uint64(i[offset])<<32|uint64(i[offset+1])<<24|uint64(i[offset+2])<<16|uint64(i[offset+3])<<8|uint64(i[offset+4])), 0)
+		offset += 5
+	}
+
+	// Segmentation UPID type
+	d.SegmentationUPIDType = uint8(i[offset])
+	offset += 1
+
+	// Segmentation UPID
+	var upidLength = int(i[offset])
+	offset += 1
+	d.SegmentationUPID = make([]byte, upidLength)
+	copy(d.SegmentationUPID, i[offset:offset+upidLength])
+	offset += upidLength
+
+	// Segmentation type ID
+	d.SegmentationTypeID = uint8(i[offset])
+	offset += 1
+
+	// Segment num
+	d.SegmentNum = uint8(i[offset])
+	offset += 1
+
+	// Segments expected
+	d.SegmentsExpected = uint8(i[offset])
+	offset += 1
+
+	// Sub segments were added in later versions of the standard, therefore they may be missing
+	if hasSCTE35SubSegments(d.SegmentationTypeID) && offset+2 <= len(i) {
+		d.SubSegmentNum = uint8(i[offset])
+		d.SubSegmentsExpected = uint8(i[offset+1])
+	}
+	return
+}
+
+// hasSCTE35SubSegments checks whether the segmentation type id is followed by sub segments
+func hasSCTE35SubSegments(segmentationTypeID uint8) bool {
+	return segmentationTypeID == 0x34 ||
+		segmentationTypeID == 0x36 ||
+		segmentationTypeID == 0x38 ||
+		segmentationTypeID == 0x3a ||
+		segmentationTypeID == 0x44 ||
+		segmentationTypeID == 0x46
+}
+
+// newSCTE35SpliceDescriptorTime parses a SCTE-35 time descriptor
+func newSCTE35SpliceDescriptorTime(i []byte) *SCTE35SpliceDescriptorTime {
+	return &SCTE35SpliceDescriptorTime{
+		TAINanoseconds: uint32(i[6])<<24 | uint32(i[7])<<16 | uint32(i[8])<<8 | uint32(i[9]),
+		TAISeconds:     uint64(i[0])<<40 | uint64(i[1])<<32 | uint64(i[2])<<24 | uint64(i[3])<<16 | uint64(i[4])<<8 | uint64(i[5]),
+		UTCOffset:      uint16(i[10])<<8 | uint16(i[11]),
+	}
+}
+
+// newSCTE35SpliceDescriptorAudio parses a SCTE-35 audio descriptor
+func newSCTE35SpliceDescriptorAudio(i []byte) (d *SCTE35SpliceDescriptorAudio) {
+	// Init
+	d = &SCTE35SpliceDescriptorAudio{}
+	var offset int
+
+	// Audio count
+	var count = int(i[offset] >> 4)
+	offset += 1
+
+	// Loop through components
+	for idx := 0; idx < count; idx++ {
+		d.Components = append(d.Components, &SCTE35SpliceDescriptorAudioComponent{
+			BitStreamMode:      uint8(i[offset+4] >> 5),
+			ComponentTag:       uint8(i[offset]),
+			FullServiceAudio:   i[offset+4]&0x1 > 0,
+			ISO639LanguageCode: i[offset+1 : offset+4],
+			NumChannels:        uint8(i[offset+4]>>1) & 0xf,
+		})
+		offset += 5
+	}
+	return
+}
+
+// Serialize serializes the SCTE-35 data into a complete splice info section, CRC32 included
+// The splice command is the one matching the splice command type and the splice command length is computed. When the
+// packet is encrypted, encrypted bytes are written as is and the splice command length is set to 0xfff. The pointer
+// field is not included
+func (d *SCTE35Data) Serialize() (b []byte, err error) {
+	// Data
+	var bd []byte
+	if bd, err = writeSCTE35Section(d); err != nil {
+		err = errors.Wrap(err, "astits: writing SCTE-35 section failed")
+		return
+	}
+
+	// Check length
+	var l = len(bd) + 4
+	if l > scte35SectionMaximumLength {
+		err = fmt.Errorf("astits: SCTE-35 section length %d is bigger than %d", l, scte35SectionMaximumLength)
+		return
+	}
+
+	// Table ID, section syntax indicator, private bit, SAP type and section length
+	b = append([]byte{0xfc, 0x30 | uint8(l>>8)&0xf, uint8(l)}, bd...)
+
+	// CRC32
+	var c = computeCRC32(b)
+	b = append(b, uint8(c>>24), uint8(c>>16), uint8(c>>8), uint8(c))
+	return
+}
+
+// writeSCTE35Section serializes a SCTE-35 splice info section data
+func writeSCTE35Section(d *SCTE35Data) (b []byte, err error) {
+	// Protocol version
+	b = append(b, d.ProtocolVersion)
+
+	// Encrypted packet, encryption algorithm and PTS adjustment
+	var pts = writeSCTE35PTS(d.PTSAdjustment)
+	pts[0] |= d.EncryptionAlgorithm & 0x3f << 1
+	if d.EncryptedPacket {
+		pts[0] |= 0x80
+	}
+	b = append(b, pts...)
+
+	// CW index
+	b = append(b, d.CWIndex)
+
+	// Encrypted content is written as is
+	if d.EncryptedPacket {
+		b = append(b, uint8(d.Tier>>4), uint8(d.Tier<<4)|0xf, 0xff)
+		b = append(b, d.EncryptedBytes...)
+		return
+	}
+
+	// Splice command
+	var bc []byte
+	switch d.SpliceCommandType {
+	case SCTE35SpliceCommandTypeBandwidthReservation, SCTE35SpliceCommandTypeSpliceNull:
+	case SCTE35SpliceCommandTypePrivateCommand:
+		if d.PrivateCommand == nil {
+			err = errors.New("astits: no private command provided")
+			return
+		}
+		var i = d.PrivateCommand.Identifier
+		bc = append([]byte{uint8(i >> 24), uint8(i >> 16), uint8(i >> 8), uint8(i)}, d.PrivateCommand.PrivateBytes...)
+	case SCTE35SpliceCommandTypeSpliceInsert:
+		if d.SpliceInsert == nil {
+			err = errors.New("astits: no splice insert provided")
+			return
+		}
+		bc = writeSCTE35SpliceInsert(d.SpliceInsert)
+	case SCTE35SpliceCommandTypeSpliceSchedule:
+		if d.SpliceSchedule == nil {
+			err = errors.New("astits: no splice schedule provided")
+			return
+		}
+		bc = writeSCTE35SpliceSchedule(d.SpliceSchedule)
+	case SCTE35SpliceCommandTypeTimeSignal:
+		if d.TimeSignal == nil {
+			err = errors.New("astits: no time signal provided")
+			return
+		}
+		bc = writeSCTE35SpliceTime(d.TimeSignal.SpliceTime)
+	default:
+		err = fmt.Errorf("astits: splice command type 0x%x can't be serialized", d.SpliceCommandType)
+		return
+	}
+
+	// Tier, splice command length and splice command type
+	var l = len(bc)
+	b = append(b, uint8(d.Tier>>4), uint8(d.Tier<<4)|uint8(l>>8)&0xf, uint8(l), d.SpliceCommandType)
+	b = append(b, bc...)
+
+	// Descriptors
+	var bd []byte
+	if bd, err = writeSCTE35SpliceDescriptors(d.Descriptors); err != nil {
+		err = errors.Wrap(err, "astits: writing splice descriptors failed")
+		return
+	}
+	b = append(b, bd...)
+	return
+}
+
+// writeSCTE35PTS serializes a 33 bits PTS whose most significant bit is the least significant bit of the first byte
+// The other bits of the first byte are left to 0
+func writeSCTE35PTS(cr *ClockReference) []byte {
+	var v uint64
+	if cr != nil {
+		v = uint64(cr.Base)
+	}
+	return []byte{uint8(v>>32) & 0x1, uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}
+}
+
+// writeSCTE35SpliceTime serializes a SCTE-35 splice time
+func writeSCTE35SpliceTime(t *SCTE35SpliceTime) []byte {
+	// Time is not specified
+	if t == nil || t.PTSTime == nil {
+		return []byte{0x7f}
+	}
+
+	// PTS time
+	var b = writeSCTE35PTS(t.PTSTime)
+	b[0] |= 0xfe
+	return b
+}
+
+// writeSCTE35BreakDuration serializes a SCTE-35 break duration
+func writeSCTE35BreakDuration(d *SCTE35BreakDuration) []byte {
+	var b = writeSCTE35PTS(d.Duration)
+	b[0] |= 0x7e
+	if d.AutoReturn {
+		b[0] |= 0x80
+	}
+	return b
+}
+
+// writeSCTE35SpliceEventHeader serializes the splice event ID and the splice event cancel indicator
+func writeSCTE35SpliceEventHeader(id uint32, cancel bool) []byte {
+	var b = []byte{uint8(id >> 24), uint8(id >> 16), uint8(id >> 8), uint8(id), 0x7f}
+	if cancel {
+		b[4] |= 0x80
+	}
+	return b
+}
+
+// writeSCTE35SpliceInsert serializes a SCTE-35 splice insert command
+func writeSCTE35SpliceInsert(s *SCTE35SpliceInsert) (b []byte) {
+	// Splice event ID and splice event cancel indicator
+	b = writeSCTE35SpliceEventHeader(s.SpliceEventID, s.SpliceEventCancel)
+	if s.SpliceEventCancel {
+		return
+	}
+
+	// Flags
+	var flags = uint8(0xf)
+	if s.OutOfNetwork {
+		flags |= 0x80
+	}
+	if s.ProgramSplice {
+		flags |= 0x40
+	}
+	if s.BreakDuration != nil {
+		flags |= 0x20
+	}
+	if s.SpliceImmediate {
+		flags |= 0x10
+	}
+	b = append(b, flags)
+
+	// Splice time
+	if s.ProgramSplice && !s.SpliceImmediate {
+		b = append(b, writeSCTE35SpliceTime(s.SpliceTime)...)
+	}
+
+	// Components
+	if !s.ProgramSplice {
+		b = append(b, uint8(len(s.Components)))
+		for _, c := range s.Components {
+			b = append(b, c.ComponentTag)
+			if !s.SpliceImmediate {
+				b = append(b, writeSCTE35SpliceTime(c.SpliceTime)...)
+			}
+		}
+	}
+
+	// Break duration
+	if s.BreakDuration != nil {
+		b = append(b, writeSCTE35BreakDuration(s.BreakDuration)...)
+	}
+
+	// Unique program ID, avail num and avails expected
+	b = append(b, uint8(s.UniqueProgramID>>8), uint8(s.UniqueProgramID), s.AvailNum, s.AvailsExpected)
+	return
+}
+
+// writeSCTE35SpliceSchedule serializes a SCTE-35 splice schedule command
+func writeSCTE35SpliceSchedule(s *SCTE35SpliceSchedule) (b []byte) {
+	// Splice count
+	b = append(b, uint8(len(s.Events)))
+
+	// Loop through events
+	for _, e := range s.Events {
+		// Splice event ID and splice event cancel indicator
+		b = append(b, writeSCTE35SpliceEventHeader(e.SpliceEventID, e.SpliceEventCancel)...)
+		if e.SpliceEventCancel {
+			continue
+		}
+
+		// Flags
+		var flags = uint8(0x1f)
+		if e.OutOfNetwork {
+			flags |= 0x80
+		}
+		if e.ProgramSplice {
+			flags |= 0x40
+		}
+		if e.BreakDuration != nil {
+			flags |= 0x20
+		}
+		b = append(b, flags)
+
+		// UTC splice time
+		if e.ProgramSplice {
+			b = append(b, uint8(e.UTCSpliceTime>>24), uint8(e.UTCSpliceTime>>16), uint8(e.UTCSpliceTime>>8), uint8(e.UTCSpliceTime))
+		} else {
+			b = append(b, uint8(len(e.Components)))
+			for _, c := range e.Components {
+				b = append(b, c.ComponentTag, uint8(c.UTCSpliceTime>>24), uint8(c.UTCSpliceTime>>16), uint8(c.UTCSpliceTime>>8), uint8(c.UTCSpliceTime))
+			}
+		}
+
+		// Break duration
+		if e.BreakDuration != nil {
+			b = append(b, writeSCTE35BreakDuration(e.BreakDuration)...)
+		}
+
+		// Unique program ID, avail num and avails expected
+		b = append(b, uint8(e.UniqueProgramID>>8), uint8(e.UniqueProgramID), e.AvailNum, e.AvailsExpected)
+	}
+	return
+}
+
+// writeSCTE35SpliceDescriptors serializes a SCTE-35 splice descriptors loop, its length included
+// Descriptors with private bytes are written as is
+func writeSCTE35SpliceDescriptors(ds []*SCTE35SpliceDescriptor) (b []byte, err error) {
+	// Loop through descriptors
+	var bl []byte
+	for _, d := range ds {
+		// Get descriptor content
+		var bc []byte
+		switch {
+		case d.PrivateBytes != nil:
+			bc = d.PrivateBytes
+		case d.Audio != nil:
+			bc = writeSCTE35SpliceDescriptorAudio(d.Audio)
+		case d.Avail != nil:
+			var i = d.Avail.ProviderAvailID
+			bc = []byte{uint8(i >> 24), uint8(i >> 16), uint8(i >> 8), uint8(i)}
+		case d.DTMF != nil:
+			bc = append([]byte{d.DTMF.Preroll, uint8(len(d.DTMF.Chars))<<5 | 0x1f}, d.DTMF.Chars...)
+		case d.Segmentation != nil:
+			bc = writeSCTE35SpliceDescriptorSegmentation(d.Segmentation)
+		case d.Time != nil:
+			bc = writeSCTE35SpliceDescriptorTime(d.Time)
+		default:
+			err = fmt.Errorf("astits: splice descriptor with tag 0x%x can't be serialized", d.Tag)
+			return
+		}
+
+		// Check length
+		var l = 4 + len(bc)
+		if l > 0xff {
+			err = fmt.Errorf("astits: splice descriptor with tag 0x%x is bigger than 255 bytes", d.Tag)
+			return
+		}
+
+		// Tag, length and identifier
+		var i = d.Identifier
+		if d.PrivateBytes == nil {
+			i = SCTE35IdentifierCUEI
+		}
+		bl = append(bl, d.Tag, uint8(l), uint8(i>>24), uint8(i>>16), uint8(i>>8), uint8(i))
+		bl = append(bl, bc...)
+	}
+
+	// Descriptor loop length
+	b = append([]byte{uint8(len(bl) >> 8), uint8(len(bl))}, bl...)
+	return
+}
+
+// writeSCTE35SpliceDescriptorSegmentation serializes a SCTE-35 segmentation descriptor
+func writeSCTE35SpliceDescriptorSegmentation(d *SCTE35SpliceDescriptorSegmentation) (b []byte) {
+	// Segmentation event ID and segmentation event cancel indicator
+	b = writeSCTE35SpliceEventHeader(d.SegmentationEventID, d.SegmentationEventCancel)
+	if d.SegmentationEventCancel {
+		return
+	}
+
+	// Flags
+	var flags uint8
+	if d.ProgramSegmentation {
+		flags |= 0x80
+	}
+	if d.SegmentationDuration != nil {
+		flags |= 0x40
+	}
+	if d.DeliveryNotRestricted {
+		flags |= 0x3f
+	} else {
+		if d.WebDeliveryAllowed {
+			flags |= 0x10
+		}
+		if d.NoRegionalBlackout {
+			flags |= 0x8
+		}
+		if d.ArchiveAllowed {
+			flags |= 0x4
+		}
+		flags |= d.DeviceRestrictions & 0x3
+	}
+	b = append(b, flags)
+
+	// Components
+	if !d.ProgramSegmentation {
+		b = append(b, uint8(len(d.Components)))
+		for _, c := range d.Components {
+			var pts = writeSCTE35PTS(c.PTSOffset)
+			pts[0] |= 0xfe
+			b = append(b, c.ComponentTag)
+			b = append(b, pts...)
+		}
+	}
+
+	// Segmentation duration
+	if d.SegmentationDuration != nil {
+		var v = uint64(d.SegmentationDuration.Base)
+		b = append(b, uint8(v>>32), uint8(v>>24), uint8(v>>16), uint8(v>>8), uint8(v))
+	}
+
+	// Segmentation UPID
+	b = append(b, d.SegmentationUPIDType, uint8(len(d.SegmentationUPID)))
+	b = append(b, d.SegmentationUPID...)
+
+	// Segmentation type ID, segment num and segments expected
+	b = append(b, d.SegmentationTypeID, d.SegmentNum, d.SegmentsExpected)
+
+	// Sub segments
+	if hasSCTE35SubSegments(d.SegmentationTypeID) {
+		b = append(b, d.SubSegmentNum, d.SubSegmentsExpected)
+	}
+	return
+}
+
+// writeSCTE35SpliceDescriptorTime serializes a SCTE-35 time descriptor
+func writeSCTE35SpliceDescriptorTime(d *SCTE35SpliceDescriptorTime) []byte {
+	return []byte{
+		uint8(d.TAISeconds >> 40), uint8(d.TAISeconds >> 32), uint8(d.TAISeconds >> 24), uint8(d.TAISeconds >> 16), uint8(d.TAISeconds >> 8), uint8(d.TAISeconds),
+		uint8(d.TAINanoseconds >> 24), uint8(d.TAINanoseconds >> 16), uint8(d.TAINanoseconds >> 8), uint8(d.TAINanoseconds),
+		uint8(d.UTCOffset >> 8), uint8(d.UTCOffset),
+	}
+}
+
+// writeSCTE35SpliceDescriptorAudio serializes a SCTE-35 audio descriptor
+func writeSCTE35SpliceDescriptorAudio(d *SCTE35SpliceDescriptorAudio) (b []byte) {
+	b = append(b, uint8(len(d.Components))<<4|0xf)
+	for _, c := range d.Components {
+		var flags = c.BitStreamMode&0x7<<5 | c.NumChannels&0xf<<1
+		if c.FullServiceAudio {
+			flags |= 0x1
+		}
+		b = append(b, c.ComponentTag)
+		b = append(b, c.ISO639LanguageCode...)
+		b = append(b, flags)
+	}
+	return
+}
@@ -0,0 +1,243 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/asticode/go-astitools/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+var scte35 = &SCTE35Data{
+	CWIndex: 0xff,
+	Descriptors: []*SCTE35SpliceDescriptor{
+		{
+			Identifier: SCTE35IdentifierCUEI,
+			Length:     25,
+			Segmentation: &SCTE35SpliceDescriptorSegmentation{
+				DeliveryNotRestricted: true,
+				ProgramSegmentation:   true,
+				SegmentationDuration:  &ClockReference{Base: 2700000},
+				SegmentationEventID:   2,
+				SegmentationTypeID:    SCTE35SegmentationTypeIDProviderPlacementOpportunityStart,
+				SegmentationUPID:      []byte("abc"),
+				SegmentationUPIDType:  0x9,
+				SegmentNum:            1,
+				SegmentsExpected:      2,
+				SubSegmentNum:         3,
+				SubSegmentsExpected:   4,
+			},
+			Tag: SCTE35SpliceDescriptorTagSegmentation,
+		},
+		{
+			Identifier:   0x41424344,
+			Length:       6,
+			PrivateBytes: []byte{0x1, 0x2},
+			Tag:          SCTE35SpliceDescriptorTagAvail,
+		},
+	},
+	PTSAdjustment:     &ClockReference{Base: 1<<32 | 0x10},
+	SpliceCommandType: SCTE35SpliceCommandTypeSpliceInsert,
+	SpliceInsert: &SCTE35SpliceInsert{
+		AvailNum:        1,
+		AvailsExpected:  2,
+		BreakDuration:   &SCTE35BreakDuration{AutoReturn: true, Duration: &ClockReference{Base: 2700000}},
+		OutOfNetwork:    true,
+		ProgramSplice:   true,
+		SpliceEventID:   1,
+		SpliceTime:      &SCTE35SpliceTime{PTSTime: &ClockReference{Base: 1<<32 | 0x20}},
+		UniqueProgramID: 3,
+	},
+	Tier: 0xfff,
+}
+
+func scte35Bytes() []byte {
+	w := astibinary.New()
+	w.Write(uint8(0))                                                         // Protocol version
+	w.Write("0")                                                              // Encrypted packet
+	w.Write("000000")                                                         // Encryption algorithm
+	w.Write("1")                                                              // PTS adjustment
+	w.Write(uint32(0x10))                                                     // PTS adjustment
+	w.Write(uint8(0xff))                                                      // CW index
+	w.Write("111111111111")                                                   // Tier
+	w.Write("000000010100")                                                   // Splice command length
+	w.Write(uint8(SCTE35SpliceCommandTypeSpliceInsert))                       // Splice command type
+	w.Write(uint32(1))                                                        // Splice event ID
+	w.Write("0")                                                              // Splice event cancel indicator
+	w.Write("1111111")                                                        // Reserved
+	w.Write("1")                                                              // Out of network indicator
+	w.Write("1")                                                              // Program splice flag
+	w.Write("1")                                                              // Duration flag
+	w.Write("0")                                                              // Splice immediate flag
+	w.Write("1111")                                                           // Reserved
+	w.Write("1")                                                              // Time specified flag
+	w.Write("111111")                                                         // Reserved
+	w.Write("1")                                                              // PTS time
+	w.Write(uint32(0x20))                                                     // PTS time
+	w.Write("1")                                                              // Auto return
+	w.Write("111111")                                                         // Reserved
+	w.Write("0")                                                              // Duration
+	w.Write(uint32(2700000))                                                  // Duration
+	w.Write(uint16(3))                                                        // Unique program ID
+	w.Write(uint8(1))                                                         // Avail num
+	w.Write(uint8(2))                                                         // Avails expected
+	w.Write(uint16(35))                                                       // Descriptor loop length
+	w.Write(uint8(SCTE35SpliceDescriptorTagSegmentation))                     // Descriptor #1 tag
+	w.Write(uint8(25))                                                        // Descriptor #1 length
+	w.Write(uint32(SCTE35IdentifierCUEI))                                     // Descriptor #1 identifier
+	w.Write(uint32(2))                                                        // Descriptor #1 segmentation event ID
+	w.Write("0")                                                              // Descriptor #1 segmentation event cancel indicator
+	w.Write("1111111")                                                        // Descriptor #1 reserved
+	w.Write("1")                                                              // Descriptor #1 program segmentation flag
+	w.Write("1")                                                              // Descriptor #1 segmentation duration flag
+	w.Write("1")                                                              // Descriptor #1 delivery not restricted flag
+	w.Write("11111")                                                          // Descriptor #1 reserved
+	w.Write(uint8(0))                                                         // Descriptor #1 segmentation duration
+	w.Write(uint32(2700000))                                                  // Descriptor #1 segmentation duration
+	w.Write(uint8(0x9))                                                       // Descriptor #1 segmentation UPID type
+	w.Write(uint8(3))                                                         // Descriptor #1 segmentation UPID length
+	w.Write([]byte("abc"))                                                    // Descriptor #1 segmentation UPID
+	w.Write(uint8(SCTE35SegmentationTypeIDProviderPlacementOpportunityStart)) // Descriptor #1 segmentation type ID
+	w.Write(uint8(1))                                                         // Descriptor #1 segment num
+	w.Write(uint8(2))                                                         // Descriptor #1 segments expected
+	w.Write(uint8(3))                                                         // Descriptor #1 sub segment num
+	w.Write(uint8(4))                                                         // Descriptor #1 sub segments expected
+	w.Write(uint8(SCTE35SpliceDescriptorTagAvail))                            // Descriptor #2 tag
+	w.Write(uint8(6))                                                         // Descriptor #2 length
+	w.Write([]byte("ABCD"))                                                   // Descriptor #2 identifier
+	w.Write([]byte{0x1, 0x2})                                                 // Descriptor #2 private bytes
+	w.Write([]byte{0xff, 0xff})                                               // Alignment stuffing
+	return w.Bytes()
+}
+
+func TestParseSCTE35Section(t *testing.T) {
+	// Splice insert
+	var offset int
+	var b = scte35Bytes()
+	d := parseSCTE35Section(b, &offset, len(b))
+	assert.Equal(t, scte35, d)
+	assert.Equal(t, len(b), offset)
+
+	// Time signal
+	w := astibinary.New()
+	w.Write(uint8(0))                                 // Protocol version
+	w.Write("0000000")                                // Encrypted packet and encryption algorithm
+	w.Write("0")                                      // PTS adjustment
+	w.Write(uint32(0))                                // PTS adjustment
+	w.Write(uint8(0))                                 // CW index
+	w.Write("111111111111")                           // Tier
+	w.Write("000000000101")                           // Splice command length
+	w.Write(uint8(SCTE35SpliceCommandTypeTimeSignal)) // Splice command type
+	w.Write("1")                                      // Time specified flag
+	w.Write("111111")                                 // Reserved
+	w.Write("0")                                      // PTS time
+	w.Write(uint32(0x30))                             // PTS time
+	w.Write(uint16(0))                                // Descriptor loop length
+	b = w.Bytes()
+	offset = 0
+	d = parseSCTE35Section(b, &offset, len(b))
+	assert.Equal(t, &SCTE35TimeSignal{SpliceTime: &SCTE35SpliceTime{PTSTime: &ClockReference{Base: 0x30}}}, d.TimeSignal)
+	assert.Equal(t, len(b), offset)
+
+	// Encrypted
+	w.Reset()
+	w.Write(uint8(0))                                  // Protocol version
+	w.Write("1")                                       // Encrypted packet
+	w.Write("000010")                                  // Encryption algorithm
+	w.Write("0")                                       // PTS adjustment
+	w.Write(uint32(0))                                 // PTS adjustment
+	w.Write(uint8(3))                                  // CW index
+	w.Write("111111111111")                            // Tier
+	w.Write("000000000000")                            // Splice command length
+	w.Write([]byte{0x1, 0x2, 0x3, 0x4, 0x5, 0x6, 0x7}) // Encrypted bytes
+	b = w.Bytes()
+	offset = 0
+	d = parseSCTE35Section(b, &offset, len(b))
+	assert.Equal(t, []byte{0x1, 0x2, 0x3, 0x4, 0x5, 0x6, 0x7}, d.EncryptedBytes)
+	assert.True(t, d.EncryptedPacket)
+	assert.Equal(t, uint8(SCTE35EncryptionAlgorithmDESCBC), d.EncryptionAlgorithm)
+	assert.Equal(t, uint8(3), d.CWIndex)
+	assert.Equal(t, len(b), offset)
+}
+
+func TestWriteSCTE35Section(t *testing.T) {
+	b, err := writeSCTE35Section(scte35)
+	assert.NoError(t, err)
+	var e = scte35Bytes()
+	assert.Equal(t, e[:len(e)-2], b)
+}
+
+func TestSCTE35DataSerialize(t *testing.T) {
+	for _, d := range []*SCTE35Data{
+		scte35,
+		{
+			Descriptors: []*SCTE35SpliceDescriptor{
+				{Audio: &SCTE35SpliceDescriptorAudio{Components: []*SCTE35SpliceDescriptorAudioComponent{{BitStreamMode: 2, ComponentTag: 1, FullServiceAudio: true, ISO639LanguageCode: []byte("eng"), NumChannels: 5}}}, Identifier: SCTE35IdentifierCUEI, Length: 10, Tag: SCTE35SpliceDescriptorTagAudio},
+				{Avail: &SCTE35SpliceDescriptorAvail{ProviderAvailID: 4}, Identifier: SCTE35IdentifierCUEI, Length: 8, Tag: SCTE35SpliceDescriptorTagAvail},
+				{DTMF: &SCTE35SpliceDescriptorDTMF{Chars: []byte("12*"), Preroll: 5}, Identifier: SCTE35IdentifierCUEI, Length: 9, Tag: SCTE35SpliceDescriptorTagDTMF},
+				{Identifier: SCTE35IdentifierCUEI, Length: 16, Tag: SCTE35SpliceDescriptorTagTime, Time: &SCTE35SpliceDescriptorTime{TAINanoseconds: 2, TAISeconds: 1 << 40, UTCOffset: 37}},
+				{Identifier: SCTE35IdentifierCUEI, Length: 22, Segmentation: &SCTE35SpliceDescriptorSegmentation{
+					ArchiveAllowed:      true,
+					Components:          []*SCTE35SpliceDescriptorSegmentationComponent{{ComponentTag: 1, PTSOffset: &ClockReference{Base: 0x10}}},
+					DeviceRestrictions:  2,
+					SegmentationTypeID:  SCTE35SegmentationTypeIDProgramStart,
+					SegmentationUPID:    []byte{},
+					WebDeliveryAllowed:  true,
+					SegmentationEventID: 3,
+				}, Tag: SCTE35SpliceDescriptorTagSegmentation},
+				{Identifier: SCTE35IdentifierCUEI, Length: 9, Segmentation: &SCTE35SpliceDescriptorSegmentation{SegmentationEventCancel: true, SegmentationEventID: 4}, Tag: SCTE35SpliceDescriptorTagSegmentation},
+			},
+			PTSAdjustment:     &ClockReference{},
+			SpliceCommandType: SCTE35SpliceCommandTypeTimeSignal,
+			Tier:              0xfff,
+			TimeSignal:        &SCTE35TimeSignal{SpliceTime: &SCTE35SpliceTime{PTSTime: &ClockReference{Base: 0x30}}},
+		},
+		{
+			PTSAdjustment:     &ClockReference{},
+			SpliceCommandType: SCTE35SpliceCommandTypeSpliceInsert,
+			SpliceInsert: &SCTE35SpliceInsert{
+				Components:      []*SCTE35SpliceInsertComponent{{ComponentTag: 1, SpliceTime: &SCTE35SpliceTime{PTSTime: &ClockReference{Base: 0x40}}}, {ComponentTag: 2, SpliceTime: &SCTE35SpliceTime{}}},
+				SpliceEventID:   5,
+				UniqueProgramID: 6,
+			},
+		},
+		{
+			PTSAdjustment:     &ClockReference{},
+			SpliceCommandType: SCTE35SpliceCommandTypeSpliceSchedule,
+			SpliceSchedule: &SCTE35SpliceSchedule{Events: []*SCTE35SpliceScheduleEvent{
+				{BreakDuration: &SCTE35BreakDuration{Duration: &ClockReference{Base: 0x50}}, OutOfNetwork: true, ProgramSplice: true, SpliceEventID: 7, UTCSpliceTime: 8},
+				{Components: []*SCTE35SpliceScheduleComponent{{ComponentTag: 1, UTCSpliceTime: 9}}, SpliceEventID: 10},
+				{SpliceEventCancel: true, SpliceEventID: 11},
+			}},
+		},
+		{
+			PrivateCommand:    &SCTE35PrivateCommand{Identifier: 0x41424344, PrivateBytes: []byte{0x1}},
+			PTSAdjustment:     &ClockReference{},
+			SpliceCommandType: SCTE35SpliceCommandTypePrivateCommand,
+		},
+		{
+			CWIndex:             1,
+			EncryptedBytes:      []byte{0x1, 0x2, 0x3},
+			EncryptedPacket:     true,
+			EncryptionAlgorithm: SCTE35EncryptionAlgorithmDESECB,
+			PTSAdjustment:       &ClockReference{Base: 1 << 32},
+			Tier:                0xabc,
+		},
+	} {
+		b, err := d.Serialize()
+		assert.NoError(t, err)
+		ps, err := parsePSIData(append([]byte{0x0}, b...), uint16(0x100), CRCModeError)
+		assert.NoError(t, err)
+		assert.Len(t, ps.Sections, 1)
+		assert.Equal(t, d, ps.Sections[0].Syntax.Data.SCTE35)
+	}
+
+	// Splice command that can't be serialized
+	_, err := (&SCTE35Data{SpliceCommandType: SCTE35SpliceCommandTypeSpliceInsert}).Serialize()
+	assert.Error(t, err)
+	_, err = (&SCTE35Data{SpliceCommandType: 0x1}).Serialize()
+	assert.Error(t, err)
+
+	// Descriptor that can't be serialized
+	_, err = (&SCTE35Data{Descriptors: []*SCTE35SpliceDescriptor{{Tag: 0x10}}}).Serialize()
+	assert.Error(t, err)
+}
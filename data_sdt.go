@@ -1,5 +1,7 @@
 package astits
 
+import "github.com/pkg/errors"
+
 // Running statuses
 const (
 	RunningStatusNotRunning          = 1
@@ -68,3 +70,73 @@ func parseSDTSection(i []byte, offset *int, offsetSectionsEnd int, tableIDExtens
 	}
 	return
 }
+
+// NewSDTDataService creates a new running SDT data service described by a service descriptor
+func NewSDTDataService(serviceID uint16, serviceType uint8, provider, name string) *SDTDataService {
+	return &SDTDataService{
+		Descriptors:   []*Descriptor{NewDescriptorService(serviceType, provider, name)},
+		RunningStatus: RunningStatusRunning,
+		ServiceID:     serviceID,
+	}
+}
+
+// Serialize serializes the SDT data of the actual transport stream into as many sections as needed, CRC32 included
+func (d *SDTData) Serialize(versionNumber uint8) (ss [][]byte, err error) {
+	// Services
+	var items [][]byte
+	for _, s := range d.Services {
+		var b []byte
+		if b, err = writeSDTDataService(s); err != nil {
+			err = errors.Wrapf(err, "astits: writing service %d failed", s.ServiceID)
+			return
+		}
+		items = append(items, b)
+	}
+
+	// Sections
+	if ss, err = writePSISections(
+		&PSISectionHeader{PrivateBit: true, SectionSyntaxIndicator: true, TableID: 0x42},
+		&PSISectionSyntaxHeader{
+			CurrentNextIndicator: true,
+			TableIDExtension:     d.TransportStreamID,
+			VersionNumber:        versionNumber,
+		},
+		[]byte{uint8(d.OriginalNetworkID >> 8), uint8(d.OriginalNetworkID), 0xff},
+		items,
+	); err != nil {
+		err = errors.Wrap(err, "astits: writing PSI sections failed")
+		return
+	}
+	return
+}
+
+// writeSDTDataService serializes an SDT data service
+func writeSDTDataService(s *SDTDataService) (b []byte, err error) {
+	// Service ID
+	b = append(b, uint8(s.ServiceID>>8), uint8(s.ServiceID))
+
+	// EIT flags
+	var f = uint8(0xfc)
+	if s.HasEITSchedule {
+		f |= 0x2
+	}
+	if s.HasEITPresentFollowing {
+		f |= 0x1
+	}
+	b = append(b, f)
+
+	// Descriptors
+	var bd []byte
+	if bd, err = writeDescriptors(s.Descriptors); err != nil {
+		err = errors.Wrap(err, "astits: writing descriptors failed")
+		return
+	}
+
+	// Running status and free CA mode share their byte with the descriptors loop length
+	bd[0] = s.RunningStatus<<5 | bd[0]&0xf
+	if s.HasFreeCSAMode {
+		bd[0] |= 0x10
+	}
+	b = append(b, bd...)
+	return
+}
@@ -40,3 +40,28 @@ func TestParseSDTSection(t *testing.T) {
 	d := parseSDTSection(b, &offset, len(b), uint16(1))
 	assert.Equal(t, d, sdt)
 }
+
+func TestSDTDataSerialize(t *testing.T) {
+	// Single section
+	ss, err := sdt.Serialize(2)
+	assert.NoError(t, err)
+	assert.Len(t, ss, 1)
+	d, err := parsePSIData(writePSIPayload(ss), uint16(0x11), CRCModeError)
+	assert.NoError(t, err)
+	assert.Equal(t, PSITableTypeSDT, d.Sections[0].Header.TableType)
+	assert.Equal(t, uint8(2), d.Sections[0].Syntax.Header.VersionNumber)
+	assert.Equal(t, sdt, d.Sections[0].Syntax.Data.SDT)
+
+	// Services
+	var e = &SDTData{OriginalNetworkID: 2, TransportStreamID: 1}
+	for idx := 0; idx < 50; idx++ {
+		e.Services = append(e.Services, NewSDTDataService(uint16(idx), ServiceTypeDigitalTelevisionService, "provider", "service name"))
+	}
+	ss, err = e.Serialize(0)
+	assert.NoError(t, err)
+	assert.Len(t, ss, 2)
+	d, err = parsePSIData(writePSIPayload(ss), uint16(0x11), CRCModeError)
+	assert.NoError(t, err)
+	assert.Len(t, d.Sections, 2)
+	assert.Equal(t, e.Services, append(d.Sections[0].Syntax.Data.SDT.Services, d.Sections[1].Syntax.Data.SDT.Services...))
+}
@@ -0,0 +1,42 @@
+package astits
+
+// SITData represents a SIT data
+// Page: 85 | Chapter: 7.1.2 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+type SITData struct {
+	Services                []*SITDataService
+	TransmissionDescriptors []*Descriptor
+}
+
+// SITDataService represents a SIT data service
+type SITDataService struct {
+	Descriptors   []*Descriptor
+	RunningStatus uint8
+	ServiceID     uint16
+}
+
+// parseSITSection parses a SIT section
+func parseSITSection(i []byte, offset *int, offsetSectionsEnd int) (d *SITData) {
+	// Init
+	d = &SITData{}
+
+	// Transmission descriptors
+	d.TransmissionDescriptors = parseDescriptors(i, offset)
+
+	// Loop until end of section data is reached
+	for *offset < offsetSectionsEnd {
+		// Service ID
+		var s = &SITDataService{}
+		s.ServiceID = uint16(i[*offset])<<8 | uint16(i[*offset+1])
+		*offset += 2
+
+		// Running status
+		s.RunningStatus = uint8(i[*offset]>>4) & 0x7
+
+		// Descriptors
+		s.Descriptors = parseDescriptors(i, offset)
+
+		// Append service
+		d.Services = append(d.Services, s)
+	}
+	return
+}
@@ -0,0 +1,35 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/asticode/go-astitools/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+var sit = &SITData{
+	Services: []*SITDataService{{
+		Descriptors:   descriptors,
+		RunningStatus: RunningStatusRunning,
+		ServiceID:     1,
+	}},
+	TransmissionDescriptors: descriptors,
+}
+
+func sitBytes() []byte {
+	w := astibinary.New()
+	w.Write("1111")     // Reserved for future use
+	descriptorsBytes(w) // Transmission descriptors
+	w.Write(uint16(1))  // Service #1 id
+	w.Write("1")        // Service #1 reserved for future use
+	w.Write("100")      // Service #1 running status
+	descriptorsBytes(w) // Service #1 descriptors
+	return w.Bytes()
+}
+
+func TestParseSITSection(t *testing.T) {
+	var offset int
+	var b = sitBytes()
+	d := parseSITSection(b, &offset, len(b))
+	assert.Equal(t, sit, d)
+}
@@ -0,0 +1,15 @@
+package astits
+
+// STData represents a ST data
+// Its content is only made of stuffing bytes that are discarded
+// Page: 40 | Chapter: 5.2.8 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+type STData struct {
+	Length int // Number of stuffing bytes
+}
+
+// parseSTSection parses a ST section
+func parseSTSection(i []byte, offset *int, offsetSectionsEnd int) (d *STData) {
+	d = &STData{Length: offsetSectionsEnd - *offset}
+	*offset = offsetSectionsEnd
+	return
+}
@@ -0,0 +1,14 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSTSection(t *testing.T) {
+	var offset = 1
+	d := parseSTSection([]byte("stuffing"), &offset, 8)
+	assert.Equal(t, &STData{Length: 7}, d)
+	assert.Equal(t, 8, offset)
+}
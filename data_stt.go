@@ -0,0 +1,52 @@
+package astits
+
+import "time"
+
+// STTData represents a STT data
+// Page: 25 | Chapter: 6.1 | Link: https://www.atsc.org/wp-content/uploads/2015/03/Program-System-Information-Protocol-for-Terrestrial-Broadcast-and-Cable.pdf
+type STTData struct {
+	DaylightSavingDayOfMonth uint8
+	DaylightSavingHour       uint8
+	DaylightSavingStatus     bool
+	Descriptors              []*Descriptor
+	GPSUTCOffset             uint8 // Number of leap seconds between GPS and UTC
+	ProtocolVersion          uint8
+	SystemTime               uint32    // Number of GPS seconds since 00:00:00 UTC, January 6th, 1980
+	UTCTime                  time.Time // System time converted to UTC with the GPS UTC offset
+}
+
+// parseSTTSection parses a STT section
+func parseSTTSection(i []byte, offset *int, offsetSectionsEnd int) (d *STTData) {
+	// Init
+	d = &STTData{}
+
+	// Protocol version
+	d.ProtocolVersion = uint8(i[*offset])
+	*offset += 1
+
+	// System time
+	d.SystemTime = uint32(i[*offset])<<24 | uint32(i[*offset+1])<<16 | uint32(i[*offset+2])<<8 | uint32(i[*offset+3])
+	*offset += 4
+
+	// GPS UTC offset
+	d.GPSUTCOffset = uint8(i[*offset])
+	*offset += 1
+
+	// UTC time
+	d.UTCTime = atscGPSEpoch.Add(time.Duration(int64(d.SystemTime)-int64(d.GPSUTCOffset)) * time.Second)
+
+	// Daylight saving status
+	d.DaylightSavingStatus = i[*offset]&0x80 > 0
+
+	// Daylight saving day of month
+	d.DaylightSavingDayOfMonth = uint8(i[*offset] & 0x1f)
+	*offset += 1
+
+	// Daylight saving hour
+	d.DaylightSavingHour = uint8(i[*offset])
+	*offset += 1
+
+	// Descriptors
+	d.Descriptors = parseDescriptorsLoop(i, offset, offsetSectionsEnd)
+	return
+}
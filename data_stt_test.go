@@ -0,0 +1,42 @@
+package astits
+
+import (
+	"testing"
+	"time"
+
+	"github.com/asticode/go-astitools/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+var stt = &STTData{
+	DaylightSavingDayOfMonth: 14,
+	DaylightSavingHour:       2,
+	DaylightSavingStatus:     true,
+	Descriptors:              descriptors,
+	GPSUTCOffset:             18,
+	SystemTime:               1000000018,
+	UTCTime:                  time.Date(2011, 9, 14, 1, 46, 40, 0, time.UTC),
+}
+
+func sttBytes() []byte {
+	w := astibinary.New()
+	w.Write(uint8(0))                             // Protocol version
+	w.Write(uint32(1000000018))                   // System time
+	w.Write(uint8(18))                            // GPS UTC offset
+	w.Write("1")                                  // Daylight saving status
+	w.Write("11")                                 // Reserved
+	w.Write("01110")                              // Daylight saving day of month
+	w.Write(uint8(2))                             // Daylight saving hour
+	w.Write(uint8(DescriptorTagStreamIdentifier)) // Descriptor tag
+	w.Write(uint8(1))                             // Descriptor length
+	w.Write(uint8(7))                             // Descriptor component tag
+	return w.Bytes()
+}
+
+func TestParseSTTSection(t *testing.T) {
+	var offset int
+	var b = sttBytes()
+	d := parseSTTSection(b, &offset, len(b))
+	assert.Equal(t, stt, d)
+	assert.Equal(t, len(b), offset)
+}
@@ -0,0 +1,19 @@
+package astits
+
+import "time"
+
+// TDTData represents a TDT data
+// Page: 39 | Chapter: 5.2.5 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+type TDTData struct {
+	UTCTime time.Time
+}
+
+// parseTDTSection parses a TDT section
+func parseTDTSection(i []byte, offset *int) (d *TDTData) {
+	// Init
+	d = &TDTData{}
+
+	// UTC time
+	d.UTCTime = parseDVBTime(i, offset)
+	return
+}
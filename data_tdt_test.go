@@ -0,0 +1,14 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTDTSection(t *testing.T) {
+	var offset int
+	d := parseTDTSection(dvbTimeBytes, &offset)
+	assert.Equal(t, &TDTData{UTCTime: dvbTime}, d)
+	assert.Equal(t, 5, offset)
+}
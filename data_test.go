@@ -3,6 +3,7 @@ package astits
 import (
 	"testing"
 
+	"github.com/asticode/go-astilog"
 	"github.com/asticode/go-astitools/binary"
 	"github.com/stretchr/testify/assert"
 )
@@ -10,6 +11,7 @@ import (
 func TestParseData(t *testing.T) {
 	// Init
 	pm := newProgramMap()
+	sm := newProgramMap()
 	ps := []*Packet{}
 
 	// Custom parser
@@ -19,15 +21,18 @@ func TestParseData(t *testing.T) {
 		skip = true
 		return
 	}
-	ds, err := parseData(ps, c, pm)
+	ds, err := parseData(ps, c, pm, sm, nil, CRCModeError, astilog.NopLogger())
 	assert.NoError(t, err)
 	assert.Equal(t, cds, ds)
 
-	// Do nothing for CAT
-	ps = []*Packet{{Header: &PacketHeader{PID: PIDCAT}}}
-	ds, err = parseData(ps, nil, pm)
+	// CAT
+	ps = []*Packet{{
+		Header:  &PacketHeader{PID: PIDCAT},
+		Payload: append([]byte{0x0}, writePSISection(&PSISectionHeader{SectionSyntaxIndicator: true, TableID: 1}, &PSISectionSyntaxHeader{CurrentNextIndicator: true, TableIDExtension: 0xffff}, catBytes())...),
+	}}
+	ds, err = parseData(ps, nil, pm, sm, nil, CRCModeError, astilog.NopLogger())
 	assert.NoError(t, err)
-	assert.Empty(t, ds)
+	assert.Equal(t, []*Data{{CAT: cat, FirstPacket: ps[0], PID: PIDCAT, RawSection: ps[0].Payload[1:]}}, ds)
 
 	// PES
 	p := pesWithHeaderBytes()
@@ -41,7 +46,7 @@ func TestParseData(t *testing.T) {
 			Payload: p[33:],
 		},
 	}
-	ds, err = parseData(ps, nil, pm)
+	ds, err = parseData(ps, nil, pm, sm, nil, CRCModeError, astilog.NopLogger())
 	assert.NoError(t, err)
 	assert.Equal(t, []*Data{{FirstPacket: ps[0], PES: pesWithHeader, PID: uint16(256)}}, ds)
 
@@ -58,22 +63,28 @@ func TestParseData(t *testing.T) {
 			Payload: p[33:],
 		},
 	}
-	ds, err = parseData(ps, nil, pm)
+	ds, err = parseData(ps, nil, pm, sm, nil, CRCModeError, astilog.NopLogger())
 	assert.NoError(t, err)
 	assert.Equal(t, psi.toData(ps[0], uint16(256)), ds)
 }
 
 func TestIsPSIPayload(t *testing.T) {
 	pm := newProgramMap()
+	sm := newProgramMap()
 	var pids []int
 	for i := 0; i <= 255; i++ {
-		if isPSIPayload(uint16(i), pm) {
+		if isPSIPayload(uint16(i), pm, sm) {
 			pids = append(pids, i)
 		}
 	}
-	assert.Equal(t, []int{0, 16, 17, 18, 19, 20, 30, 31}, pids)
+	assert.Equal(t, []int{0, 1, 2, 16, 17, 18, 19, 20, 30, 31, 36, 37}, pids)
+	assert.True(t, isPSIPayload(PIDATSCBase, pm, sm))
 	pm.set(uint16(1), uint16(0))
-	assert.True(t, isPSIPayload(uint16(1), pm))
+	assert.True(t, isPSIPayload(uint16(1), pm, sm))
+	sm.set(uint16(0x1d00), MGTTableTypeEITFirst)
+	assert.True(t, isPSIPayload(uint16(0x1d00), pm, sm))
+	sm.set(uint16(0x1d01), StreamTypeSCTE35)
+	assert.True(t, isPSIPayload(uint16(0x1d01), pm, sm))
 }
 
 func TestIsPESPayload(t *testing.T) {
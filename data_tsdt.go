@@ -0,0 +1,17 @@
+package astits
+
+// TSDTData represents a TSDT data
+// Page: 43 | Chapter: 2.4.4.12 | Link: http://ecee.colorado.edu/~ecen5653/ecen5653/papers/iso13818-1.pdf
+type TSDTData struct {
+	Descriptors []*Descriptor
+}
+
+// parseTSDTSection parses a TSDT section
+func parseTSDTSection(i []byte, offset *int, offsetSectionsEnd int) (d *TSDTData) {
+	// Init
+	d = &TSDTData{}
+
+	// Descriptors
+	d.Descriptors = parseDescriptorsLoop(i, offset, offsetSectionsEnd)
+	return
+}
@@ -0,0 +1,15 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTSDTSection(t *testing.T) {
+	var offset int
+	var b = []byte{DescriptorTagStreamIdentifier, 0x1, 0x7}
+	d := parseTSDTSection(b, &offset, len(b))
+	assert.Equal(t, &TSDTData{Descriptors: descriptors}, d)
+	assert.Equal(t, len(b), offset)
+}
@@ -0,0 +1,140 @@
+package astits
+
+import "strings"
+
+// VCT modulation modes
+// Page: 36 | Chapter: 6.3.1 | Link: https://www.atsc.org/wp-content/uploads/2015/03/Program-System-Information-Protocol-for-Terrestrial-Broadcast-and-Cable.pdf
+const (
+	VCTModulationModeAnalog    = 0x1
+	VCTModulationModeSCTEMode1 = 0x2 // 64-QAM
+	VCTModulationModeSCTEMode2 = 0x3 // 256-QAM
+	VCTModulationMode8VSB      = 0x4
+	VCTModulationMode16VSB     = 0x5
+)
+
+// VCT service types
+// Page: 38 | Chapter: 6.3.1 | Link: https://www.atsc.org/wp-content/uploads/2015/03/Program-System-Information-Protocol-for-Terrestrial-Broadcast-and-Cable.pdf
+const (
+	VCTServiceTypeAnalogTelevision  = 0x1
+	VCTServiceTypeDigitalTelevision = 0x2
+	VCTServiceTypeAudio             = 0x3
+	VCTServiceTypeDataOnly          = 0x4
+)
+
+// VCT ETM locations
+const (
+	VCTETMLocationNone        = 0x0
+	VCTETMLocationThisPTC     = 0x1 // ETM is located in the PTC carrying this PSIP
+	VCTETMLocationChannelTSID = 0x2 // ETM is located in the PTC specified by the channel TSID
+)
+
+// VCTData represents a TVCT or CVCT data
+// Both tables share the same structure except for the path select and out of band fields that are only relevant in
+// the CVCT
+// Page: 32 | Chapter: 6.3 | Link: https://www.atsc.org/wp-content/uploads/2015/03/Program-System-Information-Protocol-for-Terrestrial-Broadcast-and-Cable.pdf
+type VCTData struct {
+	AdditionalDescriptors []*Descriptor
+	Channels              []*VCTDataChannel
+	ProtocolVersion       uint8
+	TransportStreamID     uint16
+}
+
+// VCTDataChannel represents a VCT data channel
+type VCTDataChannel struct {
+	AccessControlled   bool
+	CarrierFrequency   uint32
+	ChannelTSID        uint16
+	Descriptors        []*Descriptor
+	ETMLocation        uint8
+	Hidden             bool
+	HideGuide          bool
+	MajorChannelNumber uint16
+	MinorChannelNumber uint16
+	ModulationMode     uint8
+	OutOfBand          bool  // CVCT only
+	PathSelect         uint8 // CVCT only
+	ProgramNumber      uint16
+	ServiceType        uint8
+	ShortName          string
+	SourceID           uint16
+}
+
+// parseVCTSection parses a TVCT or CVCT section
+func parseVCTSection(i []byte, offset *int, tableIDExtension uint16, isCable bool) (d *VCTData) {
+	// Init
+	d = &VCTData{TransportStreamID: tableIDExtension}
+
+	// Protocol version
+	d.ProtocolVersion = uint8(i[*offset])
+	*offset += 1
+
+	// Number of channels in section
+	var numChannels = int(i[*offset])
+	*offset += 1
+
+	// Loop through channels
+	for idx := 0; idx < numChannels; idx++ {
+		// Short name
+		var c = &VCTDataChannel{}
+		c.ShortName = strings.TrimRight(parseUTF16String(i[*offset:*offset+14]), "\x00")
+		*offset += 14
+
+		// Major and minor channel numbers
+		c.MajorChannelNumber = uint16(i[*offset]&0xf)<<6 | uint16(i[*offset+1]>>2)
+		c.MinorChannelNumber = uint16(i[*offset+1]&0x3)<<8 | uint16(i[*offset+2])
+		*offset += 3
+
+		// Modulation mode
+		c.ModulationMode = uint8(i[*offset])
+		*offset += 1
+
+		// Carrier frequency
+		c.CarrierFrequency = uint32(i[*offset])<<24 | uint32(i[*offset+1])<<16 | uint32(i[*offset+2])<<8 | uint32(i[*offset+3])
+		*offset += 4
+
+		// Channel TSID
+		c.ChannelTSID = uint16(i[*offset])<<8 | uint16(i[*offset+1])
+		*offset += 2
+
+		// Program number
+		c.ProgramNumber = uint16(i[*offset])<<8 | uint16(i[*offset+1])
+		*offset += 2
+
+		// ETM location
+		c.ETMLocation = uint8(i[*offset] >> 6)
+
+		// Access controlled
+		c.AccessControlled = i[*offset]&0x20 > 0
+
+		// Hidden
+		c.Hidden = i[*offset]&0x10 > 0
+
+		// Path select and out of band
+		if isCable {
+			c.PathSelect = uint8(i[*offset]>>3) & 0x1
+			c.OutOfBand = i[*offset]&0x4 > 0
+		}
+
+		// Hide guide
+		c.HideGuide = i[*offset]&0x2 > 0
+		*offset += 1
+
+		// Service type
+		c.ServiceType = uint8(i[*offset] & 0x3f)
+		*offset += 1
+
+		// Source ID
+		c.SourceID = uint16(i[*offset])<<8 | uint16(i[*offset+1])
+		*offset += 2
+
+		// Descriptors
+		c.Descriptors = parseATSCDescriptors(i, offset)
+
+		// Append channel
+		d.Channels = append(d.Channels, c)
+	}
+
+	// Additional descriptors
+	d.AdditionalDescriptors = parseATSCDescriptors(i, offset)
+	return
+}
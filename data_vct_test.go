@@ -0,0 +1,74 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/asticode/go-astitools/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+func vctBytes() []byte {
+	w := astibinary.New()
+	w.Write(uint8(0))                                                             // Protocol version
+	w.Write(uint8(1))                                                             // Number of channels in section
+	w.Write([]byte{0x0, 0x4b, 0x0, 0x41, 0x0, 0x42, 0x0, 0x43, 0, 0, 0, 0, 0, 0}) // Channel #1 short name
+	w.Write("1111")                                                               // Channel #1 reserved
+	w.Write("0000000111")                                                         // Channel #1 major channel number
+	w.Write("0000000010")                                                         // Channel #1 minor channel number
+	w.Write(uint8(VCTModulationMode8VSB))                                         // Channel #1 modulation mode
+	w.Write(uint32(0))                                                            // Channel #1 carrier frequency
+	w.Write(uint16(2))                                                            // Channel #1 channel TSID
+	w.Write(uint16(3))                                                            // Channel #1 program number
+	w.Write("01")                                                                 // Channel #1 ETM location
+	w.Write("1")                                                                  // Channel #1 access controlled
+	w.Write("0")                                                                  // Channel #1 hidden
+	w.Write("1")                                                                  // Channel #1 path select (reserved in the TVCT)
+	w.Write("1")                                                                  // Channel #1 out of band (reserved in the TVCT)
+	w.Write("1")                                                                  // Channel #1 hide guide
+	w.Write("111")                                                                // Channel #1 reserved
+	w.Write("000010")                                                             // Channel #1 service type
+	w.Write(uint16(4))                                                            // Channel #1 source ID
+	w.Write("111111")                                                             // Channel #1 reserved
+	w.Write("0000000011")                                                         // Channel #1 descriptors length
+	w.Write(uint8(DescriptorTagStreamIdentifier))                                 // Channel #1 descriptor tag
+	w.Write(uint8(1))                                                             // Channel #1 descriptor length
+	w.Write(uint8(7))                                                             // Channel #1 descriptor component tag
+	w.Write("111111")                                                             // Reserved
+	w.Write("0000000000")                                                         // Additional descriptors length
+	return w.Bytes()
+}
+
+func vct(isCable bool) *VCTData {
+	var c = &VCTDataChannel{
+		AccessControlled:   true,
+		ChannelTSID:        2,
+		Descriptors:        descriptors,
+		ETMLocation:        VCTETMLocationThisPTC,
+		HideGuide:          true,
+		MajorChannelNumber: 7,
+		MinorChannelNumber: 2,
+		ModulationMode:     VCTModulationMode8VSB,
+		ProgramNumber:      3,
+		ServiceType:        VCTServiceTypeDigitalTelevision,
+		ShortName:          "KABC",
+		SourceID:           4,
+	}
+	if isCable {
+		c.OutOfBand = true
+		c.PathSelect = 1
+	}
+	return &VCTData{
+		Channels:          []*VCTDataChannel{c},
+		TransportStreamID: 1,
+	}
+}
+
+func TestParseVCTSection(t *testing.T) {
+	for _, isCable := range []bool{false, true} {
+		var offset int
+		var b = vctBytes()
+		d := parseVCTSection(b, &offset, uint16(1), isCable)
+		assert.Equal(t, vct(isCable), d)
+		assert.Equal(t, len(b), offset)
+	}
+}
@@ -3,7 +3,9 @@ package astits
 import (
 	"context"
 	"io"
+	"time"
 
+	"github.com/asticode/go-astilog"
 	"github.com/pkg/errors"
 )
 
@@ -13,22 +15,64 @@ const syncByte = '\x47'
 // Errors
 var (
 	ErrNoMorePackets                = errors.New("astits: no more packets")
+	ErrPacketInvalidParity          = errors.New("astits: packet has an invalid parity")
 	ErrPacketMustStartWithASyncByte = errors.New("astits: packet must start with a sync byte")
 )
 
+// Transport error policies
+const (
+	TransportErrorPolicyFlag = "flag" // Packets with their transport error indicator set are processed and the data parsed from them is flagged with TransportError
+	TransportErrorPolicySkip = "skip" // Packets with their transport error indicator set are dropped the same way lost packets would be
+)
+
 // Demuxer represents a demuxer
 // https://en.wikipedia.org/wiki/MPEG_transport_stream
 // http://seidl.cs.vsb.cz/download/dvb/DVB_Poster.pdf
 // http://www.etsi.org/deliver/etsi_en/300400_300499/300468/01.13.01_40/en_300468v011301o.pdf
 type Demuxer struct {
-	ctx              context.Context
-	dataBuffer       []*Data
-	optPacketSize    int
-	optPacketsParser PacketsParser
-	packetBuffer     *packetBuffer
-	packetPool       *packetPool
-	programMap       programMap
-	r                io.Reader
+	ancPIDs                      map[uint16]bool            // SMPTE ST 2038 ancillary data PIDs announced in the PMTs
+	caSystemIDs                  map[uint16]uint16          // CA system IDs indexed by the ECM and EMM PIDs announced in the CAT and the PMTs
+	clockUnwrappers              map[uint16]*ClockUnwrapper // Indexed by PCR PID
+	continuityChecker            *continuityChecker
+	continuityErrors             int64
+	ctx                          context.Context
+	dataBuffer                   []*Data
+	metadataTracker              *metadataTracker
+	mpeDatagrams                 map[mpeKey]*mpeDatagram // Datagrams split over several MPE sections being reassembled
+	optCRCMode                   string
+	optDescrambler               Descrambler
+	optLogger                    astilog.Logger
+	optParityCheck               bool
+	optPacketBufferSize          int
+	optPacketEventHandler        PacketEventHandler
+	optPacketSize                int
+	optPacketsParser             PacketsParser
+	optPIDFilter                 *pidFilter
+	optProgramFilter             *programFilter
+	optResyncPackets             int
+	optStreamBufferSize          int
+	optTableVersionChangeHandler TableVersionChangeHandler
+	optTextDecoder               TextDecoder
+	optTransportErrorPolicy      string
+	optUnwrapClocks              bool
+	optZeroCopy                  bool
+	packetBuffer                 *packetBuffer
+	packetPool                   *packetPool
+	pcrTracker                   *pcrTracker
+	pidTracker                   *pidTracker
+	programMap                   programMap
+	programTracker               *programTracker
+	r                            io.Reader
+	sectionHandlers              sectionHandlers
+	sectionMap                   programMap // Indexed by PID, contains the table type announced in the ATSC MGT, the stream type announced in the PMT or the CA system ID of ECM and EMM PIDs
+	skippedBytes                 int64
+	statsCollector               *statsCollector
+	streamTimeTracker            *streamTimeTracker
+	t2miTracker                  *t2miTracker
+	tableVersionTracker          *tableVersionTracker
+	transportErrors              int64
+	uleTracker                   *uleTracker
+	watchDone                    chan struct{} // Closed to stop watching the context, nil when it's not watched
 }
 
 // PacketsParser represents an object capable of parsing a set of packets containing a unique payload spanning over those packets
@@ -39,10 +83,30 @@ type PacketsParser func(ps []*Packet) (ds []*Data, skip bool, err error)
 func New(ctx context.Context, r io.Reader, opts ...func(*Demuxer)) (d *Demuxer) {
 	// Init
 	d = &Demuxer{
-		ctx:        ctx,
-		packetPool: newPacketPool(),
-		programMap: newProgramMap(),
-		r:          r,
+		ancPIDs:                 make(map[uint16]bool),
+		caSystemIDs:             make(map[uint16]uint16),
+		clockUnwrappers:         make(map[uint16]*ClockUnwrapper),
+		continuityChecker:       newContinuityChecker(),
+		ctx:                     ctx,
+		metadataTracker:         newMetadataTracker(),
+		mpeDatagrams:            make(map[mpeKey]*mpeDatagram),
+		optCRCMode:              CRCModeError,
+		optLogger:               astilog.GetLogger(),
+		optTextDecoder:          NewDVBTextDecoder(),
+		optTransportErrorPolicy: TransportErrorPolicySkip,
+		packetPool:              newPacketPool(),
+		pcrTracker:              newPCRTracker(),
+		pidTracker:              newPIDTracker(),
+		programMap:              newProgramMap(),
+		programTracker:          newProgramTracker(),
+		r:                       r,
+		sectionHandlers:         make(sectionHandlers),
+		sectionMap:              newProgramMap(),
+		statsCollector:          newStatsCollector(),
+		streamTimeTracker:       newStreamTimeTracker(),
+		t2miTracker:             newT2MITracker(),
+		tableVersionTracker:     newTableVersionTracker(),
+		uleTracker:              newULETracker(),
 	}
 
 	// Apply options
@@ -52,6 +116,49 @@ func New(ctx context.Context, r io.Reader, opts ...func(*Demuxer)) (d *Demuxer)
 	return
 }
 
+// OptCRCMode returns the option to set how sections with an invalid CRC32 are processed
+// By default, the parsing of their payload fails and NextData returns an error
+func OptCRCMode(m string) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optCRCMode = m
+	}
+}
+
+// OptDescrambler returns the option to set the descrambler used by NextData to descramble the payload of scrambled
+// packets before parsing them
+// Packets returned by NextPacket are left untouched and statistics reflect the scrambling status of the stream
+func OptDescrambler(d Descrambler) func(*Demuxer) {
+	return func(dmx *Demuxer) {
+		dmx.optDescrambler = d
+	}
+}
+
+// OptLogger returns the option to set the logger
+// By default, the global astilog logger is used
+func OptLogger(l astilog.Logger) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optLogger = l
+	}
+}
+
+// OptPacketBufferSize returns the option to set the number of packets read at once from the reader
+// A big buffer reduces the number of reads when analyzing files whereas live applications may prefer reading packets
+// one by one to reduce latency, which is the default behavior
+func OptPacketBufferSize(packets int) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optPacketBufferSize = packets
+	}
+}
+
+// OptPacketEventHandler returns the option to set the handler called when the continuity counter of a packet returned
+// by NextPacket is not the expected one, either because packets have been lost or because a packet is duplicated
+// Discontinuities signaled by the discontinuity indicator of the adaptation field don't trigger events
+func OptPacketEventHandler(h PacketEventHandler) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optPacketEventHandler = h
+	}
+}
+
 // OptPacketSize returns the option to set the packet size
 func OptPacketSize(packetSize int) func(*Demuxer) {
 	return func(d *Demuxer) {
@@ -66,33 +173,247 @@ func OptPacketsParser(p PacketsParser) func(*Demuxer) {
 	}
 }
 
+// OptParityCheck returns the option to check the Reed-Solomon parity bytes of 204 and 208 bytes packets
+// When a packet has an invalid parity, ErrPacketInvalidParity is returned and the demuxer moves on to the next packet
+func OptParityCheck() func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optParityCheck = true
+	}
+}
+
+// OptPIDBlacklist returns the option to drop packets of the provided PIDs as soon as they're read, before their
+// adaptation field and payload are parsed
+func OptPIDBlacklist(pids ...uint16) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optPIDFilter = newPIDFilter(false, pids...)
+	}
+}
+
+// OptPIDWhitelist returns the option to restrict processing to packets of the provided PIDs
+// Packets of other PIDs are dropped as soon as they're read, before their adaptation field and payload are parsed, and
+// are therefore not taken into account in stats nor continuity checks. Tables are only parsed if their PIDs are part
+// of the whitelist, which means the PAT and PMT PIDs need to be whitelisted too when programs are needed.
+func OptPIDWhitelist(pids ...uint16) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optPIDFilter = newPIDFilter(true, pids...)
+	}
+}
+
+// OptProgramFilter returns the option to only process the packets of a single program, also known as the service ID
+// in DVB streams
+// The demuxer follows the PAT to find the PMT of the program and then only processes the PAT, the PMT, the PCR PID and
+// the elementary PIDs it announces, which are updated whenever the PMT changes. Other packets are dropped before being
+// parsed the same way they are with OptPIDWhitelist, which this option replaces.
+func OptProgramFilter(programNumber uint16) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optProgramFilter = newProgramFilter(programNumber)
+		d.optPIDFilter = d.optProgramFilter.pids
+	}
+}
+
+// OptResync returns the option to resynchronize the demuxer when a packet doesn't start with a sync byte, which may
+// happen with corrupted captures or UDP drops
+// Bytes are skipped until packets consecutive sync bytes are found at packet size intervals. Skipped bytes are logged
+// and can be retrieved with SkippedBytes.
+func OptResync(packets int) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optResyncPackets = packets
+	}
+}
+
+// OptStreamBufferSize returns the option to set the capacity of the data channel returned by Stream
+// Once the channel is full, the demuxer stops reading until the caller consumes data
+func OptStreamBufferSize(size int) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optStreamBufferSize = size
+	}
+}
+
+// OptTableVersionChangeHandler returns the option to set the handler called when the version number of a table
+// changes, for instance when a PMT is updated in a live stream. The handler is called before the data is returned by
+// NextData
+func OptTableVersionChangeHandler(h TableVersionChangeHandler) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optTableVersionChangeHandler = h
+	}
+}
+
+// OptTextDecoder returns the option to set the decoder used to fill the Decoded fields of the descriptors carrying text
+// By default, texts are decoded as described in the annex A of ETSI EN 300 468. Raw bytes are always kept, and a nil
+// decoder leaves the Decoded fields empty
+func OptTextDecoder(d TextDecoder) func(*Demuxer) {
+	return func(dmx *Demuxer) {
+		dmx.optTextDecoder = d
+	}
+}
+
+// OptTransportErrorPolicy returns the option to set how NextData handles packets with their transport error indicator
+// set, which demodulators set when they couldn't correct a packet
+// Such packets are always counted with TransportErrors and returned by NextPacket. By default, they are skipped
+func OptTransportErrorPolicy(p string) func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optTransportErrorPolicy = p
+	}
+}
+
+// OptUnwrapClocks returns the option to unwrap the 33 bits PCR, PTS and DTS of PES data into a continuous timeline per
+// program, so that they don't jump backwards every 26.5 hours in long captures
+// Clocks of PIDs whose program is unknown are unwrapped per PID. PCRs returned by PCR and adaptation fields are not
+// unwrapped
+func OptUnwrapClocks() func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optUnwrapClocks = true
+	}
+}
+
+// OptZeroCopy returns the option to enable the zero copy mode
+// In this mode, packets returned by NextPacket slice directly into a read buffer that is reused for the next
+// packet: they are only valid until the next call to NextPacket and must be cloned with Packet.Clone to be retained.
+// NextData is not impacted since it clones the packets it needs to retain.
+func OptZeroCopy() func(*Demuxer) {
+	return func(d *Demuxer) {
+		d.optZeroCopy = true
+	}
+}
+
 // NextPacket retrieves the next packet
+// If the context is cancelled, its error is returned as is, even if it has been cancelled during a blocking read. Reads
+// of readers implementing SetReadDeadline (such as net.Conn) are unblocked as soon as the context is cancelled, other
+// readers need to be closed by the caller once the context is cancelled.
 func (dmx *Demuxer) NextPacket() (p *Packet, err error) {
 	// Check ctx error
-	// TODO Handle ctx error another way since if the read blocks, everything blocks
-	// Maybe execute everything in a goroutine and listen the ctx channel in the same for loop
 	if err = dmx.ctx.Err(); err != nil {
 		return
 	}
 
 	// Create packet buffer if not exists
 	if dmx.packetBuffer == nil {
-		if dmx.packetBuffer, err = newPacketBuffer(dmx.r, dmx.optPacketSize); err != nil {
+		// Unblock reads when the context is cancelled
+		dmx.watchContext()
+
+		// Create packet buffer
+		if dmx.packetBuffer, err = newPacketBuffer(dmx.r, dmx.optPacketSize, dmx.optPacketBufferSize, dmx.optResyncPackets, dmx.optZeroCopy, dmx.optPIDFilter); err != nil {
+			if ctxErr := dmx.ctx.Err(); ctxErr != nil {
+				err = ctxErr
+				return
+			}
 			err = errors.Wrap(err, "astits: creating packet buffer failed")
 			return
 		}
 	}
 
 	// Fetch next packet from buffer
-	if p, err = dmx.packetBuffer.next(); err != nil {
-		if err != ErrNoMorePackets {
+	p, err = dmx.packetBuffer.next()
+
+	// Report skipped bytes
+	if n := dmx.packetBuffer.skippedBytes; n > 0 {
+		dmx.skippedBytes += int64(n)
+		dmx.optLogger.Warnf("astits: skipped %d bytes to resync", n)
+	}
+
+	// Process error
+	if err != nil {
+		if ctxErr := dmx.ctx.Err(); ctxErr != nil {
+			err = ctxErr
+		} else if err == ErrNoMorePackets {
+			dmx.stopWatchingContext()
+		} else {
 			err = errors.Wrap(err, "astits: fetching next packet from buffer failed")
 		}
 		return
 	}
+
+	// Check parity
+	if dmx.optParityCheck && !p.HasValidParity() {
+		p = nil
+		err = ErrPacketInvalidParity
+		return
+	}
+
+	// Update stats
+	dmx.pidTracker.add(p)
+	dmx.statsCollector.add(p)
+
+	// Count transport errors
+	if p.Header.TransportErrorIndicator {
+		dmx.transportErrors++
+	}
+
+	// Check continuity
+	if e := dmx.continuityChecker.check(p); e != nil {
+		if e.Type == PacketEventTypeContinuityError {
+			dmx.continuityErrors++
+		}
+		if dmx.optPacketEventHandler != nil {
+			// The packet is reused once closed or, in zero copy mode, once the next packet is fetched
+			e.Packet = e.Packet.Clone()
+			dmx.optPacketEventHandler(e)
+		}
+	}
 	return
 }
 
+// readDeadliner represents an object capable of unblocking a pending read such as a net.Conn
+type readDeadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+// watchContext makes sure pending reads are unblocked when the context is cancelled, if the reader allows it
+// The watcher runs until the context is cancelled, there are no more packets, the demuxer is rewound or it is closed
+func (dmx *Demuxer) watchContext() {
+	// Context can't be cancelled or watcher is already running
+	if dmx.ctx.Done() == nil || dmx.watchDone != nil {
+		return
+	}
+
+	// Reader can't be unblocked
+	rd, ok := dmx.r.(readDeadliner)
+	if !ok {
+		return
+	}
+
+	// Watch
+	var done = make(chan struct{})
+	dmx.watchDone = done
+	go func() {
+		select {
+		case <-dmx.ctx.Done():
+			rd.SetReadDeadline(time.Now())
+		case <-done:
+		}
+	}()
+}
+
+// stopWatchingContext stops the context watcher, if any
+func (dmx *Demuxer) stopWatchingContext() {
+	if dmx.watchDone != nil {
+		close(dmx.watchDone)
+		dmx.watchDone = nil
+	}
+}
+
+// Close releases the resources of the demuxer, which is only needed when it's not read until there are no more
+// packets and its context is not cancelled
+// The reader is not closed
+func (dmx *Demuxer) Close() {
+	dmx.stopWatchingContext()
+}
+
+// ContinuityErrors returns the total number of continuity errors detected, duplicate packets excluded
+func (dmx *Demuxer) ContinuityErrors() int64 {
+	return dmx.continuityErrors
+}
+
+// SkippedBytes returns the total number of bytes skipped to resync the demuxer
+func (dmx *Demuxer) SkippedBytes() int64 {
+	return dmx.skippedBytes
+}
+
+// TransportErrors returns the total number of packets with their transport error indicator set
+func (dmx *Demuxer) TransportErrors() int64 {
+	return dmx.transportErrors
+}
+
 // NextData retrieves the next data
 func (dmx *Demuxer) NextData() (d *Data, err error) {
 	// Check data buffer
@@ -109,24 +430,78 @@ func (dmx *Demuxer) NextData() (d *Data, err error) {
 	for {
 		// Get next packet
 		if p, err = dmx.NextPacket(); err != nil {
-			// We don't dump the packet pool since we don't want incomplete data
 			if err == ErrNoMorePackets {
+				// The last PES of each PID is only terminated by the end of the stream
+				if ds, err = dmx.dumpPESPackets(); err != nil {
+					err = errors.Wrap(err, "astits: dumping PES packets failed")
+					return
+				} else if len(ds) > 0 {
+					d = ds[0]
+					dmx.dataBuffer = append(dmx.dataBuffer, ds[1:]...)
+					return
+				}
+				err = ErrNoMorePackets
 				return
+			} else if err == dmx.ctx.Err() {
+				return
+			} else if err == ErrPacketInvalidParity {
+				// Corrupted packets are dropped the same way lost packets would be
+				dmx.optLogger.Debugf("astits: dropping packet with an invalid parity")
+				err = nil
+				continue
 			}
 			err = errors.Wrap(err, "astits: fetching next packet failed")
 			return
 		}
 
+		// Skip packets with a transport error
+		if p.Header.TransportErrorIndicator && dmx.optTransportErrorPolicy == TransportErrorPolicySkip {
+			dmx.optLogger.Debugf("astits: dropping packet with a transport error")
+			continue
+		}
+
+		// Packets are retained by the pool
+		if dmx.optZeroCopy {
+			p = p.Clone()
+		}
+
+		// Descramble
+		if err = dmx.descramble(p); err != nil {
+			err = errors.Wrap(err, "astits: descrambling packet failed")
+			return
+		}
+
+		// Update program clocks
+		var pcr = dmx.pcrTracker.add(p)
+
 		// Add packet to the pool
 		if ps = dmx.packetPool.add(p); len(ps) == 0 {
 			continue
 		}
 
-		// Parse data
-		if ds, err = parseData(ps, dmx.optPacketsParser, dmx.programMap); err != nil {
+		// Parse data. T2-MI and ULE streams are parsed on their own since their payload may look like PES one
+		if _, ok := dmx.t2miTracker.pids[ps[0].Header.PID]; ok {
+			ds = dmx.parseT2MIData(ps)
+		} else if _, ok := dmx.uleTracker.pids[ps[0].Header.PID]; ok {
+			ds = dmx.parseULEData(ps)
+		} else if ds, err = parseData(ps, dmx.optPacketsParser, dmx.programMap, dmx.sectionMap, dmx.sectionHandlers, dmx.optCRCMode, dmx.optLogger); err != nil {
 			err = errors.Wrap(err, "astits: building new data failed")
 			return
 		}
+		dmx.updateCAMessageData(ds)
+		dmx.updateMPEData(ds)
+		dmx.updatePESData(ds, pcr)
+		dmx.updateANCData(ds)
+		dmx.updateMetadataData(ds)
+		dmx.flagTransportErrors(ds, ps)
+
+		// Release packets that are not referenced by the data anymore. When a custom packets parser is used, packets
+		// may have been retained, therefore they are not released
+		if dmx.optPacketsParser == nil {
+			for _, p := range ps[1:] {
+				p.Close()
+			}
+		}
 
 		// Check whether there is data to be processed
 		if len(ds) > 0 {
@@ -134,9 +509,25 @@ func (dmx *Demuxer) NextData() (d *Data, err error) {
 			d = ds[0]
 			dmx.dataBuffer = append(dmx.dataBuffer, ds[1:]...)
 
-			// Update program map
+			// Update program and section maps, and decode texts
 			for _, v := range ds {
+				if dmx.optTextDecoder != nil {
+					if err = decodeDataTexts(v, dmx.optTextDecoder); err != nil {
+						// Texts are not mandatory, therefore we only log the error and move on
+						dmx.optLogger.Debugf("astits: decoding texts of PID %d failed: %s", v.PID, err)
+						err = nil
+					}
+				}
+
+				// Tables that are not applicable yet don't update the demuxer state
+				if v.IsNext {
+					continue
+				}
 				if v.PAT != nil {
+					dmx.programTracker.setPAT(v.PAT)
+					if dmx.optProgramFilter != nil {
+						dmx.optProgramFilter.setPAT(v.PAT)
+					}
 					for _, pgm := range v.PAT.Programs {
 						// Program number 0 is reserved to NIT
 						if pgm.ProgramNumber > 0 {
@@ -144,17 +535,192 @@ func (dmx *Demuxer) NextData() (d *Data, err error) {
 						}
 					}
 				}
+				if v.CAT != nil {
+					dmx.setCAPIDs(v.CAT.EMMPIDs())
+				}
+				if v.MGT != nil {
+					for _, t := range v.MGT.Tables {
+						if isMGTTableTypeOnOwnPID(t.Type) {
+							dmx.sectionMap.set(t.PID, t.Type)
+						}
+					}
+				}
+				if v.PMT != nil {
+					dmx.metadataTracker.setProgram(v.PMT)
+					dmx.t2miTracker.setProgram(v.PMT)
+					dmx.uleTracker.setProgram(v.PMT)
+					dmx.pcrTracker.setProgram(v.PMT)
+					dmx.pidTracker.setProgram(v.PMT)
+					dmx.programTracker.setPMT(v.PID, v.PMT)
+					if dmx.optProgramFilter != nil {
+						dmx.optProgramFilter.setPMT(v.PID, v.PMT)
+					}
+					dmx.setCAPIDs(v.PMT.ECMPIDs())
+					dmx.statsCollector.setProgram(v.PID, v.PMT)
+					for _, es := range v.PMT.ElementaryStreams {
+						if es.StreamType == StreamTypeMPEG2PacketizedData && hasRegistrationDescriptor(es, RegistrationFormatIdentifierVANC) {
+							dmx.ancPIDs[es.ElementaryPID] = true
+						}
+						if es.StreamType == StreamTypeSCTE35 || es.StreamType == StreamTypeDSMCCMPE || es.StreamType == StreamTypeDSMCCSections || es.StreamType == StreamTypeDSMCCUNMessages || hasApplicationSignallingDescriptor(es) {
+							dmx.sectionMap.set(es.ElementaryPID, uint16(es.StreamType))
+						}
+					}
+				}
+				if v.TDT != nil {
+					dmx.streamTimeTracker.setTDT(v.TDT)
+				}
+				if v.TOT != nil {
+					dmx.streamTimeTracker.setTOT(v.TOT)
+				}
+			}
+
+			// Notify table version changes once maps are up to date
+			for _, v := range ds {
+				if c := dmx.tableVersionTracker.update(v); c != nil && dmx.optTableVersionChangeHandler != nil {
+					dmx.optTableVersionChangeHandler(c)
+				}
 			}
 			return
 		}
 	}
 }
 
+// dumpPESPackets dumps the packet pool and parses the PES data it contains
+// We don't parse the PSI data left in the pool since we don't want incomplete tables. PES data that is cut off is
+// flagged as truncated
+func (dmx *Demuxer) dumpPESPackets() (ds []*Data, err error) {
+	for {
+		// Dump packets
+		var ps = dmx.packetPool.dump()
+		if len(ps) == 0 {
+			return
+		} else if isPSIPayload(ps[0].Header.PID, dmx.programMap, dmx.sectionMap) {
+			continue
+		}
+
+		// Parse data
+		var pds []*Data
+		if _, ok := dmx.t2miTracker.pids[ps[0].Header.PID]; ok {
+			pds = dmx.parseT2MIData(ps)
+		} else if _, ok := dmx.uleTracker.pids[ps[0].Header.PID]; ok {
+			pds = dmx.parseULEData(ps)
+		} else if pds, err = parseData(ps, dmx.optPacketsParser, dmx.programMap, dmx.sectionMap, dmx.sectionHandlers, dmx.optCRCMode, dmx.optLogger); err != nil {
+			err = errors.Wrap(err, "astits: building new data failed")
+			return
+		}
+		dmx.updatePESData(pds, dmx.pcrTracker.units[ps[0].Header.PID])
+		dmx.updateANCData(pds)
+		dmx.updateMetadataData(pds)
+		dmx.flagTransportErrors(pds, ps)
+		ds = append(ds, pds...)
+	}
+}
+
+// setCAPIDs routes the PIDs carrying ECMs or EMMs to the PSI parser and stores their CA system ID
+func (dmx *Demuxer) setCAPIDs(pids map[uint16][]uint16) {
+	for caSystemID, ps := range pids {
+		for _, pid := range ps {
+			dmx.caSystemIDs[pid] = caSystemID
+			dmx.sectionMap.set(pid, caSystemID)
+		}
+	}
+}
+
+// flagTransportErrors flags data if one of the packets it was parsed from has a transport error and the policy asks
+// for it
+func (dmx *Demuxer) flagTransportErrors(ds []*Data, ps []*Packet) {
+	if dmx.optTransportErrorPolicy != TransportErrorPolicyFlag {
+		return
+	}
+	for _, p := range ps {
+		if p.Header.TransportErrorIndicator {
+			for _, d := range ds {
+				d.TransportError = true
+			}
+			return
+		}
+	}
+}
+
+// Stream fetches data in a goroutine and sends it to the returned data channel
+// Both channels are closed once there are no more packets, once ctx or the demuxer context is cancelled, or once an
+// error occurred in which case the error is sent to the error channel before
+// The demuxer must not be used concurrently while it's streaming
+func (dmx *Demuxer) Stream(ctx context.Context) (<-chan *Data, <-chan error) {
+	var ds = make(chan *Data, dmx.optStreamBufferSize)
+	var errs = make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(ds)
+		for {
+			// Fetch next data
+			d, err := dmx.NextData()
+			if err != nil {
+				if err != ErrNoMorePackets && err != dmx.ctx.Err() {
+					errs <- err
+				}
+				return
+			}
+
+			// Send data
+			select {
+			case ds <- d:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ds, errs
+}
+
+// ExtractES fetches data until there are no more packets and writes the payload of every PES of the PID to w
+// This dumps the raw elementary stream of the PID, for instance H.264 or AAC, the PES being reassembled by the demuxer.
+// Data of other PIDs is discarded and n is the number of bytes written.
+// The demuxer must not be used concurrently while it's extracting
+func (dmx *Demuxer) ExtractES(pid uint16, w io.Writer) (n int64, err error) {
+	for {
+		// Fetch next data
+		var d *Data
+		if d, err = dmx.NextData(); err != nil {
+			if err == ErrNoMorePackets || err == dmx.ctx.Err() {
+				err = nil
+			}
+			return
+		}
+
+		// Write payload
+		if d.PID != pid || d.PES == nil {
+			continue
+		}
+		var nw int
+		nw, err = w.Write(d.PES.Data)
+		n += int64(nw)
+		if err != nil {
+			err = errors.Wrap(err, "astits: writing PES payload failed")
+			return
+		}
+	}
+}
+
 // Rewind rewinds the demuxer reader
 func (dmx *Demuxer) Rewind() (n int64, err error) {
+	dmx.clockUnwrappers = make(map[uint16]*ClockUnwrapper)
+	dmx.continuityChecker = newContinuityChecker()
+	dmx.continuityErrors = 0
 	dmx.dataBuffer = []*Data{}
+	dmx.metadataTracker.reset()
+	dmx.mpeDatagrams = make(map[mpeKey]*mpeDatagram)
 	dmx.packetBuffer = nil
 	dmx.packetPool = newPacketPool()
+	dmx.pcrTracker = newPCRTracker()
+	dmx.pidTracker = newPIDTracker()
+	dmx.skippedBytes = 0
+	dmx.statsCollector = newStatsCollector()
+	dmx.t2miTracker.reset()
+	dmx.tableVersionTracker = newTableVersionTracker()
+	dmx.transportErrors = 0
+	dmx.uleTracker.reset()
+	dmx.stopWatchingContext()
 	if n, err = rewind(dmx.r); err != nil {
 		err = errors.Wrap(err, "astits: rewinding reader failed")
 		return
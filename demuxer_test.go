@@ -4,8 +4,13 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"net"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/asticode/go-astilog"
 	"github.com/asticode/go-astitools/binary"
 	"github.com/stretchr/testify/assert"
 )
@@ -13,7 +18,11 @@ import (
 func TestDemuxerNew(t *testing.T) {
 	ps := 1
 	pp := func(ps []*Packet) (ds []*Data, skip bool, err error) { return }
-	dmx := New(context.Background(), nil, OptPacketSize(ps), OptPacketsParser(pp))
+	l := astilog.NopLogger()
+	dmx := New(context.Background(), nil, OptLogger(l), OptPacketSize(ps), OptPacketsParser(pp))
+	assert.Equal(t, CRCModeError, dmx.optCRCMode)
+	assert.Equal(t, TransportErrorPolicySkip, dmx.optTransportErrorPolicy)
+	assert.Equal(t, l, dmx.optLogger)
 	assert.Equal(t, ps, dmx.optPacketSize)
 	assert.Equal(t, fmt.Sprintf("%p", pp), fmt.Sprintf("%p", dmx.optPacketsParser))
 }
@@ -50,6 +59,186 @@ func TestDemuxerNextPacket(t *testing.T) {
 	assert.EqualError(t, err, ErrNoMorePackets.Error())
 }
 
+func TestDemuxerNextPacketCancelledDuringRead(t *testing.T) {
+	// Reader with read deadline
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	dmx := New(ctx, c1)
+	time.AfterFunc(10*time.Millisecond, cancel)
+	_, err := dmx.NextPacket()
+	assert.Equal(t, context.Canceled, err)
+
+	// Reader closed by the caller
+	r, w := io.Pipe()
+	defer w.Close()
+	ctx, cancel = context.WithCancel(context.Background())
+	dmx = New(ctx, r)
+	time.AfterFunc(10*time.Millisecond, func() {
+		cancel()
+		r.Close()
+	})
+	_, err = dmx.NextData()
+	assert.Equal(t, context.Canceled, err)
+
+	// Watcher stops when there are no more packets
+	c1, c2 = net.Pipe()
+	defer c1.Close()
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	dmx = New(ctx, c1, OptPacketSize(MpegTsPacketSize))
+	go func() {
+		b := make([]byte, MpegTsPacketSize)
+		b[0] = syncByte
+		c2.Write(b)
+		c2.Write(b)
+		c2.Close()
+	}()
+	for idx := 0; idx < 2; idx++ {
+		_, err = dmx.NextPacket()
+		assert.NoError(t, err)
+		assert.NotNil(t, dmx.watchDone)
+	}
+	_, err = dmx.NextPacket()
+	assert.Equal(t, ErrNoMorePackets, err)
+	assert.Nil(t, dmx.watchDone)
+
+	// Watcher stops when the demuxer is closed
+	dmx = New(ctx, c1)
+	dmx.watchContext()
+	assert.NotNil(t, dmx.watchDone)
+	dmx.Close()
+	assert.Nil(t, dmx.watchDone)
+}
+
+func TestDemuxerPacketBufferSize(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	b1, p1 := packet(*packetHeader, *packetAdaptationField, []byte("1"))
+	w.Write(b1)
+	b2, p2 := packet(*packetHeader, *packetAdaptationField, []byte("2"))
+	w.Write(b2)
+	r := bytes.NewReader(w.Bytes())
+	dmx := New(context.Background(), r, OptPacketBufferSize(10))
+	assert.Equal(t, 10, dmx.optPacketBufferSize)
+
+	// Both packets are read at once
+	p, err := dmx.NextPacket()
+	assert.NoError(t, err)
+	assert.Equal(t, p1, p)
+	assert.Equal(t, 0, r.Len())
+	p, err = dmx.NextPacket()
+	assert.NoError(t, err)
+	assert.Equal(t, p2, p)
+	_, err = dmx.NextPacket()
+	assert.EqualError(t, err, ErrNoMorePackets.Error())
+}
+
+func TestDemuxerResync(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	b1, p1 := packet(*packetHeader, *packetAdaptationField, []byte("1"))
+	w.Write(b1)
+	b2, p2 := packet(*packetHeader, *packetAdaptationField, []byte("2"))
+	w.Write(b2)
+	w.Write([]byte("garbage"))
+	w.Write(uint8(syncByte))
+	w.Write([]byte("garbage"))
+	b3, p3 := packet(*packetHeader, *packetAdaptationField, []byte("3"))
+	w.Write(b3)
+	b4, p4 := packet(*packetHeader, *packetAdaptationField, []byte("4"))
+	w.Write(b4)
+	w.Write([]byte("end"))
+	dmx := New(context.Background(), bytes.NewReader(w.Bytes()), OptLogger(astilog.NopLogger()), OptResync(2))
+
+	// Packets
+	for _, ep := range []*Packet{p1, p2, p3, p4} {
+		p, err := dmx.NextPacket()
+		assert.NoError(t, err)
+		assert.Equal(t, ep, p)
+	}
+	assert.Equal(t, int64(15), dmx.SkippedBytes())
+
+	// EOF
+	_, err := dmx.NextPacket()
+	assert.EqualError(t, err, ErrNoMorePackets.Error())
+	assert.Equal(t, int64(18), dmx.SkippedBytes())
+
+	// Resync on a single packet
+	w.Reset()
+	w.Write(b1)
+	w.Write([]byte("garbage"))
+	w.Write(b2)
+	dmx = New(context.Background(), bytes.NewReader(w.Bytes()), OptLogger(astilog.NopLogger()), OptPacketSize(M2TSPacketSize), OptResync(1))
+	for _, ep := range []*Packet{p1, p2} {
+		p, err := dmx.NextPacket()
+		assert.NoError(t, err)
+		assert.Equal(t, ep, p)
+	}
+	assert.Equal(t, int64(7), dmx.SkippedBytes())
+}
+
+func TestDemuxerParityCheck(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	b := writePacket(&Packet{Header: &PacketHeader{PID: 256}, Payload: []byte("1")})
+	w.Write(b)
+	w.Write(reedSolomonParity(b, 16))
+	b = writePacket(&Packet{Header: &PacketHeader{PID: 256}, Payload: []byte("2")})
+	w.Write(b)
+	w.Write(make([]byte, 16))
+	b = writePacket(&Packet{Header: &PacketHeader{PID: 256}, Payload: []byte("3")})
+	w.Write(b)
+	w.Write(reedSolomonParity(b, 16))
+	dmx := New(context.Background(), bytes.NewReader(w.Bytes()), OptPacketSize(DVBPacketSize), OptParityCheck())
+
+	// Packets with an invalid parity are reported
+	p, err := dmx.NextPacket()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("1"), p.Payload[len(p.Payload)-1:])
+	_, err = dmx.NextPacket()
+	assert.Equal(t, ErrPacketInvalidParity, err)
+	p, err = dmx.NextPacket()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("3"), p.Payload[len(p.Payload)-1:])
+}
+
+func TestDemuxerZeroCopy(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	b1, p1 := packet(*packetHeader, *packetAdaptationField, []byte("1"))
+	w.Write(b1)
+	b2, p2 := packet(*packetHeader, *packetAdaptationField, []byte("2"))
+	w.Write(b2)
+	dmx := New(context.Background(), bytes.NewReader(w.Bytes()), OptZeroCopy())
+
+	// Packets share the same read buffer
+	p, err := dmx.NextPacket()
+	assert.NoError(t, err)
+	assert.Equal(t, p1, p)
+	c := p.Clone()
+	q, err := dmx.NextPacket()
+	assert.NoError(t, err)
+	assert.Equal(t, p2, q)
+	assert.Equal(t, q.Payload, p.Payload)
+	assert.Equal(t, p1, c)
+
+	// Data
+	w.Reset()
+	b := psiBytes()
+	b1, _ = packet(PacketHeader{ContinuityCounter: uint8(0), PayloadUnitStartIndicator: true, PID: PIDPAT}, PacketAdaptationField{}, b[:147])
+	w.Write(b1)
+	b2, _ = packet(PacketHeader{ContinuityCounter: uint8(1), PID: PIDPAT}, PacketAdaptationField{}, b[147:])
+	w.Write(b2)
+	b3, _ := packet(PacketHeader{ContinuityCounter: uint8(2), PayloadUnitStartIndicator: true, PID: PIDPAT}, PacketAdaptationField{}, []byte{})
+	w.Write(b3)
+	dmx = New(context.Background(), bytes.NewReader(w.Bytes()), OptZeroCopy())
+	d, err := dmx.NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, eit, d.EIT)
+}
+
 func TestDemuxerNextData(t *testing.T) {
 	// Init
 	w := astibinary.New()
@@ -84,11 +273,174 @@ func TestDemuxerNextData(t *testing.T) {
 	assert.EqualError(t, err, ErrNoMorePackets.Error())
 }
 
+func TestDemuxerATSC(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	m := astibinary.New()
+	m.Write(uint8(0))        // Protocol version
+	m.Write(uint16(1))       // Tables defined
+	m.Write(uint16(0x100))   // Table #1 type
+	m.Write("111")           // Table #1 reserved
+	m.Write("1110100000000") // Table #1 PID
+	m.Write("11100000")      // Table #1 reserved and version number
+	m.Write(uint32(0))       // Table #1 number bytes
+	m.Write(uint16(0xf000))  // Table #1 descriptors length
+	m.Write(uint16(0xf000))  // Descriptors length
+	var sh = &PSISectionSyntaxHeader{CurrentNextIndicator: true, TableIDExtension: 1}
+	for _, v := range []struct {
+		b   []byte
+		id  int
+		pid uint16
+	}{
+		{b: m.Bytes(), id: 0xc7, pid: PIDATSCBase},
+		{b: atscEITBytes(), id: 0xcb, pid: 0x1d00},
+	} {
+		var p = writePSIPayload([][]byte{writePSISection(&PSISectionHeader{PrivateBit: true, SectionSyntaxIndicator: true, TableID: v.id}, sh, v.b)})
+		p = append(p, bytes.Repeat([]byte{0xff}, 147-len(p))...)
+		b, _ := packet(PacketHeader{PayloadUnitStartIndicator: true, PID: v.pid}, PacketAdaptationField{}, p)
+		w.Write(b)
+		b, _ = packet(PacketHeader{ContinuityCounter: uint8(1), PayloadUnitStartIndicator: true, PID: v.pid}, PacketAdaptationField{}, []byte{})
+		w.Write(b)
+	}
+	dmx := New(context.Background(), bytes.NewReader(w.Bytes()))
+
+	// MGT
+	d, err := dmx.NextData()
+	assert.NoError(t, err)
+	assert.NotNil(t, d.MGT)
+	assert.Equal(t, map[uint16]uint16{0x1d00: 0x100}, dmx.sectionMap.p)
+
+	// EIT
+	d, err = dmx.NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, atscEIT, d.ATSCEIT)
+}
+
+func TestDemuxerSCTE35(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	pm, _ := (&PMTData{ElementaryStreams: []*PMTElementaryStream{{ElementaryPID: 0x101, StreamType: StreamTypeSCTE35}}, PCRPID: 0x1fff, ProgramNumber: 1}).Serialize(0)
+	var b = scte35Bytes()
+	var s = append([]byte{0xfc, 0x30 | uint8((len(b)+4)>>8), uint8(len(b) + 4)}, b...)
+	var c = computeCRC32(s)
+	s = append(s, uint8(c>>24), uint8(c>>16), uint8(c>>8), uint8(c))
+	for _, v := range []struct {
+		b   []byte
+		pid uint16
+	}{
+		{b: pm, pid: 0x100},
+		{b: s, pid: 0x101},
+	} {
+		var p = append([]byte{0x0}, v.b...)
+		p = append(p, bytes.Repeat([]byte{0xff}, 147-len(p))...)
+		b, _ := packet(PacketHeader{PayloadUnitStartIndicator: true, PID: v.pid}, PacketAdaptationField{}, p)
+		w.Write(b)
+		b, _ = packet(PacketHeader{ContinuityCounter: uint8(1), PayloadUnitStartIndicator: true, PID: v.pid}, PacketAdaptationField{}, []byte{})
+		w.Write(b)
+	}
+	dmx := New(context.Background(), bytes.NewReader(w.Bytes()))
+	dmx.programMap.set(0x100, 1)
+
+	// PMT
+	d, err := dmx.NextData()
+	assert.NoError(t, err)
+	assert.NotNil(t, d.PMT)
+	assert.Equal(t, map[uint16]uint16{0x101: StreamTypeSCTE35}, dmx.sectionMap.p)
+
+	// SCTE-35
+	d, err = dmx.NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, scte35, d.SCTE35)
+}
+
+func TestDemuxerAIT(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	pm, _ := (&PMTData{ElementaryStreams: []*PMTElementaryStream{{
+		ElementaryPID:               0x101,
+		ElementaryStreamDescriptors: []*Descriptor{{ApplicationSignalling: &DescriptorApplicationSignalling{Items: []*DescriptorApplicationSignallingItem{{ApplicationType: AITApplicationTypeHbbTV}}}, Tag: DescriptorTagApplicationSignalling}},
+		StreamType:                  StreamTypePrivateSections,
+	}}, PCRPID: 0x1fff, ProgramNumber: 1}).Serialize(0)
+	var s = writePSISection(&PSISectionHeader{SectionSyntaxIndicator: true, TableID: 0x74}, &PSISectionSyntaxHeader{CurrentNextIndicator: true, TableIDExtension: 0x8000 | AITApplicationTypeHbbTV}, aitBytes())
+	for _, v := range []struct {
+		b   []byte
+		pid uint16
+	}{
+		{b: pm, pid: 0x100},
+		{b: s, pid: 0x101},
+	} {
+		var p = append([]byte{0x0}, v.b...)
+		p = append(p, bytes.Repeat([]byte{0xff}, 147-len(p))...)
+		b, _ := packet(PacketHeader{PayloadUnitStartIndicator: true, PID: v.pid}, PacketAdaptationField{}, p)
+		w.Write(b)
+		b, _ = packet(PacketHeader{ContinuityCounter: uint8(1), PayloadUnitStartIndicator: true, PID: v.pid}, PacketAdaptationField{}, []byte{})
+		w.Write(b)
+	}
+	dmx := New(context.Background(), bytes.NewReader(w.Bytes()))
+	dmx.programMap.set(0x100, 1)
+
+	// PMT
+	d, err := dmx.NextData()
+	assert.NoError(t, err)
+	assert.NotNil(t, d.PMT)
+	assert.Equal(t, map[uint16]uint16{0x101: StreamTypePrivateSections}, dmx.sectionMap.p)
+
+	// AIT
+	d, err = dmx.NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, ait, d.AIT)
+}
+
+func TestDemuxerStream(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	b := psiBytes()
+	b1, _ := packet(PacketHeader{ContinuityCounter: uint8(0), PayloadUnitStartIndicator: true, PID: PIDPAT}, PacketAdaptationField{}, b[:147])
+	w.Write(b1)
+	b2, _ := packet(PacketHeader{ContinuityCounter: uint8(1), PID: PIDPAT}, PacketAdaptationField{}, b[147:])
+	w.Write(b2)
+	b3, _ := packet(PacketHeader{ContinuityCounter: uint8(2), PayloadUnitStartIndicator: true, PID: PIDPAT}, PacketAdaptationField{}, []byte{})
+	w.Write(b3)
+
+	// Stream until there are no more packets
+	ds, errs := New(context.Background(), bytes.NewReader(w.Bytes()), OptStreamBufferSize(1)).Stream(context.Background())
+	var n int
+	for range ds {
+		n++
+	}
+	var e int
+	for _, s := range psi.Sections {
+		if s.Header.TableType != PSITableTypeUnknown {
+			e++
+		}
+	}
+	assert.Equal(t, e, n)
+	assert.NoError(t, <-errs)
+
+	// Stream errors are sent to the error channel
+	ds, errs = New(context.Background(), bytes.NewReader([]byte("invalid"))).Stream(context.Background())
+	_, ok := <-ds
+	assert.False(t, ok)
+	assert.Error(t, <-errs)
+
+	// Streaming stops when the context is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	ds, errs = New(context.Background(), bytes.NewReader(w.Bytes())).Stream(ctx)
+	cancel()
+	for range ds {
+	}
+	assert.NoError(t, <-errs)
+}
+
 func TestDemuxerRewind(t *testing.T) {
 	r := bytes.NewReader([]byte("content"))
 	dmx := New(context.Background(), r)
 	dmx.packetPool.add(&Packet{Header: &PacketHeader{PID: 1}})
 	dmx.dataBuffer = append(dmx.dataBuffer, &Data{})
+	dmx.metadataTracker.pids[0x100] = &metadataTrackerPID{buf: []byte("cell"), formatIdentifier: MetadataFormatIdentifierKLVA}
+	dmx.t2miTracker.pids[0x101] = &t2miTrackerPID{buf: []byte("t2mi"), userPackets: map[uint16][]byte{1: []byte("user")}}
+	dmx.mpeDatagrams[mpeKey{pid: 0x102}] = &mpeDatagram{buf: []byte("datagram")}
+	dmx.uleTracker.pids[0x103] = &uleTrackerPID{buf: []byte("sndu")}
 	b := make([]byte, 2)
 	_, err := r.Read(b)
 	assert.NoError(t, err)
@@ -99,4 +451,199 @@ func TestDemuxerRewind(t *testing.T) {
 	assert.Equal(t, 0, len(dmx.dataBuffer))
 	assert.Equal(t, 0, len(dmx.packetPool.b))
 	assert.Nil(t, dmx.packetBuffer)
+	assert.Equal(t, &metadataTrackerPID{formatIdentifier: MetadataFormatIdentifierKLVA}, dmx.metadataTracker.pids[0x100])
+	assert.Equal(t, &t2miTrackerPID{userPackets: map[uint16][]byte{}}, dmx.t2miTracker.pids[0x101])
+	assert.Len(t, dmx.mpeDatagrams, 0)
+	assert.Equal(t, &uleTrackerPID{}, dmx.uleTracker.pids[0x103])
+}
+
+func TestDemuxerExtractES(t *testing.T) {
+	// Init
+	w := &bytes.Buffer{}
+	p1 := NewPESPacketizer(0x100)
+	p2 := NewPESPacketizer(0x101)
+	for _, s := range []string{"first", "second", "third"} {
+		d := &PESData{Data: bytes.Repeat([]byte(s), 50), Header: &PESHeader{OptionalHeader: &PESOptionalHeader{}, StreamID: 0xe0}}
+		w.Write(p1.Packetize(d, nil))
+		w.Write(p2.Packetize(d, nil))
+	}
+
+	// Extract
+	b := &bytes.Buffer{}
+	n, err := New(context.Background(), bytes.NewReader(w.Bytes())).ExtractES(0x100, b)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(strings.Repeat("first", 50)+strings.Repeat("second", 50)+strings.Repeat("third", 50)), b.Bytes())
+	assert.Equal(t, int64(b.Len()), n)
+}
+
+func TestDemuxerLargePES(t *testing.T) {
+	// Init
+	w := &bytes.Buffer{}
+	p := NewPESPacketizer(0x100)
+	d := &PESData{Data: bytes.Repeat([]byte("data"), 1500), Header: &PESHeader{OptionalHeader: &PESOptionalHeader{}, StreamID: 0xe0}}
+	w.Write(p.Packetize(d, nil))
+	b := p.Packetize(d, nil)
+	assert.Len(t, b, 33*MpegTsPacketSize)
+	w.Write(b[:20*MpegTsPacketSize])
+	dmx := New(context.Background(), bytes.NewReader(w.Bytes()))
+
+	// Complete PES
+	r, err := dmx.NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, d.Data, r.PES.Data)
+	assert.Equal(t, uint16(6003), r.PES.Header.PacketLength)
+	assert.True(t, r.PES.IsLengthBounded)
+	assert.False(t, r.PES.Truncated)
+
+	// Truncated PES
+	r, err = dmx.NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, d.Data[:20*(MpegTsPacketSize-4)-9], r.PES.Data)
+	assert.True(t, r.PES.Truncated)
+	_, err = dmx.NextData()
+	assert.Equal(t, ErrNoMorePackets, err)
+}
+
+func TestDemuxerUnboundedPES(t *testing.T) {
+	// Init
+	w := &bytes.Buffer{}
+	p := NewPESPacketizer(0x100)
+	d := &PESData{Data: bytes.Repeat([]byte("data"), 0x4000+1), Header: &PESHeader{OptionalHeader: &PESOptionalHeader{}, StreamID: 0xe0}}
+	w.Write(p.Packetize(d, nil))
+	w.Write(p.Packetize(d, nil))
+	dmx := New(context.Background(), bytes.NewReader(w.Bytes()))
+
+	// PES is emitted at the next payload unit start
+	r, err := dmx.NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(0), r.PES.Header.PacketLength)
+	assert.False(t, r.PES.IsLengthBounded)
+	assert.False(t, r.PES.Truncated)
+	assert.Equal(t, d.Data, r.PES.Data)
+
+	// PES is emitted at the end of the stream
+	r, err = dmx.NextData()
+	assert.NoError(t, err)
+	assert.False(t, r.PES.IsLengthBounded)
+	assert.Equal(t, d.Data, r.PES.Data)
+	_, err = dmx.NextData()
+	assert.Equal(t, ErrNoMorePackets, err)
+}
+
+func TestDemuxerTransportErrorPolicy(t *testing.T) {
+	// Init
+	w := &bytes.Buffer{}
+	p := NewPESPacketizer(0x100)
+	for _, s := range []string{"first", "second", "third"} {
+		w.Write(p.Packetize(&PESData{Data: []byte(s), Header: &PESHeader{OptionalHeader: &PESOptionalHeader{}, StreamID: 0xe0}}, nil))
+	}
+	b := w.Bytes()
+	b[MpegTsPacketSize+1] |= 0x80
+
+	// Loop through policies
+	for _, v := range []struct {
+		data   []string
+		errors []bool
+		policy string
+	}{
+		{data: []string{"first", "second", "third"}, errors: []bool{false, true, false}, policy: TransportErrorPolicyFlag},
+		{data: []string{"third"}, errors: []bool{false}, policy: TransportErrorPolicySkip},
+	} {
+		dmx := New(context.Background(), bytes.NewReader(b), OptTransportErrorPolicy(v.policy))
+		var data []string
+		var errors []bool
+		for {
+			d, err := dmx.NextData()
+			if err != nil {
+				assert.Equal(t, ErrNoMorePackets, err)
+				break
+			}
+			data = append(data, string(d.PES.Data))
+			errors = append(errors, d.TransportError)
+		}
+		assert.Equal(t, v.data, data, v.policy)
+		assert.Equal(t, v.errors, errors, v.policy)
+		assert.Equal(t, int64(1), dmx.TransportErrors(), v.policy)
+	}
+}
+
+func TestDemuxerNextTables(t *testing.T) {
+	// Init
+	w := &bytes.Buffer{}
+	var cc uint8
+	for _, v := range []struct {
+		current bool
+		pmtPID  uint16
+	}{
+		{pmtPID: 0x1001},
+		{current: true, pmtPID: 0x1000},
+		{current: true, pmtPID: 0x1000}, // Flushes the previous table
+	} {
+		s := writePSISection(&PSISectionHeader{SectionSyntaxIndicator: true, TableID: 0}, &PSISectionSyntaxHeader{CurrentNextIndicator: v.current, TableIDExtension: 1}, writePATSection(&PATData{Programs: []*PATProgram{{ProgramMapID: v.pmtPID, ProgramNumber: 1}}}))
+		for _, p := range packetizePayload(PIDPAT, writePSIPayload([][]byte{s}), nil, true, &cc) {
+			w.Write(writePacket(p))
+		}
+	}
+	dmx := New(context.Background(), bytes.NewReader(w.Bytes()))
+
+	// Next table
+	d, err := dmx.NextData()
+	assert.NoError(t, err)
+	assert.True(t, d.IsNext)
+	assert.Equal(t, uint16(0x1001), d.PAT.Programs[0].ProgramMapID)
+	assert.Empty(t, dmx.Programs())
+	assert.False(t, dmx.programMap.exists(0x1001))
+
+	// Current table
+	d, err = dmx.NextData()
+	assert.NoError(t, err)
+	assert.False(t, d.IsNext)
+	assert.Len(t, dmx.Programs(), 1)
+	assert.True(t, dmx.programMap.exists(0x1000))
+	assert.False(t, dmx.programMap.exists(0x1001))
+}
+
+func TestDemuxerProgramFilter(t *testing.T) {
+	// Init
+	buf := &bytes.Buffer{}
+	w := &bytes.Buffer{}
+	mx1 := NewMuxer(context.Background(), buf)
+	mx1.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x100, StreamType: StreamTypeMPEG1Audio})
+	mx2 := NewMuxer(context.Background(), buf, MuxerOptPMTPID(0x1100), MuxerOptProgramNumber(2))
+	mx2.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x200, StreamType: StreamTypeMPEG1Audio})
+	for i := 0; i < 3; i++ {
+		// Only the PAT of the second muxer is kept
+		buf.Reset()
+		mx1.WriteTables()
+		w.Write(buf.Bytes()[MpegTsPacketSize:])
+		buf.Reset()
+		mx2.WriteTables()
+		w.Write(buf.Bytes())
+
+		// Data
+		for _, pid := range []uint16{0x100, 0x200} {
+			buf.Reset()
+			mx := mx1
+			if pid == 0x200 {
+				mx = mx2
+			}
+			mx.WriteData(&MuxerData{PES: &PESData{Data: []byte("data"), Header: &PESHeader{OptionalHeader: &PESOptionalHeader{}, StreamID: 0xc0}}, PID: pid})
+			w.Write(buf.Bytes())
+		}
+	}
+	dmx := New(context.Background(), bytes.NewReader(w.Bytes()), OptProgramFilter(2))
+
+	// Only data of the program is returned
+	pids := make(map[uint16]bool)
+	for {
+		d, err := dmx.NextData()
+		if err != nil {
+			assert.Equal(t, ErrNoMorePackets, err)
+			break
+		}
+		pids[d.PID] = true
+	}
+	assert.Equal(t, map[uint16]bool{PIDPAT: true, 0x200: true, 0x1100: true}, pids)
+	_, ok := dmx.PIDs()[0x100]
+	assert.False(t, ok)
 }
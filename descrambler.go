@@ -0,0 +1,29 @@
+package astits
+
+import "github.com/pkg/errors"
+
+// Descrambler represents an object capable of descrambling the payload of packets, such as a DVB CSA or a BISS
+// implementation
+// It is given the PID and the transport scrambling control of the packet, which tells whether the even or the odd key
+// must be used, and returns the descrambled payload
+type Descrambler interface {
+	Descramble(pid uint16, scramblingControl uint8, payload []byte) ([]byte, error)
+}
+
+// descramble descrambles the payload of a packet in place if it's scrambled and a descrambler has been provided
+func (dmx *Demuxer) descramble(p *Packet) (err error) {
+	// Nothing to descramble
+	if dmx.optDescrambler == nil || p.Header.TransportScramblingControl == ScramblingControlNotScrambled || !p.Header.HasPayload {
+		return
+	}
+
+	// Descramble
+	var b []byte
+	if b, err = dmx.optDescrambler.Descramble(p.Header.PID, p.Header.TransportScramblingControl, p.Payload); err != nil {
+		err = errors.Wrapf(err, "astits: descrambling packet of PID %d failed", p.Header.PID)
+		return
+	}
+	p.Header.TransportScramblingControl = ScramblingControlNotScrambled
+	p.Payload = b
+	return
+}
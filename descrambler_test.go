@@ -0,0 +1,55 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// xorDescrambler descrambles payloads scrambled with a xor of the key matching the scrambling control
+type xorDescrambler struct {
+	err  error
+	pids []uint16
+}
+
+func (d *xorDescrambler) Descramble(pid uint16, scramblingControl uint8, payload []byte) ([]byte, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	d.pids = append(d.pids, pid)
+	var o = make([]byte, len(payload))
+	for idx, b := range payload {
+		o[idx] = b ^ scramblingControl
+	}
+	return o, nil
+}
+
+func TestDemuxerDescrambler(t *testing.T) {
+	// Init
+	var pes = (&PESData{Data: []byte("data"), Header: &PESHeader{StreamID: StreamIDPrivateStream2}}).Serialize()
+	pes = append(pes, bytes.Repeat([]byte{0xff}, 184-len(pes))...)
+	var scrambled = make([]byte, len(pes))
+	for idx, b := range pes {
+		scrambled[idx] = b ^ ScramblingControlScrambledWithOddKey
+	}
+	buf := &bytes.Buffer{}
+	buf.Write(writePacket(&Packet{Header: &PacketHeader{HasPayload: true, PayloadUnitStartIndicator: true, PID: 0x100, TransportScramblingControl: ScramblingControlScrambledWithOddKey}, Payload: scrambled}))
+	buf.Write(writePacket(&Packet{Header: &PacketHeader{HasPayload: true, PID: PIDNull}, Payload: make([]byte, 184)}))
+
+	// Descramble
+	d := &xorDescrambler{}
+	dmx := New(context.Background(), bytes.NewReader(buf.Bytes()), OptDescrambler(d))
+	v, err := dmx.NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("data"), v.PES.Data)
+	assert.Equal(t, []uint16{0x100}, d.pids)
+	assert.Equal(t, int64(1), dmx.Stats().ScrambledPackets)
+
+	// Error
+	dmx = New(context.Background(), bytes.NewReader(buf.Bytes()), OptDescrambler(&xorDescrambler{err: errors.New("invalid key")}))
+	_, err = dmx.NextData()
+	assert.Error(t, err)
+}
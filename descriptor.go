@@ -1,9 +1,10 @@
 package astits
 
 import (
+	"fmt"
 	"time"
 
-	"github.com/asticode/go-astilog"
+	"github.com/pkg/errors"
 )
 
 // Audio types
@@ -14,6 +15,16 @@ const (
 	AudioTypeVisualImpairedCommentary = 0x3
 )
 
+// Cable modulations
+// Chapter: 6.2.13.1 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+const (
+	CableModulation128QAM = 0x4
+	CableModulation16QAM  = 0x1
+	CableModulation256QAM = 0x5
+	CableModulation32QAM  = 0x2
+	CableModulation64QAM  = 0x3
+)
+
 // Data stream alignments
 // Page: 85 | Chapter:2.6.11 | Link: http://ecee.colorado.edu/~ecen5653/ecen5653/papers/iso13818-1.pdf
 const (
@@ -28,34 +39,145 @@ const (
 // Page: 42 | Chapter: 6.1 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
 const (
 	DescriptorTagAC3                        = 0x6a
+	DescriptorTagApplicationSignalling      = 0x6f
+	DescriptorTagAudioStream                = 0x3
 	DescriptorTagAVCVideo                   = 0x28
+	DescriptorTagCA                         = 0x9
+	DescriptorTagCableDeliverySystem        = 0x44
 	DescriptorTagComponent                  = 0x50
 	DescriptorTagContent                    = 0x54
 	DescriptorTagDataStreamAlignment        = 0x6
 	DescriptorTagEnhancedAC3                = 0x7a
 	DescriptorTagExtendedEvent              = 0x4e
 	DescriptorTagExtension                  = 0x7f
+	DescriptorTagHEVCVideo                  = 0x38
 	DescriptorTagISO639LanguageAndAudioType = 0xa
 	DescriptorTagLocalTimeOffset            = 0x58
 	DescriptorTagMaximumBitrate             = 0xe
+	DescriptorTagMetadata                   = 0x26
+	DescriptorTagMultilingualBouquetName    = 0x5c
+	DescriptorTagMultilingualComponent      = 0x5e
+	DescriptorTagMultilingualNetworkName    = 0x5b
+	DescriptorTagMultilingualServiceName    = 0x5d
 	DescriptorTagNetworkName                = 0x40
 	DescriptorTagParentalRating             = 0x55
 	DescriptorTagPrivateDataIndicator       = 0xf
 	DescriptorTagPrivateDataSpecifier       = 0x5f
 	DescriptorTagRegistration               = 0x5
+	DescriptorTagSatelliteDeliverySystem    = 0x43
 	DescriptorTagService                    = 0x48
 	DescriptorTagShortEvent                 = 0x4d
 	DescriptorTagStreamIdentifier           = 0x52
 	DescriptorTagSubtitling                 = 0x59
 	DescriptorTagTeletext                   = 0x56
+	DescriptorTagTerrestrialDeliverySystem  = 0x5a
 	DescriptorTagVBIData                    = 0x45
 	DescriptorTagVBITeletext                = 0x46
+	DescriptorTagVideoStream                = 0x2
 )
 
 // Descriptor extension tags
 // Page: 111 | Chapter: 6.1 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
 const (
 	DescriptorTagExtensionSupplementaryAudio = 0x6
+	DescriptorTagExtensionT2MI               = 0x11
+)
+
+// FEC schemes
+// Chapter: 6.2.13.1 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+const (
+	FECInner1Over2                = 0x1
+	FECInner2Over3                = 0x2
+	FECInner3Over4                = 0x3
+	FECInner3Over5                = 0x7
+	FECInner4Over5                = 0x8
+	FECInner5Over6                = 0x4
+	FECInner7Over8                = 0x5
+	FECInner8Over9                = 0x6
+	FECInner9Over10               = 0x9
+	FECInnerNoConvolutionalCoding = 0xf
+	FECOuterNone                  = 0x1
+	FECOuterRS                    = 0x2 // RS(204/188)
+)
+
+// Metadata application formats and metadata formats
+// Page: 116 | Chapter: 2.6.60 | Link: ISO/IEC 13818-1
+const (
+	MetadataApplicationFormatIdentifierField = 0xffff     // The application format is signalled by the application format identifier
+	MetadataFormatIdentifierField            = 0xff       // The format is signalled by the format identifier
+	MetadataFormatIdentifierID3              = 0x49443320 // "ID3 ", as used by Apple HTTP Live Streaming timed metadata
+	MetadataFormatIdentifierKLVA             = 0x4b4c5641 // "KLVA", SMPTE ST 336 KLV metadata as specified by MISB ST 1402
+)
+
+// Metadata decoder config flags
+const (
+	metadataDecoderConfigFlagsConfig               = 0x1
+	metadataDecoderConfigFlagsConfigIdentification = 0x3
+	metadataDecoderConfigFlagsMetadataServiceID    = 0x4
+	metadataDecoderConfigFlagsReserved1            = 0x5
+	metadataDecoderConfigFlagsReserved2            = 0x6
+)
+
+// Polarizations
+// Chapter: 6.2.13.2 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+const (
+	PolarizationCircularLeft     = 0x2
+	PolarizationCircularRight    = 0x3
+	PolarizationLinearHorizontal = 0x0
+	PolarizationLinearVertical   = 0x1
+)
+
+// Private data specifiers
+// Link: https://www.dvbservices.com/identifiers/private_data_spec_id
+const (
+	PrivateDataSpecifierEICTA  = 0x28
+	PrivateDataSpecifierNorDig = 0x29
+	PrivateDataSpecifierUKDTG  = 0x233a
+)
+
+// Private descriptor tags, whose meaning depends on the private data specifier in effect
+// Link: https://www.dvbservices.com/identifiers/private_data_spec_id
+const (
+	DescriptorTagHDSimulcastLogicalChannel = 0x88 // EICTA and UK DTG
+	DescriptorTagLogicalChannel            = 0x83 // EICTA, UK DTG and NorDig version 1
+	DescriptorTagLogicalChannelV2          = 0x87 // NorDig version 2
+)
+
+// Registration format identifiers
+// Link: https://smpte-ra.org/registered-mpeg-ts-ids
+const (
+	RegistrationFormatIdentifierAC3  = 0x41432d33 // "AC-3", ATSC A/52 AC-3 audio
+	RegistrationFormatIdentifierBSSD = 0x42535344 // "BSSD", SMPTE ST 302 AES3 audio
+	RegistrationFormatIdentifierCUEI = 0x43554549 // "CUEI", ANSI/SCTE 35 splice information
+	RegistrationFormatIdentifierDTS1 = 0x44545331 // "DTS1", DTS audio with 512 samples frames
+	RegistrationFormatIdentifierDTS2 = 0x44545332 // "DTS2", DTS audio with 1024 samples frames
+	RegistrationFormatIdentifierDTS3 = 0x44545333 // "DTS3", DTS audio with 2048 samples frames
+	RegistrationFormatIdentifierEAC3 = 0x45414333 // "EAC3", ATSC A/52 Annex E E-AC-3 audio
+	RegistrationFormatIdentifierHDMV = 0x48444d56 // "HDMV", Blu-ray disc movies
+	RegistrationFormatIdentifierHEVC = 0x48455643 // "HEVC", ITU-T Rec. H.265 video
+	RegistrationFormatIdentifierKLVA = 0x4b4c5641 // "KLVA", asynchronous SMPTE ST 336 KLV metadata
+	RegistrationFormatIdentifierOpus = 0x4f707573 // "Opus", Opus audio
+	RegistrationFormatIdentifierVANC = 0x56414e43 // "VANC", SMPTE ST 2038 ancillary data
+	RegistrationFormatIdentifierVC1  = 0x56432d31 // "VC-1", SMPTE ST 421 video
+)
+
+// Roll-off factors
+// Chapter: 6.2.13.2 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+const (
+	RollOff020 = 0x2
+	RollOff025 = 0x1
+	RollOff035 = 0x0
+)
+
+// Satellite modulation systems and types
+// Chapter: 6.2.13.2 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+const (
+	SatelliteModulationSystemDVBS  = 0x0
+	SatelliteModulationSystemDVBS2 = 0x1
+	SatelliteModulationType16QAM   = 0x3
+	SatelliteModulationType8PSK    = 0x2
+	SatelliteModulationTypeAuto    = 0x0
+	SatelliteModulationTypeQPSK    = 0x1
 )
 
 // Service types
@@ -65,6 +187,24 @@ const (
 	ServiceTypeDigitalTelevisionService = 0x1
 )
 
+// Subtitling types
+// Page: 53 | Chapter: 6.2.8 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+const (
+	SubtitlingTypeDVBSubtitles                   = 0x10
+	SubtitlingTypeDVBSubtitles16x9               = 0x12
+	SubtitlingTypeDVBSubtitles221x1              = 0x13
+	SubtitlingTypeDVBSubtitles4x3                = 0x11
+	SubtitlingTypeDVBSubtitlesHD                 = 0x14
+	SubtitlingTypeDVBSubtitlesHardOfHearing      = 0x20
+	SubtitlingTypeDVBSubtitlesHardOfHearing16x9  = 0x22
+	SubtitlingTypeDVBSubtitlesHardOfHearing221x1 = 0x23
+	SubtitlingTypeDVBSubtitlesHardOfHearing4x3   = 0x21
+	SubtitlingTypeDVBSubtitlesHardOfHearingHD    = 0x24
+	SubtitlingTypeEBUTeletextAssociated          = 0x2
+	SubtitlingTypeEBUTeletextSubtitles           = 0x1
+	SubtitlingTypeVBIData                        = 0x3
+)
+
 // Teletext types
 // Page: 106 | Chapter: 6.2.43 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
 const (
@@ -75,6 +215,30 @@ const (
 	TeletextTypeTeletextSubtitlePageForHearingImpairedPeople = 0x5
 )
 
+// Terrestrial bandwidths, code rates, constellations, guard intervals and transmission modes
+// Chapter: 6.2.13.4 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+const (
+	TerrestrialBandwidth5MHz        = 0x3
+	TerrestrialBandwidth6MHz        = 0x2
+	TerrestrialBandwidth7MHz        = 0x1
+	TerrestrialBandwidth8MHz        = 0x0
+	TerrestrialCodeRate1Over2       = 0x0
+	TerrestrialCodeRate2Over3       = 0x1
+	TerrestrialCodeRate3Over4       = 0x2
+	TerrestrialCodeRate5Over6       = 0x3
+	TerrestrialCodeRate7Over8       = 0x4
+	TerrestrialConstellation16QAM   = 0x1
+	TerrestrialConstellation64QAM   = 0x2
+	TerrestrialConstellationQPSK    = 0x0
+	TerrestrialGuardInterval1Over16 = 0x1
+	TerrestrialGuardInterval1Over32 = 0x0
+	TerrestrialGuardInterval1Over4  = 0x3
+	TerrestrialGuardInterval1Over8  = 0x2
+	TerrestrialTransmissionMode2K   = 0x0
+	TerrestrialTransmissionMode4K   = 0x2
+	TerrestrialTransmissionMode8K   = 0x1
+)
+
 // VBI data service id
 // Page: 109 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
 const (
@@ -86,35 +250,57 @@ const (
 	VBIDataServiceIDWSS                  = 0x5
 )
 
+// Errors
+var (
+	ErrDescriptorNotSerializable = errors.New("astits: descriptor is not serializable")
+)
+
 // Descriptor represents a descriptor
-// TODO Handle UTF8
 type Descriptor struct {
 	AC3                        *DescriptorAC3
+	ApplicationSignalling      *DescriptorApplicationSignalling
+	AudioStream                *DescriptorAudioStream
 	AVCVideo                   *DescriptorAVCVideo
+	CA                         *DescriptorCA
+	CableDeliverySystem        *DescriptorCableDeliverySystem
 	Component                  *DescriptorComponent
 	Content                    *DescriptorContent
 	DataStreamAlignment        *DescriptorDataStreamAlignment
 	EnhancedAC3                *DescriptorEnhancedAC3
 	ExtendedEvent              *DescriptorExtendedEvent
 	Extension                  *DescriptorExtension
+	HDSimulcastLogicalChannel  *DescriptorLogicalChannel // Set for user defined tags when the private data specifier in effect is known to use it
+	HEVCVideo                  *DescriptorHEVCVideo
 	ISO639LanguageAndAudioType *DescriptorISO639LanguageAndAudioType
 	Length                     uint8
 	LocalTimeOffset            *DescriptorLocalTimeOffset
+	LogicalChannel             *DescriptorLogicalChannel   // Set for user defined tags when the private data specifier in effect is known to use it
+	LogicalChannelV2           *DescriptorLogicalChannelV2 // Set for user defined tags when the private data specifier in effect is known to use it
 	MaximumBitrate             *DescriptorMaximumBitrate
+	Metadata                   *DescriptorMetadata
+	MultilingualBouquetName    *DescriptorMultilingualName
+	MultilingualComponent      *DescriptorMultilingualComponent
+	MultilingualNetworkName    *DescriptorMultilingualName
+	MultilingualServiceName    *DescriptorMultilingualServiceName
 	NetworkName                *DescriptorNetworkName
 	ParentalRating             *DescriptorParentalRating
 	PrivateDataIndicator       *DescriptorPrivateDataIndicator
 	PrivateDataSpecifier       *DescriptorPrivateDataSpecifier
 	Registration               *DescriptorRegistration
+	SatelliteDeliverySystem    *DescriptorSatelliteDeliverySystem
 	Service                    *DescriptorService
 	ShortEvent                 *DescriptorShortEvent
+	Specifier                  uint32 // Private data specifier in effect, set by the last private data specifier descriptor of the loop up to this one
 	StreamIdentifier           *DescriptorStreamIdentifier
 	Subtitling                 *DescriptorSubtitling
 	Tag                        uint8 // the tag defines the structure of the contained data following the descriptor length.
 	Teletext                   *DescriptorTeletext
-	UserDefined                []byte
+	TerrestrialDeliverySystem  *DescriptorTerrestrialDeliverySystem
+	Unknown                    *DescriptorUnknown // Set when the tag is not supported
+	UserDefined                []byte             // Raw content of user defined tags, interpreted or not. It's written as is when set, so clear it to write changes
 	VBIData                    *DescriptorVBIData
 	VBITeletext                *DescriptorTeletext
+	VideoStream                *DescriptorVideoStream
 }
 
 // DescriptorAC3 represents an AC3 descriptor
@@ -162,17 +348,140 @@ func newDescriptorAC3(i []byte) (d *DescriptorAC3) {
 	return
 }
 
+func writeDescriptorAC3(d *DescriptorAC3) (b []byte) {
+	// Flags
+	var flags uint8
+	if d.HasComponentType {
+		flags |= 0x80
+	}
+	if d.HasBSID {
+		flags |= 0x40
+	}
+	if d.HasMainID {
+		flags |= 0x20
+	}
+	if d.HasASVC {
+		flags |= 0x10
+	}
+	b = append(b, flags)
+
+	// Optional fields
+	if d.HasComponentType {
+		b = append(b, d.ComponentType)
+	}
+	if d.HasBSID {
+		b = append(b, d.BSID)
+	}
+	if d.HasMainID {
+		b = append(b, d.MainID)
+	}
+	if d.HasASVC {
+		b = append(b, d.ASVC)
+	}
+
+	// Additional info
+	b = append(b, d.AdditionalInfo...)
+	return
+}
+
+// DescriptorApplicationSignalling represents an application signalling descriptor, which flags the elementary streams
+// carrying an AIT
+// Page: 30 | Chapter: 5.3.5.1 | Link: https://www.etsi.org/deliver/etsi_ts/102800_102899/102809/01.03.01_60/ts_102809v010301p.pdf
+type DescriptorApplicationSignalling struct {
+	Items []*DescriptorApplicationSignallingItem
+}
+
+// DescriptorApplicationSignallingItem represents an application signalling descriptor item
+type DescriptorApplicationSignallingItem struct {
+	AITVersionNumber uint8
+	ApplicationType  uint16
+}
+
+func newDescriptorApplicationSignalling(i []byte) (d *DescriptorApplicationSignalling) {
+	d = &DescriptorApplicationSignalling{}
+	for offset := 0; offset+3 <= len(i); offset += 3 {
+		d.Items = append(d.Items, &DescriptorApplicationSignallingItem{
+			AITVersionNumber: uint8(i[offset+2] & 0x1f),
+			ApplicationType:  uint16(i[offset]&0x7f)<<8 | uint16(i[offset+1]),
+		})
+	}
+	return
+}
+
+func writeDescriptorApplicationSignalling(d *DescriptorApplicationSignalling) (b []byte) {
+	for _, itm := range d.Items {
+		b = append(b, 0x80|uint8(itm.ApplicationType>>8&0x7f), uint8(itm.ApplicationType), 0xe0|itm.AITVersionNumber&0x1f)
+	}
+	return
+}
+
+// DescriptorAudioStream represents an audio stream descriptor
+// Chapter: 2.6.4 | Link: http://ecee.colorado.edu/~ecen5653/ecen5653/papers/iso13818-1.pdf
+type DescriptorAudioStream struct {
+	FreeFormatFlag             bool
+	ID                         uint8 // 1 for ISO/IEC 11172-3 audio, 0 for the lower sampling frequencies of ISO/IEC 13818-3
+	Layer                      uint8
+	VariableRateAudioIndicator bool
+}
+
+func newDescriptorAudioStream(i []byte) *DescriptorAudioStream {
+	return &DescriptorAudioStream{
+		FreeFormatFlag:             i[0]&0x80 > 0,
+		ID:                         i[0] >> 6 & 0x1,
+		Layer:                      i[0] >> 4 & 0x3,
+		VariableRateAudioIndicator: i[0]&0x8 > 0,
+	}
+}
+
+func writeDescriptorAudioStream(d *DescriptorAudioStream) []byte {
+	var b = 0x7 | (d.ID&0x1)<<6 | (d.Layer&0x3)<<4
+	if d.FreeFormatFlag {
+		b |= 0x80
+	}
+	if d.VariableRateAudioIndicator {
+		b |= 0x8
+	}
+	return []byte{b}
+}
+
 // DescriptorAVCVideo represents an AVC video descriptor
 // No doc found unfortunately, basing the implementation on https://github.com/gfto/bitstream/blob/master/mpeg/psi/desc_28.h
 type DescriptorAVCVideo struct {
-	AVC24HourPictureFlag bool
-	AVCStillPresent      bool
-	CompatibleFlags      uint8
-	ConstraintSet0Flag   bool
-	ConstraintSet1Flag   bool
-	ConstraintSet2Flag   bool
-	LevelIDC             uint8
-	ProfileIDC           uint8
+	AVC24HourPictureFlag          bool
+	AVCStillPresent               bool
+	CompatibleFlags               uint8 // Constraint set 3 to 5 flags followed by the AVC compatible flags
+	ConstraintSet0Flag            bool
+	ConstraintSet1Flag            bool
+	ConstraintSet2Flag            bool
+	FramePackingSEINotPresentFlag bool
+	LevelIDC                      uint8
+	ProfileIDC                    uint8
+}
+
+// avcProfileNames are the names of the AVC profiles indexed by profile idc
+var avcProfileNames = map[uint8]string{
+	44:  "CAVLC 4:4:4 Intra",
+	66:  "Baseline",
+	77:  "Main",
+	83:  "Scalable Baseline",
+	86:  "Scalable High",
+	88:  "Extended",
+	100: "High",
+	110: "High 10",
+	118: "Multiview High",
+	122: "High 4:2:2",
+	128: "Stereo High",
+	244: "High 4:4:4 Predictive",
+}
+
+// Level returns the level of the level idc, e.g. 4.1
+func (d *DescriptorAVCVideo) Level() float64 {
+	return float64(d.LevelIDC) / 10
+}
+
+// ProfileName returns the name of the profile idc, or an empty string if it's not known
+func (d *DescriptorAVCVideo) ProfileName() string {
+	return avcProfileNames[d.ProfileIDC]
 }
 
 func newDescriptorAVCVideo(i []byte) (d *DescriptorAVCVideo) {
@@ -200,6 +509,84 @@ func newDescriptorAVCVideo(i []byte) (d *DescriptorAVCVideo) {
 
 	// AVC 24 hour picture flag
 	d.AVC24HourPictureFlag = i[offset]&0x40 > 0
+
+	// Frame packing SEI not present flag
+	d.FramePackingSEINotPresentFlag = i[offset]&0x20 > 0
+	return
+}
+
+func writeDescriptorAVCVideo(d *DescriptorAVCVideo) []byte {
+	// Flags
+	var flags = d.CompatibleFlags & 0x1f
+	if d.ConstraintSet0Flag {
+		flags |= 0x80
+	}
+	if d.ConstraintSet1Flag {
+		flags |= 0x40
+	}
+	if d.ConstraintSet2Flag {
+		flags |= 0x20
+	}
+
+	// AVC still present, AVC 24 hour picture and frame packing SEI not present flags
+	var still uint8 = 0x1f
+	if d.AVCStillPresent {
+		still |= 0x80
+	}
+	if d.AVC24HourPictureFlag {
+		still |= 0x40
+	}
+	if d.FramePackingSEINotPresentFlag {
+		still |= 0x20
+	}
+	return []byte{d.ProfileIDC, flags, d.LevelIDC, still}
+}
+
+// DescriptorCA represents a conditional access descriptor
+// Page: 64 | Chapter: 2.6.16 | Link: http://ecee.colorado.edu/~ecen5653/ecen5653/papers/iso13818-1.pdf
+type DescriptorCA struct {
+	CAPID       uint16 // PID carrying EMMs when found in the CAT, or ECMs when found in the PMT
+	CASystemID  uint16
+	PrivateData []byte
+}
+
+func newDescriptorCA(i []byte) *DescriptorCA {
+	return &DescriptorCA{
+		CAPID:       uint16(i[2]&0x1f)<<8 | uint16(i[3]),
+		CASystemID:  uint16(i[0])<<8 | uint16(i[1]),
+		PrivateData: i[4:],
+	}
+}
+
+func writeDescriptorCA(d *DescriptorCA) []byte {
+	return append([]byte{uint8(d.CASystemID >> 8), uint8(d.CASystemID), 0xe0 | uint8(d.CAPID>>8)&0x1f, uint8(d.CAPID)}, d.PrivateData...)
+}
+
+// DescriptorCableDeliverySystem represents a cable delivery system descriptor
+// Chapter: 6.2.13.1 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+type DescriptorCableDeliverySystem struct {
+	FECInner   uint8
+	FECOuter   uint8
+	Frequency  uint64 // In Hz, with a precision of 100 Hz
+	Modulation uint8
+	SymbolRate uint32 // In symbols/s, with a precision of 100 symbols/s
+}
+
+func newDescriptorCableDeliverySystem(i []byte) *DescriptorCableDeliverySystem {
+	return &DescriptorCableDeliverySystem{
+		FECInner:   i[10] & 0xf,
+		FECOuter:   i[5] & 0xf,
+		Frequency:  parseDVBBCD(i[0:4], 8) * 100,
+		Modulation: i[6],
+		SymbolRate: uint32(parseDVBBCD(i[7:11], 7)) * 100,
+	}
+}
+
+func writeDescriptorCableDeliverySystem(d *DescriptorCableDeliverySystem) (b []byte) {
+	b = append(b, writeDVBBCD(d.Frequency/100, 8)...)
+	b = append(b, 0xff, 0xf0|d.FECOuter&0xf, d.Modulation)
+	b = append(b, writeDVBBCD(uint64(d.SymbolRate/100), 7)...)
+	b[len(b)-1] |= d.FECInner & 0xf
 	return
 }
 
@@ -208,6 +595,7 @@ func newDescriptorAVCVideo(i []byte) (d *DescriptorAVCVideo) {
 type DescriptorComponent struct {
 	ComponentTag       uint8
 	ComponentType      uint8
+	DecodedText        string // Set when a text decoder is provided
 	ISO639LanguageCode []byte
 	StreamContent      uint8
 	StreamContentExt   uint8
@@ -246,6 +634,13 @@ func newDescriptorComponent(i []byte) (d *DescriptorComponent) {
 	return
 }
 
+func writeDescriptorComponent(d *DescriptorComponent) (b []byte) {
+	b = append(b, d.StreamContentExt<<4|d.StreamContent&0xf, d.ComponentType, d.ComponentTag)
+	b = append(b, d.ISO639LanguageCode...)
+	b = append(b, d.Text...)
+	return
+}
+
 // DescriptorContent represents a content descriptor
 // Page: 58 | https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
 type DescriptorContent struct {
@@ -278,7 +673,17 @@ func newDescriptorContent(i []byte) (d *DescriptorContent) {
 	return
 }
 
+func writeDescriptorContent(d *DescriptorContent) (b []byte) {
+	for _, itm := range d.Items {
+		b = append(b, itm.ContentNibbleLevel1<<4|itm.ContentNibbleLevel2&0xf, itm.UserByte)
+	}
+	return
+}
+
 // DescriptorDataStreamAlignment represents a data stream alignment descriptor
+// Its type is to be compared to the video data stream alignments for video streams, and to the audio ones for audio
+// streams
+// Page: 85 | Chapter: 2.6.11 | Link: http://ecee.colorado.edu/~ecen5653/ecen5653/papers/iso13818-1.pdf
 type DescriptorDataStreamAlignment struct {
 	Type uint8
 }
@@ -287,6 +692,10 @@ func newDescriptorDataStreamAlignment(i []byte) *DescriptorDataStreamAlignment {
 	return &DescriptorDataStreamAlignment{Type: uint8(i[0])}
 }
 
+func writeDescriptorDataStreamAlignment(d *DescriptorDataStreamAlignment) []byte {
+	return []byte{d.Type}
+}
+
 // DescriptorEnhancedAC3 represents an enhanced AC3 descriptor
 // Page: 166 | https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
 type DescriptorEnhancedAC3 struct {
@@ -355,8 +764,66 @@ func newDescriptorEnhancedAC3(i []byte) (d *DescriptorEnhancedAC3) {
 	return
 }
 
+func writeDescriptorEnhancedAC3(d *DescriptorEnhancedAC3) (b []byte) {
+	// Flags
+	var flags uint8
+	if d.HasComponentType {
+		flags |= 0x80
+	}
+	if d.HasBSID {
+		flags |= 0x40
+	}
+	if d.HasMainID {
+		flags |= 0x20
+	}
+	if d.HasASVC {
+		flags |= 0x10
+	}
+	if d.MixInfoExists {
+		flags |= 0x8
+	}
+	if d.HasSubStream1 {
+		flags |= 0x4
+	}
+	if d.HasSubStream2 {
+		flags |= 0x2
+	}
+	if d.HasSubStream3 {
+		flags |= 0x1
+	}
+	b = append(b, flags)
+
+	// Optional fields
+	if d.HasComponentType {
+		b = append(b, d.ComponentType)
+	}
+	if d.HasBSID {
+		b = append(b, d.BSID)
+	}
+	if d.HasMainID {
+		b = append(b, d.MainID)
+	}
+	if d.HasASVC {
+		b = append(b, d.ASVC)
+	}
+	if d.HasSubStream1 {
+		b = append(b, d.SubStream1)
+	}
+	if d.HasSubStream2 {
+		b = append(b, d.SubStream2)
+	}
+	if d.HasSubStream3 {
+		b = append(b, d.SubStream3)
+	}
+
+	// Additional info
+	b = append(b, d.AdditionalInfo...)
+	return
+}
+
 // DescriptorExtendedEvent represents an extended event descriptor
 type DescriptorExtendedEvent struct {
+	DecodedText          string // Set when a text decoder is provided
 	ISO639LanguageCode   []byte
 	Items                []*DescriptorExtendedEventItem
 	LastDescriptorNumber uint8
@@ -366,8 +833,10 @@ type DescriptorExtendedEvent struct {
 
 // DescriptorExtendedEventItem represents an extended event item descriptor
 type DescriptorExtendedEventItem struct {
-	Content     []byte
-	Description []byte
+	Content            []byte
+	DecodedContent     string // Set when a text decoder is provided
+	DecodedDescription string // Set when a text decoder is provided
+	Description        []byte
 }
 
 func newDescriptorExtendedEvent(i []byte) (d *DescriptorExtendedEvent) {
@@ -437,11 +906,37 @@ func newDescriptorExtendedEventItem(i []byte, offset *int) (d *DescriptorExtende
 	return
 }
 
+func writeDescriptorExtendedEvent(d *DescriptorExtendedEvent) (b []byte) {
+	// Number and last descriptor number
+	b = append(b, d.Number<<4|d.LastDescriptorNumber&0xf)
+
+	// ISO 639 language code
+	b = append(b, d.ISO639LanguageCode...)
+
+	// Items
+	var bi []byte
+	for _, item := range d.Items {
+		bi = append(bi, uint8(len(item.Description)))
+		bi = append(bi, item.Description...)
+		bi = append(bi, uint8(len(item.Content)))
+		bi = append(bi, item.Content...)
+	}
+	b = append(b, uint8(len(bi)))
+	b = append(b, bi...)
+
+	// Text
+	b = append(b, uint8(len(d.Text)))
+	b = append(b, d.Text...)
+	return
+}
+
 // DescriptorExtension represents an extension descriptor
 // Page: 72 | https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
 type DescriptorExtension struct {
 	SupplementaryAudio *DescriptorExtensionSupplementaryAudio
+	T2MI               *DescriptorExtensionT2MI
 	Tag                uint8
+	Unknown            *DescriptorUnknown // Set when the extension tag is not supported
 }
 
 func newDescriptorExtension(i []byte) (d *DescriptorExtension) {
@@ -453,9 +948,26 @@ func newDescriptorExtension(i []byte) (d *DescriptorExtension) {
 	switch d.Tag {
 	case DescriptorTagExtensionSupplementaryAudio:
 		d.SupplementaryAudio = newDescriptorExtensionSupplementaryAudio(b)
+	case DescriptorTagExtensionT2MI:
+		d.T2MI = newDescriptorExtensionT2MI(b)
 	default:
-		// TODO Remove this log
-		astilog.Debugf("astits: unlisted extension tag 0x%x", d.Tag)
+		d.Unknown = newDescriptorUnknown(d.Tag, b)
+	}
+	return
+}
+
+func writeDescriptorExtension(d *DescriptorExtension) (b []byte, err error) {
+	// Switch on tag
+	b = append(b, d.Tag)
+	switch {
+	case d.SupplementaryAudio != nil:
+		b = append(b, writeDescriptorExtensionSupplementaryAudio(d.SupplementaryAudio)...)
+	case d.T2MI != nil:
+		b = append(b, writeDescriptorExtensionT2MI(d.T2MI)...)
+	case d.Unknown != nil:
+		b = append(b, d.Unknown.Data...)
+	default:
+		err = ErrDescriptorNotSerializable
 	}
 	return
 }
@@ -499,6 +1011,204 @@ func newDescriptorExtensionSupplementaryAudio(i []byte) (d *DescriptorExtensionS
 	return
 }
 
+func writeDescriptorExtensionSupplementaryAudio(d *DescriptorExtensionSupplementaryAudio) (b []byte) {
+	// Mix type, editorial classification and language code flag
+	var v = d.EditorialClassification&0x1f<<2 | 0x2
+	if d.MixType {
+		v |= 0x80
+	}
+	if d.HasLanguageCode {
+		v |= 0x1
+	}
+	b = append(b, v)
+
+	// Language code
+	if d.HasLanguageCode {
+		b = append(b, d.LanguageCode...)
+	}
+
+	// Private data
+	b = append(b, d.PrivateData...)
+	return
+}
+
+// DescriptorExtensionT2MI represents a T2-MI extension descriptor, which flags the elementary streams carrying T2-MI
+// packets
+// Page: 26 | Chapter: 5.1 | Link: https://www.etsi.org/deliver/etsi_ts/102700_102799/102773/01.04.01_60/ts_102773v010401p.pdf
+type DescriptorExtensionT2MI struct {
+	NumStreamsMinusOne     uint8 // Number of T2-MI streams carried on the elementary stream minus one
+	PCRISCRCommonClockFlag bool  // Set when the PCRs and the ISCRs are derived from the same clock
+	StreamID               uint8
+}
+
+func newDescriptorExtensionT2MI(i []byte) (d *DescriptorExtensionT2MI) {
+	// Init
+	d = &DescriptorExtensionT2MI{}
+
+	// T2-MI stream ID
+	d.StreamID = uint8(i[0] & 0x7)
+
+	// Number of T2-MI streams minus one
+	d.NumStreamsMinusOne = uint8(i[1] & 0x7)
+
+	// PCR ISCR common clock flag
+	d.PCRISCRCommonClockFlag = i[2]&0x1 > 0
+	return
+}
+
+func writeDescriptorExtensionT2MI(d *DescriptorExtensionT2MI) (b []byte) {
+	// T2-MI stream ID and number of T2-MI streams minus one
+	b = append(b, 0xf8|d.StreamID&0x7, 0xf8|d.NumStreamsMinusOne&0x7)
+
+	// PCR ISCR common clock flag
+	var v = uint8(0xfe)
+	if d.PCRISCRCommonClockFlag {
+		v |= 0x1
+	}
+	b = append(b, v)
+	return
+}
+
+// DescriptorHEVCVideo represents an HEVC video descriptor
+// Temporal IDs are only set when the temporal layer subset flag is set
+// Chapter: 2.6.95 | Link: https://www.itu.int/rec/T-REC-H.222.0
+type DescriptorHEVCVideo struct {
+	Copied44Bits                   uint64
+	FrameOnlyConstraintFlag        bool
+	HDRWCGIDC                      uint8
+	HEVC24HourPicturePresentFlag   bool
+	HEVCStillPresentFlag           bool
+	InterlacedSourceFlag           bool
+	LevelIDC                       uint8
+	NonPackedConstraintFlag        bool
+	ProfileCompatibilityIndication uint32
+	ProfileIDC                     uint8
+	ProfileSpace                   uint8
+	ProgressiveSourceFlag          bool
+	SubPicHRDParamsNotPresentFlag  bool
+	TemporalIDMax                  uint8
+	TemporalIDMin                  uint8
+	TemporalLayerSubsetFlag        bool
+	TierFlag                       bool // Whether the level is of the high tier rather than of the main tier
+}
+
+// hevcProfileNames are the names of the HEVC profiles indexed by general profile idc
+var hevcProfileNames = map[uint8]string{
+	1:  "Main",
+	2:  "Main 10",
+	3:  "Main Still Picture",
+	4:  "Format Range Extensions",
+	5:  "High Throughput",
+	9:  "Screen Content Coding",
+	11: "High Throughput Screen Content Coding",
+}
+
+// Level returns the level of the level idc, e.g. 5.1
+func (d *DescriptorHEVCVideo) Level() float64 {
+	return float64(d.LevelIDC) / 30
+}
+
+// ProfileName returns the name of the profile idc, or an empty string if it's not known
+func (d *DescriptorHEVCVideo) ProfileName() string {
+	return hevcProfileNames[d.ProfileIDC]
+}
+
+func newDescriptorHEVCVideo(i []byte) (d *DescriptorHEVCVideo) {
+	// Init
+	d = &DescriptorHEVCVideo{}
+	var offset int
+
+	// Profile space, tier flag and profile idc
+	d.ProfileSpace = i[offset] >> 6
+	d.TierFlag = i[offset]&0x20 > 0
+	d.ProfileIDC = i[offset] & 0x1f
+	offset += 1
+
+	// Profile compatibility indication
+	d.ProfileCompatibilityIndication = uint32(i[offset])<<24 | uint32(i[offset+1])<<16 | uint32(i[offset+2])<<8 | uint32(i[offset+3])
+	offset += 4
+
+	// Flags and copied 44 bits
+	d.ProgressiveSourceFlag = i[offset]&0x80 > 0
+	d.InterlacedSourceFlag = i[offset]&0x40 > 0
+	d.NonPackedConstraintFlag = i[offset]&0x20 > 0
+	d.FrameOnlyConstraintFlag = i[offset]&0x10 > 0
+	d.Copied44Bits = uint64(i[offset]&0xf)<<40 | uint64(i[offset+1])<<32 | uint64(i[offset+2])<<24 | uint64(i[offset+3])<<16 | uint64(i[offset+4])<<8 | uint64(i[offset+5])
+	offset += 6
+
+	// Level idc
+	d.LevelIDC = i[offset]
+	offset += 1
+
+	// Flags
+	d.TemporalLayerSubsetFlag = i[offset]&0x80 > 0
+	d.HEVCStillPresentFlag = i[offset]&0x40 > 0
+	d.HEVC24HourPicturePresentFlag = i[offset]&0x20 > 0
+	d.SubPicHRDParamsNotPresentFlag = i[offset]&0x10 > 0
+	d.HDRWCGIDC = i[offset] & 0x3
+	offset += 1
+
+	// Temporal IDs
+	if d.TemporalLayerSubsetFlag && offset+2 <= len(i) {
+		d.TemporalIDMin = i[offset] >> 5
+		d.TemporalIDMax = i[offset+1] >> 5
+	}
+	return
+}
+
+func writeDescriptorHEVCVideo(d *DescriptorHEVCVideo) (b []byte) {
+	// Profile space, tier flag and profile idc
+	var v = (d.ProfileSpace&0x3)<<6 | d.ProfileIDC&0x1f
+	if d.TierFlag {
+		v |= 0x20
+	}
+	b = append(b, v)
+
+	// Profile compatibility indication
+	b = append(b, uint8(d.ProfileCompatibilityIndication>>24), uint8(d.ProfileCompatibilityIndication>>16), uint8(d.ProfileCompatibilityIndication>>8), uint8(d.ProfileCompatibilityIndication))
+
+	// Flags and copied 44 bits
+	v = uint8(d.Copied44Bits>>40) & 0xf
+	if d.ProgressiveSourceFlag {
+		v |= 0x80
+	}
+	if d.InterlacedSourceFlag {
+		v |= 0x40
+	}
+	if d.NonPackedConstraintFlag {
+		v |= 0x20
+	}
+	if d.FrameOnlyConstraintFlag {
+		v |= 0x10
+	}
+	b = append(b, v, uint8(d.Copied44Bits>>32), uint8(d.Copied44Bits>>24), uint8(d.Copied44Bits>>16), uint8(d.Copied44Bits>>8), uint8(d.Copied44Bits))
+
+	// Level idc
+	b = append(b, d.LevelIDC)
+
+	// Flags
+	v = 0xc | d.HDRWCGIDC&0x3
+	if d.TemporalLayerSubsetFlag {
+		v |= 0x80
+	}
+	if d.HEVCStillPresentFlag {
+		v |= 0x40
+	}
+	if d.HEVC24HourPicturePresentFlag {
+		v |= 0x20
+	}
+	if d.SubPicHRDParamsNotPresentFlag {
+		v |= 0x10
+	}
+	b = append(b, v)
+
+	// Temporal IDs
+	if d.TemporalLayerSubsetFlag {
+		b = append(b, 0x1f|(d.TemporalIDMin&0x7)<<5, 0x1f|(d.TemporalIDMax&0x7)<<5)
+	}
+	return
+}
+
 // DescriptorISO639LanguageAndAudioType represents an ISO639 language descriptor
 type DescriptorISO639LanguageAndAudioType struct {
 	Language []byte
@@ -512,6 +1222,10 @@ func newDescriptorISO639LanguageAndAudioType(i []byte) *DescriptorISO639Language
 	}
 }
 
+func writeDescriptorISO639LanguageAndAudioType(d *DescriptorISO639LanguageAndAudioType) []byte {
+	return append(append([]byte{}, d.Language...), d.Type)
+}
+
 // DescriptorLocalTimeOffset represents a local time offset descriptor
 // Page: 84 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
 type DescriptorLocalTimeOffset struct {
@@ -562,25 +1276,492 @@ func newDescriptorLocalTimeOffset(i []byte) (d *DescriptorLocalTimeOffset) {
 	return
 }
 
-// DescriptorMaximumBitrate represents a maximum bitrate descriptor
-type DescriptorMaximumBitrate struct {
-	Bitrate uint32 // In bytes/second
-}
+func writeDescriptorLocalTimeOffset(d *DescriptorLocalTimeOffset) (b []byte) {
+	for _, itm := range d.Items {
+		// Country code
+		b = append(b, itm.CountryCode...)
 
-func newDescriptorMaximumBitrate(i []byte) *DescriptorMaximumBitrate {
-	return &DescriptorMaximumBitrate{Bitrate: (uint32(i[0]&0x3f)<<16 | uint32(i[1])<<8 | uint32(i[2])) * 50}
-}
+		// Country region ID and local time offset polarity
+		var v = itm.CountryRegionID<<2 | 0x2
+		if itm.LocalTimeOffsetPolarity {
+			v |= 0x1
+		}
+		b = append(b, v)
+
+		// Local time offset, time of change and next time offset
+		b = append(b, writeDVBDurationMinutes(itm.LocalTimeOffset)...)
+		b = append(b, writeDVBTime(itm.TimeOfChange)...)
+		b = append(b, writeDVBDurationMinutes(itm.NextTimeOffset)...)
+	}
+	return
+}
+
+// DescriptorLogicalChannel represents a logical channel descriptor, as specified by IEC 62216, the UK DTG D-Book and
+// NorDig version 1
+// Logical channel numbers are coded on 10 bits, or on 14 bits when the NorDig private data specifier is in effect
+// HD simulcast logical channel descriptors, which number the services replacing their SD simulcast on HD receivers,
+// share its structure
+// Link: https://www.nordig.org/wp-content/uploads/2016/03/NorDig-Unified-Requirements-ver.-2.6.pdf
+type DescriptorLogicalChannel struct {
+	Items     []*DescriptorLogicalChannelItem
+	Specifier uint32 // Private data specifier in effect when the descriptor was parsed
+}
+
+// DescriptorLogicalChannelItem represents a logical channel item descriptor
+type DescriptorLogicalChannelItem struct {
+	LogicalChannelNumber uint16
+	ServiceID            uint16
+	VisibleServiceFlag   bool
+}
+
+func newDescriptorLogicalChannel(i []byte, specifier uint32) (d *DescriptorLogicalChannel) {
+	// Init
+	d = &DescriptorLogicalChannel{Specifier: specifier}
+	var mask = uint16(0x3ff)
+	if specifier == PrivateDataSpecifierNorDig {
+		mask = 0x3fff
+	}
+
+	// Add items
+	for offset := 0; offset+4 <= len(i); offset += 4 {
+		d.Items = append(d.Items, newDescriptorLogicalChannelItem(i[offset:], mask))
+	}
+	return
+}
+
+func newDescriptorLogicalChannelItem(i []byte, mask uint16) *DescriptorLogicalChannelItem {
+	return &DescriptorLogicalChannelItem{
+		LogicalChannelNumber: (uint16(i[2])<<8 | uint16(i[3])) & mask,
+		ServiceID:            uint16(i[0])<<8 | uint16(i[1]),
+		VisibleServiceFlag:   i[2]&0x80 > 0,
+	}
+}
+
+func writeDescriptorLogicalChannel(d *DescriptorLogicalChannel) (b []byte) {
+	var mask = uint16(0x3ff)
+	if d.Specifier == PrivateDataSpecifierNorDig {
+		mask = 0x3fff
+	}
+	for _, itm := range d.Items {
+		b = append(b, writeDescriptorLogicalChannelItem(itm, mask)...)
+	}
+	return
+}
+
+func writeDescriptorLogicalChannelItem(itm *DescriptorLogicalChannelItem, mask uint16) []byte {
+	// Reserved bits are set, parsed descriptors being written from their raw content anyway
+	var v = 0x7fff&^mask | itm.LogicalChannelNumber&mask
+	if itm.VisibleServiceFlag {
+		v |= 0x8000
+	}
+	return []byte{uint8(itm.ServiceID >> 8), uint8(itm.ServiceID), uint8(v >> 8), uint8(v)}
+}
+
+// DescriptorLogicalChannelV2 represents a NorDig logical channel descriptor version 2, which defines channel lists
+// Link: https://www.nordig.org/wp-content/uploads/2016/03/NorDig-Unified-Requirements-ver.-2.6.pdf
+type DescriptorLogicalChannelV2 struct {
+	ChannelLists []*DescriptorLogicalChannelV2ChannelList
+}
+
+// DescriptorLogicalChannelV2ChannelList represents a channel list of a NorDig logical channel descriptor version 2
+type DescriptorLogicalChannelV2ChannelList struct {
+	ChannelListID          uint8
+	ChannelListName        []byte
+	CountryCode            []byte
+	DecodedChannelListName string // Set when a text decoder is provided
+	Items                  []*DescriptorLogicalChannelItem
+}
+
+func newDescriptorLogicalChannelV2(i []byte) (d *DescriptorLogicalChannelV2) {
+	// Init
+	d = &DescriptorLogicalChannelV2{}
+	var offset int
+
+	// Add channel lists, which must fill the descriptor exactly otherwise its content doesn't follow the NorDig structure
+	for offset < len(i) {
+		// Channel list ID
+		if offset+2 > len(i) {
+			return nil
+		}
+		var l = &DescriptorLogicalChannelV2ChannelList{ChannelListID: i[offset]}
+		offset += 1
+
+		// Channel list name
+		var length = int(i[offset])
+		offset += 1
+		if offset+length+4 > len(i) {
+			return nil
+		}
+		l.ChannelListName = i[offset : offset+length]
+		offset += length
+
+		// Country code
+		l.CountryCode = i[offset : offset+3]
+		offset += 3
+
+		// Items
+		length = int(i[offset])
+		offset += 1
+		var offsetEnd = offset + length
+		if offsetEnd > len(i) || length%4 > 0 {
+			return nil
+		}
+		for ; offset < offsetEnd; offset += 4 {
+			l.Items = append(l.Items, newDescriptorLogicalChannelItem(i[offset:], 0x3ff))
+		}
+		d.ChannelLists = append(d.ChannelLists, l)
+	}
+	return
+}
+
+func writeDescriptorLogicalChannelV2(d *DescriptorLogicalChannelV2) (b []byte) {
+	for _, l := range d.ChannelLists {
+		b = append(b, l.ChannelListID, uint8(len(l.ChannelListName)))
+		b = append(b, l.ChannelListName...)
+		b = append(b, l.CountryCode...)
+		b = append(b, uint8(4*len(l.Items)))
+		for _, itm := range l.Items {
+			b = append(b, writeDescriptorLogicalChannelItem(itm, 0x3ff)...)
+		}
+	}
+	return
+}
+
+// DescriptorMaximumBitrate represents a maximum bitrate descriptor
+type DescriptorMaximumBitrate struct {
+	Bitrate uint32 // In bytes/second
+}
+
+func newDescriptorMaximumBitrate(i []byte) *DescriptorMaximumBitrate {
+	return &DescriptorMaximumBitrate{Bitrate: (uint32(i[0]&0x3f)<<16 | uint32(i[1])<<8 | uint32(i[2])) * 50}
+}
+
+func writeDescriptorMaximumBitrate(d *DescriptorMaximumBitrate) []byte {
+	var b = d.Bitrate / 50
+	return []byte{0xc0 | uint8(b>>16)&0x3f, uint8(b >> 8), uint8(b)}
+}
+
+// DescriptorMetadata represents a metadata descriptor, which describes the format of a metadata stream
+// Page: 116 | Chapter: 2.6.60 | Link: ISO/IEC 13818-1
+type DescriptorMetadata struct {
+	ApplicationFormat              uint16
+	ApplicationFormatIdentifier    uint32 // Set when ApplicationFormat is MetadataApplicationFormatIdentifierField
+	DecoderConfig                  []byte // Decoder config, decoder config identification record or reserved data depending on DecoderConfigFlags
+	DecoderConfigFlags             uint8
+	DecoderConfigMetadataServiceID uint8
+	Format                         uint8
+	FormatIdentifier               uint32 // Set when Format is MetadataFormatIdentifierField
+	HasDSMCC                       bool
+	PrivateData                    []byte
+	ServiceID                      uint8
+	ServiceIdentification          []byte // Set when HasDSMCC is true
+}
+
+func newDescriptorMetadata(i []byte) (d *DescriptorMetadata) {
+	// Init
+	d = &DescriptorMetadata{}
+	var offset int
+
+	// Application format
+	if offset+2 > len(i) {
+		return
+	}
+	d.ApplicationFormat = uint16(i[offset])<<8 | uint16(i[offset+1])
+	offset += 2
+	if d.ApplicationFormat == MetadataApplicationFormatIdentifierField {
+		if offset+4 > len(i) {
+			return
+		}
+		d.ApplicationFormatIdentifier = uint32(i[offset])<<24 | uint32(i[offset+1])<<16 | uint32(i[offset+2])<<8 | uint32(i[offset+3])
+		offset += 4
+	}
+
+	// Format
+	if offset >= len(i) {
+		return
+	}
+	d.Format = i[offset]
+	offset++
+	if d.Format == MetadataFormatIdentifierField {
+		if offset+4 > len(i) {
+			return
+		}
+		d.FormatIdentifier = uint32(i[offset])<<24 | uint32(i[offset+1])<<16 | uint32(i[offset+2])<<8 | uint32(i[offset+3])
+		offset += 4
+	}
+
+	// Service ID and flags
+	if offset+2 > len(i) {
+		return
+	}
+	d.ServiceID = i[offset]
+	d.DecoderConfigFlags = i[offset+1] >> 5
+	d.HasDSMCC = i[offset+1]&0x10 > 0
+	offset += 2
+
+	// Service identification
+	if d.HasDSMCC {
+		if offset >= len(i) || offset+1+int(i[offset]) > len(i) {
+			return
+		}
+		d.ServiceIdentification = i[offset+1 : offset+1+int(i[offset])]
+		offset += 1 + int(i[offset])
+	}
+
+	// Decoder config
+	switch d.DecoderConfigFlags {
+	case metadataDecoderConfigFlagsConfig, metadataDecoderConfigFlagsConfigIdentification,
+		metadataDecoderConfigFlagsReserved1, metadataDecoderConfigFlagsReserved2:
+		if offset >= len(i) || offset+1+int(i[offset]) > len(i) {
+			return
+		}
+		d.DecoderConfig = i[offset+1 : offset+1+int(i[offset])]
+		offset += 1 + int(i[offset])
+	case metadataDecoderConfigFlagsMetadataServiceID:
+		if offset >= len(i) {
+			return
+		}
+		d.DecoderConfigMetadataServiceID = i[offset]
+		offset++
+	}
+
+	// Private data
+	if offset < len(i) {
+		d.PrivateData = i[offset:]
+	}
+	return
+}
+
+func writeDescriptorMetadata(d *DescriptorMetadata) (b []byte) {
+	// Application format
+	b = append(b, uint8(d.ApplicationFormat>>8), uint8(d.ApplicationFormat))
+	if d.ApplicationFormat == MetadataApplicationFormatIdentifierField {
+		b = append(b, uint8(d.ApplicationFormatIdentifier>>24), uint8(d.ApplicationFormatIdentifier>>16), uint8(d.ApplicationFormatIdentifier>>8), uint8(d.ApplicationFormatIdentifier))
+	}
+
+	// Format
+	b = append(b, d.Format)
+	if d.Format == MetadataFormatIdentifierField {
+		b = append(b, uint8(d.FormatIdentifier>>24), uint8(d.FormatIdentifier>>16), uint8(d.FormatIdentifier>>8), uint8(d.FormatIdentifier))
+	}
+
+	// Service ID and flags
+	var flags = d.DecoderConfigFlags<<5 | 0xf
+	if d.HasDSMCC {
+		flags |= 0x10
+	}
+	b = append(b, d.ServiceID, flags)
+
+	// Service identification
+	if d.HasDSMCC {
+		b = append(b, uint8(len(d.ServiceIdentification)))
+		b = append(b, d.ServiceIdentification...)
+	}
+
+	// Decoder config
+	switch d.DecoderConfigFlags {
+	case metadataDecoderConfigFlagsConfig, metadataDecoderConfigFlagsConfigIdentification,
+		metadataDecoderConfigFlagsReserved1, metadataDecoderConfigFlagsReserved2:
+		b = append(b, uint8(len(d.DecoderConfig)))
+		b = append(b, d.DecoderConfig...)
+	case metadataDecoderConfigFlagsMetadataServiceID:
+		b = append(b, d.DecoderConfigMetadataServiceID)
+	}
+
+	// Private data
+	b = append(b, d.PrivateData...)
+	return
+}
+
+// DescriptorMultilingualComponent represents a multilingual component descriptor
+// Chapter: 6.2.24 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+type DescriptorMultilingualComponent struct {
+	ComponentTag uint8
+	Items        []*DescriptorMultilingualComponentItem
+}
+
+// DescriptorMultilingualComponentItem represents a multilingual component item descriptor
+type DescriptorMultilingualComponentItem struct {
+	DecodedText        string // Set when a text decoder is provided
+	ISO639LanguageCode []byte
+	Text               []byte
+}
+
+// Item returns the item of a language, or nil if the language is not signaled
+func (d *DescriptorMultilingualComponent) Item(language string) *DescriptorMultilingualComponentItem {
+	for _, itm := range d.Items {
+		if string(itm.ISO639LanguageCode) == language {
+			return itm
+		}
+	}
+	return nil
+}
+
+func newDescriptorMultilingualComponent(i []byte) (d *DescriptorMultilingualComponent) {
+	// Init
+	d = &DescriptorMultilingualComponent{ComponentTag: i[0]}
+	var offset = 1
+
+	// Add items
+	for offset+4 <= len(i) {
+		var itm = &DescriptorMultilingualComponentItem{ISO639LanguageCode: i[offset : offset+3]}
+		offset += 3
+		itm.Text = parseMultilingualString(i, &offset)
+		d.Items = append(d.Items, itm)
+	}
+	return
+}
+
+func writeDescriptorMultilingualComponent(d *DescriptorMultilingualComponent) (b []byte) {
+	b = append(b, d.ComponentTag)
+	for _, itm := range d.Items {
+		b = append(b, itm.ISO639LanguageCode...)
+		b = append(b, uint8(len(itm.Text)))
+		b = append(b, itm.Text...)
+	}
+	return
+}
+
+// DescriptorMultilingualName represents a multilingual network name or a multilingual bouquet name descriptor
+// Chapter: 6.2.23 and 6.2.25 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+type DescriptorMultilingualName struct {
+	Items []*DescriptorMultilingualNameItem
+}
+
+// DescriptorMultilingualNameItem represents a multilingual name item descriptor
+type DescriptorMultilingualNameItem struct {
+	DecodedName        string // Set when a text decoder is provided
+	ISO639LanguageCode []byte
+	Name               []byte
+}
+
+// Item returns the item of a language, or nil if the language is not signaled
+func (d *DescriptorMultilingualName) Item(language string) *DescriptorMultilingualNameItem {
+	for _, itm := range d.Items {
+		if string(itm.ISO639LanguageCode) == language {
+			return itm
+		}
+	}
+	return nil
+}
+
+func newDescriptorMultilingualName(i []byte) (d *DescriptorMultilingualName) {
+	// Init
+	d = &DescriptorMultilingualName{}
+	var offset int
+
+	// Add items
+	for offset+4 <= len(i) {
+		var itm = &DescriptorMultilingualNameItem{ISO639LanguageCode: i[offset : offset+3]}
+		offset += 3
+		itm.Name = parseMultilingualString(i, &offset)
+		d.Items = append(d.Items, itm)
+	}
+	return
+}
+
+func writeDescriptorMultilingualName(d *DescriptorMultilingualName) (b []byte) {
+	for _, itm := range d.Items {
+		b = append(b, itm.ISO639LanguageCode...)
+		b = append(b, uint8(len(itm.Name)))
+		b = append(b, itm.Name...)
+	}
+	return
+}
+
+// DescriptorMultilingualServiceName represents a multilingual service name descriptor
+// Chapter: 6.2.26 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+type DescriptorMultilingualServiceName struct {
+	Items []*DescriptorMultilingualServiceNameItem
+}
+
+// DescriptorMultilingualServiceNameItem represents a multilingual service name item descriptor
+type DescriptorMultilingualServiceNameItem struct {
+	DecodedName        string // Set when a text decoder is provided
+	DecodedProvider    string // Set when a text decoder is provided
+	ISO639LanguageCode []byte
+	Name               []byte
+	Provider           []byte
+}
+
+// Item returns the item of a language, or nil if the language is not signaled
+func (d *DescriptorMultilingualServiceName) Item(language string) *DescriptorMultilingualServiceNameItem {
+	for _, itm := range d.Items {
+		if string(itm.ISO639LanguageCode) == language {
+			return itm
+		}
+	}
+	return nil
+}
+
+func newDescriptorMultilingualServiceName(i []byte) (d *DescriptorMultilingualServiceName) {
+	// Init
+	d = &DescriptorMultilingualServiceName{}
+	var offset int
+
+	// Add items
+	for offset+5 <= len(i) {
+		var itm = &DescriptorMultilingualServiceNameItem{ISO639LanguageCode: i[offset : offset+3]}
+		offset += 3
+		itm.Provider = parseMultilingualString(i, &offset)
+		itm.Name = parseMultilingualString(i, &offset)
+		d.Items = append(d.Items, itm)
+	}
+	return
+}
+
+func writeDescriptorMultilingualServiceName(d *DescriptorMultilingualServiceName) (b []byte) {
+	for _, itm := range d.Items {
+		b = append(b, itm.ISO639LanguageCode...)
+		b = append(b, uint8(len(itm.Provider)))
+		b = append(b, itm.Provider...)
+		b = append(b, uint8(len(itm.Name)))
+		b = append(b, itm.Name...)
+	}
+	return
+}
+
+// parseMultilingualString parses a string prefixed with its length, truncated to the bytes available
+func parseMultilingualString(i []byte, offset *int) (o []byte) {
+	// Length
+	if *offset >= len(i) {
+		return
+	}
+	var end = *offset + 1 + int(i[*offset])
+	if end > len(i) {
+		end = len(i)
+	}
+
+	// String
+	o = i[*offset+1 : end]
+	*offset = end
+	return
+}
 
 // DescriptorNetworkName represents a network name descriptor
 // Page: 93 | Chapter: 6.2.27 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
 type DescriptorNetworkName struct {
-	Name []byte
+	DecodedName string // Set when a text decoder is provided
+	Name        []byte
 }
 
 func newDescriptorNetworkName(i []byte) *DescriptorNetworkName {
 	return &DescriptorNetworkName{Name: i}
 }
 
+// NewDescriptorNetworkName creates a new network name descriptor
+func NewDescriptorNetworkName(name string) *Descriptor {
+	return &Descriptor{
+		Length:      uint8(len(name)),
+		NetworkName: &DescriptorNetworkName{Name: []byte(name)},
+		Tag:         DescriptorTagNetworkName,
+	}
+}
+
+func writeDescriptorNetworkName(d *DescriptorNetworkName) []byte {
+	return append([]byte{}, d.Name...)
+}
+
 // DescriptorParentalRating represents a parental rating descriptor
 // Page: 93 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
 type DescriptorParentalRating struct {
@@ -596,19 +1777,35 @@ type DescriptorParentalRatingItem struct {
 // MinimumAge returns the minimum age for the parental rating
 func (d DescriptorParentalRatingItem) MinimumAge() int {
 	// Undefined or user defined ratings
-	if d.Rating == 0 || d.Rating > 0x10 {
+	if d.Rating == 0 || d.Rating > 0xf {
 		return 0
 	}
 	return int(d.Rating) + 3
 }
 
+// IsUserDefined checks whether the rating is defined by the broadcaster rather than being a minimum age
+func (d DescriptorParentalRatingItem) IsUserDefined() bool {
+	return d.Rating > 0xf
+}
+
+// MinimumAge returns the minimum age signaled for a country, which is 0 when the rating is undefined or defined by the
+// broadcaster, and false when the country is not signaled
+func (d *DescriptorParentalRating) MinimumAge(countryCode string) (int, bool) {
+	for _, itm := range d.Items {
+		if string(itm.CountryCode) == countryCode {
+			return itm.MinimumAge(), true
+		}
+	}
+	return 0, false
+}
+
 func newDescriptorParentalRating(i []byte) (d *DescriptorParentalRating) {
 	// Init
 	d = &DescriptorParentalRating{}
 	var offset int
 
 	// Add items
-	for offset < len(i) {
+	for offset+4 <= len(i) {
 		d.Items = append(d.Items, &DescriptorParentalRatingItem{
 			CountryCode: i[offset : offset+3],
 			Rating:      uint8(i[offset+3]),
@@ -618,6 +1815,14 @@ func newDescriptorParentalRating(i []byte) (d *DescriptorParentalRating) {
 	return
 }
 
+func writeDescriptorParentalRating(d *DescriptorParentalRating) (b []byte) {
+	for _, itm := range d.Items {
+		b = append(b, itm.CountryCode...)
+		b = append(b, itm.Rating)
+	}
+	return
+}
+
 // DescriptorPrivateDataIndicator represents a private data Indicator descriptor
 type DescriptorPrivateDataIndicator struct {
 	Indicator uint32
@@ -627,6 +1832,10 @@ func newDescriptorPrivateDataIndicator(i []byte) *DescriptorPrivateDataIndicator
 	return &DescriptorPrivateDataIndicator{Indicator: uint32(i[0])<<24 | uint32(i[1])<<16 | uint32(i[2])<<8 | uint32(i[3])}
 }
 
+func writeDescriptorPrivateDataIndicator(d *DescriptorPrivateDataIndicator) []byte {
+	return []byte{uint8(d.Indicator >> 24), uint8(d.Indicator >> 16), uint8(d.Indicator >> 8), uint8(d.Indicator)}
+}
+
 // DescriptorPrivateDataSpecifier represents a private data specifier descriptor
 type DescriptorPrivateDataSpecifier struct {
 	Specifier uint32
@@ -636,6 +1845,10 @@ func newDescriptorPrivateDataSpecifier(i []byte) *DescriptorPrivateDataSpecifier
 	return &DescriptorPrivateDataSpecifier{Specifier: uint32(i[0])<<24 | uint32(i[1])<<16 | uint32(i[2])<<8 | uint32(i[3])}
 }
 
+func writeDescriptorPrivateDataSpecifier(d *DescriptorPrivateDataSpecifier) []byte {
+	return []byte{uint8(d.Specifier >> 24), uint8(d.Specifier >> 16), uint8(d.Specifier >> 8), uint8(d.Specifier)}
+}
+
 // DescriptorRegistration represents a registration descriptor
 // Page: 84 | http://ecee.colorado.edu/~ecen5653/ecen5653/papers/iso13818-1.pdf
 type DescriptorRegistration struct {
@@ -643,6 +1856,17 @@ type DescriptorRegistration struct {
 	FormatIdentifier             uint32
 }
 
+// FourCC returns the format identifier as 4 characters, non printable bytes being replaced by dots
+func (d *DescriptorRegistration) FourCC() string {
+	var b = []byte{uint8(d.FormatIdentifier >> 24), uint8(d.FormatIdentifier >> 16), uint8(d.FormatIdentifier >> 8), uint8(d.FormatIdentifier)}
+	for idx := range b {
+		if b[idx] < 0x20 || b[idx] > 0x7e {
+			b[idx] = '.'
+		}
+	}
+	return string(b)
+}
+
 func newDescriptorRegistration(i []byte) (d *DescriptorRegistration) {
 	d = &DescriptorRegistration{}
 	d.FormatIdentifier = uint32(i[0])<<24 | uint32(i[1])<<16 | uint32(i[2])<<8 | uint32(i[3])
@@ -654,12 +1878,65 @@ func newDescriptorRegistration(i []byte) (d *DescriptorRegistration) {
 	return
 }
 
+func writeDescriptorRegistration(d *DescriptorRegistration) []byte {
+	var b = []byte{uint8(d.FormatIdentifier >> 24), uint8(d.FormatIdentifier >> 16), uint8(d.FormatIdentifier >> 8), uint8(d.FormatIdentifier)}
+	return append(b, d.AdditionalIdentificationInfo...)
+}
+
+// DescriptorSatelliteDeliverySystem represents a satellite delivery system descriptor
+// Chapter: 6.2.13.2 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+type DescriptorSatelliteDeliverySystem struct {
+	FECInner         uint8
+	Frequency        uint64 // In Hz, with a precision of 10 kHz
+	ModulationSystem uint8
+	ModulationType   uint8
+	OrbitalPosition  uint16 // In tenths of a degree
+	Polarization     uint8
+	RollOff          uint8  // Only relevant for DVB-S2
+	SymbolRate       uint32 // In symbols/s, with a precision of 100 symbols/s
+	WestEastFlag     bool   // Whether the orbital position is in the eastern part of the orbit
+}
+
+func newDescriptorSatelliteDeliverySystem(i []byte) *DescriptorSatelliteDeliverySystem {
+	return &DescriptorSatelliteDeliverySystem{
+		FECInner:         i[10] & 0xf,
+		Frequency:        parseDVBBCD(i[0:4], 8) * 10000,
+		ModulationSystem: i[6] >> 2 & 0x1,
+		ModulationType:   i[6] & 0x3,
+		OrbitalPosition:  uint16(parseDVBBCD(i[4:6], 4)),
+		Polarization:     i[6] >> 5 & 0x3,
+		RollOff:          i[6] >> 3 & 0x3,
+		SymbolRate:       uint32(parseDVBBCD(i[7:11], 7)) * 100,
+		WestEastFlag:     i[6]&0x80 > 0,
+	}
+}
+
+func writeDescriptorSatelliteDeliverySystem(d *DescriptorSatelliteDeliverySystem) (b []byte) {
+	// Frequency and orbital position
+	b = append(b, writeDVBBCD(d.Frequency/10000, 8)...)
+	b = append(b, writeDVBBCD(uint64(d.OrbitalPosition), 4)...)
+
+	// Flags
+	var v = d.Polarization&0x3<<5 | d.RollOff&0x3<<3 | d.ModulationSystem&0x1<<2 | d.ModulationType&0x3
+	if d.WestEastFlag {
+		v |= 0x80
+	}
+	b = append(b, v)
+
+	// Symbol rate and FEC inner
+	b = append(b, writeDVBBCD(uint64(d.SymbolRate/100), 7)...)
+	b[len(b)-1] |= d.FECInner & 0xf
+	return
+}
+
 // DescriptorService represents a service descriptor
 // Page: 96 | Chapter: 6.2.33 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
 type DescriptorService struct {
-	Name     []byte
-	Provider []byte
-	Type     uint8
+	DecodedName     string // Set when a text decoder is provided
+	DecodedProvider string // Set when a text decoder is provided
+	Name            []byte
+	Provider        []byte
+	Type            uint8
 }
 
 func newDescriptorService(i []byte) (d *DescriptorService) {
@@ -676,12 +1953,35 @@ func newDescriptorService(i []byte) (d *DescriptorService) {
 	return
 }
 
+// NewDescriptorService creates a new service descriptor
+func NewDescriptorService(serviceType uint8, provider, name string) *Descriptor {
+	return &Descriptor{
+		Length: uint8(3 + len(provider) + len(name)),
+		Service: &DescriptorService{
+			Name:     []byte(name),
+			Provider: []byte(provider),
+			Type:     serviceType,
+		},
+		Tag: DescriptorTagService,
+	}
+}
+
+func writeDescriptorService(d *DescriptorService) (b []byte) {
+	b = append(b, d.Type, uint8(len(d.Provider)))
+	b = append(b, d.Provider...)
+	b = append(b, uint8(len(d.Name)))
+	b = append(b, d.Name...)
+	return
+}
+
 // DescriptorShortEvent represents a short event descriptor
 // Page: 99 | Chapter: 6.2.37 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
 type DescriptorShortEvent struct {
-	EventName []byte
-	Language  []byte
-	Text      []byte
+	DecodedEventName string // Set when a text decoder is provided
+	DecodedText      string // Set when a text decoder is provided
+	EventName        []byte
+	Language         []byte
+	Text             []byte
 }
 
 func newDescriptorShortEvent(i []byte) (d *DescriptorShortEvent) {
@@ -699,6 +1999,28 @@ func newDescriptorShortEvent(i []byte) (d *DescriptorShortEvent) {
 	return
 }
 
+// NewDescriptorShortEvent creates a new short event descriptor
+func NewDescriptorShortEvent(language, eventName, text string) *Descriptor {
+	return &Descriptor{
+		Length: uint8(2 + len(language) + len(eventName) + len(text)),
+		ShortEvent: &DescriptorShortEvent{
+			EventName: []byte(eventName),
+			Language:  []byte(language),
+			Text:      []byte(text),
+		},
+		Tag: DescriptorTagShortEvent,
+	}
+}
+
+func writeDescriptorShortEvent(d *DescriptorShortEvent) (b []byte) {
+	b = append(b, d.Language...)
+	b = append(b, uint8(len(d.EventName)))
+	b = append(b, d.EventName...)
+	b = append(b, uint8(len(d.Text)))
+	b = append(b, d.Text...)
+	return
+}
+
 // DescriptorStreamIdentifier represents a stream identifier descriptor
 // Page: 102 | Chapter: 6.2.39 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
 type DescriptorStreamIdentifier struct{ ComponentTag uint8 }
@@ -707,6 +2029,10 @@ func newDescriptorStreamIdentifier(i []byte) *DescriptorStreamIdentifier {
 	return &DescriptorStreamIdentifier{ComponentTag: uint8(i[0])}
 }
 
+func writeDescriptorStreamIdentifier(d *DescriptorStreamIdentifier) []byte {
+	return []byte{d.ComponentTag}
+}
+
 // DescriptorSubtitling represents a subtitling descriptor
 // Page: 103 | Chapter: 6.2.41 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
 type DescriptorSubtitling struct {
@@ -715,10 +2041,15 @@ type DescriptorSubtitling struct {
 
 // DescriptorSubtitlingItem represents subtitling descriptor item
 type DescriptorSubtitlingItem struct {
-	AncillaryPageID   uint16
-	CompositionPageID uint16
+	AncillaryPageID   uint16 // Page carrying the segments shared by several subtitle services
+	CompositionPageID uint16 // Page carrying the segments specific to this subtitle service
 	Language          []byte
-	Type              uint8
+	Type              uint8 // Same values as the component type of the component descriptor, see SubtitlingType constants
+}
+
+// IsHardOfHearing checks whether the item describes subtitles intended for the hard of hearing
+func (d *DescriptorSubtitlingItem) IsHardOfHearing() bool {
+	return d.Type >= SubtitlingTypeDVBSubtitlesHardOfHearing && d.Type <= SubtitlingTypeDVBSubtitlesHardOfHearingHD
 }
 
 func newDescriptorSubtitling(i []byte) (d *DescriptorSubtitling) {
@@ -739,6 +2070,14 @@ func newDescriptorSubtitling(i []byte) (d *DescriptorSubtitling) {
 	return
 }
 
+func writeDescriptorSubtitling(d *DescriptorSubtitling) (b []byte) {
+	for _, itm := range d.Items {
+		b = append(b, itm.Language...)
+		b = append(b, itm.Type, uint8(itm.CompositionPageID>>8), uint8(itm.CompositionPageID), uint8(itm.AncillaryPageID>>8), uint8(itm.AncillaryPageID))
+	}
+	return
+}
+
 // DescriptorTeletext represents a teletext descriptor
 // Page: 105 | Chapter: 6.2.43 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
 type DescriptorTeletext struct {
@@ -770,6 +2109,82 @@ func newDescriptorTeletext(i []byte) (d *DescriptorTeletext) {
 	return
 }
 
+func writeDescriptorTeletext(d *DescriptorTeletext) (b []byte) {
+	for _, itm := range d.Items {
+		b = append(b, itm.Language...)
+		b = append(b, itm.Type<<3|itm.Magazine&0x7, itm.Page/10%10<<4|itm.Page%10)
+	}
+	return
+}
+
+// DescriptorTerrestrialDeliverySystem represents a terrestrial delivery system descriptor
+// Chapter: 6.2.13.4 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+type DescriptorTerrestrialDeliverySystem struct {
+	Bandwidth            uint8
+	CodeRateHPStream     uint8
+	CodeRateLPStream     uint8
+	Constellation        uint8
+	Frequency            uint64 // Centre frequency, in Hz
+	GuardInterval        uint8
+	HierarchyInformation uint8
+	HighPriority         bool // Whether the stream is the high priority stream when hierarchical modulation is used
+	MPEFECIndicator      bool // Unset when MPE-FEC is used
+	OtherFrequencyFlag   bool // Whether other frequencies are in use
+	TimeSlicingIndicator bool // Unset when time slicing is used
+	TransmissionMode     uint8
+}
+
+func newDescriptorTerrestrialDeliverySystem(i []byte) *DescriptorTerrestrialDeliverySystem {
+	return &DescriptorTerrestrialDeliverySystem{
+		Bandwidth:            i[4] >> 5,
+		CodeRateHPStream:     i[5] & 0x7,
+		CodeRateLPStream:     i[6] >> 5,
+		Constellation:        i[5] >> 6,
+		Frequency:            uint64(uint32(i[0])<<24|uint32(i[1])<<16|uint32(i[2])<<8|uint32(i[3])) * 10,
+		GuardInterval:        i[6] >> 3 & 0x3,
+		HierarchyInformation: i[5] >> 3 & 0x7,
+		HighPriority:         i[4]&0x10 > 0,
+		MPEFECIndicator:      i[4]&0x4 > 0,
+		OtherFrequencyFlag:   i[6]&0x1 > 0,
+		TimeSlicingIndicator: i[4]&0x8 > 0,
+		TransmissionMode:     i[6] >> 1 & 0x3,
+	}
+}
+
+func writeDescriptorTerrestrialDeliverySystem(d *DescriptorTerrestrialDeliverySystem) []byte {
+	// Bandwidth, priority, time slicing and MPE-FEC indicators
+	var f = uint32(d.Frequency / 10)
+	var b = []byte{uint8(f >> 24), uint8(f >> 16), uint8(f >> 8), uint8(f), d.Bandwidth<<5 | 0x3}
+	if d.HighPriority {
+		b[4] |= 0x10
+	}
+	if d.TimeSlicingIndicator {
+		b[4] |= 0x8
+	}
+	if d.MPEFECIndicator {
+		b[4] |= 0x4
+	}
+
+	// Constellation, hierarchy, code rates, guard interval, transmission mode and other frequency flag
+	b = append(b, d.Constellation&0x3<<6|d.HierarchyInformation&0x7<<3|d.CodeRateHPStream&0x7)
+	var v = d.CodeRateLPStream&0x7<<5 | d.GuardInterval&0x3<<3 | d.TransmissionMode&0x3<<1
+	if d.OtherFrequencyFlag {
+		v |= 0x1
+	}
+	return append(b, v, 0xff, 0xff, 0xff, 0xff)
+}
+
+// DescriptorUnknown represents a descriptor whose tag is not supported
+// Its content is kept untouched so that it can be serialized back byte for byte
+type DescriptorUnknown struct {
+	Data []byte
+	Tag  uint8
+}
+
+func newDescriptorUnknown(tag uint8, i []byte) *DescriptorUnknown {
+	return &DescriptorUnknown{Data: append([]byte{}, i...), Tag: tag}
+}
+
 // DescriptorVBIData represents a VBI data descriptor
 // Page: 108 | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
 type DescriptorVBIData struct {
@@ -829,6 +2244,93 @@ func newDescriptorVBIData(i []byte) (d *DescriptorVBIData) {
 	return
 }
 
+func writeDescriptorVBIData(d *DescriptorVBIData) (b []byte) {
+	for _, srv := range d.Services {
+		b = append(b, srv.DataServiceID, uint8(len(srv.Descriptors)))
+		for _, dsc := range srv.Descriptors {
+			var v = 0xc0 | dsc.LineOffset&0x1f
+			if dsc.FieldParity {
+				v |= 0x20
+			}
+			b = append(b, v)
+		}
+	}
+	return
+}
+
+// DescriptorVideoStream represents a video stream descriptor
+// Profile, level and chroma format fields are only set for MPEG-2 video, i.e. when the MPEG-1 only flag is false
+// Chapter: 2.6.2 | Link: http://ecee.colorado.edu/~ecen5653/ecen5653/papers/iso13818-1.pdf
+type DescriptorVideoStream struct {
+	ChromaFormat              uint8
+	ConstrainedParameterFlag  bool
+	FrameRateCode             uint8
+	FrameRateExtensionFlag    bool
+	MPEG1OnlyFlag             bool
+	MultipleFrameRateFlag     bool
+	ProfileAndLevelIndication uint8
+	StillPictureFlag          bool
+}
+
+// videoStreamFrameRates are the frame rates indexed by frame rate code, as specified by ISO/IEC 13818-2
+var videoStreamFrameRates = []float64{0, 24000.0 / 1001, 24, 25, 30000.0 / 1001, 30, 50, 60000.0 / 1001, 60}
+
+// FrameRate returns the frame rate of the frame rate code, or 0 if it's forbidden or reserved
+// When the multiple frame rate flag is set, the video may also use other frame rates, lower than this one
+func (d *DescriptorVideoStream) FrameRate() float64 {
+	if int(d.FrameRateCode) < len(videoStreamFrameRates) {
+		return videoStreamFrameRates[d.FrameRateCode]
+	}
+	return 0
+}
+
+func newDescriptorVideoStream(i []byte) (d *DescriptorVideoStream) {
+	// Init
+	d = &DescriptorVideoStream{
+		ConstrainedParameterFlag: i[0]&0x2 > 0,
+		FrameRateCode:            i[0] >> 3 & 0xf,
+		MPEG1OnlyFlag:            i[0]&0x4 > 0,
+		MultipleFrameRateFlag:    i[0]&0x80 > 0,
+		StillPictureFlag:         i[0]&0x1 > 0,
+	}
+
+	// MPEG-2 fields
+	if !d.MPEG1OnlyFlag && len(i) >= 3 {
+		d.ProfileAndLevelIndication = i[1]
+		d.ChromaFormat = i[2] >> 6
+		d.FrameRateExtensionFlag = i[2]&0x20 > 0
+	}
+	return
+}
+
+func writeDescriptorVideoStream(d *DescriptorVideoStream) (b []byte) {
+	// Flags
+	var v = (d.FrameRateCode & 0xf) << 3
+	if d.MultipleFrameRateFlag {
+		v |= 0x80
+	}
+	if d.MPEG1OnlyFlag {
+		v |= 0x4
+	}
+	if d.ConstrainedParameterFlag {
+		v |= 0x2
+	}
+	if d.StillPictureFlag {
+		v |= 0x1
+	}
+	b = append(b, v)
+
+	// MPEG-2 fields
+	if !d.MPEG1OnlyFlag {
+		v = 0x1f | (d.ChromaFormat&0x3)<<6
+		if d.FrameRateExtensionFlag {
+			v |= 0x20
+		}
+		b = append(b, d.ProfileAndLevelIndication, v)
+	}
+	return
+}
+
 // parseDescriptors parses descriptors
 func parseDescriptors(i []byte, offset *int) (o []*Descriptor) {
 	// Get length
@@ -837,82 +2339,281 @@ func parseDescriptors(i []byte, offset *int) (o []*Descriptor) {
 
 	// Loop
 	if length > 0 {
-		length += *offset
-		for *offset < length {
-			// Init
-			var d = &Descriptor{
-				Length: uint8(i[*offset+1]),
-				Tag:    uint8(i[*offset]),
-			}
-			*offset += 2
-
-			// Parse data
-			if d.Length > 0 {
-				// Get descriptor content
-				var b = i[*offset : *offset+int(d.Length)]
-
-				// User defined
-				if d.Tag >= 0x80 && d.Tag <= 0xfe {
-					d.UserDefined = make([]byte, len(b))
-					copy(d.UserDefined, b)
-				} else {
-					// Switch on tag
-					switch d.Tag {
-					case DescriptorTagAC3:
-						d.AC3 = newDescriptorAC3(b)
-					case DescriptorTagAVCVideo:
-						d.AVCVideo = newDescriptorAVCVideo(b)
-					case DescriptorTagComponent:
-						d.Component = newDescriptorComponent(b)
-					case DescriptorTagContent:
-						d.Content = newDescriptorContent(b)
-					case DescriptorTagDataStreamAlignment:
-						d.DataStreamAlignment = newDescriptorDataStreamAlignment(b)
-					case DescriptorTagEnhancedAC3:
-						d.EnhancedAC3 = newDescriptorEnhancedAC3(b)
-					case DescriptorTagExtendedEvent:
-						d.ExtendedEvent = newDescriptorExtendedEvent(b)
-					case DescriptorTagExtension:
-						d.Extension = newDescriptorExtension(b)
-					case DescriptorTagISO639LanguageAndAudioType:
-						d.ISO639LanguageAndAudioType = newDescriptorISO639LanguageAndAudioType(b)
-					case DescriptorTagLocalTimeOffset:
-						d.LocalTimeOffset = newDescriptorLocalTimeOffset(b)
-					case DescriptorTagMaximumBitrate:
-						d.MaximumBitrate = newDescriptorMaximumBitrate(b)
-					case DescriptorTagNetworkName:
-						d.NetworkName = newDescriptorNetworkName(b)
-					case DescriptorTagParentalRating:
-						d.ParentalRating = newDescriptorParentalRating(b)
-					case DescriptorTagPrivateDataIndicator:
-						d.PrivateDataIndicator = newDescriptorPrivateDataIndicator(b)
-					case DescriptorTagPrivateDataSpecifier:
-						d.PrivateDataSpecifier = newDescriptorPrivateDataSpecifier(b)
-					case DescriptorTagRegistration:
-						d.Registration = newDescriptorRegistration(b)
-					case DescriptorTagService:
-						d.Service = newDescriptorService(b)
-					case DescriptorTagShortEvent:
-						d.ShortEvent = newDescriptorShortEvent(b)
-					case DescriptorTagStreamIdentifier:
-						d.StreamIdentifier = newDescriptorStreamIdentifier(b)
-					case DescriptorTagSubtitling:
-						d.Subtitling = newDescriptorSubtitling(b)
-					case DescriptorTagTeletext:
-						d.Teletext = newDescriptorTeletext(b)
-					case DescriptorTagVBIData:
-						d.VBIData = newDescriptorVBIData(b)
-					case DescriptorTagVBITeletext:
-						d.VBITeletext = newDescriptorTeletext(b)
-					default:
-						// TODO Remove this log
-						astilog.Debugf("astits: unlisted descriptor tag 0x%x", d.Tag)
-					}
+		o = parseDescriptorsLoop(i, offset, *offset+length)
+	}
+	return
+}
+
+// parseDescriptorsLoop parses descriptors until the end offset is reached
+// User defined descriptors are interpreted based on the private data specifier in effect, which is set by the last
+// private data specifier descriptor of the loop
+func parseDescriptorsLoop(i []byte, offset *int, offsetEnd int) (o []*Descriptor) {
+	var specifier uint32
+	for *offset < offsetEnd {
+		// Init
+		var d = &Descriptor{
+			Length: uint8(i[*offset+1]),
+			Tag:    uint8(i[*offset]),
+		}
+		*offset += 2
+
+		// Parse data
+		if d.Length > 0 {
+			// Get descriptor content
+			var b = i[*offset : *offset+int(d.Length)]
+
+			// User defined
+			if d.Tag >= 0x80 && d.Tag <= 0xfe {
+				d.UserDefined = make([]byte, len(b))
+				copy(d.UserDefined, b)
+				parseUserDefinedDescriptor(d, specifier)
+			} else {
+				// Switch on tag
+				switch d.Tag {
+				case DescriptorTagAC3:
+					d.AC3 = newDescriptorAC3(b)
+				case DescriptorTagApplicationSignalling:
+					d.ApplicationSignalling = newDescriptorApplicationSignalling(b)
+				case DescriptorTagAudioStream:
+					d.AudioStream = newDescriptorAudioStream(b)
+				case DescriptorTagAVCVideo:
+					d.AVCVideo = newDescriptorAVCVideo(b)
+				case DescriptorTagCA:
+					d.CA = newDescriptorCA(b)
+				case DescriptorTagCableDeliverySystem:
+					d.CableDeliverySystem = newDescriptorCableDeliverySystem(b)
+				case DescriptorTagComponent:
+					d.Component = newDescriptorComponent(b)
+				case DescriptorTagContent:
+					d.Content = newDescriptorContent(b)
+				case DescriptorTagDataStreamAlignment:
+					d.DataStreamAlignment = newDescriptorDataStreamAlignment(b)
+				case DescriptorTagEnhancedAC3:
+					d.EnhancedAC3 = newDescriptorEnhancedAC3(b)
+				case DescriptorTagExtendedEvent:
+					d.ExtendedEvent = newDescriptorExtendedEvent(b)
+				case DescriptorTagExtension:
+					d.Extension = newDescriptorExtension(b)
+				case DescriptorTagHEVCVideo:
+					d.HEVCVideo = newDescriptorHEVCVideo(b)
+				case DescriptorTagISO639LanguageAndAudioType:
+					d.ISO639LanguageAndAudioType = newDescriptorISO639LanguageAndAudioType(b)
+				case DescriptorTagLocalTimeOffset:
+					d.LocalTimeOffset = newDescriptorLocalTimeOffset(b)
+				case DescriptorTagMaximumBitrate:
+					d.MaximumBitrate = newDescriptorMaximumBitrate(b)
+				case DescriptorTagMetadata:
+					d.Metadata = newDescriptorMetadata(b)
+				case DescriptorTagMultilingualBouquetName:
+					d.MultilingualBouquetName = newDescriptorMultilingualName(b)
+				case DescriptorTagMultilingualComponent:
+					d.MultilingualComponent = newDescriptorMultilingualComponent(b)
+				case DescriptorTagMultilingualNetworkName:
+					d.MultilingualNetworkName = newDescriptorMultilingualName(b)
+				case DescriptorTagMultilingualServiceName:
+					d.MultilingualServiceName = newDescriptorMultilingualServiceName(b)
+				case DescriptorTagNetworkName:
+					d.NetworkName = newDescriptorNetworkName(b)
+				case DescriptorTagParentalRating:
+					d.ParentalRating = newDescriptorParentalRating(b)
+				case DescriptorTagPrivateDataIndicator:
+					d.PrivateDataIndicator = newDescriptorPrivateDataIndicator(b)
+				case DescriptorTagPrivateDataSpecifier:
+					d.PrivateDataSpecifier = newDescriptorPrivateDataSpecifier(b)
+				case DescriptorTagRegistration:
+					d.Registration = newDescriptorRegistration(b)
+				case DescriptorTagSatelliteDeliverySystem:
+					d.SatelliteDeliverySystem = newDescriptorSatelliteDeliverySystem(b)
+				case DescriptorTagService:
+					d.Service = newDescriptorService(b)
+				case DescriptorTagShortEvent:
+					d.ShortEvent = newDescriptorShortEvent(b)
+				case DescriptorTagStreamIdentifier:
+					d.StreamIdentifier = newDescriptorStreamIdentifier(b)
+				case DescriptorTagSubtitling:
+					d.Subtitling = newDescriptorSubtitling(b)
+				case DescriptorTagTeletext:
+					d.Teletext = newDescriptorTeletext(b)
+				case DescriptorTagTerrestrialDeliverySystem:
+					d.TerrestrialDeliverySystem = newDescriptorTerrestrialDeliverySystem(b)
+				case DescriptorTagVBIData:
+					d.VBIData = newDescriptorVBIData(b)
+				case DescriptorTagVBITeletext:
+					d.VBITeletext = newDescriptorTeletext(b)
+				case DescriptorTagVideoStream:
+					d.VideoStream = newDescriptorVideoStream(b)
+				default:
+					d.Unknown = newDescriptorUnknown(d.Tag, b)
 				}
-				*offset += int(d.Length)
 			}
-			o = append(o, d)
+			*offset += int(d.Length)
+
+			// Update private data specifier
+			if d.PrivateDataSpecifier != nil {
+				specifier = d.PrivateDataSpecifier.Specifier
+			}
+		}
+		d.Specifier = specifier
+		o = append(o, d)
+	}
+	return
+}
+
+// parseUserDefinedDescriptor interprets the content of a user defined descriptor under a private data specifier
+func parseUserDefinedDescriptor(d *Descriptor, specifier uint32) {
+	switch {
+	case d.Tag == DescriptorTagHDSimulcastLogicalChannel && (specifier == PrivateDataSpecifierEICTA || specifier == PrivateDataSpecifierUKDTG):
+		d.HDSimulcastLogicalChannel = newDescriptorLogicalChannel(d.UserDefined, specifier)
+	case d.Tag == DescriptorTagLogicalChannel && isLogicalChannelSpecifier(specifier):
+		d.LogicalChannel = newDescriptorLogicalChannel(d.UserDefined, specifier)
+	case d.Tag == DescriptorTagLogicalChannelV2 && specifier == PrivateDataSpecifierNorDig:
+		d.LogicalChannelV2 = newDescriptorLogicalChannelV2(d.UserDefined)
+	}
+}
+
+// IsPrivate checks whether the descriptor is the private descriptor of a tag defined by a private data specifier, so
+// that user defined descriptors can be interpreted per specifier rather than by tag only
+func (d *Descriptor) IsPrivate(specifier uint32, tag uint8) bool {
+	return d.Specifier == specifier && d.Tag == tag
+}
+
+// isLogicalChannelSpecifier checks whether logical channel descriptors are defined by a private data specifier
+func isLogicalChannelSpecifier(specifier uint32) bool {
+	return specifier == PrivateDataSpecifierEICTA ||
+		specifier == PrivateDataSpecifierNorDig ||
+		specifier == PrivateDataSpecifierUKDTG
+}
+
+// Serialize serializes the descriptor, including its tag and its length, which is computed based on its content
+// ErrDescriptorNotSerializable is returned when the descriptor has a length but no content
+func (d *Descriptor) Serialize() (b []byte, err error) {
+	return writeDescriptor(d)
+}
+
+// writeDescriptors serializes a descriptors loop, including its length
+func writeDescriptors(ds []*Descriptor) (b []byte, err error) {
+	// Descriptors
+	var c []byte
+	for _, d := range ds {
+		var bd []byte
+		if bd, err = writeDescriptor(d); err != nil {
+			err = errors.Wrapf(err, "astits: writing descriptor with tag 0x%x failed", d.Tag)
+			return
+		}
+		c = append(c, bd...)
+	}
+
+	// Length
+	b = append([]byte{0xf0 | uint8(len(c)>>8)&0xf, uint8(len(c))}, c...)
+	return
+}
+
+// writeDescriptor serializes a descriptor, including its tag and length
+// The length is computed based on the content, descriptors without content are only valid if their length is 0
+func writeDescriptor(d *Descriptor) (b []byte, err error) {
+	// Get content
+	var c []byte
+	if d.Tag >= 0x80 && d.Tag <= 0xfe {
+		switch {
+		case d.UserDefined != nil:
+			c = d.UserDefined
+		case d.HDSimulcastLogicalChannel != nil:
+			c = writeDescriptorLogicalChannel(d.HDSimulcastLogicalChannel)
+		case d.LogicalChannel != nil:
+			c = writeDescriptorLogicalChannel(d.LogicalChannel)
+		case d.LogicalChannelV2 != nil:
+			c = writeDescriptorLogicalChannelV2(d.LogicalChannelV2)
+		}
+	} else {
+		switch {
+		case d.AC3 != nil:
+			c = writeDescriptorAC3(d.AC3)
+		case d.ApplicationSignalling != nil:
+			c = writeDescriptorApplicationSignalling(d.ApplicationSignalling)
+		case d.AudioStream != nil:
+			c = writeDescriptorAudioStream(d.AudioStream)
+		case d.AVCVideo != nil:
+			c = writeDescriptorAVCVideo(d.AVCVideo)
+		case d.CA != nil:
+			c = writeDescriptorCA(d.CA)
+		case d.CableDeliverySystem != nil:
+			c = writeDescriptorCableDeliverySystem(d.CableDeliverySystem)
+		case d.Component != nil:
+			c = writeDescriptorComponent(d.Component)
+		case d.Content != nil:
+			c = writeDescriptorContent(d.Content)
+		case d.DataStreamAlignment != nil:
+			c = writeDescriptorDataStreamAlignment(d.DataStreamAlignment)
+		case d.EnhancedAC3 != nil:
+			c = writeDescriptorEnhancedAC3(d.EnhancedAC3)
+		case d.ExtendedEvent != nil:
+			c = writeDescriptorExtendedEvent(d.ExtendedEvent)
+		case d.Extension != nil:
+			if c, err = writeDescriptorExtension(d.Extension); err != nil {
+				err = errors.Wrapf(err, "astits: writing extension descriptor with tag 0x%x failed", d.Extension.Tag)
+				return
+			}
+		case d.HEVCVideo != nil:
+			c = writeDescriptorHEVCVideo(d.HEVCVideo)
+		case d.ISO639LanguageAndAudioType != nil:
+			c = writeDescriptorISO639LanguageAndAudioType(d.ISO639LanguageAndAudioType)
+		case d.LocalTimeOffset != nil:
+			c = writeDescriptorLocalTimeOffset(d.LocalTimeOffset)
+		case d.MaximumBitrate != nil:
+			c = writeDescriptorMaximumBitrate(d.MaximumBitrate)
+		case d.Metadata != nil:
+			c = writeDescriptorMetadata(d.Metadata)
+		case d.MultilingualBouquetName != nil:
+			c = writeDescriptorMultilingualName(d.MultilingualBouquetName)
+		case d.MultilingualComponent != nil:
+			c = writeDescriptorMultilingualComponent(d.MultilingualComponent)
+		case d.MultilingualNetworkName != nil:
+			c = writeDescriptorMultilingualName(d.MultilingualNetworkName)
+		case d.MultilingualServiceName != nil:
+			c = writeDescriptorMultilingualServiceName(d.MultilingualServiceName)
+		case d.NetworkName != nil:
+			c = writeDescriptorNetworkName(d.NetworkName)
+		case d.ParentalRating != nil:
+			c = writeDescriptorParentalRating(d.ParentalRating)
+		case d.PrivateDataIndicator != nil:
+			c = writeDescriptorPrivateDataIndicator(d.PrivateDataIndicator)
+		case d.PrivateDataSpecifier != nil:
+			c = writeDescriptorPrivateDataSpecifier(d.PrivateDataSpecifier)
+		case d.Registration != nil:
+			c = writeDescriptorRegistration(d.Registration)
+		case d.SatelliteDeliverySystem != nil:
+			c = writeDescriptorSatelliteDeliverySystem(d.SatelliteDeliverySystem)
+		case d.Service != nil:
+			c = writeDescriptorService(d.Service)
+		case d.ShortEvent != nil:
+			c = writeDescriptorShortEvent(d.ShortEvent)
+		case d.StreamIdentifier != nil:
+			c = writeDescriptorStreamIdentifier(d.StreamIdentifier)
+		case d.Subtitling != nil:
+			c = writeDescriptorSubtitling(d.Subtitling)
+		case d.Teletext != nil:
+			c = writeDescriptorTeletext(d.Teletext)
+		case d.TerrestrialDeliverySystem != nil:
+			c = writeDescriptorTerrestrialDeliverySystem(d.TerrestrialDeliverySystem)
+		case d.VBIData != nil:
+			c = writeDescriptorVBIData(d.VBIData)
+		case d.VBITeletext != nil:
+			c = writeDescriptorTeletext(d.VBITeletext)
+		case d.VideoStream != nil:
+			c = writeDescriptorVideoStream(d.VideoStream)
+		case d.Unknown != nil:
+			c = d.Unknown.Data
+		case d.Length > 0:
+			err = ErrDescriptorNotSerializable
+			return
 		}
 	}
+
+	// Check length
+	if len(c) > 0xff {
+		err = fmt.Errorf("astits: descriptor content length %d is too big", len(c))
+		return
+	}
+	b = append([]byte{d.Tag, uint8(len(c))}, c...)
 	return
 }
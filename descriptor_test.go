@@ -369,4 +369,537 @@ func TestParseDescriptor(t *testing.T) {
 		AdditionalIdentificationInfo: []byte("test"),
 		FormatIdentifier:             uint32(1),
 	})
+
+	// All descriptors can be serialized back
+	b, err := writeDescriptors(ds)
+	assert.NoError(t, err)
+	assert.Len(t, b, len(w.Bytes()))
+	offset = 0
+	assert.Equal(t, ds, parseDescriptors(b, &offset))
+}
+
+func TestWriteDescriptors(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	w.Write("1111")         // Reserved
+	w.Write("000000101101") // Descriptors length
+	// Data stream alignment
+	w.Write(uint8(DescriptorTagDataStreamAlignment)) // Tag
+	w.Write(uint8(1))                                // Length
+	w.Write(uint8(2))                                // Type
+	// ISO639 language and audio type
+	w.Write(uint8(DescriptorTagISO639LanguageAndAudioType)) // Tag
+	w.Write(uint8(4))                                       // Length
+	w.Write([]byte("eng"))                                  // Language
+	w.Write(uint8(AudioTypeCleanEffects))                   // Audio type
+	// Maximum bitrate
+	w.Write(uint8(DescriptorTagMaximumBitrate)) // Tag
+	w.Write(uint8(3))                           // Length
+	w.Write("11")                               // Reserved
+	w.Write("0000000000000000000001")           // Maximum bitrate
+	// Private data indicator
+	w.Write(uint8(DescriptorTagPrivateDataIndicator)) // Tag
+	w.Write(uint8(4))                                 // Length
+	w.Write(uint32(127))                              // Private data indicator
+	// Private data specifier
+	w.Write(uint8(DescriptorTagPrivateDataSpecifier)) // Tag
+	w.Write(uint8(4))                                 // Length
+	w.Write(uint32(128))                              // Private data specifier
+	// Registration
+	w.Write(uint8(DescriptorTagRegistration)) // Tag
+	w.Write(uint8(8))                         // Length
+	w.Write(uint32(1))                        // Format identifier
+	w.Write([]byte("test"))                   // Additional identification info
+	// Stream identifier
+	w.Write(uint8(DescriptorTagStreamIdentifier)) // Tag
+	w.Write(uint8(1))                             // Length
+	w.Write(uint8(7))                             // Component tag
+	// User defined
+	w.Write(uint8(0x80))    // Tag
+	w.Write(uint8(4))       // Length
+	w.Write([]byte("test")) // User defined
+
+	// Assert
+	var offset int
+	b, err := writeDescriptors(parseDescriptors(w.Bytes(), &offset))
+	assert.NoError(t, err)
+	assert.Equal(t, w.Bytes(), b)
+
+	// Descriptor that can't be serialized
+	_, err = writeDescriptors([]*Descriptor{{Length: 1, Tag: 0x60}})
+	assert.EqualError(t, err, "astits: writing descriptor with tag 0x60 failed: "+ErrDescriptorNotSerializable.Error())
+	_, err = writeDescriptors([]*Descriptor{{Extension: &DescriptorExtension{Tag: 0x7}, Length: 1, Tag: DescriptorTagExtension}})
+	assert.Error(t, err)
+
+	// Serialize
+	b, err = (&Descriptor{StreamIdentifier: &DescriptorStreamIdentifier{ComponentTag: 0x7}, Tag: DescriptorTagStreamIdentifier}).Serialize()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{DescriptorTagStreamIdentifier, 0x1, 0x7}, b)
+}
+
+func TestDescriptorUnknown(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	w.Write("1111")         // Reserved
+	w.Write("000000001101") // Descriptors length
+	// Unknown
+	w.Write(uint8(0x60))    // Tag
+	w.Write(uint8(4))       // Length
+	w.Write([]byte("test")) // Data
+	// Unknown extension
+	w.Write(uint8(DescriptorTagExtension)) // Tag
+	w.Write(uint8(5))                      // Length
+	w.Write(uint8(0x10))                   // Extension tag
+	w.Write([]byte("test"))                // Data
+
+	// Parse
+	var offset int
+	ds := parseDescriptors(w.Bytes(), &offset)
+	assert.Equal(t, &DescriptorUnknown{Data: []byte("test"), Tag: 0x60}, ds[0].Unknown)
+	assert.Equal(t, &DescriptorUnknown{Data: []byte("test"), Tag: 0x10}, ds[1].Extension.Unknown)
+
+	// Write
+	b, err := writeDescriptors(ds)
+	assert.NoError(t, err)
+	assert.Equal(t, w.Bytes(), b)
+}
+
+func TestWriteDescriptorEvents(t *testing.T) {
+	var ds = []*Descriptor{
+		NewDescriptorShortEvent("eng", "name", "text"),
+		{
+			ExtendedEvent: &DescriptorExtendedEvent{
+				ISO639LanguageCode:   []byte("eng"),
+				Items:                []*DescriptorExtendedEventItem{{Content: []byte("content"), Description: []byte("description")}},
+				LastDescriptorNumber: 1,
+				Text:                 []byte("text"),
+			},
+			Length: 30,
+			Tag:    DescriptorTagExtendedEvent,
+		},
+	}
+	b, err := writeDescriptors(ds)
+	assert.NoError(t, err)
+	var offset int
+	assert.Equal(t, ds, parseDescriptors(b, &offset))
+}
+
+func TestDescriptorApplicationSignalling(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	w.Write("1111")                                    // Reserved
+	w.Write("000000001000")                            // Descriptors length
+	w.Write(uint8(DescriptorTagApplicationSignalling)) // Tag
+	w.Write(uint8(6))                                  // Length
+	w.Write("1")                                       // Reserved
+	w.Write("000000000010000")                         // Application type
+	w.Write("111")                                     // Reserved
+	w.Write("00011")                                   // AIT version number
+	w.Write("1")                                       // Reserved
+	w.Write("000000000000001")                         // Application type
+	w.Write("111")                                     // Reserved
+	w.Write("00100")                                   // AIT version number
+
+	// Parse
+	var offset int
+	ds := parseDescriptors(w.Bytes(), &offset)
+	assert.Equal(t, &DescriptorApplicationSignalling{Items: []*DescriptorApplicationSignallingItem{
+		{AITVersionNumber: 3, ApplicationType: AITApplicationTypeHbbTV},
+		{AITVersionNumber: 4, ApplicationType: AITApplicationTypeDVBJ},
+	}}, ds[0].ApplicationSignalling)
+
+	// Write
+	b, err := writeDescriptors(ds)
+	assert.NoError(t, err)
+	assert.Equal(t, w.Bytes(), b)
+}
+
+func TestDescriptorMetadata(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	w.Write("1111")                       // Reserved
+	w.Write("000000010111")               // Descriptors length
+	w.Write(uint8(DescriptorTagMetadata)) // Tag
+	w.Write(uint8(21))                    // Length
+	w.Write(uint16(0xffff))               // Metadata application format
+	w.Write([]byte("ID3 "))               // Metadata application format identifier
+	w.Write(uint8(0xff))                  // Metadata format
+	w.Write([]byte("ID3 "))               // Metadata format identifier
+	w.Write(uint8(2))                     // Metadata service ID
+	w.Write("001")                        // Decoder config flags
+	w.Write("1")                          // DSM-CC flag
+	w.Write("1111")                       // Reserved
+	w.Write(uint8(2))                     // Service identification length
+	w.Write([]byte("si"))                 // Service identification
+	w.Write(uint8(3))                     // Decoder config length
+	w.Write([]byte("cfg"))                // Decoder config
+	w.Write([]byte{0x1})                  // Private data
+
+	// Parse
+	var offset int
+	ds := parseDescriptors(w.Bytes(), &offset)
+	assert.Equal(t, &DescriptorMetadata{
+		ApplicationFormat:           MetadataApplicationFormatIdentifierField,
+		ApplicationFormatIdentifier: MetadataFormatIdentifierID3,
+		DecoderConfig:               []byte("cfg"),
+		DecoderConfigFlags:          metadataDecoderConfigFlagsConfig,
+		Format:                      MetadataFormatIdentifierField,
+		FormatIdentifier:            MetadataFormatIdentifierID3,
+		HasDSMCC:                    true,
+		PrivateData:                 []byte{0x1},
+		ServiceID:                   2,
+		ServiceIdentification:       []byte("si"),
+	}, ds[0].Metadata)
+
+	// Write
+	b, err := writeDescriptors(ds)
+	assert.NoError(t, err)
+	assert.Equal(t, w.Bytes(), b)
+}
+
+func TestDescriptorExtensionT2MI(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	w.Write("1111")                            // Reserved
+	w.Write("000000000110")                    // Descriptors length
+	w.Write(uint8(DescriptorTagExtension))     // Tag
+	w.Write(uint8(4))                          // Length
+	w.Write(uint8(DescriptorTagExtensionT2MI)) // Extension tag
+	w.Write("11111")                           // Reserved
+	w.Write("010")                             // T2-MI stream ID
+	w.Write("11111")                           // Reserved
+	w.Write("011")                             // Number of T2-MI streams minus one
+	w.Write("1111111")                         // Reserved
+	w.Write("1")                               // PCR ISCR common clock flag
+
+	// Parse
+	var offset int
+	ds := parseDescriptors(w.Bytes(), &offset)
+	assert.Equal(t, &DescriptorExtensionT2MI{NumStreamsMinusOne: 3, PCRISCRCommonClockFlag: true, StreamID: 2}, ds[0].Extension.T2MI)
+
+	// Write
+	b, err := writeDescriptors(ds)
+	assert.NoError(t, err)
+	assert.Equal(t, w.Bytes(), b)
+}
+
+func TestDescriptorDeliverySystems(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	w.Write("1111")                                        // Reserved
+	w.Write("000000100111")                                // Descriptors length
+	w.Write(uint8(DescriptorTagSatelliteDeliverySystem))   // Tag
+	w.Write(uint8(11))                                     // Length
+	w.Write([]byte{0x01, 0x17, 0x57, 0x25})                // Frequency
+	w.Write([]byte{0x01, 0x92})                            // Orbital position
+	w.Write("1")                                           // West east flag
+	w.Write("01")                                          // Polarization
+	w.Write("10")                                          // Roll off
+	w.Write("1")                                           // Modulation system
+	w.Write("10")                                          // Modulation type
+	w.Write([]byte{0x02, 0x75, 0x00})                      // Symbol rate
+	w.Write("0000")                                        // Symbol rate
+	w.Write("0011")                                        // FEC inner
+	w.Write(uint8(DescriptorTagCableDeliverySystem))       // Tag
+	w.Write(uint8(11))                                     // Length
+	w.Write([]byte{0x03, 0x46, 0x00, 0x00})                // Frequency
+	w.Write("111111111111")                                // Reserved
+	w.Write("0010")                                        // FEC outer
+	w.Write(uint8(CableModulation256QAM))                  // Modulation
+	w.Write([]byte{0x00, 0x69, 0x00})                      // Symbol rate
+	w.Write("0000")                                        // Symbol rate
+	w.Write("1111")                                        // FEC inner
+	w.Write(uint8(DescriptorTagTerrestrialDeliverySystem)) // Tag
+	w.Write(uint8(11))                                     // Length
+	w.Write(uint32(47400000))                              // Centre frequency
+	w.Write("000")                                         // Bandwidth
+	w.Write("1")                                           // Priority
+	w.Write("1")                                           // Time slicing indicator
+	w.Write("0")                                           // MPE-FEC indicator
+	w.Write("11")                                          // Reserved
+	w.Write("10")                                          // Constellation
+	w.Write("000")                                         // Hierarchy information
+	w.Write("010")                                         // Code rate HP stream
+	w.Write("000")                                         // Code rate LP stream
+	w.Write("00")                                          // Guard interval
+	w.Write("01")                                          // Transmission mode
+	w.Write("1")                                           // Other frequency flag
+	w.Write(uint32(0xffffffff))                            // Reserved
+
+	// Parse
+	var offset int
+	ds := parseDescriptors(w.Bytes(), &offset)
+	assert.Equal(t, &DescriptorSatelliteDeliverySystem{
+		FECInner:         FECInner3Over4,
+		Frequency:        11757250000,
+		ModulationSystem: SatelliteModulationSystemDVBS2,
+		ModulationType:   SatelliteModulationType8PSK,
+		OrbitalPosition:  192,
+		Polarization:     PolarizationLinearVertical,
+		RollOff:          RollOff020,
+		SymbolRate:       27500000,
+		WestEastFlag:     true,
+	}, ds[0].SatelliteDeliverySystem)
+	assert.Equal(t, &DescriptorCableDeliverySystem{
+		FECInner:   FECInnerNoConvolutionalCoding,
+		FECOuter:   FECOuterRS,
+		Frequency:  346000000,
+		Modulation: CableModulation256QAM,
+		SymbolRate: 6900000,
+	}, ds[1].CableDeliverySystem)
+	assert.Equal(t, &DescriptorTerrestrialDeliverySystem{
+		Bandwidth:            TerrestrialBandwidth8MHz,
+		CodeRateHPStream:     TerrestrialCodeRate3Over4,
+		CodeRateLPStream:     TerrestrialCodeRate1Over2,
+		Constellation:        TerrestrialConstellation64QAM,
+		Frequency:            474000000,
+		GuardInterval:        TerrestrialGuardInterval1Over32,
+		HierarchyInformation: 0,
+		HighPriority:         true,
+		OtherFrequencyFlag:   true,
+		TimeSlicingIndicator: true,
+		TransmissionMode:     TerrestrialTransmissionMode8K,
+	}, ds[2].TerrestrialDeliverySystem)
+
+	// Write
+	b, err := writeDescriptors(ds)
+	assert.NoError(t, err)
+	assert.Equal(t, w.Bytes(), b)
+}
+
+func TestDescriptorSpecifier(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	w.Write("1111")                                        // Reserved
+	w.Write("000000011010")                                // Descriptors length
+	w.Write(uint8(DescriptorTagHDSimulcastLogicalChannel)) // Tag
+	w.Write(uint8(0))                                      // Length
+	w.Write(uint8(DescriptorTagPrivateDataSpecifier))      // Tag
+	w.Write(uint8(4))                                      // Length
+	w.Write(uint32(PrivateDataSpecifierUKDTG))             // Private data specifier
+	w.Write(uint8(DescriptorTagHDSimulcastLogicalChannel)) // Tag
+	w.Write(uint8(4))                                      // Length
+	w.Write(uint16(0x101))                                 // Service ID
+	w.Write("1")                                           // Visible service flag
+	w.Write("11111")                                       // Reserved
+	w.Write("0001100101")                                  // Logical channel number
+	w.Write(uint8(DescriptorTagPrivateDataSpecifier))      // Tag
+	w.Write(uint8(4))                                      // Length
+	w.Write(uint32(1))                                     // Private data specifier
+	w.Write(uint8(DescriptorTagHDSimulcastLogicalChannel)) // Tag
+	w.Write(uint8(4))                                      // Length
+	w.Write([]byte("test"))                                // User defined
+
+	// Parse
+	var offset int
+	ds := parseDescriptors(w.Bytes(), &offset)
+	assert.Equal(t, []uint32{0, PrivateDataSpecifierUKDTG, PrivateDataSpecifierUKDTG, 1, 1}, []uint32{ds[0].Specifier, ds[1].Specifier, ds[2].Specifier, ds[3].Specifier, ds[4].Specifier})
+	assert.False(t, ds[0].IsPrivate(PrivateDataSpecifierUKDTG, DescriptorTagHDSimulcastLogicalChannel))
+	assert.True(t, ds[2].IsPrivate(PrivateDataSpecifierUKDTG, DescriptorTagHDSimulcastLogicalChannel))
+	assert.Equal(t, &DescriptorLogicalChannel{Items: []*DescriptorLogicalChannelItem{{LogicalChannelNumber: 101, ServiceID: 0x101, VisibleServiceFlag: true}}, Specifier: PrivateDataSpecifierUKDTG}, ds[2].HDSimulcastLogicalChannel)
+	assert.Nil(t, ds[4].HDSimulcastLogicalChannel)
+	assert.Equal(t, []byte("test"), ds[4].UserDefined)
+
+	// Write
+	b, err := writeDescriptors(ds)
+	assert.NoError(t, err)
+	assert.Equal(t, w.Bytes(), b)
+}
+
+func TestDescriptorParentalRating(t *testing.T) {
+	// Parse
+	ds := parseDescriptorsLoop([]byte{DescriptorTagParentalRating, 0xd, 'F', 'R', 'A', 0x7, 'G', 'B', 'R', 0x10, 'D', 'E', 'U', 0x0, 'E'}, new(int), 15)
+	d := ds[0].ParentalRating
+	assert.Len(t, d.Items, 3)
+	age, ok := d.MinimumAge("FRA")
+	assert.True(t, ok)
+	assert.Equal(t, 10, age)
+	assert.True(t, d.Items[1].IsUserDefined())
+	age, ok = d.MinimumAge("GBR")
+	assert.True(t, ok)
+	assert.Equal(t, 0, age)
+	assert.False(t, d.Items[2].IsUserDefined())
+	assert.Equal(t, 0, d.Items[2].MinimumAge())
+	_, ok = d.MinimumAge("ESP")
+	assert.False(t, ok)
+}
+
+func TestDescriptorMultilingual(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	w.Write("1111")                                      // Reserved
+	w.Write("000000110101")                              // Descriptors length
+	w.Write(uint8(DescriptorTagMultilingualBouquetName)) // Tag
+	w.Write(uint8(10))                                   // Length
+	w.Write([]byte("eng"))                               // ISO 639 language code
+	w.Write(uint8(6))                                    // Name length
+	w.Write([]byte("bouque"))                            // Name
+	w.Write(uint8(DescriptorTagMultilingualComponent))   // Tag
+	w.Write(uint8(15))                                   // Length
+	w.Write(uint8(7))                                    // Component tag
+	w.Write([]byte("eng"))                               // ISO 639 language code
+	w.Write(uint8(2))                                    // Text length
+	w.Write([]byte("hd"))                                // Text
+	w.Write([]byte("fre"))                               // ISO 639 language code
+	w.Write(uint8(4))                                    // Text length
+	w.Write([]byte("haut"))                              // Text
+	w.Write(uint8(DescriptorTagMultilingualNetworkName)) // Tag
+	w.Write(uint8(7))                                    // Length
+	w.Write([]byte("fre"))                               // ISO 639 language code
+	w.Write(uint8(3))                                    // Name length
+	w.Write([]byte("net"))                               // Name
+	w.Write(uint8(DescriptorTagMultilingualServiceName)) // Tag
+	w.Write(uint8(13))                                   // Length
+	w.Write([]byte("ger"))                               // ISO 639 language code
+	w.Write(uint8(4))                                    // Provider name length
+	w.Write([]byte("prov"))                              // Provider name
+	w.Write(uint8(4))                                    // Service name length
+	w.Write([]byte("serv"))                              // Service name
+
+	// Parse
+	var offset int
+	ds := parseDescriptors(w.Bytes(), &offset)
+	assert.Equal(t, &DescriptorMultilingualName{Items: []*DescriptorMultilingualNameItem{{ISO639LanguageCode: []byte("eng"), Name: []byte("bouque")}}}, ds[0].MultilingualBouquetName)
+	assert.Equal(t, uint8(7), ds[1].MultilingualComponent.ComponentTag)
+	assert.Equal(t, []byte("haut"), ds[1].MultilingualComponent.Item("fre").Text)
+	assert.Nil(t, ds[1].MultilingualComponent.Item("ger"))
+	assert.Equal(t, []byte("net"), ds[2].MultilingualNetworkName.Item("fre").Name)
+	assert.Equal(t, &DescriptorMultilingualServiceNameItem{ISO639LanguageCode: []byte("ger"), Name: []byte("serv"), Provider: []byte("prov")}, ds[3].MultilingualServiceName.Item("ger"))
+
+	// Write
+	b, err := writeDescriptors(ds)
+	assert.NoError(t, err)
+	assert.Equal(t, w.Bytes(), b)
+
+	// Decode texts
+	assert.NoError(t, decodeDescriptorTexts(ds[3], NewDVBTextDecoder()))
+	assert.Equal(t, "prov", ds[3].MultilingualServiceName.Items[0].DecodedProvider)
+	assert.Equal(t, "serv", ds[3].MultilingualServiceName.Items[0].DecodedName)
+}
+
+func TestDescriptorElementaryStreams(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	w.Write("1111")                                   // Reserved
+	w.Write("000000001110")                           // Descriptors length
+	w.Write(uint8(DescriptorTagVideoStream))          // Tag
+	w.Write(uint8(3))                                 // Length
+	w.Write("1")                                      // Multiple frame rate flag
+	w.Write("0011")                                   // Frame rate code
+	w.Write("0")                                      // MPEG-1 only flag
+	w.Write("0")                                      // Constrained parameter flag
+	w.Write("1")                                      // Still picture flag
+	w.Write(uint8(0x48))                              // Profile and level indication
+	w.Write("01")                                     // Chroma format
+	w.Write("1")                                      // Frame rate extension flag
+	w.Write("11111")                                  // Reserved
+	w.Write(uint8(DescriptorTagVideoStream))          // Tag
+	w.Write(uint8(1))                                 // Length
+	w.Write("0")                                      // Multiple frame rate flag
+	w.Write("1111")                                   // Frame rate code
+	w.Write("1")                                      // MPEG-1 only flag
+	w.Write("1")                                      // Constrained parameter flag
+	w.Write("0")                                      // Still picture flag
+	w.Write(uint8(DescriptorTagAudioStream))          // Tag
+	w.Write(uint8(1))                                 // Length
+	w.Write("1")                                      // Free format flag
+	w.Write("1")                                      // ID
+	w.Write("10")                                     // Layer
+	w.Write("1")                                      // Variable rate audio indicator
+	w.Write("111")                                    // Reserved
+	w.Write(uint8(DescriptorTagDataStreamAlignment))  // Tag
+	w.Write(uint8(1))                                 // Length
+	w.Write(uint8(DataStreamAligmentVideoAccessUnit)) // Type
+
+	// Parse
+	var offset int
+	ds := parseDescriptors(w.Bytes(), &offset)
+	assert.Equal(t, &DescriptorVideoStream{
+		ChromaFormat:              1,
+		FrameRateCode:             3,
+		FrameRateExtensionFlag:    true,
+		MultipleFrameRateFlag:     true,
+		ProfileAndLevelIndication: 0x48,
+		StillPictureFlag:          true,
+	}, ds[0].VideoStream)
+	assert.Equal(t, float64(25), ds[0].VideoStream.FrameRate())
+	assert.Equal(t, &DescriptorVideoStream{ConstrainedParameterFlag: true, FrameRateCode: 0xf, MPEG1OnlyFlag: true}, ds[1].VideoStream)
+	assert.Equal(t, float64(0), ds[1].VideoStream.FrameRate())
+	assert.Equal(t, &DescriptorAudioStream{FreeFormatFlag: true, ID: 1, Layer: 2, VariableRateAudioIndicator: true}, ds[2].AudioStream)
+	assert.Equal(t, uint8(DataStreamAligmentVideoAccessUnit), ds[3].DataStreamAlignment.Type)
+
+	// Write
+	b, err := writeDescriptors(ds)
+	assert.NoError(t, err)
+	assert.Equal(t, w.Bytes(), b)
+}
+
+func TestDescriptorVideoCodecs(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	w.Write("1111")                        // Reserved
+	w.Write("000000010111")                // Descriptors length
+	w.Write(uint8(DescriptorTagAVCVideo))  // Tag
+	w.Write(uint8(4))                      // Length
+	w.Write(uint8(100))                    // Profile idc
+	w.Write("000")                         // Constraint set flags
+	w.Write("00000")                       // Compatible flags
+	w.Write(uint8(41))                     // Level idc
+	w.Write("0")                           // AVC still present
+	w.Write("0")                           // AVC 24 hour picture flag
+	w.Write("1")                           // Frame packing SEI not present flag
+	w.Write("11111")                       // Reserved
+	w.Write(uint8(DescriptorTagHEVCVideo)) // Tag
+	w.Write(uint8(15))                     // Length
+	w.Write("00")                          // Profile space
+	w.Write("1")                           // Tier flag
+	w.Write("00010")                       // Profile idc
+	w.Write(uint32(0x60000000))            // Profile compatibility indication
+	w.Write("1")                           // Progressive source flag
+	w.Write("0")                           // Interlaced source flag
+	w.Write("0")                           // Non packed constraint flag
+	w.Write("1")                           // Frame only constraint flag
+	w.Write("0000")                        // Copied 44 bits
+	w.Write(uint8(0))                      // Copied 44 bits
+	w.Write(uint32(5))                     // Copied 44 bits
+	w.Write(uint8(153))                    // Level idc
+	w.Write("1")                           // Temporal layer subset flag
+	w.Write("1")                           // HEVC still present flag
+	w.Write("0")                           // HEVC 24 hour picture present flag
+	w.Write("1")                           // Sub pic HRD params not present flag
+	w.Write("11")                          // Reserved
+	w.Write("10")                          // HDR WCG idc
+	w.Write("001")                         // Temporal ID min
+	w.Write("11111")                       // Reserved
+	w.Write("011")                         // Temporal ID max
+	w.Write("11111")                       // Reserved
+
+	// Parse
+	var offset int
+	ds := parseDescriptors(w.Bytes(), &offset)
+	assert.Equal(t, &DescriptorAVCVideo{FramePackingSEINotPresentFlag: true, LevelIDC: 41, ProfileIDC: 100}, ds[0].AVCVideo)
+	assert.Equal(t, "High", ds[0].AVCVideo.ProfileName())
+	assert.InDelta(t, 4.1, ds[0].AVCVideo.Level(), 0.001)
+	assert.Equal(t, &DescriptorHEVCVideo{
+		Copied44Bits:                   5,
+		FrameOnlyConstraintFlag:        true,
+		HDRWCGIDC:                      2,
+		HEVCStillPresentFlag:           true,
+		LevelIDC:                       153,
+		ProfileCompatibilityIndication: 0x60000000,
+		ProfileIDC:                     2,
+		ProgressiveSourceFlag:          true,
+		SubPicHRDParamsNotPresentFlag:  true,
+		TemporalIDMax:                  3,
+		TemporalIDMin:                  1,
+		TemporalLayerSubsetFlag:        true,
+		TierFlag:                       true,
+	}, ds[1].HEVCVideo)
+	assert.Equal(t, "Main 10", ds[1].HEVCVideo.ProfileName())
+	assert.InDelta(t, 5.1, ds[1].HEVCVideo.Level(), 0.001)
+
+	// Write
+	b, err := writeDescriptors(ds)
+	assert.NoError(t, err)
+	assert.Equal(t, w.Bytes(), b)
 }
@@ -0,0 +1,491 @@
+package astits
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+// BIOP object kinds
+// Page: 40 | Chapter: 4.7.4 | Link: https://www.etsi.org/deliver/etsi_tr/101200_101299/101202/01.02.01_60/tr_101202v010201p.pdf
+const (
+	BIOPObjectKindDirectory      = "dir"
+	BIOPObjectKindFile           = "fil"
+	BIOPObjectKindServiceGateway = "srg"
+	BIOPObjectKindStream         = "str"
+	BIOPObjectKindStreamEvent    = "ste"
+)
+
+// BIOP constants
+const (
+	biopMagic                    = 0x42494f50 // "BIOP"
+	biopProfileTagBIOP           = 0x49534f06
+	biopProfileTagObjectLocation = 0x49534f50
+)
+
+// DSMCCIOR represents an IOP::IOR, i.e. a reference to an object of an object carousel
+// Only the object location of the BIOP profile, which tells which module carries the object, is parsed
+// Page: 50 | Chapter: 4.7.3.3 | Link: https://www.etsi.org/deliver/etsi_tr/101200_101299/101202/01.02.01_60/tr_101202v010201p.pdf
+type DSMCCIOR struct {
+	CarouselID uint32
+	ModuleID   uint16
+	ObjectKey  []byte
+	TypeID     string
+}
+
+// parseDSMCCIOR parses an IOP::IOR
+func parseDSMCCIOR(r *bitReader) (ior *DSMCCIOR, err error) {
+	// Type ID
+	var b []byte
+	if b, err = readDSMCCLengthPrefixedBytes(r, 32); err != nil {
+		err = errors.Wrap(err, "astits: reading type ID failed")
+		return
+	}
+	ior = &DSMCCIOR{TypeID: string(bytes.TrimRight(b, "\x00"))}
+
+	// Alignment gap
+	if len(b)%4 > 0 {
+		if _, err = r.readBytes(4 - len(b)%4); err != nil {
+			err = errors.Wrap(err, "astits: reading alignment gap failed")
+			return
+		}
+	}
+
+	// Tagged profiles count
+	var count uint32
+	if count, err = r.read(32); err != nil {
+		err = errors.Wrap(err, "astits: reading tagged profiles count failed")
+		return
+	}
+
+	// Loop through tagged profiles
+	var hasLocation bool
+	for idx := 0; idx < int(count); idx++ {
+		// Tag and data
+		var tag uint32
+		if tag, err = r.read(32); err != nil {
+			err = errors.Wrapf(err, "astits: reading tagged profile #%d tag failed", idx+1)
+			return
+		}
+		if b, err = readDSMCCLengthPrefixedBytes(r, 32); err != nil {
+			err = errors.Wrapf(err, "astits: reading tagged profile #%d data failed", idx+1)
+			return
+		}
+
+		// Only BIOP profiles are parsed
+		if tag != biopProfileTagBIOP {
+			continue
+		}
+		var ok bool
+		if ok, err = parseBIOPProfileBody(b, ior); err != nil {
+			err = errors.Wrapf(err, "astits: parsing tagged profile #%d failed", idx+1)
+			return
+		}
+		hasLocation = hasLocation || ok
+	}
+
+	// Check object location
+	if !hasLocation {
+		err = errors.New("astits: IOR has no object location")
+		return
+	}
+	return
+}
+
+// parseBIOPProfileBody parses the lite components of a BIOP profile body and returns whether an object location was
+// found
+func parseBIOPProfileBody(i []byte, ior *DSMCCIOR) (ok bool, err error) {
+	// Byte order and component count
+	var r = newBitReader(i)
+	var b []byte
+	if b, err = r.readBytes(2); err != nil {
+		err = errors.Wrap(err, "astits: reading profile header failed")
+		return
+	}
+
+	// Loop through components
+	for idx := 0; idx < int(b[1]); idx++ {
+		// Tag and data
+		var tag uint32
+		if tag, err = r.read(32); err != nil {
+			err = errors.Wrapf(err, "astits: reading component #%d tag failed", idx+1)
+			return
+		}
+		var c []byte
+		if c, err = readDSMCCLengthPrefixedBytes(r, 8); err != nil {
+			err = errors.Wrapf(err, "astits: reading component #%d data failed", idx+1)
+			return
+		}
+
+		// Only object locations are parsed
+		if tag != biopProfileTagObjectLocation {
+			continue
+		}
+		var end = 9
+		if len(c) >= end {
+			end += int(c[8])
+		}
+		if end > len(c) {
+			err = fmt.Errorf("astits: object location end (%d) > len(i) (%d)", end, len(c))
+			return
+		}
+		ior.CarouselID = uint32(c[0])<<24 | uint32(c[1])<<16 | uint32(c[2])<<8 | uint32(c[3])
+		ior.ModuleID = uint16(c[4])<<8 | uint16(c[5])
+		ior.ObjectKey = c[9 : 9+int(c[8])]
+		ok = true
+	}
+	return
+}
+
+// biopObject represents a BIOP message, i.e. an object of an object carousel
+// Page: 40 | Chapter: 4.7.4 | Link: https://www.etsi.org/deliver/etsi_tr/101200_101299/101202/01.02.01_60/tr_101202v010201p.pdf
+type biopObject struct {
+	bindings []*biopBinding // Set for directories and service gateways
+	content  []byte         // Set for files
+	key      []byte
+	kind     string
+}
+
+// biopBinding represents a binding of a BIOP directory
+type biopBinding struct {
+	ior  *DSMCCIOR
+	name string
+}
+
+// parseBIOPObjects parses the BIOP messages of a module
+func parseBIOPObjects(i []byte) (os []*biopObject, err error) {
+	var r = newBitReader(i)
+	for r.byteOffset() < len(i) {
+		// Header
+		var b []byte
+		if b, err = r.readBytes(12); err != nil {
+			err = errors.Wrap(err, "astits: reading BIOP message header failed")
+			return
+		} else if uint32(b[0])<<24|uint32(b[1])<<16|uint32(b[2])<<8|uint32(b[3]) != biopMagic {
+			err = fmt.Errorf("astits: invalid BIOP magic 0x%x", b[:4])
+			return
+		}
+
+		// Message
+		if b, err = r.readBytes(int(uint32(b[8])<<24 | uint32(b[9])<<16 | uint32(b[10])<<8 | uint32(b[11]))); err != nil {
+			err = errors.Wrap(err, "astits: reading BIOP message failed")
+			return
+		}
+		var o *biopObject
+		if o, err = parseBIOPObject(b); err != nil {
+			err = errors.Wrap(err, "astits: parsing BIOP message failed")
+			return
+		}
+		os = append(os, o)
+	}
+	return
+}
+
+// parseBIOPObject parses a BIOP message, its header excluded
+func parseBIOPObject(i []byte) (o *biopObject, err error) {
+	// Object key and kind
+	var r = newBitReader(i)
+	o = &biopObject{}
+	if o.key, err = readDSMCCLengthPrefixedBytes(r, 8); err != nil {
+		err = errors.Wrap(err, "astits: reading object key failed")
+		return
+	}
+	var b []byte
+	if b, err = readDSMCCLengthPrefixedBytes(r, 32); err != nil {
+		err = errors.Wrap(err, "astits: reading object kind failed")
+		return
+	}
+	o.kind = string(bytes.TrimRight(b, "\x00"))
+
+	// Object info
+	if _, err = readDSMCCLengthPrefixedBytes(r, 16); err != nil {
+		err = errors.Wrap(err, "astits: reading object info failed")
+		return
+	}
+
+	// Service context list
+	var count uint32
+	if count, err = r.read(8); err != nil {
+		err = errors.Wrap(err, "astits: reading service context list count failed")
+		return
+	}
+	for idx := 0; idx < int(count); idx++ {
+		if _, err = r.readBytes(4); err != nil {
+			err = errors.Wrapf(err, "astits: reading service context #%d ID failed", idx+1)
+			return
+		}
+		if _, err = readDSMCCLengthPrefixedBytes(r, 16); err != nil {
+			err = errors.Wrapf(err, "astits: reading service context #%d data failed", idx+1)
+			return
+		}
+	}
+
+	// Message body
+	if b, err = readDSMCCLengthPrefixedBytes(r, 32); err != nil {
+		err = errors.Wrap(err, "astits: reading message body failed")
+		return
+	}
+
+	// Switch on kind
+	switch o.kind {
+	case BIOPObjectKindDirectory, BIOPObjectKindServiceGateway:
+		if o.bindings, err = parseBIOPBindings(b); err != nil {
+			err = errors.Wrap(err, "astits: parsing bindings failed")
+			return
+		}
+	case BIOPObjectKindFile:
+		if o.content, err = readDSMCCLengthPrefixedBytes(newBitReader(b), 32); err != nil {
+			err = errors.Wrap(err, "astits: reading file content failed")
+			return
+		}
+	}
+	return
+}
+
+// parseBIOPBindings parses the bindings of a BIOP directory message body
+func parseBIOPBindings(i []byte) (bs []*biopBinding, err error) {
+	// Bindings count
+	var r = newBitReader(i)
+	var count uint32
+	if count, err = r.read(16); err != nil {
+		err = errors.Wrap(err, "astits: reading bindings count failed")
+		return
+	}
+
+	// Loop through bindings
+	for idx := 0; idx < int(count); idx++ {
+		// Name components
+		var n uint32
+		if n, err = r.read(8); err != nil {
+			err = errors.Wrapf(err, "astits: reading binding #%d name components count failed", idx+1)
+			return
+		}
+		var b = &biopBinding{}
+		for idxName := 0; idxName < int(n); idxName++ {
+			var id []byte
+			if id, err = readDSMCCLengthPrefixedBytes(r, 8); err != nil {
+				err = errors.Wrapf(err, "astits: reading binding #%d name component id failed", idx+1)
+				return
+			}
+			if _, err = readDSMCCLengthPrefixedBytes(r, 8); err != nil {
+				err = errors.Wrapf(err, "astits: reading binding #%d name component kind failed", idx+1)
+				return
+			}
+			b.name += string(bytes.TrimRight(id, "\x00"))
+		}
+
+		// Binding type
+		if _, err = r.read(8); err != nil {
+			err = errors.Wrapf(err, "astits: reading binding #%d type failed", idx+1)
+			return
+		}
+
+		// IOR
+		if b.ior, err = parseDSMCCIOR(r); err != nil {
+			err = errors.Wrapf(err, "astits: parsing binding #%d IOR failed", idx+1)
+			return
+		}
+
+		// Child object info
+		if _, err = readDSMCCLengthPrefixedBytes(r, 16); err != nil {
+			err = errors.Wrapf(err, "astits: reading binding #%d child object info failed", idx+1)
+			return
+		}
+		bs = append(bs, b)
+	}
+	return
+}
+
+// DSMCCFile represents a file of an object carousel
+type DSMCCFile struct {
+	Data []byte
+	Path string // Slash separated path from the service gateway, without leading slash
+}
+
+// DSMCCObjectCarousel represents an object capable of reassembling the modules of a DSM-CC object carousel out of its
+// DownloadInfoIndication and DownloadDataBlock messages, and of exposing its file system
+// Data of all the DSM-CC sections of the carousel, i.e. table IDs 0x3b and 0x3c of its PID, should be added
+type DSMCCObjectCarousel struct {
+	modules        map[uint16]*dsmccModule // Indexed by module ID
+	serviceGateway *DSMCCIOR
+}
+
+// dsmccModule represents a module being reassembled
+type dsmccModule struct {
+	blocks    [][]byte
+	blockSize uint16
+	data      []byte // Set once all blocks are received, decompressed if needed
+	info      *DSMCCDownloadInfoIndicationModule
+	received  int
+}
+
+// NewDSMCCObjectCarousel creates a new DSM-CC object carousel
+func NewDSMCCObjectCarousel() *DSMCCObjectCarousel {
+	return &DSMCCObjectCarousel{modules: make(map[uint16]*dsmccModule)}
+}
+
+// Add adds a DSM-CC data to the carousel
+// An error is returned when a complete module can't be decompressed
+func (c *DSMCCObjectCarousel) Add(d *DSMCCData) (err error) {
+	switch {
+	case d.DSI != nil:
+		if d.DSI.ServiceGateway != nil {
+			c.serviceGateway = d.DSI.ServiceGateway
+		}
+	case d.DII != nil:
+		c.addDII(d.DII)
+	case d.DDB != nil:
+		if err = c.addDDB(d.DDB); err != nil {
+			err = errors.Wrapf(err, "astits: adding block %d of module 0x%x failed", d.DDB.BlockNumber, d.DDB.ModuleID)
+			return
+		}
+	}
+	return
+}
+
+// addDII updates the modules described by a DownloadInfoIndication message, modules whose version changes being
+// reassembled again
+func (c *DSMCCObjectCarousel) addDII(d *DSMCCDownloadInfoIndication) {
+	for _, m := range d.Modules {
+		// Module is already known
+		if v, ok := c.modules[m.ID]; ok && v.info.Version == m.Version && v.info.Size == m.Size {
+			continue
+		}
+
+		// Create module
+		c.modules[m.ID] = &dsmccModule{
+			blocks:    make([][]byte, dsmccModuleBlocksCount(m.Size, d.BlockSize)),
+			blockSize: d.BlockSize,
+			info:      m,
+		}
+	}
+}
+
+// dsmccModuleBlocksCount returns the number of blocks of a module, which is never 0 so that empty modules are
+// complete once their single empty block is received
+func dsmccModuleBlocksCount(size uint32, blockSize uint16) int {
+	if blockSize == 0 || size == 0 {
+		return 1
+	}
+	return int((size + uint32(blockSize) - 1) / uint32(blockSize))
+}
+
+// addDDB adds a block to its module
+func (c *DSMCCObjectCarousel) addDDB(d *DSMCCDownloadDataBlock) (err error) {
+	// Get module
+	m, ok := c.modules[d.ModuleID]
+	if !ok || m.data != nil || m.info.Version != d.ModuleVersion || int(d.BlockNumber) >= len(m.blocks) || m.blocks[d.BlockNumber] != nil {
+		return
+	}
+
+	// Add block
+	m.blocks[d.BlockNumber] = append([]byte{}, d.Data...)
+	if m.received++; m.received < len(m.blocks) {
+		return
+	}
+
+	// Concatenate blocks
+	var b []byte
+	for _, v := range m.blocks {
+		b = append(b, v...)
+	}
+	if len(b) > int(m.info.Size) {
+		b = b[:m.info.Size]
+	}
+	m.blocks = nil
+
+	// Decompress
+	if m.info.Compressed {
+		if b, err = decompressDSMCCModule(b); err != nil {
+			// Module is reassembled again
+			m.blocks = make([][]byte, dsmccModuleBlocksCount(m.info.Size, m.blockSize))
+			m.received = 0
+			err = errors.Wrap(err, "astits: decompressing module failed")
+			return
+		}
+	}
+	m.data = b
+	return
+}
+
+// decompressDSMCCModule decompresses a module compressed with zlib
+func decompressDSMCCModule(i []byte) (o []byte, err error) {
+	var r io.ReadCloser
+	if r, err = zlib.NewReader(bytes.NewReader(i)); err != nil {
+		err = errors.Wrap(err, "astits: creating zlib reader failed")
+		return
+	}
+	defer r.Close()
+	if o, err = ioutil.ReadAll(r); err != nil {
+		err = errors.Wrap(err, "astits: reading zlib data failed")
+		return
+	}
+	return
+}
+
+// Files returns the files of the carousel that can be reached from its service gateway with the modules received so
+// far, sorted in the order of the directory bindings
+// An error is returned when a complete module can't be parsed
+func (c *DSMCCObjectCarousel) Files() (fs []*DSMCCFile, err error) {
+	// No service gateway
+	if c.serviceGateway == nil {
+		return
+	}
+
+	// Index objects of complete modules
+	var os = make(map[string]*biopObject)
+	for id, m := range c.modules {
+		if m.data == nil {
+			continue
+		}
+		var mos []*biopObject
+		if mos, err = parseBIOPObjects(m.data); err != nil {
+			err = errors.Wrapf(err, "astits: parsing objects of module 0x%x failed", id)
+			return
+		}
+		for _, o := range mos {
+			os[biopObjectID(id, o.key)] = o
+		}
+	}
+
+	// Walk the file system
+	fs = walkBIOPDirectory(os, os[biopObjectID(c.serviceGateway.ModuleID, c.serviceGateway.ObjectKey)], "", 0)
+	return
+}
+
+// biopObjectID returns the ID of an object in a carousel
+func biopObjectID(moduleID uint16, key []byte) string {
+	return fmt.Sprintf("%d/%x", moduleID, key)
+}
+
+// walkBIOPDirectory returns the files that can be reached from a directory
+// The depth is limited in case the carousel contains cycles
+func walkBIOPDirectory(os map[string]*biopObject, d *biopObject, path string, depth int) (fs []*DSMCCFile) {
+	// Check directory
+	if d == nil || depth > 32 {
+		return
+	}
+
+	// Loop through bindings
+	for _, b := range d.bindings {
+		// Get object
+		o, ok := os[biopObjectID(b.ior.ModuleID, b.ior.ObjectKey)]
+		if !ok {
+			continue
+		}
+
+		// Switch on kind
+		var p = path + b.name
+		switch o.kind {
+		case BIOPObjectKindDirectory:
+			fs = append(fs, walkBIOPDirectory(os, o, p+"/", depth+1)...)
+		case BIOPObjectKindFile:
+			fs = append(fs, &DSMCCFile{Data: o.content, Path: p})
+		}
+	}
+	return
+}
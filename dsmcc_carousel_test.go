@@ -0,0 +1,134 @@
+package astits
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func biopUint32(v int) []byte {
+	var b = make([]byte, 4)
+	binary.BigEndian.PutUint32(b, uint32(v))
+	return b
+}
+
+func biopIORBytes(kind string, moduleID uint16, key []byte) (b []byte) {
+	var l = append([]byte{0x0, 0x0, 0x0, 0x1, uint8(moduleID >> 8), uint8(moduleID), 0x1, 0x0, uint8(len(key))}, key...)
+	var p = append(append([]byte{0x0, 0x2, 0x49, 0x53, 0x4f, 0x40, 0x0}, []byte{0x49, 0x53, 0x4f, 0x50, uint8(len(l))}...), l...)
+	b = append(biopUint32(4), kind+"\x00"...)
+	b = append(b, biopUint32(2)...)
+	b = append(b, 0x0, 0x0, 0x0, 0x1, 0x0, 0x0, 0x0, 0x1, 0x5)
+	b = append(b, biopUint32(biopProfileTagBIOP)...)
+	b = append(b, biopUint32(len(p))...)
+	return append(b, p...)
+}
+
+func biopMessageBytes(key []byte, kind string, body []byte) (b []byte) {
+	b = append([]byte{uint8(len(key))}, key...)
+	b = append(b, biopUint32(4)...)
+	b = append(b, kind+"\x00"...)
+	b = append(b, 0x0, 0x2, 0x1, 0x2, 0x1, 0x0, 0x0, 0x0, 0x1, 0x0, 0x1, 0x9)
+	b = append(b, biopUint32(len(body))...)
+	b = append(b, body...)
+	return append(append([]byte{0x42, 0x49, 0x4f, 0x50, 0x1, 0x0, 0x0, 0x0}, biopUint32(len(b))...), b...)
+}
+
+func biopDirectoryBody(bindings ...[]byte) (b []byte) {
+	b = []byte{0x0, uint8(len(bindings))}
+	for _, v := range bindings {
+		b = append(b, v...)
+	}
+	return
+}
+
+func biopBindingBytes(name, kind string, moduleID uint16, key []byte) (b []byte) {
+	b = append([]byte{0x1, uint8(len(name) + 1)}, name+"\x00"...)
+	b = append(b, 0x4)
+	b = append(b, kind+"\x00"...)
+	b = append(b, 0x1)
+	b = append(b, biopIORBytes(kind, moduleID, key)...)
+	return append(b, 0x0, 0x0)
+}
+
+func biopFileBody(content string) []byte {
+	return append(append([]byte{}, biopUint32(len(content))...), content...)
+}
+
+func TestDSMCCObjectCarousel(t *testing.T) {
+	// Module 1 contains the service gateway and a directory, module 2 is compressed and contains files
+	var m1 = append(biopMessageBytes([]byte{0x1}, BIOPObjectKindServiceGateway, biopDirectoryBody(
+		biopBindingBytes("index.html", BIOPObjectKindFile, 2, []byte{0x1}),
+		biopBindingBytes("dir", BIOPObjectKindDirectory, 1, []byte{0x2}),
+		biopBindingBytes("missing", BIOPObjectKindFile, 3, []byte{0x1}),
+	)), biopMessageBytes([]byte{0x2}, BIOPObjectKindDirectory, biopDirectoryBody(
+		biopBindingBytes("a.txt", BIOPObjectKindFile, 2, []byte{0x2}),
+	))...)
+	var m2 = append(biopMessageBytes([]byte{0x1}, BIOPObjectKindFile, biopFileBody("<html>")), biopMessageBytes([]byte{0x2}, BIOPObjectKindFile, biopFileBody("a"))...)
+	buf := &bytes.Buffer{}
+	w := zlib.NewWriter(buf)
+	w.Write(m2)
+	w.Close()
+	var m2c = buf.Bytes()
+
+	// Init
+	c := NewDSMCCObjectCarousel()
+	var add = func(messageID uint16, m []byte) error {
+		var b = dsmccMessageBytes(messageID, 1, m)
+		var offset int
+		return c.Add(parseDSMCCSection(b, &offset, len(b)))
+	}
+
+	// No service gateway
+	fs, err := c.Files()
+	assert.NoError(t, err)
+	assert.Empty(t, fs)
+
+	// Add messages
+	assert.NoError(t, add(DSMCCMessageIDDownloadServerInitiate, dsmccDSIBytes(biopIORBytes(BIOPObjectKindServiceGateway, 1, []byte{0x1}))))
+	assert.Equal(t, &DSMCCIOR{CarouselID: 1, ModuleID: 1, ObjectKey: []byte{0x1}, TypeID: BIOPObjectKindServiceGateway}, c.serviceGateway)
+	assert.NoError(t, add(DSMCCMessageIDDownloadInfoIndication, dsmccDIIBytes(1, 16, dsmccDIIModuleBytes(1, uint32(len(m1)), 1, 0), dsmccDIIModuleBytes(2, uint32(len(m2c)), 1, uint32(len(m2))))))
+	for idx := 0; idx*16 < len(m1); idx++ {
+		var end = (idx + 1) * 16
+		if end > len(m1) {
+			end = len(m1)
+		}
+		assert.NoError(t, add(DSMCCMessageIDDownloadDataBlock, dsmccDDBBytes(1, 1, uint16(idx), m1[idx*16:end])))
+	}
+
+	// Module 2 is missing
+	fs, err = c.Files()
+	assert.NoError(t, err)
+	assert.Empty(t, fs)
+
+	// Blocks of another version are ignored
+	assert.NoError(t, add(DSMCCMessageIDDownloadDataBlock, dsmccDDBBytes(2, 2, 0, m2c)))
+	for idx := len(m2c)/16 + 1; idx >= 0; idx-- {
+		var start, end = idx * 16, (idx + 1) * 16
+		if start > len(m2c) {
+			continue
+		}
+		if end > len(m2c) {
+			end = len(m2c)
+		}
+		assert.NoError(t, add(DSMCCMessageIDDownloadDataBlock, dsmccDDBBytes(2, 1, uint16(idx), m2c[start:end])))
+	}
+	fs, err = c.Files()
+	assert.NoError(t, err)
+	assert.Equal(t, []*DSMCCFile{{Data: []byte("<html>"), Path: "index.html"}, {Data: []byte("a"), Path: "dir/a.txt"}}, fs)
+
+	// New module version
+	assert.NoError(t, add(DSMCCMessageIDDownloadInfoIndication, dsmccDIIBytes(1, 16, dsmccDIIModuleBytes(1, uint32(len(m1)), 1, 0), dsmccDIIModuleBytes(2, 4, 2, 10))))
+	fs, err = c.Files()
+	assert.NoError(t, err)
+	assert.Len(t, fs, 0)
+	assert.Error(t, add(DSMCCMessageIDDownloadDataBlock, dsmccDDBBytes(2, 2, 0, []byte{0x1, 0x2, 0x3, 0x4})))
+
+	// Invalid module
+	assert.NoError(t, add(DSMCCMessageIDDownloadInfoIndication, dsmccDIIBytes(1, 16, dsmccDIIModuleBytes(2, 4, 3, 0))))
+	assert.NoError(t, add(DSMCCMessageIDDownloadDataBlock, dsmccDDBBytes(2, 3, 0, []byte{0x1, 0x2, 0x3, 0x4})))
+	_, err = c.Files()
+	assert.Error(t, err)
+}
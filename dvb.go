@@ -51,3 +51,57 @@ func parseDVBDurationSeconds(i []byte, offset *int) (d time.Duration) {
 func parseDVBDurationByte(i byte) time.Duration {
 	return time.Duration(uint8(i)>>4*10 + uint8(i)&0xf)
 }
+
+// dvbMJDEpoch is the day 0 of the modified Julian date
+var dvbMJDEpoch = time.Date(1858, 11, 17, 0, 0, 0, 0, time.UTC)
+
+// writeDVBTime serializes a DVB time
+// The time is converted to UTC first
+func writeDVBTime(t time.Time) []byte {
+	t = t.UTC()
+	var mjd = uint16(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Sub(dvbMJDEpoch) / (24 * time.Hour))
+	return append([]byte{uint8(mjd >> 8), uint8(mjd)}, writeDVBDurationSeconds(time.Duration(t.Hour())*time.Hour+time.Duration(t.Minute())*time.Minute+time.Duration(t.Second())*time.Second)...)
+}
+
+// writeDVBDurationMinutes serializes a minutes duration
+func writeDVBDurationMinutes(d time.Duration) []byte {
+	return []byte{writeDVBDurationByte(int(d / time.Hour)), writeDVBDurationByte(int(d % time.Hour / time.Minute))}
+}
+
+// writeDVBDurationSeconds serializes a seconds duration
+func writeDVBDurationSeconds(d time.Duration) []byte {
+	return []byte{writeDVBDurationByte(int(d / time.Hour)), writeDVBDurationByte(int(d % time.Hour / time.Minute)), writeDVBDurationByte(int(d % time.Minute / time.Second))}
+}
+
+// writeDVBDurationByte serializes a duration byte
+func writeDVBDurationByte(i int) byte {
+	return uint8(i/10%10)<<4 | uint8(i%10)
+}
+
+// parseDVBBCD parses a number coded with a given number of 4-bit Binary Coded Decimal digits, starting with the most
+// significant one
+func parseDVBBCD(i []byte, digits int) (v uint64) {
+	for idx := 0; idx < digits; idx++ {
+		var b = i[idx/2]
+		if idx%2 == 0 {
+			b >>= 4
+		}
+		v = v*10 + uint64(b&0xf)
+	}
+	return
+}
+
+// writeDVBBCD serializes a number with a given number of 4-bit Binary Coded Decimal digits, starting with the most
+// significant one. When the number of digits is odd, the last 4 bits are left to 0
+func writeDVBBCD(v uint64, digits int) (b []byte) {
+	b = make([]byte, (digits+1)/2)
+	for idx := digits - 1; idx >= 0; idx-- {
+		var d = uint8(v % 10)
+		if idx%2 == 0 {
+			d <<= 4
+		}
+		b[idx/2] |= d
+		v /= 10
+	}
+	return
+}
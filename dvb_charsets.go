@@ -0,0 +1,195 @@
+package astits
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+)
+
+// DVBTextDecoder represents a text decoder for strings coded as described in the annex A of ETSI EN 300 468
+// The character table is selected by the first byte of the string: the default table 00 (ISO/IEC 6937), ISO/IEC 8859
+// parts, ISO/IEC 10646 (UCS-2) and UTF-8 are supported. Emphasis control codes are dropped and the CR/LF control code
+// is replaced by a new line
+// Page: 128 | Annex A | Link: https://www.dvb.org/resources/public/standards/a38_dvb-si_specification.pdf
+type DVBTextDecoder struct{}
+
+// NewDVBTextDecoder creates a new DVB text decoder
+func NewDVBTextDecoder() *DVBTextDecoder {
+	return &DVBTextDecoder{}
+}
+
+// dvbISO8859 contains the characters 0xa0 to 0xff of the ISO/IEC 8859 parts indexed by part
+var dvbISO8859 = func() (o map[int][]rune) {
+	o = make(map[int][]rune)
+	for part, s := range dvbISO8859HighParts {
+		o[part] = []rune(s)
+	}
+	return
+}()
+
+// dvbISO6937High contains the characters 0xa0 to 0xff of the ISO/IEC 6937 table, diacritical marks excluded
+var dvbISO6937High = []rune("\u00a0¡¢£$¥#§¤‘“«←↑→↓°±²³×µ¶·÷’”»¼½¾¿" +
+	"����������������" +
+	"―¹®©™♪¬¦����⅛⅜⅝⅞" +
+	"ΩÆĐªĦ�ĲĿŁØŒºÞŦŊŉ" +
+	"ĸæđðħıĳŀłøœßþŧŋ\u00ad")
+
+// DecodeText implements the TextDecoder interface
+func (d *DVBTextDecoder) DecodeText(i []byte) (o string, err error) {
+	// Empty
+	if len(i) == 0 {
+		return
+	}
+
+	// Switch on character table
+	switch b := i[0]; {
+	case b >= 0x20:
+		o = decodeDVBISO6937(i)
+	case b >= 0x1 && b <= 0xb:
+		o, err = decodeDVBISO8859(i[1:], int(b)+4)
+	case b == 0x10:
+		// Parts are coded on 2 bytes
+		if len(i) < 3 {
+			err = fmt.Errorf("astits: DVB character table 0x10 is truncated")
+			return
+		}
+		o, err = decodeDVBISO8859(i[3:], int(i[1])<<8|int(i[2]))
+	case b == 0x11:
+		o = decodeDVBUCS2(i[1:])
+	case b == 0x15:
+		o = decodeDVBUTF8(i[1:])
+	default:
+		err = fmt.Errorf("astits: DVB character table 0x%x is not supported", b)
+	}
+	return
+}
+
+// dvbCharacterTableSelector returns the character table selector the string starts with, if any
+func dvbCharacterTableSelector(i []byte) []byte {
+	if len(i) == 0 {
+		return nil
+	}
+	switch b := i[0]; {
+	case b >= 0x1 && b <= 0xb, b >= 0x11 && b <= 0x15:
+		return i[:1]
+	case b == 0x10 && len(i) >= 3:
+		return i[:3]
+	case b == 0x1f && len(i) >= 2:
+		return i[:2]
+	}
+	return nil
+}
+
+// appendDVBText appends a string continuing another one, dropping its character table selector when it's the same as
+// the one of the string it continues
+func appendDVBText(i, c []byte) []byte {
+	if s := dvbCharacterTableSelector(c); len(s) > 0 && bytes.Equal(s, dvbCharacterTableSelector(i)) {
+		c = c[len(s):]
+	}
+	return append(append([]byte{}, i...), c...)
+}
+
+// decodeDVBControlCode decodes a control code of the 0x80-0x9f range and returns whether it produces a character
+func decodeDVBControlCode(b uint8) (rune, bool) {
+	// CR/LF
+	if b == 0x8a {
+		return '\n', true
+	}
+	return 0, false
+}
+
+// decodeDVBISO6937 decodes a string coded with the default table 00
+func decodeDVBISO6937(i []byte) string {
+	var rs []rune
+	for offset := 0; offset < len(i); offset++ {
+		var b = i[offset]
+		switch {
+		case b < 0x80:
+			rs = append(rs, rune(b))
+		case b < 0xa0:
+			if r, ok := decodeDVBControlCode(b); ok {
+				rs = append(rs, r)
+			}
+		case b >= 0xc1 && b <= 0xcf:
+			// Diacritical marks apply to the following letter
+			if offset+1 < len(i) {
+				if r, ok := dvbISO6937Composed[b][i[offset+1]]; ok {
+					rs = append(rs, r)
+					offset++
+					continue
+				}
+			}
+			rs = append(rs, utf8.RuneError)
+		default:
+			rs = append(rs, dvbISO6937High[b-0xa0])
+		}
+	}
+	return string(rs)
+}
+
+// decodeDVBISO8859 decodes a string coded with a part of ISO/IEC 8859
+func decodeDVBISO8859(i []byte, part int) (o string, err error) {
+	// Get high part
+	var high []rune
+	if part != 1 {
+		var ok bool
+		if high, ok = dvbISO8859[part]; !ok {
+			err = fmt.Errorf("astits: ISO/IEC 8859 part %d is not supported", part)
+			return
+		}
+	}
+
+	// Loop through bytes
+	var rs []rune
+	for _, b := range i {
+		switch {
+		case b < 0x80:
+			rs = append(rs, rune(b))
+		case b < 0xa0:
+			if r, ok := decodeDVBControlCode(b); ok {
+				rs = append(rs, r)
+			}
+		case high == nil:
+			rs = append(rs, rune(b))
+		default:
+			rs = append(rs, high[b-0xa0])
+		}
+	}
+	o = string(rs)
+	return
+}
+
+// decodeDVBUCS2 decodes a string coded with the basic multilingual plane of ISO/IEC 10646
+// Control codes are coded in the 0xe080-0xe09f range
+func decodeDVBUCS2(i []byte) string {
+	var rs []rune
+	for offset := 0; offset+1 < len(i); offset += 2 {
+		var r = rune(i[offset])<<8 | rune(i[offset+1])
+		if r >= 0xe080 && r <= 0xe09f {
+			if r, ok := decodeDVBControlCode(uint8(r)); ok {
+				rs = append(rs, r)
+			}
+			continue
+		}
+		rs = append(rs, r)
+	}
+	return string(rs)
+}
+
+// decodeDVBUTF8 decodes a string coded with UTF-8
+// Control codes are coded as U+E080 to U+E09F and invalid sequences are replaced by U+FFFD
+func decodeDVBUTF8(i []byte) string {
+	var rs []rune
+	for len(i) > 0 {
+		r, size := utf8.DecodeRune(i)
+		i = i[size:]
+		if r >= 0xe080 && r <= 0xe09f {
+			if r, ok := decodeDVBControlCode(uint8(r)); ok {
+				rs = append(rs, r)
+			}
+			continue
+		}
+		rs = append(rs, r)
+	}
+	return string(rs)
+}
@@ -0,0 +1,38 @@
+package astits
+
+// dvbISO8859HighParts contains the characters 0xa0 to 0xff of the ISO/IEC 8859 parts 2 to 16, indexed by part. Part 1
+// maps directly to Unicode and part 12 doesn't exist. Undefined characters are U+FFFD
+var dvbISO8859HighParts = map[int]string{
+	2:  "\u00a0Ą˘Ł¤ĽŚ§¨ŠŞŤŹ\u00adŽŻ°ą˛ł´ľśˇ¸šşťź˝žżŔÁÂĂÄĹĆÇČÉĘËĚÍÎĎĐŃŇÓÔŐÖ×ŘŮÚŰÜÝŢßŕáâăäĺćçčéęëěíîďđńňóôőö÷řůúűüýţ˙",
+	3:  "\u00a0Ħ˘£¤�Ĥ§¨İŞĞĴ\u00ad�Ż°ħ²³´µĥ·¸ışğĵ½�żÀÁÂ�ÄĊĈÇÈÉÊËÌÍÎÏ�ÑÒÓÔĠÖ×ĜÙÚÛÜŬŜßàáâ�äċĉçèéêëìíîï�ñòóôġö÷ĝùúûüŭŝ˙",
+	4:  "\u00a0ĄĸŖ¤ĨĻ§¨ŠĒĢŦ\u00adŽ¯°ą˛ŗ´ĩļˇ¸šēģŧŊžŋĀÁÂÃÄÅÆĮČÉĘËĖÍÎĪĐŅŌĶÔÕÖ×ØŲÚÛÜŨŪßāáâãäåæįčéęëėíîīđņōķôõö÷øųúûüũū˙",
+	5:  "\u00a0ЁЂЃЄЅІЇЈЉЊЋЌ\u00adЎЏАБВГДЕЖЗИЙКЛМНОПРСТУФХЦЧШЩЪЫЬЭЮЯабвгдежзийклмнопрстуфхцчшщъыьэюя№ёђѓєѕіїјљњћќ§ўџ",
+	6:  "\u00a0���¤�������،\u00ad�������������؛���؟�ءآأؤإئابةتثجحخدذرزسشصضطظعغ�����ـفقكلمنهوىي\u064b\u064c\u064d\u064e\u064f\u0650\u0651\u0652�������������",
+	7:  "\u00a0‘’£€₯¦§¨©ͺ«¬\u00ad�―°±²³΄΅Ά·ΈΉΊ»Ό½ΎΏΐΑΒΓΔΕΖΗΘΙΚΛΜΝΞΟΠΡ�ΣΤΥΦΧΨΩΪΫάέήίΰαβγδεζηθικλμνξοπρςστυφχψωϊϋόύώ�",
+	8:  "\u00a0�¢£¤¥¦§¨©×«¬\u00ad®¯°±²³´µ¶·¸¹÷»¼½¾��������������������������������‗אבגדהוזחטיךכלםמןנסעףפץצקרשת��\u200e\u200f�",
+	9:  "\u00a0¡¢£¤¥¦§¨©ª«¬\u00ad®¯°±²³´µ¶·¸¹º»¼½¾¿ÀÁÂÃÄÅÆÇÈÉÊËÌÍÎÏĞÑÒÓÔÕÖ×ØÙÚÛÜİŞßàáâãäåæçèéêëìíîïğñòóôõö÷øùúûüışÿ",
+	10: "\u00a0ĄĒĢĪĨĶ§ĻĐŠŦŽ\u00adŪŊ°ąēģīĩķ·ļđšŧž―ūŋĀÁÂÃÄÅÆĮČÉĘËĖÍÎÏÐŅŌÓÔÕÖŨØŲÚÛÜÝÞßāáâãäåæįčéęëėíîïðņōóôõöũøųúûüýþĸ",
+	11: "\u00a0กขฃคฅฆงจฉชซฌญฎฏฐฑฒณดตถทธนบปผฝพฟภมยรฤลฦวศษสหฬอฮฯะ\u0e31าำ\u0e34\u0e35\u0e36\u0e37\u0e38\u0e39\u0e3a����฿เแโใไๅๆ\u0e47\u0e48\u0e49\u0e4a\u0e4b\u0e4c\u0e4d\u0e4e๏๐๑๒๓๔๕๖๗๘๙๚๛����",
+	13: "\u00a0”¢£¤„¦§Ø©Ŗ«¬\u00ad®Æ°±²³“µ¶·ø¹ŗ»¼½¾æĄĮĀĆÄÅĘĒČÉŹĖĢĶĪĻŠŃŅÓŌÕÖ×ŲŁŚŪÜŻŽßąįāćäåęēčéźėģķīļšńņóōõö÷ųłśūüżž’",
+	14: "\u00a0Ḃḃ£ĊċḊ§Ẁ©ẂḋỲ\u00ad®ŸḞḟĠġṀṁ¶ṖẁṗẃṠỳẄẅṡÀÁÂÃÄÅÆÇÈÉÊËÌÍÎÏŴÑÒÓÔÕÖṪØÙÚÛÜÝŶßàáâãäåæçèéêëìíîïŵñòóôõöṫøùúûüýŷÿ",
+	15: "\u00a0¡¢£€¥Š§š©ª«¬\u00ad®¯°±²³Žµ¶·ž¹º»ŒœŸ¿ÀÁÂÃÄÅÆÇÈÉÊËÌÍÎÏÐÑÒÓÔÕÖ×ØÙÚÛÜÝÞßàáâãäåæçèéêëìíîïðñòóôõö÷øùúûüýþÿ",
+	16: "\u00a0ĄąŁ€„Š§š©Ș«Ź\u00adźŻ°±ČłŽ”¶·žčș»ŒœŸżÀÁÂĂÄĆÆÇÈÉÊËÌÍÎÏĐŃÒÓÔŐÖŚŰÙÚÛÜĘȚßàáâăäćæçèéêëìíîïđńòóôőöśűùúûüęțÿ",
+}
+
+// dvbISO6937Composed contains the characters obtained by combining a diacritical mark of the ISO/IEC 6937 table,
+// indexed by the byte coding the mark, with the letter following it
+var dvbISO6937Composed = map[uint8]map[uint8]rune{
+	0xc1: {'A': 'À', 'E': 'È', 'I': 'Ì', 'N': 'Ǹ', 'O': 'Ò', 'U': 'Ù', 'W': 'Ẁ', 'Y': 'Ỳ', 'a': 'à', 'e': 'è', 'i': 'ì', 'n': 'ǹ', 'o': 'ò', 'u': 'ù', 'w': 'ẁ', 'y': 'ỳ'},
+	0xc2: {'A': 'Á', 'C': 'Ć', 'E': 'É', 'G': 'Ǵ', 'I': 'Í', 'K': 'Ḱ', 'L': 'Ĺ', 'M': 'Ḿ', 'N': 'Ń', 'O': 'Ó', 'P': 'Ṕ', 'R': 'Ŕ', 'S': 'Ś', 'U': 'Ú', 'W': 'Ẃ', 'Y': 'Ý', 'Z': 'Ź', 'a': 'á', 'c': 'ć', 'e': 'é', 'g': 'ǵ', 'i': 'í', 'k': 'ḱ', 'l': 'ĺ', 'm': 'ḿ', 'n': 'ń', 'o': 'ó', 'p': 'ṕ', 'r': 'ŕ', 's': 'ś', 'u': 'ú', 'w': 'ẃ', 'y': 'ý', 'z': 'ź'},
+	0xc3: {'A': 'Â', 'C': 'Ĉ', 'E': 'Ê', 'G': 'Ĝ', 'H': 'Ĥ', 'I': 'Î', 'J': 'Ĵ', 'O': 'Ô', 'S': 'Ŝ', 'U': 'Û', 'W': 'Ŵ', 'Y': 'Ŷ', 'Z': 'Ẑ', 'a': 'â', 'c': 'ĉ', 'e': 'ê', 'g': 'ĝ', 'h': 'ĥ', 'i': 'î', 'j': 'ĵ', 'o': 'ô', 's': 'ŝ', 'u': 'û', 'w': 'ŵ', 'y': 'ŷ', 'z': 'ẑ'},
+	0xc4: {'A': 'Ã', 'E': 'Ẽ', 'I': 'Ĩ', 'N': 'Ñ', 'O': 'Õ', 'U': 'Ũ', 'V': 'Ṽ', 'Y': 'Ỹ', 'a': 'ã', 'e': 'ẽ', 'i': 'ĩ', 'n': 'ñ', 'o': 'õ', 'u': 'ũ', 'v': 'ṽ', 'y': 'ỹ'},
+	0xc5: {'A': 'Ā', 'E': 'Ē', 'G': 'Ḡ', 'I': 'Ī', 'O': 'Ō', 'U': 'Ū', 'Y': 'Ȳ', 'a': 'ā', 'e': 'ē', 'g': 'ḡ', 'i': 'ī', 'o': 'ō', 'u': 'ū', 'y': 'ȳ'},
+	0xc6: {'A': 'Ă', 'E': 'Ĕ', 'G': 'Ğ', 'I': 'Ĭ', 'O': 'Ŏ', 'U': 'Ŭ', 'a': 'ă', 'e': 'ĕ', 'g': 'ğ', 'i': 'ĭ', 'o': 'ŏ', 'u': 'ŭ'},
+	0xc7: {'A': 'Ȧ', 'B': 'Ḃ', 'C': 'Ċ', 'D': 'Ḋ', 'E': 'Ė', 'F': 'Ḟ', 'G': 'Ġ', 'H': 'Ḣ', 'I': 'İ', 'M': 'Ṁ', 'N': 'Ṅ', 'O': 'Ȯ', 'P': 'Ṗ', 'R': 'Ṙ', 'S': 'Ṡ', 'T': 'Ṫ', 'W': 'Ẇ', 'X': 'Ẋ', 'Y': 'Ẏ', 'Z': 'Ż', 'a': 'ȧ', 'b': 'ḃ', 'c': 'ċ', 'd': 'ḋ', 'e': 'ė', 'f': 'ḟ', 'g': 'ġ', 'h': 'ḣ', 'm': 'ṁ', 'n': 'ṅ', 'o': 'ȯ', 'p': 'ṗ', 'r': 'ṙ', 's': 'ṡ', 't': 'ṫ', 'w': 'ẇ', 'x': 'ẋ', 'y': 'ẏ', 'z': 'ż'},
+	0xc8: {'A': 'Ä', 'E': 'Ë', 'H': 'Ḧ', 'I': 'Ï', 'O': 'Ö', 'U': 'Ü', 'W': 'Ẅ', 'X': 'Ẍ', 'Y': 'Ÿ', 'a': 'ä', 'e': 'ë', 'h': 'ḧ', 'i': 'ï', 'o': 'ö', 't': 'ẗ', 'u': 'ü', 'w': 'ẅ', 'x': 'ẍ', 'y': 'ÿ'},
+	0xca: {'A': 'Å', 'U': 'Ů', 'a': 'å', 'u': 'ů', 'w': 'ẘ', 'y': 'ẙ'},
+	0xcb: {'C': 'Ç', 'D': 'Ḑ', 'E': 'Ȩ', 'G': 'Ģ', 'H': 'Ḩ', 'K': 'Ķ', 'L': 'Ļ', 'N': 'Ņ', 'R': 'Ŗ', 'S': 'Ş', 'T': 'Ţ', 'c': 'ç', 'd': 'ḑ', 'e': 'ȩ', 'g': 'ģ', 'h': 'ḩ', 'k': 'ķ', 'l': 'ļ', 'n': 'ņ', 'r': 'ŗ', 's': 'ş', 't': 'ţ'},
+	0xcd: {'O': 'Ő', 'U': 'Ű', 'o': 'ő', 'u': 'ű'},
+	0xce: {'A': 'Ą', 'E': 'Ę', 'I': 'Į', 'O': 'Ǫ', 'U': 'Ų', 'a': 'ą', 'e': 'ę', 'i': 'į', 'o': 'ǫ', 'u': 'ų'},
+	0xcf: {'A': 'Ǎ', 'C': 'Č', 'D': 'Ď', 'E': 'Ě', 'G': 'Ǧ', 'H': 'Ȟ', 'I': 'Ǐ', 'K': 'Ǩ', 'L': 'Ľ', 'N': 'Ň', 'O': 'Ǒ', 'R': 'Ř', 'S': 'Š', 'T': 'Ť', 'U': 'Ǔ', 'Z': 'Ž', 'a': 'ǎ', 'c': 'č', 'd': 'ď', 'e': 'ě', 'g': 'ǧ', 'h': 'ȟ', 'i': 'ǐ', 'j': 'ǰ', 'k': 'ǩ', 'l': 'ľ', 'n': 'ň', 'o': 'ǒ', 'r': 'ř', 's': 'š', 't': 'ť', 'u': 'ǔ', 'z': 'ž'},
+}
@@ -0,0 +1,43 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDVBISO8859HighParts(t *testing.T) {
+	for part, s := range dvbISO8859 {
+		assert.Len(t, s, 96, "part %d", part)
+	}
+	assert.Len(t, dvbISO6937High, 96)
+}
+
+func TestDVBTextDecoder(t *testing.T) {
+	d := NewDVBTextDecoder()
+	for _, v := range []struct {
+		i []byte
+		o string
+	}{
+		{i: []byte{}, o: ""},
+		{i: []byte("caf\xc2e \x86cr\x87\x8a\xa9s\xa4"), o: "café cr\n‘s$"},
+		{i: []byte("\x01\xb0\xbf"), o: "АП"},
+		{i: []byte("\x05\xd0\xfe"), o: "Ğş"},
+		{i: []byte("\x10\x00\x01caf\xe9"), o: "café"},
+		{i: []byte("\x10\x00\x0f\xa4"), o: "€"},
+		{i: []byte("\x11\x00c\x00\xe9\xe0\x8a\x4e\x2d"), o: "cé\n中"},
+		{i: []byte("\x15caf\xc3\xa9\xee\x82\x8a"), o: "café\n"},
+	} {
+		o, err := d.DecodeText(v.i)
+		assert.NoError(t, err)
+		assert.Equal(t, v.o, o)
+	}
+
+	// Errors
+	_, err := d.DecodeText([]byte("\x08test"))
+	assert.EqualError(t, err, "astits: ISO/IEC 8859 part 12 is not supported")
+	_, err = d.DecodeText([]byte("\x10\x00"))
+	assert.EqualError(t, err, "astits: DVB character table 0x10 is truncated")
+	_, err = d.DecodeText([]byte("\x13test"))
+	assert.EqualError(t, err, "astits: DVB character table 0x13 is not supported")
+}
@@ -0,0 +1,792 @@
+package astits
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DVB subtitle segment types
+// Page: 20 | Chapter: 7.2 | Link: https://www.etsi.org/deliver/etsi_en/300700_300799/300743/01.06.01_60/en_300743v010601p.pdf
+const (
+	DVBSubtitleSegmentTypeAlternativeCLUT     = 0x16
+	DVBSubtitleSegmentTypeCLUTDefinition      = 0x12
+	DVBSubtitleSegmentTypeDisparitySignalling = 0x15
+	DVBSubtitleSegmentTypeDisplayDefinition   = 0x14
+	DVBSubtitleSegmentTypeEndOfDisplaySet     = 0x80
+	DVBSubtitleSegmentTypeObjectData          = 0x13
+	DVBSubtitleSegmentTypePageComposition     = 0x10
+	DVBSubtitleSegmentTypeRegionComposition   = 0x11
+	DVBSubtitleSegmentTypeStuffing            = 0xff
+)
+
+// DVB subtitle object coding methods
+const (
+	DVBSubtitleObjectCodingMethodPixels             = 0x0
+	DVBSubtitleObjectCodingMethodProgressivePixels  = 0x2
+	DVBSubtitleObjectCodingMethodStringOfCharacters = 0x1
+)
+
+// DVB subtitle object types
+const (
+	DVBSubtitleObjectTypeBasicBitmap     = 0x0
+	DVBSubtitleObjectTypeBasicCharacter  = 0x1
+	DVBSubtitleObjectTypeCompositeString = 0x2
+)
+
+// DVB subtitle page states
+const (
+	DVBSubtitlePageStateAcquisitionPoint = 0x1
+	DVBSubtitlePageStateModeChange       = 0x2
+	DVBSubtitlePageStateNormalCase       = 0x0
+)
+
+// DVB subtitle pixel data types
+const (
+	dvbSubtitlePixelDataType2BitCodeString  = 0x10
+	dvbSubtitlePixelDataType2To4BitMapTable = 0x20
+	dvbSubtitlePixelDataType2To8BitMapTable = 0x21
+	dvbSubtitlePixelDataType4BitCodeString  = 0x11
+	dvbSubtitlePixelDataType4To8BitMapTable = 0x22
+	dvbSubtitlePixelDataType8BitCodeString  = 0x12
+	dvbSubtitlePixelDataTypeEndOfObjectLine = 0xf0
+)
+
+// DVB subtitle constants
+const (
+	dvbSubtitleDataIdentifier = 0x20
+	dvbSubtitleSyncByte       = 0x0f
+)
+
+// DVBSubtitleData represents the content of a DVB subtitle PES, i.e. the segments describing the display sets of
+// subtitle pages
+// Page: 19 | Chapter: 7.1 | Link: https://www.etsi.org/deliver/etsi_en/300700_300799/300743/01.06.01_60/en_300743v010601p.pdf
+type DVBSubtitleData struct {
+	Segments         []*DVBSubtitleSegment
+	SubtitleStreamID uint8
+}
+
+// DVBSubtitleSegment represents a DVB subtitle segment
+// Only one of the segment fields is set, depending on the type. Segments whose type is not parsed are only available
+// through their data
+type DVBSubtitleSegment struct {
+	CLUTDefinition    *DVBSubtitleCLUTDefinition
+	Data              []byte // Segment data, segment header excluded
+	DisplayDefinition *DVBSubtitleDisplayDefinition
+	ObjectData        *DVBSubtitleObjectData
+	PageComposition   *DVBSubtitlePageComposition
+	PageID            uint16 // Composition or ancillary page ID announced in the subtitling descriptor
+	RegionComposition *DVBSubtitleRegionComposition
+	Type              uint8
+}
+
+// DVBSubtitleCLUTDefinition represents a DVB subtitle CLUT definition segment
+// Page: 27 | Chapter: 7.2.4 | Link: https://www.etsi.org/deliver/etsi_en/300700_300799/300743/01.06.01_60/en_300743v010601p.pdf
+type DVBSubtitleCLUTDefinition struct {
+	Entries       []*DVBSubtitleCLUTEntry
+	ID            uint8
+	VersionNumber uint8
+}
+
+// DVBSubtitleCLUTEntry represents an entry of a DVB subtitle CLUT
+// Values coded with a reduced range are scaled to 8 bits
+type DVBSubtitleCLUTEntry struct {
+	Cb         uint8
+	Cr         uint8
+	FullRange  bool
+	ID         uint8
+	In2BitCLUT bool
+	In4BitCLUT bool
+	In8BitCLUT bool
+	T          uint8 // Transparency, 0 being opaque
+	Y          uint8 // 0 means the entry is fully transparent
+}
+
+// DVBSubtitleDisplayDefinition represents a DVB subtitle display definition segment
+// Page: 21 | Chapter: 7.2.1 | Link: https://www.etsi.org/deliver/etsi_en/300700_300799/300743/01.06.01_60/en_300743v010601p.pdf
+type DVBSubtitleDisplayDefinition struct {
+	DisplayHeight                   uint16 // In pixels
+	DisplayWidth                    uint16 // In pixels
+	HasWindow                       bool
+	VersionNumber                   uint8
+	WindowHorizontalPositionMaximum uint16
+	WindowHorizontalPositionMinimum uint16
+	WindowVerticalPositionMaximum   uint16
+	WindowVerticalPositionMinimum   uint16
+}
+
+// DVBSubtitleObjectData represents a DVB subtitle object data segment
+// Page: 28 | Chapter: 7.2.5 | Link: https://www.etsi.org/deliver/etsi_en/300700_300799/300743/01.06.01_60/en_300743v010601p.pdf
+type DVBSubtitleObjectData struct {
+	BottomFieldDataBlock   []byte   // Pixel data sub-block of the bottom field. Empty when the top field is repeated
+	Characters             []uint16 // Set when the object is coded as a string of characters
+	CodingMethod           uint8
+	ID                     uint16
+	NonModifyingColourFlag bool
+	TopFieldDataBlock      []byte // Pixel data sub-block of the top field
+	VersionNumber          uint8
+}
+
+// DVBSubtitlePageComposition represents a DVB subtitle page composition segment
+// Page: 22 | Chapter: 7.2.2 | Link: https://www.etsi.org/deliver/etsi_en/300700_300799/300743/01.06.01_60/en_300743v010601p.pdf
+type DVBSubtitlePageComposition struct {
+	Regions       []*DVBSubtitlePageRegion
+	State         uint8
+	TimeOut       time.Duration // Duration after which the page is erased
+	VersionNumber uint8
+}
+
+// DVBSubtitlePageRegion represents a region displayed on a DVB subtitle page
+type DVBSubtitlePageRegion struct {
+	HorizontalAddress uint16
+	ID                uint8
+	VerticalAddress   uint16
+}
+
+// DVBSubtitleRegionComposition represents a DVB subtitle region composition segment
+// Page: 24 | Chapter: 7.2.3 | Link: https://www.etsi.org/deliver/etsi_en/300700_300799/300743/01.06.01_60/en_300743v010601p.pdf
+type DVBSubtitleRegionComposition struct {
+	CLUTID               uint8
+	Depth                uint8 // In bits per pixel
+	FillFlag             bool
+	Height               uint16
+	ID                   uint8
+	LevelOfCompatibility uint8 // In bits per pixel
+	Objects              []*DVBSubtitleRegionObject
+	PixelCode2Bit        uint8
+	PixelCode4Bit        uint8
+	PixelCode8Bit        uint8
+	VersionNumber        uint8
+	Width                uint16
+}
+
+// DVBSubtitleRegionObject represents an object displayed in a DVB subtitle region
+type DVBSubtitleRegionObject struct {
+	BackgroundPixelCode uint8 // Only set for character objects
+	ForegroundPixelCode uint8 // Only set for character objects
+	HorizontalPosition  uint16
+	ID                  uint16
+	ProviderFlag        uint8
+	Type                uint8
+	VerticalPosition    uint16
+}
+
+// ParseDVBSubtitle parses the payload of a DVB subtitle PES, carried on an elementary stream of the
+// StreamTypeMPEG2PacketizedData stream type described by a subtitling descriptor, into segments
+func ParseDVBSubtitle(i []byte) (d *DVBSubtitleData, err error) {
+	// Check header
+	if len(i) < 2 {
+		err = fmt.Errorf("astits: dvb subtitle header end (2) > len(i) (%d)", len(i))
+		return
+	} else if i[0] != dvbSubtitleDataIdentifier {
+		err = fmt.Errorf("astits: invalid dvb subtitle data identifier 0x%x", i[0])
+		return
+	}
+
+	// Init
+	d = &DVBSubtitleData{SubtitleStreamID: i[1]}
+	var offset = 2
+
+	// Loop through segments
+	for offset < len(i) && i[offset] == dvbSubtitleSyncByte {
+		var s *DVBSubtitleSegment
+		if s, err = parseDVBSubtitleSegment(i, &offset); err != nil {
+			err = errors.Wrapf(err, "astits: parsing dvb subtitle segment at offset %d failed", offset)
+			return
+		}
+		d.Segments = append(d.Segments, s)
+	}
+	return
+}
+
+// parseDVBSubtitleSegment parses a DVB subtitle segment
+func parseDVBSubtitleSegment(i []byte, offset *int) (s *DVBSubtitleSegment, err error) {
+	// Check for incomplete header
+	if *offset+6 > len(i) {
+		err = fmt.Errorf("astits: dvb subtitle segment header end (%d) > len(i) (%d)", *offset+6, len(i))
+		return
+	}
+
+	// Header
+	s = &DVBSubtitleSegment{
+		PageID: uint16(i[*offset+2])<<8 | uint16(i[*offset+3]),
+		Type:   i[*offset+1],
+	}
+	var length = int(i[*offset+4])<<8 | int(i[*offset+5])
+	*offset += 6
+
+	// Data
+	if *offset+length > len(i) {
+		err = fmt.Errorf("astits: dvb subtitle segment end (%d) > len(i) (%d)", *offset+length, len(i))
+		return
+	}
+	s.Data = i[*offset : *offset+length]
+	*offset += length
+
+	// Switch on type
+	switch s.Type {
+	case DVBSubtitleSegmentTypeCLUTDefinition:
+		s.CLUTDefinition, err = parseDVBSubtitleCLUTDefinition(s.Data)
+	case DVBSubtitleSegmentTypeDisplayDefinition:
+		s.DisplayDefinition, err = parseDVBSubtitleDisplayDefinition(s.Data)
+	case DVBSubtitleSegmentTypeObjectData:
+		s.ObjectData, err = parseDVBSubtitleObjectData(s.Data)
+	case DVBSubtitleSegmentTypePageComposition:
+		s.PageComposition, err = parseDVBSubtitlePageComposition(s.Data)
+	case DVBSubtitleSegmentTypeRegionComposition:
+		s.RegionComposition, err = parseDVBSubtitleRegionComposition(s.Data)
+	}
+	if err != nil {
+		err = errors.Wrapf(err, "astits: parsing dvb subtitle segment of type 0x%x failed", s.Type)
+		return
+	}
+	return
+}
+
+// parseDVBSubtitleCLUTDefinition parses a DVB subtitle CLUT definition segment
+func parseDVBSubtitleCLUTDefinition(i []byte) (d *DVBSubtitleCLUTDefinition, err error) {
+	// Check for incomplete data
+	if len(i) < 2 {
+		err = fmt.Errorf("astits: clut definition header end (2) > len(i) (%d)", len(i))
+		return
+	}
+
+	// Init
+	d = &DVBSubtitleCLUTDefinition{
+		ID:            i[0],
+		VersionNumber: i[1] >> 4,
+	}
+	var offset = 2
+
+	// Loop through entries
+	for offset < len(i) {
+		// Check for incomplete entry
+		if offset+2 > len(i) {
+			err = fmt.Errorf("astits: clut entry header end (%d) > len(i) (%d)", offset+2, len(i))
+			return
+		}
+
+		// Header
+		var e = &DVBSubtitleCLUTEntry{
+			FullRange:  i[offset+1]&0x1 > 0,
+			ID:         i[offset],
+			In2BitCLUT: i[offset+1]&0x80 > 0,
+			In4BitCLUT: i[offset+1]&0x40 > 0,
+			In8BitCLUT: i[offset+1]&0x20 > 0,
+		}
+		offset += 2
+
+		// Values
+		if e.FullRange {
+			if offset+4 > len(i) {
+				err = fmt.Errorf("astits: clut entry end (%d) > len(i) (%d)", offset+4, len(i))
+				return
+			}
+			e.Y, e.Cr, e.Cb, e.T = i[offset], i[offset+1], i[offset+2], i[offset+3]
+			offset += 4
+		} else {
+			if offset+2 > len(i) {
+				err = fmt.Errorf("astits: clut entry end (%d) > len(i) (%d)", offset+2, len(i))
+				return
+			}
+			var v = uint16(i[offset])<<8 | uint16(i[offset+1])
+			e.Y = uint8(v>>10) << 2
+			e.Cr = uint8(v>>6&0xf) << 4
+			e.Cb = uint8(v>>2&0xf) << 4
+			e.T = uint8(v&0x3) << 6
+			offset += 2
+		}
+		d.Entries = append(d.Entries, e)
+	}
+	return
+}
+
+// parseDVBSubtitleDisplayDefinition parses a DVB subtitle display definition segment
+func parseDVBSubtitleDisplayDefinition(i []byte) (d *DVBSubtitleDisplayDefinition, err error) {
+	// Check for incomplete data
+	if len(i) < 5 {
+		err = fmt.Errorf("astits: display definition end (5) > len(i) (%d)", len(i))
+		return
+	}
+
+	// Init
+	d = &DVBSubtitleDisplayDefinition{
+		DisplayHeight: (uint16(i[3])<<8 | uint16(i[4])) + 1,
+		DisplayWidth:  (uint16(i[1])<<8 | uint16(i[2])) + 1,
+		HasWindow:     i[0]&0x8 > 0,
+		VersionNumber: i[0] >> 4,
+	}
+
+	// Window
+	if d.HasWindow {
+		if len(i) < 13 {
+			err = fmt.Errorf("astits: display definition window end (13) > len(i) (%d)", len(i))
+			return
+		}
+		d.WindowHorizontalPositionMinimum = uint16(i[5])<<8 | uint16(i[6])
+		d.WindowHorizontalPositionMaximum = uint16(i[7])<<8 | uint16(i[8])
+		d.WindowVerticalPositionMinimum = uint16(i[9])<<8 | uint16(i[10])
+		d.WindowVerticalPositionMaximum = uint16(i[11])<<8 | uint16(i[12])
+	}
+	return
+}
+
+// parseDVBSubtitleObjectData parses a DVB subtitle object data segment
+func parseDVBSubtitleObjectData(i []byte) (d *DVBSubtitleObjectData, err error) {
+	// Check for incomplete data
+	if len(i) < 3 {
+		err = fmt.Errorf("astits: object data header end (3) > len(i) (%d)", len(i))
+		return
+	}
+
+	// Init
+	d = &DVBSubtitleObjectData{
+		CodingMethod:           i[2] >> 2 & 0x3,
+		ID:                     uint16(i[0])<<8 | uint16(i[1]),
+		NonModifyingColourFlag: i[2]&0x2 > 0,
+		VersionNumber:          i[2] >> 4,
+	}
+	var offset = 3
+
+	// Switch on coding method
+	switch d.CodingMethod {
+	case DVBSubtitleObjectCodingMethodPixels:
+		// Lengths
+		if offset+4 > len(i) {
+			err = fmt.Errorf("astits: object data lengths end (%d) > len(i) (%d)", offset+4, len(i))
+			return
+		}
+		var topLength = int(i[offset])<<8 | int(i[offset+1])
+		var bottomLength = int(i[offset+2])<<8 | int(i[offset+3])
+		offset += 4
+
+		// Data blocks
+		if offset+topLength+bottomLength > len(i) {
+			err = fmt.Errorf("astits: object data blocks end (%d) > len(i) (%d)", offset+topLength+bottomLength, len(i))
+			return
+		}
+		d.TopFieldDataBlock = i[offset : offset+topLength]
+		offset += topLength
+		d.BottomFieldDataBlock = i[offset : offset+bottomLength]
+	case DVBSubtitleObjectCodingMethodStringOfCharacters:
+		// Number of codes
+		if offset+1 > len(i) {
+			err = fmt.Errorf("astits: object data number of codes end (%d) > len(i) (%d)", offset+1, len(i))
+			return
+		}
+		var n = int(i[offset])
+		offset += 1
+
+		// Character codes
+		if offset+2*n > len(i) {
+			err = fmt.Errorf("astits: object data character codes end (%d) > len(i) (%d)", offset+2*n, len(i))
+			return
+		}
+		for idx := 0; idx < n; idx++ {
+			d.Characters = append(d.Characters, uint16(i[offset])<<8|uint16(i[offset+1]))
+			offset += 2
+		}
+	}
+	return
+}
+
+// parseDVBSubtitlePageComposition parses a DVB subtitle page composition segment
+func parseDVBSubtitlePageComposition(i []byte) (d *DVBSubtitlePageComposition, err error) {
+	// Check for incomplete data
+	if len(i) < 2 {
+		err = fmt.Errorf("astits: page composition header end (2) > len(i) (%d)", len(i))
+		return
+	}
+
+	// Init
+	d = &DVBSubtitlePageComposition{
+		State:         i[1] >> 2 & 0x3,
+		TimeOut:       time.Duration(i[0]) * time.Second,
+		VersionNumber: i[1] >> 4,
+	}
+	var offset = 2
+
+	// Loop through regions
+	for offset < len(i) {
+		if offset+6 > len(i) {
+			err = fmt.Errorf("astits: page region end (%d) > len(i) (%d)", offset+6, len(i))
+			return
+		}
+		d.Regions = append(d.Regions, &DVBSubtitlePageRegion{
+			HorizontalAddress: uint16(i[offset+2])<<8 | uint16(i[offset+3]),
+			ID:                i[offset],
+			VerticalAddress:   uint16(i[offset+4])<<8 | uint16(i[offset+5]),
+		})
+		offset += 6
+	}
+	return
+}
+
+// parseDVBSubtitleRegionComposition parses a DVB subtitle region composition segment
+func parseDVBSubtitleRegionComposition(i []byte) (d *DVBSubtitleRegionComposition, err error) {
+	// Check for incomplete data
+	if len(i) < 10 {
+		err = fmt.Errorf("astits: region composition header end (10) > len(i) (%d)", len(i))
+		return
+	}
+
+	// Init
+	d = &DVBSubtitleRegionComposition{
+		CLUTID:               i[7],
+		Depth:                1 << (i[6] >> 2 & 0x7),
+		FillFlag:             i[1]&0x8 > 0,
+		Height:               uint16(i[4])<<8 | uint16(i[5]),
+		ID:                   i[0],
+		LevelOfCompatibility: 1 << (i[6] >> 5),
+		PixelCode2Bit:        i[9] >> 2 & 0x3,
+		PixelCode4Bit:        i[9] >> 4,
+		PixelCode8Bit:        i[8],
+		VersionNumber:        i[1] >> 4,
+		Width:                uint16(i[2])<<8 | uint16(i[3]),
+	}
+	var offset = 10
+
+	// Loop through objects
+	for offset < len(i) {
+		// Check for incomplete object
+		if offset+6 > len(i) {
+			err = fmt.Errorf("astits: region object end (%d) > len(i) (%d)", offset+6, len(i))
+			return
+		}
+
+		// Object
+		var o = &DVBSubtitleRegionObject{
+			HorizontalPosition: uint16(i[offset+2]&0xf)<<8 | uint16(i[offset+3]),
+			ID:                 uint16(i[offset])<<8 | uint16(i[offset+1]),
+			ProviderFlag:       i[offset+2] >> 4 & 0x3,
+			Type:               i[offset+2] >> 6,
+			VerticalPosition:   uint16(i[offset+4]&0xf)<<8 | uint16(i[offset+5]),
+		}
+		offset += 6
+
+		// Character objects
+		if o.Type == DVBSubtitleObjectTypeBasicCharacter || o.Type == DVBSubtitleObjectTypeCompositeString {
+			if offset+2 > len(i) {
+				err = fmt.Errorf("astits: region object pixel codes end (%d) > len(i) (%d)", offset+2, len(i))
+				return
+			}
+			o.ForegroundPixelCode = i[offset]
+			o.BackgroundPixelCode = i[offset+1]
+			offset += 2
+		}
+		d.Objects = append(d.Objects, o)
+	}
+	return
+}
+
+// DecodePixels decodes the pixel data sub-blocks of an object coded as pixels into lines of pixel codes, ready to be
+// looked up in the CLUT of a region whose depth in bits per pixel is provided
+// Lines of the top field and of the bottom field are interleaved, the top field being repeated when the bottom field
+// is empty. Pixel codes are mapped to the depth of the region with the map tables of the sub-blocks, or the default
+// ones, and reduced to their most significant bits when their depth is bigger than the region's.
+func (d *DVBSubtitleObjectData) DecodePixels(regionDepth uint8) (lines [][]uint8, err error) {
+	// Check coding method
+	if d.CodingMethod != DVBSubtitleObjectCodingMethodPixels {
+		err = fmt.Errorf("astits: object coding method 0x%x is not pixels", d.CodingMethod)
+		return
+	}
+
+	// Check region depth
+	if regionDepth != 2 && regionDepth != 4 && regionDepth != 8 {
+		err = fmt.Errorf("astits: invalid region depth %d", regionDepth)
+		return
+	}
+
+	// Decode top field
+	var top [][]uint8
+	if top, err = decodeDVBSubtitlePixelDataSubBlock(d.TopFieldDataBlock, regionDepth); err != nil {
+		err = errors.Wrap(err, "astits: decoding top field failed")
+		return
+	}
+
+	// Decode bottom field
+	var bottom = top
+	if len(d.BottomFieldDataBlock) > 0 {
+		if bottom, err = decodeDVBSubtitlePixelDataSubBlock(d.BottomFieldDataBlock, regionDepth); err != nil {
+			err = errors.Wrap(err, "astits: decoding bottom field failed")
+			return
+		}
+	}
+
+	// Interleave fields
+	for idx := 0; idx < len(top) || idx < len(bottom); idx++ {
+		if idx < len(top) {
+			lines = append(lines, top[idx])
+		}
+		if idx < len(bottom) {
+			lines = append(lines, bottom[idx])
+		}
+	}
+	return
+}
+
+// decodeDVBSubtitlePixelDataSubBlock decodes a pixel data sub-block into lines of pixel codes
+// Page: 30 | Chapter: 7.2.5.1 | Link: https://www.etsi.org/deliver/etsi_en/300700_300799/300743/01.06.01_60/en_300743v010601p.pdf
+func decodeDVBSubtitlePixelDataSubBlock(i []byte, regionDepth uint8) (lines [][]uint8, err error) {
+	// Default map tables
+	var map2To4 = []uint8{0x0, 0x7, 0x8, 0xf}
+	var map2To8 = []uint8{0x00, 0x77, 0x88, 0xff}
+	var map4To8 = make([]uint8, 16)
+	for idx := range map4To8 {
+		map4To8[idx] = uint8(idx * 0x11)
+	}
+
+	// Loop through data types
+	var line []uint8
+	var offset int
+	for offset < len(i) {
+		var t = i[offset]
+		offset += 1
+		switch t {
+		case dvbSubtitlePixelDataType2BitCodeString, dvbSubtitlePixelDataType4BitCodeString, dvbSubtitlePixelDataType8BitCodeString:
+			// Decode code string
+			var codes []uint8
+			var depth uint8
+			var r = newBitReader(i[offset:])
+			switch t {
+			case dvbSubtitlePixelDataType2BitCodeString:
+				depth = 2
+				codes, err = decodeDVBSubtitle2BitCodeString(r)
+			case dvbSubtitlePixelDataType4BitCodeString:
+				depth = 4
+				codes, err = decodeDVBSubtitle4BitCodeString(r)
+			default:
+				depth = 8
+				codes, err = decodeDVBSubtitle8BitCodeString(r)
+			}
+			if err != nil {
+				err = errors.Wrapf(err, "astits: decoding code string of type 0x%x failed", t)
+				return
+			}
+			offset += r.byteOffset()
+
+			// Map codes to the depth of the region
+			for _, c := range codes {
+				switch {
+				case depth == regionDepth:
+				case depth > regionDepth:
+					c >>= depth - regionDepth
+				case depth == 2 && regionDepth == 4:
+					c = map2To4[c]
+				case depth == 2:
+					c = map2To8[c]
+				default:
+					c = map4To8[c]
+				}
+				line = append(line, c)
+			}
+		case dvbSubtitlePixelDataType2To4BitMapTable:
+			if offset+2 > len(i) {
+				err = fmt.Errorf("astits: 2 to 4 bit map table end (%d) > len(i) (%d)", offset+2, len(i))
+				return
+			}
+			map2To4 = []uint8{i[offset] >> 4, i[offset] & 0xf, i[offset+1] >> 4, i[offset+1] & 0xf}
+			offset += 2
+		case dvbSubtitlePixelDataType2To8BitMapTable:
+			if offset+4 > len(i) {
+				err = fmt.Errorf("astits: 2 to 8 bit map table end (%d) > len(i) (%d)", offset+4, len(i))
+				return
+			}
+			map2To8 = append([]uint8{}, i[offset:offset+4]...)
+			offset += 4
+		case dvbSubtitlePixelDataType4To8BitMapTable:
+			if offset+16 > len(i) {
+				err = fmt.Errorf("astits: 4 to 8 bit map table end (%d) > len(i) (%d)", offset+16, len(i))
+				return
+			}
+			map4To8 = append([]uint8{}, i[offset:offset+16]...)
+			offset += 16
+		case dvbSubtitlePixelDataTypeEndOfObjectLine:
+			lines = append(lines, line)
+			line = nil
+		default:
+			err = fmt.Errorf("astits: invalid pixel data type 0x%x", t)
+			return
+		}
+	}
+
+	// Last line is not terminated
+	if len(line) > 0 {
+		lines = append(lines, line)
+	}
+	return
+}
+
+// decodeDVBSubtitle2BitCodeString decodes a 2-bit/pixel code string
+func decodeDVBSubtitle2BitCodeString(r *bitReader) (codes []uint8, err error) {
+	for {
+		// Pixel code
+		var c uint32
+		if c, err = r.read(2); err != nil {
+			return
+		} else if c != 0 {
+			codes = append(codes, uint8(c))
+			continue
+		}
+
+		// Switch 1
+		var s uint32
+		if s, err = r.read(1); err != nil {
+			return
+		} else if s == 1 {
+			var l uint32
+			if l, c, err = readDVBSubtitleRun(r, 3, 2); err != nil {
+				return
+			}
+			codes = appendDVBSubtitleRun(codes, int(l)+3, uint8(c))
+			continue
+		}
+
+		// Switch 2
+		if s, err = r.read(1); err != nil {
+			return
+		} else if s == 1 {
+			codes = append(codes, 0)
+			continue
+		}
+
+		// Switch 3
+		if s, err = r.read(2); err != nil {
+			return
+		}
+		switch s {
+		case 0:
+			r.align()
+			return
+		case 1:
+			codes = append(codes, 0, 0)
+		case 2:
+			var l uint32
+			if l, c, err = readDVBSubtitleRun(r, 4, 2); err != nil {
+				return
+			}
+			codes = appendDVBSubtitleRun(codes, int(l)+12, uint8(c))
+		default:
+			var l uint32
+			if l, c, err = readDVBSubtitleRun(r, 8, 2); err != nil {
+				return
+			}
+			codes = appendDVBSubtitleRun(codes, int(l)+29, uint8(c))
+		}
+	}
+}
+
+// decodeDVBSubtitle4BitCodeString decodes a 4-bit/pixel code string
+func decodeDVBSubtitle4BitCodeString(r *bitReader) (codes []uint8, err error) {
+	for {
+		// Pixel code
+		var c uint32
+		if c, err = r.read(4); err != nil {
+			return
+		} else if c != 0 {
+			codes = append(codes, uint8(c))
+			continue
+		}
+
+		// Switch 1
+		var s uint32
+		if s, err = r.read(1); err != nil {
+			return
+		} else if s == 0 {
+			var l uint32
+			if l, err = r.read(3); err != nil {
+				return
+			} else if l == 0 {
+				r.align()
+				return
+			}
+			codes = appendDVBSubtitleRun(codes, int(l)+2, 0)
+			continue
+		}
+
+		// Switch 2
+		if s, err = r.read(1); err != nil {
+			return
+		} else if s == 0 {
+			var l uint32
+			if l, c, err = readDVBSubtitleRun(r, 2, 4); err != nil {
+				return
+			}
+			codes = appendDVBSubtitleRun(codes, int(l)+4, uint8(c))
+			continue
+		}
+
+		// Switch 3
+		if s, err = r.read(2); err != nil {
+			return
+		}
+		switch s {
+		case 0:
+			codes = append(codes, 0)
+		case 1:
+			codes = append(codes, 0, 0)
+		case 2:
+			var l uint32
+			if l, c, err = readDVBSubtitleRun(r, 4, 4); err != nil {
+				return
+			}
+			codes = appendDVBSubtitleRun(codes, int(l)+9, uint8(c))
+		default:
+			var l uint32
+			if l, c, err = readDVBSubtitleRun(r, 8, 4); err != nil {
+				return
+			}
+			codes = appendDVBSubtitleRun(codes, int(l)+25, uint8(c))
+		}
+	}
+}
+
+// decodeDVBSubtitle8BitCodeString decodes an 8-bit/pixel code string
+func decodeDVBSubtitle8BitCodeString(r *bitReader) (codes []uint8, err error) {
+	for {
+		// Pixel code
+		var c uint32
+		if c, err = r.read(8); err != nil {
+			return
+		} else if c != 0 {
+			codes = append(codes, uint8(c))
+			continue
+		}
+
+		// Switch 1
+		var s, l uint32
+		if s, err = r.read(1); err != nil {
+			return
+		} else if s == 0 {
+			if l, err = r.read(7); err != nil {
+				return
+			} else if l == 0 {
+				return
+			}
+			codes = appendDVBSubtitleRun(codes, int(l), 0)
+			continue
+		}
+		if l, c, err = readDVBSubtitleRun(r, 7, 8); err != nil {
+			return
+		}
+		codes = appendDVBSubtitleRun(codes, int(l), uint8(c))
+	}
+}
+
+// readDVBSubtitleRun reads a run length followed by a pixel code
+func readDVBSubtitleRun(r *bitReader, lengthBits, codeBits int) (l, c uint32, err error) {
+	if l, err = r.read(lengthBits); err != nil {
+		return
+	}
+	c, err = r.read(codeBits)
+	return
+}
+
+// appendDVBSubtitleRun appends a run of pixels of the same code
+func appendDVBSubtitleRun(codes []uint8, n int, c uint8) []uint8 {
+	for idx := 0; idx < n; idx++ {
+		codes = append(codes, c)
+	}
+	return codes
+}
@@ -0,0 +1,134 @@
+package astits
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func dvbSubtitleSegmentBytes(t uint8, pageID uint16, data []byte) []byte {
+	return append([]byte{dvbSubtitleSyncByte, t, uint8(pageID >> 8), uint8(pageID), uint8(len(data) >> 8), uint8(len(data))}, data...)
+}
+
+var dvbSubtitlePixelData = []byte{
+	dvbSubtitlePixelDataType2BitCodeString, 0x60, 0x00, dvbSubtitlePixelDataTypeEndOfObjectLine,
+	dvbSubtitlePixelDataType4BitCodeString, 0x50, 0x30, 0x00, dvbSubtitlePixelDataTypeEndOfObjectLine,
+	dvbSubtitlePixelDataType8BitCodeString, 0xaa, 0x00, 0x83, 0x0b, 0x00, 0x00, dvbSubtitlePixelDataTypeEndOfObjectLine,
+}
+
+func TestParseDVBSubtitle(t *testing.T) {
+	// Init
+	var b = []byte{dvbSubtitleDataIdentifier, 0x0}
+	b = append(b, dvbSubtitleSegmentBytes(DVBSubtitleSegmentTypeDisplayDefinition, 1, []byte{0x18, 0x02, 0xcf, 0x02, 0x3f, 0x0, 0x1, 0x0, 0x2, 0x0, 0x3, 0x0, 0x4})...)
+	b = append(b, dvbSubtitleSegmentBytes(DVBSubtitleSegmentTypePageComposition, 1, []byte{0xf, 0x24, 0x1, 0xff, 0x0, 0x10, 0x1, 0x20})...)
+	b = append(b, dvbSubtitleSegmentBytes(DVBSubtitleSegmentTypeRegionComposition, 1, []byte{0x1, 0x38, 0x1, 0x0, 0x0, 0x20, 0x48, 0x2, 0x3, 0x48, 0x0, 0x5, 0x40, 0x10, 0x0, 0x20, 0x1, 0x2})...)
+	b = append(b, dvbSubtitleSegmentBytes(DVBSubtitleSegmentTypeCLUTDefinition, 1, []byte{0x2, 0x50, 0x1, 0xe1, 0x10, 0x20, 0x30, 0x40, 0x2, 0x40, 0xff, 0xff})...)
+	b = append(b, dvbSubtitleSegmentBytes(DVBSubtitleSegmentTypeObjectData, 1, append([]byte{0x0, 0x5, 0x60, 0x0, uint8(len(dvbSubtitlePixelData)), 0x0, 0x0}, dvbSubtitlePixelData...))...)
+	b = append(b, dvbSubtitleSegmentBytes(DVBSubtitleSegmentTypeObjectData, 1, []byte{0x0, 0x6, 0x64, 0x2, 0x0, 0x41, 0x0, 0x42})...)
+	b = append(b, dvbSubtitleSegmentBytes(DVBSubtitleSegmentTypeEndOfDisplaySet, 1, nil)...)
+	b = append(b, 0xff)
+
+	// Parse
+	d, err := ParseDVBSubtitle(b)
+	assert.NoError(t, err)
+	assert.Len(t, d.Segments, 7)
+	assert.Equal(t, &DVBSubtitleDisplayDefinition{
+		DisplayHeight:                   576,
+		DisplayWidth:                    720,
+		HasWindow:                       true,
+		VersionNumber:                   1,
+		WindowHorizontalPositionMaximum: 2,
+		WindowHorizontalPositionMinimum: 1,
+		WindowVerticalPositionMaximum:   4,
+		WindowVerticalPositionMinimum:   3,
+	}, d.Segments[0].DisplayDefinition)
+	assert.Equal(t, uint16(1), d.Segments[1].PageID)
+	assert.Equal(t, &DVBSubtitlePageComposition{
+		Regions:       []*DVBSubtitlePageRegion{{HorizontalAddress: 0x10, ID: 1, VerticalAddress: 0x120}},
+		State:         DVBSubtitlePageStateAcquisitionPoint,
+		TimeOut:       15 * time.Second,
+		VersionNumber: 2,
+	}, d.Segments[1].PageComposition)
+	assert.Equal(t, &DVBSubtitleRegionComposition{
+		CLUTID:               2,
+		Depth:                4,
+		FillFlag:             true,
+		Height:               0x20,
+		ID:                   1,
+		LevelOfCompatibility: 4,
+		Objects: []*DVBSubtitleRegionObject{{
+			BackgroundPixelCode: 2,
+			ForegroundPixelCode: 1,
+			HorizontalPosition:  0x10,
+			ID:                  5,
+			ProviderFlag:        0,
+			Type:                DVBSubtitleObjectTypeBasicCharacter,
+			VerticalPosition:    0x20,
+		}},
+		PixelCode2Bit: 2,
+		PixelCode4Bit: 4,
+		PixelCode8Bit: 3,
+		VersionNumber: 3,
+		Width:         0x100,
+	}, d.Segments[2].RegionComposition)
+	assert.Equal(t, &DVBSubtitleCLUTDefinition{
+		Entries: []*DVBSubtitleCLUTEntry{
+			{Cb: 0x30, Cr: 0x20, FullRange: true, ID: 1, In2BitCLUT: true, In4BitCLUT: true, In8BitCLUT: true, T: 0x40, Y: 0x10},
+			{Cb: 0xf0, Cr: 0xf0, ID: 2, In4BitCLUT: true, T: 0xc0, Y: 0xfc},
+		},
+		ID:            2,
+		VersionNumber: 5,
+	}, d.Segments[3].CLUTDefinition)
+	assert.Equal(t, &DVBSubtitleObjectData{
+		BottomFieldDataBlock: []byte{},
+		CodingMethod:         DVBSubtitleObjectCodingMethodPixels,
+		ID:                   5,
+		TopFieldDataBlock:    dvbSubtitlePixelData,
+		VersionNumber:        6,
+	}, d.Segments[4].ObjectData)
+	assert.Equal(t, &DVBSubtitleObjectData{
+		Characters:             []uint16{0x41, 0x42},
+		CodingMethod:           DVBSubtitleObjectCodingMethodStringOfCharacters,
+		ID:                     6,
+		NonModifyingColourFlag: false,
+		VersionNumber:          6,
+	}, d.Segments[5].ObjectData)
+	assert.Equal(t, uint8(DVBSubtitleSegmentTypeEndOfDisplaySet), d.Segments[6].Type)
+
+	// Errors
+	_, err = ParseDVBSubtitle([]byte{0x21, 0x0})
+	assert.Error(t, err)
+	_, err = ParseDVBSubtitle(append([]byte{dvbSubtitleDataIdentifier, 0x0}, dvbSubtitleSegmentBytes(DVBSubtitleSegmentTypePageComposition, 1, []byte{0xf})...))
+	assert.Error(t, err)
+	_, err = ParseDVBSubtitle([]byte{dvbSubtitleDataIdentifier, 0x0, dvbSubtitleSyncByte, DVBSubtitleSegmentTypePageComposition, 0x0, 0x1, 0x0, 0x10})
+	assert.Error(t, err)
+}
+
+func TestDVBSubtitleObjectDataDecodePixels(t *testing.T) {
+	// 4 bits region
+	d := &DVBSubtitleObjectData{TopFieldDataBlock: dvbSubtitlePixelData}
+	ls, err := d.DecodePixels(4)
+	assert.NoError(t, err)
+	var l1, l2, l3 = []uint8{0x7, 0x8}, []uint8{0x5, 0x0, 0x0, 0x0, 0x0, 0x0}, []uint8{0xa, 0x0, 0x0, 0x0}
+	assert.Equal(t, [][]uint8{l1, l1, l2, l2, l3, l3}, ls)
+
+	// 8 bits region with a bottom field and a map table
+	d = &DVBSubtitleObjectData{
+		BottomFieldDataBlock: []byte{dvbSubtitlePixelDataType2To8BitMapTable, 0x1, 0x2, 0x3, 0x4, dvbSubtitlePixelDataType2BitCodeString, 0x60, 0x00, dvbSubtitlePixelDataTypeEndOfObjectLine},
+		TopFieldDataBlock:    dvbSubtitlePixelData[:4],
+	}
+	ls, err = d.DecodePixels(8)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]uint8{{0x77, 0x88}, {0x2, 0x3}}, ls)
+
+	// Errors
+	_, err = d.DecodePixels(3)
+	assert.Error(t, err)
+	_, err = (&DVBSubtitleObjectData{TopFieldDataBlock: []byte{0x13}}).DecodePixels(4)
+	assert.Error(t, err)
+	_, err = (&DVBSubtitleObjectData{TopFieldDataBlock: []byte{dvbSubtitlePixelDataType4BitCodeString, 0x50}}).DecodePixels(4)
+	assert.Error(t, err)
+	_, err = (&DVBSubtitleObjectData{CodingMethod: DVBSubtitleObjectCodingMethodStringOfCharacters}).DecodePixels(4)
+	assert.Error(t, err)
+}
@@ -36,3 +36,22 @@ func TestParseDVBDurationSeconds(t *testing.T) {
 	assert.Equal(t, dvbDurationSeconds, d)
 	assert.Equal(t, 3, offset)
 }
+
+func TestWriteDVBTime(t *testing.T) {
+	assert.Equal(t, dvbTimeBytes, writeDVBTime(dvbTime))
+}
+
+func TestWriteDVBDurationMinutes(t *testing.T) {
+	assert.Equal(t, dvbDurationMinutesBytes, writeDVBDurationMinutes(dvbDurationMinutes))
+}
+
+func TestWriteDVBDurationSeconds(t *testing.T) {
+	assert.Equal(t, dvbDurationSecondsBytes, writeDVBDurationSeconds(dvbDurationSeconds))
+}
+
+func TestDVBBCD(t *testing.T) {
+	assert.Equal(t, uint64(1175725), parseDVBBCD([]byte{0x01, 0x17, 0x57, 0x25}, 8))
+	assert.Equal(t, uint64(275000), parseDVBBCD([]byte{0x02, 0x75, 0x00, 0x03}, 7))
+	assert.Equal(t, []byte{0x01, 0x17, 0x57, 0x25}, writeDVBBCD(1175725, 8))
+	assert.Equal(t, []byte{0x02, 0x75, 0x00, 0x00}, writeDVBBCD(275000, 7))
+}
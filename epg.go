@@ -0,0 +1,242 @@
+package astits
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// EPG represents an electronic program guide built out of the events of EIT data, present/following and schedule
+// tables alike, that can be queried service by service
+// Events are identified by their service and their event ID, therefore an event received again, for instance once
+// updated or in another table, replaces the previous one. Sections are merged into the schedule segment they belong to:
+// when a section is received again, the events it no longer carries are removed from the guide, as well as the events
+// of the sections that its segment no longer has.
+// It can be queried while EIT data is being added
+type EPG struct {
+	events   map[EPGService]map[uint16]*epgEvent // Indexed by service and event ID
+	m        *sync.Mutex
+	sections map[epgSectionKey]*epgSection
+}
+
+// EPGService represents the service an event belongs to
+type EPGService struct {
+	OriginalNetworkID uint16
+	ServiceID         uint16
+	TransportStreamID uint16
+}
+
+// EPGEvent represents an event of the guide
+// Texts are taken from the first short event descriptor and from the extended event descriptors of the same language,
+// decoded if a text decoder was provided
+type EPGEvent struct {
+	Descriptors         []*Descriptor
+	Duration            time.Duration
+	EventID             uint16
+	ExtendedDescription string // Text of the extended event descriptors reassembled in the order of their descriptor number
+	ExtendedItems       []*DescriptorExtendedEventItem
+	Genres              []ContentSubgenre // Subgenres of the content descriptors, which hold their genre
+	Language            string
+	MinimumAges         map[string]int // Minimum ages of the parental rating descriptors indexed by country code
+	RunningStatus       uint8
+	ShortDescription    string
+	StartTime           time.Time
+	Title               string
+}
+
+// End returns the time at which the event ends
+func (e *EPGEvent) End() time.Time {
+	return e.StartTime.Add(e.Duration)
+}
+
+// epgEvent represents an event of the guide along with the section that last carried it
+type epgEvent struct {
+	e       *EPGEvent
+	section epgSectionKey
+}
+
+// epgSectionKey represents the key of an EIT section
+type epgSectionKey struct {
+	sectionNumber uint8
+	service       EPGService
+	tableID       uint8
+}
+
+// epgSection represents the last EIT section received
+type epgSection struct {
+	eventIDs      []uint16
+	versionNumber uint8
+}
+
+// NewEPG creates a new EPG
+func NewEPG() *EPG {
+	return &EPG{
+		events:   make(map[EPGService]map[uint16]*epgEvent),
+		m:        &sync.Mutex{},
+		sections: make(map[epgSectionKey]*epgSection),
+	}
+}
+
+// Add adds the events of an EIT data to the guide
+func (g *EPG) Add(d *EITData) {
+	// Lock
+	g.m.Lock()
+	defer g.m.Unlock()
+
+	// Get events
+	var s = EPGService{OriginalNetworkID: d.OriginalNetworkID, ServiceID: d.ServiceID, TransportStreamID: d.TransportStreamID}
+	es, ok := g.events[s]
+	if !ok {
+		es = make(map[uint16]*epgEvent)
+		g.events[s] = es
+	}
+
+	// Sections of the segment beyond its last section are no longer part of the new version
+	// Schedule segments are made of 8 sections whereas present/following tables are made of a single one
+	var k = epgSectionKey{sectionNumber: d.SectionNumber, service: s, tableID: d.TableID}
+	if v, ok := g.sections[k]; !ok || v.versionNumber != d.VersionNumber {
+		var first = d.SectionNumber - d.SectionNumber%eitScheduleSegmentSections
+		for n := int(d.SegmentLastSectionNumber) + 1; n < int(first)+eitScheduleSegmentSections; n++ {
+			g.removeSection(epgSectionKey{sectionNumber: uint8(n), service: s, tableID: d.TableID})
+		}
+	}
+
+	// Remove the events of the previous section
+	g.removeSection(k)
+
+	// Add events
+	var v = &epgSection{versionNumber: d.VersionNumber}
+	for _, e := range d.Events {
+		es[e.EventID] = &epgEvent{e: newEPGEvent(e), section: k}
+		v.eventIDs = append(v.eventIDs, e.EventID)
+	}
+	g.sections[k] = v
+}
+
+// removeSection removes a section and the events it was the last one to carry
+func (g *EPG) removeSection(k epgSectionKey) {
+	v, ok := g.sections[k]
+	if !ok {
+		return
+	}
+	for _, id := range v.eventIDs {
+		if e, ok := g.events[k.service][id]; ok && e.section == k {
+			delete(g.events[k.service], id)
+		}
+	}
+	delete(g.sections, k)
+}
+
+// newEPGEvent creates a new EPG event based on an EIT event
+func newEPGEvent(e *EITDataEvent) (o *EPGEvent) {
+	// Init
+	o = &EPGEvent{
+		Descriptors:   e.Descriptors,
+		Duration:      e.Duration,
+		EventID:       e.EventID,
+		RunningStatus: e.RunningStatus,
+		StartTime:     e.StartTime,
+	}
+
+	// Short event
+	for _, d := range e.Descriptors {
+		if d.ShortEvent != nil {
+			o.Language = string(d.ShortEvent.Language)
+			o.ShortDescription = epgText(d.ShortEvent.DecodedText, d.ShortEvent.Text)
+			o.Title = epgText(d.ShortEvent.DecodedEventName, d.ShortEvent.EventName)
+			break
+		}
+	}
+
+	// Genres
+	for _, d := range e.Descriptors {
+		if d.Content != nil {
+			for _, itm := range d.Content.Items {
+				o.Genres = append(o.Genres, itm.Subgenre())
+			}
+		}
+	}
+
+	// Parental ratings
+	for _, d := range e.Descriptors {
+		if d.ParentalRating != nil {
+			for _, itm := range d.ParentalRating.Items {
+				if o.MinimumAges == nil {
+					o.MinimumAges = make(map[string]int)
+				}
+				o.MinimumAges[string(itm.CountryCode)] = itm.MinimumAge()
+			}
+		}
+	}
+
+	// Extended events
+	for _, x := range e.ExtendedEvents() {
+		if o.Language == "" || x.Language == o.Language {
+			o.ExtendedDescription = epgText(x.DecodedText, x.Text)
+			o.ExtendedItems = x.Items
+			break
+		}
+	}
+	return
+}
+
+// epgText returns the decoded text if any, or the raw text otherwise
+func epgText(decoded string, raw []byte) string {
+	if decoded != "" {
+		return decoded
+	}
+	return string(raw)
+}
+
+// Services returns the services having events, sorted by original network ID, transport stream ID and service ID
+func (g *EPG) Services() (ss []EPGService) {
+	// Lock
+	g.m.Lock()
+	defer g.m.Unlock()
+
+	// Loop through services
+	for s, es := range g.events {
+		if len(es) > 0 {
+			ss = append(ss, s)
+		}
+	}
+	sort.Slice(ss, func(i, j int) bool {
+		if ss[i].OriginalNetworkID != ss[j].OriginalNetworkID {
+			return ss[i].OriginalNetworkID < ss[j].OriginalNetworkID
+		} else if ss[i].TransportStreamID != ss[j].TransportStreamID {
+			return ss[i].TransportStreamID < ss[j].TransportStreamID
+		}
+		return ss[i].ServiceID < ss[j].ServiceID
+	})
+	return
+}
+
+// Events returns the events of a service sorted by start time
+func (g *EPG) Events(s EPGService) (es []*EPGEvent) {
+	// Lock
+	g.m.Lock()
+	defer g.m.Unlock()
+
+	// Loop through events
+	for _, e := range g.events[s] {
+		var c = *e.e
+		es = append(es, &c)
+	}
+	sort.Slice(es, func(i, j int) bool {
+		if !es[i].StartTime.Equal(es[j].StartTime) {
+			return es[i].StartTime.Before(es[j].StartTime)
+		}
+		return es[i].EventID < es[j].EventID
+	})
+	return
+}
+
+// EventAt returns the event of a service airing at a given time, or nil if there is none
+func (g *EPG) EventAt(s EPGService, t time.Time) *EPGEvent {
+	for _, e := range g.Events(s) {
+		if !t.Before(e.StartTime) && t.Before(e.End()) {
+			return e
+		}
+	}
+	return nil
+}
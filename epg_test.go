@@ -0,0 +1,74 @@
+package astits
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEPG(t *testing.T) {
+	// Init
+	g := NewEPG()
+	var s = EPGService{OriginalNetworkID: 1, ServiceID: 3, TransportStreamID: 2}
+	event := func(id uint16, start time.Duration, ds ...*Descriptor) *EITDataEvent {
+		return &EITDataEvent{Descriptors: ds, Duration: time.Hour, EventID: id, StartTime: dvbTime.Add(start)}
+	}
+	section := func(sectionNumber, segmentLastSectionNumber, versionNumber uint8, es ...*EITDataEvent) *EITData {
+		return &EITData{
+			Events:                   es,
+			OriginalNetworkID:        s.OriginalNetworkID,
+			SectionNumber:            sectionNumber,
+			SegmentLastSectionNumber: segmentLastSectionNumber,
+			ServiceID:                s.ServiceID,
+			TableID:                  eitTableIDScheduleActual,
+			TransportStreamID:        s.TransportStreamID,
+			VersionNumber:            versionNumber,
+		}
+	}
+	ids := func() (ids []uint16) {
+		for _, e := range g.Events(s) {
+			ids = append(ids, e.EventID)
+		}
+		return
+	}
+
+	// Texts
+	g.Add(section(0, 1, 0, event(1, time.Hour, NewDescriptorShortEvent("eng", "title", "short"),
+		&Descriptor{Content: &DescriptorContent{Items: []*DescriptorContentItem{{ContentNibbleLevel1: 0x4, ContentNibbleLevel2: 0x3}}}},
+		&Descriptor{ParentalRating: &DescriptorParentalRating{Items: []*DescriptorParentalRatingItem{{CountryCode: []byte("GBR"), Rating: 0x9}}}},
+		&Descriptor{ExtendedEvent: &DescriptorExtendedEvent{ISO639LanguageCode: []byte("eng"), Number: 1, Text: []byte(" world")}},
+		&Descriptor{ExtendedEvent: &DescriptorExtendedEvent{ISO639LanguageCode: []byte("fre"), Number: 0, Text: []byte("bonjour")}},
+		&Descriptor{ExtendedEvent: &DescriptorExtendedEvent{ISO639LanguageCode: []byte("eng"), Number: 0, Text: []byte("hello")}},
+	)))
+	g.Add(section(1, 1, 0, event(2, 0), event(3, 2*time.Hour)))
+	assert.Equal(t, []EPGService{s}, g.Services())
+	assert.Equal(t, []uint16{2, 1, 3}, ids())
+	e := g.EventAt(s, dvbTime.Add(90*time.Minute))
+	assert.Equal(t, uint16(1), e.EventID)
+	assert.Equal(t, "eng", e.Language)
+	assert.Equal(t, "title", e.Title)
+	assert.Equal(t, "short", e.ShortDescription)
+	assert.Equal(t, "hello world", e.ExtendedDescription)
+	assert.Equal(t, []ContentSubgenre{ContentSubgenreSportsFootballSoccer}, e.Genres)
+	assert.Equal(t, map[string]int{"GBR": 12}, e.MinimumAges)
+	assert.Nil(t, g.EventAt(s, dvbTime.Add(3*time.Hour)))
+
+	// Events no longer carried by a section are removed
+	g.Add(section(1, 1, 0, event(2, 0)))
+	assert.Equal(t, []uint16{2, 1}, ids())
+
+	// Events moved to another section are kept
+	g.Add(section(0, 1, 0, event(1, time.Hour), event(2, 0)))
+	g.Add(section(1, 1, 0))
+	assert.Equal(t, []uint16{2, 1}, ids())
+
+	// Sections beyond the last section of the segment of a new version are removed
+	g.Add(section(1, 1, 0, event(3, 2*time.Hour)))
+	g.Add(section(8, 8, 0, event(4, 24*time.Hour)))
+	assert.Equal(t, []uint16{2, 1, 3, 4}, ids())
+	g.Add(section(0, 0, 1, event(1, time.Hour)))
+	assert.Equal(t, []uint16{1, 4}, ids())
+	g.Add(section(8, 8, 1))
+	assert.Equal(t, []uint16{1}, ids())
+}
@@ -0,0 +1,241 @@
+package astits
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/pkg/errors"
+)
+
+// ID3 tag flags
+// Page: 1 | Chapter: 3.1 | Link: https://id3.org/id3v2.4.0-structure
+const (
+	ID3TagFlagExperimental      = 0x20
+	ID3TagFlagExtendedHeader    = 0x40
+	ID3TagFlagFooter            = 0x10
+	ID3TagFlagUnsynchronisation = 0x80
+)
+
+// ID3 text encodings
+// Page: 1 | Chapter: 4 | Link: https://id3.org/id3v2.4.0-structure
+const (
+	ID3TextEncodingISO88591 = 0
+	ID3TextEncodingUTF16    = 1 // With BOM
+	ID3TextEncodingUTF16BE  = 2
+	ID3TextEncodingUTF8     = 3
+)
+
+// ID3 constants
+const (
+	id3FrameFlagDataLengthIndicator = 0x1
+	id3FrameFlagUnsynchronisation   = 0x2
+	id3FrameHeaderLength            = 10
+	id3HeaderLength                 = 10
+)
+
+// ID3Data represents the ID3 tags carried by a PES of a timed metadata stream, as used by Apple HTTP Live Streaming
+// Link: https://developer.apple.com/library/archive/documentation/AudioVideo/Conceptual/HTTP_Live_Streaming_Metadata_Spec/
+type ID3Data struct {
+	PTS  *ClockReference // PTS of the PES the tags were carried by. The tags apply to the media presented at that time
+	Tags []*ID3Tag
+}
+
+// ID3Tag represents an ID3v2 tag
+// Page: 1 | Chapter: 3 | Link: https://id3.org/id3v2.4.0-structure
+type ID3Tag struct {
+	Flags    uint8
+	Frames   []*ID3Frame // Only ID3v2.3 and ID3v2.4 frames are parsed
+	Revision uint8
+	Version  uint8 // Major version, i.e. 3 for ID3v2.3 and 4 for ID3v2.4
+}
+
+// ID3Frame represents an ID3v2 frame
+// Unsynchronisation is removed from the data, which is left compressed or encrypted if the frame flags say so
+// Page: 1 | Chapter: 4 | Link: https://id3.org/id3v2.4.0-structure
+type ID3Frame struct {
+	Data  []byte
+	Flags uint16
+	ID    string // Examples: "PRIV", "TXXX", "TIT2"
+}
+
+// Text decodes the content of a text information frame, i.e. a frame whose ID starts with "T"
+// Strings separated by null characters, such as the description and the value of a TXXX frame, are joined with "\x00"
+func (f *ID3Frame) Text() (s string, err error) {
+	// Check length
+	if len(f.Data) == 0 {
+		err = errors.New("astits: ID3 text frame has no text encoding")
+		return
+	}
+
+	// Switch on text encoding
+	var b = f.Data[1:]
+	switch f.Data[0] {
+	case ID3TextEncodingISO88591:
+		var rs = make([]rune, len(b))
+		for idx, c := range b {
+			rs[idx] = rune(c)
+		}
+		s = string(rs)
+	case ID3TextEncodingUTF16, ID3TextEncodingUTF16BE:
+		// Byte order marks may start each string
+		var us []uint16
+		var bigEndian = true
+		for idx := 0; idx+1 < len(b); idx += 2 {
+			var u uint16
+			if bigEndian {
+				u = uint16(b[idx])<<8 | uint16(b[idx+1])
+			} else {
+				u = uint16(b[idx+1])<<8 | uint16(b[idx])
+			}
+			switch u {
+			case 0xfeff:
+				continue
+			case 0xfffe:
+				bigEndian = !bigEndian
+				continue
+			case 0:
+				bigEndian = true
+			}
+			us = append(us, u)
+		}
+		s = string(utf16.Decode(us))
+	case ID3TextEncodingUTF8:
+		s = string(b)
+	default:
+		err = fmt.Errorf("astits: unknown ID3 text encoding 0x%x", f.Data[0])
+		return
+	}
+
+	// Remove terminating null characters
+	s = strings.TrimRight(s, "\x00")
+	return
+}
+
+// ParseID3Tags parses the ID3v2 tags concatenated in a payload
+func ParseID3Tags(i []byte) (ts []*ID3Tag, err error) {
+	var offset int
+	for offset < len(i) {
+		var t *ID3Tag
+		if t, err = parseID3Tag(i, &offset); err != nil {
+			err = errors.Wrapf(err, "astits: parsing ID3 tag #%d failed", len(ts)+1)
+			return
+		}
+		ts = append(ts, t)
+	}
+	return
+}
+
+// parseID3Tag parses an ID3v2 tag
+func parseID3Tag(i []byte, offset *int) (t *ID3Tag, err error) {
+	// Check header
+	if *offset+id3HeaderLength > len(i) {
+		err = fmt.Errorf("astits: ID3 header end (%d) > len(i) (%d)", *offset+id3HeaderLength, len(i))
+		return
+	} else if string(i[*offset:*offset+3]) != "ID3" {
+		err = fmt.Errorf("astits: ID3 tag starts with 0x%x instead of ID3", i[*offset:*offset+3])
+		return
+	}
+
+	// Header
+	t = &ID3Tag{
+		Flags:    i[*offset+5],
+		Revision: i[*offset+4],
+		Version:  i[*offset+3],
+	}
+	var size = int(parseID3SyncsafeInteger(i[*offset+6 : *offset+10]))
+	*offset += id3HeaderLength
+
+	// Get frames
+	if *offset+size > len(i) {
+		err = fmt.Errorf("astits: ID3 tag end (%d) > len(i) (%d)", *offset+size, len(i))
+		return
+	}
+	var b = i[*offset : *offset+size]
+	*offset += size
+	if t.Flags&ID3TagFlagFooter > 0 {
+		*offset += id3HeaderLength
+	}
+
+	// Only ID3v2.3 and ID3v2.4 frames are parsed
+	if t.Version != 3 && t.Version != 4 {
+		return
+	}
+
+	// Unsynchronisation applies to the whole tag in ID3v2.3
+	if t.Version == 3 && t.Flags&ID3TagFlagUnsynchronisation > 0 {
+		b = removeID3Unsynchronisation(b)
+	}
+
+	// Skip extended header
+	var o int
+	if t.Flags&ID3TagFlagExtendedHeader > 0 {
+		if len(b) < 4 {
+			err = fmt.Errorf("astits: ID3 extended header end (4) > len(b) (%d)", len(b))
+			return
+		}
+		if t.Version == 3 {
+			o = 4 + int(uint32(b[0])<<24|uint32(b[1])<<16|uint32(b[2])<<8|uint32(b[3]))
+		} else {
+			o = int(parseID3SyncsafeInteger(b[:4]))
+		}
+	}
+
+	// Loop through frames
+	for o+id3FrameHeaderLength <= len(b) {
+		// Padding
+		if b[o] == 0 {
+			break
+		}
+
+		// Header
+		var f = &ID3Frame{
+			Flags: uint16(b[o+8])<<8 | uint16(b[o+9]),
+			ID:    string(b[o : o+4]),
+		}
+		var frameSize int
+		if t.Version == 3 {
+			frameSize = int(uint32(b[o+4])<<24 | uint32(b[o+5])<<16 | uint32(b[o+6])<<8 | uint32(b[o+7]))
+		} else {
+			frameSize = int(parseID3SyncsafeInteger(b[o+4 : o+8]))
+		}
+		o += id3FrameHeaderLength
+
+		// Data
+		if o+frameSize > len(b) {
+			err = fmt.Errorf("astits: ID3 frame %s end (%d) > len(b) (%d)", f.ID, o+frameSize, len(b))
+			return
+		}
+		f.Data = b[o : o+frameSize]
+		o += frameSize
+
+		// ID3v2.4 frame flags
+		if t.Version == 4 {
+			if f.Flags&id3FrameFlagUnsynchronisation > 0 {
+				f.Data = removeID3Unsynchronisation(f.Data)
+			}
+			if f.Flags&id3FrameFlagDataLengthIndicator > 0 && len(f.Data) >= 4 {
+				f.Data = f.Data[4:]
+			}
+		}
+		t.Frames = append(t.Frames, f)
+	}
+	return
+}
+
+// parseID3SyncsafeInteger parses a 28 bits integer coded on 4 bytes whose most significant bits are unset
+func parseID3SyncsafeInteger(i []byte) uint32 {
+	return uint32(i[0]&0x7f)<<21 | uint32(i[1]&0x7f)<<14 | uint32(i[2]&0x7f)<<7 | uint32(i[3]&0x7f)
+}
+
+// removeID3Unsynchronisation removes the 0x00 bytes following 0xff bytes
+func removeID3Unsynchronisation(i []byte) (o []byte) {
+	o = make([]byte, 0, len(i))
+	for idx, b := range i {
+		if b == 0 && idx > 0 && i[idx-1] == 0xff {
+			continue
+		}
+		o = append(o, b)
+	}
+	return
+}
@@ -0,0 +1,122 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/asticode/go-astitools/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+// id3TagBytes builds an ID3v2.4 tag with a TXXX frame and a PRIV frame
+func id3TagBytes() []byte {
+	w := astibinary.New()
+	w.Write([]byte("ID3"))                          // File identifier
+	w.Write([]byte{0x4, 0x0})                       // Version and revision
+	w.Write(uint8(0))                               // Flags
+	w.Write([]byte{0x0, 0x0, 0x0, 0x27})            // Size
+	w.Write([]byte("TXXX"))                         // Frame ID
+	w.Write([]byte{0x0, 0x0, 0x0, 0xb})             // Frame size
+	w.Write(uint16(0))                              // Frame flags
+	w.Write([]byte{ID3TextEncodingUTF8})            // Text encoding
+	w.Write([]byte("key\x00value\x00"))             // Description and value
+	w.Write([]byte("PRIV"))                         // Frame ID
+	w.Write([]byte{0x0, 0x0, 0x0, 0x6})             // Frame size
+	w.Write(uint16(id3FrameFlagUnsynchronisation))  // Frame flags
+	w.Write([]byte{'o', 0x0, 0xff, 0x0, 0xe0, 0x1}) // Owner and data
+	w.Write([]byte{0x0, 0x0})                       // Padding
+	return w.Bytes()
+}
+
+var id3Tags = []*ID3Tag{{
+	Frames: []*ID3Frame{
+		{Data: []byte("\x03key\x00value\x00"), ID: "TXXX"},
+		{Data: []byte{'o', 0x0, 0xff, 0xe0, 0x1}, Flags: id3FrameFlagUnsynchronisation, ID: "PRIV"},
+	},
+	Version: 4,
+}}
+
+func TestParseID3Tags(t *testing.T) {
+	// Concatenated tags
+	ts, err := ParseID3Tags(append(id3TagBytes(), id3TagBytes()...))
+	assert.NoError(t, err)
+	assert.Equal(t, append(id3Tags, id3Tags...), ts)
+
+	// Truncated tag
+	_, err = ParseID3Tags(id3TagBytes()[:20])
+	assert.Error(t, err)
+
+	// Invalid identifier
+	_, err = ParseID3Tags([]byte("TAG0000000"))
+	assert.Error(t, err)
+}
+
+func TestID3FrameText(t *testing.T) {
+	for _, v := range []struct {
+		f *ID3Frame
+		s string
+	}{
+		{f: &ID3Frame{Data: []byte("\x00caf\xe9\x00")}, s: "café"},
+		{f: &ID3Frame{Data: []byte("\x01\xff\xfeh\x00i\x00\x00\x00")}, s: "hi"},
+		{f: &ID3Frame{Data: []byte("\x02\x00h\x00i")}, s: "hi"},
+		{f: &ID3Frame{Data: []byte("\x03key\x00value\x00")}, s: "key\x00value"},
+	} {
+		s, err := v.f.Text()
+		assert.NoError(t, err)
+		assert.Equal(t, v.s, s)
+	}
+	_, err := (&ID3Frame{Data: []byte{0x4}}).Text()
+	assert.Error(t, err)
+}
+
+func TestDemuxerID3(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	pm, _ := (&PMTData{ElementaryStreams: []*PMTElementaryStream{{
+		ElementaryPID: 0x101,
+		ElementaryStreamDescriptors: []*Descriptor{{Metadata: &DescriptorMetadata{
+			ApplicationFormat:           MetadataApplicationFormatIdentifierField,
+			ApplicationFormatIdentifier: MetadataFormatIdentifierID3,
+			Format:                      MetadataFormatIdentifierField,
+			FormatIdentifier:            MetadataFormatIdentifierID3,
+		}, Tag: DescriptorTagMetadata}},
+		StreamType: StreamTypeMetadataPES,
+	}}, PCRPID: 0x1fff, ProgramNumber: 1}).Serialize(0)
+	pes := (&PESData{
+		Data: id3TagBytes(),
+		Header: &PESHeader{
+			OptionalHeader: &PESOptionalHeader{
+				MarkerBits:      2,
+				PTS:             newClockReference(90000, 0),
+				PTSDTSIndicator: PTSDTSIndicatorOnlyPTS,
+			},
+			StreamID: StreamIDPrivateStream1,
+		},
+	}).Serialize()
+	for _, v := range []struct {
+		b   []byte
+		pid uint16
+	}{
+		{b: append([]byte{0x0}, pm...), pid: 0x100},
+		{b: pes, pid: 0x101},
+	} {
+		var p = append(v.b, bytes.Repeat([]byte{0xff}, 147-len(v.b))...)
+		b, _ := packet(PacketHeader{PayloadUnitStartIndicator: true, PID: v.pid}, PacketAdaptationField{}, p)
+		w.Write(b)
+		b, _ = packet(PacketHeader{ContinuityCounter: uint8(1), PayloadUnitStartIndicator: true, PID: v.pid}, PacketAdaptationField{}, []byte{})
+		w.Write(b)
+	}
+	dmx := New(context.Background(), bytes.NewReader(w.Bytes()))
+	dmx.programMap.set(0x100, 1)
+
+	// PMT
+	d, err := dmx.NextData()
+	assert.NoError(t, err)
+	assert.NotNil(t, d.PMT)
+
+	// ID3
+	d, err = dmx.NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, &ID3Data{PTS: newClockReference(90000, 0), Tags: id3Tags}, d.ID3)
+}
@@ -0,0 +1,138 @@
+package astits
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// KLV universal keys
+// Link: MISB ST 0601
+const (
+	KLVKeyUASDatalinkLocalSet = "\x06\x0e\x2b\x34\x02\x0b\x01\x01\x0e\x01\x03\x01\x01\x00\x00\x00"
+)
+
+// KLV constants
+const (
+	klvKeyLength = 16
+)
+
+// KLVData represents the KLV packets carried by a PES of a KLV metadata stream
+type KLVData struct {
+	Packets []*KLVPacket
+	PTS     *ClockReference // PTS of the PES the packets were carried by. Only synchronous streams are timed
+}
+
+// KLVPacket represents a KLV packet, i.e. a universal key followed by a BER coded length and a value
+// Page: 6 | Chapter: 4 | Link: SMPTE ST 336
+type KLVPacket struct {
+	Key    []byte // Universal label, which can be compared to the KLVKey* constants
+	Length int
+	Value  []byte
+}
+
+// KLVLocalSetItem represents an item of a KLV local set
+// Page: 10 | Chapter: 6.3 | Link: SMPTE ST 336
+type KLVLocalSetItem struct {
+	Tag   uint32
+	Value []byte
+}
+
+// ParseKLVPackets parses the KLV packets concatenated in a payload
+func ParseKLVPackets(i []byte) (ps []*KLVPacket, err error) {
+	var offset int
+	for offset < len(i) {
+		// Key
+		if offset+klvKeyLength > len(i) {
+			err = fmt.Errorf("astits: KLV key end (%d) > len(i) (%d)", offset+klvKeyLength, len(i))
+			return
+		}
+		var p = &KLVPacket{Key: i[offset : offset+klvKeyLength]}
+		offset += klvKeyLength
+
+		// Length
+		if p.Length, err = parseKLVBERLength(i, &offset); err != nil {
+			err = errors.Wrapf(err, "astits: parsing length of KLV packet #%d failed", len(ps)+1)
+			return
+		}
+
+		// Value
+		if offset+p.Length > len(i) {
+			err = fmt.Errorf("astits: KLV value end (%d) > len(i) (%d)", offset+p.Length, len(i))
+			return
+		}
+		p.Value = i[offset : offset+p.Length]
+		offset += p.Length
+		ps = append(ps, p)
+	}
+	return
+}
+
+// LocalSet parses the value of the packet as a local set whose tags are BER-OID coded and whose lengths are BER
+// coded, such as the MISB ST 0601 UAS datalink local set
+func (p *KLVPacket) LocalSet() (is []*KLVLocalSetItem, err error) {
+	var offset int
+	for offset < len(p.Value) {
+		// Tag
+		var itm = &KLVLocalSetItem{}
+		for {
+			if offset >= len(p.Value) {
+				err = fmt.Errorf("astits: KLV local set tag end (%d) > len(value) (%d)", offset+1, len(p.Value))
+				return
+			}
+			itm.Tag = itm.Tag<<7 | uint32(p.Value[offset]&0x7f)
+			offset++
+			if p.Value[offset-1]&0x80 == 0 {
+				break
+			}
+		}
+
+		// Length
+		var l int
+		if l, err = parseKLVBERLength(p.Value, &offset); err != nil {
+			err = errors.Wrapf(err, "astits: parsing length of KLV local set item with tag %d failed", itm.Tag)
+			return
+		}
+
+		// Value
+		if offset+l > len(p.Value) {
+			err = fmt.Errorf("astits: KLV local set value end (%d) > len(value) (%d)", offset+l, len(p.Value))
+			return
+		}
+		itm.Value = p.Value[offset : offset+l]
+		offset += l
+		is = append(is, itm)
+	}
+	return
+}
+
+// parseKLVBERLength parses a BER coded length, either on 1 byte when it's lower than 128 or on the number of bytes
+// specified by the first byte otherwise
+func parseKLVBERLength(i []byte, offset *int) (l int, err error) {
+	// Short form
+	if *offset >= len(i) {
+		err = fmt.Errorf("astits: BER length end (%d) > len(i) (%d)", *offset+1, len(i))
+		return
+	}
+	var b = i[*offset]
+	*offset++
+	if b&0x80 == 0 {
+		l = int(b)
+		return
+	}
+
+	// Long form
+	var n = int(b & 0x7f)
+	if n > 4 {
+		err = fmt.Errorf("astits: BER length is coded on %d bytes", n)
+		return
+	} else if *offset+n > len(i) {
+		err = fmt.Errorf("astits: BER length end (%d) > len(i) (%d)", *offset+n, len(i))
+		return
+	}
+	for idx := 0; idx < n; idx++ {
+		l = l<<8 | int(i[*offset+idx])
+	}
+	*offset += n
+	return
+}
@@ -0,0 +1,101 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/asticode/go-astitools/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+func klvBytes() []byte {
+	w := astibinary.New()
+	w.Write([]byte(KLVKeyUASDatalinkLocalSet)) // Key
+	w.Write(uint8(0x81))                       // Length in long form
+	w.Write(uint8(9))                          // Length
+	w.Write([]byte{0x2, 0x2, 0x1, 0x2})        // Tag 2 with a 2 bytes value
+	w.Write([]byte{0x81, 0x1, 0x2, 0x3, 0x4})  // Tag 129 with a 2 bytes value
+	w.Write(bytes.Repeat([]byte{0x1}, 16))     // Key
+	w.Write(uint8(0))                          // Length
+	return w.Bytes()
+}
+
+var klvPackets = []*KLVPacket{
+	{Key: []byte(KLVKeyUASDatalinkLocalSet), Length: 9, Value: []byte{0x2, 0x2, 0x1, 0x2, 0x81, 0x1, 0x2, 0x3, 0x4}},
+	{Key: bytes.Repeat([]byte{0x1}, 16), Value: []byte{}},
+}
+
+func TestParseKLVPackets(t *testing.T) {
+	ps, err := ParseKLVPackets(klvBytes())
+	assert.NoError(t, err)
+	assert.Equal(t, klvPackets, ps)
+
+	// Truncated packets
+	for _, l := range []int{10, 17, 20} {
+		_, err = ParseKLVPackets(klvBytes()[:l])
+		assert.Error(t, err)
+	}
+}
+
+func TestKLVPacketLocalSet(t *testing.T) {
+	is, err := klvPackets[0].LocalSet()
+	assert.NoError(t, err)
+	assert.Equal(t, []*KLVLocalSetItem{
+		{Tag: 2, Value: []byte{0x1, 0x2}},
+		{Tag: 129, Value: []byte{0x3, 0x4}},
+	}, is)
+	_, err = (&KLVPacket{Value: []byte{0x2, 0x2, 0x1}}).LocalSet()
+	assert.Error(t, err)
+}
+
+func TestDemuxerKLV(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	pm, _ := (&PMTData{ElementaryStreams: []*PMTElementaryStream{{
+		ElementaryPID: 0x101,
+		ElementaryStreamDescriptors: []*Descriptor{{Metadata: &DescriptorMetadata{
+			ApplicationFormat: 0x100,
+			Format:            MetadataFormatIdentifierField,
+			FormatIdentifier:  MetadataFormatIdentifierKLVA,
+		}, Tag: DescriptorTagMetadata}},
+		StreamType: StreamTypeMetadataPES,
+	}}, PCRPID: 0x1fff, ProgramNumber: 1}).Serialize(0)
+	var k = klvBytes()
+	pes := (&PESData{
+		Data: append([]byte{0x0, 0x0, 0xdf, 0x0, uint8(len(k))}, k...),
+		Header: &PESHeader{
+			OptionalHeader: &PESOptionalHeader{
+				MarkerBits:      2,
+				PTS:             newClockReference(90000, 0),
+				PTSDTSIndicator: PTSDTSIndicatorOnlyPTS,
+			},
+			StreamID: StreamIDMetadataStream,
+		},
+	}).Serialize()
+	for _, v := range []struct {
+		b   []byte
+		pid uint16
+	}{
+		{b: append([]byte{0x0}, pm...), pid: 0x100},
+		{b: pes, pid: 0x101},
+	} {
+		var p = append(v.b, bytes.Repeat([]byte{0xff}, 147-len(v.b))...)
+		b, _ := packet(PacketHeader{PayloadUnitStartIndicator: true, PID: v.pid}, PacketAdaptationField{}, p)
+		w.Write(b)
+		b, _ = packet(PacketHeader{ContinuityCounter: uint8(1), PayloadUnitStartIndicator: true, PID: v.pid}, PacketAdaptationField{}, []byte{})
+		w.Write(b)
+	}
+	dmx := New(context.Background(), bytes.NewReader(w.Bytes()))
+	dmx.programMap.set(0x100, 1)
+
+	// PMT
+	d, err := dmx.NextData()
+	assert.NoError(t, err)
+	assert.NotNil(t, d.PMT)
+
+	// KLV
+	d, err = dmx.NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, &KLVData{Packets: klvPackets, PTS: newClockReference(90000, 0)}, d.KLV)
+}
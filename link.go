@@ -0,0 +1,266 @@
+package astits
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Link constants
+const (
+	linkDefaultPayloadSize = udpPacketsPerDatagram * MpegTsPacketSize
+	linkDefaultRetryDelay  = time.Second
+	linkReadBufferSize     = 64 * 1024
+)
+
+// LinkDialFunc represents a function connecting to the remote end of a link, such as the caller or listener of an
+// SRT library. The connection is closed by the link when it fails or when the link is closed
+type LinkDialFunc func(ctx context.Context) (io.ReadWriteCloser, error)
+
+// Link represents a contribution link, such as an SRT connection, used as a packet source given to the demuxer or as a
+// packet sink given to the muxer, whose connection is reestablished whenever it fails
+// Reads only return complete packets: bytes of a packet cut short by a failure are dropped so that the buffered reader
+// of the demuxer is never left in the middle of a packet. Since the first packet received after a reconnection may not
+// start where expected, the demuxer should be created with OptResync.
+// Writes are sent in payloads of 7 packets, which is the payload size of SRT in live mode, and payloads that fail to
+// be sent are dropped instead of delaying the stream.
+// Reads and writes block while the link reconnects, and return io.EOF once the context is done or the link is closed
+type Link struct {
+	c              io.ReadWriteCloser
+	closed         bool
+	connected      bool // Whether a connection has been established once
+	ctx            context.Context
+	dial           LinkDialFunc
+	droppedPackets int64
+	errorHandler   func(err error)
+	m              *sync.Mutex
+	packetSize     int
+	payloadSize    int
+	readBuffer     []byte
+	readPartial    []byte // Bytes of the incomplete packet ending the last read
+	readReady      []byte // Complete packets that have not been read yet
+	reconnections  int64
+	retryDelay     time.Duration
+	writeBuffer    []byte
+}
+
+// NewLink creates a new link connecting with a dial function
+func NewLink(ctx context.Context, dial LinkDialFunc, opts ...func(*Link)) (l *Link) {
+	l = &Link{
+		ctx:         ctx,
+		dial:        dial,
+		m:           &sync.Mutex{},
+		packetSize:  MpegTsPacketSize,
+		payloadSize: linkDefaultPayloadSize,
+		retryDelay:  linkDefaultRetryDelay,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	l.readBuffer = make([]byte, linkReadBufferSize+l.packetSize)
+	return
+}
+
+// LinkOptErrorHandler returns the option to be notified of the errors that make the link reconnect
+func LinkOptErrorHandler(h func(err error)) func(*Link) {
+	return func(l *Link) {
+		l.errorHandler = h
+	}
+}
+
+// LinkOptPacketSize returns the option to set the size of the packets carried by the link, which is 188 by default
+func LinkOptPacketSize(packetSize int) func(*Link) {
+	return func(l *Link) {
+		l.packetSize = packetSize
+		l.payloadSize = udpPacketsPerDatagram * packetSize
+	}
+}
+
+// LinkOptRetryDelay returns the option to set the delay between 2 connection attempts, which is 1s by default
+func LinkOptRetryDelay(d time.Duration) func(*Link) {
+	return func(l *Link) {
+		l.retryDelay = d
+	}
+}
+
+// DroppedPackets returns the number of packets that were dropped because they couldn't be sent
+func (l *Link) DroppedPackets() int64 {
+	l.m.Lock()
+	defer l.m.Unlock()
+	return l.droppedPackets
+}
+
+// Reconnections returns the number of times the connection has been reestablished
+func (l *Link) Reconnections() int64 {
+	l.m.Lock()
+	defer l.m.Unlock()
+	return l.reconnections
+}
+
+// Close closes the link and its connection, which makes blocked reads and writes return
+func (l *Link) Close() (err error) {
+	l.m.Lock()
+	defer l.m.Unlock()
+	l.closed = true
+	if l.c != nil {
+		err = l.c.Close()
+		l.c = nil
+	}
+	return
+}
+
+// conn returns the connection of the link, and connects it if needed
+func (l *Link) conn() (c io.ReadWriteCloser, err error) {
+	for {
+		// Link is done
+		l.m.Lock()
+		if l.closed || l.ctx.Err() != nil {
+			l.m.Unlock()
+			err = io.EOF
+			return
+		} else if l.c != nil {
+			c = l.c
+			l.m.Unlock()
+			return
+		}
+		l.m.Unlock()
+
+		// Dial
+		if c, err = l.dial(l.ctx); err == nil {
+			l.m.Lock()
+			if l.closed {
+				l.m.Unlock()
+				c.Close()
+				err = io.EOF
+				return
+			}
+			if l.connected {
+				l.reconnections++
+			}
+			l.c = c
+			l.connected = true
+			l.m.Unlock()
+			return
+		}
+		l.handleError(errors.Wrap(err, "astits: dialing failed"))
+
+		// Wait before retrying
+		select {
+		case <-l.ctx.Done():
+		case <-time.After(l.retryDelay):
+		}
+	}
+}
+
+// fail closes a connection that failed so that the next read or write reconnects
+func (l *Link) fail(c io.ReadWriteCloser, err error) {
+	l.m.Lock()
+	if l.c == c {
+		l.c = nil
+		c.Close()
+	}
+	var closed = l.closed
+	l.m.Unlock()
+
+	// Errors caused by closing the link are expected
+	if !closed {
+		l.handleError(err)
+	}
+}
+
+// handleError notifies the error handler, if any
+func (l *Link) handleError(err error) {
+	if l.errorHandler != nil {
+		l.errorHandler(err)
+	}
+}
+
+// Read implements the io.Reader interface and returns complete packets
+func (l *Link) Read(p []byte) (n int, err error) {
+	// Wait for complete packets
+	for len(l.readReady) == 0 {
+		// Get connection
+		var c io.ReadWriteCloser
+		if c, err = l.conn(); err != nil {
+			return
+		}
+
+		// Read after the bytes of the incomplete packet
+		var o = copy(l.readBuffer, l.readPartial)
+		var m int
+		m, err = c.Read(l.readBuffer[o:])
+		if err != nil {
+			// The incomplete packet won't be completed by the next connection
+			l.readPartial = nil
+			l.fail(c, errors.Wrap(err, "astits: reading failed"))
+			err = nil
+			continue
+		}
+
+		// Split complete packets
+		var b = l.readBuffer[:o+m]
+		var e = len(b) - len(b)%l.packetSize
+		l.readReady = b[:e]
+		l.readPartial = b[e:]
+	}
+
+	// Copy
+	n = copy(p, l.readReady)
+	l.readReady = l.readReady[n:]
+	return
+}
+
+// Write implements the io.Writer interface and sends packets in payloads of 7 packets
+// Bytes that don't make a complete payload are buffered until the next write or until Flush is called
+func (l *Link) Write(b []byte) (n int, err error) {
+	// Buffer bytes
+	n = len(b)
+	l.writeBuffer = append(l.writeBuffer, b...)
+
+	// Loop through payloads
+	var o int
+	for ; o+l.payloadSize <= len(l.writeBuffer); o += l.payloadSize {
+		if err = l.send(l.writeBuffer[o : o+l.payloadSize]); err != nil {
+			return
+		}
+	}
+
+	// Keep the rest
+	l.writeBuffer = append(l.writeBuffer[:0], l.writeBuffer[o:]...)
+	return
+}
+
+// Flush sends the complete packets that don't make a complete payload
+func (l *Link) Flush() (err error) {
+	var e = len(l.writeBuffer) - len(l.writeBuffer)%l.packetSize
+	if e == 0 {
+		return
+	}
+	if err = l.send(l.writeBuffer[:e]); err != nil {
+		return
+	}
+	l.writeBuffer = append(l.writeBuffer[:0], l.writeBuffer[e:]...)
+	return
+}
+
+// send sends a payload, which is dropped if it fails
+func (l *Link) send(b []byte) (err error) {
+	// Get connection
+	var c io.ReadWriteCloser
+	if c, err = l.conn(); err != nil {
+		return
+	}
+
+	// Write
+	if _, err = c.Write(b); err != nil {
+		l.m.Lock()
+		l.droppedPackets += int64(len(b) / l.packetSize)
+		l.m.Unlock()
+		l.fail(c, errors.Wrap(err, "astits: writing failed"))
+		err = nil
+	}
+	return
+}
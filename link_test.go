@@ -0,0 +1,108 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type linkConn struct {
+	closed  bool
+	reads   [][]byte
+	writes  [][]byte
+	maxOK   int // Number of writes that succeed
+	written int
+}
+
+func (c *linkConn) Read(p []byte) (n int, err error) {
+	if len(c.reads) == 0 {
+		return 0, io.EOF
+	}
+	n = copy(p, c.reads[0])
+	c.reads = c.reads[1:]
+	return
+}
+
+func (c *linkConn) Write(p []byte) (n int, err error) {
+	if c.written++; c.written > c.maxOK {
+		return 0, errors.New("write failed")
+	}
+	c.writes = append(c.writes, append([]byte{}, p...))
+	return len(p), nil
+}
+
+func (c *linkConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestLinkRead(t *testing.T) {
+	// Init
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var pA, pB, pC, pD = t2miTSPacket(0xaa), t2miTSPacket(0xbb), t2miTSPacket(0xcc), t2miTSPacket(0xdd)
+	var cs = []*linkConn{
+		{reads: [][]byte{append(pA, pB[:100]...)}},
+		{reads: [][]byte{pC[:50], append(pC[50:], pD...)}},
+	}
+	var errs int
+	l := NewLink(ctx, func(ctx context.Context) (io.ReadWriteCloser, error) {
+		if len(cs) == 0 {
+			cancel()
+			return nil, errors.New("dial failed")
+		}
+		c := cs[0]
+		cs = cs[1:]
+		return c, nil
+	}, LinkOptErrorHandler(func(err error) { errs++ }), LinkOptRetryDelay(time.Millisecond))
+
+	// Packet cut short by the failure is dropped
+	b, err := ioutil.ReadAll(l)
+	assert.NoError(t, err)
+	assert.Equal(t, append(pA, append(pC, pD...)...), b)
+	assert.Equal(t, int64(1), l.Reconnections())
+	assert.Equal(t, 3, errs)
+
+	// Closed link
+	l = NewLink(context.Background(), func(ctx context.Context) (io.ReadWriteCloser, error) { return &linkConn{}, nil })
+	l.Close()
+	_, err = l.Read(b)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestLinkWrite(t *testing.T) {
+	// Init
+	var cs = []*linkConn{{maxOK: 1}, {maxOK: 1}}
+	var dialed []*linkConn
+	l := NewLink(context.Background(), func(ctx context.Context) (io.ReadWriteCloser, error) {
+		c := cs[0]
+		cs = cs[1:]
+		dialed = append(dialed, c)
+		return c, nil
+	})
+
+	// Payloads that fail are dropped
+	var ps = bytes.Repeat(t2miTSPacket(0xaa), 15)
+	n, err := l.Write(ps[:100])
+	assert.NoError(t, err)
+	assert.Equal(t, 100, n)
+	assert.Len(t, dialed, 0)
+	_, err = l.Write(ps[100:])
+	assert.NoError(t, err)
+	assert.Len(t, dialed, 1)
+	assert.Equal(t, [][]byte{ps[:7*MpegTsPacketSize]}, dialed[0].writes)
+	assert.True(t, dialed[0].closed)
+	assert.Equal(t, int64(7), l.DroppedPackets())
+
+	// Flush
+	assert.NoError(t, l.Flush())
+	assert.Len(t, dialed, 2)
+	assert.Equal(t, [][]byte{ps[14*MpegTsPacketSize:]}, dialed[1].writes)
+	assert.Equal(t, int64(1), l.Reconnections())
+}
@@ -0,0 +1,85 @@
+package astits
+
+// LogicalChannel represents the logical channel number of a service, as signaled by the logical channel descriptors
+// of a NIT or a BAT
+type LogicalChannel struct {
+	ChannelListID     uint8 // Set when the number comes from a NorDig channel list
+	CountryCode       string
+	Number            uint16
+	OriginalNetworkID uint16
+	ServiceID         uint16
+	TransportStreamID uint16
+	Visible           bool
+}
+
+// LogicalChannels returns the logical channel numbers of the services of the transport streams of the network
+// Logical channel descriptors are only taken into account when the private data specifier in effect uses them
+func (d *NITData) LogicalChannels() []*LogicalChannel {
+	return d.LogicalChannelsWithDefaultSpecifier(0)
+}
+
+// LogicalChannelsWithDefaultSpecifier returns the logical channel numbers of the services of the transport streams
+// of the network, logical channel descriptors preceded by no private data specifier being interpreted as defined by
+// the provided one, since many networks don't signal it
+func (d *NITData) LogicalChannelsWithDefaultSpecifier(specifier uint32) (cs []*LogicalChannel) {
+	for _, ts := range d.TransportStreams {
+		cs = append(cs, logicalChannels(ts.TransportStreamID, ts.OriginalNetworkID, ts.TransportDescriptors, specifier)...)
+	}
+	return
+}
+
+// LogicalChannels returns the logical channel numbers of the services of the transport streams of the bouquet
+// Logical channel descriptors are only taken into account when the private data specifier in effect uses them
+func (d *BATData) LogicalChannels() []*LogicalChannel {
+	return d.LogicalChannelsWithDefaultSpecifier(0)
+}
+
+// LogicalChannelsWithDefaultSpecifier returns the logical channel numbers of the services of the transport streams
+// of the bouquet, logical channel descriptors preceded by no private data specifier being interpreted as defined by
+// the provided one
+func (d *BATData) LogicalChannelsWithDefaultSpecifier(specifier uint32) (cs []*LogicalChannel) {
+	for _, ts := range d.TransportStreams {
+		cs = append(cs, logicalChannels(ts.TransportStreamID, ts.OriginalNetworkID, ts.TransportDescriptors, specifier)...)
+	}
+	return
+}
+
+// logicalChannels returns the logical channel numbers signaled by the descriptors of a transport stream
+func logicalChannels(transportStreamID, originalNetworkID uint16, ds []*Descriptor, defaultSpecifier uint32) (cs []*LogicalChannel) {
+	// Create logical channel
+	var add = func(itm *DescriptorLogicalChannelItem, channelListID uint8, countryCode string) {
+		cs = append(cs, &LogicalChannel{
+			ChannelListID:     channelListID,
+			CountryCode:       countryCode,
+			Number:            itm.LogicalChannelNumber,
+			OriginalNetworkID: originalNetworkID,
+			ServiceID:         itm.ServiceID,
+			TransportStreamID: transportStreamID,
+			Visible:           itm.VisibleServiceFlag,
+		})
+	}
+
+	// Loop through descriptors
+	for _, d := range ds {
+		// Interpret descriptors preceded by no private data specifier with the default one
+		if d.Specifier == 0 && defaultSpecifier != 0 && d.UserDefined != nil {
+			var c = &Descriptor{Tag: d.Tag, UserDefined: d.UserDefined}
+			parseUserDefinedDescriptor(c, defaultSpecifier)
+			d = c
+		}
+
+		// Add logical channels
+		if d.LogicalChannel != nil {
+			for _, itm := range d.LogicalChannel.Items {
+				add(itm, 0, "")
+			}
+		} else if d.LogicalChannelV2 != nil {
+			for _, l := range d.LogicalChannelV2.ChannelLists {
+				for _, itm := range l.Items {
+					add(itm, l.ChannelListID, string(l.CountryCode))
+				}
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,135 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/asticode/go-astitools/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescriptorLogicalChannel(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	w.Write("1111")                                   // Reserved
+	w.Write("000000101000")                           // Descriptors length
+	w.Write(uint8(DescriptorTagLogicalChannel))       // Tag
+	w.Write(uint8(4))                                 // Length
+	w.Write(uint16(0x101))                            // Service ID
+	w.Write("1")                                      // Visible service flag
+	w.Write("11111")                                  // Reserved
+	w.Write("0000000001")                             // Logical channel number
+	w.Write(uint8(DescriptorTagPrivateDataSpecifier)) // Tag
+	w.Write(uint8(4))                                 // Length
+	w.Write(uint32(PrivateDataSpecifierNorDig))       // Private data specifier
+	w.Write(uint8(DescriptorTagLogicalChannel))       // Tag
+	w.Write(uint8(4))                                 // Length
+	w.Write(uint16(0x102))                            // Service ID
+	w.Write("0")                                      // Visible service flag
+	w.Write("1")                                      // Reserved
+	w.Write("00010000000000")                         // Logical channel number
+	w.Write(uint8(DescriptorTagLogicalChannelV2))     // Tag
+	w.Write(uint8(12))                                // Length
+	w.Write(uint8(2))                                 // Channel list ID
+	w.Write(uint8(2))                                 // Channel list name length
+	w.Write([]byte("no"))                             // Channel list name
+	w.Write([]byte("NOR"))                            // Country code
+	w.Write(uint8(4))                                 // Descriptor length
+	w.Write(uint16(0x101))                            // Service ID
+	w.Write("1")                                      // Visible service flag
+	w.Write("11111")                                  // Reserved
+	w.Write("0000000011")                             // Logical channel number
+	w.Write(uint8(DescriptorTagPrivateDataSpecifier)) // Tag
+	w.Write(uint8(4))                                 // Length
+	w.Write(uint32(1))                                // Private data specifier
+	w.Write(uint8(DescriptorTagLogicalChannel))       // Tag
+	w.Write(uint8(0))                                 // Length
+
+	// Parse
+	var offset int
+	ds := parseDescriptors(w.Bytes(), &offset)
+	assert.Nil(t, ds[0].LogicalChannel)
+	assert.Equal(t, []byte{0x1, 0x1, 0xfc, 0x1}, ds[0].UserDefined)
+	assert.Equal(t, &DescriptorLogicalChannel{Items: []*DescriptorLogicalChannelItem{{LogicalChannelNumber: 1024, ServiceID: 0x102}}, Specifier: PrivateDataSpecifierNorDig}, ds[2].LogicalChannel)
+	assert.Equal(t, []*DescriptorLogicalChannelV2ChannelList{{
+		ChannelListID:   2,
+		ChannelListName: []byte("no"),
+		CountryCode:     []byte("NOR"),
+		Items:           []*DescriptorLogicalChannelItem{{LogicalChannelNumber: 3, ServiceID: 0x101, VisibleServiceFlag: true}},
+	}}, ds[3].LogicalChannelV2.ChannelLists)
+	assert.Len(t, ds[3].UserDefined, 12)
+	assert.Nil(t, ds[5].LogicalChannel)
+
+	// Write
+	b, err := writeDescriptors(ds)
+	assert.NoError(t, err)
+	assert.Equal(t, w.Bytes(), b)
+
+	// Logical channels
+	var nit = &NITData{TransportStreams: []*NITDataTransportStream{{OriginalNetworkID: 2, TransportDescriptors: ds, TransportStreamID: 1}}}
+	assert.Equal(t, []*LogicalChannel{
+		{Number: 1024, OriginalNetworkID: 2, ServiceID: 0x102, TransportStreamID: 1},
+		{ChannelListID: 2, CountryCode: "NOR", Number: 3, OriginalNetworkID: 2, ServiceID: 0x101, TransportStreamID: 1, Visible: true},
+	}, nit.LogicalChannels())
+	assert.Equal(t, []*LogicalChannel{
+		{Number: 1, OriginalNetworkID: 2, ServiceID: 0x101, TransportStreamID: 1, Visible: true},
+		{Number: 1024, OriginalNetworkID: 2, ServiceID: 0x102, TransportStreamID: 1},
+		{ChannelListID: 2, CountryCode: "NOR", Number: 3, OriginalNetworkID: 2, ServiceID: 0x101, TransportStreamID: 1, Visible: true},
+	}, nit.LogicalChannelsWithDefaultSpecifier(PrivateDataSpecifierEICTA))
+
+	// Changes are written once raw bytes are cleared
+	ds[2].LogicalChannel.Items[0].LogicalChannelNumber = 5
+	ds[2].UserDefined = nil
+	b, err = writeDescriptor(ds[2])
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{DescriptorTagLogicalChannel, 4, 0x1, 0x2, 0x40, 0x5}, b)
+}
+
+func TestDescriptorLogicalChannelV2Passthrough(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	w.Write("1111")                                   // Reserved
+	w.Write("000000101100")                           // Descriptors length
+	w.Write(uint8(DescriptorTagPrivateDataSpecifier)) // Tag
+	w.Write(uint8(4))                                 // Length
+	w.Write(uint32(PrivateDataSpecifierNorDig))       // Private data specifier
+	w.Write(uint8(DescriptorTagLogicalChannelV2))     // Tag
+	w.Write(uint8(13))                                // Length
+	w.Write(uint8(2))                                 // Channel list ID
+	w.Write(uint8(2))                                 // Channel list name length
+	w.Write([]byte("no"))                             // Channel list name
+	w.Write([]byte("NOR"))                            // Country code
+	w.Write(uint8(8))                                 // Descriptor length
+	w.Write(uint16(0x101))                            // Service ID
+	w.Write("1")                                      // Visible service flag
+	w.Write("00000")                                  // Reserved
+	w.Write("0000000011")                             // Logical channel number
+	w.Write(uint8(0x1))                               // Truncated channel list
+	w.Write(uint8(DescriptorTagLogicalChannelV2))     // Tag
+	w.Write(uint8(7))                                 // Length
+	w.Write([]byte("foreign"))                        // Foreign content
+	w.Write(uint8(DescriptorTagLogicalChannelV2))     // Tag
+	w.Write(uint8(12))                                // Length
+	w.Write(uint8(2))                                 // Channel list ID
+	w.Write(uint8(2))                                 // Channel list name length
+	w.Write([]byte("no"))                             // Channel list name
+	w.Write([]byte("NOR"))                            // Country code
+	w.Write(uint8(4))                                 // Descriptor length
+	w.Write(uint16(0x101))                            // Service ID
+	w.Write("1")                                      // Visible service flag
+	w.Write("00000")                                  // Reserved
+	w.Write("0000000011")                             // Logical channel number
+
+	// Parse
+	var offset int
+	ds := parseDescriptors(w.Bytes(), &offset)
+	assert.Nil(t, ds[1].LogicalChannelV2)
+	assert.Len(t, ds[1].UserDefined, 13)
+	assert.Nil(t, ds[2].LogicalChannelV2)
+	assert.Equal(t, []byte("foreign"), ds[2].UserDefined)
+	assert.Equal(t, []*DescriptorLogicalChannelItem{{LogicalChannelNumber: 3, ServiceID: 0x101, VisibleServiceFlag: true}}, ds[3].LogicalChannelV2.ChannelLists[0].Items)
+
+	// Write
+	b, err := writeDescriptors(ds)
+	assert.NoError(t, err)
+	assert.Equal(t, w.Bytes(), b)
+}
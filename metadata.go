@@ -0,0 +1,160 @@
+package astits
+
+// Metadata access unit cell constants
+// Page: 110 | Chapter: 2.12.4 | Link: ISO/IEC 13818-1
+const (
+	metadataAUCellFragmentComplete = 0x3
+	metadataAUCellFragmentFirst    = 0x2
+	metadataAUCellFragmentLast     = 0x1
+	metadataAUCellHeaderLength     = 5
+)
+
+// metadataTracker keeps track of the elementary streams carrying ID3 or KLV metadata
+type metadataTracker struct {
+	pids map[uint16]*metadataTrackerPID // Indexed by elementary PID
+}
+
+// metadataTrackerPID represents an elementary stream carrying ID3 or KLV metadata
+type metadataTrackerPID struct {
+	buf              []byte // Payload of the fragmented metadata access unit cell being reassembled
+	formatIdentifier uint32
+	serviceID        uint8
+}
+
+// newMetadataTracker creates a new metadata tracker
+func newMetadataTracker() *metadataTracker {
+	return &metadataTracker{pids: make(map[uint16]*metadataTrackerPID)}
+}
+
+// setProgram adds the metadata streams of a program, i.e. the metadata PES streams whose metadata descriptor signals
+// ID3 tags or KLV packets, and the asynchronous KLV streams flagged by a registration descriptor
+func (t *metadataTracker) setProgram(d *PMTData) {
+	for _, es := range d.ElementaryStreams {
+		// Get format
+		var p *metadataTrackerPID
+		switch es.StreamType {
+		case StreamTypeMetadataPES:
+			for _, f := range []uint32{MetadataFormatIdentifierID3, MetadataFormatIdentifierKLVA} {
+				if md := metadataDescriptor(es, f); md != nil {
+					p = &metadataTrackerPID{formatIdentifier: f, serviceID: md.ServiceID}
+					break
+				}
+			}
+		case StreamTypeMPEG2PacketizedData:
+			if hasRegistrationDescriptor(es, RegistrationFormatIdentifierKLVA) {
+				p = &metadataTrackerPID{formatIdentifier: MetadataFormatIdentifierKLVA}
+			}
+		}
+		if p == nil {
+			continue
+		}
+
+		// Streams whose format is unchanged keep the cell being reassembled
+		if v, ok := t.pids[es.ElementaryPID]; ok && v.formatIdentifier == p.formatIdentifier && v.serviceID == p.serviceID {
+			continue
+		}
+		t.pids[es.ElementaryPID] = p
+	}
+}
+
+// reset drops the cells being reassembled, the metadata streams being kept as long as the PMTs are
+func (t *metadataTracker) reset() {
+	for _, p := range t.pids {
+		p.buf = nil
+	}
+}
+
+// payload returns the metadata payload of a PES
+// PES whose stream ID is the metadata stream one are made of metadata access unit cells, whose payloads may be
+// fragmented over several PES. Other PES, such as the private stream 1 ones used by Apple HTTP Live Streaming or by
+// asynchronous KLV streams, carry the metadata directly
+// Page: 110 | Chapter: 2.12.4 | Link: ISO/IEC 13818-1
+func (p *metadataTrackerPID) payload(d *PESData) (o []byte) {
+	// Metadata is carried directly
+	if d.Header.StreamID != StreamIDMetadataStream {
+		return d.Data
+	}
+
+	// Loop through cells
+	for offset := 0; offset+metadataAUCellHeaderLength <= len(d.Data); {
+		// Header
+		var serviceID, fragment = d.Data[offset], d.Data[offset+2] >> 6
+		var l = int(uint16(d.Data[offset+3])<<8 | uint16(d.Data[offset+4]))
+		offset += metadataAUCellHeaderLength
+		if offset+l > len(d.Data) {
+			p.buf = nil
+			return
+		}
+		var b = d.Data[offset : offset+l]
+		offset += l
+
+		// Cells of other metadata services are ignored
+		if serviceID != p.serviceID {
+			continue
+		}
+
+		// Switch on fragment indication
+		switch fragment {
+		case metadataAUCellFragmentComplete:
+			o = append(o, b...)
+		case metadataAUCellFragmentFirst:
+			p.buf = append([]byte{}, b...)
+		case metadataAUCellFragmentLast:
+			if p.buf != nil {
+				o = append(o, append(p.buf, b...)...)
+				p.buf = nil
+			}
+		default:
+			if p.buf != nil {
+				p.buf = append(p.buf, b...)
+			}
+		}
+	}
+	return
+}
+
+// updateMetadataData parses the ID3 tags or the KLV packets of PES data received on metadata streams
+func (dmx *Demuxer) updateMetadataData(ds []*Data) {
+	for _, d := range ds {
+		// Only PES data of metadata streams is updated
+		if d.PES == nil {
+			continue
+		}
+		p, ok := dmx.metadataTracker.pids[d.PID]
+		if !ok {
+			continue
+		}
+
+		// Get payload
+		var b = p.payload(d.PES)
+		if len(b) == 0 {
+			continue
+		}
+
+		// Get PTS
+		var pts *ClockReference
+		if h := d.PES.Header.OptionalHeader; h != nil {
+			pts = h.PTS
+		}
+
+		// Switch on format
+		switch p.formatIdentifier {
+		case MetadataFormatIdentifierID3:
+			ts, err := ParseID3Tags(b)
+			if err != nil {
+				// Tags may be incomplete, therefore we only log the error and move on
+				dmx.optLogger.Debugf("astits: parsing ID3 tags of PID %d failed: %s", d.PID, err)
+				continue
+			}
+			d.ID3 = &ID3Data{PTS: pts, Tags: ts}
+		case MetadataFormatIdentifierKLVA:
+			ps, err := ParseKLVPackets(b)
+			if err != nil {
+				// Packets may be incomplete, therefore we only log the error and move on
+				dmx.optLogger.Debugf("astits: parsing KLV packets of PID %d failed: %s", d.PID, err)
+				continue
+			}
+			d.KLV = &KLVData{Packets: ps, PTS: pts}
+		}
+	}
+}
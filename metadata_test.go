@@ -0,0 +1,50 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadataTrackerPIDPayload(t *testing.T) {
+	// Cells
+	var tag = id3TagBytes()
+	var cells = func(c ...[]byte) (b []byte) {
+		for _, v := range c {
+			b = append(b, v...)
+		}
+		return
+	}
+	var cell = func(serviceID, fragment uint8, b []byte) []byte {
+		return append([]byte{serviceID, 0x0, fragment<<6 | 0xf, uint8(len(b) >> 8), uint8(len(b))}, b...)
+	}
+
+	// Complete cells of other services are ignored
+	p := &metadataTrackerPID{serviceID: 1}
+	assert.Equal(t, tag, p.payload(&PESData{Data: cells(cell(0, metadataAUCellFragmentComplete, []byte{0x1}), cell(1, metadataAUCellFragmentComplete, tag)), Header: &PESHeader{StreamID: StreamIDMetadataStream}}))
+
+	// Fragmented cells
+	assert.Empty(t, p.payload(&PESData{Data: cell(1, metadataAUCellFragmentFirst, tag[:10]), Header: &PESHeader{StreamID: StreamIDMetadataStream}}))
+	assert.Empty(t, p.payload(&PESData{Data: cell(1, 0, tag[10:20]), Header: &PESHeader{StreamID: StreamIDMetadataStream}}))
+	assert.Equal(t, tag, p.payload(&PESData{Data: cell(1, metadataAUCellFragmentLast, tag[20:]), Header: &PESHeader{StreamID: StreamIDMetadataStream}}))
+
+	// Tags carried directly
+	assert.Equal(t, tag, p.payload(&PESData{Data: tag, Header: &PESHeader{StreamID: StreamIDPrivateStream1}}))
+}
+
+func TestMetadataTrackerSetProgram(t *testing.T) {
+	tr := newMetadataTracker()
+	tr.pids[0x100] = &metadataTrackerPID{buf: []byte{0x1}, formatIdentifier: MetadataFormatIdentifierID3}
+	tr.setProgram(&PMTData{ElementaryStreams: []*PMTElementaryStream{
+		{ElementaryPID: 0x100, ElementaryStreamDescriptors: []*Descriptor{{Metadata: &DescriptorMetadata{Format: MetadataFormatIdentifierField, FormatIdentifier: MetadataFormatIdentifierID3}}}, StreamType: StreamTypeMetadataPES},
+		{ElementaryPID: 0x101, ElementaryStreamDescriptors: []*Descriptor{{Metadata: &DescriptorMetadata{Format: MetadataFormatIdentifierField, FormatIdentifier: MetadataFormatIdentifierKLVA, ServiceID: 2}}}, StreamType: StreamTypeMetadataPES},
+		{ElementaryPID: 0x102, ElementaryStreamDescriptors: []*Descriptor{{Registration: &DescriptorRegistration{FormatIdentifier: RegistrationFormatIdentifierKLVA}}}, StreamType: StreamTypeMPEG2PacketizedData},
+		{ElementaryPID: 0x103, ElementaryStreamDescriptors: []*Descriptor{{Registration: &DescriptorRegistration{FormatIdentifier: RegistrationFormatIdentifierVANC}}}, StreamType: StreamTypeMPEG2PacketizedData},
+		{ElementaryPID: 0x104, StreamType: StreamTypeMetadataPES},
+	}})
+	assert.Equal(t, map[uint16]*metadataTrackerPID{
+		0x100: {buf: []byte{0x1}, formatIdentifier: MetadataFormatIdentifierID3},
+		0x101: {formatIdentifier: MetadataFormatIdentifierKLVA, serviceID: 2},
+		0x102: {formatIdentifier: MetadataFormatIdentifierKLVA},
+	}, tr.pids)
+}
@@ -0,0 +1,482 @@
+package astits
+
+import (
+	"sort"
+	"time"
+
+	"github.com/asticode/go-astilog"
+)
+
+// Monitor indicators
+// Page: 20 | Chapter: 5.2 | Link: https://www.etsi.org/deliver/etsi_tr/101200_101299/101290/01.03.01_60/tr_101290v010301p.pdf
+const (
+	MonitorIndicatorContinuityCountError           = "Continuity_count_error"
+	MonitorIndicatorCRCError                       = "CRC_error"
+	MonitorIndicatorPATError2                      = "PAT_error_2"
+	MonitorIndicatorPCRAccuracyError               = "PCR_accuracy_error"
+	MonitorIndicatorPCRDiscontinuityIndicatorError = "PCR_discontinuity_indicator_error"
+	MonitorIndicatorPCRRepetitionError             = "PCR_repetition_error"
+	MonitorIndicatorPIDError                       = "PID_error"
+	MonitorIndicatorPMTError2                      = "PMT_error_2"
+	MonitorIndicatorPTSError                       = "PTS_error"
+	MonitorIndicatorSyncByteError                  = "Sync_byte_error"
+	MonitorIndicatorTSSyncLoss                     = "TS_sync_loss"
+)
+
+// Monitor parameters
+const (
+	monitorDefaultPIDTimeout      = 5 * time.Second
+	monitorPATTimeout             = 500 * time.Millisecond
+	monitorPCRAccuracy            = 500 * time.Nanosecond
+	monitorPCRDiscontinuityPeriod = 100 * time.Millisecond
+	monitorPCRRepetitionPeriod    = 40 * time.Millisecond
+	monitorPCRWrap                = clockReferenceBaseWrap * 300
+	monitorPMTTimeout             = 500 * time.Millisecond
+	monitorPTSRepetitionPeriod    = 700 * time.Millisecond
+	monitorSyncAcquisitionBytes   = 5 // Number of consecutive correct sync bytes needed to acquire sync
+	monitorSyncLossBytes          = 2 // Number of consecutive corrupted sync bytes after which sync is lost
+	monitorTableIDPAT             = 0x0
+	monitorTableIDPMT             = 0x2
+)
+
+// Monitor priorities indexed by indicator
+var monitorIndicatorPriorities = map[string]int{
+	MonitorIndicatorContinuityCountError:           1,
+	MonitorIndicatorCRCError:                       2,
+	MonitorIndicatorPATError2:                      1,
+	MonitorIndicatorPCRAccuracyError:               2,
+	MonitorIndicatorPCRDiscontinuityIndicatorError: 2,
+	MonitorIndicatorPCRRepetitionError:             2,
+	MonitorIndicatorPIDError:                       1,
+	MonitorIndicatorPMTError2:                      1,
+	MonitorIndicatorPTSError:                       2,
+	MonitorIndicatorSyncByteError:                  1,
+	MonitorIndicatorTSSyncLoss:                     1,
+}
+
+// Monitor CRC PIDs, carrying the PAT, the CAT, the NIT, the SDT, the BAT, the EIT and the TOT. PMT PIDs are added from
+// the PAT
+var monitorCRCPIDs = map[uint16]bool{
+	PIDPAT: true,
+	PIDCAT: true,
+	0x10:   true,
+	0x11:   true,
+	0x12:   true,
+	0x14:   true,
+}
+
+// MonitorEvent represents an error detected by the monitor
+type MonitorEvent struct {
+	Duration  time.Duration // Measured duration of timing errors, such as the interval between two PCRs or the PCR inaccuracy
+	Indicator string
+	Packet    *Packet // Packet that triggered the error, if any. Timeouts and sync errors are not attached to a packet
+	PID       uint16
+	Priority  int
+	Time      time.Time
+}
+
+// MonitorEventHandler represents an object capable of handling monitor events
+// Packets of events are only valid until the handler returns
+type MonitorEventHandler func(e *MonitorEvent)
+
+// Monitor represents an object capable of checking a transport stream against the measurements of ETSI TR 101 290,
+// which can back a broadcast monitoring probe
+// All first priority checks are implemented, as well as the CRC, PCR and PTS second priority checks
+// Page: 19 | Chapter: 5 | Link: https://www.etsi.org/deliver/etsi_tr/101200_101299/101290/01.03.01_60/tr_101290v010301p.pdf
+type Monitor struct {
+	continuityChecker *continuityChecker
+	counts            map[string]int64
+	inSync            bool
+	optEventHandler   MonitorEventHandler
+	optPIDTimeout     time.Duration
+	packetPool        *packetPool
+	pat               *monitorTimer
+	pcrs              map[uint16]*monitorPCR   // Indexed by PID carrying PCRs
+	pids              map[uint16]*monitorTimer // Elementary PIDs referred to in PMTs
+	pmts              map[uint16]*monitorTimer // PMT PIDs referred to in the PAT
+	position          int64                    // Number of bytes added
+	programMap        programMap
+	ptss              map[uint16]time.Time // Arrival time of the last PTS indexed by PID
+	syncBytes         int                  // Number of consecutive correct sync bytes if positive, corrupted sync bytes if negative
+}
+
+// monitorTimer keeps track of the last time something occurred
+type monitorTimer struct {
+	last time.Time
+}
+
+// monitorPCR keeps track of the PCRs of a PID
+type monitorPCR struct {
+	accuracy     time.Duration
+	jitter       time.Duration
+	last         int // In 27 MHz ticks
+	lastPosition int64
+	lastTime     time.Time
+	ticksPerByte float64 // Measured between the last two PCRs, 0 if unknown
+}
+
+// NewMonitor creates a new monitor
+func NewMonitor(opts ...func(*Monitor)) (m *Monitor) {
+	m = &Monitor{
+		continuityChecker: newContinuityChecker(),
+		counts:            make(map[string]int64),
+		optPIDTimeout:     monitorDefaultPIDTimeout,
+		packetPool:        newPacketPool(),
+		pcrs:              make(map[uint16]*monitorPCR),
+		pids:              make(map[uint16]*monitorTimer),
+		pmts:              make(map[uint16]*monitorTimer),
+		programMap:        newProgramMap(),
+		ptss:              make(map[uint16]time.Time),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return
+}
+
+// MonitorOptEventHandler returns the option to set the handler called for each error detected
+func MonitorOptEventHandler(h MonitorEventHandler) func(*Monitor) {
+	return func(m *Monitor) {
+		m.optEventHandler = h
+	}
+}
+
+// MonitorOptPIDTimeout returns the option to set the period after which a PID referred to in a PMT that doesn't occur
+// triggers a PID_error. It defaults to 5 seconds
+func MonitorOptPIDTimeout(d time.Duration) func(*Monitor) {
+	return func(m *Monitor) {
+		m.optPIDTimeout = d
+	}
+}
+
+// Count returns the number of errors detected for an indicator
+func (m *Monitor) Count(indicator string) int64 {
+	return m.counts[indicator]
+}
+
+// Add checks a packet of the stream received at a given time
+// Packets must be added in order, unaligned or not, one packet size at a time. Live probes will most likely use the
+// arrival time of packets whereas file analyzers may derive it from the position of packets and the stream bitrate
+func (m *Monitor) Add(b []byte, t time.Time) {
+	// Update position
+	defer func() { m.position += int64(len(b)) }()
+
+	// Check sync
+	if !m.checkSync(b, t) {
+		return
+	}
+
+	// Parse packet
+	p, err := parsePacket(b)
+	if err != nil {
+		return
+	}
+
+	// Check packet
+	m.checkPSI(p, t)
+	m.checkSections(p, t)
+	m.checkContinuity(p, t)
+	m.checkPCR(p, t)
+	m.checkPTS(p, t)
+	if tm, ok := m.pids[p.Header.PID]; ok {
+		tm.last = t
+	}
+
+	// Check timeouts
+	m.checkTimeouts(t)
+}
+
+// checkSync checks the sync byte of a packet and returns whether the packet should be processed
+func (m *Monitor) checkSync(b []byte, t time.Time) bool {
+	// Get sync byte
+	var s byte
+	if len(b) == M2TSPacketSize {
+		s = b[4]
+	} else if len(b) > 0 {
+		s = b[0]
+	}
+
+	// Correct sync byte
+	if s == syncByte {
+		if m.syncBytes < 0 {
+			m.syncBytes = 0
+		}
+		m.syncBytes++
+		if !m.inSync && m.syncBytes >= monitorSyncAcquisitionBytes {
+			m.inSync = true
+			m.resetTimers(t)
+		}
+		return m.inSync
+	}
+
+	// Corrupted sync byte
+	if m.syncBytes > 0 {
+		m.syncBytes = 0
+	}
+	m.syncBytes--
+	if !m.inSync {
+		return false
+	}
+	m.emit(&MonitorEvent{Indicator: MonitorIndicatorSyncByteError, Time: t})
+	if -m.syncBytes >= monitorSyncLossBytes {
+		m.inSync = false
+		m.emit(&MonitorEvent{Indicator: MonitorIndicatorTSSyncLoss, Time: t})
+	}
+	return false
+}
+
+// resetTimers resets the timers once sync is acquired since nothing can be received while sync is lost
+func (m *Monitor) resetTimers(t time.Time) {
+	m.pat = &monitorTimer{last: t}
+	for _, tms := range []map[uint16]*monitorTimer{m.pids, m.pmts} {
+		for _, tm := range tms {
+			tm.last = t
+		}
+	}
+}
+
+// checkContinuity checks the continuity counter of a packet
+func (m *Monitor) checkContinuity(p *Packet, t time.Time) {
+	if e := m.continuityChecker.check(p); e != nil && e.Type == PacketEventTypeContinuityError {
+		m.emit(&MonitorEvent{Indicator: MonitorIndicatorContinuityCountError, Packet: p, PID: p.Header.PID, Time: t})
+	}
+}
+
+// checkPSI checks a packet of the PAT or of a PMT
+func (m *Monitor) checkPSI(p *Packet, t time.Time) {
+	// Get timer
+	var indicator string
+	var tableID int
+	var tm *monitorTimer
+	if p.Header.PID == PIDPAT {
+		indicator, tableID, tm = MonitorIndicatorPATError2, monitorTableIDPAT, m.pat
+	} else if v, ok := m.pmts[p.Header.PID]; ok {
+		indicator, tableID, tm = MonitorIndicatorPMTError2, monitorTableIDPMT, v
+	} else {
+		return
+	}
+
+	// Check scrambling
+	if p.Header.TransportScramblingControl != ScramblingControlNotScrambled {
+		m.emit(&MonitorEvent{Indicator: indicator, Packet: p, PID: p.Header.PID, Time: t})
+		return
+	}
+
+	// Check table ID of the first section starting in the packet
+	if p.Header.PayloadUnitStartIndicator && len(p.Payload) > 0 && int(p.Payload[0])+1 < len(p.Payload) {
+		if int(p.Payload[int(p.Payload[0])+1]) == tableID {
+			tm.last = t
+		} else if p.Header.PID == PIDPAT {
+			m.emit(&MonitorEvent{Indicator: indicator, Packet: p, PID: p.Header.PID, Time: t})
+		}
+	}
+}
+
+// checkSections checks the CRC32 of the sections of the tables, and updates the PIDs the monitor is watching with the
+// PAT and the PMTs
+func (m *Monitor) checkSections(p *Packet, t time.Time) {
+	// Check PID
+	var _, isPMT = m.pmts[p.Header.PID]
+	if !isPMT && !monitorCRCPIDs[p.Header.PID] {
+		return
+	}
+
+	// Reassemble payload
+	if p.Header.TransportErrorIndicator || p.Header.TransportScramblingControl != ScramblingControlNotScrambled {
+		return
+	}
+	var ps []*Packet
+	if ps = m.packetPool.add(p.Clone()); len(ps) == 0 {
+		return
+	}
+
+	// Check CRC32
+	for _, s := range monitorSections(ps) {
+		if hasCRC32(psiTableTypeOnPID(int(s[0]), p.Header.PID)) && computeCRC32(s[:len(s)-4]) != parseCRC32(s) {
+			m.emit(&MonitorEvent{Indicator: MonitorIndicatorCRCError, Packet: ps[0], PID: p.Header.PID, Time: t})
+			return
+		}
+	}
+
+	// Parse tables
+	if !isPMT && p.Header.PID != PIDPAT {
+		return
+	}
+	ds, err := parseData(ps, nil, m.programMap, newProgramMap(), nil, CRCModeDrop, astilog.NopLogger())
+	if err != nil {
+		return
+	}
+	for _, d := range ds {
+		if d.PAT != nil {
+			for _, pgm := range d.PAT.Programs {
+				// Program number 0 is reserved to NIT
+				if pgm.ProgramNumber > 0 {
+					m.programMap.set(pgm.ProgramMapID, pgm.ProgramNumber)
+					if _, ok := m.pmts[pgm.ProgramMapID]; !ok {
+						m.pmts[pgm.ProgramMapID] = &monitorTimer{last: t}
+					}
+				}
+			}
+		}
+		if d.PMT != nil {
+			for _, es := range d.PMT.ElementaryStreams {
+				if _, ok := m.pids[es.ElementaryPID]; !ok {
+					m.pids[es.ElementaryPID] = &monitorTimer{last: t}
+				}
+			}
+		}
+	}
+}
+
+// monitorSections splits the payload of a set of packets into complete sections
+func monitorSections(ps []*Packet) (ss [][]byte) {
+	// Reconstruct payload
+	var i []byte
+	for _, p := range ps {
+		i = append(i, p.Payload...)
+	}
+
+	// Pointer field
+	if len(i) == 0 {
+		return
+	}
+	var offset = 1 + int(i[0])
+
+	// Loop through sections
+	for offset+3 <= len(i) && i[offset] != 0xff {
+		var offsetEnd = offset + 3 + int(uint16(i[offset+1]&0xf)<<8|uint16(i[offset+2]))
+		if offsetEnd > len(i) || offsetEnd-offset < 7 {
+			return
+		}
+		ss = append(ss, i[offset:offsetEnd])
+		offset = offsetEnd
+	}
+	return
+}
+
+// checkPCR checks the PCR of a packet, if any
+// Page: 29 | Chapter: 5.2.2 | Link: https://www.etsi.org/deliver/etsi_tr/101200_101299/101290/01.03.01_60/tr_101290v010301p.pdf
+func (m *Monitor) checkPCR(p *Packet, t time.Time) {
+	// Check PCR
+	if !p.Header.HasAdaptationField || p.AdaptationField == nil || !p.AdaptationField.HasPCR || p.AdaptationField.PCR == nil {
+		return
+	}
+	var c = p.AdaptationField.PCR.Ticks27MHz()
+
+	// First PCR or discontinuity
+	var s, ok = m.pcrs[p.Header.PID]
+	if !ok || p.AdaptationField.DiscontinuityIndicator {
+		m.pcrs[p.Header.PID] = &monitorPCR{last: c, lastPosition: m.position, lastTime: t}
+		return
+	}
+
+	// Check repetition
+	var interval = t.Sub(s.lastTime)
+	if interval > monitorPCRRepetitionPeriod {
+		m.emit(&MonitorEvent{Duration: interval, Indicator: MonitorIndicatorPCRRepetitionError, Packet: p, PID: p.Header.PID, Time: t})
+	}
+
+	// Check discontinuity
+	var d = (c - s.last) % monitorPCRWrap
+	if d > monitorPCRWrap/2 {
+		d -= monitorPCRWrap
+	} else if d < -monitorPCRWrap/2 {
+		d += monitorPCRWrap
+	}
+	var delta = ticks27MHzToDuration(d)
+	if delta < 0 || delta > monitorPCRDiscontinuityPeriod {
+		m.emit(&MonitorEvent{Duration: delta, Indicator: MonitorIndicatorPCRDiscontinuityIndicatorError, Packet: p, PID: p.Header.PID, Time: t})
+		m.pcrs[p.Header.PID] = &monitorPCR{last: c, lastPosition: m.position, lastTime: t}
+		return
+	}
+
+	// Measure jitter
+	s.jitter = delta - interval
+
+	// Check accuracy by comparing the PCR to the one interpolated with the bitrate measured between the previous PCRs
+	var bytes = float64(m.position - s.lastPosition)
+	if s.ticksPerByte > 0 {
+		s.accuracy = ticks27MHzToDuration(d - int(bytes*s.ticksPerByte+0.5))
+		if s.accuracy > monitorPCRAccuracy || s.accuracy < -monitorPCRAccuracy {
+			m.emit(&MonitorEvent{Duration: s.accuracy, Indicator: MonitorIndicatorPCRAccuracyError, Packet: p, PID: p.Header.PID, Time: t})
+		}
+	}
+	if bytes > 0 {
+		s.ticksPerByte = float64(d) / bytes
+	}
+
+	// Update
+	s.last = c
+	s.lastPosition = m.position
+	s.lastTime = t
+}
+
+// checkPTS checks the PTS of a packet starting a PES, if any
+func (m *Monitor) checkPTS(p *Packet, t time.Time) {
+	// Check PTS
+	var i = p.Payload
+	if !p.Header.PayloadUnitStartIndicator || len(i) < 9 || !isPESPayload(i) || !hasPESOptionalHeader(i[3]) || i[7]>>6&PTSDTSIndicatorOnlyPTS == 0 {
+		return
+	}
+
+	// Check repetition
+	if last, ok := m.ptss[p.Header.PID]; ok {
+		if interval := t.Sub(last); interval > monitorPTSRepetitionPeriod {
+			m.emit(&MonitorEvent{Duration: interval, Indicator: MonitorIndicatorPTSError, Packet: p, PID: p.Header.PID, Time: t})
+		}
+	}
+	m.ptss[p.Header.PID] = t
+}
+
+// PCRAccuracy returns the last PCR accuracy measured on a PID, that is the difference between its last PCR and the one
+// expected from the bitrate measured between its previous PCRs
+func (m *Monitor) PCRAccuracy(pid uint16) time.Duration {
+	if s, ok := m.pcrs[pid]; ok {
+		return s.accuracy
+	}
+	return 0
+}
+
+// PCRJitter returns the last PCR jitter measured on a PID, that is the difference between the interval of its last two
+// PCRs and the interval of their arrival times
+func (m *Monitor) PCRJitter(pid uint16) time.Duration {
+	if s, ok := m.pcrs[pid]; ok {
+		return s.jitter
+	}
+	return 0
+}
+
+// checkTimeouts checks whether the PAT, the PMTs and the elementary PIDs occurred recently enough
+// Once an error has been reported, the timer is reset so that it is reported again if nothing occurs for another period
+func (m *Monitor) checkTimeouts(t time.Time) {
+	if m.pat != nil && t.Sub(m.pat.last) > monitorPATTimeout {
+		m.pat.last = t
+		m.emit(&MonitorEvent{Indicator: MonitorIndicatorPATError2, PID: PIDPAT, Time: t})
+	}
+	m.checkTimers(m.pmts, monitorPMTTimeout, MonitorIndicatorPMTError2, t)
+	m.checkTimers(m.pids, m.optPIDTimeout, MonitorIndicatorPIDError, t)
+}
+
+// checkTimers checks a set of timers indexed by PID, in PID order
+func (m *Monitor) checkTimers(tms map[uint16]*monitorTimer, timeout time.Duration, indicator string, t time.Time) {
+	var pids []int
+	for pid, tm := range tms {
+		if t.Sub(tm.last) > timeout {
+			pids = append(pids, int(pid))
+		}
+	}
+	sort.Ints(pids)
+	for _, pid := range pids {
+		tms[uint16(pid)].last = t
+		m.emit(&MonitorEvent{Indicator: indicator, PID: uint16(pid), Time: t})
+	}
+}
+
+// emit counts an error and sends it to the event handler
+func (m *Monitor) emit(e *MonitorEvent) {
+	e.Priority = monitorIndicatorPriorities[e.Indicator]
+	m.counts[e.Indicator]++
+	if m.optEventHandler != nil {
+		m.optEventHandler(e)
+	}
+}
@@ -0,0 +1,207 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type monitorTest struct {
+	es []*MonitorEvent
+	m  *Monitor
+	t  time.Time
+}
+
+func newMonitorTest(opts ...func(*Monitor)) (t *monitorTest) {
+	t = &monitorTest{t: time.Unix(0, 0)}
+	t.m = NewMonitor(append([]func(*Monitor){MonitorOptEventHandler(func(e *MonitorEvent) { t.es = append(t.es, e) })}, opts...)...)
+	return
+}
+
+func (t *monitorTest) add(b []byte, step time.Duration) {
+	for len(b) >= MpegTsPacketSize {
+		t.t = t.t.Add(step)
+		t.m.Add(b[:MpegTsPacketSize], t.t)
+		b = b[MpegTsPacketSize:]
+	}
+}
+
+func (t *monitorTest) indicators() (is []string) {
+	for _, e := range t.es {
+		is = append(is, e.Indicator)
+	}
+	t.es = nil
+	return
+}
+
+func TestMonitorSync(t *testing.T) {
+	// Init
+	mt := newMonitorTest()
+	good := writePacket(&Packet{Header: &PacketHeader{HasPayload: true, PID: PIDNull}, Payload: []byte("null")})
+	bad := append([]byte{0}, good[1:]...)
+
+	// Sync is not acquired yet
+	mt.add(bytes.Repeat(good, 4), 0)
+	mt.add(bad, 0)
+	assert.Empty(t, mt.indicators())
+
+	// Sync is acquired
+	mt.add(bytes.Repeat(good, 5), 0)
+	mt.add(bad, 0)
+	assert.Equal(t, []string{MonitorIndicatorSyncByteError}, mt.indicators())
+	mt.add(good, 0)
+	mt.add(bad, 0)
+	assert.Equal(t, []string{MonitorIndicatorSyncByteError}, mt.indicators())
+	mt.add(bad, 0)
+	assert.Equal(t, []string{MonitorIndicatorSyncByteError, MonitorIndicatorTSSyncLoss}, mt.indicators())
+
+	// Sync is lost
+	mt.add(bytes.Repeat(bad, 3), 0)
+	mt.add(bytes.Repeat(good, 4), 0)
+	mt.add(bad, 0)
+	assert.Empty(t, mt.indicators())
+	assert.Equal(t, int64(3), mt.m.Count(MonitorIndicatorSyncByteError))
+	assert.Equal(t, int64(1), mt.m.Count(MonitorIndicatorTSSyncLoss))
+}
+
+func TestMonitorTables(t *testing.T) {
+	// Init
+	mt := newMonitorTest(MonitorOptPIDTimeout(time.Second))
+	buf := &bytes.Buffer{}
+	mx := NewMuxer(context.Background(), buf)
+	mx.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x100, StreamType: StreamTypeLowerBitrateVideo})
+	mx.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x101, StreamType: StreamTypeMPEG1Audio})
+	write := func(pid uint16) []byte {
+		buf.Reset()
+		mx.WriteData(&MuxerData{PES: &PESData{Data: []byte("data"), Header: &PESHeader{OptionalHeader: &PESOptionalHeader{}, StreamID: 0xe0}}, PID: pid})
+		return buf.Bytes()
+	}
+	tables := func() []byte {
+		buf.Reset()
+		mx.WriteTables()
+		return buf.Bytes()
+	}
+
+	// Valid stream
+	for i := 0; i < 10; i++ {
+		mt.add(tables(), 10*time.Millisecond)
+		mt.add(write(0x100), 10*time.Millisecond)
+		mt.add(write(0x101), 10*time.Millisecond)
+	}
+	assert.Empty(t, mt.indicators())
+
+	// Tables are missing
+	for i := 0; i < 30; i++ {
+		mt.add(write(0x100), 10*time.Millisecond)
+		mt.add(write(0x101), 10*time.Millisecond)
+	}
+	assert.Equal(t, []string{MonitorIndicatorPATError2, MonitorIndicatorPMTError2}, mt.indicators())
+
+	// PID is missing
+	for i := 0; i < 40; i++ {
+		mt.add(tables(), 10*time.Millisecond)
+		mt.add(write(0x100), 10*time.Millisecond)
+	}
+	assert.Equal(t, uint16(0x101), mt.es[0].PID)
+	assert.Equal(t, []string{MonitorIndicatorPIDError}, mt.indicators())
+
+	// Packet is lost
+	write(0x100)
+	mt.add(write(0x100), 10*time.Millisecond)
+	assert.Equal(t, []string{MonitorIndicatorContinuityCountError}, mt.indicators())
+
+	// PAT has a wrong table ID
+	b := tables()
+	b[5] = 0x2
+	mt.add(b, 10*time.Millisecond)
+	assert.Equal(t, []string{MonitorIndicatorPATError2}, mt.indicators())
+
+	// PMT is scrambled, and the CRC32 of the previous PAT is checked once it's complete
+	b = tables()
+	b[MpegTsPacketSize+3] |= ScramblingControlScrambledWithEvenKey << 6
+	mt.add(b, 10*time.Millisecond)
+	assert.Equal(t, 2, mt.es[0].Priority)
+	assert.Equal(t, 1, mt.es[1].Priority)
+	assert.Equal(t, []string{MonitorIndicatorCRCError, MonitorIndicatorPMTError2}, mt.indicators())
+}
+
+func TestMonitorPCR(t *testing.T) {
+	// Init
+	mt := newMonitorTest()
+	var pcr int
+	write := func(step time.Duration, n int, discontinuity bool) {
+		for i := 0; i < n; i++ {
+			pcr += durationTo27MHzTicks(step)
+			mt.t = mt.t.Add(step)
+			mt.m.Add(writePacket(&Packet{
+				AdaptationField: &PacketAdaptationField{DiscontinuityIndicator: discontinuity, HasPCR: true, PCR: NewClockReference27MHz(pcr)},
+				Header:          &PacketHeader{HasAdaptationField: true, PID: 0x100},
+			}), mt.t)
+		}
+	}
+
+	// Valid PCRs
+	write(20*time.Millisecond, 10, false)
+	assert.Empty(t, mt.indicators())
+	assert.Equal(t, time.Duration(0), mt.m.PCRAccuracy(0x100))
+	assert.Equal(t, time.Duration(0), mt.m.PCRJitter(0x100))
+
+	// Repetition error
+	write(50*time.Millisecond, 1, false)
+	assert.Equal(t, 50*time.Millisecond, mt.es[0].Duration)
+	assert.Equal(t, []string{MonitorIndicatorPCRRepetitionError, MonitorIndicatorPCRAccuracyError}, mt.indicators())
+
+	// Bitrate is measured again
+	write(20*time.Millisecond, 2, false)
+	assert.Equal(t, []string{MonitorIndicatorPCRAccuracyError}, mt.indicators())
+
+	// Accuracy error and jitter
+	pcr += 27 * 10
+	write(20*time.Millisecond, 1, false)
+	assert.Equal(t, []string{MonitorIndicatorPCRAccuracyError}, mt.indicators())
+	assert.Equal(t, 10*time.Microsecond, mt.m.PCRAccuracy(0x100))
+	assert.Equal(t, 10*time.Microsecond, mt.m.PCRJitter(0x100))
+
+	// Discontinuity error
+	pcr += durationTo27MHzTicks(time.Second)
+	write(20*time.Millisecond, 1, false)
+	assert.Equal(t, []string{MonitorIndicatorPCRDiscontinuityIndicatorError}, mt.indicators())
+
+	// Discontinuity indicator
+	pcr += durationTo27MHzTicks(time.Second)
+	write(20*time.Millisecond, 1, true)
+	write(20*time.Millisecond, 3, false)
+	assert.Empty(t, mt.indicators())
+	assert.Equal(t, int64(1), mt.m.Count(MonitorIndicatorPCRRepetitionError))
+}
+
+func TestMonitorPTS(t *testing.T) {
+	// Init
+	mt := newMonitorTest()
+	p := NewPESPacketizer(0x100)
+	write := func(step time.Duration, pts bool) {
+		h := &PESOptionalHeader{}
+		if pts {
+			h.PTSDTSIndicator = PTSDTSIndicatorOnlyPTS
+			h.PTS = &ClockReference{}
+		}
+		mt.add(p.Packetize(&PESData{Data: []byte("data"), Header: &PESHeader{OptionalHeader: h, StreamID: 0xe0}}, nil), step)
+	}
+
+	// PTS error
+	for i := 0; i < 5; i++ {
+		write(100*time.Millisecond, true)
+	}
+	for i := 0; i < 7; i++ {
+		write(100*time.Millisecond, false)
+	}
+	write(100*time.Millisecond, true)
+	assert.Equal(t, int64(1), mt.m.Count(MonitorIndicatorPTSError))
+	e := mt.es[len(mt.es)-1]
+	assert.Equal(t, MonitorIndicatorPTSError, e.Indicator)
+	assert.Equal(t, 800*time.Millisecond, e.Duration)
+	assert.Equal(t, uint16(0x100), e.PID)
+}
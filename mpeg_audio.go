@@ -0,0 +1,181 @@
+package astits
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// MPEG audio layers
+const (
+	MPEGAudioLayerI   = 3
+	MPEGAudioLayerII  = 2
+	MPEGAudioLayerIII = 1
+)
+
+// MPEG audio modes
+const (
+	MPEGAudioModeDualChannel   = 2
+	MPEGAudioModeJointStereo   = 1
+	MPEGAudioModeSingleChannel = 3
+	MPEGAudioModeStereo        = 0
+)
+
+// MPEG audio versions
+const (
+	MPEGAudioVersion1  = 3 // ISO/IEC 11172-3
+	MPEGAudioVersion2  = 2 // ISO/IEC 13818-3 lower sampling frequencies
+	MPEGAudioVersion25 = 0 // Unofficial extension to even lower sampling frequencies
+)
+
+// MPEG audio bitrates in kbps indexed by version 1 or not, by layer and by bitrate index
+// Page: 21 | Chapter: 2.4.2.3 | Link: ISO/IEC 11172-3
+var mpegAudioBitrates = map[bool]map[uint8][]int{
+	true: {
+		MPEGAudioLayerI:   {0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+		MPEGAudioLayerII:  {0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+		MPEGAudioLayerIII: {0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+	},
+	false: {
+		MPEGAudioLayerI:   {0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+		MPEGAudioLayerII:  {0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+		MPEGAudioLayerIII: {0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	},
+}
+
+// MPEG audio sampling frequencies indexed by version and by sampling frequency index
+var mpegAudioSamplingFrequencies = map[uint8][]int{
+	MPEGAudioVersion1:  {44100, 48000, 32000},
+	MPEGAudioVersion2:  {22050, 24000, 16000},
+	MPEGAudioVersion25: {11025, 12000, 8000},
+}
+
+// MPEGAudioFrame represents an MPEG audio frame
+// Only the header is parsed
+// Page: 21 | Chapter: 2.4.1.3 | Link: ISO/IEC 11172-3
+type MPEGAudioFrame struct {
+	Bitrate           int // In bits per second
+	Data              []byte
+	Emphasis          uint8
+	FrameLength       int // In bytes, header included
+	HasCRC            bool
+	HasPadding        bool
+	IsCopyrighted     bool
+	IsOriginal        bool
+	Layer             uint8
+	Mode              uint8
+	ModeExtension     uint8
+	SamplingFrequency int // In Hz
+	Version           uint8
+}
+
+// ParseMPEGAudioFrames parses the MPEG audio frames of a byte stream, such as the payload of a PES of the
+// StreamTypeMPEG1Audio or StreamTypeMPEG2HalvedSampleRateAudio stream types
+// Free format frames, whose length can't be computed from their header, and other invalid frames are skipped up to the
+// next 11 bits frame sync. The bytes of the last frame, when it continues in the next PES, are returned in rest
+func ParseMPEGAudioFrames(i []byte) (fs []*MPEGAudioFrame, rest []byte) {
+	rest = walkAudioFrames(i, func(offset *int) (err error) {
+		var f *MPEGAudioFrame
+		if f, err = parseMPEGAudioFrame(i, offset); err != nil {
+			return
+		}
+		fs = append(fs, f)
+		return
+	})
+	return
+}
+
+// parseMPEGAudioFrame parses an MPEG audio frame
+func parseMPEGAudioFrame(i []byte, offset *int) (f *MPEGAudioFrame, err error) {
+	// Syncword
+	// A frame sync cut off by the end of the input only has its first byte checked
+	var offsetStart = *offset
+	if i[offsetStart] != 0xff || (offsetStart+1 < len(i) && i[offsetStart+1]&0xe0 != 0xe0) {
+		err = fmt.Errorf("astits: invalid mpeg audio syncword at offset %d", offsetStart)
+		return
+	}
+
+	// Check for incomplete header
+	if offsetStart+4 > len(i) {
+		err = errors.Wrapf(errTruncatedAudioFrame, "astits: mpeg audio header end (%d) > len(i) (%d)", offsetStart+4, len(i))
+		return
+	}
+
+	// Init
+	f = &MPEGAudioFrame{}
+
+	// Version
+	f.Version = uint8(i[*offset+1] >> 3 & 0x3)
+
+	// Layer
+	f.Layer = uint8(i[*offset+1] >> 1 & 0x3)
+
+	// Protection bit
+	f.HasCRC = i[*offset+1]&0x1 == 0
+	*offset += 2
+
+	// Bitrate
+	var bitrateIndex = int(i[*offset] >> 4)
+	if _, ok := mpegAudioSamplingFrequencies[f.Version]; !ok || f.Layer == 0 {
+		err = fmt.Errorf("astits: invalid mpeg audio version %d or layer %d", f.Version, f.Layer)
+		return
+	} else if bitrateIndex == 0 || bitrateIndex == 0xf {
+		err = fmt.Errorf("astits: unsupported mpeg audio bitrate index %d", bitrateIndex)
+		return
+	}
+	f.Bitrate = mpegAudioBitrates[f.Version == MPEGAudioVersion1][f.Layer][bitrateIndex] * 1000
+
+	// Sampling frequency
+	var samplingFrequencyIndex = int(i[*offset] >> 2 & 0x3)
+	if samplingFrequencyIndex == 3 {
+		err = fmt.Errorf("astits: invalid mpeg audio sampling frequency index %d", samplingFrequencyIndex)
+		return
+	}
+	f.SamplingFrequency = mpegAudioSamplingFrequencies[f.Version][samplingFrequencyIndex]
+
+	// Padding
+	f.HasPadding = i[*offset]&0x2 > 0
+	*offset += 1
+
+	// Mode
+	f.Mode = uint8(i[*offset] >> 6)
+
+	// Mode extension
+	f.ModeExtension = uint8(i[*offset] >> 4 & 0x3)
+
+	// Copyright
+	f.IsCopyrighted = i[*offset]&0x8 > 0
+
+	// Original
+	f.IsOriginal = i[*offset]&0x4 > 0
+
+	// Emphasis
+	f.Emphasis = uint8(i[*offset] & 0x3)
+	*offset += 1
+
+	// Frame length
+	var padding int
+	if f.HasPadding {
+		padding = 1
+	}
+	switch {
+	case f.Layer == MPEGAudioLayerI:
+		f.FrameLength = (12*f.Bitrate/f.SamplingFrequency + padding) * 4
+	case f.Layer == MPEGAudioLayerIII && f.Version != MPEGAudioVersion1:
+		f.FrameLength = 72*f.Bitrate/f.SamplingFrequency + padding
+	default:
+		f.FrameLength = 144*f.Bitrate/f.SamplingFrequency + padding
+	}
+
+	// Check frame length
+	var offsetEnd = offsetStart + f.FrameLength
+	if offsetEnd > len(i) {
+		err = errors.Wrapf(errTruncatedAudioFrame, "astits: mpeg audio frame end (%d) > len(i) (%d)", offsetEnd, len(i))
+		return
+	}
+
+	// Data
+	f.Data = i[offsetStart:offsetEnd]
+	*offset = offsetEnd
+	return
+}
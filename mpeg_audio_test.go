@@ -0,0 +1,57 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMPEGAudioFrames(t *testing.T) {
+	// Valid frames
+	// MPEG-1 layer II at 192 kbps and 48 kHz, then MPEG-2 layer II at 64 kbps, 24 kHz with padding
+	b := append([]byte{0xff, 0xfd, 0xa4, 0xc4}, make([]byte, 572)...)
+	b = append(b, 0xff, 0xf4, 0x86, 0x0)
+	b = append(b, make([]byte, 381)...)
+	fs, rest := ParseMPEGAudioFrames(b)
+	assert.Empty(t, rest)
+	assert.Equal(t, []*MPEGAudioFrame{
+		{
+			Bitrate:           192000,
+			Data:              b[:576],
+			FrameLength:       576,
+			IsOriginal:        true,
+			Layer:             MPEGAudioLayerII,
+			Mode:              MPEGAudioModeSingleChannel,
+			SamplingFrequency: 48000,
+			Version:           MPEGAudioVersion1,
+		},
+		{
+			Bitrate:           64000,
+			Data:              b[576:],
+			FrameLength:       385,
+			HasCRC:            true,
+			HasPadding:        true,
+			Layer:             MPEGAudioLayerII,
+			Mode:              MPEGAudioModeStereo,
+			SamplingFrequency: 24000,
+			Version:           MPEGAudioVersion2,
+		},
+	}, fs)
+
+	// Frame spanning 2 payloads
+	fs, rest = ParseMPEGAudioFrames(b[:600])
+	assert.Len(t, fs, 1)
+	assert.Equal(t, b[576:600], rest)
+	fs, rest = ParseMPEGAudioFrames(append(rest, b[600:]...))
+	assert.Empty(t, rest)
+	assert.Len(t, fs, 1)
+	assert.Equal(t, uint8(MPEGAudioVersion2), fs[0].Version)
+
+	// Invalid headers are skipped
+	fs, rest = ParseMPEGAudioFrames(append([]byte{0xff, 0x1d, 0xa4, 0xc4, 0xff, 0xfd, 0x4, 0xc4}, b...))
+	assert.Empty(t, rest)
+	assert.Len(t, fs, 2)
+	fs, rest = ParseMPEGAudioFrames([]byte{0x1, 0xff})
+	assert.Empty(t, fs)
+	assert.Equal(t, []byte{0xff}, rest)
+}
@@ -0,0 +1,387 @@
+package astits
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Default MPTS muxer values
+const (
+	mptsMuxerDefaultOriginalNetworkID = 1
+	mptsMuxerDefaultTransportStreamID = 1
+)
+
+// MPTSInput represents a single program transport stream multiplexed by an MPTS muxer
+type MPTSInput struct {
+	PIDs         map[uint16]uint16 // Output PIDs indexed by input PIDs. PIDs that are not in the map are kept as is
+	ProviderName string            // Announced in the SDT
+	Reader       io.Reader
+	ServiceName  string // Announced in the SDT. The service is not announced in the SDT when it's empty
+	ServiceType  uint8  // Announced in the SDT
+}
+
+// MPTSMuxer represents a software multiplexer merging several single program transport streams into a multi program
+// transport stream
+// Packets of each input are remapped with a PIDRemapper and inputs are interleaved according to their PCRs: packets
+// are always read from the input whose timeline is late compared to the others, up to its next PCR. The PAT
+// announcing the programs of all inputs and the SDT describing their services are regenerated, whereas input packets
+// of PIDs reserved to PSI and DVB SI, other than the PAT, and null packets are dropped since they would collide. The
+// NIT is regenerated as well when a network is set, unless it's provided as complete sections.
+type MPTSMuxer struct {
+	counters             map[uint16]uint8 // Indexed by PID, contains the next continuity counter of regenerated tables
+	ctx                  context.Context
+	inputs               []*mptsMuxerInput
+	network              *NITData // Network described in the regenerated NIT
+	nit                  [][]byte
+	optOriginalNetworkID uint16
+	optTablesInterval    int
+	optTransportStreamID uint16
+	packets              int // Number of packets written since the tables were last written
+	pat                  *PATData
+	pids                 map[uint16]int // Indexed by output PID, contains the index of the input owning it
+	sdt                  *SDTData
+	tablesVersion        uint8
+	w                    io.Writer
+}
+
+// mptsMuxerInput represents an input of an MPTS muxer
+type mptsMuxerInput struct {
+	MPTSInput
+	dmx       *Demuxer
+	done      bool
+	firstPCR  int
+	hasPCR    bool
+	pat       *PATData // Last PAT of the input taken into account in the regenerated tables
+	pcr       int      // Time elapsed since the first PCR of the input, in 27 MHz ticks
+	remapper  *PIDRemapper
+	unwrapper *ClockUnwrapper
+}
+
+// NewMPTSMuxer creates a new MPTS muxer based on a writer
+func NewMPTSMuxer(ctx context.Context, w io.Writer, opts ...func(*MPTSMuxer)) (m *MPTSMuxer) {
+	// Init
+	m = &MPTSMuxer{
+		counters:             make(map[uint16]uint8),
+		ctx:                  ctx,
+		optOriginalNetworkID: mptsMuxerDefaultOriginalNetworkID,
+		optTransportStreamID: mptsMuxerDefaultTransportStreamID,
+		pids:                 make(map[uint16]int),
+		w:                    w,
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(m)
+	}
+	return
+}
+
+// MPTSMuxerOptNetwork returns the option to regenerate a NIT describing the network and announcing the output
+// transport stream
+func MPTSMuxerOptNetwork(networkID uint16, name string) func(*MPTSMuxer) {
+	return func(m *MPTSMuxer) {
+		m.network = NewNITData(networkID, name)
+	}
+}
+
+// MPTSMuxerOptOriginalNetworkID returns the option to set the original network ID announced in the SDT
+func MPTSMuxerOptOriginalNetworkID(id uint16) func(*MPTSMuxer) {
+	return func(m *MPTSMuxer) {
+		m.optOriginalNetworkID = id
+	}
+}
+
+// MPTSMuxerOptTablesInterval returns the option to write the regenerated tables every time packets packets have been
+// written, on top of every time they change
+func MPTSMuxerOptTablesInterval(packets int) func(*MPTSMuxer) {
+	return func(m *MPTSMuxer) {
+		m.optTablesInterval = packets
+	}
+}
+
+// MPTSMuxerOptTransportStreamID returns the option to set the transport stream ID announced in the PAT and the SDT
+func MPTSMuxerOptTransportStreamID(id uint16) func(*MPTSMuxer) {
+	return func(m *MPTSMuxer) {
+		m.optTransportStreamID = id
+	}
+}
+
+// AddInput adds a single program transport stream to the multiplex
+func (m *MPTSMuxer) AddInput(i MPTSInput) {
+	// Drop PIDs reserved to PSI and DVB SI as well as null packets
+	var dropPIDs = map[uint16]bool{PIDNull: true}
+	for pid := uint16(PIDPAT + 1); pid < 0x20; pid++ {
+		dropPIDs[pid] = true
+	}
+
+	// PIDs
+	if i.PIDs == nil {
+		i.PIDs = make(map[uint16]uint16)
+	}
+
+	// Add input
+	var in = &mptsMuxerInput{
+		MPTSInput: i,
+		dmx:       New(m.ctx, i.Reader),
+		remapper:  newPIDRemapper(i.PIDs, dropPIDs),
+		unwrapper: NewClockUnwrapper(),
+	}
+	in.remapper.skipPAT = true
+	m.inputs = append(m.inputs, in)
+}
+
+// SetNIT sets the sections of the NIT written along with the regenerated tables
+// Sections must be complete sections, CRC32 included, and take precedence over the NIT regenerated when a network is
+// set. The NIT is announced in the PAT as soon as it's set
+func (m *MPTSMuxer) SetNIT(sections [][]byte) {
+	m.nit = sections
+	m.pat = nil
+}
+
+// Mux multiplexes inputs until there are no more packets to read in any of them
+func (m *MPTSMuxer) Mux() (n int64, err error) {
+	for {
+		// Check ctx error
+		if err = m.ctx.Err(); err != nil {
+			return
+		}
+
+		// Get the input whose timeline is late
+		var idx = -1
+		for k, i := range m.inputs {
+			if !i.done && (idx < 0 || i.pcr < m.inputs[idx].pcr) {
+				idx = k
+			}
+		}
+
+		// No more packets
+		if idx < 0 {
+			return
+		}
+
+		// Mux input
+		var nn int
+		if nn, err = m.muxInput(idx); err != nil {
+			err = errors.Wrapf(err, "astits: muxing input %d failed", idx)
+			return
+		}
+		n += int64(nn)
+	}
+}
+
+// muxInput writes the packets of an input up to its next PCR
+func (m *MPTSMuxer) muxInput(idx int) (n int, err error) {
+	var i = m.inputs[idx]
+	for {
+		// Fetch next packet
+		var p *Packet
+		if p, err = i.dmx.NextPacket(); err != nil {
+			if err == ErrNoMorePackets {
+				err = nil
+				i.done = true
+				return
+			}
+			err = errors.Wrap(err, "astits: fetching next packet failed")
+			return
+		}
+
+		// Remap packet
+		var b []byte
+		if b, err = i.remapper.Remap(p); err != nil {
+			err = errors.Wrap(err, "astits: remapping packet failed")
+			return
+		}
+
+		// Write tables
+		var nn int
+		if nn, err = m.writeTables(); err != nil {
+			err = errors.Wrap(err, "astits: writing tables failed")
+			return
+		}
+		n += nn
+
+		// Write packets
+		if len(b) > 0 {
+			// Check PID
+			var pid, _ = rawPacketPID(b)
+			if owner, ok := m.pids[pid]; ok && owner != idx {
+				err = errors.Wrapf(ErrPIDAlreadyExists, "astits: PID %d is already used by input %d", pid, owner)
+				return
+			}
+			m.pids[pid] = idx
+
+			// Write
+			if nn, err = m.w.Write(b); err != nil {
+				err = errors.Wrapf(err, "astits: writing packets of PID %d failed", pid)
+				return
+			}
+			n += nn
+			m.packets += len(b) / MpegTsPacketSize
+		}
+
+		// Update timeline
+		if p.Header.HasAdaptationField && p.AdaptationField != nil && p.AdaptationField.HasPCR && p.AdaptationField.PCR != nil {
+			var c = i.unwrapper.Unwrap(p.AdaptationField.PCR).Ticks27MHz()
+			if !i.hasPCR {
+				i.firstPCR = c
+				i.hasPCR = true
+			}
+			i.pcr = c - i.firstPCR
+			return
+		}
+	}
+}
+
+// writeTables writes the regenerated tables if they have changed or if the tables interval has elapsed
+func (m *MPTSMuxer) writeTables() (n int, err error) {
+	// Check whether input PATs have changed
+	var hasPAT bool
+	for _, i := range m.inputs {
+		if i.remapper.pat != i.pat {
+			if !samePATPrograms(i.pat, i.remapper.pat) {
+				m.pat = nil
+			}
+			i.pat = i.remapper.pat
+		}
+		if i.pat != nil {
+			hasPAT = true
+		}
+	}
+
+	// Tables are only written once there's something to announce
+	if !hasPAT && len(m.nit) == 0 && m.network == nil {
+		return
+	}
+
+	// Regenerate tables
+	if m.pat == nil {
+		if err = m.regenerateTables(); err != nil {
+			err = errors.Wrap(err, "astits: regenerating tables failed")
+			return
+		}
+	} else if m.optTablesInterval <= 0 || m.packets < m.optTablesInterval {
+		return
+	}
+
+	// Serialize NIT
+	var ns = m.nit
+	if len(ns) == 0 && m.network != nil {
+		if ns, err = m.network.Serialize(m.tablesVersion); err != nil {
+			err = errors.Wrap(err, "astits: serializing NIT failed")
+			return
+		}
+	}
+
+	// Serialize PAT
+	var ps [][]byte
+	if ps, err = m.pat.Serialize(m.tablesVersion); err != nil {
+		err = errors.Wrap(err, "astits: serializing PAT failed")
+		return
+	}
+
+	// Serialize SDT
+	var ss [][]byte
+	if len(m.sdt.Services) > 0 {
+		if ss, err = m.sdt.Serialize(m.tablesVersion); err != nil {
+			err = errors.Wrap(err, "astits: serializing SDT failed")
+			return
+		}
+	}
+
+	// Loop through tables
+	for _, t := range []struct {
+		pid      uint16
+		sections [][]byte
+	}{
+		{pid: PIDPAT, sections: ps},
+		{pid: PIDNIT, sections: ns},
+		{pid: PIDSDT, sections: ss},
+	} {
+		// No sections
+		if len(t.sections) == 0 {
+			continue
+		}
+
+		// Write packets
+		var cc = m.counters[t.pid]
+		for _, p := range packetizePayload(t.pid, writePSIPayload(t.sections), nil, true, &cc) {
+			var nn int
+			if nn, err = m.w.Write(writePacket(p)); err != nil {
+				err = errors.Wrapf(err, "astits: writing packet of PID %d failed", t.pid)
+				return
+			}
+			n += nn
+		}
+		m.counters[t.pid] = cc
+	}
+	m.packets = 0
+	return
+}
+
+// regenerateTables regenerates the PAT, the NIT and the SDT based on the PATs of the inputs and increments their version
+func (m *MPTSMuxer) regenerateTables() (err error) {
+	// Init
+	var programs = make(map[uint16]bool)
+	var first = m.sdt == nil
+	m.pat = &PATData{TransportStreamID: m.optTransportStreamID}
+	m.sdt = &SDTData{OriginalNetworkID: m.optOriginalNetworkID, TransportStreamID: m.optTransportStreamID}
+
+	// NIT
+	if m.network != nil {
+		m.network.TransportStreams = []*NITDataTransportStream{{OriginalNetworkID: m.optOriginalNetworkID, TransportStreamID: m.optTransportStreamID}}
+	}
+	if len(m.nit) > 0 || m.network != nil {
+		m.pat.Programs = append(m.pat.Programs, &PATProgram{ProgramMapID: PIDNIT})
+	}
+
+	// Loop through inputs
+	for idx, i := range m.inputs {
+		// PAT has not been received yet
+		if i.pat == nil {
+			continue
+		}
+
+		// Loop through programs
+		for _, pgm := range i.pat.Programs {
+			// Program number 0 is reserved to NIT
+			if pgm.ProgramNumber == 0 {
+				continue
+			}
+
+			// Check program number
+			if programs[pgm.ProgramNumber] {
+				err = fmt.Errorf("astits: program number %d of input %d already exists", pgm.ProgramNumber, idx)
+				return
+			}
+			programs[pgm.ProgramNumber] = true
+
+			// Add program
+			m.pat.Programs = append(m.pat.Programs, &PATProgram{ProgramMapID: pgm.ProgramMapID, ProgramNumber: pgm.ProgramNumber})
+			if i.ServiceName != "" {
+				m.sdt.Services = append(m.sdt.Services, NewSDTDataService(pgm.ProgramNumber, i.ServiceType, i.ProviderName, i.ServiceName))
+			}
+		}
+	}
+
+	// Increment version
+	if !first {
+		m.tablesVersion = (m.tablesVersion + 1) % 32
+	}
+	return
+}
+
+// samePATPrograms checks whether 2 PATs announce the same programs
+func samePATPrograms(a, b *PATData) bool {
+	if a == nil || b == nil || len(a.Programs) != len(b.Programs) {
+		return a == b
+	}
+	for idx, p := range a.Programs {
+		if *p != *b.Programs[idx] {
+			return false
+		}
+	}
+	return true
+}
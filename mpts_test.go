@@ -0,0 +1,108 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mptsTestInput(programNumber uint16, step time.Duration) []byte {
+	buf := &bytes.Buffer{}
+	mx := NewMuxer(context.Background(), buf, MuxerOptProgramNumber(programNumber))
+	mx.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x100, StreamType: StreamTypeMPEG1Audio})
+	for idx := 0; idx < 5; idx++ {
+		mx.WriteData(&MuxerData{
+			AdaptationField: &PacketAdaptationField{HasPCR: true, PCR: NewClockReference27MHz(durationTo27MHzTicks(time.Duration(idx) * step))},
+			PES:             &PESData{Data: []byte{uint8(programNumber)}, Header: &PESHeader{OptionalHeader: &PESOptionalHeader{}, StreamID: 0xc0}},
+			PID:             0x100,
+		})
+	}
+	return buf.Bytes()
+}
+
+func TestMPTSMuxer(t *testing.T) {
+	// Mux
+	w := &bytes.Buffer{}
+	m := NewMPTSMuxer(context.Background(), w, MPTSMuxerOptTablesInterval(4), MPTSMuxerOptTransportStreamID(2))
+	m.AddInput(MPTSInput{ProviderName: "provider", Reader: bytes.NewReader(mptsTestInput(1, 40*time.Millisecond)), ServiceName: "first", ServiceType: ServiceTypeDigitalTelevisionService})
+	m.AddInput(MPTSInput{PIDs: map[uint16]uint16{0x100: 0x200, 0x1000: 0x1100}, Reader: bytes.NewReader(mptsTestInput(2, 20*time.Millisecond)), ServiceName: "second"})
+	_, err := m.Mux()
+	assert.NoError(t, err)
+
+	// Demux
+	dmx := New(context.Background(), bytes.NewReader(w.Bytes()))
+	var pat *PATData
+	var sdt *SDTData
+	var data []uint8
+	for {
+		d, err := dmx.NextData()
+		if err != nil {
+			assert.Equal(t, ErrNoMorePackets, err)
+			break
+		}
+		switch {
+		case d.PAT != nil:
+			pat = d.PAT
+		case d.SDT != nil:
+			sdt = d.SDT
+		case d.PES != nil:
+			data = append(data, d.PES.Data...)
+		}
+	}
+	assert.Equal(t, &PATData{Programs: []*PATProgram{{ProgramMapID: 0x1000, ProgramNumber: 1}, {ProgramMapID: 0x1100, ProgramNumber: 2}}, TransportStreamID: 2}, pat)
+	assert.Len(t, sdt.Services, 2)
+	assert.Equal(t, uint16(2), sdt.Services[1].ServiceID)
+	assert.Equal(t, []byte("second"), sdt.Services[1].Descriptors[0].Service.Name)
+	assert.Equal(t, []uint8{1, 2, 2, 1, 2, 2, 1, 1, 1, 2}, data)
+	assert.Equal(t, int64(0), dmx.ContinuityErrors())
+
+	// PIDs collide
+	m = NewMPTSMuxer(context.Background(), &bytes.Buffer{})
+	m.AddInput(MPTSInput{Reader: bytes.NewReader(mptsTestInput(1, 40*time.Millisecond))})
+	m.AddInput(MPTSInput{Reader: bytes.NewReader(mptsTestInput(2, 40*time.Millisecond))})
+	_, err = m.Mux()
+	assert.Error(t, err)
+
+	// Null packets and regenerated NIT
+	w.Reset()
+	m = NewMPTSMuxer(context.Background(), w, MPTSMuxerOptNetwork(3, "network"), MPTSMuxerOptOriginalNetworkID(4), MPTSMuxerOptTablesInterval(4))
+	for _, pn := range []uint16{1, 2} {
+		b := append(writePacket(&Packet{Header: &PacketHeader{HasPayload: true, PID: PIDNull}, Payload: make([]byte, 184)}), mptsTestInput(pn, 40*time.Millisecond)...)
+		m.AddInput(MPTSInput{PIDs: map[uint16]uint16{0x100: 0x100 * (pn + 1), 0x1000: 0x1000 + pn}, Reader: bytes.NewReader(b)})
+	}
+	_, err = m.Mux()
+	assert.NoError(t, err)
+	dmx = New(context.Background(), bytes.NewReader(w.Bytes()), OptPacketSize(MpegTsPacketSize))
+	var nit *NITData
+	for {
+		p, err := dmx.NextPacket()
+		if err != nil {
+			assert.Equal(t, ErrNoMorePackets, err)
+			break
+		}
+		assert.NotEqual(t, uint16(PIDNull), p.Header.PID)
+	}
+	dmx = New(context.Background(), bytes.NewReader(w.Bytes()))
+	for {
+		d, err := dmx.NextData()
+		if err != nil {
+			break
+		}
+		switch {
+		case d.NIT != nil:
+			nit = d.NIT
+		case d.PAT != nil:
+			pat = d.PAT
+		}
+	}
+	assert.Equal(t, &PATProgram{ProgramMapID: PIDNIT}, pat.Programs[0])
+	assert.Len(t, pat.Programs, 3)
+	if assert.NotNil(t, nit) {
+		assert.Equal(t, uint16(3), nit.NetworkID)
+		assert.Equal(t, []byte("network"), nit.NetworkDescriptors[0].NetworkName.Name)
+		assert.Equal(t, []*NITDataTransportStream{{OriginalNetworkID: 4, TransportStreamID: 1}}, nit.TransportStreams)
+	}
+}
@@ -0,0 +1,468 @@
+package astits
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Default muxer values
+const (
+	muxerDefaultPMTPID            = 0x1000
+	muxerDefaultProgramNumber     = 1
+	muxerDefaultTransportStreamID = 1
+)
+
+// Errors
+var (
+	ErrPIDAlreadyExists = errors.New("astits: PID already exists")
+)
+
+// Muxer represents a muxer
+// It writes elementary streams data as well as the PAT, the PMT describing them and any additional table as 188 bytes
+// packets. Tables can be repeated at regular intervals of the stream time, which is given by the PCRs written: before
+// writing a data, the tables whose period has elapsed since they were last written are written again.
+type Muxer struct {
+	clock              int // Last PCR written, in 27 MHz ticks
+	clockUnwrapper     *ClockUnwrapper
+	continuityCounters map[uint16]uint8 // Indexed by PID, contains the next continuity counter to use
+	ctx                context.Context
+	hasClock           bool
+	patVersion         uint8
+	pcrRestamper       *PCRRestamper
+	pmt                PMTData
+	pmtPID             uint16
+	pmtVersion         uint8
+	tablePeriods       map[uint16]time.Duration // Indexed by PID
+	tables             []*muxerTable
+	tablesChanged      bool
+	tablesPeriod       time.Duration
+	tablesWrittenAt    map[uint16]int // Indexed by PID, contains the clock at which the tables were last written
+	timestampShifter   *TimestampShifter
+	transportStreamID  uint16
+	w                  io.Writer
+}
+
+// MuxerData represents a data to be muxed
+// The adaptation field, if any, is written in the first packet only
+type MuxerData struct {
+	AdaptationField *PacketAdaptationField
+	PES             *PESData
+	PID             uint16
+}
+
+// muxerTable represents a table written along with the PAT and the PMT
+type muxerTable struct {
+	pid      uint16
+	sections [][]byte
+	tableID  uint8
+}
+
+// NewMuxer creates a new muxer based on a writer
+func NewMuxer(ctx context.Context, w io.Writer, opts ...func(*Muxer)) (m *Muxer) {
+	// Init
+	m = &Muxer{
+		clockUnwrapper:     NewClockUnwrapper(),
+		continuityCounters: make(map[uint16]uint8),
+		ctx:                ctx,
+		pmt: PMTData{
+			PCRPID:        PIDNull,
+			ProgramNumber: muxerDefaultProgramNumber,
+		},
+		pmtPID:            muxerDefaultPMTPID,
+		tablePeriods:      make(map[uint16]time.Duration),
+		tablesChanged:     true,
+		tablesWrittenAt:   make(map[uint16]int),
+		transportStreamID: muxerDefaultTransportStreamID,
+		w:                 w,
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(m)
+	}
+	return
+}
+
+// MuxerOptConstantBitrate returns the option to write a constant bitrate stream, in bits per second, by scheduling
+// packets against their PCRs and padding the stream with null packets using a CBRWriter
+func MuxerOptConstantBitrate(bitrate int) func(*Muxer) {
+	return func(m *Muxer) {
+		m.w = NewCBRWriter(m.w, bitrate)
+	}
+}
+
+// MuxerOptPCRRestamper returns the option to restamp every packet written with a PCR restamper
+func MuxerOptPCRRestamper(r *PCRRestamper) func(*Muxer) {
+	return func(m *Muxer) {
+		m.pcrRestamper = r
+	}
+}
+
+// MuxerOptPMTPID returns the option to set the PID of the PMT
+func MuxerOptPMTPID(pid uint16) func(*Muxer) {
+	return func(m *Muxer) {
+		m.pmtPID = pid
+	}
+}
+
+// MuxerOptProgramNumber returns the option to set the program number
+func MuxerOptProgramNumber(programNumber uint16) func(*Muxer) {
+	return func(m *Muxer) {
+		m.pmt.ProgramNumber = programNumber
+	}
+}
+
+// MuxerOptTablesPeriod returns the option to repeat the PAT, the PMT and the tables that have been set every time the
+// period has elapsed, such as every 100ms for live outputs. Periods of specific PIDs can be set with SetTablePeriod
+func MuxerOptTablesPeriod(d time.Duration) func(*Muxer) {
+	return func(m *Muxer) {
+		m.tablesPeriod = d
+	}
+}
+
+// MuxerOptTimestampShifter returns the option to shift every packet written with a timestamp shifter
+// Packets are shifted before being restamped
+func MuxerOptTimestampShifter(s *TimestampShifter) func(*Muxer) {
+	return func(m *Muxer) {
+		m.timestampShifter = s
+	}
+}
+
+// MuxerOptTransportStreamID returns the option to set the transport stream ID
+func MuxerOptTransportStreamID(id uint16) func(*Muxer) {
+	return func(m *Muxer) {
+		m.transportStreamID = id
+	}
+}
+
+// AddElementaryStream adds a new elementary stream to the PMT
+// The first elementary stream added carries the PCR unless SetPCRPID is called
+func (m *Muxer) AddElementaryStream(es PMTElementaryStream) (err error) {
+	// Check PID
+	if es.ElementaryPID == PIDPAT || es.ElementaryPID == m.pmtPID || m.hasElementaryStream(es.ElementaryPID) || m.hasTable(es.ElementaryPID) {
+		err = ErrPIDAlreadyExists
+		return
+	}
+
+	// Add elementary stream
+	m.pmt.ElementaryStreams = append(m.pmt.ElementaryStreams, &es)
+	if m.pmt.PCRPID == PIDNull {
+		m.pmt.PCRPID = es.ElementaryPID
+	}
+	m.tableChanged()
+	return
+}
+
+// SetTable sets the sections of a table written along with the PAT and the PMT every time tables are written
+// Sections must be complete sections, CRC32 included, such as the ones returned by Serialize methods. They replace the
+// sections previously set for the same PID and table ID, and are all written in the same payload after a single
+// pointer field. When a table is set on the NIT PID, the PAT announces it as the network PID
+func (m *Muxer) SetTable(pid uint16, sections [][]byte) (err error) {
+	// Check sections
+	if len(sections) == 0 || len(sections[0]) == 0 {
+		err = errors.New("astits: no sections provided")
+		return
+	}
+
+	// Check PID
+	if pid == PIDPAT || pid == m.pmtPID || m.hasElementaryStream(pid) {
+		err = ErrPIDAlreadyExists
+		return
+	}
+
+	// Replace table
+	var t = &muxerTable{pid: pid, sections: sections, tableID: sections[0][0]}
+	var replaced bool
+	for idx, tt := range m.tables {
+		if tt.pid == t.pid && tt.tableID == t.tableID {
+			m.tables[idx] = t
+			replaced = true
+			break
+		}
+	}
+
+	// Add table
+	if !replaced {
+		// The PAT changes once it announces the network PID
+		if pid == PIDNIT && !m.hasTable(PIDNIT) && !m.tablesChanged {
+			m.patVersion = (m.patVersion + 1) % 32
+		}
+		m.tables = append(m.tables, t)
+	}
+	m.tablesChanged = true
+	return
+}
+
+// SetTablePeriod sets the period at which the tables of a PID are repeated, which overrides the one set with
+// MuxerOptTablesPeriod. For instance DVB requires the SDT to be repeated at least every 2s. A period of 0 disables
+// the repetition of the PID
+func (m *Muxer) SetTablePeriod(pid uint16, d time.Duration) {
+	m.tablePeriods[pid] = d
+}
+
+// SetPCRPID sets the PID carrying the PCR
+func (m *Muxer) SetPCRPID(pid uint16) {
+	m.pmt.PCRPID = pid
+	m.tableChanged()
+}
+
+// tableChanged increments the PMT version if it has already been written
+func (m *Muxer) tableChanged() {
+	if !m.tablesChanged {
+		m.pmtVersion = (m.pmtVersion + 1) % 32
+		m.tablesChanged = true
+	}
+}
+
+// hasElementaryStream checks whether an elementary stream with this PID has been added
+func (m *Muxer) hasElementaryStream(pid uint16) bool {
+	for _, es := range m.pmt.ElementaryStreams {
+		if es.ElementaryPID == pid {
+			return true
+		}
+	}
+	return false
+}
+
+// hasElementaryStreamWithType checks whether an elementary stream with this PID and this stream type has been added
+func (m *Muxer) hasElementaryStreamWithType(pid uint16, streamType uint8) bool {
+	for _, es := range m.pmt.ElementaryStreams {
+		if es.ElementaryPID == pid {
+			return es.StreamType == streamType
+		}
+	}
+	return false
+}
+
+// hasTable checks whether a table with this PID has been set
+func (m *Muxer) hasTable(pid uint16) bool {
+	for _, t := range m.tables {
+		if t.pid == pid {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteTables writes the PAT, the PMT and the tables that have been set, in this order
+func (m *Muxer) WriteTables() (n int, err error) {
+	// Check ctx error
+	if err = m.ctx.Err(); err != nil {
+		return
+	}
+
+	// Write PAT and PMT
+	var nn int
+	for _, pid := range []uint16{PIDPAT, m.pmtPID} {
+		if nn, err = m.writeTable(pid); err != nil {
+			err = errors.Wrapf(err, "astits: writing table with PID %d failed", pid)
+			return
+		}
+		n += nn
+	}
+
+	// Write tables
+	for _, t := range m.tables {
+		if nn, err = m.writePayload(t.pid, writePSIPayload(t.sections), nil, true); err != nil {
+			err = errors.Wrapf(err, "astits: writing table with PID %d and table ID 0x%x failed", t.pid, t.tableID)
+			return
+		}
+		m.tablesWrittenAt[t.pid] = m.clock
+		n += nn
+	}
+	m.tablesChanged = false
+	return
+}
+
+// writeTable writes the PAT, the PMT or the tables that have been set on a PID
+func (m *Muxer) writeTable(pid uint16) (n int, err error) {
+	// Serialize
+	var ss [][]byte
+	switch pid {
+	case PIDPAT:
+		var pat = &PATData{
+			Programs:          []*PATProgram{{ProgramMapID: m.pmtPID, ProgramNumber: m.pmt.ProgramNumber}},
+			TransportStreamID: m.transportStreamID,
+		}
+		if m.hasTable(PIDNIT) {
+			// Program number 0 is reserved to NIT
+			pat.Programs = append([]*PATProgram{{ProgramMapID: PIDNIT}}, pat.Programs...)
+		}
+		if ss, err = pat.Serialize(m.patVersion); err != nil {
+			err = errors.Wrap(err, "astits: serializing PAT failed")
+			return
+		}
+	case m.pmtPID:
+		var b []byte
+		if b, err = m.pmt.Serialize(m.pmtVersion); err != nil {
+			err = errors.Wrap(err, "astits: serializing PMT failed")
+			return
+		}
+		ss = [][]byte{b}
+	default:
+		for _, t := range m.tables {
+			if t.pid == pid {
+				ss = append(ss, t.sections...)
+			}
+		}
+	}
+
+	// Write
+	if n, err = m.writePayload(pid, writePSIPayload(ss), nil, true); err != nil {
+		err = errors.Wrap(err, "astits: writing payload failed")
+		return
+	}
+	m.tablesWrittenAt[pid] = m.clock
+	return
+}
+
+// writeTablesIfNeeded writes all the tables if they have never been written or if they have changed since, and
+// otherwise repeats the tables whose period has elapsed
+func (m *Muxer) writeTablesIfNeeded() (n int, err error) {
+	// Tables have changed
+	if m.tablesChanged {
+		return m.WriteTables()
+	}
+
+	// Stream time is unknown
+	if !m.hasClock {
+		return
+	}
+
+	// Get PIDs
+	var pids = []uint16{PIDPAT, m.pmtPID}
+	for _, t := range m.tables {
+		if !containsPID(pids, t.pid) {
+			pids = append(pids, t.pid)
+		}
+	}
+
+	// Loop through PIDs
+	for _, pid := range pids {
+		// Get period
+		var d = m.tablesPeriod
+		if v, ok := m.tablePeriods[pid]; ok {
+			d = v
+		}
+
+		// Period has not elapsed yet
+		// A clock going backwards is a discontinuity, after which tables are repeated right away
+		if c, ok := m.tablesWrittenAt[pid]; d <= 0 || (ok && m.clock >= c && ticks27MHzToDuration(m.clock-c) < d) {
+			continue
+		}
+
+		// Write table
+		var nn int
+		if nn, err = m.writeTable(pid); err != nil {
+			err = errors.Wrapf(err, "astits: writing table with PID %d failed", pid)
+			return
+		}
+		n += nn
+	}
+	return
+}
+
+// WriteData writes a data
+// Tables are written first if they have never been written, if they have changed since or if their period has elapsed
+func (m *Muxer) WriteData(d *MuxerData) (n int, err error) {
+	// Check ctx error
+	if err = m.ctx.Err(); err != nil {
+		return
+	}
+
+	// Check PID
+	if !m.hasElementaryStream(d.PID) {
+		err = fmt.Errorf("astits: PID %d is not an elementary stream", d.PID)
+		return
+	}
+
+	// Write tables
+	var nn int
+	if nn, err = m.writeTablesIfNeeded(); err != nil {
+		err = errors.Wrap(err, "astits: writing tables failed")
+		return
+	}
+	n += nn
+
+	// Write PES
+	if nn, err = m.writePayload(d.PID, writePESData(d.PES), d.AdaptationField, false); err != nil {
+		err = errors.Wrap(err, "astits: writing PES failed")
+		return
+	}
+	n += nn
+	return
+}
+
+// WriteSCTE35 writes a SCTE-35 splice info section on a PID that has been added as an elementary stream with the
+// StreamTypeSCTE35 stream type
+// Tables are written first if they have never been written, if they have changed since or if their period has elapsed
+func (m *Muxer) WriteSCTE35(pid uint16, d *SCTE35Data) (n int, err error) {
+	// Check ctx error
+	if err = m.ctx.Err(); err != nil {
+		return
+	}
+
+	// Check PID
+	if !m.hasElementaryStreamWithType(pid, StreamTypeSCTE35) {
+		err = fmt.Errorf("astits: PID %d is not a SCTE-35 elementary stream", pid)
+		return
+	}
+
+	// Serialize
+	var b []byte
+	if b, err = d.Serialize(); err != nil {
+		err = errors.Wrap(err, "astits: serializing SCTE-35 failed")
+		return
+	}
+
+	// Write tables
+	var nn int
+	if nn, err = m.writeTablesIfNeeded(); err != nil {
+		err = errors.Wrap(err, "astits: writing tables failed")
+		return
+	}
+	n += nn
+
+	// Write SCTE-35
+	if nn, err = m.writePayload(pid, writePSIPayload([][]byte{b}), nil, true); err != nil {
+		err = errors.Wrap(err, "astits: writing SCTE-35 failed")
+		return
+	}
+	n += nn
+	return
+}
+
+// writePayload splits a payload into packets and writes them
+// When stuffPayload is true, the last packet is stuffed with 0xff bytes in its payload as it's done for PSI,
+// otherwise it's stuffed in its adaptation field
+func (m *Muxer) writePayload(pid uint16, b []byte, a *PacketAdaptationField, stuffPayload bool) (n int, err error) {
+	// Split payload
+	var cc = m.continuityCounters[pid]
+	var ps = packetizePayload(pid, b, a, stuffPayload, &cc)
+	m.continuityCounters[pid] = cc
+
+	// Write packets
+	for _, p := range ps {
+		if m.timestampShifter != nil {
+			m.timestampShifter.Shift(p)
+		}
+		if m.pcrRestamper != nil {
+			m.pcrRestamper.Restamp(p)
+		}
+		if p.AdaptationField != nil && p.AdaptationField.HasPCR && p.AdaptationField.PCR != nil {
+			m.clock = m.clockUnwrapper.Unwrap(p.AdaptationField.PCR).Ticks27MHz()
+			m.hasClock = true
+		}
+		var nn int
+		if nn, err = m.w.Write(writePacket(p)); err != nil {
+			err = errors.Wrapf(err, "astits: writing packet of PID %d failed", pid)
+			return
+		}
+		n += nn
+	}
+	return
+}
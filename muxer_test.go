@@ -0,0 +1,250 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMuxerNew(t *testing.T) {
+	m := NewMuxer(context.Background(), nil, MuxerOptPMTPID(0x100), MuxerOptProgramNumber(2), MuxerOptTransportStreamID(3))
+	assert.Equal(t, uint16(0x100), m.pmtPID)
+	assert.Equal(t, uint16(2), m.pmt.ProgramNumber)
+	assert.Equal(t, uint16(3), m.transportStreamID)
+}
+
+func TestMuxerAddElementaryStream(t *testing.T) {
+	m := NewMuxer(context.Background(), nil)
+	err := m.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x100, StreamType: StreamTypeLowerBitrateVideo})
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(0x100), m.pmt.PCRPID)
+	err = m.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x100, StreamType: StreamTypeMPEG1Audio})
+	assert.Equal(t, ErrPIDAlreadyExists, err)
+	err = m.AddElementaryStream(PMTElementaryStream{ElementaryPID: muxerDefaultPMTPID, StreamType: StreamTypeMPEG1Audio})
+	assert.Equal(t, ErrPIDAlreadyExists, err)
+	m.SetPCRPID(0x101)
+	assert.Equal(t, uint16(0x101), m.pmt.PCRPID)
+}
+
+func TestMuxerWriteData(t *testing.T) {
+	// Init
+	buf := &bytes.Buffer{}
+	m := NewMuxer(context.Background(), buf)
+	err := m.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x100, StreamType: StreamTypeLowerBitrateVideo})
+	assert.NoError(t, err)
+
+	// Unknown PID
+	_, err = m.WriteData(&MuxerData{PID: 0x101})
+	assert.Error(t, err)
+
+	// Write data
+	var pes = &PESData{
+		Data: bytes.Repeat([]byte("data"), 100),
+		Header: &PESHeader{
+			OptionalHeader: &PESOptionalHeader{PTS: ptsClockReference},
+			StreamID:       0xe0,
+		},
+	}
+	n, err := m.WriteData(&MuxerData{
+		AdaptationField: &PacketAdaptationField{HasPCR: true, PCR: pcr, RandomAccessIndicator: true},
+		PES:             pes,
+		PID:             0x100,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 5*MpegTsPacketSize, n)
+	n, err = m.WriteData(&MuxerData{PES: pes, PID: 0x100})
+	assert.NoError(t, err)
+	assert.Equal(t, 3*MpegTsPacketSize, n)
+	assert.Equal(t, 8*MpegTsPacketSize, buf.Len())
+
+	// Data is only retrieved once the next payload unit starts
+	_, err = m.WriteTables()
+	assert.NoError(t, err)
+	_, err = m.WriteData(&MuxerData{PES: pes, PID: 0x100})
+	assert.NoError(t, err)
+
+	// Demux
+	dmx := New(context.Background(), bytes.NewReader(buf.Bytes()))
+	d, err := dmx.NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, pes.Data, d.PES.Data)
+	assert.Equal(t, ptsClockReference, d.PES.Header.OptionalHeader.PTS)
+	assert.Equal(t, pcr, d.FirstPacket.AdaptationField.PCR)
+	d, err = dmx.NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, &PATData{Programs: []*PATProgram{{ProgramMapID: muxerDefaultPMTPID, ProgramNumber: muxerDefaultProgramNumber}}, TransportStreamID: muxerDefaultTransportStreamID}, d.PAT)
+	d, err = dmx.NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, &PMTData{
+		ElementaryStreams: []*PMTElementaryStream{{ElementaryPID: 0x100, StreamType: StreamTypeLowerBitrateVideo}},
+		PCRPID:            0x100,
+		ProgramNumber:     muxerDefaultProgramNumber,
+	}, d.PMT)
+	d, err = dmx.NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, pes.Data, d.PES.Data)
+
+	// Continuity counters
+	var ccs []uint8
+	for idx := 2; idx < 8; idx++ {
+		ccs = append(ccs, buf.Bytes()[idx*MpegTsPacketSize+3]&0xf)
+	}
+	assert.Equal(t, []uint8{0, 1, 2, 3, 4, 5}, ccs)
+}
+
+func TestMuxerSetTable(t *testing.T) {
+	// Init
+	buf := &bytes.Buffer{}
+	m := NewMuxer(context.Background(), buf)
+	err := m.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x100, StreamType: StreamTypeLowerBitrateVideo})
+	assert.NoError(t, err)
+
+	// Invalid PIDs
+	assert.Equal(t, ErrPIDAlreadyExists, m.SetTable(0x100, [][]byte{{0x0}}))
+	assert.Equal(t, ErrPIDAlreadyExists, m.SetTable(muxerDefaultPMTPID, [][]byte{{0x0}}))
+	assert.Error(t, m.SetTable(0x11, nil))
+
+	// Table spanning over several sections
+	var items [][]byte
+	var ps []*PATProgram
+	for idx := 0; idx < 300; idx++ {
+		p := &PATProgram{ProgramMapID: uint16(idx + 0x200), ProgramNumber: uint16(idx + 1)}
+		items = append(items, writePATSection(&PATData{Programs: []*PATProgram{p}}))
+		ps = append(ps, p)
+	}
+	ss, err := writePSISections(&PSISectionHeader{SectionSyntaxIndicator: true, TableID: 0}, &PSISectionSyntaxHeader{CurrentNextIndicator: true, TableIDExtension: 1}, nil, items)
+	assert.NoError(t, err)
+	err = m.SetTable(0x11, ss)
+	assert.NoError(t, err)
+	assert.Equal(t, ErrPIDAlreadyExists, m.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x11}))
+
+	// Data is only retrieved once the next payload unit starts
+	_, err = m.WriteTables()
+	assert.NoError(t, err)
+	_, err = m.WriteTables()
+	assert.NoError(t, err)
+
+	// Demux
+	dmx := New(context.Background(), bytes.NewReader(buf.Bytes()))
+	var pids []uint16
+	var tps []*PATProgram
+	for {
+		d, err := dmx.NextData()
+		if err == ErrNoMorePackets {
+			break
+		}
+		assert.NoError(t, err)
+		pids = append(pids, d.PID)
+		if d.PID == 0x11 {
+			tps = append(tps, d.PAT.Programs...)
+		}
+	}
+	assert.Equal(t, []uint16{PIDPAT, muxerDefaultPMTPID, 0x11, 0x11}, pids)
+	assert.Equal(t, ps, tps)
+}
+
+func TestMuxerOptTablesPeriod(t *testing.T) {
+	// Init
+	buf := &bytes.Buffer{}
+	m := NewMuxer(context.Background(), buf, MuxerOptTablesPeriod(100*time.Millisecond))
+	m.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x100, StreamType: StreamTypeMPEG1Audio})
+	ss, _ := writePSISections(&PSISectionHeader{SectionSyntaxIndicator: true, TableID: 0x42}, &PSISectionSyntaxHeader{CurrentNextIndicator: true, TableIDExtension: 1}, nil, [][]byte{{0x0, 0x1, 0xff}})
+	m.SetTable(0x11, ss)
+	m.SetTablePeriod(0x11, 300*time.Millisecond)
+
+	// Tables are repeated once their period has elapsed since the last PCR written
+	for idx := 0; idx <= 10; idx++ {
+		_, err := m.WriteData(&MuxerData{
+			AdaptationField: &PacketAdaptationField{HasPCR: true, PCR: NewClockReferenceFromDuration(time.Duration(idx) * 50 * time.Millisecond)},
+			PES:             &PESData{Data: []byte("data"), Header: &PESHeader{OptionalHeader: &PESOptionalHeader{}, StreamID: 0xc0}},
+			PID:             0x100,
+		})
+		assert.NoError(t, err)
+	}
+	var counts = make(map[uint16]int)
+	for i := buf.Bytes(); len(i) >= MpegTsPacketSize; i = i[MpegTsPacketSize:] {
+		pid, _ := rawPacketPID(i)
+		counts[pid]++
+	}
+	assert.Equal(t, map[uint16]int{PIDPAT: 5, muxerDefaultPMTPID: 5, 0x11: 2, 0x100: 11}, counts)
+}
+
+func TestMuxerNIT(t *testing.T) {
+	// Init
+	buf := &bytes.Buffer{}
+	m := NewMuxer(context.Background(), buf)
+	m.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x100, StreamType: StreamTypeMPEG1Audio})
+	_, err := m.WriteTables()
+	assert.NoError(t, err)
+
+	// The PAT announces the network PID
+	ss, err := NewNITData(1, "network").Serialize(0)
+	assert.NoError(t, err)
+	assert.NoError(t, m.SetTable(PIDNIT, ss))
+	_, err = m.WriteTables()
+	assert.NoError(t, err)
+	_, err = m.WriteTables()
+	assert.NoError(t, err)
+
+	// Demux
+	dmx := New(context.Background(), bytes.NewReader(buf.Bytes()))
+	var pats []*PATData
+	var nit *NITData
+	for {
+		d, err := dmx.NextData()
+		if err == ErrNoMorePackets {
+			break
+		}
+		assert.NoError(t, err)
+		if d.PAT != nil {
+			pats = append(pats, d.PAT)
+		} else if d.NIT != nil {
+			nit = d.NIT
+		}
+	}
+	assert.Len(t, pats, 2)
+	assert.Len(t, pats[0].Programs, 1)
+	assert.Equal(t, []*PATProgram{{ProgramMapID: PIDNIT}, {ProgramMapID: muxerDefaultPMTPID, ProgramNumber: 1}}, pats[1].Programs)
+	assert.Equal(t, "network", nit.NetworkDescriptors[0].NetworkName.DecodedName)
+}
+
+func TestMuxerWriteSCTE35(t *testing.T) {
+	// Init
+	buf := &bytes.Buffer{}
+	m := NewMuxer(context.Background(), buf)
+	err := m.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x100, StreamType: StreamTypeLowerBitrateVideo})
+	assert.NoError(t, err)
+	err = m.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x101, StreamType: StreamTypeSCTE35})
+	assert.NoError(t, err)
+
+	// Invalid PIDs
+	_, err = m.WriteSCTE35(0x100, scte35)
+	assert.Error(t, err)
+	_, err = m.WriteSCTE35(0x102, scte35)
+	assert.Error(t, err)
+
+	// Data is only retrieved once the next payload unit starts
+	n, err := m.WriteSCTE35(0x101, scte35)
+	assert.NoError(t, err)
+	assert.Equal(t, 3*MpegTsPacketSize, n)
+	_, err = m.WriteTables()
+	assert.NoError(t, err)
+	_, err = m.WriteSCTE35(0x101, scte35)
+	assert.NoError(t, err)
+
+	// Demux
+	dmx := New(context.Background(), bytes.NewReader(buf.Bytes()))
+	var d *Data
+	for d == nil || d.SCTE35 == nil {
+		d, err = dmx.NextData()
+		assert.NoError(t, err)
+		if err != nil {
+			return
+		}
+	}
+	assert.Equal(t, uint16(0x101), d.PID)
+	assert.Equal(t, scte35, d.SCTE35)
+}
@@ -0,0 +1,200 @@
+package astits
+
+// H.264 NAL unit types
+// Page: 65 | Chapter: 7.4.1 | Link: https://www.itu.int/rec/T-REC-H.264
+const (
+	H264NALUnitTypeAccessUnitDelimiter = 9
+	H264NALUnitTypeAuxiliarySlice      = 19
+	H264NALUnitTypeCodedSliceExtension = 20
+	H264NALUnitTypeEndOfSequence       = 10
+	H264NALUnitTypeEndOfStream         = 11
+	H264NALUnitTypeFillerData          = 12
+	H264NALUnitTypeIDRSlice            = 5
+	H264NALUnitTypeNonIDRSlice         = 1
+	H264NALUnitTypePPS                 = 8
+	H264NALUnitTypePrefixNALUnit       = 14
+	H264NALUnitTypeSEI                 = 6
+	H264NALUnitTypeSliceDataPartitionA = 2
+	H264NALUnitTypeSliceDataPartitionB = 3
+	H264NALUnitTypeSliceDataPartitionC = 4
+	H264NALUnitTypeSPS                 = 7
+	H264NALUnitTypeSPSExtension        = 13
+	H264NALUnitTypeSubsetSPS           = 15
+)
+
+// HEVC NAL unit types
+// Page: 67 | Chapter: 7.4.2.2 | Link: https://www.itu.int/rec/T-REC-H.265
+const (
+	HEVCNALUnitTypeAccessUnitDelimiter       = 35
+	HEVCNALUnitTypeBLAWithLeadingPictures    = 16
+	HEVCNALUnitTypeBLAWithRADL               = 17
+	HEVCNALUnitTypeBLAWithoutLeadingPictures = 18
+	HEVCNALUnitTypeCRA                       = 21
+	HEVCNALUnitTypeEndOfBitstream            = 37
+	HEVCNALUnitTypeEndOfSequence             = 36
+	HEVCNALUnitTypeFillerData                = 38
+	HEVCNALUnitTypeIDRWithRADL               = 19
+	HEVCNALUnitTypeIDRWithoutLeadingPictures = 20
+	HEVCNALUnitTypePPS                       = 34
+	HEVCNALUnitTypePrefixSEI                 = 39
+	HEVCNALUnitTypeRADLN                     = 6
+	HEVCNALUnitTypeRADLR                     = 7
+	HEVCNALUnitTypeRASLN                     = 8
+	HEVCNALUnitTypeRASLR                     = 9
+	HEVCNALUnitTypeSPS                       = 33
+	HEVCNALUnitTypeSTSAN                     = 4
+	HEVCNALUnitTypeSTSAR                     = 5
+	HEVCNALUnitTypeSuffixSEI                 = 40
+	HEVCNALUnitTypeTrailN                    = 0
+	HEVCNALUnitTypeTrailR                    = 1
+	HEVCNALUnitTypeTSAN                      = 2
+	HEVCNALUnitTypeTSAR                      = 3
+	HEVCNALUnitTypeVPS                       = 32
+)
+
+// H264AccessUnit represents an H.264 access unit
+// In MPEG-TS, a video PES usually carries exactly one access unit
+type H264AccessUnit struct {
+	HasIDR   bool // True when the access unit contains an IDR slice, i.e. it's a keyframe
+	HasPPS   bool
+	HasSPS   bool
+	NALUnits []*H264NALUnit
+}
+
+// H264NALUnit represents an H.264 NAL unit
+// Page: 43 | Chapter: 7.3.1 | Link: https://www.itu.int/rec/T-REC-H.264
+type H264NALUnit struct {
+	Data   []byte // NAL unit header included, start code and trailing zero bytes excluded. Emulation prevention bytes are kept
+	RefIDC uint8
+	Type   uint8
+}
+
+// ParseH264AccessUnit splits an Annex B byte stream, such as the payload of an H.264 PES, into NAL units
+// Bytes before the first start code are ignored. NAL units data point to the input
+func ParseH264AccessUnit(i []byte) (au *H264AccessUnit) {
+	// Init
+	au = &H264AccessUnit{}
+
+	// Loop through NAL units
+	for _, b := range splitNALUnits(i) {
+		// Parse header
+		var n = &H264NALUnit{
+			Data:   b,
+			RefIDC: b[0] >> 5 & 0x3,
+			Type:   b[0] & 0x1f,
+		}
+
+		// Update access unit
+		switch n.Type {
+		case H264NALUnitTypeIDRSlice:
+			au.HasIDR = true
+		case H264NALUnitTypePPS:
+			au.HasPPS = true
+		case H264NALUnitTypeSPS:
+			au.HasSPS = true
+		}
+		au.NALUnits = append(au.NALUnits, n)
+	}
+	return
+}
+
+// HEVCAccessUnit represents an HEVC access unit
+// In MPEG-TS, a video PES usually carries exactly one access unit
+type HEVCAccessUnit struct {
+	HasIRAP  bool // True when the access unit contains an IRAP picture (BLA, CRA or IDR), i.e. it's a keyframe
+	HasPPS   bool
+	HasSPS   bool
+	HasVPS   bool
+	NALUnits []*HEVCNALUnit
+}
+
+// HEVCNALUnit represents an HEVC NAL unit
+// Page: 35 | Chapter: 7.3.1 | Link: https://www.itu.int/rec/T-REC-H.265
+type HEVCNALUnit struct {
+	Data            []byte // NAL unit header included, start code and trailing zero bytes excluded. Emulation prevention bytes are kept
+	LayerID         uint8
+	TemporalIDPlus1 uint8
+	Type            uint8
+}
+
+// ParseHEVCAccessUnit splits an Annex B byte stream, such as the payload of an HEVC PES, into NAL units
+// Bytes before the first start code and NAL units shorter than their 2 bytes header are ignored. NAL units data point
+// to the input
+func ParseHEVCAccessUnit(i []byte) (au *HEVCAccessUnit) {
+	// Init
+	au = &HEVCAccessUnit{}
+
+	// Loop through NAL units
+	for _, b := range splitNALUnits(i) {
+		// Parse header
+		if len(b) < 2 {
+			continue
+		}
+		var n = &HEVCNALUnit{
+			Data:            b,
+			LayerID:         b[0]&0x1<<5 | b[1]>>3,
+			TemporalIDPlus1: b[1] & 0x7,
+			Type:            b[0] >> 1 & 0x3f,
+		}
+
+		// Update access unit
+		switch {
+		case isHEVCNALUnitTypeIRAP(n.Type):
+			au.HasIRAP = true
+		case n.Type == HEVCNALUnitTypePPS:
+			au.HasPPS = true
+		case n.Type == HEVCNALUnitTypeSPS:
+			au.HasSPS = true
+		case n.Type == HEVCNALUnitTypeVPS:
+			au.HasVPS = true
+		}
+		au.NALUnits = append(au.NALUnits, n)
+	}
+	return
+}
+
+// isHEVCNALUnitTypeIRAP checks whether a NAL unit type is an IRAP picture type, reserved types 22 and 23 included
+func isHEVCNALUnitTypeIRAP(t uint8) bool {
+	return t >= HEVCNALUnitTypeBLAWithLeadingPictures && t <= 23
+}
+
+// splitNALUnits splits an Annex B byte stream into NAL units
+// A NAL unit starts after a 0x000001 start code and ends before the next 0x000000 or 0x000001 sequence, which can't
+// occur inside a NAL unit thanks to emulation prevention
+// The byte stream format is the same for H.264 and HEVC
+// Page: 327 | Chapter: B.2 | Link: https://www.itu.int/rec/T-REC-H.264
+func splitNALUnits(i []byte) (ns [][]byte) {
+	var start = -1
+	for idx := 0; idx+2 < len(i); idx++ {
+		// Look for 0x000000 or 0x000001
+		if i[idx] != 0 || i[idx+1] != 0 || i[idx+2] > 1 {
+			continue
+		}
+
+		// End the current NAL unit
+		if start >= 0 {
+			if idx > start {
+				ns = append(ns, i[start:idx])
+			}
+			start = -1
+		}
+
+		// Start a new NAL unit
+		if i[idx+2] == 1 {
+			start = idx + 3
+			idx += 2
+		}
+	}
+
+	// Last NAL unit
+	if start >= 0 {
+		var end = len(i)
+		for end > start && i[end-1] == 0 {
+			end--
+		}
+		if end > start {
+			ns = append(ns, i[start:end])
+		}
+	}
+	return
+}
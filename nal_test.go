@@ -0,0 +1,56 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitNALUnits(t *testing.T) {
+	assert.Nil(t, splitNALUnits([]byte{}))
+	assert.Nil(t, splitNALUnits([]byte{0x1, 0x2, 0x3}))
+	assert.Equal(t, [][]byte{{0x9, 0xf0}, {0x67, 0x1}, {0x68}, {0x65, 0x2, 0x3}}, splitNALUnits([]byte{0xff, 0x0, 0x0, 0x0, 0x1, 0x9, 0xf0, 0x0, 0x0, 0x1, 0x67, 0x1, 0x0, 0x0, 0x0, 0x0, 0x1, 0x68, 0x0, 0x0, 0x1, 0x65, 0x2, 0x3, 0x0, 0x0}))
+}
+
+func TestParseH264AccessUnit(t *testing.T) {
+	// Keyframe
+	au := ParseH264AccessUnit([]byte{0x0, 0x0, 0x0, 0x1, 0x9, 0xf0, 0x0, 0x0, 0x1, 0x67, 0x1, 0x0, 0x0, 0x1, 0x68, 0x2, 0x0, 0x0, 0x1, 0x65, 0x3})
+	assert.Equal(t, &H264AccessUnit{
+		HasIDR: true,
+		HasPPS: true,
+		HasSPS: true,
+		NALUnits: []*H264NALUnit{
+			{Data: []byte{0x9, 0xf0}, Type: H264NALUnitTypeAccessUnitDelimiter},
+			{Data: []byte{0x67, 0x1}, RefIDC: 3, Type: H264NALUnitTypeSPS},
+			{Data: []byte{0x68, 0x2}, RefIDC: 3, Type: H264NALUnitTypePPS},
+			{Data: []byte{0x65, 0x3}, RefIDC: 3, Type: H264NALUnitTypeIDRSlice},
+		},
+	}, au)
+
+	// Non keyframe
+	au = ParseH264AccessUnit([]byte{0x0, 0x0, 0x1, 0x41, 0x3})
+	assert.False(t, au.HasIDR)
+	assert.Equal(t, []*H264NALUnit{{Data: []byte{0x41, 0x3}, RefIDC: 2, Type: H264NALUnitTypeNonIDRSlice}}, au.NALUnits)
+}
+
+func TestParseHEVCAccessUnit(t *testing.T) {
+	// Keyframe
+	au := ParseHEVCAccessUnit([]byte{0x0, 0x0, 0x0, 0x1, 0x40, 0x1, 0xc, 0x0, 0x0, 0x1, 0x42, 0x1, 0x1, 0x0, 0x0, 0x1, 0x44, 0x1, 0xc1, 0x0, 0x0, 0x1, 0x26, 0x1, 0xaf, 0x0, 0x0, 0x1, 0x26})
+	assert.Equal(t, &HEVCAccessUnit{
+		HasIRAP: true,
+		HasPPS:  true,
+		HasSPS:  true,
+		HasVPS:  true,
+		NALUnits: []*HEVCNALUnit{
+			{Data: []byte{0x40, 0x1, 0xc}, TemporalIDPlus1: 1, Type: HEVCNALUnitTypeVPS},
+			{Data: []byte{0x42, 0x1, 0x1}, TemporalIDPlus1: 1, Type: HEVCNALUnitTypeSPS},
+			{Data: []byte{0x44, 0x1, 0xc1}, TemporalIDPlus1: 1, Type: HEVCNALUnitTypePPS},
+			{Data: []byte{0x26, 0x1, 0xaf}, TemporalIDPlus1: 1, Type: HEVCNALUnitTypeIDRWithRADL},
+		},
+	}, au)
+
+	// Non keyframe
+	au = ParseHEVCAccessUnit([]byte{0x0, 0x0, 0x1, 0x3, 0xa, 0xd0})
+	assert.False(t, au.HasIRAP)
+	assert.Equal(t, []*HEVCNALUnit{{Data: []byte{0x3, 0xa, 0xd0}, LayerID: 33, TemporalIDPlus1: 2, Type: HEVCNALUnitTypeTrailR}}, au.NALUnits)
+}
@@ -1,5 +1,25 @@
 package astits
 
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Packet sizes
+const (
+	ATSCPacketSize   = 208 // MPEG-TS packet followed by 20 Reed-Solomon parity bytes computed without the sync byte
+	DVBPacketSize    = 204 // MPEG-TS packet followed by 16 Reed-Solomon parity bytes
+	M2TSPacketSize   = 192 // MPEG-TS packet prefixed with a 4 bytes extra header containing an arrival timestamp
+	MpegTsPacketSize = 188
+)
+
+// Errors
+var (
+	ErrPacketTooBig = errors.New("astits: packet is too big")
+)
+
 // Scrambling Controls
 const (
 	ScramblingControlNotScrambled         = 0
@@ -12,11 +32,19 @@ const (
 // https://en.wikipedia.org/wiki/MPEG_transport_stream
 type Packet struct {
 	AdaptationField *PacketAdaptationField
-	Bytes           []byte // This is the whole packet content
+	Bytes           []byte             // This is the whole packet content
+	ExtraHeader     *PacketExtraHeader // Only set for 192 bytes M2TS packets
 	Header          *PacketHeader
+	Parity          []byte // Only set for 204 and 208 bytes packets, contains the Reed-Solomon parity bytes
 	Payload         []byte // This is only the payload content
 }
 
+// PacketExtraHeader represents the 4 bytes header prefixing 192 bytes M2TS packets (Blu-ray/BDAV streams)
+type PacketExtraHeader struct {
+	ArrivalTimestamp        uint32 // Based on a 27 MHz clock, stored on 30 bits
+	CopyPermissionIndicator uint8
+}
+
 // PacketHeader represents a packet header
 type PacketHeader struct {
 	ContinuityCounter          uint8 // Sequence number of payload packets (0x00 to 0x0F) within each stream (except PID 8191)
@@ -61,22 +89,142 @@ type PacketAdaptationExtensionField struct {
 	SpliceType             uint8  // Indicates the parameters of the H.262 splice.
 }
 
+// Clone returns a deep copy of the packet which doesn't share any memory with the original packet
+func (p *Packet) Clone() (c *Packet) {
+	// Bytes
+	c = &Packet{Bytes: cloneBytes(p.Bytes)}
+
+	// Payload and parity share the bytes memory when the packet has been parsed
+	c.Parity = cloneSubBytes(p.Bytes, c.Bytes, p.Parity)
+	c.Payload = cloneSubBytes(p.Bytes, c.Bytes, p.Payload)
+
+	// Extra header
+	if p.ExtraHeader != nil {
+		var h = *p.ExtraHeader
+		c.ExtraHeader = &h
+	}
+
+	// Header
+	if p.Header != nil {
+		var h = *p.Header
+		c.Header = &h
+	}
+
+	// Adaptation field
+	if p.AdaptationField != nil {
+		var a = *p.AdaptationField
+		a.OPCR = cloneClockReference(a.OPCR)
+		a.PCR = cloneClockReference(a.PCR)
+		a.TransportPrivateData = cloneBytes(a.TransportPrivateData)
+		if a.AdaptationExtensionField != nil {
+			var e = *a.AdaptationExtensionField
+			e.DTSNextAccessUnit = cloneClockReference(e.DTSNextAccessUnit)
+			a.AdaptationExtensionField = &e
+		}
+		c.AdaptationField = &a
+	}
+	return
+}
+
+// cloneBytes returns a copy of a slice of bytes
+func cloneBytes(i []byte) (o []byte) {
+	if i == nil {
+		return
+	}
+	o = make([]byte, len(i))
+	copy(o, i)
+	return
+}
+
+// cloneSubBytes clones a sub slice of src into its equivalent sub slice of dst if it shares src memory, otherwise it
+// copies it
+func cloneSubBytes(src, dst, sub []byte) []byte {
+	if len(sub) == 0 {
+		return cloneBytes(sub)
+	}
+	for idx := 0; idx+len(sub) <= len(src); idx++ {
+		if &src[idx] == &sub[0] {
+			return dst[idx : idx+len(sub)]
+		}
+	}
+	return cloneBytes(sub)
+}
+
+// cloneClockReference returns a copy of a clock reference
+func cloneClockReference(i *ClockReference) *ClockReference {
+	if i == nil {
+		return nil
+	}
+	return newClockReference(i.Base, i.Extension)
+}
+
+// packetsPool allows reusing packets memory once they have been closed
+var packetsPool = &sync.Pool{New: func() interface{} { return &Packet{} }}
+
+// Close releases the packet so that its memory can be reused for the next packets
+// Closing a packet is optional, however once closed the packet and its content must not be used anymore. Packets
+// retrieved in zero copy mode must not be closed.
+func (p *Packet) Close() {
+	packetsPool.Put(p)
+}
+
+// newPooledPacket retrieves a packet from the pool with bytes of the provided size
+func newPooledPacket(size int) (p *Packet) {
+	p = packetsPool.Get().(*Packet)
+	if cap(p.Bytes) < size {
+		p.Bytes = make([]byte, size)
+	} else {
+		p.Bytes = p.Bytes[:size]
+	}
+	return
+}
+
 // parsePacket parses a packet
 func parsePacket(i []byte) (p *Packet, err error) {
+	p = &Packet{}
+	if err = parsePacketInto(p, i); err != nil {
+		p = nil
+		return
+	}
+	return
+}
+
+// parsePacketInto parses a packet into an existing packet, reusing its header if any
+// 192 bytes packets are considered as M2TS packets whereas bytes following the first 188 bytes of bigger packets are
+// ignored
+func parsePacketInto(p *Packet, i []byte) (err error) {
+	// Init
+	p.AdaptationField = nil
+	p.Bytes = i
+	p.ExtraHeader = nil
+	p.Parity = nil
+	p.Payload = nil
+
+	// Extra header
+	if len(i) == M2TSPacketSize {
+		p.ExtraHeader = parsePacketExtraHeader(i)
+		i = i[4:]
+	}
+
 	// Packet must start with a sync byte
 	if i[0] != syncByte {
 		err = ErrPacketMustStartWithASyncByte
 		return
 	}
 
-	// Init
-	p = &Packet{Bytes: i}
+	// Parity
+	if len(i) == DVBPacketSize || len(i) == ATSCPacketSize {
+		p.Parity = i[MpegTsPacketSize:]
+	}
 
-	// In case packet size is bigger than 188 bytes, we don't care for the first bytes
-	i = i[len(i)-188+1:]
+	// Remove sync byte and trailing bytes
+	i = i[1:MpegTsPacketSize]
 
 	// Parse header
-	p.Header = parsePacketHeader(i)
+	if p.Header == nil {
+		p.Header = &PacketHeader{}
+	}
+	*p.Header = *parsePacketHeader(i)
 
 	// Parse adaptation field
 	if p.Header.HasAdaptationField {
@@ -90,6 +238,26 @@ func parsePacket(i []byte) (p *Packet, err error) {
 	return
 }
 
+// HasValidParity checks whether the Reed-Solomon parity bytes of the packet match its content
+// Packets without parity bytes are always valid
+func (p *Packet) HasValidParity() bool {
+	switch len(p.Bytes) {
+	case DVBPacketSize:
+		return reedSolomonIsValid(p.Bytes, DVBPacketSize-MpegTsPacketSize)
+	case ATSCPacketSize:
+		return reedSolomonIsValid(p.Bytes[1:], ATSCPacketSize-MpegTsPacketSize)
+	}
+	return true
+}
+
+// parsePacketExtraHeader parses the extra header of a M2TS packet
+func parsePacketExtraHeader(i []byte) *PacketExtraHeader {
+	return &PacketExtraHeader{
+		ArrivalTimestamp:        uint32(i[0]&0x3f)<<24 | uint32(i[1])<<16 | uint32(i[2])<<8 | uint32(i[3]),
+		CopyPermissionIndicator: uint8(i[0]) >> 6,
+	}
+}
+
 // payloadOffset returns the payload offset
 func payloadOffset(h *PacketHeader, a *PacketAdaptationField) (offset int) {
 	offset = 3
@@ -150,7 +318,7 @@ func parsePacketAdaptationField(i []byte) (a *PacketAdaptationField) {
 
 		// Splicing countdown
 		if a.HasSplicingCountdown {
-			a.SpliceCountdown = int(i[offset])
+			a.SpliceCountdown = int(int8(i[offset]))
 			offset += 1
 		}
 
@@ -205,3 +373,319 @@ func parsePCR(i []byte) *ClockReference {
 	var pcr = uint64(i[0])<<40 | uint64(i[1])<<32 | uint64(i[2])<<24 | uint64(i[3])<<16 | uint64(i[4])<<8 | uint64(i[5])
 	return newClockReference(int(pcr>>15), int(pcr&0x1ff))
 }
+
+// writePacket serializes a packet into a 188 bytes slice, or a 192 bytes slice if it has an extra header
+// The adaptation field is stuffed so that the payload ends exactly at the end of the packet, which means the payload
+// must not be bigger than what's left once the header and the adaptation field have been written
+func writePacket(p *Packet) (b []byte) {
+	// Init
+	b = make([]byte, 0, M2TSPacketSize)
+
+	// Extra header
+	if p.ExtraHeader != nil {
+		b = append(b, writePacketExtraHeader(p.ExtraHeader)...)
+	}
+
+	// Sync byte
+	b = append(b, syncByte)
+
+	// Compute stuffing
+	var h = *p.Header
+	var a *PacketAdaptationField
+	var l = 4 + len(p.Payload)
+	if p.AdaptationField != nil {
+		l += 1 + packetAdaptationFieldMinimumLength(p.AdaptationField)
+	}
+	if l < MpegTsPacketSize {
+		if p.AdaptationField == nil {
+			// An adaptation field containing only its length byte is enough to stuff 1 byte
+			a = &PacketAdaptationField{Length: MpegTsPacketSize - l - 1}
+		} else {
+			var c = *p.AdaptationField
+			c.Length = packetAdaptationFieldMinimumLength(p.AdaptationField) + MpegTsPacketSize - l
+			a = &c
+		}
+	} else if p.AdaptationField != nil {
+		var c = *p.AdaptationField
+		c.Length = packetAdaptationFieldMinimumLength(p.AdaptationField)
+		a = &c
+	}
+	h.HasAdaptationField = a != nil
+	h.HasPayload = len(p.Payload) > 0
+
+	// Header
+	b = append(b, writePacketHeader(&h)...)
+
+	// Adaptation field
+	if a != nil {
+		b = append(b, writePacketAdaptationField(a)...)
+	}
+
+	// Payload
+	b = append(b, p.Payload...)
+	return
+}
+
+// Serialize serializes the packet into a 188 bytes slice, or a 192 bytes slice if it has an extra header, that can be
+// parsed back into the same packet
+// The adaptation field and payload flags of the header are set according to the adaptation field and the payload, and
+// the adaptation field is stuffed with 0xff bytes so that the payload ends exactly at the end of the packet.
+// ErrPacketTooBig is returned when the adaptation field and the payload don't fit in the packet.
+func (p *Packet) Serialize() (b []byte, err error) {
+	// Validate header
+	if p.Header == nil {
+		err = errors.New("astits: packet header is nil")
+		return
+	} else if p.Header.PID > PIDNull {
+		err = fmt.Errorf("astits: PID %d is bigger than %d", p.Header.PID, PIDNull)
+		return
+	}
+
+	// Validate adaptation field
+	var l = 4 + len(p.Payload)
+	if p.AdaptationField != nil {
+		if err = validateAdaptationField(p.AdaptationField); err != nil {
+			err = errors.Wrap(err, "astits: validating adaptation field failed")
+			return
+		}
+		l += 1 + packetAdaptationFieldMinimumLength(p.AdaptationField)
+	}
+
+	// Validate length
+	if l > MpegTsPacketSize {
+		err = errors.Wrapf(ErrPacketTooBig, "astits: packet length is %d", l)
+		return
+	}
+
+	// Write
+	b = writePacket(p)
+	return
+}
+
+// writePacketExtraHeader serializes the extra header of a M2TS packet
+func writePacketExtraHeader(h *PacketExtraHeader) []byte {
+	return []byte{h.CopyPermissionIndicator<<6 | uint8(h.ArrivalTimestamp>>24)&0x3f, uint8(h.ArrivalTimestamp >> 16), uint8(h.ArrivalTimestamp >> 8), uint8(h.ArrivalTimestamp)}
+}
+
+// writePacketHeader serializes a packet header without its sync byte
+func writePacketHeader(h *PacketHeader) []byte {
+	var b = make([]byte, 3)
+	if h.TransportErrorIndicator {
+		b[0] |= 0x80
+	}
+	if h.PayloadUnitStartIndicator {
+		b[0] |= 0x40
+	}
+	if h.TransportPriority {
+		b[0] |= 0x20
+	}
+	b[0] |= uint8(h.PID>>8) & 0x1f
+	b[1] = uint8(h.PID)
+	b[2] = h.TransportScramblingControl & 0x3 << 6
+	if h.HasAdaptationField {
+		b[2] |= 0x20
+	}
+	if h.HasPayload {
+		b[2] |= 0x10
+	}
+	b[2] |= h.ContinuityCounter & 0xf
+	return b
+}
+
+// packetAdaptationFieldMinimumLength returns the minimum length of an adaptation field, stuffing bytes excluded
+func packetAdaptationFieldMinimumLength(a *PacketAdaptationField) (l int) {
+	// Adaptation field with only stuffing
+	if !a.HasPCR && !a.HasOPCR && !a.HasSplicingCountdown && !a.HasTransportPrivateData && !a.HasAdaptationExtensionField &&
+		!a.DiscontinuityIndicator && !a.RandomAccessIndicator && !a.ElementaryStreamPriorityIndicator {
+		return
+	}
+
+	// Flags
+	l = 1
+
+	// PCR
+	if a.HasPCR {
+		l += 6
+	}
+
+	// OPCR
+	if a.HasOPCR {
+		l += 6
+	}
+
+	// Splicing countdown
+	if a.HasSplicingCountdown {
+		l += 1
+	}
+
+	// Transport private data
+	if a.HasTransportPrivateData {
+		l += 1 + len(a.TransportPrivateData)
+	}
+
+	// Adaptation extension
+	if a.HasAdaptationExtensionField {
+		l += 1
+		if a.AdaptationExtensionField != nil {
+			l += packetAdaptationExtensionFieldLength(a.AdaptationExtensionField)
+		}
+	}
+	return
+}
+
+// packetAdaptationExtensionFieldLength returns the length of an adaptation extension field, its length byte excluded
+// Reserved bytes announced by its length are kept
+func packetAdaptationExtensionFieldLength(e *PacketAdaptationExtensionField) (l int) {
+	// Flags
+	l = 1
+
+	// Legal time window
+	if e.HasLegalTimeWindow {
+		l += 2
+	}
+
+	// Piecewise rate
+	if e.HasPiecewiseRate {
+		l += 3
+	}
+
+	// Seamless splice
+	if e.HasSeamlessSplice {
+		l += 5
+	}
+
+	// Reserved bytes
+	if e.Length > l {
+		l = e.Length
+	}
+	return
+}
+
+// writePacketAdaptationField serializes a packet adaptation field and stuffs it with 0xff bytes up to its length
+func writePacketAdaptationField(a *PacketAdaptationField) (b []byte) {
+	// Length
+	b = append(b, uint8(a.Length))
+	if a.Length == 0 {
+		return
+	}
+
+	// Flags
+	var flags uint8
+	if a.DiscontinuityIndicator {
+		flags |= 0x80
+	}
+	if a.RandomAccessIndicator {
+		flags |= 0x40
+	}
+	if a.ElementaryStreamPriorityIndicator {
+		flags |= 0x20
+	}
+	if a.HasPCR {
+		flags |= 0x10
+	}
+	if a.HasOPCR {
+		flags |= 0x08
+	}
+	if a.HasSplicingCountdown {
+		flags |= 0x04
+	}
+	if a.HasTransportPrivateData {
+		flags |= 0x02
+	}
+	if a.HasAdaptationExtensionField {
+		flags |= 0x01
+	}
+	b = append(b, flags)
+
+	// PCR
+	if a.HasPCR {
+		b = append(b, writePCR(a.PCR)...)
+	}
+
+	// OPCR
+	if a.HasOPCR {
+		b = append(b, writePCR(a.OPCR)...)
+	}
+
+	// Splicing countdown
+	if a.HasSplicingCountdown {
+		b = append(b, uint8(a.SpliceCountdown))
+	}
+
+	// Transport private data
+	if a.HasTransportPrivateData {
+		b = append(b, uint8(len(a.TransportPrivateData)))
+		b = append(b, a.TransportPrivateData...)
+	}
+
+	// Adaptation extension
+	if a.HasAdaptationExtensionField {
+		if a.AdaptationExtensionField != nil {
+			b = append(b, writePacketAdaptationExtensionField(a.AdaptationExtensionField)...)
+		} else {
+			b = append(b, 0)
+		}
+	}
+
+	// Stuffing bytes
+	for len(b) < a.Length+1 {
+		b = append(b, 0xff)
+	}
+	return
+}
+
+// writePacketAdaptationExtensionField serializes a packet adaptation extension field and fills it with 0xff reserved
+// bytes up to its length
+func writePacketAdaptationExtensionField(e *PacketAdaptationExtensionField) (b []byte) {
+	// Length
+	var l = packetAdaptationExtensionFieldLength(e)
+	b = append(b, uint8(l))
+
+	// Flags
+	var flags uint8 = 0x1f
+	if e.HasLegalTimeWindow {
+		flags |= 0x80
+	}
+	if e.HasPiecewiseRate {
+		flags |= 0x40
+	}
+	if e.HasSeamlessSplice {
+		flags |= 0x20
+	}
+	b = append(b, flags)
+
+	// Legal time window
+	if e.HasLegalTimeWindow {
+		var v = uint8(e.LegalTimeWindowOffset>>8) & 0x7f
+		if e.LegalTimeWindowIsValid {
+			v |= 0x80
+		}
+		b = append(b, v, uint8(e.LegalTimeWindowOffset))
+	}
+
+	// Piecewise rate
+	if e.HasPiecewiseRate {
+		b = append(b, 0xc0|uint8(e.PiecewiseRate>>16)&0x3f, uint8(e.PiecewiseRate>>8), uint8(e.PiecewiseRate))
+	}
+
+	// Seamless splice
+	if e.HasSeamlessSplice {
+		var dts = e.DTSNextAccessUnit
+		if dts == nil {
+			dts = &ClockReference{}
+		}
+		b = append(b, writePTSOrDTS(e.SpliceType&0xf, dts)...)
+	}
+
+	// Reserved bytes
+	for len(b) < l+1 {
+		b = append(b, 0xff)
+	}
+	return
+}
+
+// writePCR serializes a Program Clock Reference
+func writePCR(cr *ClockReference) []byte {
+	var pcr = uint64(cr.Base)&0x1ffffffff<<15 | 0x3f<<9 | uint64(cr.Extension)&0x1ff
+	return []byte{uint8(pcr >> 40), uint8(pcr >> 32), uint8(pcr >> 24), uint8(pcr >> 16), uint8(pcr >> 8), uint8(pcr)}
+}
@@ -1,6 +1,7 @@
 package astits
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 
@@ -9,17 +10,32 @@ import (
 
 // packetBuffer represents a packet buffer
 type packetBuffer struct {
-	b          []*Packet
-	packetSize int
-	r          io.Reader
+	b             []*Packet
+	br            *bufio.Reader // Only used in resync mode
+	packetSize    int
+	pidFilter     *pidFilter // Optional
+	r             io.Reader
+	readBuffer    []byte // Only used in zero copy mode
+	resyncPackets int
+	skippedBytes  int // Number of bytes skipped during the last resync
+	zeroCopy      bool
 }
 
 // newPacketBuffer creates a new packet buffer
-func newPacketBuffer(r io.Reader, packetSize int) (pb *packetBuffer, err error) {
+// When bufferSize is > 1, bufferSize packets are read at once from the reader, otherwise packets are read one by one.
+// When resyncPackets is > 0, the buffer resynchronizes itself when a packet doesn't start with a sync byte by looking
+// for resyncPackets consecutive sync bytes at packet size intervals.
+// In zero copy mode, the same read buffer is used for every packet which means a packet is only valid until the next
+// packet is fetched.
+// When a PID filter is provided, packets it doesn't allow are skipped before being parsed
+func newPacketBuffer(r io.Reader, packetSize, bufferSize, resyncPackets int, zeroCopy bool, f *pidFilter) (pb *packetBuffer, err error) {
 	// Init
 	pb = &packetBuffer{
-		packetSize: packetSize,
-		r:          r,
+		packetSize:    packetSize,
+		pidFilter:     f,
+		r:             r,
+		resyncPackets: resyncPackets,
+		zeroCopy:      zeroCopy,
 	}
 
 	// Packet size is not set
@@ -30,52 +46,98 @@ func newPacketBuffer(r io.Reader, packetSize int) (pb *packetBuffer, err error)
 			return
 		}
 	}
+
+	// Resync needs to look ahead
+	if resyncPackets > 0 && bufferSize < resyncPackets {
+		bufferSize = resyncPackets
+	}
+
+	// Buffer reads
+	// Resync always needs a buffered reader since it peeks bytes
+	if bufferSize > 1 || resyncPackets > 0 {
+		pb.br = bufio.NewReaderSize(r, bufferSize*pb.packetSize)
+		pb.r = pb.br
+	}
 	return
 }
 
 // autoDetectPacketSize updates the packet size based on the first bytes
 // Minimum packet size is 188 and is bounded by 2 sync bytes
-// Assumption is made that the first byte of the reader is a sync byte
+// Assumption is made that the first byte of the reader is a sync byte, unless packets are 192 bytes M2TS packets in
+// which case the sync byte follows a 4 bytes extra header
 func autoDetectPacketSize(r io.Reader) (packetSize int, err error) {
 	// Read first bytes
-	const l = 193
+	const l = ATSCPacketSize + 1
 	var b = make([]byte, l)
-	if _, err = r.Read(b); err != nil {
+	var n int
+	if n, err = io.ReadFull(r, b); err != nil && err != io.ErrUnexpectedEOF {
 		err = errors.Wrapf(err, "astits: reading first %d bytes failed", l)
 		return
 	}
+	err = nil
+	b = b[:n]
 
-	// Packet must start with a sync byte
-	if b[0] != syncByte {
-		err = ErrPacketMustStartWithASyncByte
+	// Detect packet size
+	if packetSize = detectPacketSize(b); packetSize == 0 {
+		if len(b) == 0 || (b[0] != syncByte && (len(b) < 5 || b[4] != syncByte)) {
+			err = ErrPacketMustStartWithASyncByte
+		} else if len(b) > M2TSPacketSize && b[0] == syncByte && b[M2TSPacketSize] == syncByte {
+			err = fmt.Errorf("astits: %d bytes packets starting with a sync byte are not supported, only M2TS packets are", M2TSPacketSize)
+		} else {
+			err = fmt.Errorf("astits: only one sync byte detected in first %d bytes", len(b))
+		}
 		return
 	}
 
-	// Look for sync bytes
-	for idx, b := range b {
-		if b == syncByte && idx >= 188 {
-			// Update packet size
-			packetSize = idx
-
-			// Rewind or sync reader
-			var n int64
-			if n, err = rewind(r); err != nil {
-				err = errors.Wrap(err, "astits: rewinding failed")
+	// Rewind or sync reader
+	var rn int64
+	if rn, err = rewind(r); err != nil {
+		err = errors.Wrap(err, "astits: rewinding failed")
+		return
+	} else if rn == -1 {
+		if ls := (packetSize - n%packetSize) % packetSize; ls > 0 {
+			if _, err = io.ReadFull(r, make([]byte, ls)); err != nil {
+				err = errors.Wrapf(err, "astits: reading %d bytes to sync reader failed", ls)
 				return
-			} else if n == -1 {
-				var ls = packetSize - (l - packetSize)
-				if _, err = r.Read(make([]byte, ls)); err != nil {
-					err = errors.Wrapf(err, "astits: reading %d bytes to sync reader failed", ls)
-					return
-				}
 			}
-			return
 		}
 	}
-	err = fmt.Errorf("astits: only one sync byte detected in first %d bytes", l)
 	return
 }
 
+// detectPacketSize returns the packet size detected in the first bytes of a stream or 0 if none has been detected
+func detectPacketSize(b []byte) int {
+	// M2TS packets
+	if len(b) > M2TSPacketSize+4 && b[0] != syncByte && b[4] == syncByte && b[M2TSPacketSize+4] == syncByte {
+		return M2TSPacketSize
+	}
+
+	// Packet must start with a sync byte
+	if len(b) == 0 || b[0] != syncByte {
+		return 0
+	}
+
+	// Standard packet sizes are checked first since parity bytes may contain sync bytes
+	for _, ps := range []int{MpegTsPacketSize, DVBPacketSize, ATSCPacketSize} {
+		if ps < len(b) && b[ps] == syncByte {
+			return ps
+		}
+	}
+
+	// Look for the next sync byte
+	// 192 bytes packets are always parsed as M2TS packets whose sync byte follows a 4 bytes header, therefore packets
+	// starting with a sync byte and followed by a 4 bytes trailer are rejected
+	for idx := MpegTsPacketSize; idx < len(b); idx++ {
+		if b[idx] == syncByte {
+			if idx == M2TSPacketSize {
+				return 0
+			}
+			return idx
+		}
+	}
+	return 0
+}
+
 // rewind rewinds the reader if possible, otherwise n = -1
 func rewind(r io.Reader) (n int64, err error) {
 	if s, ok := r.(io.Seeker); ok {
@@ -91,21 +153,129 @@ func rewind(r io.Reader) (n int64, err error) {
 
 // next fetches the next packet from the buffer
 func (pb *packetBuffer) next() (p *Packet, err error) {
-	// Read
-	var b = make([]byte, pb.packetSize)
-	if _, err = io.ReadFull(pb.r, b); err != nil {
-		if err == io.EOF || err == io.ErrUnexpectedEOF {
-			err = ErrNoMorePackets
-		} else {
-			err = errors.Wrapf(err, "astits: reading %d bytes failed", pb.packetSize)
+	// Get packet
+	if pb.zeroCopy {
+		if len(pb.readBuffer) != pb.packetSize {
+			pb.readBuffer = make([]byte, pb.packetSize)
+		}
+		p = &Packet{Bytes: pb.readBuffer}
+	} else {
+		p = newPooledPacket(pb.packetSize)
+	}
+
+	// Loop until a packet is allowed by the PID filter
+	pb.skippedBytes = 0
+	for {
+		// Resync
+		if pb.resyncPackets > 0 {
+			if err = pb.resync(); err != nil {
+				if err != ErrNoMorePackets {
+					err = errors.Wrap(err, "astits: resyncing failed")
+				}
+				p = nil
+				return
+			}
+		}
+
+		// Read
+		if _, err = io.ReadFull(pb.r, p.Bytes); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				err = ErrNoMorePackets
+			} else {
+				err = errors.Wrapf(err, "astits: reading %d bytes failed", pb.packetSize)
+			}
+			p = nil
+			return
+		}
+
+		// Filter PID
+		// Packets without a sync byte are never filtered so that parsing reports them
+		if pb.pidFilter == nil {
+			break
+		} else if pid, ok := rawPacketPID(p.Bytes); !ok || pb.pidFilter.allows(pid) {
+			break
 		}
-		return
 	}
 
 	// Parse packet
-	if p, err = parsePacket(b); err != nil {
+	if err = parsePacketInto(p, p.Bytes); err != nil {
 		err = errors.Wrap(err, "astits: building packet failed")
+		p = nil
 		return
 	}
 	return
 }
+
+// packetSyncOffset returns the offset of the sync byte in packets of this size
+func packetSyncOffset(packetSize int) int {
+	if packetSize == M2TSPacketSize {
+		return 4
+	}
+	return 0
+}
+
+// resync makes sure the next packet starts with a sync byte and is followed by resyncPackets - 1 packets starting with
+// a sync byte, and discards bytes until it is
+func (pb *packetBuffer) resync() (err error) {
+	// Next sync byte is where it's expected
+	var b []byte
+	var so = packetSyncOffset(pb.packetSize)
+	if b, err = pb.br.Peek(so + 1); err != nil {
+		if err == io.EOF && len(b) == 0 {
+			err = ErrNoMorePackets
+			return
+		} else if err != io.EOF {
+			err = errors.Wrapf(err, "astits: peeking %d bytes failed", so+1)
+			return
+		}
+		err = nil
+	} else if b[so] == syncByte {
+		return
+	}
+
+	// Loop until stream is synchronized
+	for {
+		// Look ahead
+		// At the end of the stream, we only check the packets that are left
+		var l = pb.resyncPackets * pb.packetSize
+		if b, err = pb.br.Peek(l); err != nil && err != io.EOF {
+			err = errors.Wrapf(err, "astits: peeking %d bytes failed", l)
+			return
+		}
+		err = nil
+
+		// Not enough bytes left for a packet
+		if len(b) < pb.packetSize {
+			if _, err = pb.br.Discard(len(b)); err != nil {
+				err = errors.Wrapf(err, "astits: discarding %d bytes failed", len(b))
+				return
+			}
+			pb.skippedBytes += len(b)
+			err = ErrNoMorePackets
+			return
+		}
+
+		// Check sync bytes
+		var synced = true
+		for offset := 0; offset+pb.packetSize <= len(b); offset += pb.packetSize {
+			if b[offset+so] != syncByte {
+				synced = false
+				break
+			}
+		}
+		if synced {
+			return
+		}
+
+		// Discard until next sync byte
+		var n = 1
+		for n+so < len(b) && b[n+so] != syncByte {
+			n++
+		}
+		if _, err = pb.br.Discard(n); err != nil {
+			err = errors.Wrapf(err, "astits: discarding %d bytes failed", n)
+			return
+		}
+		pb.skippedBytes += n
+	}
+}
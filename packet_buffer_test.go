@@ -30,4 +30,74 @@ func TestAutoDetectPacketSize(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 188, p)
 	assert.Equal(t, 380, r.Len())
+
+	// M2TS packets
+	w.Reset()
+	w.Write([]byte("test"))
+	w.Write(byte(syncByte))
+	w.Write(make([]byte, 187))
+	w.Write([]byte("test"))
+	w.Write(byte(syncByte))
+	w.Write(make([]byte, 187))
+	r = bytes.NewReader(w.Bytes())
+	p, err = autoDetectPacketSize(r)
+	assert.NoError(t, err)
+	assert.Equal(t, M2TSPacketSize, p)
+	assert.Equal(t, 384, r.Len())
+
+	// 192 bytes packets with a trailer
+	w.Reset()
+	w.Write(byte(syncByte))
+	w.Write(make([]byte, 187))
+	w.Write([]byte("test"))
+	w.Write(byte(syncByte))
+	w.Write(make([]byte, 187))
+	w.Write([]byte("test"))
+	_, err = autoDetectPacketSize(bytes.NewReader(w.Bytes()))
+	assert.Error(t, err)
+
+	// Sync bytes in parity bytes
+	w.Reset()
+	w.Write(byte(syncByte))
+	w.Write(make([]byte, 187))
+	w.Write(uint8(1))
+	w.Write(byte(syncByte))
+	w.Write(make([]byte, 14))
+	w.Write(byte(syncByte))
+	w.Write(make([]byte, 203))
+	r = bytes.NewReader(w.Bytes())
+	p, err = autoDetectPacketSize(r)
+	assert.NoError(t, err)
+	assert.Equal(t, DVBPacketSize, p)
+}
+
+func TestPacketBufferPIDFilter(t *testing.T) {
+	// Init
+	w := &bytes.Buffer{}
+	for _, pid := range []uint16{0x100, 0x101, 0x102, 0x101} {
+		w.Write(writePacket(&Packet{Header: &PacketHeader{HasPayload: true, PID: pid}, Payload: []byte("payload")}))
+	}
+
+	// Loop through filters
+	for _, v := range []struct {
+		f    *pidFilter
+		pids []uint16
+	}{
+		{pids: []uint16{0x100, 0x101, 0x102, 0x101}},
+		{f: newPIDFilter(true, 0x101), pids: []uint16{0x101, 0x101}},
+		{f: newPIDFilter(false, 0x101), pids: []uint16{0x100, 0x102}},
+	} {
+		pb, err := newPacketBuffer(bytes.NewReader(w.Bytes()), MpegTsPacketSize, 0, 0, false, v.f)
+		assert.NoError(t, err)
+		var pids []uint16
+		for {
+			p, err := pb.next()
+			if err != nil {
+				assert.Equal(t, ErrNoMorePackets, err)
+				break
+			}
+			pids = append(pids, p.Header.PID)
+		}
+		assert.Equal(t, v.pids, pids)
+	}
 }
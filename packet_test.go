@@ -5,13 +5,14 @@ import (
 	"testing"
 
 	"github.com/asticode/go-astitools/binary"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
 func packet(h PacketHeader, a PacketAdaptationField, i []byte) ([]byte, *Packet) {
 	w := astibinary.New()
+	w.Write([]byte("test"))                              // Sometimes packets are 192 bytes and have an extra header
 	w.Write(uint8(syncByte))                             // Sync byte
-	w.Write([]byte("test"))                              // Sometimes packets are 192 bytes
 	w.Write(packetHeaderBytes(h))                        // Header
 	w.Write(packetAdaptationFieldBytes(a))               // Adaptation field
 	var payload = append(i, make([]byte, 147-len(i))...) // Payload
@@ -19,11 +20,17 @@ func packet(h PacketHeader, a PacketAdaptationField, i []byte) ([]byte, *Packet)
 	return w.Bytes(), &Packet{
 		AdaptationField: packetAdaptationField,
 		Bytes:           w.Bytes(),
+		ExtraHeader:     packetExtraHeader,
 		Header:          packetHeader,
 		Payload:         payload,
 	}
 }
 
+var packetExtraHeader = &PacketExtraHeader{
+	ArrivalTimestamp:        0x34657374,
+	CopyPermissionIndicator: 1,
+}
+
 func TestParsePacket(t *testing.T) {
 	// Packet not starting with a sync
 	w := astibinary.New()
@@ -148,3 +155,155 @@ func pcrBytes() []byte {
 func TestParsePCR(t *testing.T) {
 	assert.Equal(t, pcr, parsePCR(pcrBytes()))
 }
+
+func TestWritePacket(t *testing.T) {
+	// Stuffing without adaptation field
+	p, err := parsePacket(writePacket(&Packet{
+		Header:  &PacketHeader{ContinuityCounter: 3, PayloadUnitStartIndicator: true, PID: 256},
+		Payload: []byte("payload"),
+	}))
+	assert.NoError(t, err)
+	assert.Len(t, p.Bytes, MpegTsPacketSize)
+	assert.Equal(t, &PacketHeader{ContinuityCounter: 3, HasAdaptationField: true, HasPayload: true, PayloadUnitStartIndicator: true, PID: 256}, p.Header)
+	assert.Equal(t, []byte("payload"), p.Payload)
+
+	// Stuffing with only the adaptation field length
+	p, err = parsePacket(writePacket(&Packet{Header: &PacketHeader{PID: 256}, Payload: make([]byte, 183)}))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, p.AdaptationField.Length)
+	assert.Len(t, p.Payload, 183)
+
+	// No stuffing
+	p, err = parsePacket(writePacket(&Packet{Header: &PacketHeader{PID: 256}, Payload: make([]byte, 184)}))
+	assert.NoError(t, err)
+	assert.False(t, p.Header.HasAdaptationField)
+	assert.Len(t, p.Payload, 184)
+
+	// Adaptation field with PCR
+	p, err = parsePacket(writePacket(&Packet{
+		AdaptationField: &PacketAdaptationField{HasPCR: true, PCR: pcr, RandomAccessIndicator: true},
+		Header:          &PacketHeader{PID: 256},
+		Payload:         []byte("payload"),
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, pcr, p.AdaptationField.PCR)
+	assert.True(t, p.AdaptationField.RandomAccessIndicator)
+	assert.Equal(t, 176, p.AdaptationField.Length)
+	assert.Equal(t, []byte("payload"), p.Payload)
+}
+
+func TestPacketSerialize(t *testing.T) {
+	// Round trip
+	b, _ := packet(*packetHeader, *packetAdaptationField, []byte("payload"))
+	p, err := parsePacket(b)
+	assert.NoError(t, err)
+	s, err := p.Serialize()
+	assert.NoError(t, err)
+	assert.Len(t, s, M2TSPacketSize)
+	sp, err := parsePacket(s)
+	assert.NoError(t, err)
+	assert.Equal(t, p.AdaptationField, sp.AdaptationField)
+	assert.Equal(t, p.ExtraHeader, sp.ExtraHeader)
+	assert.Equal(t, p.Header, sp.Header)
+	assert.Equal(t, p.Payload, sp.Payload)
+	s2, err := sp.Serialize()
+	assert.NoError(t, err)
+	assert.Equal(t, s, s2)
+
+	// Errors
+	_, err = (&Packet{}).Serialize()
+	assert.Error(t, err)
+	_, err = (&Packet{Header: &PacketHeader{PID: 0x2000}}).Serialize()
+	assert.Error(t, err)
+	_, err = (&Packet{AdaptationField: &PacketAdaptationField{HasPCR: true}, Header: &PacketHeader{}}).Serialize()
+	assert.Error(t, err)
+	_, err = (&Packet{Header: &PacketHeader{}, Payload: make([]byte, 185)}).Serialize()
+	assert.Equal(t, ErrPacketTooBig, errors.Cause(err))
+	_, err = (&Packet{AdaptationField: &PacketAdaptationField{HasPCR: true, PCR: pcr}, Header: &PacketHeader{}, Payload: make([]byte, 177)}).Serialize()
+	assert.Equal(t, ErrPacketTooBig, errors.Cause(err))
+}
+
+func TestWritePacketExtraHeader(t *testing.T) {
+	assert.Equal(t, []byte("test"), writePacketExtraHeader(packetExtraHeader))
+	assert.Equal(t, packetExtraHeader, parsePacketExtraHeader(writePacketExtraHeader(packetExtraHeader)))
+
+	// M2TS packet
+	p, err := parsePacket(writePacket(&Packet{
+		ExtraHeader: packetExtraHeader,
+		Header:      &PacketHeader{PID: 256},
+		Payload:     []byte("payload"),
+	}))
+	assert.NoError(t, err)
+	assert.Len(t, p.Bytes, M2TSPacketSize)
+	assert.Equal(t, packetExtraHeader, p.ExtraHeader)
+	assert.Equal(t, []byte("payload"), p.Payload)
+}
+
+func TestWritePacketHeader(t *testing.T) {
+	assert.Equal(t, packetHeaderBytes(*packetHeader), writePacketHeader(packetHeader))
+}
+
+func TestWritePCR(t *testing.T) {
+	assert.Equal(t, pcrBytes(), writePCR(pcr))
+}
+
+func TestPacketClone(t *testing.T) {
+	b, _ := packet(*packetHeader, *packetAdaptationField, []byte("payload"))
+	p, err := parsePacket(b)
+	assert.NoError(t, err)
+	c := p.Clone()
+	assert.Equal(t, p, c)
+	for idx := range b {
+		b[idx] = 0
+	}
+	p.Header.PID = 1
+	p.AdaptationField.PCR.Base = 1
+	_, e := packet(*packetHeader, *packetAdaptationField, []byte("payload"))
+	assert.Equal(t, e, c)
+}
+
+func TestPacketHasValidParity(t *testing.T) {
+	b := writePacket(&Packet{Header: &PacketHeader{PID: 256}, Payload: []byte("payload")})
+
+	// DVB
+	p, err := parsePacket(append(append([]byte{}, b...), reedSolomonParity(b, 16)...))
+	assert.NoError(t, err)
+	assert.Len(t, p.Parity, 16)
+	assert.Equal(t, []byte("payload"), p.Payload[len(p.Payload)-7:])
+	assert.True(t, p.HasValidParity())
+	assert.Equal(t, p, p.Clone())
+	p.Bytes[100] ^= 0x1
+	assert.False(t, p.HasValidParity())
+
+	// ATSC
+	p, err = parsePacket(append(append([]byte{}, b...), reedSolomonParity(b[1:], 20)...))
+	assert.NoError(t, err)
+	assert.Len(t, p.Parity, 20)
+	assert.True(t, p.HasValidParity())
+	p.Parity[0] ^= 0x1
+	assert.False(t, p.HasValidParity())
+
+	// No parity
+	p, err = parsePacket(b)
+	assert.NoError(t, err)
+	assert.Nil(t, p.Parity)
+	assert.True(t, p.HasValidParity())
+}
+
+func TestNewPooledPacket(t *testing.T) {
+	p := newPooledPacket(188)
+	assert.Len(t, p.Bytes, 188)
+	p.Close()
+	p = newPooledPacket(192)
+	assert.Len(t, p.Bytes, 192)
+	p.Close()
+	p = newPooledPacket(188)
+	assert.Len(t, p.Bytes, 188)
+
+	// Packet is reset when parsed again
+	b, ep := packet(PacketHeader{PID: 1}, PacketAdaptationField{}, []byte("payload"))
+	p.AdaptationField = &PacketAdaptationField{}
+	assert.NoError(t, parsePacketInto(p, b))
+	assert.Equal(t, ep.Payload, p.Payload)
+	assert.Equal(t, uint16(1), p.Header.PID)
+}
@@ -0,0 +1,72 @@
+package astits
+
+// PESPacketizer represents an object capable of splitting PES data into 188 bytes packets of a PID
+// It keeps track of the continuity counter of the PID between calls
+type PESPacketizer struct {
+	continuityCounter uint8
+	pid               uint16
+}
+
+// NewPESPacketizer creates a new PES packetizer for a PID
+func NewPESPacketizer(pid uint16) *PESPacketizer {
+	return &PESPacketizer{pid: pid}
+}
+
+// Packetize serializes a PES data and splits it into packets that are returned as consecutive 188 bytes slices
+// The first packet has its payload unit start indicator set and carries the adaptation field, if any. The last packet
+// is stuffed in its adaptation field
+func (p *PESPacketizer) Packetize(d *PESData, a *PacketAdaptationField) (b []byte) {
+	for _, pkt := range packetizePayload(p.pid, d.Serialize(), a, false, &p.continuityCounter) {
+		b = append(b, writePacket(pkt)...)
+	}
+	return
+}
+
+// packetizePayload splits a payload into packets
+// The adaptation field, if any, is written in the first packet only. When stuffPayload is true, the last packet is
+// stuffed with 0xff bytes in its payload as it's done for PSI, otherwise it's stuffed in its adaptation field.
+// cc contains the next continuity counter to use and is updated
+func packetizePayload(pid uint16, b []byte, a *PacketAdaptationField, stuffPayload bool, cc *uint8) (ps []*Packet) {
+	var pusi = true
+	for len(b) > 0 {
+		// Init packet
+		var p = &Packet{
+			AdaptationField: a,
+			Header:          &PacketHeader{PID: pid},
+		}
+
+		// Compute the available payload size
+		var max = MpegTsPacketSize - 4
+		if a != nil {
+			max -= 1 + packetAdaptationFieldMinimumLength(a)
+			a = nil
+		}
+
+		// Add payload
+		if max > 0 {
+			var l = max
+			if len(b) < l {
+				l = len(b)
+			}
+			p.Payload = b[:l]
+			b = b[l:]
+			if stuffPayload && l < max {
+				p.Payload = append(append([]byte{}, p.Payload...), make([]byte, max-l)...)
+				for idx := l; idx < max; idx++ {
+					p.Payload[idx] = 0xff
+				}
+			}
+			p.Header.ContinuityCounter = *cc
+			p.Header.PayloadUnitStartIndicator = pusi
+			*cc = (*cc + 1) % 16
+			pusi = false
+		} else {
+			// Packets without payload must not increment the continuity counter
+			p.Header.ContinuityCounter = (*cc + 15) % 16
+		}
+
+		// Append packet
+		ps = append(ps, p)
+	}
+	return
+}
@@ -0,0 +1,61 @@
+package astits
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPESPacketizer(t *testing.T) {
+	// Init
+	p := NewPESPacketizer(0x100)
+	d := &PESData{
+		Data: bytes.Repeat([]byte("data"), 100),
+		Header: &PESHeader{
+			OptionalHeader: &PESOptionalHeader{DataAlignmentIndicator: true, PTS: ptsClockReference},
+			StreamID:       0xe0,
+		},
+	}
+
+	// Packetize
+	b := p.Packetize(d, &PacketAdaptationField{HasPCR: true, PCR: pcr})
+	assert.Len(t, b, 3*MpegTsPacketSize)
+	b = append(b, p.Packetize(d, nil)...)
+	assert.Len(t, b, 6*MpegTsPacketSize)
+
+	// Parse packets
+	var ps []*Packet
+	for idx := 0; idx < 6; idx++ {
+		pkt, err := parsePacket(b[idx*MpegTsPacketSize : (idx+1)*MpegTsPacketSize])
+		assert.NoError(t, err)
+		assert.Equal(t, uint16(0x100), pkt.Header.PID)
+		assert.Equal(t, uint8(idx), pkt.Header.ContinuityCounter)
+		assert.Equal(t, idx%3 == 0, pkt.Header.PayloadUnitStartIndicator)
+		ps = append(ps, pkt)
+	}
+	assert.Equal(t, pcr, ps[0].AdaptationField.PCR)
+	assert.Nil(t, ps[3].AdaptationField)
+	assert.True(t, ps[5].Header.HasAdaptationField)
+
+	// Reassemble
+	var pl []byte
+	for _, pkt := range ps[:3] {
+		pl = append(pl, pkt.Payload...)
+	}
+	o, err := parsePESData(pl)
+	assert.NoError(t, err)
+	assert.Equal(t, d.Data, o.Data)
+	assert.Equal(t, ptsClockReference, o.Header.OptionalHeader.PTS)
+}
+
+func TestPacketizePayload(t *testing.T) {
+	// Payload stuffing
+	var cc = uint8(15)
+	ps := packetizePayload(0x100, []byte{0x1, 0x2}, nil, true, &cc)
+	assert.Len(t, ps, 1)
+	assert.Equal(t, uint8(15), ps[0].Header.ContinuityCounter)
+	assert.Equal(t, uint8(0), cc)
+	assert.Len(t, ps[0].Payload, MpegTsPacketSize-4)
+	assert.Equal(t, []byte{0x1, 0x2, 0xff}, ps[0].Payload[:3])
+}
@@ -0,0 +1,88 @@
+package astits
+
+// pcrTracker keeps track of the program clocks of the stream
+type pcrTracker struct {
+	pcrPIDs map[uint16]uint16          // PCR PIDs indexed by PID
+	pcrs    map[uint16]*ClockReference // Last PCRs indexed by PCR PID
+	units   map[uint16]*ClockReference // Last PCRs received before the first packet of the payload unit being buffered, indexed by PID
+}
+
+// newPCRTracker creates a new PCR tracker
+func newPCRTracker() *pcrTracker {
+	return &pcrTracker{
+		pcrPIDs: make(map[uint16]uint16),
+		pcrs:    make(map[uint16]*ClockReference),
+		units:   make(map[uint16]*ClockReference),
+	}
+}
+
+// add updates the program clocks with a packet and returns the PCR of the payload unit the packet terminates, if any
+// The PCR of a packet starting a payload unit is taken into account for that unit
+func (t *pcrTracker) add(p *Packet) (unit *ClockReference) {
+	// Update PCR
+	if p.Header.HasAdaptationField && p.AdaptationField.HasPCR && p.AdaptationField.PCR != nil {
+		var c = *p.AdaptationField.PCR
+		t.pcrs[p.Header.PID] = &c
+	}
+
+	// Update payload unit
+	unit = t.units[p.Header.PID]
+	if p.Header.PayloadUnitStartIndicator {
+		t.units[p.Header.PID] = t.pcr(p.Header.PID)
+	}
+	return
+}
+
+// pcr returns the last PCR of the program clock a PID belongs to
+func (t *pcrTracker) pcr(pid uint16) *ClockReference {
+	if pcrPID, ok := t.pcrPIDs[pid]; ok {
+		return t.pcrs[pcrPID]
+	}
+	return t.pcrs[pid]
+}
+
+// setProgram maps the PIDs of a program to its PCR PID
+func (t *pcrTracker) setProgram(d *PMTData) {
+	if d.PCRPID == PIDNull {
+		return
+	}
+	for _, es := range d.ElementaryStreams {
+		t.pcrPIDs[es.ElementaryPID] = d.PCRPID
+	}
+}
+
+// updatePESData sets the PCR of PES data and unwraps its clocks if needed
+func (dmx *Demuxer) updatePESData(ds []*Data, pcr *ClockReference) {
+	for _, d := range ds {
+		// Only PES data is updated
+		if d.PES == nil {
+			continue
+		}
+		d.PCR = pcr
+
+		// Unwrap clocks
+		if !dmx.optUnwrapClocks {
+			continue
+		}
+		var pid = d.PID
+		if pcrPID, ok := dmx.pcrTracker.pcrPIDs[d.PID]; ok {
+			pid = pcrPID
+		}
+		u, ok := dmx.clockUnwrappers[pid]
+		if !ok {
+			u = NewClockUnwrapper()
+			dmx.clockUnwrappers[pid] = u
+		}
+		d.PCR = u.Unwrap(d.PCR)
+		if h := d.PES.Header.OptionalHeader; h != nil {
+			h.DTS = u.Unwrap(h.DTS)
+			h.PTS = u.Unwrap(h.PTS)
+		}
+	}
+}
+
+// PCR returns the last PCR received on the program clock the PID belongs to, or nil if none has been received yet
+// The PID can either be the PCR PID of a program or the PID of one of its elementary streams
+func (dmx *Demuxer) PCR(pid uint16) *ClockReference {
+	return dmx.pcrTracker.pcr(pid)
+}
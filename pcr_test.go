@@ -0,0 +1,80 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDemuxerPCR(t *testing.T) {
+	// Init
+	buf := &bytes.Buffer{}
+	m := NewMuxer(context.Background(), buf)
+	m.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x100, StreamType: StreamTypeLowerBitrateVideo})
+	m.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x101, StreamType: StreamTypeMPEG1Audio})
+	var pes = func(streamID uint8) *PESData {
+		return &PESData{Data: []byte("data"), Header: &PESHeader{OptionalHeader: &PESOptionalHeader{}, StreamID: streamID}}
+	}
+	m.WriteData(&MuxerData{PES: pes(0xc0), PID: 0x101})
+	m.WriteTables()
+	m.WriteData(&MuxerData{AdaptationField: &PacketAdaptationField{HasPCR: true, PCR: &ClockReference{Base: 900, Extension: 1}}, PES: pes(0xe0), PID: 0x100})
+	m.WriteData(&MuxerData{PES: pes(0xc0), PID: 0x101})
+	m.WriteData(&MuxerData{AdaptationField: &PacketAdaptationField{HasPCR: true, PCR: &ClockReference{Base: 1800}}, PES: pes(0xe0), PID: 0x100})
+	m.WriteData(&MuxerData{PES: pes(0xc0), PID: 0x101})
+
+	// Demux
+	dmx := New(context.Background(), bytes.NewReader(buf.Bytes()))
+	assert.Nil(t, dmx.PCR(0x100))
+	var pcrs = make(map[uint16][]*ClockReference)
+	for {
+		d, err := dmx.NextData()
+		if err != nil {
+			assert.Equal(t, ErrNoMorePackets, err)
+			break
+		}
+		if d.PES != nil {
+			pcrs[d.PID] = append(pcrs[d.PID], d.PCR)
+		}
+	}
+	assert.Equal(t, []*ClockReference{{Base: 900, Extension: 1}, {Base: 1800}}, pcrs[0x100])
+	assert.Equal(t, []*ClockReference{nil, {Base: 900, Extension: 1}, {Base: 1800}}, pcrs[0x101])
+	assert.Equal(t, &ClockReference{Base: 1800}, dmx.PCR(0x100))
+	assert.Equal(t, &ClockReference{Base: 1800}, dmx.PCR(0x101))
+	assert.Nil(t, dmx.PCR(0x102))
+}
+
+func TestDemuxerOptUnwrapClocks(t *testing.T) {
+	// Init
+	buf := &bytes.Buffer{}
+	m := NewMuxer(context.Background(), buf)
+	m.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x100, StreamType: StreamTypeLowerBitrateVideo})
+	m.WriteTables()
+	for _, b := range []int{clockReferenceBaseWrap - 9000, 0, 9000} {
+		m.WriteData(&MuxerData{
+			AdaptationField: &PacketAdaptationField{HasPCR: true, PCR: &ClockReference{Base: b}},
+			PES:             &PESData{Data: []byte("data"), Header: &PESHeader{OptionalHeader: &PESOptionalHeader{PTS: &ClockReference{Base: (b + 3000) % clockReferenceBaseWrap}}, StreamID: 0xe0}},
+			PID:             0x100,
+		})
+		m.WriteTables()
+	}
+
+	// Demux
+	dmx := New(context.Background(), bytes.NewReader(buf.Bytes()), OptUnwrapClocks())
+	var pcrs, ptss []int
+	for {
+		d, err := dmx.NextData()
+		if err != nil {
+			assert.Equal(t, ErrNoMorePackets, err)
+			break
+		}
+		if d.PES != nil {
+			pcrs = append(pcrs, d.PCR.Base)
+			ptss = append(ptss, d.PES.Header.OptionalHeader.PTS.Base)
+		}
+	}
+	assert.Equal(t, []int{clockReferenceBaseWrap - 9000, clockReferenceBaseWrap, clockReferenceBaseWrap + 9000}, pcrs)
+	assert.Equal(t, []int{clockReferenceBaseWrap - 6000, clockReferenceBaseWrap + 3000, clockReferenceBaseWrap + 12000}, ptss)
+	assert.Equal(t, &ClockReference{Base: 9000}, dmx.PCR(0x100))
+}
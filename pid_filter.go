@@ -0,0 +1,92 @@
+package astits
+
+// pidFilter represents a set of PIDs packets are filtered with
+// In whitelist mode, only packets whose PID is in the set are kept whereas in blacklist mode packets whose PID is in the
+// set are dropped
+type pidFilter struct {
+	pids      map[uint16]bool
+	whitelist bool
+}
+
+// newPIDFilter creates a new PID filter
+func newPIDFilter(whitelist bool, pids ...uint16) (f *pidFilter) {
+	f = &pidFilter{
+		pids:      make(map[uint16]bool),
+		whitelist: whitelist,
+	}
+	for _, pid := range pids {
+		f.pids[pid] = true
+	}
+	return
+}
+
+// allows checks whether packets of a PID are kept by the filter
+func (f *pidFilter) allows(pid uint16) bool {
+	return f.pids[pid] == f.whitelist
+}
+
+// rawPacketPID returns the PID of a packet that hasn't been parsed yet
+// ok is false when the packet doesn't start with a sync byte where it's expected
+func rawPacketPID(i []byte) (pid uint16, ok bool) {
+	var so = packetSyncOffset(len(i))
+	if len(i) < so+3 || i[so] != syncByte {
+		return
+	}
+	return uint16(i[so+1]&0x1f)<<8 | uint16(i[so+2]), true
+}
+
+// add adds PIDs to the filter
+func (f *pidFilter) add(pids ...uint16) {
+	for _, pid := range pids {
+		f.pids[pid] = true
+	}
+}
+
+// set replaces the PIDs of the filter
+func (f *pidFilter) set(pids ...uint16) {
+	f.pids = make(map[uint16]bool)
+	f.add(pids...)
+}
+
+// programFilter restricts a PID whitelist to the PIDs of a single program, which are learned from the tables
+// Only the PAT is allowed until the PMT PID of the program is announced, and only the PAT, the PMT, the PCR PID and the
+// elementary PIDs of the program are allowed once its PMT has been received
+type programFilter struct {
+	pids          *pidFilter
+	programNumber uint16
+}
+
+// newProgramFilter creates a new program filter
+func newProgramFilter(programNumber uint16) *programFilter {
+	return &programFilter{
+		pids:          newPIDFilter(true, PIDPAT),
+		programNumber: programNumber,
+	}
+}
+
+// setPAT allows the PMT PID of the program if it's announced in a PAT section
+func (f *programFilter) setPAT(d *PATData) {
+	for _, pgm := range d.Programs {
+		if pgm.ProgramNumber == f.programNumber {
+			f.pids.add(pgm.ProgramMapID)
+		}
+	}
+}
+
+// setPMT allows the PIDs announced in the PMT of the program
+func (f *programFilter) setPMT(pid uint16, d *PMTData) {
+	// PMT is not the one of the program
+	if d.ProgramNumber != f.programNumber {
+		return
+	}
+
+	// Set PIDs
+	var pids = []uint16{PIDPAT, pid}
+	if d.PCRPID != PIDNull {
+		pids = append(pids, d.PCRPID)
+	}
+	for _, es := range d.ElementaryStreams {
+		pids = append(pids, es.ElementaryPID)
+	}
+	f.pids.set(pids...)
+}
@@ -0,0 +1,350 @@
+package astits
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// PIDRemapper represents an object capable of renumbering PIDs on the fly
+// Packets are written on their new PID with continuity counters renumbered per output PID, so that streams whose PIDs
+// are remapped to the same PIDs, for instance when combining several single program streams one after the other,
+// stay continuous. The PAT and the PMTs are not copied but rewritten as soon as they're complete to reference the new
+// PIDs. Tables other than the PMTs carried on PMT PIDs, as well as sections that are not applicable yet, are not
+// rewritten and are therefore dropped.
+type PIDRemapper struct {
+	counters   map[uint16]*pidRemapperCounter // Indexed by output PID
+	dropPIDs   map[uint16]bool
+	pat        *PATData            // Last rewritten PAT
+	pids       map[uint16]uint16   // Indexed by input PID, contains the output PID
+	pmts       map[uint16]*PMTData // Indexed by output PID, contains the last rewritten PMT
+	program    *pidRemapperProgram
+	programMap programMap          // Contains the input PMT PIDs announced in the PAT
+	sections   map[uint16][][]byte // Indexed by output PID, contains the last rewritten sections
+	skipPAT    bool                // When true, the rewritten PAT is kept but not written
+	tables     map[uint16][]byte   // Indexed by input PID, contains the payload of the tables being reassembled
+}
+
+// pidRemapperProgram represents the only program kept by a PID remapper
+type pidRemapperProgram struct {
+	number uint16
+	pids   map[uint16]bool // Input PIDs of the program, learned from the PAT and the PMT
+}
+
+// pidRemapperCounter represents the continuity counter of an output PID
+type pidRemapperCounter struct {
+	hasPrevious bool
+	next        uint8  // Next continuity counter to use
+	previousCC  uint8  // Input continuity counter of the last packet with a payload
+	previousPID uint16 // Input PID of the last packet with a payload
+}
+
+// NewPIDRemapper creates a new PID remapper based on a map of the output PIDs indexed by input PIDs
+// PIDs that are not in the map are kept as is
+func NewPIDRemapper(pids map[uint16]uint16) *PIDRemapper {
+	return newPIDRemapper(pids, make(map[uint16]bool))
+}
+
+// newPIDRemapper creates a new PID remapper that also drops the packets of the provided PIDs
+func newPIDRemapper(pids map[uint16]uint16, dropPIDs map[uint16]bool) *PIDRemapper {
+	return &PIDRemapper{
+		counters:   make(map[uint16]*pidRemapperCounter),
+		dropPIDs:   dropPIDs,
+		pids:       pids,
+		pmts:       make(map[uint16]*PMTData),
+		programMap: newProgramMap(),
+		sections:   make(map[uint16][][]byte),
+		tables:     make(map[uint16][]byte),
+	}
+}
+
+// keepProgram makes the PID remapper drop packets that don't belong to a program, as well as the other programs of
+// the PAT
+func (r *PIDRemapper) keepProgram(number uint16) {
+	r.program = &pidRemapperProgram{
+		number: number,
+		pids:   make(map[uint16]bool),
+	}
+}
+
+// Remap remaps a packet and returns the 188 bytes packets to write in its place
+// Nothing is returned while a PAT or a PMT is being reassembled, whereas all the packets of the rewritten table are
+// returned once it's complete
+func (r *PIDRemapper) Remap(p *Packet) (b []byte, err error) {
+	// PID is dropped
+	var pid = p.Header.PID
+	if r.dropPIDs[pid] || (r.program != nil && pid != PIDPAT && !r.program.pids[pid]) {
+		return
+	}
+
+	// Tables are rewritten
+	if pid == PIDPAT || r.programMap.exists(pid) {
+		if b, err = r.addTablePacket(p); err != nil {
+			err = errors.Wrapf(err, "astits: adding table packet of PID %d failed", pid)
+			return
+		}
+		return
+	}
+
+	// Copy packet
+	var outPID = r.outputPID(pid)
+	b = make([]byte, MpegTsPacketSize)
+	if len(p.Bytes) > 0 {
+		copy(b, p.Bytes[packetSyncOffset(len(p.Bytes)):])
+	} else {
+		var s = writePacket(p)
+		copy(b, s[len(s)-MpegTsPacketSize:])
+	}
+	b[1] = b[1]&0xe0 | uint8(outPID>>8)&0x1f
+	b[2] = uint8(outPID)
+	b[3] = b[3]&0xf0 | r.continuityCounter(outPID, p)
+	return
+}
+
+// continuityCounter returns the continuity counter of a packet written on an output PID
+// Duplicate packets keep the continuity counter of the packet they duplicate, and packets without payload keep the
+// continuity counter of the last packet with a payload
+func (r *PIDRemapper) continuityCounter(outPID uint16, p *Packet) (cc uint8) {
+	// Get counter
+	c, ok := r.counters[outPID]
+	if !ok {
+		c = &pidRemapperCounter{}
+		r.counters[outPID] = c
+	}
+
+	// Packet is a duplicate or has no payload
+	var previous = (c.next + 15) % 16
+	if !p.Header.HasPayload {
+		return previous
+	} else if c.hasPrevious && c.previousPID == p.Header.PID && c.previousCC == p.Header.ContinuityCounter {
+		return previous
+	}
+
+	// Increment
+	cc = c.next
+	c.hasPrevious = true
+	c.next = (c.next + 1) % 16
+	c.previousCC = p.Header.ContinuityCounter
+	c.previousPID = p.Header.PID
+	return
+}
+
+// addTablePacket reassembles the payload of a table and returns the packets of the rewritten sections once it's
+// complete
+func (r *PIDRemapper) addTablePacket(p *Packet) (b []byte, err error) {
+	// Reassemble payload
+	var pid = p.Header.PID
+	if !p.Header.HasPayload {
+		return
+	} else if p.Header.PayloadUnitStartIndicator {
+		r.tables[pid] = append([]byte{}, p.Payload...)
+	} else if _, ok := r.tables[pid]; ok {
+		r.tables[pid] = append(r.tables[pid], p.Payload...)
+	} else {
+		return
+	}
+
+	// Payload is not complete
+	if !isPSIPayloadComplete(r.tables[pid]) {
+		return
+	}
+	var payload = r.tables[pid]
+	delete(r.tables, pid)
+
+	// Parse payload
+	// Sections with an invalid CRC32 are dropped since they can't be trusted anymore once rewritten
+	var d *PSIData
+	if d, err = parsePSIData(payload, pid, CRCModeDrop); err != nil {
+		err = errors.Wrap(err, "astits: parsing PSI data failed")
+		return
+	}
+
+	// Loop through sections
+	var ss [][]byte
+	for _, s := range d.Sections {
+		// Only current sections are rewritten
+		if s.Syntax == nil || s.Syntax.Data == nil || s.Syntax.Header == nil || !s.Syntax.Header.CurrentNextIndicator {
+			continue
+		}
+
+		// Rewrite section
+		if s.Syntax.Data.PAT != nil {
+			if r.pat = r.rewritePAT(s.Syntax.Data.PAT); !r.skipPAT {
+				var ps [][]byte
+				if ps, err = r.pat.Serialize(s.Syntax.Header.VersionNumber); err != nil {
+					err = errors.Wrap(err, "astits: serializing PAT failed")
+					return
+				}
+				ss = append(ss, ps...)
+			}
+		} else if s.Syntax.Data.PMT != nil {
+			// Program is not kept
+			if r.program != nil && s.Syntax.Data.PMT.ProgramNumber != r.program.number {
+				continue
+			}
+
+			// Serialize
+			var pmt = r.rewritePMT(pid, s.Syntax.Data.PMT)
+			r.pmts[r.outputPID(pid)] = pmt
+			var sb []byte
+			if sb, err = pmt.Serialize(s.Syntax.Header.VersionNumber); err != nil {
+				err = errors.Wrap(err, "astits: serializing PMT failed")
+				return
+			}
+			ss = append(ss, sb)
+		}
+	}
+
+	// Nothing to write
+	if len(ss) == 0 {
+		return
+	}
+
+	// Packetize sections
+	var outPID = r.outputPID(pid)
+	r.sections[outPID] = ss
+	b = r.packetizeSections(outPID, ss)
+	return
+}
+
+// packetizeSections splits sections into packets written on an output PID
+func (r *PIDRemapper) packetizeSections(outPID uint16, ss [][]byte) (b []byte) {
+	c, ok := r.counters[outPID]
+	if !ok {
+		c = &pidRemapperCounter{}
+		r.counters[outPID] = c
+	}
+	for _, p := range packetizePayload(outPID, writePSIPayload(ss), nil, true, &c.next) {
+		b = append(b, writePacket(p)...)
+	}
+	c.hasPrevious = false
+	return
+}
+
+// Tables returns the packets of the last rewritten PAT and PMTs, the PAT first and the PMTs sorted by PID, so that
+// they can be repeated independently from the input
+func (r *PIDRemapper) Tables() (b []byte) {
+	// Sort PIDs
+	var pids []int
+	for pid := range r.sections {
+		pids = append(pids, int(pid))
+	}
+	sort.Ints(pids)
+
+	// Packetize sections
+	for _, pid := range pids {
+		b = append(b, r.packetizeSections(uint16(pid), r.sections[uint16(pid)])...)
+	}
+	return
+}
+
+// referenceStream returns the output PID and the stream type of the first video elementary stream of the first
+// program of the PAT whose PMT has been rewritten, or of its first elementary stream when it has no video, or PIDNull
+// when there is none yet
+func (r *PIDRemapper) referenceStream() (pid uint16, streamType uint8) {
+	pid = PIDNull
+	if r.pat == nil {
+		return
+	}
+	for _, pgm := range r.pat.Programs {
+		// PMT has not been rewritten yet
+		pmt, ok := r.pmts[pgm.ProgramMapID]
+		if !ok || len(pmt.ElementaryStreams) == 0 {
+			continue
+		}
+
+		// Look for video
+		for _, es := range pmt.ElementaryStreams {
+			if isVideoStreamType(es.StreamType) {
+				return es.ElementaryPID, es.StreamType
+			}
+		}
+		return pmt.ElementaryStreams[0].ElementaryPID, pmt.ElementaryStreams[0].StreamType
+	}
+	return
+}
+
+// isPSIPayloadComplete checks whether a PSI payload contains complete sections only
+func isPSIPayloadComplete(i []byte) bool {
+	// Pointer field
+	if len(i) == 0 {
+		return false
+	}
+	var offset = 1 + int(i[0])
+
+	// Loop through sections
+	for offset < len(i) && i[offset] != 0xff {
+		if offset+3 > len(i) {
+			return false
+		}
+		offset += 3 + (int(i[offset+1]&0xf)<<8 | int(i[offset+2]))
+	}
+	return offset <= len(i)
+}
+
+// outputPID returns the PID packets of an input PID are written on
+func (r *PIDRemapper) outputPID(pid uint16) uint16 {
+	if v, ok := r.pids[pid]; ok {
+		return v
+	}
+	return pid
+}
+
+// rewritePAT updates the PMT PIDs with a PAT and returns the PAT referencing the programs whose PMT PID is not dropped
+func (r *PIDRemapper) rewritePAT(d *PATData) (o *PATData) {
+	o = &PATData{TransportStreamID: d.TransportStreamID}
+	for _, pgm := range d.Programs {
+		// Program number 0 is reserved to NIT
+		if pgm.ProgramNumber > 0 {
+			r.programMap.set(pgm.ProgramMapID, pgm.ProgramNumber)
+		}
+
+		// PMT PID is dropped or program is not kept
+		if r.dropPIDs[pgm.ProgramMapID] {
+			continue
+		} else if r.program != nil {
+			if pgm.ProgramNumber != r.program.number {
+				continue
+			}
+			r.program.pids[pgm.ProgramMapID] = true
+		}
+		o.Programs = append(o.Programs, &PATProgram{ProgramMapID: r.outputPID(pgm.ProgramMapID), ProgramNumber: pgm.ProgramNumber})
+	}
+	return
+}
+
+// rewritePMT returns the PMT referencing the elementary streams whose PID is not dropped
+// When the PCR PID is dropped, the PMT signals that the program has no PCR. When only a program is kept, its PIDs
+// are updated with its PMT
+func (r *PIDRemapper) rewritePMT(pid uint16, d *PMTData) (o *PMTData) {
+	// Update program PIDs
+	if r.program != nil {
+		r.program.pids = map[uint16]bool{pid: true, d.PCRPID: true}
+		for _, es := range d.ElementaryStreams {
+			r.program.pids[es.ElementaryPID] = true
+		}
+	}
+
+	// Init
+	o = &PMTData{
+		PCRPID:             PIDNull,
+		ProgramDescriptors: d.ProgramDescriptors,
+		ProgramNumber:      d.ProgramNumber,
+	}
+
+	// PCR PID
+	if d.PCRPID != PIDNull && !r.dropPIDs[d.PCRPID] {
+		o.PCRPID = r.outputPID(d.PCRPID)
+	}
+
+	// Elementary streams
+	for _, es := range d.ElementaryStreams {
+		if r.dropPIDs[es.ElementaryPID] {
+			continue
+		}
+		var c = *es
+		c.ElementaryPID = r.outputPID(es.ElementaryPID)
+		o.ElementaryStreams = append(o.ElementaryStreams, &c)
+	}
+	return
+}
@@ -0,0 +1,72 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPIDRemapper(t *testing.T) {
+	// Init
+	r := NewPIDRemapper(map[uint16]uint16{0x100: 0x1e1})
+	o := &bytes.Buffer{}
+
+	// Combine 2 streams one after the other
+	for idx := 0; idx < 2; idx++ {
+		i := &bytes.Buffer{}
+		mx := NewMuxer(context.Background(), i)
+		mx.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x100, StreamType: StreamTypeLowerBitrateVideo})
+		for j := 0; j < 2; j++ {
+			mx.WriteData(&MuxerData{PES: &PESData{Data: []byte("data"), Header: &PESHeader{OptionalHeader: &PESOptionalHeader{}, StreamID: 0xe0}}, PID: 0x100})
+		}
+		b := i.Bytes()
+		for len(b) >= MpegTsPacketSize {
+			p, err := parsePacket(b[:MpegTsPacketSize])
+			assert.NoError(t, err)
+			rb, err := r.Remap(p)
+			assert.NoError(t, err)
+			o.Write(rb)
+
+			// Duplicate packets keep their continuity counter
+			if p.Header.PID == 0x100 {
+				rb, err = r.Remap(p)
+				assert.NoError(t, err)
+				o.Write(rb)
+			}
+			b = b[MpegTsPacketSize:]
+		}
+	}
+
+	// Demux
+	dmx := New(context.Background(), bytes.NewReader(o.Bytes()))
+	var pes int
+	for {
+		d, err := dmx.NextData()
+		if err != nil {
+			assert.Equal(t, ErrNoMorePackets, err)
+			break
+		}
+		if d.PMT != nil {
+			assert.Equal(t, uint16(0x1e1), d.PMT.PCRPID)
+			assert.Equal(t, uint16(0x1e1), d.PMT.ElementaryStreams[0].ElementaryPID)
+		} else if d.PES != nil {
+			pes++
+			assert.Equal(t, uint16(0x1e1), d.PID)
+		}
+	}
+	assert.Equal(t, 4, pes)
+	assert.Equal(t, int64(0), dmx.ContinuityErrors())
+}
+
+func TestIsPSIPayloadComplete(t *testing.T) {
+	ss, err := (&PATData{Programs: []*PATProgram{{ProgramMapID: 0x1000, ProgramNumber: 1}}}).Serialize(0)
+	assert.NoError(t, err)
+	s := ss[0]
+	b := append([]byte{0}, s...)
+	assert.False(t, isPSIPayloadComplete(b[:len(b)-1]))
+	assert.True(t, isPSIPayloadComplete(b))
+	assert.True(t, isPSIPayloadComplete(append(b, 0xff, 0xff)))
+	assert.False(t, isPSIPayloadComplete(append(b, s[:2]...)))
+}
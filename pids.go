@@ -0,0 +1,132 @@
+package astits
+
+import "sync"
+
+// PID types
+const (
+	PIDTypeNull    = "null"
+	PIDTypePAT     = "PAT"
+	PIDTypePCR     = "PCR" // PID only carrying PCRs
+	PIDTypePES     = "PES"
+	PIDTypePMT     = "PMT"
+	PIDTypePSI     = "PSI" // PID carrying tables other than the PAT and the PMTs, such as the CAT, DVB SI or SCTE-35 sections
+	PIDTypeUnknown = "unknown"
+)
+
+// PIDInfo represents what is known about a PID seen by the demuxer
+type PIDInfo struct {
+	HasPCR        bool
+	HasStreamType bool
+	IsScrambled   bool // Whether the last packet of the PID was scrambled
+	PID           uint16
+	StreamType    uint8 // Stream type announced in the PMT, if any
+	Type          string
+}
+
+// pidTracker keeps track of the PIDs seen by the demuxer
+type pidTracker struct {
+	m           *sync.Mutex
+	pids        map[uint16]*pidTrackerPID
+	streamTypes map[uint16]uint8 // Stream types announced in the PMTs indexed by elementary PID
+}
+
+// pidTrackerPID represents what has been seen on a PID
+type pidTrackerPID struct {
+	hasPCR      bool
+	hasPES      bool
+	hasPayload  bool
+	isScrambled bool
+}
+
+// newPIDTracker creates a new PID tracker
+func newPIDTracker() *pidTracker {
+	return &pidTracker{
+		m:           &sync.Mutex{},
+		pids:        make(map[uint16]*pidTrackerPID),
+		streamTypes: make(map[uint16]uint8),
+	}
+}
+
+// add updates the PIDs with a packet
+func (t *pidTracker) add(p *Packet) {
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// Get PID
+	v, ok := t.pids[p.Header.PID]
+	if !ok {
+		v = &pidTrackerPID{}
+		t.pids[p.Header.PID] = v
+	}
+
+	// Update
+	v.isScrambled = p.Header.TransportScramblingControl != ScramblingControlNotScrambled
+	if p.Header.HasAdaptationField && p.AdaptationField != nil && p.AdaptationField.HasPCR {
+		v.hasPCR = true
+	}
+	if p.Header.HasPayload {
+		v.hasPayload = true
+		if p.Header.PayloadUnitStartIndicator && !v.isScrambled && isPESPayload(p.Payload) {
+			v.hasPES = true
+		}
+	}
+}
+
+// setProgram stores the stream types announced in a PMT
+func (t *pidTracker) setProgram(d *PMTData) {
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// Set stream types
+	for _, es := range d.ElementaryStreams {
+		t.streamTypes[es.ElementaryPID] = es.StreamType
+	}
+}
+
+// snapshot returns a snapshot of the PIDs seen so far
+func (t *pidTracker) snapshot(pm, sm programMap) (ps map[uint16]*PIDInfo) {
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// Loop through PIDs
+	ps = make(map[uint16]*PIDInfo)
+	for pid, v := range t.pids {
+		// Init
+		var i = &PIDInfo{
+			HasPCR:      v.hasPCR,
+			IsScrambled: v.isScrambled,
+			PID:         pid,
+		}
+		i.StreamType, i.HasStreamType = t.streamTypes[pid]
+
+		// Infer type
+		switch {
+		case pid == PIDNull:
+			i.Type = PIDTypeNull
+		case pid == PIDPAT:
+			i.Type = PIDTypePAT
+		case pm.exists(pid):
+			i.Type = PIDTypePMT
+		case isPSIPayload(pid, pm, sm):
+			i.Type = PIDTypePSI
+		case v.hasPES || (i.HasStreamType && v.hasPayload):
+			i.Type = PIDTypePES
+		case v.hasPCR && !v.hasPayload:
+			i.Type = PIDTypePCR
+		default:
+			i.Type = PIDTypeUnknown
+		}
+		ps[pid] = i
+	}
+	return
+}
+
+// PIDs returns a snapshot of every PID seen so far, along with its type inferred from the tables and the packets
+// received, its stream type when it's announced in a PMT and its scrambling status
+// It can be called while the demuxer is streaming
+func (dmx *Demuxer) PIDs() map[uint16]*PIDInfo {
+	return dmx.pidTracker.snapshot(dmx.programMap, dmx.sectionMap)
+}
@@ -0,0 +1,40 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDemuxerPIDs(t *testing.T) {
+	// Init
+	buf := &bytes.Buffer{}
+	m := NewMuxer(context.Background(), buf)
+	m.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x100, StreamType: StreamTypeLowerBitrateVideo})
+	m.SetPCRPID(0x200)
+	m.WriteTables()
+	m.WriteData(&MuxerData{PES: &PESData{Data: []byte("data"), Header: &PESHeader{OptionalHeader: &PESOptionalHeader{}, StreamID: 0xe0}}, PID: 0x100})
+	buf.Write(writePacket(&Packet{AdaptationField: &PacketAdaptationField{HasPCR: true, Length: 183, PCR: &ClockReference{}}, Header: &PacketHeader{HasAdaptationField: true, PID: 0x200}}))
+	buf.Write(writePacket(&Packet{Header: &PacketHeader{HasPayload: true, PID: 0x300, PayloadUnitStartIndicator: true, TransportScramblingControl: ScramblingControlScrambledWithEvenKey}, Payload: make([]byte, 184)}))
+	buf.Write(writePacket(&Packet{Header: &PacketHeader{HasPayload: true, PID: PIDNull}, Payload: make([]byte, 184)}))
+	m.WriteTables()
+
+	// Demux
+	dmx := New(context.Background(), bytes.NewReader(buf.Bytes()))
+	for {
+		if _, err := dmx.NextData(); err != nil {
+			assert.Equal(t, ErrNoMorePackets, err)
+			break
+		}
+	}
+	assert.Equal(t, map[uint16]*PIDInfo{
+		PIDPAT:  {PID: PIDPAT, Type: PIDTypePAT},
+		PIDNull: {PID: PIDNull, Type: PIDTypeNull},
+		0x100:   {HasStreamType: true, PID: 0x100, StreamType: StreamTypeLowerBitrateVideo, Type: PIDTypePES},
+		0x200:   {HasPCR: true, PID: 0x200, Type: PIDTypePCR},
+		0x300:   {IsScrambled: true, PID: 0x300, Type: PIDTypeUnknown},
+		0x1000:  {PID: 0x1000, Type: PIDTypePMT},
+	}, dmx.PIDs())
+}
@@ -0,0 +1,96 @@
+package astits
+
+import (
+	"sort"
+	"sync"
+)
+
+// Program represents a program announced in the PAT, along with the content of its PMT once it has been received
+type Program struct {
+	ElementaryStreams  []*PMTElementaryStream
+	HasPMT             bool // Whether the PMT of the program has been received
+	PCRPID             uint16
+	PMTPID             uint16
+	ProgramDescriptors []*Descriptor
+	ProgramNumber      uint16
+}
+
+// SubtitleTracks returns the DVB subtitle tracks announced in the last PMT of the program
+func (p *Program) SubtitleTracks() []*SubtitleTrack {
+	return subtitleTracks(p.ElementaryStreams)
+}
+
+// programTracker keeps track of the programs of the stream
+type programTracker struct {
+	m        *sync.Mutex
+	programs map[uint16]*Program // Indexed by program number
+}
+
+// newProgramTracker creates a new program tracker
+func newProgramTracker() *programTracker {
+	return &programTracker{
+		m:        &sync.Mutex{},
+		programs: make(map[uint16]*Program),
+	}
+}
+
+// setPAT updates the programs announced in a PAT section
+// Since the PAT may be split over several sections, programs are never removed
+func (t *programTracker) setPAT(d *PATData) {
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// Loop through programs
+	for _, pgm := range d.Programs {
+		// Program number 0 is reserved to NIT
+		if pgm.ProgramNumber == 0 {
+			continue
+		}
+
+		// Program is already known with the same PMT PID
+		if p, ok := t.programs[pgm.ProgramNumber]; ok && p.PMTPID == pgm.ProgramMapID {
+			continue
+		}
+		t.programs[pgm.ProgramNumber] = &Program{PCRPID: PIDNull, PMTPID: pgm.ProgramMapID, ProgramNumber: pgm.ProgramNumber}
+	}
+}
+
+// setPMT updates a program with its PMT
+func (t *programTracker) setPMT(pid uint16, d *PMTData) {
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// Update program
+	t.programs[d.ProgramNumber] = &Program{
+		ElementaryStreams:  d.ElementaryStreams,
+		HasPMT:             true,
+		PCRPID:             d.PCRPID,
+		PMTPID:             pid,
+		ProgramDescriptors: d.ProgramDescriptors,
+		ProgramNumber:      d.ProgramNumber,
+	}
+}
+
+// snapshot returns the programs sorted by program number
+func (t *programTracker) snapshot() (ps []*Program) {
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// Copy programs
+	for _, p := range t.programs {
+		var c = *p
+		ps = append(ps, &c)
+	}
+	sort.Slice(ps, func(i, j int) bool { return ps[i].ProgramNumber < ps[j].ProgramNumber })
+	return
+}
+
+// Programs returns the programs announced in the PAT so far, sorted by program number, with the PCR PID, the
+// descriptors and the elementary streams of their last PMT
+// It can be called while the demuxer is streaming
+func (dmx *Demuxer) Programs() []*Program {
+	return dmx.programTracker.snapshot()
+}
@@ -0,0 +1,52 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDemuxerPrograms(t *testing.T) {
+	// Init
+	buf := &bytes.Buffer{}
+	m := NewMuxer(context.Background(), buf, MuxerOptProgramNumber(2))
+	m.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x100, StreamType: StreamTypeLowerBitrateVideo})
+	m.SetPCRPID(0x100)
+	m.WriteTables()
+	m.WriteTables()
+	m.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x101, StreamType: StreamTypeMPEG1Audio})
+	m.WriteTables()
+	m.WriteTables()
+	dmx := New(context.Background(), bytes.NewReader(buf.Bytes()))
+	assert.Empty(t, dmx.Programs())
+
+	// PAT and PMT
+	for {
+		d, err := dmx.NextData()
+		assert.NoError(t, err)
+		if d.PMT != nil {
+			break
+		}
+	}
+	assert.Equal(t, []*Program{{
+		ElementaryStreams: []*PMTElementaryStream{{ElementaryPID: 0x100, StreamType: StreamTypeLowerBitrateVideo}},
+		HasPMT:            true,
+		PCRPID:            0x100,
+		PMTPID:            0x1000,
+		ProgramNumber:     2,
+	}}, dmx.Programs())
+
+	// PMT update
+	for {
+		if _, err := dmx.NextData(); err != nil {
+			assert.Equal(t, ErrNoMorePackets, err)
+			break
+		}
+	}
+	ps := dmx.Programs()
+	assert.Len(t, ps, 1)
+	assert.Len(t, ps[0].ElementaryStreams, 2)
+	assert.Equal(t, uint16(0x101), ps[0].ElementaryStreams[1].ElementaryPID)
+}
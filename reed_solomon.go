@@ -0,0 +1,49 @@
+package astits
+
+// Reed-Solomon codes are computed over GF(2^8) with the field generator polynomial x^8 + x^4 + x^3 + x^2 + 1, which
+// is the one used by both DVB (RS(204,188)) and ATSC (RS(207,187))
+// http://www.etsi.org/deliver/etsi_en/300400_300499/300421/01.01.02_60/en_300421v010102p.pdf
+const reedSolomonFieldPolynomial = 0x11d
+
+// Reed-Solomon tables
+var reedSolomonExp, reedSolomonLog = reedSolomonTables()
+
+// reedSolomonTables builds the exponential and logarithm tables of the field
+func reedSolomonTables() (exp [512]uint8, log [256]uint8) {
+	var x = 1
+	for i := 0; i < 255; i++ {
+		exp[i] = uint8(x)
+		log[x] = uint8(i)
+		if x <<= 1; x&0x100 > 0 {
+			x ^= reedSolomonFieldPolynomial
+		}
+	}
+	for i := 255; i < 512; i++ {
+		exp[i] = exp[i-255]
+	}
+	return
+}
+
+// reedSolomonMultiply multiplies 2 elements of the field
+func reedSolomonMultiply(a, b uint8) uint8 {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return reedSolomonExp[int(reedSolomonLog[a])+int(reedSolomonLog[b])]
+}
+
+// reedSolomonIsValid checks whether a codeword ending with nroots parity bytes has no error which is the case when all
+// its syndromes are equal to 0
+// Shortened codes don't need to be padded since leading zeros don't change syndromes
+func reedSolomonIsValid(codeword []byte, nroots int) bool {
+	for j := 0; j < nroots; j++ {
+		var s uint8
+		for _, c := range codeword {
+			s = reedSolomonMultiply(s, reedSolomonExp[j]) ^ c
+		}
+		if s != 0 {
+			return false
+		}
+	}
+	return true
+}
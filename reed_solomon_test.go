@@ -0,0 +1,41 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// reedSolomonParity computes the nroots parity bytes of data
+func reedSolomonParity(data []byte, nroots int) []byte {
+	// Build generator polynomial whose roots are alpha^0 to alpha^(nroots - 1), highest degree first
+	var g = []uint8{1}
+	for i := 0; i < nroots; i++ {
+		var n = make([]uint8, len(g)+1)
+		for j, c := range g {
+			n[j] ^= c
+			n[j+1] ^= reedSolomonMultiply(c, reedSolomonExp[i])
+		}
+		g = n
+	}
+
+	// Divide data * x^nroots by the generator polynomial
+	var r = make([]uint8, nroots)
+	for _, d := range data {
+		var f = d ^ r[0]
+		copy(r, r[1:])
+		r[nroots-1] = 0
+		for j := 0; j < nroots; j++ {
+			r[j] ^= reedSolomonMultiply(f, g[j+1])
+		}
+	}
+	return r
+}
+
+func TestReedSolomonIsValid(t *testing.T) {
+	var d = append([]byte{syncByte}, []byte("payload")...)
+	var c = append(d, reedSolomonParity(d, 16)...)
+	assert.True(t, reedSolomonIsValid(c, 16))
+	c[3] ^= 0x1
+	assert.False(t, reedSolomonIsValid(c, 16))
+}
@@ -0,0 +1,158 @@
+package astits
+
+import (
+	"context"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Remuxer represents a remuxer
+// It reads packets with a demuxer and copies them to a writer as 188 bytes packets, dropping and remapping PIDs on the
+// way with a PIDRemapper. The PAT and the PMTs are rewritten to only reference the PIDs that survive, with their new
+// values, whereas other packets are copied untouched except for their PID and their continuity counter.
+type Remuxer struct {
+	dmx               *Demuxer
+	dropPIDs          map[uint16]bool
+	optDemuxerOpts    []func(*Demuxer)
+	optProgramNumber  *uint16
+	optTablesInterval int
+	packets           int               // Number of packets written since the tables were last repeated
+	pidMap            map[uint16]uint16 // Indexed by input PID, contains the output PID
+	remapper          *PIDRemapper
+	w                 io.Writer
+}
+
+// NewRemuxer creates a new remuxer reading packets from a reader and writing them to a writer
+func NewRemuxer(ctx context.Context, r io.Reader, w io.Writer, opts ...func(*Remuxer)) (rmx *Remuxer) {
+	// Init
+	rmx = &Remuxer{
+		dropPIDs: make(map[uint16]bool),
+		pidMap:   make(map[uint16]uint16),
+		w:        w,
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(rmx)
+	}
+
+	// Create demuxer and remapper
+	rmx.dmx = New(ctx, r, rmx.optDemuxerOpts...)
+	rmx.remapper = newPIDRemapper(rmx.pidMap, rmx.dropPIDs)
+	if rmx.optProgramNumber != nil {
+		rmx.remapper.keepProgram(*rmx.optProgramNumber)
+	}
+	return
+}
+
+// ExtractSPTS writes a single program transport stream containing the program of a multi program transport stream
+// It is a shortcut for a remuxer created with RemuxerOptProgram
+func ExtractSPTS(ctx context.Context, r io.Reader, w io.Writer, programNumber uint16, opts ...func(*Remuxer)) (n int64, err error) {
+	if n, err = NewRemuxer(ctx, r, w, append([]func(*Remuxer){RemuxerOptProgram(programNumber)}, opts...)...).Remux(); err != nil {
+		err = errors.Wrapf(err, "astits: remuxing program %d failed", programNumber)
+		return
+	}
+	return
+}
+
+// RemuxerOptDemuxerOpts returns the option to pass options to the demuxer reading packets
+func RemuxerOptDemuxerOpts(opts ...func(*Demuxer)) func(*Remuxer) {
+	return func(r *Remuxer) {
+		r.optDemuxerOpts = append(r.optDemuxerOpts, opts...)
+	}
+}
+
+// RemuxerOptDropPIDs returns the option to drop the packets of the provided PIDs
+// Programs whose PMT PID is dropped are removed from the PAT, and elementary streams whose PID is dropped are removed
+// from their PMT
+func RemuxerOptDropPIDs(pids ...uint16) func(*Remuxer) {
+	return func(r *Remuxer) {
+		for _, pid := range pids {
+			r.dropPIDs[pid] = true
+		}
+	}
+}
+
+// RemuxerOptProgram returns the option to only keep a program, which produces a single program transport stream
+// The PAT is rewritten to only announce the program, whose PMT is copied, and only the packets of the PAT, the PMT,
+// the PCR PID and the elementary PIDs of the program are written. Packets of the program received before its PMT are
+// dropped.
+func RemuxerOptProgram(programNumber uint16) func(*Remuxer) {
+	return func(r *Remuxer) {
+		r.optProgramNumber = &programNumber
+	}
+}
+
+// RemuxerOptRemapPID returns the option to write the packets of a PID on another PID
+// References to the PID in the PAT and the PMTs are rewritten accordingly
+func RemuxerOptRemapPID(from, to uint16) func(*Remuxer) {
+	return func(r *Remuxer) {
+		r.pidMap[from] = to
+	}
+}
+
+// RemuxerOptTablesInterval returns the option to repeat the last rewritten PAT and PMTs every time packets packets
+// have been written, which re-stamps them independently from the repetition rate of the input
+func RemuxerOptTablesInterval(packets int) func(*Remuxer) {
+	return func(r *Remuxer) {
+		r.optTablesInterval = packets
+	}
+}
+
+// Demuxer returns the demuxer reading packets, which can be used to retrieve its stats or the programs of the input
+// Its NextPacket and NextData methods must not be called since they would steal packets from the remuxer
+func (r *Remuxer) Demuxer() *Demuxer {
+	return r.dmx
+}
+
+// Remux remuxes packets until there are no more packets to read
+func (r *Remuxer) Remux() (n int64, err error) {
+	for {
+		var nn int
+		if nn, err = r.RemuxPacket(); err != nil {
+			if err == ErrNoMorePackets {
+				err = nil
+			}
+			return
+		}
+		n += int64(nn)
+	}
+}
+
+// RemuxPacket reads the next packet and writes it, or the tables it completes, if its PID is not dropped
+// ErrNoMorePackets is returned once there are no more packets to read
+func (r *Remuxer) RemuxPacket() (n int, err error) {
+	// Fetch next packet
+	var p *Packet
+	if p, err = r.dmx.NextPacket(); err != nil {
+		if err != ErrNoMorePackets {
+			err = errors.Wrap(err, "astits: fetching next packet failed")
+		}
+		return
+	}
+
+	// Remap packet
+	var b []byte
+	if b, err = r.remapper.Remap(p); err != nil {
+		err = errors.Wrap(err, "astits: remapping packet failed")
+		return
+	}
+
+	// Repeat tables
+	if r.optTablesInterval > 0 && len(b) > 0 {
+		if r.packets += len(b) / MpegTsPacketSize; r.packets >= r.optTablesInterval {
+			b = append(b, r.remapper.Tables()...)
+			r.packets = 0
+		}
+	}
+
+	// Write packets
+	if len(b) > 0 {
+		if n, err = r.w.Write(b); err != nil {
+			err = errors.Wrapf(err, "astits: writing packets of PID %d failed", p.Header.PID)
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,126 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemuxer(t *testing.T) {
+	// Init
+	i := &bytes.Buffer{}
+	mx := NewMuxer(context.Background(), i)
+	mx.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x100, StreamType: StreamTypeLowerBitrateVideo})
+	mx.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x101, StreamType: StreamTypeMPEG1Audio})
+	for idx := 0; idx < 3; idx++ {
+		mx.WriteTables()
+		for _, pid := range []uint16{0x100, 0x101} {
+			mx.WriteData(&MuxerData{PES: &PESData{Data: []byte("data"), Header: &PESHeader{OptionalHeader: &PESOptionalHeader{}, StreamID: 0xe0}}, PID: pid})
+		}
+	}
+
+	// Remux
+	o := &bytes.Buffer{}
+	n, err := NewRemuxer(context.Background(), bytes.NewReader(i.Bytes()), o, RemuxerOptDropPIDs(0x101), RemuxerOptRemapPID(0x100, 0x200), RemuxerOptRemapPID(0x1000, 0x1100)).Remux()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(o.Len()), n)
+
+	// Demux
+	dmx := New(context.Background(), bytes.NewReader(o.Bytes()))
+	var pats, pmts, pes int
+	for {
+		d, err := dmx.NextData()
+		if err != nil {
+			assert.Equal(t, ErrNoMorePackets, err)
+			break
+		}
+		switch {
+		case d.PAT != nil:
+			pats++
+			assert.Equal(t, []*PATProgram{{ProgramMapID: 0x1100, ProgramNumber: 1}}, d.PAT.Programs)
+		case d.PMT != nil:
+			pmts++
+			assert.Equal(t, uint16(0x1100), d.PID)
+			assert.Equal(t, uint16(0x200), d.PMT.PCRPID)
+			assert.Len(t, d.PMT.ElementaryStreams, 1)
+			assert.Equal(t, uint16(0x200), d.PMT.ElementaryStreams[0].ElementaryPID)
+		case d.PES != nil:
+			pes++
+			assert.Equal(t, uint16(0x200), d.PID)
+			assert.Equal(t, []byte("data"), d.PES.Data)
+		}
+	}
+	assert.Equal(t, 2, pats)
+	assert.Equal(t, 2, pmts)
+	assert.Equal(t, 3, pes)
+	assert.Equal(t, int64(0), dmx.ContinuityErrors())
+	assert.NotContains(t, dmx.PIDs(), uint16(0x101))
+}
+
+func TestExtractSPTS(t *testing.T) {
+	// Init
+	buf := &bytes.Buffer{}
+	i := &bytes.Buffer{}
+	mx1 := NewMuxer(context.Background(), buf)
+	mx1.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x100, StreamType: StreamTypeMPEG1Audio})
+	mx2 := NewMuxer(context.Background(), buf, MuxerOptPMTPID(0x1100), MuxerOptProgramNumber(2))
+	mx2.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x200, StreamType: StreamTypeMPEG1Audio})
+	ss, err := (&PATData{Programs: []*PATProgram{
+		{ProgramMapID: 0x1000, ProgramNumber: 1},
+		{ProgramMapID: 0x1100, ProgramNumber: 2},
+	}}).Serialize(0)
+	assert.NoError(t, err)
+	pat := writePSIPayload(ss)
+	var cc uint8
+	for idx := 0; idx < 3; idx++ {
+		// PAT announces both programs
+		for _, p := range packetizePayload(PIDPAT, pat, nil, true, &cc) {
+			i.Write(writePacket(p))
+		}
+		for _, mx := range []*Muxer{mx1, mx2} {
+			buf.Reset()
+			mx.WriteTables()
+			i.Write(buf.Bytes()[MpegTsPacketSize:])
+		}
+
+		// Data
+		for _, pid := range []uint16{0x100, 0x200} {
+			buf.Reset()
+			mx := mx1
+			if pid == 0x200 {
+				mx = mx2
+			}
+			mx.WriteData(&MuxerData{PES: &PESData{Data: []byte("data"), Header: &PESHeader{OptionalHeader: &PESOptionalHeader{}, StreamID: 0xc0}}, PID: pid})
+			i.Write(buf.Bytes())
+		}
+	}
+
+	// Extract
+	o := &bytes.Buffer{}
+	_, err = ExtractSPTS(context.Background(), bytes.NewReader(i.Bytes()), o, 2, RemuxerOptTablesInterval(1))
+	assert.NoError(t, err)
+
+	// Demux
+	dmx := New(context.Background(), bytes.NewReader(o.Bytes()))
+	var pats int
+	for {
+		d, err := dmx.NextData()
+		if err != nil {
+			assert.Equal(t, ErrNoMorePackets, err)
+			break
+		}
+		if d.PAT != nil {
+			pats++
+			assert.Equal(t, []*PATProgram{{ProgramMapID: 0x1100, ProgramNumber: 2}}, d.PAT.Programs)
+		}
+	}
+	var pids []uint16
+	for pid := range dmx.PIDs() {
+		pids = append(pids, pid)
+	}
+	assert.ElementsMatch(t, []uint16{PIDPAT, 0x200, 0x1100}, pids)
+	assert.True(t, pats > 2)
+	assert.Equal(t, int64(0), dmx.ContinuityErrors())
+}
@@ -0,0 +1,114 @@
+package astits
+
+// PCRRestamper represents an object capable of rewriting the PCRs of packets so that they stay consistent with the
+// position of the packets in the output, which is needed once streams have been remuxed or spliced
+// The restamped PCR of a packet is the initial PCR shifted by the time needed to transmit the packets seen before it at
+// the output bitrate. Unless set with PCRRestamperOptInitialPCR, the initial PCR is the first PCR seen which is
+// therefore kept as is
+type PCRRestamper struct {
+	bitrate            int // In bits per second
+	hasInitial         bool
+	hasOffset          bool
+	initial            int // In 27 MHz ticks
+	initialPosition    int64
+	offset             int // Difference between the last restamped PCR and its original value, in 27 MHz ticks
+	optShiftTimestamps bool
+	position           int64 // Number of bytes seen
+}
+
+// NewPCRRestamper creates a new PCR restamper for an output bitrate in bits per second
+func NewPCRRestamper(bitrate int, opts ...func(*PCRRestamper)) (r *PCRRestamper) {
+	r = &PCRRestamper{bitrate: bitrate}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return
+}
+
+// PCRRestamperOptInitialPCR returns the option to set the PCR of the first packet seen
+func PCRRestamperOptInitialPCR(c ClockReference) func(*PCRRestamper) {
+	return func(r *PCRRestamper) {
+		r.hasInitial = true
+		r.initial = c.Ticks27MHz()
+	}
+}
+
+// PCRRestamperOptShiftTimestamps returns the option to shift the PTS and DTS of the packets starting a PES by the
+// difference between the last restamped PCR and its original value, so that they stay consistent with the PCRs
+// Since the same difference is used for all PIDs, this is meant for single program streams
+func PCRRestamperOptShiftTimestamps() func(*PCRRestamper) {
+	return func(r *PCRRestamper) {
+		r.optShiftTimestamps = true
+	}
+}
+
+// Restamp restamps a packet in place, its raw bytes included so that they can be forwarded as is
+// It must be called with every packet of the output, in order, whether it carries a PCR or not
+func (r *PCRRestamper) Restamp(p *Packet) {
+	// Restamp PCR
+	if p.AdaptationField != nil && p.AdaptationField.HasPCR && p.AdaptationField.PCR != nil {
+		// Compute PCR
+		var o = p.AdaptationField.PCR.Ticks27MHz()
+		if !r.hasInitial {
+			r.hasInitial = true
+			r.initial = o
+			r.initialPosition = r.position
+		}
+		var c = NewClockReference27MHz(r.initial + r.ticks(r.position-r.initialPosition))
+		r.offset = c.Ticks27MHz() - o
+		r.hasOffset = true
+
+		// The adaptation field may be shared with other packets
+		var a = *p.AdaptationField
+		a.PCR = c
+		p.AdaptationField = &a
+
+		// Rewrite raw bytes, whose PCR follows the adaptation field length and flags
+		if so := packetSyncOffset(len(p.Bytes)); len(p.Bytes) >= so+12 && p.Bytes[so+3]&0x20 > 0 && p.Bytes[so+4] > 0 && p.Bytes[so+5]&0x10 > 0 {
+			copy(p.Bytes[so+6:], writePCR(c))
+		}
+	}
+
+	// Shift timestamps
+	if r.optShiftTimestamps && r.hasOffset && p.Header.PayloadUnitStartIndicator {
+		shiftPESPayloadTimestamps(p.Payload, func(ClockReference) int { return r.offset / 300 })
+	}
+	r.position += MpegTsPacketSize
+}
+
+// ticks converts a number of bytes into the number of 27 MHz ticks needed to transmit them at the output bitrate
+func (r *PCRRestamper) ticks(n int64) int {
+	if r.bitrate <= 0 {
+		return 0
+	}
+	var bits, bitrate = n * 8, int64(r.bitrate)
+	return int(bits/bitrate*27000000 + bits%bitrate*27000000/bitrate)
+}
+
+// shiftPESPayloadTimestamps shifts in place the PTS and DTS of a payload starting with a PES header by the number of
+// 90 kHz ticks returned by offset for each of them
+func shiftPESPayloadTimestamps(i []byte, offset func(c ClockReference) int) {
+	// Check PES header
+	if len(i) < 9 || !isPESPayload(i) || !hasPESOptionalHeader(i[3]) {
+		return
+	}
+
+	// Get timestamps
+	var offsets []int
+	switch i[7] >> 6 {
+	case PTSDTSIndicatorOnlyPTS:
+		offsets = []int{9}
+	case PTSDTSIndicatorBothPresent:
+		offsets = []int{9, 14}
+	}
+
+	// Shift timestamps
+	for _, o := range offsets {
+		if o+5 > len(i) {
+			return
+		}
+		var c = parsePTSOrDTS(i[o:])
+		c.Base = wrapClockReferenceBase(c.Base + offset(*c))
+		copy(i[o:], writePTSOrDTS(i[o]>>4, c))
+	}
+}
@@ -0,0 +1,77 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPCRRestamper(t *testing.T) {
+	// Each packet lasts 100ms
+	r := NewPCRRestamper(MpegTsPacketSize*8*10, PCRRestamperOptShiftTimestamps())
+	a := &PacketAdaptationField{HasPCR: true, PCR: &ClockReference{Base: 5}}
+	pes := &PESData{Data: []byte("data"), Header: &PESHeader{OptionalHeader: &PESOptionalHeader{DTS: &ClockReference{Base: 50}, PTS: &ClockReference{Base: 100}}, StreamID: 0xe0}}
+	ps := []*Packet{
+		{Header: &PacketHeader{PayloadUnitStartIndicator: true}, Payload: pes.Serialize()},
+		{AdaptationField: &PacketAdaptationField{HasPCR: true, PCR: &ClockReference{Base: 1000, Extension: 1}}, Header: &PacketHeader{}},
+		{Header: &PacketHeader{}},
+		{AdaptationField: a, Header: &PacketHeader{}},
+		{Header: &PacketHeader{PayloadUnitStartIndicator: true}, Payload: pes.Serialize()},
+	}
+	for _, p := range ps {
+		r.Restamp(p)
+	}
+
+	// PCRs
+	assert.Equal(t, &ClockReference{Base: 1000, Extension: 1}, ps[1].AdaptationField.PCR)
+	assert.Equal(t, &ClockReference{Base: 19000, Extension: 1}, ps[3].AdaptationField.PCR)
+	assert.Equal(t, &ClockReference{Base: 5}, a.PCR)
+
+	// Timestamps are only shifted once a PCR has been restamped
+	o, err := parsePESData(ps[0].Payload)
+	assert.NoError(t, err)
+	assert.Equal(t, 100, o.Header.OptionalHeader.PTS.Base)
+	o, err = parsePESData(ps[4].Payload)
+	assert.NoError(t, err)
+	assert.Equal(t, 50+18995, o.Header.OptionalHeader.DTS.Base)
+	assert.Equal(t, 100+18995, o.Header.OptionalHeader.PTS.Base)
+
+	// Timestamps wrap
+	b := ps[4].Payload
+	shiftPESPayloadTimestamps(b, func(ClockReference) int { return -19100 })
+	o, err = parsePESData(b)
+	assert.NoError(t, err)
+	assert.Equal(t, clockReferenceBaseWrap-5, o.Header.OptionalHeader.PTS.Base)
+
+	// Raw bytes
+	p, err := parsePacket(writePacket(&Packet{
+		AdaptationField: &PacketAdaptationField{HasPCR: true, PCR: &ClockReference{Base: 1000}},
+		Header:          &PacketHeader{HasAdaptationField: true, HasPayload: true},
+		Payload:         []byte("payload"),
+	}))
+	assert.NoError(t, err)
+	NewPCRRestamper(MpegTsPacketSize*8*10, PCRRestamperOptInitialPCR(ClockReference{Base: 2000})).Restamp(p)
+	p, err = parsePacket(p.Bytes)
+	assert.NoError(t, err)
+	assert.Equal(t, &ClockReference{Base: 2000}, p.AdaptationField.PCR)
+}
+
+func TestMuxerOptPCRRestamper(t *testing.T) {
+	// Init
+	buf := &bytes.Buffer{}
+	m := NewMuxer(context.Background(), buf, MuxerOptPCRRestamper(NewPCRRestamper(MpegTsPacketSize*8*10, PCRRestamperOptInitialPCR(ClockReference{Base: 100}))))
+	m.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x100, StreamType: StreamTypeLowerBitrateVideo})
+	m.WriteData(&MuxerData{
+		AdaptationField: &PacketAdaptationField{HasPCR: true, PCR: &ClockReference{Base: 5}},
+		PES:             &PESData{Data: []byte("data"), Header: &PESHeader{OptionalHeader: &PESOptionalHeader{}, StreamID: 0xe0}},
+		PID:             0x100,
+	})
+
+	// PAT and PMT are written before
+	assert.Equal(t, 3*MpegTsPacketSize, buf.Len())
+	p, err := parsePacket(buf.Bytes()[2*MpegTsPacketSize:])
+	assert.NoError(t, err)
+	assert.Equal(t, &ClockReference{Base: 100 + 2*9000}, p.AdaptationField.PCR)
+}
@@ -0,0 +1,78 @@
+package astits
+
+import "github.com/pkg/errors"
+
+// SectionHandler represents an object capable of handling a raw PSI section reassembled by the demuxer
+// The section starts with its table ID and includes its CRC32, if any, which is not checked. It can be retained.
+type SectionHandler func(pid uint16, section []byte) error
+
+// sectionHandlers represents the section handlers indexed by PID and table ID
+type sectionHandlers map[uint16]map[int]SectionHandler
+
+// RegisterSectionHandler registers a handler called with every section of the PID whose table ID matches
+// Sections are handed to the handler before the default parsing, even if the PID or the table ID is unknown to the
+// demuxer in which case no data is returned for them. A handler replaces the one previously registered for the same
+// PID and table ID, and a nil handler unregisters it
+// An error returned by the handler is returned by NextData
+func (dmx *Demuxer) RegisterSectionHandler(pid uint16, tableID int, fn SectionHandler) {
+	// Unregister
+	if fn == nil {
+		if hs, ok := dmx.sectionHandlers[pid]; ok {
+			delete(hs, tableID)
+			if len(hs) == 0 {
+				delete(dmx.sectionHandlers, pid)
+			}
+		}
+		return
+	}
+
+	// Register
+	if _, ok := dmx.sectionHandlers[pid]; !ok {
+		dmx.sectionHandlers[pid] = make(map[int]SectionHandler)
+	}
+	dmx.sectionHandlers[pid][tableID] = fn
+}
+
+// handle hands the sections of a PSI payload to the handlers registered for the PID and returns whether there are any
+func (shs sectionHandlers) handle(i []byte, pid uint16) (ok bool, err error) {
+	// Get handlers
+	var hs map[int]SectionHandler
+	if hs, ok = shs[pid]; !ok {
+		return
+	}
+
+	// Loop through sections
+	for _, s := range splitPSISections(i) {
+		if h, found := hs[int(s[0])]; found {
+			if err = h(pid, s); err != nil {
+				err = errors.Wrapf(err, "astits: handling section with table ID 0x%x failed", s[0])
+				return
+			}
+		}
+	}
+	return
+}
+
+// splitPSISections splits a PSI payload into raw sections
+// Splitting stops at the first stuffing byte or at the first truncated section
+func splitPSISections(i []byte) (ss [][]byte) {
+	// Pointer field
+	if len(i) == 0 {
+		return
+	}
+	var offset = 1 + int(i[0])
+
+	// Loop through sections
+	for offset+3 <= len(i) && i[offset] != 0xff {
+		// Section length
+		var offsetEnd = offset + 3 + (int(i[offset+1]&0xf)<<8 | int(i[offset+2]))
+		if offsetEnd > len(i) {
+			return
+		}
+
+		// Append section
+		ss = append(ss, i[offset:offsetEnd])
+		offset = offsetEnd
+	}
+	return
+}
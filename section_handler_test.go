@@ -0,0 +1,56 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/asticode/go-astitools/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitPSISections(t *testing.T) {
+	assert.Nil(t, splitPSISections([]byte{}))
+	assert.Equal(t, [][]byte{{0x80, 0xf0, 0x1, 0x1}, {0x81, 0xf0, 0x0}}, splitPSISections([]byte{0x1, 0x0, 0x80, 0xf0, 0x1, 0x1, 0x81, 0xf0, 0x0, 0xff, 0x82, 0xf0, 0x0}))
+	assert.Equal(t, [][]byte{{0x80, 0xf0, 0x0}}, splitPSISections([]byte{0x0, 0x80, 0xf0, 0x0, 0x81, 0xf0, 0x2, 0x1}))
+}
+
+func TestDemuxerRegisterSectionHandler(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	var s1 = []byte{0x80, 0x70, 0x2, 0x1, 0x2}
+	var s2 = []byte{0x81, 0x70, 0x1, 0x3}
+	var p = append(append([]byte{0x0}, s1...), s2...)
+	p = append(p, bytes.Repeat([]byte{0xff}, 147-len(p))...)
+	b, _ := packet(PacketHeader{PayloadUnitStartIndicator: true, PID: 0x100}, PacketAdaptationField{}, p)
+	w.Write(b)
+	b, _ = packet(PacketHeader{ContinuityCounter: uint8(1), PayloadUnitStartIndicator: true, PID: 0x100}, PacketAdaptationField{}, []byte{})
+	w.Write(b)
+	dmx := New(context.Background(), bytes.NewReader(w.Bytes()))
+
+	// Register
+	var ss [][]byte
+	dmx.RegisterSectionHandler(0x100, 0x80, func(pid uint16, s []byte) error {
+		assert.Equal(t, uint16(0x100), pid)
+		ss = append(ss, s)
+		return nil
+	})
+	dmx.RegisterSectionHandler(0x100, 0x81, func(pid uint16, s []byte) error { return errors.New("test") })
+	dmx.RegisterSectionHandler(0x100, 0x81, nil)
+	dmx.RegisterSectionHandler(0x101, 0x81, nil)
+	assert.Len(t, dmx.sectionHandlers[0x100], 1)
+
+	// Sections are handled but no data is returned
+	_, err := dmx.NextData()
+	assert.Equal(t, ErrNoMorePackets, err)
+	assert.Equal(t, [][]byte{s1}, ss)
+
+	// Errors
+	dmx = New(context.Background(), bytes.NewReader(w.Bytes()))
+	dmx.RegisterSectionHandler(0x100, 0x81, func(pid uint16, s []byte) error { return errors.New("test") })
+	_, err = dmx.NextData()
+	assert.Error(t, err)
+	dmx.RegisterSectionHandler(0x100, 0x81, nil)
+	assert.Len(t, dmx.sectionHandlers, 0)
+}
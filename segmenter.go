@@ -0,0 +1,400 @@
+package astits
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Segmenter constants
+const (
+	segmenterDefaultSegmentDuration = 6 * time.Second
+	segmenterPlaylistName           = "index.m3u8"
+	segmenterSegmentNameFormat      = "segment%d.ts"
+)
+
+// Segmenter represents an HLS segmenter
+// It reads packets with a demuxer and splits them into segment files of at least the segment duration, starting at
+// keyframes of the reference stream, and maintains an m3u8 playlist listing them. The reference stream is the first
+// video elementary stream of the first PMT, or its first elementary stream when the program has no video. Keyframes are
+// packets starting a PES with the random access indicator set or, for H.264 and HEVC, whose first VCL NAL unit is an IDR
+// or IRAP slice. Since parameter sets and SEIs often push that NAL unit to the next packets, packets are held back until
+// it is received. Every segment starts with the PAT and the PMTs so that it can be decoded on its own, and packets
+// received before the first keyframe are dropped.
+type Segmenter struct {
+	dir                string
+	dmx                *Demuxer
+	file               *os.File
+	fileWriter         *bufio.Writer
+	firstPTS           int // PTS of the first keyframe of the current segment, in 90 kHz ticks
+	lastPTS            int // Highest PTS of the reference stream since the first keyframe of the current segment, in 90 kHz ticks
+	next               int // Index of the next segment
+	optDemuxerOpts     []func(*Demuxer)
+	optPlaylistSize    int
+	optSegmentDuration time.Duration
+	pending            *segmenterPending // Set while waiting for the first VCL NAL unit of a PES of the reference stream
+	refPID             uint16
+	refStreamType      uint8
+	remapper           *PIDRemapper
+	segments           []*SegmenterSegment // Segments listed in the playlist
+	sequence           int                 // Media sequence number of the first segment listed in the playlist
+	targetDuration     int                 // In seconds
+	unwrapper          *ClockUnwrapper
+}
+
+// segmenterPending represents the packets held back until the keyframe detection of a PES of the reference stream is
+// over
+type segmenterPending struct {
+	b    []byte // Packets to write once the detection is over
+	data []byte // PES payload received so far
+	pts  int
+}
+
+// SegmenterSegment represents a segment listed in the playlist
+type SegmenterSegment struct {
+	Duration time.Duration
+	Name     string
+}
+
+// NewSegmenter creates a new segmenter reading packets from a reader and writing segments and their playlist in a
+// directory
+func NewSegmenter(ctx context.Context, r io.Reader, dir string, opts ...func(*Segmenter)) (s *Segmenter) {
+	// Init
+	s = &Segmenter{
+		dir:                dir,
+		optSegmentDuration: segmenterDefaultSegmentDuration,
+		refPID:             PIDNull,
+		remapper:           NewPIDRemapper(make(map[uint16]uint16)),
+		unwrapper:          NewClockUnwrapper(),
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	// Create demuxer
+	s.dmx = New(ctx, r, s.optDemuxerOpts...)
+	return
+}
+
+// SegmenterOptDemuxerOpts returns the option to pass options to the demuxer reading packets
+func SegmenterOptDemuxerOpts(opts ...func(*Demuxer)) func(*Segmenter) {
+	return func(s *Segmenter) {
+		s.optDemuxerOpts = append(s.optDemuxerOpts, opts...)
+	}
+}
+
+// SegmenterOptPlaylistSize returns the option to only list the last segments in the playlist, as done for live
+// streams, in which case segments removed from the playlist are deleted
+func SegmenterOptPlaylistSize(segments int) func(*Segmenter) {
+	return func(s *Segmenter) {
+		s.optPlaylistSize = segments
+	}
+}
+
+// SegmenterOptSegmentDuration returns the option to set the minimum duration of segments, which is 6s by default
+func SegmenterOptSegmentDuration(d time.Duration) func(*Segmenter) {
+	return func(s *Segmenter) {
+		s.optSegmentDuration = d
+	}
+}
+
+// Segment segments packets until there are no more packets to read, and then ends the playlist
+func (s *Segmenter) Segment() (err error) {
+	// Loop through packets
+	for {
+		if err = s.segmentPacket(); err != nil {
+			if err != ErrNoMorePackets {
+				return
+			}
+			break
+		}
+	}
+
+	// Write packets held back
+	if err = s.stopPending(false); err != nil {
+		err = errors.Wrap(err, "astits: writing pending packets failed")
+		return
+	}
+
+	// Close last segment
+	if err = s.closeSegment(true); err != nil {
+		err = errors.Wrap(err, "astits: closing last segment failed")
+		return
+	}
+	return
+}
+
+// Segments returns the segments listed in the playlist
+func (s *Segmenter) Segments() []*SegmenterSegment {
+	return s.segments
+}
+
+// segmentPacket reads the next packet and writes it to the current segment, starting a new segment when needed
+func (s *Segmenter) segmentPacket() (err error) {
+	// Fetch next packet
+	var p *Packet
+	if p, err = s.dmx.NextPacket(); err != nil {
+		if err != ErrNoMorePackets {
+			err = errors.Wrap(err, "astits: fetching next packet failed")
+		}
+		return
+	}
+
+	// Copy packet, or rewrite tables
+	var b []byte
+	if b, err = s.remapper.Remap(p); err != nil {
+		err = errors.Wrap(err, "astits: remapping packet failed")
+		return
+	}
+
+	// Update reference stream
+	if s.refPID == PIDNull {
+		if s.refPID, s.refStreamType = s.remapper.referenceStream(); s.refPID == PIDNull {
+			return
+		}
+	}
+
+	// Packet of the reference stream
+	var keyframe, known bool
+	if p.Header.PID == s.refPID && p.Header.PayloadUnitStartIndicator {
+		// The previous PES didn't start a keyframe if no VCL NAL unit has been found before the next one
+		if err = s.stopPending(false); err != nil {
+			err = errors.Wrap(err, "astits: writing pending packets failed")
+			return
+		}
+
+		// Parse PTS
+		if d, errPES := parsePESData(p.Payload); errPES == nil && d.Header.OptionalHeader != nil && d.Header.OptionalHeader.PTS != nil {
+			// PTS may be out of order when pictures are reordered
+			var pts = s.unwrapper.Unwrap(d.Header.OptionalHeader.PTS).Base
+			if pts > s.lastPTS {
+				s.lastPTS = pts
+			}
+
+			// Start a new segment at keyframes once the segment is long enough, or hold packets back until the
+			// keyframe is detected
+			if keyframe, known = s.isKeyframe(p, d); !known {
+				s.pending = &segmenterPending{data: append([]byte{}, d.Data...), pts: pts}
+			} else if keyframe {
+				if err = s.startSegment(pts); err != nil {
+					err = errors.Wrap(err, "astits: starting segment failed")
+					return
+				}
+			}
+		}
+	} else if p.Header.PID == s.refPID && s.pending != nil {
+		s.pending.data = append(s.pending.data, p.Payload...)
+		keyframe, known = accessUnitKeyframe(s.refStreamType, s.pending.data)
+	}
+
+	// Hold packets back
+	if s.pending != nil {
+		s.pending.b = append(s.pending.b, b...)
+		if known {
+			if err = s.stopPending(keyframe); err != nil {
+				err = errors.Wrap(err, "astits: writing pending packets failed")
+				return
+			}
+		}
+		return
+	}
+
+	// Write packets
+	if err = s.write(b); err != nil {
+		err = errors.Wrapf(err, "astits: writing packets of PID %d failed", p.Header.PID)
+		return
+	}
+	return
+}
+
+// isKeyframe checks whether a packet starting a PES of the reference stream starts a keyframe, and whether it can tell
+// from the packet alone
+func (s *Segmenter) isKeyframe(p *Packet, d *PESData) (keyframe, known bool) {
+	if p.Header.HasAdaptationField && p.AdaptationField != nil && p.AdaptationField.RandomAccessIndicator {
+		return true, true
+	}
+	switch s.refStreamType {
+	case StreamTypeHEVCVideo, StreamTypeLowerBitrateVideo:
+		return accessUnitKeyframe(s.refStreamType, d.Data)
+	}
+	return !isVideoStreamType(s.refStreamType), true
+}
+
+// accessUnitKeyframe checks whether the first VCL NAL unit of the beginning of an H.264 or HEVC access unit is an IDR
+// or IRAP slice, and whether a VCL NAL unit has been found at all
+func accessUnitKeyframe(streamType uint8, i []byte) (keyframe, found bool) {
+	switch streamType {
+	case StreamTypeHEVCVideo:
+		for _, n := range ParseHEVCAccessUnit(i).NALUnits {
+			// VCL NAL unit types are below 32
+			if n.Type < HEVCNALUnitTypeVPS {
+				return isHEVCNALUnitTypeIRAP(n.Type), true
+			}
+		}
+	case StreamTypeLowerBitrateVideo:
+		for _, n := range ParseH264AccessUnit(i).NALUnits {
+			if n.Type >= H264NALUnitTypeNonIDRSlice && n.Type <= H264NALUnitTypeIDRSlice {
+				return n.Type == H264NALUnitTypeIDRSlice, true
+			}
+		}
+	}
+	return
+}
+
+// stopPending writes the packets held back, after starting a new segment if they start a keyframe
+func (s *Segmenter) stopPending(keyframe bool) (err error) {
+	// Nothing is held back
+	var p = s.pending
+	if p == nil {
+		return
+	}
+	s.pending = nil
+
+	// Start a new segment
+	if keyframe {
+		if err = s.startSegment(p.pts); err != nil {
+			err = errors.Wrap(err, "astits: starting segment failed")
+			return
+		}
+	}
+
+	// Write packets
+	if err = s.write(p.b); err != nil {
+		err = errors.Wrap(err, "astits: writing packets failed")
+		return
+	}
+	return
+}
+
+// startSegment opens a new segment at a keyframe once the current segment is long enough
+func (s *Segmenter) startSegment(pts int) (err error) {
+	if s.file != nil && time.Duration(pts-s.firstPTS)*time.Second/90000 < s.optSegmentDuration {
+		return
+	}
+	if err = s.openSegment(pts); err != nil {
+		err = errors.Wrap(err, "astits: opening segment failed")
+		return
+	}
+	return
+}
+
+// write writes packets to the current segment, if any
+func (s *Segmenter) write(b []byte) (err error) {
+	if s.file == nil || len(b) == 0 {
+		return
+	}
+	if _, err = s.fileWriter.Write(b); err != nil {
+		err = errors.Wrap(err, "astits: writing failed")
+		return
+	}
+	return
+}
+
+// openSegment closes the current segment, if any, and opens a new one starting with the PAT and the PMTs
+func (s *Segmenter) openSegment(pts int) (err error) {
+	// Close current segment
+	if err = s.closeSegment(false); err != nil {
+		err = errors.Wrap(err, "astits: closing segment failed")
+		return
+	}
+
+	// Create file
+	var name = fmt.Sprintf(segmenterSegmentNameFormat, s.next)
+	if s.file, err = os.Create(filepath.Join(s.dir, name)); err != nil {
+		err = errors.Wrapf(err, "astits: creating %s failed", name)
+		return
+	}
+	s.fileWriter = bufio.NewWriter(s.file)
+	s.firstPTS = pts
+	s.lastPTS = pts
+	s.next++
+
+	// Write tables
+	if _, err = s.fileWriter.Write(s.remapper.Tables()); err != nil {
+		err = errors.Wrapf(err, "astits: writing tables to %s failed", name)
+		return
+	}
+	return
+}
+
+// closeSegment closes the current segment, if any, adds it to the playlist and writes the playlist
+func (s *Segmenter) closeSegment(end bool) (err error) {
+	// No segment opened
+	if s.file == nil {
+		return
+	}
+
+	// Close file
+	var name = filepath.Base(s.file.Name())
+	if err = s.fileWriter.Flush(); err != nil {
+		err = errors.Wrapf(err, "astits: flushing %s failed", name)
+		return
+	}
+	if err = s.file.Close(); err != nil {
+		err = errors.Wrapf(err, "astits: closing %s failed", name)
+		return
+	}
+	s.file = nil
+	s.fileWriter = nil
+
+	// Add segment
+	var g = &SegmenterSegment{
+		Duration: time.Duration(s.lastPTS-s.firstPTS) * time.Second / 90000,
+		Name:     name,
+	}
+	s.segments = append(s.segments, g)
+	if d := int(math.Ceil(g.Duration.Seconds())); d > s.targetDuration {
+		s.targetDuration = d
+	}
+
+	// Remove old segments
+	for s.optPlaylistSize > 0 && len(s.segments) > s.optPlaylistSize {
+		if err = os.Remove(filepath.Join(s.dir, s.segments[0].Name)); err != nil {
+			err = errors.Wrapf(err, "astits: removing %s failed", s.segments[0].Name)
+			return
+		}
+		s.segments = s.segments[1:]
+		s.sequence++
+	}
+
+	// Write playlist
+	if err = s.writePlaylist(end); err != nil {
+		err = errors.Wrap(err, "astits: writing playlist failed")
+		return
+	}
+	return
+}
+
+// writePlaylist writes the playlist to a temporary file renamed once complete, so that it's never read partially
+func (s *Segmenter) writePlaylist(end bool) (err error) {
+	// Build playlist
+	var b = []byte(fmt.Sprintf("#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:%d\n#EXT-X-MEDIA-SEQUENCE:%d\n", s.targetDuration, s.sequence))
+	for _, g := range s.segments {
+		b = append(b, fmt.Sprintf("#EXTINF:%.3f,\n%s\n", g.Duration.Seconds(), g.Name)...)
+	}
+	if end {
+		b = append(b, "#EXT-X-ENDLIST\n"...)
+	}
+
+	// Write playlist
+	var p = filepath.Join(s.dir, segmenterPlaylistName)
+	if err = ioutil.WriteFile(p+".tmp", b, 0644); err != nil {
+		err = errors.Wrapf(err, "astits: writing %s.tmp failed", p)
+		return
+	}
+	if err = os.Rename(p+".tmp", p); err != nil {
+		err = errors.Wrapf(err, "astits: renaming %s.tmp failed", p)
+		return
+	}
+	return
+}
@@ -0,0 +1,123 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSegmenter(t *testing.T) {
+	// Init
+	i := &bytes.Buffer{}
+	mx := NewMuxer(context.Background(), i)
+	mx.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x100, StreamType: StreamTypeLowerBitrateVideo})
+	mx.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x101, StreamType: StreamTypeMPEG1Audio})
+	write := func(pid uint16, streamID uint8, pts time.Duration, keyframe bool) {
+		var a *PacketAdaptationField
+		if keyframe {
+			a = &PacketAdaptationField{RandomAccessIndicator: true}
+		}
+		mx.WriteData(&MuxerData{AdaptationField: a, PES: &PESData{Data: []byte("data"), Header: &PESHeader{OptionalHeader: &PESOptionalHeader{
+			MarkerBits:      2,
+			PTS:             NewClockReferenceFromDuration(pts),
+			PTSDTSIndicator: PTSDTSIndicatorOnlyPTS,
+		}, StreamID: streamID}}, PID: pid})
+	}
+
+	// Keyframes every 2s, the audio written before the first one is dropped
+	write(0x101, 0xc0, 0, false)
+	for idx := 0; idx < 10; idx++ {
+		write(0x100, 0xe0, time.Duration(idx)*time.Second, idx%2 == 0)
+		write(0x101, 0xc0, time.Duration(idx)*time.Second, false)
+	}
+
+	// Segment
+	segment := func(opts ...func(*Segmenter)) (dir string, s *Segmenter) {
+		dir, err := ioutil.TempDir("", "astits")
+		assert.NoError(t, err)
+		s = NewSegmenter(context.Background(), bytes.NewReader(i.Bytes()), dir, append([]func(*Segmenter){SegmenterOptSegmentDuration(3 * time.Second)}, opts...)...)
+		assert.NoError(t, s.Segment())
+		return
+	}
+	dir, s := segment()
+	defer os.RemoveAll(dir)
+	assert.Equal(t, []*SegmenterSegment{
+		{Duration: 4 * time.Second, Name: "segment0.ts"},
+		{Duration: 4 * time.Second, Name: "segment1.ts"},
+		{Duration: time.Second, Name: "segment2.ts"},
+	}, s.Segments())
+	b, err := ioutil.ReadFile(filepath.Join(dir, "index.m3u8"))
+	assert.NoError(t, err)
+	assert.Equal(t, "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:4\n#EXT-X-MEDIA-SEQUENCE:0\n"+
+		"#EXTINF:4.000,\nsegment0.ts\n#EXTINF:4.000,\nsegment1.ts\n#EXTINF:1.000,\nsegment2.ts\n#EXT-X-ENDLIST\n", string(b))
+
+	// Segments start with the tables
+	b, err = ioutil.ReadFile(filepath.Join(dir, "segment1.ts"))
+	assert.NoError(t, err)
+	var pids []uint16
+	for j := b; len(j) >= MpegTsPacketSize; j = j[MpegTsPacketSize:] {
+		pid, _ := rawPacketPID(j)
+		pids = append(pids, pid)
+	}
+	assert.Equal(t, []uint16{PIDPAT, 0x1000, 0x100, 0x101, 0x100, 0x101, 0x100, 0x101, 0x100, 0x101}, pids)
+	d, err := New(context.Background(), bytes.NewReader(b)).NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, 4*90000, d.PES.Header.OptionalHeader.PTS.Base)
+
+	// Live playlist
+	dir, s = segment(SegmenterOptPlaylistSize(2))
+	defer os.RemoveAll(dir)
+	assert.Len(t, s.Segments(), 2)
+	_, err = os.Stat(filepath.Join(dir, "segment0.ts"))
+	assert.True(t, os.IsNotExist(err))
+	b, err = ioutil.ReadFile(filepath.Join(dir, "index.m3u8"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "#EXT-X-MEDIA-SEQUENCE:1\n#EXTINF:4.000,\nsegment1.ts\n")
+}
+
+func TestSegmenterH264(t *testing.T) {
+	// Init
+	i := &bytes.Buffer{}
+	mx := NewMuxer(context.Background(), i)
+	mx.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x100, StreamType: StreamTypeLowerBitrateVideo})
+
+	// Keyframes every 2s, whose IDR slice comes after an SEI pushing it to the second packet
+	sei := append([]byte{0x0, 0x0, 0x1, 0x6}, bytes.Repeat([]byte{0xaa}, 200)...)
+	for idx := 0; idx < 10; idx++ {
+		var slice = byte(0x41)
+		if idx%2 == 0 {
+			slice = 0x65
+		}
+		mx.WriteData(&MuxerData{PES: &PESData{Data: append(append([]byte{0x0, 0x0, 0x0, 0x1, 0x9, 0xf0}, sei...), 0x0, 0x0, 0x1, slice, 0x88), Header: &PESHeader{OptionalHeader: &PESOptionalHeader{
+			MarkerBits:      2,
+			PTS:             NewClockReferenceFromDuration(time.Duration(idx) * time.Second),
+			PTSDTSIndicator: PTSDTSIndicatorOnlyPTS,
+		}, StreamID: 0xe0}}, PID: 0x100})
+	}
+
+	// Segment
+	dir, err := ioutil.TempDir("", "astits")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	s := NewSegmenter(context.Background(), bytes.NewReader(i.Bytes()), dir, SegmenterOptSegmentDuration(3*time.Second))
+	assert.NoError(t, s.Segment())
+	assert.Equal(t, []*SegmenterSegment{
+		{Duration: 4 * time.Second, Name: "segment0.ts"},
+		{Duration: 4 * time.Second, Name: "segment1.ts"},
+		{Duration: time.Second, Name: "segment2.ts"},
+	}, s.Segments())
+
+	// Segments start with the first packet of the keyframe
+	b, err := ioutil.ReadFile(filepath.Join(dir, "segment1.ts"))
+	assert.NoError(t, err)
+	d, err := New(context.Background(), bytes.NewReader(b)).NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, 4*90000, d.PES.Header.OptionalHeader.PTS.Base)
+	assert.True(t, ParseH264AccessUnit(d.PES.Data).HasIDR)
+}
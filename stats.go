@@ -0,0 +1,289 @@
+package astits
+
+import (
+	"math/big"
+	"sync"
+	"time"
+)
+
+// Stats represents statistics about the packets read by the demuxer
+// Bitrates are computed with the PCRs of the first PID carrying PCRs, which is the reference clock, and are 0 until 2
+// of its PCRs have been received. Average bitrates are computed since its first PCR whereas instantaneous bitrates are
+// computed between its last 2 PCRs
+// Null packets and adaptation field stuffing bytes are reported to measure the actual occupancy of the mux
+type Stats struct {
+	AverageBitrate       int // In bits per second
+	Bytes                int64
+	Duration             time.Duration // Time elapsed between the first and the last PCRs of the reference clock
+	InstantaneousBitrate int           // In bits per second
+	NullPackets          int64
+	NullPacketsRatio     float64 // Proportion of null packets among packets
+	Packets              int64
+	PCRPID               uint16 // PID of the reference clock
+	PIDs                 map[uint16]*PIDStats
+	Programs             map[uint16]*ProgramStats // Indexed by program number
+	ScrambledPackets     int64                    // Number of packets whose transport scrambling control is set
+	ScrambledRatio       float64                  // Proportion of scrambled packets among packets
+	StuffingBytes        int64                    // Number of adaptation field stuffing bytes
+	StuffingRatio        float64                  // Proportion of adaptation field stuffing bytes among bytes
+}
+
+// PIDStats represents statistics about the packets of a PID
+type PIDStats struct {
+	AverageBitrate       int // In bits per second
+	Bytes                int64
+	InstantaneousBitrate int // In bits per second
+	Packets              int64
+	ScrambledPackets     int64 // Number of packets whose transport scrambling control is set
+	ScramblingControl    uint8 // Transport scrambling control of the last packet, which tells which key is in use
+	StuffingBytes        int64 // Number of adaptation field stuffing bytes
+}
+
+// ProgramStats represents statistics about the packets of a program, its PMT included
+type ProgramStats struct {
+	AverageBitrate       int // In bits per second
+	Bytes                int64
+	InstantaneousBitrate int // In bits per second
+	Packets              int64
+	PIDs                 []uint16
+	ScrambledPackets     int64 // Number of packets whose transport scrambling control is set
+}
+
+// statsCollector accumulates statistics about packets
+type statsCollector struct {
+	first     int // First PCR of the reference clock, in 27 MHz ticks
+	hasPCR    bool
+	last      int // Last PCR of the reference clock, in 27 MHz ticks
+	m         *sync.Mutex
+	pcrPID    uint16
+	pids      map[uint16]*statsPID
+	previous  int                 // PCR of the reference clock received before the last one, in 27 MHz ticks
+	programs  map[uint16][]uint16 // PIDs indexed by program number
+	total     *statsPID
+	unwrapper *ClockUnwrapper
+}
+
+// statsPID represents the counters of a PID
+type statsPID struct {
+	bytes             int64
+	bytesFirst        int64 // Number of bytes when the first PCR of the reference clock was received
+	bytesLast         int64 // Number of bytes when the last PCR of the reference clock was received
+	bytesPrevious     int64 // Number of bytes when the PCR of the reference clock before the last one was received
+	packets           int64
+	scrambledPackets  int64
+	scramblingControl uint8
+	stuffingBytes     int64
+}
+
+// newStatsCollector creates a new stats collector
+func newStatsCollector() *statsCollector {
+	return &statsCollector{
+		m:         &sync.Mutex{},
+		pids:      make(map[uint16]*statsPID),
+		programs:  make(map[uint16][]uint16),
+		total:     &statsPID{},
+		unwrapper: NewClockUnwrapper(),
+	}
+}
+
+// add accumulates the statistics of a packet
+func (s *statsCollector) add(p *Packet) {
+	// Lock
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	// Update counters
+	v, ok := s.pids[p.Header.PID]
+	if !ok {
+		v = &statsPID{}
+		s.pids[p.Header.PID] = v
+	}
+	var stuffingBytes = adaptationFieldStuffingBytes(p)
+	for _, c := range []*statsPID{v, s.total} {
+		c.bytes += MpegTsPacketSize
+		c.packets++
+		c.stuffingBytes += int64(stuffingBytes)
+		if p.Header.TransportScramblingControl != ScramblingControlNotScrambled {
+			c.scrambledPackets++
+		}
+	}
+	v.scramblingControl = p.Header.TransportScramblingControl
+
+	// Check PCR
+	if !p.Header.HasAdaptationField || p.AdaptationField == nil || !p.AdaptationField.HasPCR || p.AdaptationField.PCR == nil {
+		return
+	} else if s.hasPCR && p.Header.PID != s.pcrPID {
+		return
+	}
+
+	// First PCR of the reference clock or discontinuity
+	var c = s.unwrapper.Unwrap(p.AdaptationField.PCR).Ticks27MHz()
+	if !s.hasPCR || p.AdaptationField.DiscontinuityIndicator || c <= s.last {
+		s.hasPCR = true
+		s.pcrPID = p.Header.PID
+		s.first, s.previous, s.last = c, c, c
+		for _, v := range s.counters() {
+			v.bytesFirst, v.bytesPrevious, v.bytesLast = v.bytes, v.bytes, v.bytes
+		}
+		return
+	}
+
+	// Update
+	s.previous, s.last = s.last, c
+	for _, v := range s.counters() {
+		v.bytesPrevious, v.bytesLast = v.bytesLast, v.bytes
+	}
+}
+
+// adaptationFieldStuffingBytes returns the number of stuffing bytes of the adaptation field of a packet, if any
+// Page: 41 | Chapter: 2.4.3.5 | Link: https://www.itu.int/rec/dologin_pub.asp?lang=e&id=T-REC-H.222.0-201206-S!!PDF-E&type=items
+func adaptationFieldStuffingBytes(p *Packet) int {
+	// No adaptation field
+	var a = p.AdaptationField
+	if !p.Header.HasAdaptationField || a == nil || a.Length == 0 {
+		return 0
+	}
+
+	// Compute length of the fields
+	var l = 1
+	if a.HasPCR {
+		l += 6
+	}
+	if a.HasOPCR {
+		l += 6
+	}
+	if a.HasSplicingCountdown {
+		l += 1
+	}
+	if a.HasTransportPrivateData {
+		l += 1 + a.TransportPrivateDataLength
+	}
+	if a.HasAdaptationExtensionField && a.AdaptationExtensionField != nil {
+		l += 1 + a.AdaptationExtensionField.Length
+	}
+
+	// Stuffing
+	if l >= a.Length {
+		return 0
+	}
+	return a.Length - l
+}
+
+// counters returns the counters of all PIDs as well as the total ones
+func (s *statsCollector) counters() (vs []*statsPID) {
+	vs = []*statsPID{s.total}
+	for _, v := range s.pids {
+		vs = append(vs, v)
+	}
+	return
+}
+
+// setProgram updates the PIDs of a program
+func (s *statsCollector) setProgram(pid uint16, d *PMTData) {
+	// Lock
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	// Set PIDs
+	var pids = []uint16{pid}
+	for _, es := range d.ElementaryStreams {
+		pids = append(pids, es.ElementaryPID)
+	}
+	if d.PCRPID != PIDNull && !containsPID(pids, d.PCRPID) {
+		pids = append(pids, d.PCRPID)
+	}
+	s.programs[d.ProgramNumber] = pids
+}
+
+// containsPID checks whether a PID is in a set of PIDs
+func containsPID(pids []uint16, pid uint16) bool {
+	for _, v := range pids {
+		if v == pid {
+			return true
+		}
+	}
+	return false
+}
+
+// stats returns a snapshot of the statistics
+func (s *statsCollector) stats() (st *Stats) {
+	// Lock
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	// Init
+	st = &Stats{
+		Bytes:            s.total.bytes,
+		Duration:         ticks27MHzToDuration(s.last - s.first),
+		Packets:          s.total.packets,
+		PCRPID:           s.pcrPID,
+		PIDs:             make(map[uint16]*PIDStats),
+		Programs:         make(map[uint16]*ProgramStats),
+		ScrambledPackets: s.total.scrambledPackets,
+		StuffingBytes:    s.total.stuffingBytes,
+	}
+	st.AverageBitrate, st.InstantaneousBitrate = s.bitrates(s.total)
+
+	// Overhead
+	if v, ok := s.pids[PIDNull]; ok {
+		st.NullPackets = v.packets
+	}
+	if st.Packets > 0 {
+		st.NullPacketsRatio = float64(st.NullPackets) / float64(st.Packets)
+		st.ScrambledRatio = float64(st.ScrambledPackets) / float64(st.Packets)
+		st.StuffingRatio = float64(st.StuffingBytes) / float64(st.Bytes)
+	}
+
+	// PIDs
+	for pid, v := range s.pids {
+		var ps = &PIDStats{
+			Bytes:             v.bytes,
+			Packets:           v.packets,
+			ScrambledPackets:  v.scrambledPackets,
+			ScramblingControl: v.scramblingControl,
+			StuffingBytes:     v.stuffingBytes,
+		}
+		ps.AverageBitrate, ps.InstantaneousBitrate = s.bitrates(v)
+		st.PIDs[pid] = ps
+	}
+
+	// Programs
+	for number, pids := range s.programs {
+		var t = &statsPID{}
+		for _, pid := range pids {
+			if v, ok := s.pids[pid]; ok {
+				t.bytes += v.bytes
+				t.bytesFirst += v.bytesFirst
+				t.bytesLast += v.bytesLast
+				t.bytesPrevious += v.bytesPrevious
+				t.packets += v.packets
+				t.scrambledPackets += v.scrambledPackets
+			}
+		}
+		var ps = &ProgramStats{Bytes: t.bytes, Packets: t.packets, PIDs: append([]uint16{}, pids...), ScrambledPackets: t.scrambledPackets}
+		ps.AverageBitrate, ps.InstantaneousBitrate = s.bitrates(t)
+		st.Programs[number] = ps
+	}
+	return
+}
+
+// bitrates computes the average and instantaneous bitrates of counters
+func (s *statsCollector) bitrates(v *statsPID) (average, instantaneous int) {
+	return statsBitrate(v.bytesLast-v.bytesFirst, s.last-s.first), statsBitrate(v.bytesLast-v.bytesPrevious, s.last-s.previous)
+}
+
+// statsBitrate computes the bitrate in bits per second of a number of bytes transmitted during a number of 27 MHz ticks
+// Big integers are used since the number of bits times the clock frequency overflows an int64 after about 42 GB
+func statsBitrate(bytes int64, ticks int) int {
+	if ticks <= 0 {
+		return 0
+	}
+	var b = new(big.Int).Mul(big.NewInt(bytes*8), big.NewInt(27000000))
+	return int(b.Quo(b, big.NewInt(int64(ticks))).Int64())
+}
+
+// Stats returns a snapshot of the statistics about the packets read so far
+// It can be called while the demuxer is streaming
+func (dmx *Demuxer) Stats() *Stats {
+	return dmx.statsCollector.stats()
+}
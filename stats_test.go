@@ -0,0 +1,83 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDemuxerStats(t *testing.T) {
+	// Init
+	buf := &bytes.Buffer{}
+	m := NewMuxer(context.Background(), buf)
+	m.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x100, StreamType: StreamTypeLowerBitrateVideo})
+	m.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x101, StreamType: StreamTypeMPEG1Audio})
+	m.SetPCRPID(0x100)
+	var pes = func(streamID uint8) *PESData {
+		return &PESData{Data: []byte("data"), Header: &PESHeader{OptionalHeader: &PESOptionalHeader{}, StreamID: streamID}}
+	}
+	for i := 0; i < 11; i++ {
+		m.WriteData(&MuxerData{AdaptationField: &PacketAdaptationField{HasPCR: true, PCR: NewClockReferenceFromDuration(time.Duration(i) * 10 * time.Millisecond)}, PES: pes(0xe0), PID: 0x100})
+		m.WriteData(&MuxerData{PES: pes(0xc0), PID: 0x101})
+		if i < 10 {
+			m.WriteData(&MuxerData{PES: pes(0xc0), PID: 0x101})
+		}
+	}
+	m.WriteTables()
+	buf.Write(writePacket(&Packet{Header: &PacketHeader{HasPayload: true, PID: PIDNull}, Payload: make([]byte, 184)}))
+	buf.Write(writePacket(&Packet{Header: &PacketHeader{HasPayload: true, PID: PIDNull}, Payload: make([]byte, 184)}))
+
+	// Demux
+	dmx := New(context.Background(), bytes.NewReader(buf.Bytes()))
+	assert.Equal(t, 0, dmx.Stats().AverageBitrate)
+	for {
+		if _, err := dmx.NextData(); err != nil {
+			assert.Equal(t, ErrNoMorePackets, err)
+			break
+		}
+	}
+	s := dmx.Stats()
+	assert.Equal(t, int64(38*MpegTsPacketSize), s.Bytes)
+	assert.Equal(t, int64(38), s.Packets)
+	assert.Equal(t, int64(2), s.NullPackets)
+	assert.Equal(t, 2.0/38, s.NullPacketsRatio)
+	assert.Equal(t, int64(11*163+21*169), s.StuffingBytes)
+	assert.Equal(t, float64(11*163+21*169)/(38*MpegTsPacketSize), s.StuffingRatio)
+	assert.Equal(t, 100*time.Millisecond, s.Duration)
+	assert.Equal(t, uint16(0x100), s.PCRPID)
+	assert.Equal(t, 451200, s.AverageBitrate)
+	assert.Equal(t, 451200, s.InstantaneousBitrate)
+	assert.Equal(t, &PIDStats{AverageBitrate: 150400, Bytes: 11 * MpegTsPacketSize, InstantaneousBitrate: 150400, Packets: 11, StuffingBytes: 11 * 163}, s.PIDs[0x100])
+	assert.Equal(t, &PIDStats{AverageBitrate: 300800, Bytes: 21 * MpegTsPacketSize, InstantaneousBitrate: 300800, Packets: 21, StuffingBytes: 21 * 169}, s.PIDs[0x101])
+	assert.Equal(t, &PIDStats{Bytes: 2 * MpegTsPacketSize, Packets: 2}, s.PIDs[PIDPAT])
+	assert.Equal(t, &ProgramStats{AverageBitrate: 451200, Bytes: 34 * MpegTsPacketSize, InstantaneousBitrate: 451200, Packets: 34, PIDs: []uint16{0x1000, 0x100, 0x101}}, s.Programs[1])
+
+	// Rewind
+	_, err := dmx.Rewind()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), dmx.Stats().Packets)
+}
+
+func TestStatsScrambling(t *testing.T) {
+	s := newStatsCollector()
+	s.setProgram(0x1000, &PMTData{ElementaryStreams: []*PMTElementaryStream{{ElementaryPID: 0x100}}, ProgramNumber: 1})
+	for _, c := range []uint8{ScramblingControlScrambledWithEvenKey, ScramblingControlNotScrambled, ScramblingControlScrambledWithOddKey} {
+		s.add(&Packet{Header: &PacketHeader{PID: 0x100, TransportScramblingControl: c}})
+	}
+	st := s.stats()
+	assert.Equal(t, int64(2), st.ScrambledPackets)
+	assert.Equal(t, 2.0/3, st.ScrambledRatio)
+	assert.Equal(t, &PIDStats{Bytes: 3 * MpegTsPacketSize, Packets: 3, ScrambledPackets: 2, ScramblingControl: ScramblingControlScrambledWithOddKey}, st.PIDs[0x100])
+	assert.Equal(t, int64(2), st.Programs[1].ScrambledPackets)
+}
+
+func TestStatsBitrate(t *testing.T) {
+	assert.Equal(t, 0, statsBitrate(1000, 0))
+	assert.Equal(t, 8000, statsBitrate(1000, 27000000))
+
+	// 50 Mbps during 2 hours
+	assert.Equal(t, 50000000, statsBitrate(45000000000, 2*3600*27000000))
+}
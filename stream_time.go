@@ -0,0 +1,116 @@
+package astits
+
+import (
+	"sync"
+	"time"
+)
+
+// StreamTime represents the wall clock time of the broadcaster, as signaled by the last TDT or TOT received
+// The UTC time is only as accurate as the table that carried it, which is sent at least every 30s
+type StreamTime struct {
+	LocalTimeOffsets []*StreamTimeLocalTimeOffset // Local time offsets of the last TOT received
+	UTCTime          time.Time
+}
+
+// StreamTimeLocalTimeOffset represents the local time of a region, as signaled by a local time offset descriptor
+type StreamTimeLocalTimeOffset struct {
+	CountryCode     string
+	CountryRegionID uint8
+	LocalTime       time.Time     // UTC time in the time zone of the offset in use
+	NextOffset      time.Duration // Offset in use after the time of change
+	Offset          time.Duration // Offset in use at the UTC time, which is negative west of Greenwich
+	TimeOfChange    time.Time     // Time at which the offset changes, e.g. because of daylight saving time
+}
+
+// LocalTimeOffset returns the local time offset of a region, or nil if it is not signaled
+func (t *StreamTime) LocalTimeOffset(countryCode string, countryRegionID uint8) *StreamTimeLocalTimeOffset {
+	for _, o := range t.LocalTimeOffsets {
+		if o.CountryCode == countryCode && o.CountryRegionID == countryRegionID {
+			return o
+		}
+	}
+	return nil
+}
+
+// streamTimeTracker keeps track of the wall clock time of the stream
+type streamTimeTracker struct {
+	m        *sync.Mutex
+	offsets  []*DescriptorLocalTimeOffsetItem
+	received bool
+	utcTime  time.Time
+}
+
+// newStreamTimeTracker creates a new stream time tracker
+func newStreamTimeTracker() *streamTimeTracker {
+	return &streamTimeTracker{m: &sync.Mutex{}}
+}
+
+// setTDT updates the UTC time with a TDT
+func (t *streamTimeTracker) setTDT(d *TDTData) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	t.received = true
+	t.utcTime = d.UTCTime
+}
+
+// setTOT updates the UTC time and the local time offsets with a TOT
+func (t *streamTimeTracker) setTOT(d *TOTData) {
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// Update
+	t.offsets = nil
+	t.received = true
+	t.utcTime = d.UTCTime
+	for _, dsc := range d.Descriptors {
+		if dsc.LocalTimeOffset != nil {
+			t.offsets = append(t.offsets, dsc.LocalTimeOffset.Items...)
+		}
+	}
+}
+
+// streamTime returns the stream time, or nil if no TDT or TOT has been received yet
+func (t *streamTimeTracker) streamTime() (s *StreamTime) {
+	// Lock
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	// No time received
+	if !t.received {
+		return
+	}
+
+	// Loop through offsets
+	s = &StreamTime{UTCTime: t.utcTime}
+	for _, itm := range t.offsets {
+		// Offsets are negative when the polarity is set
+		var o = &StreamTimeLocalTimeOffset{
+			CountryCode:     string(itm.CountryCode),
+			CountryRegionID: itm.CountryRegionID,
+			NextOffset:      itm.NextTimeOffset,
+			Offset:          itm.LocalTimeOffset,
+			TimeOfChange:    itm.TimeOfChange,
+		}
+		if itm.LocalTimeOffsetPolarity {
+			o.NextOffset = -o.NextOffset
+			o.Offset = -o.Offset
+		}
+
+		// The next offset is in use once the time of change is reached
+		var offset = o.Offset
+		if !t.utcTime.Before(o.TimeOfChange) {
+			offset = o.NextOffset
+		}
+		o.LocalTime = t.utcTime.In(time.FixedZone(o.CountryCode, int(offset/time.Second)))
+		s.LocalTimeOffsets = append(s.LocalTimeOffsets, o)
+	}
+	return
+}
+
+// StreamTime returns the wall clock time of the broadcaster signaled by the last TDT or TOT received, along with the
+// local time offsets and their upcoming changes signaled by the TOT, or nil if none has been received yet
+// It can be called at any time, even while streaming
+func (dmx *Demuxer) StreamTime() *StreamTime {
+	return dmx.streamTimeTracker.streamTime()
+}
@@ -0,0 +1,59 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamTimeTracker(t *testing.T) {
+	// Init
+	tr := newStreamTimeTracker()
+	assert.Nil(t, tr.streamTime())
+	var utc = time.Date(2018, 3, 25, 0, 30, 0, 0, time.UTC)
+	var change = time.Date(2018, 3, 25, 1, 0, 0, 0, time.UTC)
+
+	// TOT
+	tr.setTOT(&TOTData{Descriptors: []*Descriptor{{LocalTimeOffset: &DescriptorLocalTimeOffset{Items: []*DescriptorLocalTimeOffsetItem{
+		{CountryCode: []byte("FRA"), LocalTimeOffset: time.Hour, NextTimeOffset: 2 * time.Hour, TimeOfChange: change},
+		{CountryCode: []byte("BRA"), CountryRegionID: 1, LocalTimeOffset: 3 * time.Hour, LocalTimeOffsetPolarity: true, NextTimeOffset: 3 * time.Hour, TimeOfChange: change},
+	}}}}, UTCTime: utc})
+	s := tr.streamTime()
+	assert.Equal(t, utc, s.UTCTime)
+	assert.Nil(t, s.LocalTimeOffset("FRA", 1))
+	o := s.LocalTimeOffset("FRA", 0)
+	assert.Equal(t, time.Hour, o.Offset)
+	assert.Equal(t, 2*time.Hour, o.NextOffset)
+	assert.Equal(t, change, o.TimeOfChange)
+	assert.Equal(t, "01:30", o.LocalTime.Format("15:04"))
+	assert.True(t, utc.Equal(o.LocalTime))
+	o = s.LocalTimeOffset("BRA", 1)
+	assert.Equal(t, -3*time.Hour, o.Offset)
+	assert.Equal(t, "21:30", o.LocalTime.Format("15:04"))
+
+	// TDT updates the UTC time only, offsets change once the time of change is reached
+	tr.setTDT(&TDTData{UTCTime: change})
+	s = tr.streamTime()
+	assert.Equal(t, change, s.UTCTime)
+	assert.Equal(t, "03:00", s.LocalTimeOffset("FRA", 0).LocalTime.Format("15:04"))
+}
+
+func TestDemuxerStreamTime(t *testing.T) {
+	// Sections are returned once the next one starts
+	var b []byte
+	var cc uint8
+	for idx := 0; idx < 2; idx++ {
+		for _, p := range packetizePayload(0x14, writePSIPayload([][]byte{append([]byte{0x70, 0x70, 0x5}, dvbTimeBytes...)}), nil, true, &cc) {
+			b = append(b, writePacket(p)...)
+		}
+	}
+	dmx := New(context.Background(), bytes.NewReader(b))
+	assert.Nil(t, dmx.StreamTime())
+	d, err := dmx.NextData()
+	assert.NoError(t, err)
+	assert.Equal(t, &TDTData{UTCTime: dvbTime}, d.TDT)
+	assert.Equal(t, dvbTime, dmx.StreamTime().UTCTime)
+}
@@ -0,0 +1,595 @@
+package astits
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// T2-MI packet types
+// Page: 12 | Chapter: 5.2.1 | Link: https://www.etsi.org/deliver/etsi_ts/102700_102799/102773/01.04.01_60/ts_102773v010401p.pdf
+const (
+	T2MIPacketTypeArbitraryCellInsertion = 0x2
+	T2MIPacketTypeAuxiliaryStreamIQData  = 0x1
+	T2MIPacketTypeBBFrame                = 0x0
+	T2MIPacketTypeFEFPartComposite       = 0x32
+	T2MIPacketTypeFEFPartIQData          = 0x31
+	T2MIPacketTypeFEFPartNull            = 0x30
+	T2MIPacketTypeFEFSubPart             = 0x33
+	T2MIPacketTypeIndividualAddressing   = 0x21
+	T2MIPacketTypeL1Current              = 0x10
+	T2MIPacketTypeL1Future               = 0x11
+	T2MIPacketTypeP2BiasBalancingCells   = 0x12
+	T2MIPacketTypeTimestamp              = 0x20
+)
+
+// T2 baseband header input stream types
+// Page: 29 | Chapter: 5.1.7 | Link: https://www.etsi.org/deliver/etsi_en/302700_302799/302755/01.04.01_60/en_302755v010401p.pdf
+const (
+	T2BBHeaderStreamTypeGCS  = 1 // Generic continuous stream
+	T2BBHeaderStreamTypeGFPS = 0 // Generic fixed-length packetized stream
+	T2BBHeaderStreamTypeGSE  = 2 // Generic encapsulated stream
+	T2BBHeaderStreamTypeTS   = 3 // Transport stream
+)
+
+// T2-MI constants
+const (
+	t2BBHeaderLength               = 10
+	t2BBHeaderSYNCDNoUserPacket    = 0xffff
+	t2L1PreLength                  = 21
+	t2miBBFramePayloadHeaderLength = 3
+	t2miCRC32Length                = 4
+	t2miHeaderLength               = 6
+)
+
+// T2MIData represents the T2-MI packets carried by a T2-MI elementary stream, as fed to DVB-T2 modulators
+// Page: 26 | Chapter: 5.1 | Link: https://www.etsi.org/deliver/etsi_ts/102700_102799/102773/01.04.01_60/ts_102773v010401p.pdf
+type T2MIData struct {
+	Packets []*T2MIPacket
+}
+
+// T2MIPacket represents a T2-MI packet
+// Page: 11 | Chapter: 5.1 | Link: https://www.etsi.org/deliver/etsi_ts/102700_102799/102773/01.04.01_60/ts_102773v010401p.pdf
+type T2MIPacket struct {
+	BBFrame         *T2MIBBFrame   // Set when the packet type is T2MIPacketTypeBBFrame
+	L1Current       *T2MIL1Current // Set when the packet type is T2MIPacketTypeL1Current
+	PacketCount     uint8
+	Payload         []byte // The last byte may end with padding bits
+	StreamID        uint8
+	SuperframeIndex uint8
+	Type            uint8
+}
+
+// T2MIBBFrame represents a baseband frame carried by a T2-MI packet
+// Page: 13 | Chapter: 5.2.2 | Link: https://www.etsi.org/deliver/etsi_ts/102700_102799/102773/01.04.01_60/ts_102773v010401p.pdf
+type T2MIBBFrame struct {
+	DataField              []byte
+	FrameIndex             uint8
+	Header                 *T2BBHeader
+	InterleavingFrameStart bool
+	PLPID                  uint8
+	TSPackets              [][]byte // Inner transport stream packets completed by this frame, sync byte included. Only set by the demuxer since user packets may span over several frames of the PLP
+}
+
+// T2BBHeader represents a DVB-T2 baseband header
+// Page: 29 | Chapter: 5.1.7 | Link: https://www.etsi.org/deliver/etsi_en/302700_302799/302755/01.04.01_60/en_302755v010401p.pdf
+type T2BBHeader struct {
+	ACM                   bool   // Unset for constant coding and modulation
+	DFL                   uint16 // Data field length in bits
+	Extension             uint8
+	HasISSY               bool
+	HasNullPacketDeletion bool
+	HighEfficiencyMode    bool
+	ISSY                  uint32 // Only set in high efficiency mode, where the ISSY is carried by the header instead of by the user packets
+	PLPID                 uint8
+	SingleInputStream     bool
+	StreamType            uint8
+	SYNC                  uint8
+	SYNCD                 uint16 // Distance in bits between the start of the data field and the first user packet starting in it
+	UPL                   uint16 // User packet length in bits, only set in normal mode
+}
+
+// T2MIL1Current represents the L1 signalling of the current T2 frame carried by a T2-MI packet
+// L1-post fields are left aligned and their length is in bits
+// Page: 14 | Chapter: 5.2.3 | Link: https://www.etsi.org/deliver/etsi_ts/102700_102799/102773/01.04.01_60/ts_102773v010401p.pdf
+type T2MIL1Current struct {
+	FrameIndex               uint8
+	L1PostConfigurable       []byte
+	L1PostConfigurableLength uint16
+	L1PostDynamic            []byte
+	L1PostDynamicLength      uint16
+	L1PostExtension          []byte
+	L1PostExtensionLength    uint16
+	L1Pre                    *T2L1Pre
+}
+
+// T2L1Pre represents the DVB-T2 L1-pre signalling
+// Page: 59 | Chapter: 7.2.2 | Link: https://www.etsi.org/deliver/etsi_en/302700_302799/302755/01.04.01_60/en_302755v010401p.pdf
+type T2L1Pre struct {
+	BandwidthExtension bool
+	CellID             uint16
+	CurrentRFIndex     uint8
+	GuardInterval      uint8
+	L1CodeRate         uint8
+	L1FECType          uint8
+	L1Modulation       uint8
+	L1PostExtension    bool
+	L1PostInfoSize     uint32
+	L1PostScrambled    bool
+	L1PostSize         uint32
+	L1RepetitionFlag   bool
+	NetworkID          uint16
+	NumDataSymbols     uint16
+	NumRF              uint8
+	NumT2Frames        uint8
+	PAPR               uint8
+	PilotPattern       uint8
+	RegenFlag          uint8
+	S1                 uint8
+	S2                 uint8
+	T2BaseLite         bool
+	T2SystemID         uint16
+	T2Version          uint8
+	TXIDAvailability   uint8
+	Type               uint8
+}
+
+// ParseT2MIPackets parses the T2-MI packets concatenated in a payload
+func ParseT2MIPackets(i []byte) (ps []*T2MIPacket, err error) {
+	var offset int
+	for offset < len(i) {
+		var p *T2MIPacket
+		if p, err = parseT2MIPacket(i, &offset); err != nil {
+			err = errors.Wrapf(err, "astits: parsing T2-MI packet #%d failed", len(ps)+1)
+			return
+		}
+		ps = append(ps, p)
+	}
+	return
+}
+
+// t2miPacketLength returns the length in bytes of the T2-MI packet starting the payload, or 0 if its header is
+// incomplete
+func t2miPacketLength(i []byte) int {
+	if len(i) < t2miHeaderLength {
+		return 0
+	}
+	return t2miHeaderLength + (int(uint16(i[4])<<8|uint16(i[5]))+7)/8 + t2miCRC32Length
+}
+
+// parseT2MIPacket parses a T2-MI packet
+func parseT2MIPacket(i []byte, offset *int) (p *T2MIPacket, err error) {
+	// Check length
+	var l = t2miPacketLength(i[*offset:])
+	if l == 0 || *offset+l > len(i) {
+		err = fmt.Errorf("astits: T2-MI packet end (%d) > len(i) (%d)", *offset+l, len(i))
+		return
+	}
+	var b = i[*offset : *offset+l]
+	*offset += l
+
+	// Check CRC32
+	if c, e := computeCRC32(b[:l-t2miCRC32Length]), parseCRC32(b[l-t2miCRC32Length:]); c != e {
+		err = fmt.Errorf("astits: T2-MI packet CRC32 %d != computed CRC32 %d", e, c)
+		return
+	}
+
+	// Header
+	p = &T2MIPacket{
+		PacketCount:     b[1],
+		Payload:         b[t2miHeaderLength : l-t2miCRC32Length],
+		StreamID:        b[3] & 0x7,
+		SuperframeIndex: b[2] >> 4,
+		Type:            b[0],
+	}
+
+	// Switch on type
+	switch p.Type {
+	case T2MIPacketTypeBBFrame:
+		if p.BBFrame, err = parseT2MIBBFrame(p.Payload); err != nil {
+			err = errors.Wrap(err, "astits: parsing T2-MI BBFrame failed")
+			return
+		}
+	case T2MIPacketTypeL1Current:
+		if p.L1Current, err = parseT2MIL1Current(p.Payload); err != nil {
+			err = errors.Wrap(err, "astits: parsing T2-MI L1-current failed")
+			return
+		}
+	}
+	return
+}
+
+// parseT2MIBBFrame parses the payload of a BBFrame T2-MI packet
+func parseT2MIBBFrame(i []byte) (f *T2MIBBFrame, err error) {
+	// Check length
+	if len(i) < t2miBBFramePayloadHeaderLength+t2BBHeaderLength {
+		err = fmt.Errorf("astits: T2 BBHeader end (%d) > len(i) (%d)", t2miBBFramePayloadHeaderLength+t2BBHeaderLength, len(i))
+		return
+	}
+
+	// Create frame
+	f = &T2MIBBFrame{
+		FrameIndex:             i[0],
+		InterleavingFrameStart: i[2]&0x80 > 0,
+		PLPID:                  i[1],
+	}
+
+	// Header
+	var b = i[t2miBBFramePayloadHeaderLength:]
+	if f.Header, err = parseT2BBHeader(b); err != nil {
+		err = errors.Wrap(err, "astits: parsing T2 BBHeader failed")
+		return
+	}
+
+	// Data field
+	var l = t2BBHeaderLength + int(f.Header.DFL)/8
+	if l > len(b) {
+		err = fmt.Errorf("astits: T2 data field end (%d) > len(b) (%d)", l, len(b))
+		return
+	}
+	f.DataField = b[t2BBHeaderLength:l]
+	return
+}
+
+// parseT2BBHeader parses a DVB-T2 baseband header, whose mode is signalled by its CRC-8
+// Page: 29 | Chapter: 5.1.7 | Link: https://www.etsi.org/deliver/etsi_en/302700_302799/302755/01.04.01_60/en_302755v010401p.pdf
+func parseT2BBHeader(i []byte) (h *T2BBHeader, err error) {
+	// Mode
+	var mode = computeT2CRC8(i[:t2BBHeaderLength-1]) ^ i[t2BBHeaderLength-1]
+	if mode > 1 {
+		err = fmt.Errorf("astits: T2 BBHeader CRC-8 is invalid")
+		return
+	}
+
+	// Create header
+	h = &T2BBHeader{
+		ACM:                   i[0]&0x10 == 0,
+		DFL:                   uint16(i[4])<<8 | uint16(i[5]),
+		Extension:             i[0] & 0x3,
+		HasISSY:               i[0]&0x8 > 0,
+		HasNullPacketDeletion: i[0]&0x4 > 0,
+		HighEfficiencyMode:    mode == 1,
+		PLPID:                 i[1],
+		SingleInputStream:     i[0]&0x20 > 0,
+		StreamType:            i[0] >> 6,
+		SYNCD:                 uint16(i[7])<<8 | uint16(i[8]),
+	}
+
+	// In high efficiency mode, the ISSY replaces the UPL and the SYNC
+	if h.HighEfficiencyMode {
+		h.ISSY = uint32(i[2])<<16 | uint32(i[3])<<8 | uint32(i[6])
+	} else {
+		h.SYNC = i[6]
+		h.UPL = uint16(i[2])<<8 | uint16(i[3])
+	}
+	return
+}
+
+// computeT2CRC8 computes the CRC-8 of a DVB-T2 baseband header, whose polynomial is x^8+x^7+x^6+x^4+x^2+1
+// Page: 28 | Chapter: 5.1.4 | Link: https://www.etsi.org/deliver/etsi_en/302700_302799/302755/01.04.01_60/en_302755v010401p.pdf
+func computeT2CRC8(i []byte) (o uint8) {
+	for _, b := range i {
+		o ^= b
+		for idx := 0; idx < 8; idx++ {
+			if o&0x80 > 0 {
+				o = o<<1 ^ 0xd5
+			} else {
+				o <<= 1
+			}
+		}
+	}
+	return
+}
+
+// userPacketLength returns the length in bytes of the user packets of the data field, or 0 if the data field doesn't
+// carry a transport stream
+// In high efficiency mode, the sync byte is removed from the user packets and their length is implicit
+func (h *T2BBHeader) userPacketLength() (l int) {
+	if h.StreamType != T2BBHeaderStreamTypeTS {
+		return
+	} else if !h.HighEfficiencyMode {
+		if l = int(h.UPL) / 8; l < MpegTsPacketSize {
+			l = 0
+		}
+		return
+	}
+	l = MpegTsPacketSize - 1
+	if h.HasNullPacketDeletion {
+		l++
+	}
+	return
+}
+
+// parseT2MIL1Current parses the payload of a L1-current T2-MI packet
+func parseT2MIL1Current(i []byte) (l *T2MIL1Current, err error) {
+	// Check length
+	if len(i) < 2+t2L1PreLength {
+		err = fmt.Errorf("astits: T2 L1-pre end (%d) > len(i) (%d)", 2+t2L1PreLength, len(i))
+		return
+	}
+
+	// Create L1
+	l = &T2MIL1Current{FrameIndex: i[0]}
+
+	// L1-pre
+	var r = newBitReader(i[2:])
+	if l.L1Pre, err = parseT2L1Pre(r); err != nil {
+		err = errors.Wrap(err, "astits: parsing T2 L1-pre failed")
+		return
+	}
+
+	// L1-post
+	for _, v := range []struct {
+		b *[]byte
+		l *uint16
+		n string
+	}{
+		{b: &l.L1PostConfigurable, l: &l.L1PostConfigurableLength, n: "configurable"},
+		{b: &l.L1PostDynamic, l: &l.L1PostDynamicLength, n: "dynamic"},
+		{b: &l.L1PostExtension, l: &l.L1PostExtensionLength, n: "extension"},
+	} {
+		// Length
+		var n uint32
+		if n, err = r.read(16); err != nil {
+			err = errors.Wrapf(err, "astits: reading T2 L1-post %s length failed", v.n)
+			return
+		}
+		*v.l = uint16(n)
+
+		// Bits
+		if *v.b, err = readT2L1Bits(r, int(n)); err != nil {
+			err = errors.Wrapf(err, "astits: reading T2 L1-post %s failed", v.n)
+			return
+		}
+	}
+	return
+}
+
+// parseT2L1Pre parses a DVB-T2 L1-pre signalling
+func parseT2L1Pre(r *bitReader) (p *T2L1Pre, err error) {
+	// Read fields
+	var vs = make([]uint32, 27)
+	for idx, n := range []int{8, 1, 3, 4, 1, 3, 4, 4, 2, 2, 18, 18, 4, 8, 16, 16, 16, 8, 12, 3, 1, 3, 3, 4, 1, 1, 4} {
+		if vs[idx], err = r.read(n); err != nil {
+			err = errors.Wrap(err, "astits: reading T2 L1-pre field failed")
+			return
+		}
+	}
+	p = &T2L1Pre{
+		BandwidthExtension: vs[1] > 0,
+		CellID:             uint16(vs[14]),
+		CurrentRFIndex:     uint8(vs[22]),
+		GuardInterval:      uint8(vs[5]),
+		L1CodeRate:         uint8(vs[8]),
+		L1FECType:          uint8(vs[9]),
+		L1Modulation:       uint8(vs[7]),
+		L1PostExtension:    vs[20] > 0,
+		L1PostInfoSize:     vs[11],
+		L1PostScrambled:    vs[24] > 0,
+		L1PostSize:         vs[10],
+		L1RepetitionFlag:   vs[4] > 0,
+		NetworkID:          uint16(vs[15]),
+		NumDataSymbols:     uint16(vs[18]),
+		NumRF:              uint8(vs[21]),
+		NumT2Frames:        uint8(vs[17]),
+		PAPR:               uint8(vs[6]),
+		PilotPattern:       uint8(vs[12]),
+		RegenFlag:          uint8(vs[19]),
+		S1:                 uint8(vs[2]),
+		S2:                 uint8(vs[3]),
+		T2BaseLite:         vs[25] > 0,
+		T2SystemID:         uint16(vs[16]),
+		T2Version:          uint8(vs[23]),
+		TXIDAvailability:   uint8(vs[13]),
+		Type:               uint8(vs[0]),
+	}
+	return
+}
+
+// readT2L1Bits reads n bits that don't need to be aligned on bytes and returns them left aligned
+func readT2L1Bits(r *bitReader, n int) (b []byte, err error) {
+	b = make([]byte, (n+7)/8)
+	for idx := 0; n > 0; idx++ {
+		var c = 8
+		if n < c {
+			c = n
+		}
+		var v uint32
+		if v, err = r.read(c); err != nil {
+			return
+		}
+		b[idx] = uint8(v << uint(8-c))
+		n -= c
+	}
+	return
+}
+
+// t2miTracker keeps track of the elementary streams carrying T2-MI packets
+type t2miTracker struct {
+	pids map[uint16]*t2miTrackerPID // Indexed by elementary PID
+}
+
+// t2miTrackerPID represents an elementary stream carrying T2-MI packets
+type t2miTrackerPID struct {
+	buf         []byte            // Start of the T2-MI packet being reassembled
+	userPackets map[uint16][]byte // Start of the user packet being reassembled, indexed by T2-MI stream ID and PLP ID
+}
+
+// newT2MITracker creates a new T2-MI tracker
+func newT2MITracker() *t2miTracker {
+	return &t2miTracker{pids: make(map[uint16]*t2miTrackerPID)}
+}
+
+// setProgram adds the T2-MI streams of a program, i.e. the elementary streams described by a T2-MI extension
+// descriptor
+func (t *t2miTracker) setProgram(d *PMTData) {
+	for _, es := range d.ElementaryStreams {
+		if _, ok := t.pids[es.ElementaryPID]; ok || es.StreamType != StreamTypeMPEG2PacketizedData {
+			continue
+		}
+		for _, dsc := range es.ElementaryStreamDescriptors {
+			if dsc.Extension != nil && dsc.Extension.T2MI != nil {
+				t.pids[es.ElementaryPID] = &t2miTrackerPID{userPackets: make(map[uint16][]byte)}
+				break
+			}
+		}
+	}
+}
+
+// reset drops the T2-MI packets and the user packets being reassembled, the T2-MI streams being kept as long as the
+// PMTs are
+func (t *t2miTracker) reset() {
+	for _, p := range t.pids {
+		p.buf = nil
+		p.userPackets = make(map[uint16][]byte)
+	}
+}
+
+// parse parses the T2-MI packets of a payload that starts with a pointer field, and completes the T2-MI packet
+// started in the previous payload with the bytes preceding the pointed T2-MI packet
+// Page: 26 | Chapter: 5.1 | Link: https://www.etsi.org/deliver/etsi_ts/102700_102799/102773/01.04.01_60/ts_102773v010401p.pdf
+func (p *t2miTrackerPID) parse(i []byte) (ps []*T2MIPacket, err error) {
+	// Pointer field
+	if len(i) == 0 || 1+int(i[0]) > len(i) {
+		p.buf = nil
+		err = fmt.Errorf("astits: T2-MI pointer field is invalid")
+		return
+	}
+	var pointer = 1 + int(i[0])
+
+	// Complete the packet started in the previous payload
+	if p.buf != nil {
+		var b = append(p.buf, i[1:pointer]...)
+		p.buf = nil
+		if l := t2miPacketLength(b); l > 0 && l <= len(b) {
+			var offset int
+			var tp *T2MIPacket
+			if tp, err = parseT2MIPacket(b[:l], &offset); err != nil {
+				err = errors.Wrap(err, "astits: parsing T2-MI packet failed")
+			} else {
+				ps = append(ps, tp)
+			}
+		}
+	}
+
+	// Loop through complete packets. Packets following a corrupted packet can't be located
+	var b = i[pointer:]
+	for err == nil {
+		var l = t2miPacketLength(b)
+		if l == 0 || l > len(b) {
+			// Keep the packet left
+			p.buf = append([]byte{}, b...)
+			break
+		}
+		var offset int
+		var tp *T2MIPacket
+		if tp, err = parseT2MIPacket(b[:l], &offset); err != nil {
+			err = errors.Wrap(err, "astits: parsing T2-MI packet failed")
+			break
+		}
+		ps = append(ps, tp)
+		b = b[l:]
+	}
+
+	// Update transport stream packets
+	for _, tp := range ps {
+		if tp.BBFrame != nil {
+			tp.BBFrame.TSPackets = p.tsPackets(tp.StreamID, tp.BBFrame)
+		}
+	}
+	return
+}
+
+// tsPackets returns the transport stream packets completed by a BBFrame, whose first user packet may have started in
+// the previous BBFrame of the same PLP
+// Page: 22 | Chapter: 5.1.5 | Link: https://www.etsi.org/deliver/etsi_en/302700_302799/302755/01.04.01_60/en_302755v010401p.pdf
+func (p *t2miTrackerPID) tsPackets(streamID uint8, f *T2MIBBFrame) (o [][]byte) {
+	// Get user packet length
+	var l = f.Header.userPacketLength()
+	if l == 0 {
+		return
+	}
+
+	// No user packet starts in the data field
+	var key = uint16(streamID)<<8 | uint16(f.PLPID)
+	var buf, ok = p.userPackets[key]
+	if f.Header.SYNCD == t2BBHeaderSYNCDNoUserPacket {
+		if ok {
+			p.userPackets[key] = append(buf, f.DataField...)
+		}
+		return
+	}
+
+	// Complete the user packet started in the previous data field
+	var syncd = int(f.Header.SYNCD) / 8
+	if syncd > len(f.DataField) {
+		delete(p.userPackets, key)
+		return
+	}
+	if ok {
+		if up := append(buf, f.DataField[:syncd]...); len(up) == l {
+			o = append(o, f.Header.tsPackets(up)...)
+		}
+	}
+
+	// Loop through user packets
+	var offset = syncd
+	for ; offset+l <= len(f.DataField); offset += l {
+		o = append(o, f.Header.tsPackets(f.DataField[offset:offset+l])...)
+	}
+
+	// Keep the user packet left
+	p.userPackets[key] = append([]byte{}, f.DataField[offset:]...)
+	return
+}
+
+// tsPackets converts a user packet into a transport stream packet by restoring its sync byte and removing its ISSY
+// and DNP fields. The null packets counted by the DNP field are inserted before it
+// In normal mode, the sync byte is replaced by the CRC-8 of the previous user packet, whereas it's removed in high
+// efficiency mode
+// Page: 25 | Chapter: 5.1.6 | Link: https://www.etsi.org/deliver/etsi_en/302700_302799/302755/01.04.01_60/en_302755v010401p.pdf
+func (h *T2BBHeader) tsPackets(up []byte) (o [][]byte) {
+	// Deleted null packets
+	if h.HasNullPacketDeletion {
+		for idx := 0; idx < int(up[len(up)-1]); idx++ {
+			o = append(o, append([]byte{}, nullPacket...))
+		}
+	}
+
+	// Transport stream packet
+	var b = make([]byte, MpegTsPacketSize)
+	b[0] = syncByte
+	if h.HighEfficiencyMode {
+		copy(b[1:], up)
+	} else {
+		copy(b[1:], up[1:])
+	}
+	o = append(o, b)
+	return
+}
+
+// parseT2MIData parses the T2-MI packets of T2-MI streams
+func (dmx *Demuxer) parseT2MIData(ps []*Packet) (ds []*Data) {
+	// Reconstruct payload
+	var payload []byte
+	for _, p := range ps {
+		payload = append(payload, p.Payload...)
+	}
+
+	// Parse packets
+	var pid = ps[0].Header.PID
+	tps, err := dmx.t2miTracker.pids[pid].parse(payload)
+	if err != nil {
+		// Packets may be corrupted, therefore we only log the error and move on
+		dmx.optLogger.Debugf("astits: parsing T2-MI packets of PID %d failed: %s", pid, err)
+	}
+	if len(tps) == 0 {
+		return
+	}
+	ds = append(ds, &Data{
+		FirstPacket: ps[0],
+		PID:         pid,
+		T2MI:        &T2MIData{Packets: tps},
+	})
+	return
+}
@@ -0,0 +1,220 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/asticode/go-astitools/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+func t2miPacketBytes(packetType uint8, payload []byte) []byte {
+	w := astibinary.New()
+	w.Write(packetType)               // Packet type
+	w.Write(uint8(1))                 // Packet count
+	w.Write("0010")                   // Superframe index
+	w.Write("000000000")              // RFU
+	w.Write("011")                    // T2-MI stream ID
+	w.Write(uint16(len(payload) * 8)) // Payload length
+	w.Write(payload)                  // Payload
+	w.Write(computeCRC32(w.Bytes()))  // CRC32
+	return w.Bytes()
+}
+
+func t2miBBFrameBytes(syncd uint16, df []byte) []byte {
+	h := astibinary.New()
+	h.Write("11")                     // TS/GS
+	h.Write("1")                      // SIS/MIS
+	h.Write("1")                      // CCM/ACM
+	h.Write("0")                      // ISSYI
+	h.Write("0")                      // NPD
+	h.Write("00")                     // EXT
+	h.Write(uint8(1))                 // PLP ID
+	h.Write(uint16(1504))             // UPL
+	h.Write(uint16(len(df) * 8))      // DFL
+	h.Write(uint8(syncByte))          // SYNC
+	h.Write(syncd)                    // SYNCD
+	h.Write(computeT2CRC8(h.Bytes())) // CRC-8 in normal mode
+	w := astibinary.New()
+	w.Write(uint8(7))   // Frame index
+	w.Write(uint8(1))   // PLP ID
+	w.Write("10000000") // Interleaving frame start and RFU
+	w.Write(h.Bytes())  // BBHeader
+	w.Write(df)         // Data field
+	return t2miPacketBytes(T2MIPacketTypeBBFrame, w.Bytes())
+}
+
+func t2miL1CurrentBytes() []byte {
+	w := astibinary.New()
+	w.Write(uint8(7))             // Frame index
+	w.Write(uint8(0))             // RFU
+	w.Write(uint8(0))             // Type
+	w.Write("1")                  // Bandwidth extension
+	w.Write("011")                // S1
+	w.Write("0001")               // S2
+	w.Write("0")                  // L1 repetition flag
+	w.Write("010")                // Guard interval
+	w.Write("0000")               // PAPR
+	w.Write("0011")               // L1 modulation
+	w.Write("00")                 // L1 code rate
+	w.Write("00")                 // L1 FEC type
+	w.Write("000000000101010110") // L1-post size
+	w.Write("000000000100001000") // L1-post info size
+	w.Write("0111")               // Pilot pattern
+	w.Write(uint8(0))             // TX ID availability
+	w.Write(uint16(0x1234))       // Cell ID
+	w.Write(uint16(0x3085))       // Network ID
+	w.Write(uint16(0x8001))       // T2 system ID
+	w.Write(uint8(2))             // Number of T2 frames
+	w.Write("000001000000")       // Number of data symbols
+	w.Write("000")                // Regen flag
+	w.Write("1")                  // L1-post extension
+	w.Write("001")                // Number of RF
+	w.Write("000")                // Current RF index
+	w.Write("0010")               // T2 version
+	w.Write("0")                  // L1-post scrambled
+	w.Write("0")                  // T2 base lite
+	w.Write("0000")               // Reserved
+	w.Write(uint16(12))           // L1-post configurable length
+	w.Write("101010101010")       // L1-post configurable
+	w.Write(uint16(4))            // L1-post dynamic length
+	w.Write("1100")               // L1-post dynamic
+	w.Write(uint16(0))            // L1-post extension length
+	return t2miPacketBytes(T2MIPacketTypeL1Current, w.Bytes())
+}
+
+func t2miTSPacket(b byte) []byte {
+	return append([]byte{syncByte, 0x1, 0x0, 0x10}, bytes.Repeat([]byte{b}, 184)...)
+}
+
+func TestComputeT2CRC8(t *testing.T) {
+	assert.Equal(t, uint8(0xbc), computeT2CRC8([]byte("123456789")))
+}
+
+func TestParseT2MIPackets(t *testing.T) {
+	// Init
+	var up = append([]byte{0x0}, t2miTSPacket(0xaa)[1:]...)
+	ps, err := ParseT2MIPackets(append(t2miBBFrameBytes(0, up), t2miL1CurrentBytes()...))
+	assert.NoError(t, err)
+	assert.Len(t, ps, 2)
+
+	// BBFrame
+	assert.Equal(t, uint8(1), ps[0].PacketCount)
+	assert.Equal(t, uint8(3), ps[0].StreamID)
+	assert.Equal(t, uint8(2), ps[0].SuperframeIndex)
+	assert.Equal(t, uint8(T2MIPacketTypeBBFrame), ps[0].Type)
+	assert.Equal(t, &T2MIBBFrame{
+		DataField:  up,
+		FrameIndex: 7,
+		Header: &T2BBHeader{
+			DFL:               1504,
+			PLPID:             1,
+			SingleInputStream: true,
+			StreamType:        T2BBHeaderStreamTypeTS,
+			SYNC:              syncByte,
+			UPL:               1504,
+		},
+		InterleavingFrameStart: true,
+		PLPID:                  1,
+	}, ps[0].BBFrame)
+
+	// L1-current
+	assert.Equal(t, &T2MIL1Current{
+		FrameIndex:               7,
+		L1PostConfigurable:       []byte{0xaa, 0xa0},
+		L1PostConfigurableLength: 12,
+		L1PostDynamic:            []byte{0xc0},
+		L1PostDynamicLength:      4,
+		L1PostExtension:          []byte{},
+		L1Pre: &T2L1Pre{
+			BandwidthExtension: true,
+			CellID:             0x1234,
+			GuardInterval:      2,
+			L1Modulation:       3,
+			L1PostExtension:    true,
+			L1PostInfoSize:     264,
+			L1PostSize:         342,
+			NetworkID:          0x3085,
+			NumDataSymbols:     64,
+			NumRF:              1,
+			NumT2Frames:        2,
+			PilotPattern:       7,
+			S1:                 3,
+			S2:                 1,
+			T2SystemID:         0x8001,
+			T2Version:          2,
+		},
+	}, ps[1].L1Current)
+
+	// Invalid CRC32
+	var b = t2miL1CurrentBytes()
+	b[len(b)-1]++
+	_, err = ParseT2MIPackets(b)
+	assert.Error(t, err)
+}
+
+func TestT2MITrackerPIDTSPackets(t *testing.T) {
+	// Init
+	p := &t2miTrackerPID{userPackets: make(map[uint16][]byte)}
+	var h = &T2BBHeader{HasNullPacketDeletion: true, HighEfficiencyMode: true, StreamType: T2BBHeaderStreamTypeTS}
+	var upA = append(t2miTSPacket(0xaa)[1:], 0x1)
+	var upB = append(t2miTSPacket(0xbb)[1:], 0x0)
+
+	// User packet starting in the frame
+	h.SYNCD = 0
+	assert.Equal(t, [][]byte{nullPacket, t2miTSPacket(0xaa)}, p.tsPackets(0, &T2MIBBFrame{DataField: append(upA, upB[:10]...), Header: h}))
+
+	// User packet started in the previous frame
+	h.SYNCD = 178 * 8
+	assert.Equal(t, [][]byte{t2miTSPacket(0xbb)}, p.tsPackets(0, &T2MIBBFrame{DataField: upB[10:], Header: h}))
+
+	// User packet started before the first frame received on the PLP
+	h.SYNCD = 178 * 8
+	assert.Len(t, p.tsPackets(0, &T2MIBBFrame{DataField: upB[10:], Header: h, PLPID: 1}), 0)
+
+	// No transport stream
+	assert.Len(t, p.tsPackets(0, &T2MIBBFrame{DataField: upA, Header: &T2BBHeader{StreamType: T2BBHeaderStreamTypeGSE}}), 0)
+}
+
+func TestDemuxerT2MI(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	pm, _ := (&PMTData{ElementaryStreams: []*PMTElementaryStream{{
+		ElementaryPID:               0x101,
+		ElementaryStreamDescriptors: []*Descriptor{{Extension: &DescriptorExtension{T2MI: &DescriptorExtensionT2MI{}, Tag: DescriptorTagExtensionT2MI}, Tag: DescriptorTagExtension}},
+		StreamType:                  StreamTypeMPEG2PacketizedData,
+	}}, PCRPID: 0x1fff, ProgramNumber: 1}).Serialize(0)
+	var p = append([]byte{0x0}, pm...)
+	b, _ := packet(PacketHeader{PayloadUnitStartIndicator: true, PID: 0x100}, PacketAdaptationField{}, append(p, bytes.Repeat([]byte{0xff}, 147-len(p))...))
+	w.Write(b)
+	b, _ = packet(PacketHeader{ContinuityCounter: uint8(1), PayloadUnitStartIndicator: true, PID: 0x100}, PacketAdaptationField{}, []byte{})
+	w.Write(b)
+
+	// User packet B starts in the first frame and ends in the second one
+	var upA = append([]byte{0x0}, t2miTSPacket(0xaa)[1:]...)
+	var upB = append([]byte{0x0}, t2miTSPacket(0xbb)[1:]...)
+	var s = append([]byte{0x0}, t2miBBFrameBytes(0, append(upA, upB[:50]...))...)
+	s = append(s, t2miBBFrameBytes(138*8, upB[50:])...)
+	s = append(s, bytes.Repeat([]byte{0xff}, 147-len(s)%147)...)
+	for idx := 0; idx*147 < len(s); idx++ {
+		b, _ = packet(PacketHeader{ContinuityCounter: uint8(idx), PayloadUnitStartIndicator: idx == 0, PID: 0x101}, PacketAdaptationField{}, s[idx*147:(idx+1)*147])
+		w.Write(b)
+	}
+	b, _ = packet(PacketHeader{ContinuityCounter: uint8(len(s) / 147), PayloadUnitStartIndicator: true, PID: 0x101}, PacketAdaptationField{}, []byte{0x0})
+	w.Write(b)
+	dmx := New(context.Background(), bytes.NewReader(w.Bytes()))
+	dmx.programMap.set(0x100, 1)
+
+	// PMT
+	d, err := dmx.NextData()
+	assert.NoError(t, err)
+	assert.NotNil(t, d.PMT)
+
+	// T2-MI
+	d, err = dmx.NextData()
+	assert.NoError(t, err)
+	assert.Len(t, d.T2MI.Packets, 2)
+	assert.Equal(t, [][]byte{t2miTSPacket(0xaa)}, d.T2MI.Packets[0].BBFrame.TSPackets)
+	assert.Equal(t, [][]byte{t2miTSPacket(0xbb)}, d.T2MI.Packets[1].BBFrame.TSPackets)
+}
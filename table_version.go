@@ -0,0 +1,91 @@
+package astits
+
+// TableVersionChange represents a change of the version number of a table
+// Tables are identified by their PID, table ID and table ID extension. Since tables may be split over several
+// sections, the old data is the last data received with the previous version, which may come from another section
+// than the new data
+type TableVersionChange struct {
+	New              *Data
+	NewVersion       uint8
+	Old              *Data
+	OldVersion       uint8
+	PID              uint16
+	TableID          int
+	TableIDExtension uint16
+}
+
+// TableVersionChangeHandler represents an object capable of handling table version changes
+type TableVersionChangeHandler func(c *TableVersionChange)
+
+// tableVersionKey represents the key of a table
+type tableVersionKey struct {
+	pid              uint16
+	tableID          int
+	tableIDExtension uint16
+}
+
+// tableVersion represents the last version of a table
+type tableVersion struct {
+	d       *Data
+	version uint8
+}
+
+// tableVersionTracker keeps track of the version numbers of tables
+type tableVersionTracker struct {
+	versions map[tableVersionKey]*tableVersion
+}
+
+// newTableVersionTracker creates a new table version tracker
+func newTableVersionTracker() *tableVersionTracker {
+	return &tableVersionTracker{versions: make(map[tableVersionKey]*tableVersion)}
+}
+
+// update updates the version of the table data has been parsed from and returns the change, if any
+// Only data parsed from sections with a syntax header is taken into account, and tables that are not applicable yet
+// are ignored until they are signaled as current
+func (t *tableVersionTracker) update(d *Data) (c *TableVersionChange) {
+	// Parse syntax header
+	var k, h, ok = parseTableVersionKey(d)
+	if !ok || d.IsNext {
+		return
+	}
+
+	// First version
+	v, ok := t.versions[k]
+	if !ok {
+		t.versions[k] = &tableVersion{d: d, version: h.VersionNumber}
+		return
+	}
+
+	// Version has changed
+	if v.version != h.VersionNumber {
+		c = &TableVersionChange{
+			New:              d,
+			NewVersion:       h.VersionNumber,
+			Old:              v.d,
+			OldVersion:       v.version,
+			PID:              k.pid,
+			TableID:          k.tableID,
+			TableIDExtension: k.tableIDExtension,
+		}
+	}
+	v.d = d
+	v.version = h.VersionNumber
+	return
+}
+
+// parseTableVersionKey parses the key and the syntax header of the section data has been parsed from
+func parseTableVersionKey(d *Data) (k tableVersionKey, h *PSISectionSyntaxHeader, ok bool) {
+	// Check section. MPE sections use the version number bits of their syntax header for other purposes
+	var i = d.RawSection
+	if len(i) < 3+psiSectionSyntaxHeaderLength || i[1]&0x80 == 0 || d.MPE != nil || d.MPEFEC != nil {
+		return
+	}
+
+	// Parse syntax header
+	var offset = 3
+	h = parsePSISectionSyntaxHeader(i, &offset)
+	k = tableVersionKey{pid: d.PID, tableID: int(i[0]), tableIDExtension: h.TableIDExtension}
+	ok = true
+	return
+}
@@ -0,0 +1,38 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDemuxerTableVersionChange(t *testing.T) {
+	// Init
+	buf := &bytes.Buffer{}
+	m := NewMuxer(context.Background(), buf)
+	m.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x100, StreamType: StreamTypeLowerBitrateVideo})
+	m.WriteTables()
+	m.WriteTables()
+	m.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x101, StreamType: StreamTypeMPEG1Audio})
+	m.WriteTables()
+	m.WriteTables()
+
+	// Demux
+	var cs []*TableVersionChange
+	dmx := New(context.Background(), bytes.NewReader(buf.Bytes()), OptTableVersionChangeHandler(func(c *TableVersionChange) { cs = append(cs, c) }))
+	for {
+		if _, err := dmx.NextData(); err != nil {
+			assert.Equal(t, ErrNoMorePackets, err)
+			break
+		}
+	}
+	assert.Len(t, cs, 1)
+	assert.Equal(t, uint16(0x1000), cs[0].PID)
+	assert.Equal(t, 0x2, cs[0].TableID)
+	assert.Equal(t, uint16(1), cs[0].TableIDExtension)
+	assert.Equal(t, cs[0].OldVersion+1, cs[0].NewVersion)
+	assert.Len(t, cs[0].Old.PMT.ElementaryStreams, 1)
+	assert.Len(t, cs[0].New.PMT.ElementaryStreams, 2)
+}
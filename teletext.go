@@ -0,0 +1,290 @@
+package astits
+
+import (
+	"fmt"
+)
+
+// Teletext data unit IDs
+// Page: 8 | Chapter: 4.4 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300472/01.03.01_60/en_300472v010301p.pdf
+const (
+	TeletextDataUnitIDEBUTeletextNonSubtitle = 0x02
+	TeletextDataUnitIDEBUTeletextSubtitle    = 0x03
+	TeletextDataUnitIDStuffing               = 0xff
+)
+
+// Teletext constants
+const (
+	teletextDataFieldLength        = 44
+	teletextDataIdentifierMaximum  = 0x1f
+	teletextDataIdentifierMinimum  = 0x10
+	teletextFramingCode            = 0xe4
+	teletextHeaderTextLength       = 32
+	teletextPacketNumberHeader     = 0
+	teletextPacketNumberLastRow    = 24
+	teletextUncorrectableHamming84 = 0xff
+)
+
+// teletextHamming84 contains the data nibbles of Hamming 8/4 coded bytes, once their bits are in the order of
+// transmission, or teletextUncorrectableHamming84 when they contain more than 1 bit error
+// Page: 11 | Chapter: 8.2 | Link: https://www.etsi.org/deliver/etsi_en/300700_300799/300706/01.02.01_60/en_300706v010201p.pdf
+var teletextHamming84 = newTeletextHamming84()
+
+// newTeletextHamming84 builds the Hamming 8/4 decoding table by encoding all nibbles and accepting 1 bit errors
+func newTeletextHamming84() (t [256]uint8) {
+	// Encode nibbles
+	var codes [16]uint8
+	for n := uint8(0); n < 16; n++ {
+		var d1, d2, d3, d4 = n & 0x1, n >> 1 & 0x1, n >> 2 & 0x1, n >> 3 & 0x1
+		var p1 = 1 ^ d1 ^ d3 ^ d4
+		var p2 = 1 ^ d1 ^ d2 ^ d4
+		var p3 = 1 ^ d1 ^ d2 ^ d3
+		var p4 = 1 ^ p1 ^ d1 ^ p2 ^ d2 ^ p3 ^ d3 ^ d4
+		codes[n] = p1 | d1<<1 | p2<<2 | d2<<3 | p3<<4 | d3<<5 | p4<<6 | d4<<7
+	}
+
+	// Loop through bytes
+	for b := 0; b < 256; b++ {
+		t[b] = teletextUncorrectableHamming84
+		for n, c := range codes {
+			if d := uint8(b) ^ c; d&(d-1) == 0 {
+				t[b] = uint8(n)
+				break
+			}
+		}
+	}
+	return
+}
+
+// TeletextData represents the content of an EBU teletext PES, i.e. its teletext packets
+// Page: 6 | Chapter: 4 | Link: https://www.etsi.org/deliver/etsi_en/300400_300499/300472/01.03.01_60/en_300472v010301p.pdf
+type TeletextData struct {
+	DataIdentifier uint8
+	Packets        []*TeletextPacket
+}
+
+// TeletextPacket represents a teletext packet carried in a data unit
+// Page: 14 | Chapter: 7.1 | Link: https://www.etsi.org/deliver/etsi_en/300700_300799/300706/01.02.01_60/en_300706v010201p.pdf
+type TeletextPacket struct {
+	Data         []byte // Data block, bits in the order of transmission so that bytes can be decoded directly
+	DataUnitID   uint8
+	FieldParity  bool
+	Header       *TeletextPageHeader // Only set for packets 0
+	LineOffset   uint8
+	Magazine     uint8 // 0 means magazine 8
+	PacketNumber uint8
+	Text         string // Only set for display rows, i.e. packets 1 to 24
+}
+
+// TeletextPageHeader represents the header of a teletext page, carried in packets 0
+// Page: 27 | Chapter: 9.3.1 | Link: https://www.etsi.org/deliver/etsi_en/300700_300799/300706/01.02.01_60/en_300706v010201p.pdf
+type TeletextPageHeader struct {
+	ErasePage                     bool
+	InhibitDisplay                bool
+	InterruptedSequence           bool
+	MagazineSerial                bool // When set, a header ends the page in progress of all magazines
+	NationalOptionCharacterSubset uint8
+	Newsflash                     bool
+	Page                          uint8 // Tens and units converted the same way as in teletext descriptors
+	Subcode                       uint16
+	Subtitle                      bool
+	SuppressHeader                bool
+	Text                          string
+	UpdateIndicator               bool
+}
+
+// ParseTeletext parses the payload of an EBU teletext PES, carried on an elementary stream of the
+// StreamTypeMPEG2PacketizedData stream type described by a teletext descriptor, into teletext packets
+// Stuffing data units and packets whose address can't be corrected are skipped
+func ParseTeletext(i []byte) (d *TeletextData, err error) {
+	// Check data identifier
+	if len(i) < 1 {
+		err = fmt.Errorf("astits: teletext data identifier end (1) > len(i) (%d)", len(i))
+		return
+	} else if i[0] < teletextDataIdentifierMinimum || i[0] > teletextDataIdentifierMaximum {
+		err = fmt.Errorf("astits: invalid teletext data identifier 0x%x", i[0])
+		return
+	}
+
+	// Init
+	d = &TeletextData{DataIdentifier: i[0]}
+	var offset = 1
+
+	// Loop through data units
+	for offset+2 <= len(i) {
+		// Header
+		var id, length = i[offset], int(i[offset+1])
+		offset += 2
+
+		// Check data unit end
+		if offset+length > len(i) {
+			err = fmt.Errorf("astits: teletext data unit end (%d) > len(i) (%d)", offset+length, len(i))
+			return
+		}
+		var b = i[offset : offset+length]
+		offset += length
+
+		// Only teletext data units are parsed
+		if (id != TeletextDataUnitIDEBUTeletextNonSubtitle && id != TeletextDataUnitIDEBUTeletextSubtitle) ||
+			length != teletextDataFieldLength || b[1] != teletextFramingCode {
+			continue
+		}
+
+		// Parse packet
+		if p := parseTeletextPacket(id, b); p != nil {
+			d.Packets = append(d.Packets, p)
+		}
+	}
+	return
+}
+
+// parseTeletextPacket parses the data field of a teletext data unit
+func parseTeletextPacket(id uint8, i []byte) (p *TeletextPacket) {
+	// Reverse bits
+	var b = make([]byte, len(i)-2)
+	for idx := range b {
+		b[idx] = reverseBits(i[idx+2])
+	}
+
+	// Magazine and packet address
+	var n1, n2 = teletextHamming84[b[0]], teletextHamming84[b[1]]
+	if n1 == teletextUncorrectableHamming84 || n2 == teletextUncorrectableHamming84 {
+		return
+	}
+
+	// Init
+	p = &TeletextPacket{
+		Data:         b[2:],
+		DataUnitID:   id,
+		FieldParity:  i[0]&0x20 > 0,
+		LineOffset:   i[0] & 0x1f,
+		Magazine:     n1 & 0x7,
+		PacketNumber: n1>>3 | n2<<1,
+	}
+
+	// Switch on packet number
+	if p.PacketNumber == teletextPacketNumberHeader {
+		p.Header = parseTeletextPageHeader(p.Data)
+	} else if p.PacketNumber <= teletextPacketNumberLastRow {
+		p.Text = decodeTeletextText(p.Data)
+	}
+	return
+}
+
+// parseTeletextPageHeader parses the data block of a page header
+func parseTeletextPageHeader(i []byte) *TeletextPageHeader {
+	// Decode nibbles
+	var ns [8]uint8
+	for idx := range ns {
+		// Bits that can't be corrected are considered unset
+		if ns[idx] = teletextHamming84[i[idx]]; ns[idx] == teletextUncorrectableHamming84 {
+			ns[idx] = 0
+		}
+	}
+
+	// Create header
+	return &TeletextPageHeader{
+		ErasePage:                     ns[3]&0x8 > 0,
+		InhibitDisplay:                ns[6]&0x8 > 0,
+		InterruptedSequence:           ns[6]&0x4 > 0,
+		MagazineSerial:                ns[7]&0x1 > 0,
+		NationalOptionCharacterSubset: ns[7] >> 1,
+		Newsflash:                     ns[5]&0x4 > 0,
+		Page:                          ns[1]*10 + ns[0],
+		Subcode:                       uint16(ns[2]) | uint16(ns[3]&0x7)<<4 | uint16(ns[4])<<7 | uint16(ns[5]&0x3)<<11,
+		Subtitle:                      ns[5]&0x8 > 0,
+		SuppressHeader:                ns[6]&0x1 > 0,
+		Text:                          decodeTeletextText(i[8 : 8+teletextHeaderTextLength]),
+		UpdateIndicator:               ns[6]&0x2 > 0,
+	}
+}
+
+// decodeTeletextText decodes odd parity characters of the G0 latin set
+// Control codes, which are displayed as spaces, and characters with a parity error are replaced with spaces
+func decodeTeletextText(i []byte) string {
+	var rs = make([]rune, len(i))
+	for idx, c := range i {
+		if !hasOddParity(c) || c&0x7f < 0x20 || c&0x7f == 0x7f {
+			rs[idx] = ' '
+			continue
+		}
+		rs[idx] = rune(c & 0x7f)
+	}
+	return string(rs)
+}
+
+// hasOddParity checks whether a byte has an odd number of bits set
+func hasOddParity(b uint8) bool {
+	b ^= b >> 4
+	b ^= b >> 2
+	b ^= b >> 1
+	return b&0x1 > 0
+}
+
+// reverseBits reverses the order of the bits of a byte
+func reverseBits(b uint8) (o uint8) {
+	for idx := 0; idx < 8; idx++ {
+		o = o<<1 | b&0x1
+		b >>= 1
+	}
+	return
+}
+
+// TeletextPage represents a teletext page assembled from its header and its display rows
+type TeletextPage struct {
+	Header   *TeletextPageHeader
+	Magazine uint8    // 0 means magazine 8
+	Rows     []string // Indexed by packet number, the header text being at index 0. Rows that were not received are empty
+}
+
+// TeletextPageDecoder represents an object capable of assembling teletext pages, for instance to extract the teletext
+// subtitle page announced in a teletext descriptor
+// A page is complete once the next header of its magazine is received, or the next header of any magazine when
+// magazines are transmitted serially.
+type TeletextPageDecoder struct {
+	pages map[uint8]*TeletextPage // Pages in progress indexed by magazine
+}
+
+// NewTeletextPageDecoder creates a new teletext page decoder
+func NewTeletextPageDecoder() *TeletextPageDecoder {
+	return &TeletextPageDecoder{pages: make(map[uint8]*TeletextPage)}
+}
+
+// Add adds a teletext packet and returns the pages it completes
+func (d *TeletextPageDecoder) Add(p *TeletextPacket) (ps []*TeletextPage) {
+	// Display row
+	if p.Header == nil {
+		if pg, ok := d.pages[p.Magazine]; ok && p.PacketNumber <= teletextPacketNumberLastRow {
+			pg.Rows[p.PacketNumber] = p.Text
+		}
+		return
+	}
+
+	// Complete pages
+	for m := uint8(0); m < 8; m++ {
+		if pg, ok := d.pages[m]; ok && (m == p.Magazine || p.Header.MagazineSerial) {
+			ps = append(ps, pg)
+			delete(d.pages, m)
+		}
+	}
+
+	// Start page
+	var pg = &TeletextPage{
+		Header:   p.Header,
+		Magazine: p.Magazine,
+		Rows:     make([]string, teletextPacketNumberLastRow+1),
+	}
+	pg.Rows[0] = p.Header.Text
+	d.pages[p.Magazine] = pg
+	return
+}
+
+// Flush returns the pages in progress, sorted by magazine, and resets the decoder
+func (d *TeletextPageDecoder) Flush() (ps []*TeletextPage) {
+	for m := uint8(0); m < 8; m++ {
+		if pg, ok := d.pages[m]; ok {
+			ps = append(ps, pg)
+		}
+	}
+	d.pages = make(map[uint8]*TeletextPage)
+	return
+}
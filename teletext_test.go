@@ -0,0 +1,141 @@
+package astits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var teletextHamming84Codes = []uint8{0x15, 0x02, 0x49, 0x5e, 0x64, 0x73, 0x38, 0x2f, 0xd0, 0xc7, 0x8c, 0x9b, 0xa1, 0xb6, 0xfd, 0xea}
+
+func teletextOddParity(c uint8) uint8 {
+	if !hasOddParity(c) {
+		c |= 0x80
+	}
+	return c
+}
+
+func teletextDataUnitBytes(id, magazine, packetNumber uint8, block []byte) []byte {
+	var b = []byte{id, teletextDataFieldLength, 0xe2, teletextFramingCode,
+		reverseBits(teletextHamming84Codes[magazine|packetNumber&0x1<<3]),
+		reverseBits(teletextHamming84Codes[packetNumber>>1])}
+	for _, c := range block {
+		b = append(b, reverseBits(c))
+	}
+	return b
+}
+
+func teletextText(s string, l int) (b []byte) {
+	for idx := 0; idx < l; idx++ {
+		var c = uint8(' ')
+		if idx < len(s) {
+			c = s[idx]
+		}
+		b = append(b, teletextOddParity(c))
+	}
+	return
+}
+
+func teletextHeaderBlock(page, subcode uint16, controls []uint8, text string) []byte {
+	var b = []byte{
+		teletextHamming84Codes[page&0xf],
+		teletextHamming84Codes[page>>4&0xf],
+		teletextHamming84Codes[subcode&0xf],
+		teletextHamming84Codes[uint8(subcode>>4&0x7)|controls[0]],
+		teletextHamming84Codes[subcode>>7&0xf],
+		teletextHamming84Codes[uint8(subcode>>11&0x3)|controls[1]],
+		teletextHamming84Codes[controls[2]],
+		teletextHamming84Codes[controls[3]],
+	}
+	return append(b, teletextText(text, teletextHeaderTextLength)...)
+}
+
+func TestTeletextHamming84(t *testing.T) {
+	for n, c := range teletextHamming84Codes {
+		assert.Equal(t, uint8(n), teletextHamming84[c])
+		assert.Equal(t, uint8(n), teletextHamming84[c^0x10])
+		assert.Equal(t, uint8(teletextUncorrectableHamming84), teletextHamming84[c^0x11])
+	}
+}
+
+func TestParseTeletext(t *testing.T) {
+	// Init
+	var b = []byte{teletextDataIdentifierMinimum}
+	b = append(b, teletextDataUnitBytes(TeletextDataUnitIDEBUTeletextSubtitle, 0, 0, teletextHeaderBlock(0x88, 0x1803, []uint8{0x8, 0x8, 0x2, 0x1}, "Header"))...)
+	b = append(b, TeletextDataUnitIDStuffing, 0x2, 0xff, 0xff)
+	b = append(b, teletextDataUnitBytes(TeletextDataUnitIDEBUTeletextSubtitle, 0, 22, append([]byte{0x0b, 0x0b}, teletextText("Hello", 38)...))...)
+	var u = teletextDataUnitBytes(TeletextDataUnitIDEBUTeletextSubtitle, 0, 23, teletextText("", 40))
+	u[4] ^= 0x3
+	b = append(b, u...)
+
+	// Parse
+	d, err := ParseTeletext(b)
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(teletextDataIdentifierMinimum), d.DataIdentifier)
+	assert.Len(t, d.Packets, 2)
+	assert.Equal(t, &TeletextPageHeader{
+		ErasePage:       true,
+		MagazineSerial:  true,
+		Page:            88,
+		Subcode:         0x1803,
+		Subtitle:        true,
+		Text:            "Header                          ",
+		UpdateIndicator: true,
+	}, d.Packets[0].Header)
+	assert.Equal(t, uint8(TeletextDataUnitIDEBUTeletextSubtitle), d.Packets[0].DataUnitID)
+	assert.True(t, d.Packets[0].FieldParity)
+	assert.Equal(t, uint8(2), d.Packets[0].LineOffset)
+	assert.Equal(t, uint8(0), d.Packets[0].Magazine)
+	assert.Equal(t, uint8(22), d.Packets[1].PacketNumber)
+	assert.Equal(t, "  Hello", d.Packets[1].Text[:7])
+	assert.Len(t, d.Packets[1].Text, 40)
+
+	// Errors
+	_, err = ParseTeletext([]byte{})
+	assert.Error(t, err)
+	_, err = ParseTeletext([]byte{0x20})
+	assert.Error(t, err)
+	_, err = ParseTeletext([]byte{teletextDataIdentifierMinimum, TeletextDataUnitIDEBUTeletextSubtitle, teletextDataFieldLength, 0x0})
+	assert.Error(t, err)
+}
+
+func TestDecodeTeletextText(t *testing.T) {
+	assert.Equal(t, " A B ", decodeTeletextText([]byte{teletextOddParity(0x0d), teletextOddParity('A'), 'B', teletextOddParity('B'), teletextOddParity(0x7f)}))
+}
+
+func TestTeletextPageDecoder(t *testing.T) {
+	// Init
+	var h = func(magazine uint8, serial bool) *TeletextPacket {
+		return &TeletextPacket{Header: &TeletextPageHeader{MagazineSerial: serial, Text: "header"}, Magazine: magazine}
+	}
+	var r = func(magazine, packetNumber uint8, text string) *TeletextPacket {
+		return &TeletextPacket{Magazine: magazine, PacketNumber: packetNumber, Text: text}
+	}
+	d := NewTeletextPageDecoder()
+
+	// Parallel magazines
+	assert.Empty(t, d.Add(r(1, 20, "ignored")))
+	assert.Empty(t, d.Add(h(1, false)))
+	assert.Empty(t, d.Add(h(2, false)))
+	assert.Empty(t, d.Add(r(1, 20, "row 20")))
+	assert.Empty(t, d.Add(r(2, 21, "row 21")))
+	ps := d.Add(h(1, false))
+	assert.Len(t, ps, 1)
+	assert.Equal(t, uint8(1), ps[0].Magazine)
+	assert.Equal(t, "header", ps[0].Rows[0])
+	assert.Equal(t, "row 20", ps[0].Rows[20])
+	assert.Equal(t, "", ps[0].Rows[21])
+
+	// Serial magazines
+	ps = d.Add(h(3, true))
+	assert.Len(t, ps, 2)
+	assert.Equal(t, uint8(1), ps[0].Magazine)
+	assert.Equal(t, uint8(2), ps[1].Magazine)
+	assert.Equal(t, "row 21", ps[1].Rows[21])
+
+	// Flush
+	ps = d.Flush()
+	assert.Len(t, ps, 1)
+	assert.Equal(t, uint8(3), ps[0].Magazine)
+	assert.Empty(t, d.Flush())
+}
@@ -0,0 +1,141 @@
+package astits
+
+import "github.com/pkg/errors"
+
+// TextDecoder represents an object capable of decoding the text fields of descriptors into UTF-8 strings
+// Raw bytes are always kept in the descriptors, decoded strings are stored in their Decoded fields
+type TextDecoder interface {
+	DecodeText(i []byte) (string, error)
+}
+
+// dataDescriptors returns the descriptors loops of the tables of a data that may contain text fields
+func dataDescriptors(d *Data) (o [][]*Descriptor) {
+	switch {
+	case d.BAT != nil:
+		o = append(o, d.BAT.BouquetDescriptors)
+		for _, ts := range d.BAT.TransportStreams {
+			o = append(o, ts.TransportDescriptors)
+		}
+	case d.BIT != nil:
+		o = append(o, d.BIT.Descriptors)
+		for _, b := range d.BIT.Broadcasters {
+			o = append(o, b.Descriptors)
+		}
+	case d.EIT != nil:
+		for _, e := range d.EIT.Events {
+			o = append(o, e.Descriptors)
+		}
+	case d.LDT != nil:
+		for _, ds := range d.LDT.Descriptions {
+			o = append(o, ds.Descriptors)
+		}
+	case d.NBIT != nil:
+		for _, n := range d.NBIT.Informations {
+			o = append(o, n.Descriptors)
+		}
+	case d.NIT != nil:
+		o = append(o, d.NIT.NetworkDescriptors)
+		for _, ts := range d.NIT.TransportStreams {
+			o = append(o, ts.TransportDescriptors)
+		}
+	case d.SDT != nil:
+		for _, s := range d.SDT.Services {
+			o = append(o, s.Descriptors)
+		}
+	case d.SIT != nil:
+		o = append(o, d.SIT.TransmissionDescriptors)
+		for _, s := range d.SIT.Services {
+			o = append(o, s.Descriptors)
+		}
+	}
+	return
+}
+
+// decodeDataTexts decodes the text fields of the descriptors of a data
+func decodeDataTexts(d *Data, dec TextDecoder) (err error) {
+	for _, ds := range dataDescriptors(d) {
+		for _, dsc := range ds {
+			if err = decodeDescriptorTexts(dsc, dec); err != nil {
+				err = errors.Wrapf(err, "astits: decoding texts of descriptor with tag 0x%x failed", dsc.Tag)
+				return
+			}
+		}
+	}
+	return
+}
+
+// decodeDescriptorTexts decodes the text fields of a descriptor
+func decodeDescriptorTexts(d *Descriptor, dec TextDecoder) (err error) {
+	switch {
+	case d.Component != nil:
+		if d.Component.DecodedText, err = dec.DecodeText(d.Component.Text); err != nil {
+			return
+		}
+	case d.ExtendedEvent != nil:
+		if d.ExtendedEvent.DecodedText, err = dec.DecodeText(d.ExtendedEvent.Text); err != nil {
+			return
+		}
+		for _, item := range d.ExtendedEvent.Items {
+			if item.DecodedContent, err = dec.DecodeText(item.Content); err != nil {
+				return
+			}
+			if item.DecodedDescription, err = dec.DecodeText(item.Description); err != nil {
+				return
+			}
+		}
+	case d.LogicalChannelV2 != nil:
+		for _, l := range d.LogicalChannelV2.ChannelLists {
+			if l.DecodedChannelListName, err = dec.DecodeText(l.ChannelListName); err != nil {
+				return
+			}
+		}
+	case d.MultilingualBouquetName != nil:
+		err = decodeMultilingualNameTexts(d.MultilingualBouquetName, dec)
+	case d.MultilingualComponent != nil:
+		for _, itm := range d.MultilingualComponent.Items {
+			if itm.DecodedText, err = dec.DecodeText(itm.Text); err != nil {
+				return
+			}
+		}
+	case d.MultilingualNetworkName != nil:
+		err = decodeMultilingualNameTexts(d.MultilingualNetworkName, dec)
+	case d.MultilingualServiceName != nil:
+		for _, itm := range d.MultilingualServiceName.Items {
+			if itm.DecodedName, err = dec.DecodeText(itm.Name); err != nil {
+				return
+			}
+			if itm.DecodedProvider, err = dec.DecodeText(itm.Provider); err != nil {
+				return
+			}
+		}
+	case d.NetworkName != nil:
+		if d.NetworkName.DecodedName, err = dec.DecodeText(d.NetworkName.Name); err != nil {
+			return
+		}
+	case d.Service != nil:
+		if d.Service.DecodedName, err = dec.DecodeText(d.Service.Name); err != nil {
+			return
+		}
+		if d.Service.DecodedProvider, err = dec.DecodeText(d.Service.Provider); err != nil {
+			return
+		}
+	case d.ShortEvent != nil:
+		if d.ShortEvent.DecodedEventName, err = dec.DecodeText(d.ShortEvent.EventName); err != nil {
+			return
+		}
+		if d.ShortEvent.DecodedText, err = dec.DecodeText(d.ShortEvent.Text); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// decodeMultilingualNameTexts decodes the names of a multilingual name descriptor
+func decodeMultilingualNameTexts(d *DescriptorMultilingualName, dec TextDecoder) (err error) {
+	for _, itm := range d.Items {
+		if itm.DecodedName, err = dec.DecodeText(itm.Name); err != nil {
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,48 @@
+package astits
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockTextDecoder struct{}
+
+func (d mockTextDecoder) DecodeText(i []byte) (string, error) {
+	if bytes.Equal(i, []byte("error")) {
+		return "", errors.New("error")
+	}
+	return string(bytes.ToUpper(i)), nil
+}
+
+func TestDecodeDataTexts(t *testing.T) {
+	// Success
+	d := &Data{SDT: &SDTData{Services: []*SDTDataService{{Descriptors: []*Descriptor{
+		{Service: &DescriptorService{Name: []byte("name"), Provider: []byte("provider")}},
+		{ShortEvent: &DescriptorShortEvent{EventName: []byte("event"), Text: []byte("text")}},
+	}}}}}
+	err := decodeDataTexts(d, mockTextDecoder{})
+	assert.NoError(t, err)
+	assert.Equal(t, "NAME", d.SDT.Services[0].Descriptors[0].Service.DecodedName)
+	assert.Equal(t, "PROVIDER", d.SDT.Services[0].Descriptors[0].Service.DecodedProvider)
+	assert.Equal(t, "EVENT", d.SDT.Services[0].Descriptors[1].ShortEvent.DecodedEventName)
+	assert.Equal(t, "TEXT", d.SDT.Services[0].Descriptors[1].ShortEvent.DecodedText)
+
+	// Extended event
+	d = &Data{EIT: &EITData{Events: []*EITDataEvent{{Descriptors: []*Descriptor{{ExtendedEvent: &DescriptorExtendedEvent{
+		Items: []*DescriptorExtendedEventItem{{Content: []byte("content"), Description: []byte("description")}},
+		Text:  []byte("text"),
+	}}}}}}}
+	err = decodeDataTexts(d, mockTextDecoder{})
+	assert.NoError(t, err)
+	assert.Equal(t, "TEXT", d.EIT.Events[0].Descriptors[0].ExtendedEvent.DecodedText)
+	assert.Equal(t, "CONTENT", d.EIT.Events[0].Descriptors[0].ExtendedEvent.Items[0].DecodedContent)
+	assert.Equal(t, "DESCRIPTION", d.EIT.Events[0].Descriptors[0].ExtendedEvent.Items[0].DecodedDescription)
+
+	// Error
+	d = &Data{NIT: &NITData{NetworkDescriptors: []*Descriptor{{NetworkName: &DescriptorNetworkName{Name: []byte("error")}, Tag: DescriptorTagNetworkName}}}}
+	err = decodeDataTexts(d, mockTextDecoder{})
+	assert.EqualError(t, err, "astits: decoding texts of descriptor with tag 0x40 failed: error")
+}
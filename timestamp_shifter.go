@@ -0,0 +1,75 @@
+package astits
+
+import "time"
+
+// TimestampOffset represents an object returning the offset to apply to a clock reference
+type TimestampOffset func(c ClockReference) time.Duration
+
+// ConstantTimestampOffset returns a timestamp offset always returning the same duration
+func ConstantTimestampOffset(d time.Duration) TimestampOffset {
+	return func(ClockReference) time.Duration { return d }
+}
+
+// RampedTimestampOffset returns a timestamp offset going linearly from one duration to another between two clock
+// references, and constant before and after them
+// Clock references are compared as is, therefore they should be unwrapped first if the ramp spans a wrap
+func RampedTimestampOffset(from, to time.Duration, start, end ClockReference) TimestampOffset {
+	return func(c ClockReference) time.Duration {
+		if !c.After(start) {
+			return from
+		} else if !c.Before(end) {
+			return to
+		}
+		var r = float64(c.Ticks27MHz()-start.Ticks27MHz()) / float64(end.Ticks27MHz()-start.Ticks27MHz())
+		return from + time.Duration(float64(to-from)*r)
+	}
+}
+
+// TimestampShifter represents an object capable of shifting the PCR, PTS and DTS of the packets of selected PIDs, for
+// instance to align timelines when concatenating recordings
+// Shifted clocks are wrapped at 33 bits
+type TimestampShifter struct {
+	offset TimestampOffset
+	pids   map[uint16]bool
+}
+
+// NewTimestampShifter creates a new timestamp shifter for a set of PIDs
+// When no PIDs are provided, packets of all PIDs are shifted
+func NewTimestampShifter(offset TimestampOffset, pids ...uint16) (s *TimestampShifter) {
+	s = &TimestampShifter{offset: offset}
+	if len(pids) > 0 {
+		s.pids = make(map[uint16]bool)
+		for _, pid := range pids {
+			s.pids[pid] = true
+		}
+	}
+	return
+}
+
+// Shift shifts a packet in place, its raw bytes included so that they can be forwarded as is
+// The PTS and DTS of a PES are only shifted if its header is in the packet starting it
+func (s *TimestampShifter) Shift(p *Packet) {
+	// Check PID
+	if s.pids != nil && !s.pids[p.Header.PID] {
+		return
+	}
+
+	// Shift PCR
+	if p.AdaptationField != nil && p.AdaptationField.HasPCR && p.AdaptationField.PCR != nil {
+		// The adaptation field may be shared with other packets
+		var a = *p.AdaptationField
+		a.PCR = a.PCR.Add(s.offset(*a.PCR))
+		a.PCR.Base = wrapClockReferenceBase(a.PCR.Base)
+		p.AdaptationField = &a
+
+		// Rewrite raw bytes, whose PCR follows the adaptation field length and flags
+		if so := packetSyncOffset(len(p.Bytes)); len(p.Bytes) >= so+12 && p.Bytes[so+3]&0x20 > 0 && p.Bytes[so+4] > 0 && p.Bytes[so+5]&0x10 > 0 {
+			copy(p.Bytes[so+6:], writePCR(a.PCR))
+		}
+	}
+
+	// Shift timestamps
+	if p.Header.PayloadUnitStartIndicator {
+		shiftPESPayloadTimestamps(p.Payload, func(c ClockReference) int { return durationTo27MHzTicks(s.offset(c)) / 300 })
+	}
+}
@@ -0,0 +1,81 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRampedTimestampOffset(t *testing.T) {
+	o := RampedTimestampOffset(time.Second, 2*time.Second, ClockReference{Base: 90000}, ClockReference{Base: 180000})
+	assert.Equal(t, time.Second, o(ClockReference{Base: 0}))
+	assert.Equal(t, time.Second, o(ClockReference{Base: 90000}))
+	assert.Equal(t, 1500*time.Millisecond, o(ClockReference{Base: 135000}))
+	assert.Equal(t, 2*time.Second, o(ClockReference{Base: 180000}))
+	assert.Equal(t, 2*time.Second, o(ClockReference{Base: 270000}))
+}
+
+func TestTimestampShifter(t *testing.T) {
+	// Init
+	s := NewTimestampShifter(ConstantTimestampOffset(-time.Second), 0x100)
+	pes := &PESData{Data: []byte("data"), Header: &PESHeader{OptionalHeader: &PESOptionalHeader{PTS: &ClockReference{Base: 100000}}, StreamID: 0xe0}}
+	a := &PacketAdaptationField{HasPCR: true, PCR: &ClockReference{Base: 100, Extension: 2}}
+	ps := []*Packet{
+		{AdaptationField: a, Header: &PacketHeader{PayloadUnitStartIndicator: true, PID: 0x100}, Payload: pes.Serialize()},
+		{AdaptationField: a, Header: &PacketHeader{PayloadUnitStartIndicator: true, PID: 0x101}, Payload: pes.Serialize()},
+	}
+	for _, p := range ps {
+		s.Shift(p)
+	}
+
+	// Selected PID
+	assert.Equal(t, &ClockReference{Base: clockReferenceBaseWrap - 89900, Extension: 2}, ps[0].AdaptationField.PCR)
+	o, err := parsePESData(ps[0].Payload)
+	assert.NoError(t, err)
+	assert.Equal(t, 10000, o.Header.OptionalHeader.PTS.Base)
+
+	// Other PID
+	assert.Equal(t, a, ps[1].AdaptationField)
+	assert.Equal(t, &ClockReference{Base: 100, Extension: 2}, a.PCR)
+	o, err = parsePESData(ps[1].Payload)
+	assert.NoError(t, err)
+	assert.Equal(t, 100000, o.Header.OptionalHeader.PTS.Base)
+
+	// Raw bytes
+	p, err := parsePacket(writePacket(&Packet{
+		AdaptationField: &PacketAdaptationField{HasPCR: true, PCR: &ClockReference{Base: 100000}},
+		Header:          &PacketHeader{HasAdaptationField: true, HasPayload: true, PayloadUnitStartIndicator: true, PID: 0x100},
+		Payload:         pes.Serialize(),
+	}))
+	assert.NoError(t, err)
+	s.Shift(p)
+	p, err = parsePacket(p.Bytes)
+	assert.NoError(t, err)
+	assert.Equal(t, &ClockReference{Base: 10000}, p.AdaptationField.PCR)
+	o, err = parsePESData(p.Payload)
+	assert.NoError(t, err)
+	assert.Equal(t, 10000, o.Header.OptionalHeader.PTS.Base)
+}
+
+func TestMuxerOptTimestampShifter(t *testing.T) {
+	// Init
+	buf := &bytes.Buffer{}
+	m := NewMuxer(context.Background(), buf, MuxerOptTimestampShifter(NewTimestampShifter(ConstantTimestampOffset(time.Second))))
+	m.AddElementaryStream(PMTElementaryStream{ElementaryPID: 0x100, StreamType: StreamTypeLowerBitrateVideo})
+	m.WriteData(&MuxerData{
+		AdaptationField: &PacketAdaptationField{HasPCR: true, PCR: &ClockReference{Base: 5}},
+		PES:             &PESData{Data: []byte("data"), Header: &PESHeader{OptionalHeader: &PESOptionalHeader{PTS: &ClockReference{Base: 10}}, StreamID: 0xe0}},
+		PID:             0x100,
+	})
+
+	// Parse
+	p, err := parsePacket(buf.Bytes()[2*MpegTsPacketSize:])
+	assert.NoError(t, err)
+	assert.Equal(t, &ClockReference{Base: 90005}, p.AdaptationField.PCR)
+	o, err := parsePESData(p.Payload)
+	assert.NoError(t, err)
+	assert.Equal(t, 90010, o.Header.OptionalHeader.PTS.Base)
+}
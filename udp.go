@@ -0,0 +1,351 @@
+package astits
+
+import (
+	"io"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// UDP constants
+const (
+	rtpHeaderLength          = 12
+	rtpPayloadTypeMP2T       = 33
+	rtpVersion               = 2
+	udpMaximumDatagramLength = 65535
+	udpPacketsPerDatagram    = 7
+	udpReadBufferSize        = 4 << 20
+)
+
+// UDPReader represents a reader of the transport stream received on a UDP socket, such as a multicast IPTV channel,
+// that can be given to the demuxer
+// Datagrams carry either raw 188 bytes packets or RTP packets whose payload is made of 188 bytes packets, which is
+// detected on every datagram. RTP headers are stripped and their sequence numbers are tracked to count lost datagrams.
+// Duplicated and late RTP packets are dropped
+// Page: 12 | Chapter: 5.1 | Link: https://tools.ietf.org/html/rfc3550
+type UDPReader struct {
+	buf               []byte // Packets of the last datagram that have not been read yet
+	c                 net.PacketConn
+	datagram          []byte
+	hasSequenceNumber bool
+	isRTP             bool
+	lostRTPPackets    int64
+	sequenceNumber    uint16 // Sequence number of the last RTP packet
+}
+
+// NewUDPReader creates a new UDP reader reading datagrams from a packet connection
+func NewUDPReader(c net.PacketConn) *UDPReader {
+	return &UDPReader{
+		c:        c,
+		datagram: make([]byte, udpMaximumDatagramLength),
+	}
+}
+
+// ListenUDP listens on an address such as "239.0.0.1:1234" and returns the reader of the datagrams received on it
+// Multicast groups are joined on the interface, or on the default one if it's nil
+// Closing the reader makes blocked reads return, which is how the demuxer reading from it is stopped
+func ListenUDP(address string, ifi *net.Interface) (r *UDPReader, err error) {
+	// Resolve address
+	var a *net.UDPAddr
+	if a, err = net.ResolveUDPAddr("udp", address); err != nil {
+		err = errors.Wrapf(err, "astits: resolving UDP address %s failed", address)
+		return
+	}
+
+	// Listen
+	var c *net.UDPConn
+	if a.IP != nil && a.IP.IsMulticast() {
+		c, err = net.ListenMulticastUDP("udp", ifi, a)
+	} else {
+		c, err = net.ListenUDP("udp", a)
+	}
+	if err != nil {
+		err = errors.Wrapf(err, "astits: listening on UDP address %s failed", address)
+		return
+	}
+
+	// Live streams come in bursts that would overflow the default socket buffer. Failing to enlarge it is not fatal
+	c.SetReadBuffer(udpReadBufferSize)
+	r = NewUDPReader(c)
+	return
+}
+
+// Close closes the underlying connection
+func (r *UDPReader) Close() error {
+	return r.c.Close()
+}
+
+// IsRTP checks whether the last datagram was an RTP packet
+func (r *UDPReader) IsRTP() bool {
+	return r.isRTP
+}
+
+// LostRTPPackets returns the number of RTP packets that were lost, based on the gaps in the sequence numbers
+func (r *UDPReader) LostRTPPackets() int64 {
+	return r.lostRTPPackets
+}
+
+// Read implements the io.Reader interface and returns the packets carried by the datagrams
+func (r *UDPReader) Read(p []byte) (n int, err error) {
+	// Read next datagram
+	for len(r.buf) == 0 {
+		var l int
+		if l, _, err = r.c.ReadFrom(r.datagram); err != nil {
+			return
+		}
+		r.buf = r.payload(r.datagram[:l])
+	}
+
+	// Copy
+	n = copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return
+}
+
+// payload returns the packets carried by a datagram, its RTP header being stripped if any
+func (r *UDPReader) payload(b []byte) []byte {
+	// Raw packets start with a sync byte, whose 2 most significant bits can't be mistaken for the RTP version
+	if r.isRTP = len(b) >= rtpHeaderLength && b[0]>>6 == rtpVersion; !r.isRTP {
+		return b
+	}
+
+	// Skip CSRC identifiers and header extension
+	var offset = rtpHeaderLength + 4*int(b[0]&0xf)
+	if b[0]&0x10 > 0 && offset+4 <= len(b) {
+		offset += 4 + 4*int(uint16(b[offset+2])<<8|uint16(b[offset+3]))
+	}
+
+	// Remove padding
+	var end = len(b)
+	if b[0]&0x20 > 0 {
+		end -= int(b[len(b)-1])
+	}
+	if offset > end {
+		return nil
+	}
+
+	// Check sequence number
+	var sequenceNumber = uint16(b[2])<<8 | uint16(b[3])
+	if r.hasSequenceNumber {
+		// Gaps are only counted for packets ahead of the last one, others are duplicated or late
+		if d := sequenceNumber - r.sequenceNumber - 1; d < 0x8000 {
+			r.lostRTPPackets += int64(d)
+		} else {
+			return nil
+		}
+	}
+	r.hasSequenceNumber = true
+	r.sequenceNumber = sequenceNumber
+	return b[offset:end]
+}
+
+// UDPWriter represents a writer sending 188 bytes packets over UDP, 7 packets per datagram, either as raw datagrams or
+// as RTP packets of the MP2T payload type
+// RTP timestamps are the 90 kHz clock of the PCRs of the reference PID, which is the first PID carrying PCRs unless
+// set with UDPWriterOptPCRPID. When pacing is enabled, packets written between 2 PCRs are spread evenly over the time
+// separating them and each datagram is sent when its first packet is due, instead of as fast as packets are written.
+// Since packets can't be scheduled before the next PCR is known, pacing delays the output by the PCR interval
+// Page: 6 | Chapter: 2 | Link: https://tools.ietf.org/html/rfc2250
+type UDPWriter struct {
+	buf             []byte
+	datagram        []byte
+	datagramPackets int
+	datagramTicks   int // 27 MHz ticks at which the first packet of the datagram is due
+	first           int // First PCR of the reference PID since the last discontinuity, in 27 MHz ticks
+	hasPCR          bool
+	hasPCRPID       bool
+	isRTP           bool
+	last            int // Last PCR of the reference PID, in 27 MHz ticks
+	now             func() time.Time
+	pacing          bool
+	pcrPID          uint16   // Reference PID
+	pending         [][]byte // Packets written since the last PCR, waiting for the next one to be scheduled
+	sequenceNumber  uint16
+	sleep           func(time.Duration)
+	ssrc            uint32
+	start           time.Time // Time at which the first PCR is due
+	unwrapper       *ClockUnwrapper
+	w               io.Writer
+}
+
+// NewUDPWriter creates a new UDP writer. Every write to the underlying writer is a datagram, which is what a
+// connection returned by net.Dial("udp", addr) expects
+func NewUDPWriter(w io.Writer, opts ...func(*UDPWriter)) (uw *UDPWriter) {
+	uw = &UDPWriter{
+		now:       time.Now,
+		sleep:     time.Sleep,
+		unwrapper: NewClockUnwrapper(),
+		w:         w,
+	}
+	for _, opt := range opts {
+		opt(uw)
+	}
+	return
+}
+
+// UDPWriterOptPacing returns the option to send datagrams at the pace of the PCRs of the reference PID
+func UDPWriterOptPacing() func(*UDPWriter) {
+	return func(w *UDPWriter) {
+		w.pacing = true
+	}
+}
+
+// UDPWriterOptPCRPID returns the option to set the PID whose PCRs drive RTP timestamps and pacing
+func UDPWriterOptPCRPID(pid uint16) func(*UDPWriter) {
+	return func(w *UDPWriter) {
+		w.hasPCRPID = true
+		w.pcrPID = pid
+	}
+}
+
+// UDPWriterOptRTP returns the option to send RTP packets with a synchronization source identifier
+func UDPWriterOptRTP(ssrc uint32) func(*UDPWriter) {
+	return func(w *UDPWriter) {
+		w.isRTP = true
+		w.ssrc = ssrc
+	}
+}
+
+// Write writes 188 bytes packets
+// Bytes that don't make a complete packet are buffered until the next write, and packets that don't make a complete
+// datagram are buffered until the next write or until Flush is called
+func (w *UDPWriter) Write(b []byte) (n int, err error) {
+	// Buffer bytes
+	n = len(b)
+	w.buf = append(w.buf, b...)
+
+	// Loop through packets
+	for len(w.buf) >= MpegTsPacketSize {
+		if err = w.writePacket(w.buf[:MpegTsPacketSize]); err != nil {
+			err = errors.Wrap(err, "astits: writing packet failed")
+			return
+		}
+		w.buf = w.buf[MpegTsPacketSize:]
+	}
+
+	// Release buffer
+	if len(w.buf) == 0 {
+		w.buf = nil
+	}
+	return
+}
+
+// Flush sends the packets waiting for a PCR and the last datagram, even if it's not complete
+func (w *UDPWriter) Flush() (err error) {
+	// Packets pending are sent right away since their due time can't be interpolated
+	if err = w.schedulePending(false, 0); err != nil {
+		err = errors.Wrap(err, "astits: scheduling pending packets failed")
+		return
+	}
+
+	// Send last datagram
+	if w.datagramPackets > 0 {
+		if err = w.send(); err != nil {
+			err = errors.Wrap(err, "astits: sending datagram failed")
+			return
+		}
+	}
+	return
+}
+
+// writePacket schedules a packet based on the PCRs of the reference PID
+func (w *UDPWriter) writePacket(b []byte) (err error) {
+	// Parse packet
+	var p *Packet
+	if p, err = parsePacket(b); err != nil {
+		err = errors.Wrap(err, "astits: parsing packet failed")
+		return
+	}
+
+	// Packets not carrying a PCR of the reference PID wait for the next PCR when pacing
+	if !p.Header.HasAdaptationField || p.AdaptationField == nil || !p.AdaptationField.HasPCR || p.AdaptationField.PCR == nil ||
+		(w.hasPCRPID && p.Header.PID != w.pcrPID) {
+		if w.pacing && w.hasPCR {
+			w.pending = append(w.pending, append([]byte{}, b...))
+			return
+		}
+		return w.schedule(b, w.last)
+	}
+
+	// First PCR or discontinuity
+	var c = w.unwrapper.Unwrap(p.AdaptationField.PCR).Ticks27MHz()
+	if !w.hasPCR || p.AdaptationField.DiscontinuityIndicator || c < w.last {
+		if err = w.schedulePending(false, 0); err != nil {
+			err = errors.Wrap(err, "astits: scheduling pending packets failed")
+			return
+		}
+		w.first = c
+		w.hasPCR = true
+		w.hasPCRPID = true
+		w.pcrPID = p.Header.PID
+		w.start = w.now()
+	} else if err = w.schedulePending(true, c); err != nil {
+		err = errors.Wrap(err, "astits: scheduling pending packets failed")
+		return
+	}
+	w.last = c
+	return w.schedule(b, c)
+}
+
+// schedulePending schedules the packets waiting for a PCR, spread evenly between the last PCR and the next one if
+// interpolated, or at the last PCR otherwise
+func (w *UDPWriter) schedulePending(interpolate bool, next int) (err error) {
+	for idx, b := range w.pending {
+		var ticks = w.last
+		if interpolate {
+			ticks += (idx + 1) * (next - w.last) / (len(w.pending) + 1)
+		}
+		if err = w.schedule(b, ticks); err != nil {
+			return
+		}
+	}
+	w.pending = nil
+	return
+}
+
+// schedule adds a packet due at a number of 27 MHz ticks to the datagram, and sends the datagram once it's complete
+func (w *UDPWriter) schedule(b []byte, ticks int) (err error) {
+	if w.datagramPackets == 0 {
+		w.datagramTicks = ticks
+	}
+	w.datagram = append(w.datagram, b...)
+	if w.datagramPackets++; w.datagramPackets == udpPacketsPerDatagram {
+		if err = w.send(); err != nil {
+			err = errors.Wrap(err, "astits: sending datagram failed")
+			return
+		}
+	}
+	return
+}
+
+// send sends the datagram once its first packet is due
+func (w *UDPWriter) send() (err error) {
+	// Wait
+	if w.pacing && w.hasPCR {
+		if d := w.start.Add(ticks27MHzToDuration(w.datagramTicks - w.first)).Sub(w.now()); d > 0 {
+			w.sleep(d)
+		}
+	}
+
+	// RTP header
+	var b = w.datagram
+	if w.isRTP {
+		var t = uint32(w.datagramTicks / 300)
+		b = append([]byte{
+			rtpVersion << 6, rtpPayloadTypeMP2T, uint8(w.sequenceNumber >> 8), uint8(w.sequenceNumber),
+			uint8(t >> 24), uint8(t >> 16), uint8(t >> 8), uint8(t),
+			uint8(w.ssrc >> 24), uint8(w.ssrc >> 16), uint8(w.ssrc >> 8), uint8(w.ssrc),
+		}, w.datagram...)
+		w.sequenceNumber++
+	}
+
+	// Write
+	if _, err = w.w.Write(b); err != nil {
+		err = errors.Wrap(err, "astits: writing datagram failed")
+		return
+	}
+	w.datagram = w.datagram[:0]
+	w.datagramPackets = 0
+	return
+}
@@ -0,0 +1,163 @@
+package astits
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/asticode/go-astitools/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+func rtpPacketBytes(sequenceNumber uint16, payload []byte) []byte {
+	w := astibinary.New()
+	w.Write("10")               // Version
+	w.Write("1")                // Padding
+	w.Write("1")                // Extension
+	w.Write("0001")             // CSRC count
+	w.Write("0")                // Marker
+	w.Write("0100001")          // Payload type
+	w.Write(sequenceNumber)     // Sequence number
+	w.Write(uint32(90000))      // Timestamp
+	w.Write(uint32(0x12345678)) // SSRC
+	w.Write(uint32(0x1))        // CSRC
+	w.Write(uint16(0xbede))     // Extension profile
+	w.Write(uint16(1))          // Extension length
+	w.Write(uint32(0x2))        // Extension
+	w.Write(payload)            // Payload
+	w.Write([]byte{0x0, 0x2})   // Padding
+	return w.Bytes()
+}
+
+func TestUDPReaderPayload(t *testing.T) {
+	// Raw packets
+	r := NewUDPReader(nil)
+	var ps = bytes.Repeat(t2miTSPacket(0xaa), 7)
+	assert.Equal(t, ps, r.payload(ps))
+	assert.False(t, r.IsRTP())
+
+	// RTP packets
+	assert.Equal(t, ps, r.payload(rtpPacketBytes(0xfffe, ps)))
+	assert.True(t, r.IsRTP())
+	assert.Equal(t, ps, r.payload(rtpPacketBytes(0xffff, ps)))
+	assert.Equal(t, int64(0), r.LostRTPPackets())
+
+	// Lost packets
+	assert.Equal(t, ps, r.payload(rtpPacketBytes(2, ps)))
+	assert.Equal(t, int64(2), r.LostRTPPackets())
+
+	// Duplicated and late packets
+	assert.Len(t, r.payload(rtpPacketBytes(2, ps)), 0)
+	assert.Len(t, r.payload(rtpPacketBytes(1, ps)), 0)
+	assert.Equal(t, int64(2), r.LostRTPPackets())
+
+	// Invalid header
+	assert.Len(t, r.payload(rtpPacketBytes(3, nil)[:18]), 0)
+}
+
+func TestUDPReader(t *testing.T) {
+	// Init
+	r, err := ListenUDP("127.0.0.1:0", nil)
+	assert.NoError(t, err)
+	c, err := net.Dial("udp", r.c.LocalAddr().String())
+	assert.NoError(t, err)
+	defer c.Close()
+
+	// Write datagrams
+	var psA = bytes.Repeat(t2miTSPacket(0xaa), 7)
+	var psB = bytes.Repeat(t2miTSPacket(0xbb), 7)
+	c.Write(rtpPacketBytes(1, psA))
+	c.Write(rtpPacketBytes(3, psB))
+
+	// Read
+	var b = make([]byte, MpegTsPacketSize)
+	n, err := r.Read(b)
+	assert.NoError(t, err)
+	assert.Equal(t, MpegTsPacketSize, n)
+	assert.Equal(t, t2miTSPacket(0xaa), b)
+	b = make([]byte, len(psA)+len(psB)-MpegTsPacketSize)
+	_, err = io.ReadFull(r, b)
+	assert.NoError(t, err)
+	assert.Equal(t, append(psA[MpegTsPacketSize:], psB...), b)
+	assert.Equal(t, int64(1), r.LostRTPPackets())
+
+	// Closing unblocks reads
+	r.Close()
+	_, err = r.Read(b)
+	assert.Error(t, err)
+}
+
+type udpDatagramRecorder struct {
+	ds [][]byte
+}
+
+func (r *udpDatagramRecorder) Write(b []byte) (int, error) {
+	r.ds = append(r.ds, append([]byte{}, b...))
+	return len(b), nil
+}
+
+func TestUDPWriter(t *testing.T) {
+	// Init
+	rc := &udpDatagramRecorder{}
+	w := NewUDPWriter(rc, UDPWriterOptPacing(), UDPWriterOptRTP(0x12345678))
+	var now time.Time
+	var sleeps []time.Duration
+	w.now = func() time.Time { return now }
+	w.sleep = func(d time.Duration) {
+		now = now.Add(d)
+		sleeps = append(sleeps, d)
+	}
+	write := func(pid uint16, pcr time.Duration) {
+		p := &Packet{Header: &PacketHeader{HasPayload: true, PID: pid}, Payload: []byte("payload")}
+		if pcr >= 0 {
+			p.AdaptationField = &PacketAdaptationField{HasPCR: true, PCR: NewClockReference27MHz(durationTo27MHzTicks(pcr))}
+			p.Header.HasAdaptationField = true
+		}
+		w.Write(writePacket(p))
+	}
+
+	// Packets between PCRs are spread evenly
+	write(0x100, 0)
+	for idx := 0; idx < 13; idx++ {
+		write(0x101, -1)
+	}
+	assert.Len(t, rc.ds, 0)
+	write(0x100, 140*time.Millisecond)
+	assert.NoError(t, w.Flush())
+	assert.Equal(t, []time.Duration{70 * time.Millisecond, 70 * time.Millisecond}, sleeps)
+	assert.Len(t, rc.ds, 3)
+	assert.Len(t, rc.ds[0], rtpHeaderLength+udpPacketsPerDatagram*MpegTsPacketSize)
+	assert.Len(t, rc.ds[2], rtpHeaderLength+MpegTsPacketSize)
+
+	// RTP header
+	r := NewUDPReader(nil)
+	var pids []uint16
+	for idx, d := range rc.ds {
+		assert.Equal(t, []byte{0x80, rtpPayloadTypeMP2T, 0x0, uint8(idx)}, d[:4])
+		assert.Equal(t, uint32(idx*6300), uint32(d[4])<<24|uint32(d[5])<<16|uint32(d[6])<<8|uint32(d[7]))
+		assert.Equal(t, []byte{0x12, 0x34, 0x56, 0x78}, d[8:12])
+		for i := r.payload(d); len(i) >= MpegTsPacketSize; i = i[MpegTsPacketSize:] {
+			pid, _ := rawPacketPID(i)
+			pids = append(pids, pid)
+		}
+	}
+	assert.Len(t, pids, 15)
+	assert.Equal(t, uint16(0x100), pids[0])
+	assert.Equal(t, uint16(0x101), pids[13])
+	assert.Equal(t, uint16(0x100), pids[14])
+	assert.Equal(t, int64(0), r.LostRTPPackets())
+
+	// Raw datagrams are sent as packets are written
+	rc.ds = nil
+	w = NewUDPWriter(rc)
+	for idx := 0; idx < 8; idx++ {
+		write(0x101, -1)
+	}
+	assert.Len(t, rc.ds, 1)
+	assert.Len(t, rc.ds[0], udpPacketsPerDatagram*MpegTsPacketSize)
+	assert.NoError(t, w.Flush())
+	assert.Len(t, rc.ds, 2)
+	assert.Len(t, rc.ds[1], MpegTsPacketSize)
+}
@@ -0,0 +1,214 @@
+package astits
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// ULE types. Types below 0x600 are next headers, others are EtherTypes
+// Page: 15 | Chapter: 4.4 | Link: https://tools.ietf.org/html/rfc4326
+const (
+	ULETypeBridgedFrame = 0x1
+	ULETypeIPv4         = 0x800
+	ULETypeIPv6         = 0x86dd
+	ULETypeTestSNDU     = 0x0
+)
+
+// ULE constants
+const (
+	uleCRC32Length              = 4
+	uleDestinationAddressLength = 6
+	uleEndIndicator             = 0xffff
+	uleHeaderLength             = 4
+	uleTypeEtherTypeMinimum     = 0x600
+)
+
+// ULEData represents the SNDUs carried by an elementary stream using the unidirectional lightweight encapsulation
+// Page: 6 | Chapter: 3 | Link: https://tools.ietf.org/html/rfc4326
+type ULEData struct {
+	SNDUs []*ULESNDU
+}
+
+// ULESNDU represents a ULE subnetwork data unit
+// Page: 10 | Chapter: 4 | Link: https://tools.ietf.org/html/rfc4326
+type ULESNDU struct {
+	CRC32              uint32
+	DestinationAddress []byte // Only set when the destination address absent bit is unset
+	PDU                []byte // Extension headers signalled by next header types are left unparsed
+	Type               uint16
+}
+
+// IsEtherType checks whether the type of the SNDU is an EtherType rather than a next header
+func (s *ULESNDU) IsEtherType() bool {
+	return s.Type >= uleTypeEtherTypeMinimum
+}
+
+// ParseULESNDUs parses the SNDUs concatenated in a payload, until the end indicator if any
+func ParseULESNDUs(i []byte) (ss []*ULESNDU, err error) {
+	var offset int
+	for offset+2 <= len(i) && uint16(i[offset])<<8|uint16(i[offset+1]) != uleEndIndicator {
+		var s *ULESNDU
+		if s, err = parseULESNDU(i, &offset); err != nil {
+			err = errors.Wrapf(err, "astits: parsing ULE SNDU #%d failed", len(ss)+1)
+			return
+		}
+		ss = append(ss, s)
+	}
+	return
+}
+
+// uleSNDULength returns the length in bytes of the SNDU starting the payload, or 0 if its header is incomplete
+func uleSNDULength(i []byte) int {
+	if len(i) < 2 {
+		return 0
+	}
+	return uleHeaderLength + int(uint16(i[0]&0x7f)<<8|uint16(i[1]))
+}
+
+// parseULESNDU parses a ULE SNDU and checks its CRC32
+func parseULESNDU(i []byte, offset *int) (s *ULESNDU, err error) {
+	// Check length
+	var l = uleSNDULength(i[*offset:])
+	var hasDestinationAddress = i[*offset]&0x80 == 0
+	var fixedLength = uleHeaderLength + uleCRC32Length
+	if hasDestinationAddress {
+		fixedLength += uleDestinationAddressLength
+	}
+	if l < fixedLength {
+		err = fmt.Errorf("astits: ULE SNDU length (%d) < minimum length (%d)", l, fixedLength)
+		return
+	} else if *offset+l > len(i) {
+		err = fmt.Errorf("astits: ULE SNDU end (%d) > len(i) (%d)", *offset+l, len(i))
+		return
+	}
+	var b = i[*offset : *offset+l]
+	*offset += l
+
+	// Check CRC32
+	s = &ULESNDU{
+		CRC32: parseCRC32(b),
+		Type:  uint16(b[2])<<8 | uint16(b[3]),
+	}
+	if c := computeCRC32(b[:l-uleCRC32Length]); c != s.CRC32 {
+		err = fmt.Errorf("astits: ULE SNDU CRC32 %x != computed CRC32 %x", s.CRC32, c)
+		return
+	}
+
+	// Destination address
+	var o = uleHeaderLength
+	if hasDestinationAddress {
+		s.DestinationAddress = b[o : o+uleDestinationAddressLength]
+		o += uleDestinationAddressLength
+	}
+
+	// PDU
+	s.PDU = b[o : l-uleCRC32Length]
+	return
+}
+
+// uleTracker keeps track of the elementary streams carrying ULE SNDUs
+type uleTracker struct {
+	pids map[uint16]*uleTrackerPID // Indexed by elementary PID
+}
+
+// uleTrackerPID represents an elementary stream carrying ULE SNDUs
+type uleTrackerPID struct {
+	buf []byte // Start of the SNDU being reassembled
+}
+
+// newULETracker creates a new ULE tracker
+func newULETracker() *uleTracker {
+	return &uleTracker{pids: make(map[uint16]*uleTrackerPID)}
+}
+
+// setProgram adds the ULE streams of a program
+func (t *uleTracker) setProgram(d *PMTData) {
+	for _, es := range d.ElementaryStreams {
+		if _, ok := t.pids[es.ElementaryPID]; !ok && es.StreamType == StreamTypeULE {
+			t.pids[es.ElementaryPID] = &uleTrackerPID{}
+		}
+	}
+}
+
+// reset drops the SNDUs being reassembled, the ULE streams being kept as long as the PMTs are
+func (t *uleTracker) reset() {
+	for _, p := range t.pids {
+		p.buf = nil
+	}
+}
+
+// parse parses the SNDUs of a payload that starts with a payload pointer, and completes the SNDU started in the
+// previous payload with the bytes preceding the pointed SNDU
+// SNDUs whose CRC32 is invalid are dropped
+// Page: 16 | Chapter: 5 | Link: https://tools.ietf.org/html/rfc4326
+func (p *uleTrackerPID) parse(i []byte) (ss []*ULESNDU, err error) {
+	// Payload pointer
+	if len(i) == 0 || 1+int(i[0]) > len(i) {
+		p.buf = nil
+		err = fmt.Errorf("astits: ULE payload pointer is invalid")
+		return
+	}
+	var pointer = 1 + int(i[0])
+
+	// Complete the SNDU started in the previous payload
+	if p.buf != nil {
+		var b = append(p.buf, i[1:pointer]...)
+		p.buf = nil
+		if l := uleSNDULength(b); l > 0 && l <= len(b) {
+			var offset int
+			var s *ULESNDU
+			if s, err = parseULESNDU(b[:l], &offset); err != nil {
+				err = errors.Wrap(err, "astits: parsing ULE SNDU failed")
+			} else {
+				ss = append(ss, s)
+			}
+		}
+	}
+
+	// Loop through complete SNDUs. The rest of the payload is padding once the end indicator is found
+	var b = i[pointer:]
+	for len(b) >= 2 && uint16(b[0])<<8|uint16(b[1]) != uleEndIndicator {
+		var l = uleSNDULength(b)
+		if l > len(b) {
+			// Keep the SNDU left
+			p.buf = append([]byte{}, b...)
+			break
+		}
+		var offset int
+		var s *ULESNDU
+		if s, err = parseULESNDU(b[:l], &offset); err != nil {
+			err = errors.Wrap(err, "astits: parsing ULE SNDU failed")
+		} else {
+			ss = append(ss, s)
+		}
+		b = b[l:]
+	}
+	return
+}
+
+// parseULEData parses the SNDUs of ULE streams
+func (dmx *Demuxer) parseULEData(ps []*Packet) (ds []*Data) {
+	// Reconstruct payload
+	var payload []byte
+	for _, p := range ps {
+		payload = append(payload, p.Payload...)
+	}
+
+	// Parse SNDUs
+	var pid = ps[0].Header.PID
+	ss, err := dmx.uleTracker.pids[pid].parse(payload)
+	if err != nil {
+		// SNDUs may be corrupted, therefore we only log the error and move on
+		dmx.optLogger.Debugf("astits: parsing ULE SNDUs of PID %d failed: %s", pid, err)
+	}
+	if len(ss) == 0 {
+		return
+	}
+	ds = append(ds, &Data{
+		FirstPacket: ps[0],
+		PID:         pid,
+		ULE:         &ULEData{SNDUs: ss},
+	})
+	return
+}
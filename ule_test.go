@@ -0,0 +1,87 @@
+package astits
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/asticode/go-astitools/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+func uleSNDUBytes(destinationAddress []byte, pdu []byte) []byte {
+	w := astibinary.New()
+	var l = uint16(len(destinationAddress) + len(pdu) + 4)
+	if destinationAddress == nil {
+		l |= 0x8000
+	}
+	w.Write(l)                       // Destination address absent bit and length
+	w.Write(uint16(ULETypeIPv4))     // Type
+	w.Write(destinationAddress)      // Destination address
+	w.Write(pdu)                     // PDU
+	w.Write(computeCRC32(w.Bytes())) // CRC32
+	return w.Bytes()
+}
+
+func TestParseULESNDUs(t *testing.T) {
+	// Init
+	var b = append(uleSNDUBytes(nil, []byte("pdu1")), uleSNDUBytes([]byte{0x1, 0x2, 0x3, 0x4, 0x5, 0x6}, []byte("pdu2"))...)
+	b = append(b, 0xff, 0xff, 0x0)
+
+	// Parse
+	ss, err := ParseULESNDUs(b)
+	assert.NoError(t, err)
+	assert.Len(t, ss, 2)
+	assert.Nil(t, ss[0].DestinationAddress)
+	assert.Equal(t, []byte("pdu1"), ss[0].PDU)
+	assert.True(t, ss[0].IsEtherType())
+	assert.Equal(t, uint16(ULETypeIPv4), ss[0].Type)
+	assert.Equal(t, []byte{0x1, 0x2, 0x3, 0x4, 0x5, 0x6}, ss[1].DestinationAddress)
+	assert.Equal(t, []byte("pdu2"), ss[1].PDU)
+	assert.Equal(t, computeCRC32(b[12:26]), ss[1].CRC32)
+
+	// Invalid CRC32
+	b[5]++
+	_, err = ParseULESNDUs(b)
+	assert.Error(t, err)
+}
+
+func TestDemuxerULE(t *testing.T) {
+	// Init
+	w := astibinary.New()
+	pm, _ := (&PMTData{ElementaryStreams: []*PMTElementaryStream{{ElementaryPID: 0x101, StreamType: StreamTypeULE}}, PCRPID: 0x1fff, ProgramNumber: 1}).Serialize(0)
+	var p = append([]byte{0x0}, pm...)
+	b, _ := packet(PacketHeader{PayloadUnitStartIndicator: true, PID: 0x100}, PacketAdaptationField{}, append(p, bytes.Repeat([]byte{0xff}, 147-len(p))...))
+	w.Write(b)
+	b, _ = packet(PacketHeader{ContinuityCounter: uint8(1), PayloadUnitStartIndicator: true, PID: 0x100}, PacketAdaptationField{}, []byte{})
+	w.Write(b)
+
+	// SNDU B starts in the first packet and ends in the second one
+	var sA = uleSNDUBytes(nil, bytes.Repeat([]byte{0xa}, 42))
+	var sB = uleSNDUBytes(nil, bytes.Repeat([]byte{0xb}, 112))
+	p = append(append([]byte{0x0}, sA...), sB[:96]...)
+	b, _ = packet(PacketHeader{PayloadUnitStartIndicator: true, PID: 0x101}, PacketAdaptationField{}, p)
+	w.Write(b)
+	p = append([]byte{uint8(len(sB) - 96)}, sB[96:]...)
+	b, _ = packet(PacketHeader{ContinuityCounter: uint8(1), PayloadUnitStartIndicator: true, PID: 0x101}, PacketAdaptationField{}, append(p, bytes.Repeat([]byte{0xff}, 147-len(p))...))
+	w.Write(b)
+	dmx := New(context.Background(), bytes.NewReader(w.Bytes()))
+	dmx.programMap.set(0x100, 1)
+
+	// PMT
+	d, err := dmx.NextData()
+	assert.NoError(t, err)
+	assert.NotNil(t, d.PMT)
+
+	// SNDU A
+	d, err = dmx.NextData()
+	assert.NoError(t, err)
+	assert.Len(t, d.ULE.SNDUs, 1)
+	assert.Equal(t, bytes.Repeat([]byte{0xa}, 42), d.ULE.SNDUs[0].PDU)
+
+	// SNDU B
+	d, err = dmx.NextData()
+	assert.NoError(t, err)
+	assert.Len(t, d.ULE.SNDUs, 1)
+	assert.Equal(t, bytes.Repeat([]byte{0xb}, 112), d.ULE.SNDUs[0].PDU)
+}